@@ -3,12 +3,14 @@ package klip
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
 
 	"github.com/john/klip/internal/app"
+	"github.com/john/klip/internal/storage"
 )
 
 var (
@@ -37,6 +39,40 @@ func Execute() {
 	log.SetLevel(log.DebugLevel)
 	log.SetOutput(os.Stderr)
 
+	// --profile or $KLIP_PROFILE picks the profile before storage is opened
+	profile, args := flagArg(os.Args[1:], "profile")
+	if profile == "" {
+		profile = os.Getenv(storage.ProfileEnv)
+	}
+	if err := storage.SetProfile(profile); err != nil {
+		log.Error("Invalid profile", "error", err)
+		os.Exit(1)
+	}
+
+	// --config or $KLIP_CONFIG picks the config file before anything loads
+	// it, overriding the profile's
+	configPath, args := flagArg(args, "config")
+	if configPath == "" {
+		configPath = os.Getenv(storage.ConfigFileEnv)
+	}
+	if configPath != "" {
+		path, err := storage.SetConfigFile(configPath)
+		if err != nil {
+			log.Error("Invalid config path", "path", configPath, "error", err)
+			os.Exit(1)
+		}
+		log.Info("Using config file", "path", path)
+	}
+
+	// klip serve answers chat requests over HTTP instead of opening the UI
+	if len(args) > 0 && args[0] == "serve" {
+		if err := serve(args[1:]); err != nil {
+			log.Error("Error running server", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Display banner
 	fmt.Print(titleStyle.Render("Klip - Terminal AI Chat"))
 	fmt.Print(bannerStyle.Render(banner))
@@ -52,9 +88,32 @@ func Execute() {
 		tea.WithMouseCellMotion(),
 	)
 
-	// Run the program
-	if _, err := p.Run(); err != nil {
+	// Run the program. Bubble Tea turns SIGINT and SIGTERM into Run
+	// returning, so the session is saved however the program stops.
+	_, err := p.Run()
+	model.Shutdown()
+	if err != nil {
 		log.Error("Error running application", "error", err)
 		os.Exit(1)
 	}
 }
+
+// flagArg takes --name <value> or --name=<value> out of args, also
+// accepting a single dash, and returns the value and the remaining args
+func flagArg(args []string, flag string) (string, []string) {
+	var found string
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		if !strings.HasPrefix(args[i], "-") || name != flag {
+			rest = append(rest, args[i])
+			continue
+		}
+		if !hasValue && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		found = value
+	}
+	return found, rest
+}
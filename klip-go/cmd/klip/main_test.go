@@ -0,0 +1,31 @@
+package klip
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlagArg(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		path string
+		rest []string
+	}{
+		{"none", []string{"serve", "-addr", ":9000"}, "", []string{"serve", "-addr", ":9000"}},
+		{"separate value", []string{"--config", "work.json"}, "work.json", []string{}},
+		{"equals", []string{"--config=work.json", "serve"}, "work.json", []string{"serve"}},
+		{"single dash", []string{"-config", "profiles/home", "serve", "-token", "t"}, "profiles/home", []string{"serve", "-token", "t"}},
+		{"missing value", []string{"--config"}, "", []string{}},
+		{"other flags kept", []string{"--profile", "work", "--config", "c.json"}, "c.json", []string{"--profile", "work"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, rest := flagArg(tt.args, "config")
+			assert.Equal(t, tt.path, path)
+			assert.Equal(t, tt.rest, rest)
+		})
+	}
+}
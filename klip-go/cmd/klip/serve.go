@@ -0,0 +1,55 @@
+package klip
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/charmbracelet/log"
+
+	"github.com/john/klip/internal/server"
+	"github.com/john/klip/internal/storage"
+)
+
+// serve runs klip as a local HTTP API: klip serve [-addr host:port] [-token t].
+// The token defaults to $KLIP_SERVE_TOKEN, and is required to listen beyond
+// loopback.
+func serve(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", server.DefaultAddr, "address to listen on; needs a token unless loopback")
+	token := flags.String("token", os.Getenv("KLIP_SERVE_TOKEN"), "bearer token clients must send")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := storage.New()
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer func() {
+		if store.AnalyticsLogger != nil {
+			if err := store.AnalyticsLogger.Close(); err != nil {
+				log.Warn("Failed to flush analytics", "error", err)
+			}
+		}
+	}()
+
+	config, err := store.ConfigManager.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := server.New(server.StorageProviders(store, config), store.AnalyticsLogger, *token)
+	if err := srv.ListenAndServe(ctx, *addr); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
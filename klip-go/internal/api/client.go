@@ -33,6 +33,54 @@ func (p Provider) String() string {
 	return string(p)
 }
 
+// DisplayName returns the provider's name as written in the UI
+func (p Provider) DisplayName() string {
+	switch p {
+	case ProviderAnthropic:
+		return "Anthropic"
+	case ProviderOpenAI:
+		return "OpenAI"
+	case ProviderOpenRouter:
+		return "OpenRouter"
+	case "":
+		return "Provider"
+	}
+	return string(p)
+}
+
+// TemperatureRange returns the lowest and highest temperature the provider accepts
+func (p Provider) TemperatureRange() (float64, float64) {
+	return storage.TemperatureRange(string(p))
+}
+
+// SupportsContinuation reports whether the provider resumes a response when the
+// conversation ends with a partial assistant message
+func (p Provider) SupportsContinuation() bool {
+	return p == ProviderAnthropic
+}
+
+// ValidateParameters checks generation parameters against provider and model
+// limits. Nil values mean "use the provider default" and are always valid.
+func ValidateParameters(model Model, temperature *float64, maxTokens *int) error {
+	if temperature != nil {
+		minTemp, maxTemp := model.Provider.TemperatureRange()
+		if *temperature < minTemp || *temperature > maxTemp {
+			return fmt.Errorf("temperature for %s must be between %.1f and %.1f", model.Provider, minTemp, maxTemp)
+		}
+	}
+
+	if maxTokens != nil {
+		if *maxTokens < 1 {
+			return fmt.Errorf("max tokens must be at least 1")
+		}
+		if model.MaxTokens > 0 && *maxTokens > model.MaxTokens {
+			return fmt.Errorf("max tokens for %s cannot exceed %d", model.Name, model.MaxTokens)
+		}
+	}
+
+	return nil
+}
+
 // Model represents an AI model
 type Model struct {
 	ID            string   `json:"id"`
@@ -47,6 +95,17 @@ type Message struct {
 	Role      string    `json:"role"`
 	Content   string    `json:"content"`
 	Timestamp time.Time `json:"timestamp"`
+
+	// Model and Usage record what produced an assistant reply, when the
+	// provider reported it
+	Model string `json:"model,omitempty"`
+	Usage *Usage `json:"usage,omitempty"`
+
+	// Cached is set when the reply came from the local response cache
+	Cached bool `json:"cached,omitempty"`
+
+	// Truncated is set when the reply stopped at the token limit
+	Truncated bool `json:"truncated,omitempty"`
 }
 
 // ChatRequest represents a chat request
@@ -54,15 +113,29 @@ type ChatRequest struct {
 	Model           Model     `json:"model"`
 	Messages        []Message `json:"messages"`
 	MaxTokens       int       `json:"max_tokens,omitempty"`
-	Temperature     float64   `json:"temperature,omitempty"`
+	Temperature     *float64  `json:"temperature,omitempty"`
 	Stream          bool      `json:"stream,omitempty"`
 	EnableWebSearch bool      `json:"enable_web_search,omitempty"`
+
+	// PromptCaching asks providers that support it to cache the system
+	// prompt and conversation so far, so the next turn rereads them cheaply
+	PromptCaching bool `json:"prompt_caching,omitempty"`
 }
 
-// Usage represents token usage information
+// Usage represents token usage information. With prompt caching,
+// InputTokens counts only the uncached input; tokens read from and written
+// to the cache are billed at their own rates.
 type Usage struct {
 	InputTokens  int `json:"input_tokens"`
 	OutputTokens int `json:"output_tokens"`
+
+	CacheReadInputTokens     int `json:"cache_read_input_tokens,omitempty"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
+}
+
+// TotalInputTokens counts the input tokens whether or not they were cached
+func (u Usage) TotalInputTokens() int {
+	return u.InputTokens + u.CacheReadInputTokens + u.CacheCreationInputTokens
 }
 
 // ChatResponse represents a chat response
@@ -70,6 +143,32 @@ type ChatResponse struct {
 	Content string           `json:"content"`
 	Usage   *Usage           `json:"usage,omitempty"`
 	Metrics *ResponseMetrics `json:"metrics,omitempty"`
+
+	// FinishReason says why generation stopped, normalized across
+	// providers; see FinishReasonLength
+	FinishReason string `json:"finish_reason,omitempty"`
+
+	// RateLimit is the quota reported in the response headers, if any
+	RateLimit *RateLimit `json:"rate_limit,omitempty"`
+}
+
+// Finish reasons reported in ChatResponse and the final StreamChunk
+const (
+	FinishReasonStop   = "stop"
+	FinishReasonLength = "length"
+)
+
+// NormalizeFinishReason maps a provider's stop reason onto the shared
+// values. Anthropic reports "max_tokens" where OpenAI reports "length".
+func NormalizeFinishReason(reason string) string {
+	switch reason {
+	case "length", "max_tokens":
+		return FinishReasonLength
+	case "stop", "end_turn", "stop_sequence":
+		return FinishReasonStop
+	default:
+		return reason
+	}
 }
 
 // ResponseMetrics contains response timing and metadata
@@ -84,6 +183,15 @@ type ResponseMetrics struct {
 type StreamChunk struct {
 	Content string `json:"content"`
 	Done    bool   `json:"done"`
+
+	// FinishReason is set on the final chunk when the provider reported one
+	FinishReason string `json:"finish_reason,omitempty"`
+
+	// RateLimit is set on the final chunk when the response headers had one
+	RateLimit *RateLimit `json:"rate_limit,omitempty"`
+
+	// Usage is set on the final chunk when the provider reported it
+	Usage *Usage `json:"usage,omitempty"`
 }
 
 // ProviderInterface defines the interface that all providers must implement
@@ -104,6 +212,9 @@ type APIError struct {
 	Message    string `json:"message"`
 	Provider   string `json:"provider"`
 	Retryable  bool   `json:"retryable"`
+
+	// RateLimit is the quota and Retry-After the provider sent, if any
+	RateLimit *RateLimit `json:"rate_limit,omitempty"`
 }
 
 func (e *APIError) Error() string {
@@ -117,8 +228,21 @@ type Client struct {
 	analytics   *storage.AnalyticsLogger
 	provider    ProviderInterface
 	retryConfig *RetryConfig
+
+	onConnectionState func(ConnectionState)
 }
 
+// ConnectionState describes the streaming connection to the provider; the
+// values mirror the status bar's connection states
+type ConnectionState int
+
+const (
+	ConnectionDisconnected ConnectionState = iota
+	ConnectionConnecting
+	ConnectionConnected
+	ConnectionError
+)
+
 // RetryConfig contains retry configuration
 type RetryConfig struct {
 	MaxRetries      int
@@ -127,6 +251,10 @@ type RetryConfig struct {
 	ExponentBase    float64
 	JitterMax       time.Duration
 	RetryableErrors []int // HTTP status codes that should trigger retries
+
+	// SkipRateLimits returns rate limit errors at once, for callers that
+	// wait them out themselves
+	SkipRateLimits bool
 }
 
 // DefaultRetryConfig returns the default retry configuration
@@ -147,14 +275,7 @@ func NewClient(provider ProviderInterface, analytics *storage.AnalyticsLogger) (
 		return nil, errors.New("provider is required")
 	}
 
-	httpClient := &http.Client{
-		Timeout: 120 * time.Second,
-		Transport: &http.Transport{
-			MaxIdleConns:        100,
-			MaxIdleConnsPerHost: 10,
-			IdleConnTimeout:     30 * time.Second,
-		},
-	}
+	httpClient := NewHTTPClient(120*time.Second, ProxySettings{})
 
 	client := &Client{
 		httpClient:  httpClient,
@@ -240,6 +361,8 @@ func (c *Client) Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, err
 			if response.Usage != nil {
 				responseMetrics.TokensInput = response.Usage.InputTokens
 				responseMetrics.TokensOutput = response.Usage.OutputTokens
+				responseMetrics.CacheReadTokens = response.Usage.CacheReadInputTokens
+				responseMetrics.CacheWriteTokens = response.Usage.CacheCreationInputTokens
 			}
 		}
 
@@ -259,7 +382,9 @@ func (c *Client) Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, err
 	return response, err
 }
 
-// ChatStream sends a streaming chat request to the AI provider
+// ChatStream sends a streaming chat request to the AI provider. Connections
+// that drop mid-response are reopened up to MaxRetries times, resuming after
+// the content already received when the provider supports continuation.
 func (c *Client) ChatStream(ctx context.Context, req *ChatRequest) (<-chan StreamChunk, <-chan error) {
 	startTime := time.Now()
 	requestMetrics := c.buildRequestMetrics(req, startTime)
@@ -280,84 +405,72 @@ func (c *Client) ChatStream(ctx context.Context, req *ChatRequest) (<-chan Strea
 		defer close(errorChan)
 
 		var totalContent strings.Builder
-		var interrupted bool
+		var held string
+		var streamErr error
+		var final StreamChunk
 		retryCount := 0
 
-		// Execute with retry logic
+	attempts:
 		for attempt := 0; attempt <= c.retryConfig.MaxRetries; attempt++ {
 			retryCount = attempt
-			providerChunkChan, providerErrorChan := c.provider.ChatStream(ctx, req)
-
-			// Process the stream
-			var streamErr error
+			c.setConnectionState(ConnectionConnecting)
 
-			for {
-				select {
-				case chunk, ok := <-providerChunkChan:
-					if !ok {
-						// Stream finished successfully
-						goto streamComplete
-					}
-					totalContent.WriteString(chunk.Content)
-					chunkChan <- chunk
+			streamErr = c.streamAttempt(ctx, resumeRequest(req, totalContent.String()), chunkChan, &totalContent, &held, &final)
+			if streamErr == nil {
+				break
+			}
 
-				case err := <-providerErrorChan:
-					streamErr = err
-					goto streamError
+			// The continuation brings its own leading whitespace
+			held = ""
 
-				case <-ctx.Done():
-					interrupted = true
-					streamErr = ctx.Err()
-					goto streamError
-				}
+			// Content already delivered can only be kept if the provider resumes after it
+			resumable := totalContent.Len() == 0 || req.Model.Provider.SupportsContinuation()
+			if !resumable || !c.shouldRetry(streamErr, attempt) {
+				break
 			}
 
-		streamError:
-			if streamErr != nil && c.shouldRetry(streamErr, attempt) {
-				// Calculate backoff delay
-				delay := c.calculateBackoff(attempt)
-				c.logger.Debug("Retrying stream request", "attempt", attempt+1, "delay", delay, "error", streamErr)
+			// Calculate backoff delay
+			delay := c.calculateBackoff(attempt)
+			c.logger.Debug("Reconnecting stream", "attempt", attempt+1, "delay", delay, "received", totalContent.Len(), "error", streamErr)
 
-				select {
-				case <-ctx.Done():
-					errorChan <- ctx.Err()
-					return
-				case <-time.After(delay):
-					continue
-				}
-			} else {
-				errorChan <- streamErr
-				goto logMetrics
+			select {
+			case <-ctx.Done():
+				streamErr = ctx.Err()
+				break attempts
+			case <-time.After(delay):
 			}
 		}
 
-	streamComplete:
-		// Send final chunk to indicate completion
-		chunkChan <- StreamChunk{Content: "", Done: true}
+		if streamErr != nil {
+			c.setConnectionState(ConnectionError)
+			errorChan <- streamErr
+		} else {
+			// Send final chunk to indicate completion
+			chunkChan <- StreamChunk{Content: "", Done: true, FinishReason: final.FinishReason, Usage: final.Usage}
+		}
 
-	logMetrics:
 		// Log response metrics
 		if c.analytics != nil {
-			endTime := time.Now()
+			interrupted := errors.Is(streamErr, context.Canceled)
 			responseMetrics := storage.ResponseMetrics{
-				EndTime:        endTime,
+				EndTime:        time.Now(),
 				ResponseLength: totalContent.Len(),
 				Interrupted:    interrupted,
-				Success:        !interrupted && len(errorChan) == 0,
+				Success:        streamErr == nil,
 				RetryCount:     retryCount,
 			}
+			if usage := final.Usage; usage != nil {
+				responseMetrics.TokensInput = usage.InputTokens
+				responseMetrics.TokensOutput = usage.OutputTokens
+				responseMetrics.CacheReadTokens = usage.CacheReadInputTokens
+				responseMetrics.CacheWriteTokens = usage.CacheCreationInputTokens
+			}
 
-			if len(errorChan) > 0 {
-				// Peek at error without consuming it
-				select {
-				case err := <-errorChan:
-					responseMetrics.ErrorType = fmt.Sprintf("%T", err)
-					responseMetrics.ErrorMessage = err.Error()
-					if apiErr, ok := err.(*APIError); ok {
-						responseMetrics.StatusCode = apiErr.StatusCode
-					}
-					errorChan <- err // Put it back
-				default:
+			if streamErr != nil {
+				responseMetrics.ErrorType = fmt.Sprintf("%T", streamErr)
+				responseMetrics.ErrorMessage = streamErr.Error()
+				if apiErr, ok := streamErr.(*APIError); ok {
+					responseMetrics.StatusCode = apiErr.StatusCode
 				}
 			}
 
@@ -370,6 +483,104 @@ func (c *Client) ChatStream(ctx context.Context, req *ChatRequest) (<-chan Strea
 	return chunkChan, errorChan
 }
 
+// streamAttempt relays one provider stream, recording the content it delivers
+// and its final chunk. It returns nil once the provider signals completion.
+func (c *Client) streamAttempt(ctx context.Context, req *ChatRequest, out chan<- StreamChunk, content *strings.Builder, held *string, final *StreamChunk) error {
+	chunks, errs := c.provider.ChatStream(ctx, req)
+	connected := false
+
+	// Trailing whitespace is held back until more text follows, since a
+	// resumed request can't end with it and the continuation repeats it
+	flush := func() {
+		if *held != "" {
+			content.WriteString(*held)
+			out <- StreamChunk{Content: *held}
+			*held = ""
+		}
+	}
+
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				// The provider may have failed just before closing
+				if errs != nil {
+					if err, ok := <-errs; ok && err != nil {
+						return err
+					}
+				}
+				flush()
+				return nil
+			}
+
+			if !connected {
+				connected = true
+				c.setConnectionState(ConnectionConnected)
+			}
+
+			if chunk.Content != "" {
+				text := *held + chunk.Content
+				trimmed := strings.TrimRight(text, " \t\n")
+				*held = text[len(trimmed):]
+				if trimmed != "" {
+					content.WriteString(trimmed)
+					out <- StreamChunk{Content: trimmed}
+				}
+			}
+			if chunk.Done {
+				flush()
+				*final = chunk
+				return nil
+			}
+
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// resumeRequest returns a request that continues after partial content, or
+// the original request if nothing has been received yet
+func resumeRequest(req *ChatRequest, partial string) *ChatRequest {
+	if partial == "" {
+		return req
+	}
+
+	resumed := *req
+	resumed.Messages = append(append([]Message(nil), req.Messages...), Message{
+		Role:      "assistant",
+		Content:   strings.TrimRight(partial, " \t\n"),
+		Timestamp: time.Now(),
+	})
+	return &resumed
+}
+
+// SetRetryConfig replaces the retry configuration
+func (c *Client) SetRetryConfig(config *RetryConfig) {
+	c.retryConfig = config
+}
+
+// SetConnectionStateHandler registers a callback for streaming connection
+// state changes, e.g. to drive a status indicator
+func (c *Client) SetConnectionStateHandler(handler func(ConnectionState)) {
+	c.onConnectionState = handler
+}
+
+func (c *Client) setConnectionState(state ConnectionState) {
+	if c.onConnectionState != nil {
+		c.onConnectionState(state)
+	}
+}
+
 // GetModels returns available models for the current provider
 func (c *Client) GetModels(ctx context.Context) ([]Model, error) {
 	return c.provider.GetModels(ctx)
@@ -385,9 +596,7 @@ func (c *Client) shouldRetry(err error, attempt int) bool {
 	if attempt >= c.retryConfig.MaxRetries {
 		return false
 	}
-
-	// Check for context cancellation
-	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+	if c.retryConfig.SkipRateLimits && ClassifyError(err).Kind == ErrorRateLimit {
 		return false
 	}
 
@@ -398,9 +607,31 @@ func (c *Client) shouldRetry(err error, attempt int) bool {
 				return true
 			}
 		}
+	}
+
+	return IsRetryable(err)
+}
+
+// IsRetryable reports whether a failed request is worth sending again
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// Check for context cancellation
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if apiErr, ok := err.(*APIError); ok {
 		return apiErr.Retryable
 	}
 
+	// A body that ends mid-stream means the connection dropped
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
 	// Retry on network errors
 	return strings.Contains(err.Error(), "connection") ||
 		strings.Contains(err.Error(), "timeout") ||
@@ -446,6 +677,11 @@ func (c *Client) buildRequestMetrics(req *ChatRequest, startTime time.Time) stor
 		lastUserMessage = userMessages[len(userMessages)-1].Content
 	}
 
+	var temperature float64
+	if req.Temperature != nil {
+		temperature = *req.Temperature
+	}
+
 	return storage.RequestMetrics{
 		StartTime:               startTime,
 		ModelID:                 req.Model.ID,
@@ -455,7 +691,7 @@ func (c *Client) buildRequestMetrics(req *ChatRequest, startTime time.Time) stor
 		UserMessageLength:       len(lastUserMessage),
 		TotalConversationLength: totalLength,
 		HasSystemMessage:        systemMessage != "",
-		Temperature:             req.Temperature,
+		Temperature:             temperature,
 		MaxTokens:               req.MaxTokens,
 		IsStream:                req.Stream,
 	}
@@ -519,7 +755,7 @@ func ParseSSEStream(ctx context.Context, body io.ReadCloser, parseFunc func([]by
 				if content, done, err := parseFunc([]byte(data)); err != nil {
 					errorChan <- err
 					return
-				} else if content != "" {
+				} else if content != "" || done {
 					buffer.WriteString(content)
 					chunkChan <- StreamChunk{Content: content, Done: done}
 					if done {
@@ -531,43 +767,106 @@ func ParseSSEStream(ctx context.Context, body io.ReadCloser, parseFunc func([]by
 
 		if err := scanner.Err(); err != nil {
 			errorChan <- fmt.Errorf("stream scanning error: %w", err)
+			return
 		}
+
+		// The body ended without an end-of-stream event
+		errorChan <- fmt.Errorf("stream closed before completion: %w", io.ErrUnexpectedEOF)
 	}()
 
 	return chunkChan, errorChan
 }
 
+// WithFinishReason stamps the final chunk of a parsed stream with reason(),
+// for parse functions that see the stop reason before the end of the stream
+func WithFinishReason(chunks <-chan StreamChunk, reason func() string) <-chan StreamChunk {
+	out := make(chan StreamChunk, cap(chunks))
+	go func() {
+		defer close(out)
+		for chunk := range chunks {
+			if chunk.Done {
+				chunk.FinishReason = NormalizeFinishReason(reason())
+			}
+			out <- chunk
+		}
+	}()
+	return out
+}
+
+// WithUsage stamps the final chunk of a parsed stream with usage(), for
+// parse functions that collect token counts from several events
+func WithUsage(chunks <-chan StreamChunk, usage func() *Usage) <-chan StreamChunk {
+	out := make(chan StreamChunk, cap(chunks))
+	go func() {
+		defer close(out)
+		for chunk := range chunks {
+			if chunk.Done {
+				chunk.Usage = usage()
+			}
+			out <- chunk
+		}
+	}()
+	return out
+}
+
+// ForwardStream relays a parsed stream to a provider's output channels until it
+// completes, fails or ctx is cancelled (exported for provider use)
+func ForwardStream(ctx context.Context, chunks <-chan StreamChunk, errs <-chan error, out chan<- StreamChunk, outErr chan<- error) {
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				// The parser may have failed just before closing
+				if errs != nil {
+					if err, ok := <-errs; ok && err != nil {
+						outErr <- err
+					}
+				}
+				return
+			}
+			out <- chunk
+		case err, ok := <-errs:
+			if !ok {
+				// Keep relaying chunks buffered before the parser finished
+				errs = nil
+				continue
+			}
+			if err != nil {
+				outErr <- err
+			}
+			return
+		case <-ctx.Done():
+			outErr <- ctx.Err()
+			return
+		}
+	}
+}
+
 // ParseErrorResponse extracts error information from an HTTP response (exported for provider use)
 func ParseErrorResponse(resp *http.Response, provider string) error {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Provider:   provider,
+		Retryable:  isRetryableStatusCode(resp.StatusCode),
+		RateLimit:  ParseRateLimitHeaders(resp.Header),
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return &APIError{
-			StatusCode: resp.StatusCode,
-			Message:    fmt.Sprintf("Failed to read error response: %v", err),
-			Provider:   provider,
-			Retryable:  isRetryableStatusCode(resp.StatusCode),
-		}
+		apiErr.Message = fmt.Sprintf("Failed to read error response: %v", err)
+		return apiErr
 	}
 
 	// Try to parse JSON error
 	var errorData map[string]interface{}
 	if json.Unmarshal(body, &errorData) == nil {
-		message := extractErrorMessage(errorData)
-		return &APIError{
-			StatusCode: resp.StatusCode,
-			Message:    message,
-			Provider:   provider,
-			Retryable:  isRetryableStatusCode(resp.StatusCode),
-		}
+		apiErr.Message = extractErrorMessage(errorData)
+		return apiErr
 	}
 
 	// Fallback to plain text
-	return &APIError{
-		StatusCode: resp.StatusCode,
-		Message:    string(body),
-		Provider:   provider,
-		Retryable:  isRetryableStatusCode(resp.StatusCode),
-	}
+	apiErr.Message = string(body)
+	return apiErr
 }
 
 // extractErrorMessage extracts error message from various API error formats
@@ -2,6 +2,10 @@ package api
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -204,6 +208,19 @@ func TestShouldRetry(t *testing.T) {
 	}
 }
 
+func TestShouldRetrySkipsRateLimits(t *testing.T) {
+	config := DefaultRetryConfig()
+	config.SkipRateLimits = true
+	client := &Client{retryConfig: config}
+
+	if client.shouldRetry(&APIError{StatusCode: 429, Message: "Rate limited"}, 0) {
+		t.Error("Expected rate limits to be left to the caller")
+	}
+	if !client.shouldRetry(&APIError{StatusCode: 503, Message: "Service unavailable"}, 0) {
+		t.Error("Expected other retryable errors to still retry")
+	}
+}
+
 func TestMakeHTTPRequest(t *testing.T) {
 	// Create a test server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -352,6 +369,99 @@ func TestParseErrorResponse(t *testing.T) {
 	}
 }
 
+func TestParseRateLimitHeaders(t *testing.T) {
+	header := http.Header{}
+	if limit := ParseRateLimitHeaders(header); limit != nil {
+		t.Errorf("Expected nil without rate limit headers, got %+v", limit)
+	}
+
+	header.Set("x-ratelimit-limit-requests", "50")
+	header.Set("x-ratelimit-remaining-requests", "0")
+	header.Set("Retry-After", "12")
+	limit := ParseRateLimitHeaders(header)
+	if limit == nil {
+		t.Fatal("Expected rate limit from headers")
+	}
+	if limit.Limit != 50 || limit.Remaining != 0 || limit.Used() != 50 {
+		t.Errorf("Expected 50/50 requests used, got %+v", limit)
+	}
+	if limit.RetryAfter != 12*time.Second {
+		t.Errorf("Expected 12s retry-after, got %v", limit.RetryAfter)
+	}
+
+	// Anthropic names its headers differently; retry-after-ms wins
+	header = http.Header{}
+	header.Set("anthropic-ratelimit-requests-limit", "1000")
+	header.Set("anthropic-ratelimit-requests-remaining", "998")
+	header.Set("retry-after-ms", "1500")
+	header.Set("Retry-After", "2")
+	limit = ParseRateLimitHeaders(header)
+	if limit == nil || limit.Used() != 2 || limit.RetryAfter != 1500*time.Millisecond {
+		t.Errorf("Expected 2 used and 1.5s retry-after, got %+v", limit)
+	}
+
+	// Retry-After may be an HTTP date
+	now := time.Now()
+	header = http.Header{}
+	header.Set("Retry-After", now.Add(30*time.Second).UTC().Format(http.TimeFormat))
+	wait, ok := parseRetryAfter(header, now)
+	if !ok || wait <= 28*time.Second || wait > 30*time.Second {
+		t.Errorf("Expected about 30s from an HTTP date, got %v", wait)
+	}
+
+	// Rate-limited error responses carry the parsed headers
+	resp := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header:     http.Header{"Retry-After": []string{"7"}},
+		Body:       io.NopCloser(strings.NewReader(`{"error": {"message": "slow down"}}`)),
+	}
+	var apiErr *APIError
+	if !errors.As(ParseErrorResponse(resp, "test"), &apiErr) || apiErr.RateLimit == nil {
+		t.Fatal("Expected APIError with a rate limit")
+	}
+	if apiErr.RateLimit.RetryAfter != 7*time.Second {
+		t.Errorf("Expected 7s retry-after on the error, got %v", apiErr.RateLimit.RetryAfter)
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusUnauthorized,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(`{"error": {"message": "invalid x-api-key"}}`)),
+	}
+	err := fmt.Errorf("chat failed: %w", ParseErrorResponse(resp, "anthropic"))
+
+	classified := ClassifyError(err)
+	if classified.Kind != ErrorAuth {
+		t.Fatalf("Expected an auth error, got %s", classified.Kind)
+	}
+	if classified.Provider != ProviderAnthropic || classified.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected anthropic 401, got %s %d", classified.Provider, classified.StatusCode)
+	}
+	if !strings.HasPrefix(classified.Message, "Anthropic key invalid or expired") || !strings.Contains(classified.Message, "settings") {
+		t.Errorf("Expected an actionable key message, got %q", classified.Message)
+	}
+
+	tests := []struct {
+		err  error
+		want ErrorKind
+	}{
+		{&APIError{StatusCode: http.StatusForbidden, Provider: "openai"}, ErrorAuth},
+		{&APIError{StatusCode: http.StatusTooManyRequests, Provider: "openai"}, ErrorRateLimit},
+		{&APIError{StatusCode: http.StatusBadGateway, Provider: "openai"}, ErrorServer},
+		{&APIError{StatusCode: http.StatusBadRequest, Provider: "openai"}, ErrorBadRequest},
+		{context.Canceled, ErrorCanceled},
+		{context.DeadlineExceeded, ErrorNetwork},
+		{errors.New("boom"), ErrorUnknown},
+	}
+	for _, tt := range tests {
+		if got := ClassifyError(tt.err).Kind; got != tt.want {
+			t.Errorf("ClassifyError(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
 func TestBuildRequestMetrics(t *testing.T) {
 	client := &Client{}
 	startTime := time.Now()
@@ -368,7 +478,7 @@ func TestBuildRequestMetrics(t *testing.T) {
 			{Role: "assistant", Content: "Hi there!", Timestamp: startTime},
 		},
 		MaxTokens:   1000,
-		Temperature: 0.7,
+		Temperature: ptr(0.7),
 		Stream:      true,
 	}
 
@@ -435,3 +545,213 @@ func BenchmarkShouldRetry(b *testing.B) {
 		client.shouldRetry(err, i%3)
 	}
 }
+
+func TestValidateParameters(t *testing.T) {
+	anthropic := Model{Name: "Claude", Provider: ProviderAnthropic, MaxTokens: 8192}
+	openai := Model{Name: "GPT", Provider: ProviderOpenAI, MaxTokens: 16384}
+
+	tests := []struct {
+		name        string
+		model       Model
+		temperature *float64
+		maxTokens   *int
+		expectError bool
+	}{
+		{"provider defaults", anthropic, nil, nil, false},
+		{"zero temperature", anthropic, ptr(0.0), nil, false},
+		{"anthropic in range", anthropic, ptr(0.7), ptr(4096), false},
+		{"anthropic temperature too high", anthropic, ptr(1.5), nil, true},
+		{"openai accepts higher temperature", openai, ptr(1.5), nil, false},
+		{"openai temperature too high", openai, ptr(2.1), nil, true},
+		{"negative temperature", openai, ptr(-0.1), nil, true},
+		{"max tokens above model limit", anthropic, nil, ptr(10000), true},
+		{"zero max tokens", openai, nil, ptr(0), true},
+		{"negative max tokens", openai, nil, ptr(-1), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateParameters(tt.model, tt.temperature, tt.maxTokens)
+			if tt.expectError && err == nil {
+				t.Errorf("Expected error but got none")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		})
+	}
+}
+
+// sseTransport serves one canned SSE body per request, recording what was sent
+type sseTransport struct {
+	bodies   []string
+	requests []ChatRequest
+}
+
+func (st *sseTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	var req ChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	st.requests = append(st.requests, req)
+
+	body := st.bodies[len(st.requests)-1]
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+	}, nil
+}
+
+// sseProvider streams over HTTP with the shared SSE helpers
+type sseProvider struct {
+	MockProvider
+	httpClient *http.Client
+}
+
+func (p *sseProvider) ChatStream(ctx context.Context, req *ChatRequest) (<-chan StreamChunk, <-chan error) {
+	chunkChan := make(chan StreamChunk, 10)
+	errorChan := make(chan error, 1)
+
+	go func() {
+		defer close(chunkChan)
+		defer close(errorChan)
+
+		body, _ := json.Marshal(req)
+		resp, err := MakeHTTPRequest(ctx, p.httpClient, "POST", "http://provider.test/stream", nil, body)
+		if err != nil {
+			errorChan <- err
+			return
+		}
+
+		parseFunc := func(data []byte) (string, bool, error) {
+			var event struct {
+				Text string `json:"text"`
+				Done bool   `json:"done"`
+			}
+			if err := json.Unmarshal(data, &event); err != nil {
+				return "", false, err
+			}
+			return event.Text, event.Done, nil
+		}
+
+		chunks, errs := ParseSSEStream(ctx, resp.Body, parseFunc)
+		ForwardStream(ctx, chunks, errs, chunkChan, errorChan)
+	}()
+
+	return chunkChan, errorChan
+}
+
+func newReconnectTestClient(transport *sseTransport) (*Client, *[]ConnectionState) {
+	provider := &sseProvider{httpClient: &http.Client{Transport: transport}}
+	client, _ := NewClient(provider, nil)
+	client.retryConfig.BaseDelay = time.Millisecond
+	client.retryConfig.JitterMax = 0
+
+	states := &[]ConnectionState{}
+	client.SetConnectionStateHandler(func(state ConnectionState) {
+		*states = append(*states, state)
+	})
+	return client, states
+}
+
+func collectStream(chunks <-chan StreamChunk, errs <-chan error) (string, error) {
+	var content strings.Builder
+	for chunk := range chunks {
+		content.WriteString(chunk.Content)
+	}
+	return content.String(), <-errs
+}
+
+func TestChatStreamReconnectsAfterEarlyClose(t *testing.T) {
+	transport := &sseTransport{bodies: []string{
+		"data: {\"text\":\"Hel\"}\n\n",
+		"data: {\"text\":\"lo\"}\n\ndata: {\"done\":true}\n\n",
+	}}
+	client, states := newReconnectTestClient(transport)
+
+	req := &ChatRequest{
+		Model:    Model{ID: "claude", Provider: ProviderAnthropic},
+		Messages: []Message{{Role: "user", Content: "hi"}},
+		Stream:   true,
+	}
+	content, err := collectStream(client.ChatStream(context.Background(), req))
+
+	if err != nil {
+		t.Fatalf("Expected stream to recover, got %v", err)
+	}
+	if content != "Hello" {
+		t.Errorf("Expected resumed content %q, got %q", "Hello", content)
+	}
+
+	if len(transport.requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(transport.requests))
+	}
+	resumed := transport.requests[1].Messages
+	if last := resumed[len(resumed)-1]; last.Role != "assistant" || last.Content != "Hel" {
+		t.Errorf("Expected reconnect to resume after partial content, got %+v", last)
+	}
+
+	expected := []ConnectionState{ConnectionConnecting, ConnectionConnected, ConnectionConnecting, ConnectionConnected}
+	if fmt.Sprint(*states) != fmt.Sprint(expected) {
+		t.Errorf("Expected connection states %v, got %v", expected, *states)
+	}
+}
+
+func TestChatStreamResumesWithoutDoublingSpaces(t *testing.T) {
+	transport := &sseTransport{bodies: []string{
+		"data: {\"text\":\"Once upon a \"}\n\n",
+		"data: {\"text\":\" time.\\n\"}\n\ndata: {\"done\":true}\n\n",
+	}}
+	client, _ := newReconnectTestClient(transport)
+
+	req := &ChatRequest{
+		Model:    Model{ID: "claude", Provider: ProviderAnthropic},
+		Messages: []Message{{Role: "user", Content: "Tell me a story"}},
+		Stream:   true,
+	}
+	content, err := collectStream(client.ChatStream(context.Background(), req))
+
+	if err != nil {
+		t.Fatalf("Expected stream to recover, got %v", err)
+	}
+	if content != "Once upon a time.\n" {
+		t.Errorf("Expected joined content %q, got %q", "Once upon a time.\n", content)
+	}
+	resumed := transport.requests[1].Messages
+	if last := resumed[len(resumed)-1]; last.Content != "Once upon a" {
+		t.Errorf("Expected reconnect to resume after %q, got %q", "Once upon a", last.Content)
+	}
+}
+
+func TestChatStreamSurfacesDropWithoutContinuation(t *testing.T) {
+	transport := &sseTransport{bodies: []string{
+		"data: {\"text\":\"Hel\"}\n\n",
+		"data: {\"text\":\"Hello\"}\n\ndata: {\"done\":true}\n\n",
+	}}
+	client, states := newReconnectTestClient(transport)
+
+	req := &ChatRequest{
+		Model:    Model{ID: "gpt-4o", Provider: ProviderOpenAI},
+		Messages: []Message{{Role: "user", Content: "hi"}},
+		Stream:   true,
+	}
+	content, err := collectStream(client.ChatStream(context.Background(), req))
+
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected the dropped connection to be reported, got %v", err)
+	}
+	if content != "Hel" {
+		t.Errorf("Expected partial content %q, got %q", "Hel", content)
+	}
+	if len(transport.requests) != 1 {
+		t.Errorf("Expected no reconnect without continuation, got %d requests", len(transport.requests))
+	}
+	if last := (*states)[len(*states)-1]; last != ConnectionError {
+		t.Errorf("Expected final state to be an error, got %v", last)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
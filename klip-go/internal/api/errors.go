@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// ErrorKind says what went wrong with a provider request, in terms of what
+// the user can do about it
+type ErrorKind int
+
+const (
+	ErrorUnknown ErrorKind = iota
+	ErrorAuth
+	ErrorRateLimit
+	ErrorBadRequest
+	ErrorServer
+	ErrorNetwork
+	ErrorCanceled
+)
+
+// String returns the string representation of ErrorKind
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorAuth:
+		return "auth"
+	case ErrorRateLimit:
+		return "rate_limit"
+	case ErrorBadRequest:
+		return "bad_request"
+	case ErrorServer:
+		return "server"
+	case ErrorNetwork:
+		return "network"
+	case ErrorCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+// ClassifiedError is a provider error with the message to show for it
+type ClassifiedError struct {
+	Kind       ErrorKind
+	Provider   Provider
+	StatusCode int
+
+	// Message tells the user what happened and what to do next
+	Message string
+	Err     error
+}
+
+// ClassifyError maps a failed request to an ErrorKind and an actionable
+// message. Errors from providers carry their status code; anything else
+// is classified as a network or cancellation error where it can be.
+func ClassifyError(err error) ClassifiedError {
+	classified := ClassifiedError{Err: err}
+	if err == nil {
+		return classified
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		classified.Provider = Provider(apiErr.Provider)
+		classified.StatusCode = apiErr.StatusCode
+		name := classified.Provider.DisplayName()
+
+		switch code := apiErr.StatusCode; {
+		case code == http.StatusUnauthorized:
+			classified.Kind = ErrorAuth
+			classified.Message = fmt.Sprintf("%s key invalid or expired · open settings to update it", name)
+		case code == http.StatusForbidden:
+			classified.Kind = ErrorAuth
+			classified.Message = fmt.Sprintf("%s key was refused access · check it in settings", name)
+		case code == http.StatusTooManyRequests:
+			classified.Kind = ErrorRateLimit
+			classified.Message = fmt.Sprintf("%s rate limit reached · wait a moment and retry", name)
+		case code >= 500:
+			classified.Kind = ErrorServer
+			classified.Message = fmt.Sprintf("%s is having trouble (%d) · try again later", name, code)
+		case code >= 400:
+			classified.Kind = ErrorBadRequest
+			classified.Message = fmt.Sprintf("%s rejected the request: %s", name, apiErr.Message)
+		default:
+			classified.Message = apiErr.Error()
+		}
+		return classified
+	}
+
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		classified.Kind = ErrorCanceled
+		classified.Message = "Request canceled"
+	case errors.As(err, &netErr), errors.Is(err, context.DeadlineExceeded):
+		classified.Kind = ErrorNetwork
+		classified.Message = "Can't reach the provider · check your connection and proxy settings"
+	default:
+		classified.Message = err.Error()
+	}
+	return classified
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// gistsURL is the GitHub endpoint for creating gists
+const gistsURL = "https://api.github.com/gists"
+
+// CreateGist uploads content as a single-file secret gist and returns its
+// URL. token must be a GitHub token with the gist scope.
+func CreateGist(ctx context.Context, client *http.Client, token, filename, description, content string) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"description": description,
+		"public":      false,
+		"files": map[string]interface{}{
+			filename: map[string]string{"content": content},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal gist: %w", err)
+	}
+
+	resp, err := MakeHTTPRequest(ctx, client, "POST", gistsURL, map[string]string{
+		"Accept":               "application/vnd.github+json",
+		"Authorization":        "Bearer " + token,
+		"Content-Type":         "application/json",
+		"X-GitHub-Api-Version": "2022-11-28",
+	}, body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", ParseErrorResponse(resp, "github")
+	}
+
+	var gist struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&gist); err != nil {
+		return "", fmt.Errorf("failed to decode gist response: %w", err)
+	}
+	if gist.HTMLURL == "" {
+		return "", fmt.Errorf("gist response did not include a URL")
+	}
+	return gist.HTMLURL, nil
+}
@@ -205,6 +205,12 @@ var PredefinedModels = map[string]Model{
 	},
 }
 
+// ModelFetcher is implemented by providers that can list their models from
+// the provider's models endpoint rather than a built-in list
+type ModelFetcher interface {
+	FetchModels(ctx context.Context) ([]Model, error)
+}
+
 // ModelManager handles model discovery and caching
 type ModelManager struct {
 	providers     map[Provider]ProviderInterface
@@ -212,6 +218,7 @@ type ModelManager struct {
 	cacheExpiry   map[Provider]time.Time
 	cacheMutex    sync.RWMutex
 	cacheDuration time.Duration
+	now           func() time.Time
 }
 
 // NewModelManager creates a new model manager
@@ -221,19 +228,41 @@ func NewModelManager() *ModelManager {
 		cachedModels:  make(map[Provider][]Model),
 		cacheExpiry:   make(map[Provider]time.Time),
 		cacheDuration: 5 * time.Minute,
+		now:           time.Now,
 	}
 }
 
 // RegisterProvider registers a provider with the model manager
 func (mm *ModelManager) RegisterProvider(provider Provider, providerImpl ProviderInterface) {
+	mm.cacheMutex.Lock()
+	defer mm.cacheMutex.Unlock()
+
 	mm.providers[provider] = providerImpl
 }
 
-// GetModelsByProvider returns models for a specific provider
+// SetCacheDuration sets how long fetched model lists are reused; zero or
+// less fetches them every time
+func (mm *ModelManager) SetCacheDuration(duration time.Duration) {
+	mm.cacheMutex.Lock()
+	defer mm.cacheMutex.Unlock()
+
+	mm.cacheDuration = duration
+}
+
+// GetModelsByProvider returns models for a specific provider, falling back
+// to the built-in models when they can't be fetched
 func (mm *ModelManager) GetModelsByProvider(ctx context.Context, provider Provider) ([]Model, error) {
-	// Check cache first
+	models, _ := mm.FetchModels(ctx, provider)
+	return models, nil
+}
+
+// FetchModels lists provider's models, from the cache while it is fresh
+// and otherwise from the provider, filling in what the listing leaves out
+// from the built-in models. When fetching fails it returns the built-in
+// models along with the error.
+func (mm *ModelManager) FetchModels(ctx context.Context, provider Provider) ([]Model, error) {
 	mm.cacheMutex.RLock()
-	if expiry, exists := mm.cacheExpiry[provider]; exists && time.Now().Before(expiry) {
+	if expiry, exists := mm.cacheExpiry[provider]; exists && mm.now().Before(expiry) {
 		if models, exists := mm.cachedModels[provider]; exists {
 			result := make([]Model, len(models))
 			copy(result, models)
@@ -241,27 +270,56 @@ func (mm *ModelManager) GetModelsByProvider(ctx context.Context, provider Provid
 			return result, nil
 		}
 	}
+	providerImpl, exists := mm.providers[provider]
 	mm.cacheMutex.RUnlock()
 
-	// Fetch from provider
-	providerImpl, exists := mm.providers[provider]
 	if !exists {
-		return mm.getStaticModelsByProvider(provider), nil
+		return mm.getStaticModelsByProvider(provider), fmt.Errorf("no client for provider %s", provider)
 	}
 
-	models, err := providerImpl.GetModels(ctx)
+	var models []Model
+	var err error
+	if fetcher, ok := providerImpl.(ModelFetcher); ok {
+		models, err = fetcher.FetchModels(ctx)
+	} else {
+		models, err = providerImpl.GetModels(ctx)
+	}
 	if err != nil {
-		// Fallback to static models on error
-		return mm.getStaticModelsByProvider(provider), nil
+		return mm.getStaticModelsByProvider(provider), err
 	}
+	models = mergeKnownModels(models)
 
-	// Update cache
 	mm.cacheMutex.Lock()
-	mm.cachedModels[provider] = models
-	mm.cacheExpiry[provider] = time.Now().Add(mm.cacheDuration)
+	if mm.cacheDuration > 0 {
+		mm.cachedModels[provider] = models
+		mm.cacheExpiry[provider] = mm.now().Add(mm.cacheDuration)
+	}
 	mm.cacheMutex.Unlock()
 
-	return models, nil
+	result := make([]Model, len(models))
+	copy(result, models)
+	return result, nil
+}
+
+// mergeKnownModels fills the name and limits of listed models from
+// PredefinedModels where the provider's listing leaves them out
+func mergeKnownModels(models []Model) []Model {
+	for i, model := range models {
+		known, ok := PredefinedModels[model.ID]
+		if !ok {
+			continue
+		}
+		if model.Name == "" || model.Name == model.ID {
+			models[i].Name = known.Name
+		}
+		if model.MaxTokens == 0 {
+			models[i].MaxTokens = known.MaxTokens
+		}
+		if model.ContextWindow == 0 {
+			models[i].ContextWindow = known.ContextWindow
+		}
+	}
+	return models
 }
 
 // GetAllModels returns all available models from all providers
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 )
@@ -397,3 +398,66 @@ func BenchmarkModelManagerGetModelsByProvider(b *testing.B) {
 		_, _ = mm.GetModelsByProvider(ctx, ProviderAnthropic)
 	}
 }
+
+// fetchingProvider lists models from a fake models endpoint, counting fetches
+type fetchingProvider struct {
+	MockProvider
+	fetches int
+	err     error
+}
+
+func (p *fetchingProvider) FetchModels(ctx context.Context) ([]Model, error) {
+	p.fetches++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []Model{{ID: "claude-3-5-haiku-20241022", Name: "claude-3-5-haiku-20241022", Provider: ProviderAnthropic}}, nil
+}
+
+func TestModelManagerFetchModelsCacheTTL(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	mm := NewModelManager()
+	mm.now = func() time.Time { return now }
+	mm.SetCacheDuration(10 * time.Minute)
+
+	provider := &fetchingProvider{}
+	mm.RegisterProvider(ProviderAnthropic, provider)
+	ctx := context.Background()
+
+	models, err := mm.FetchModels(ctx, ProviderAnthropic)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(models) != 1 || models[0].Name != "Claude 3.5 Haiku" || models[0].ContextWindow == 0 {
+		t.Errorf("Expected the listed model merged with its known details, got %+v", models)
+	}
+
+	// Within the TTL the cached list is reused
+	now = now.Add(9 * time.Minute)
+	if _, err := mm.FetchModels(ctx, ProviderAnthropic); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if provider.fetches != 1 {
+		t.Errorf("Expected 1 fetch within the TTL, got %d", provider.fetches)
+	}
+
+	// After it expires the list is fetched again
+	now = now.Add(2 * time.Minute)
+	if _, err := mm.FetchModels(ctx, ProviderAnthropic); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if provider.fetches != 2 {
+		t.Errorf("Expected a refetch after the TTL, got %d fetches", provider.fetches)
+	}
+
+	// Clearing the cache forces a fetch, and failures fall back to the built-in list
+	mm.ClearCache()
+	provider.err = fmt.Errorf("unavailable")
+	models, err = mm.FetchModels(ctx, ProviderAnthropic)
+	if err == nil {
+		t.Errorf("Expected the fetch error to be reported")
+	}
+	if provider.fetches != 3 || len(models) == 0 {
+		t.Errorf("Expected a fetch and built-in models, got %d fetches and %d models", provider.fetches, len(models))
+	}
+}
@@ -36,23 +36,79 @@ func NewAnthropicProvider(apiKey string, httpClient *http.Client) (api.ProviderI
 	}, nil
 }
 
+// setBaseURL overrides the API root used for every request
+func (p *AnthropicProvider) setBaseURL(baseURL string) {
+	p.baseURL = baseURL
+}
+
 // AnthropicRequest represents the request format for Anthropic API
 type AnthropicRequest struct {
-	Model     string             `json:"model"`
-	MaxTokens int                `json:"max_tokens"`
-	Messages  []AnthropicMessage `json:"messages"`
-	System    string             `json:"system,omitempty"`
-	Stream    bool               `json:"stream,omitempty"`
-	Tools     []AnthropicTool    `json:"tools,omitempty"`
-	Metadata  *AnthropicMetadata `json:"metadata,omitempty"`
+	Model       string             `json:"model"`
+	MaxTokens   int                `json:"max_tokens"`
+	Messages    []AnthropicMessage `json:"messages"`
+	System      string             `json:"system,omitempty"`
+	Temperature *float64           `json:"temperature,omitempty"`
+	Stream      bool               `json:"stream,omitempty"`
+	Tools       []AnthropicTool    `json:"tools,omitempty"`
+	Metadata    *AnthropicMetadata `json:"metadata,omitempty"`
+
+	// SystemCacheControl marks the system prompt as a prompt caching
+	// breakpoint, sending it as a text block
+	SystemCacheControl *AnthropicCacheControl `json:"-"`
+}
+
+// MarshalJSON sends the system prompt as a text block when it carries a
+// cache breakpoint, and as a plain string otherwise
+func (r AnthropicRequest) MarshalJSON() ([]byte, error) {
+	type plain AnthropicRequest
+	if r.SystemCacheControl == nil || r.System == "" {
+		return json.Marshal(plain(r))
+	}
+	return json.Marshal(struct {
+		plain
+		System []AnthropicTextBlock `json:"system"`
+	}{plain(r), []AnthropicTextBlock{{Type: "text", Text: r.System, CacheControl: r.SystemCacheControl}}})
 }
 
 // AnthropicMessage represents a message in Anthropic format
 type AnthropicMessage struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
+
+	// CacheControl marks the message as a prompt caching breakpoint,
+	// sending its content as a text block
+	CacheControl *AnthropicCacheControl `json:"-"`
 }
 
+// MarshalJSON sends the content as a text block when the message carries a
+// cache breakpoint, and as a plain string otherwise
+func (m AnthropicMessage) MarshalJSON() ([]byte, error) {
+	type plain AnthropicMessage
+	if m.CacheControl == nil {
+		return json.Marshal(plain(m))
+	}
+	return json.Marshal(struct {
+		plain
+		Content []AnthropicTextBlock `json:"content"`
+	}{plain(m), []AnthropicTextBlock{{Type: "text", Text: m.Content, CacheControl: m.CacheControl}}})
+}
+
+// AnthropicTextBlock is a text content block of a request
+type AnthropicTextBlock struct {
+	Type         string                 `json:"type"`
+	Text         string                 `json:"text"`
+	CacheControl *AnthropicCacheControl `json:"cache_control,omitempty"`
+}
+
+// AnthropicCacheControl marks the end of a prompt prefix to cache
+type AnthropicCacheControl struct {
+	Type string `json:"type"`
+}
+
+// ephemeralCache is the cache breakpoint Anthropic supports, kept for a
+// few minutes after its last use
+var ephemeralCache = &AnthropicCacheControl{Type: "ephemeral"}
+
 // AnthropicTool represents a tool definition for Anthropic
 type AnthropicTool struct {
 	Type    string `json:"type"`
@@ -85,8 +141,20 @@ type AnthropicContent struct {
 
 // AnthropicUsage represents usage information from Anthropic
 type AnthropicUsage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens,omitempty"`
+}
+
+// toUsage converts Anthropic usage to the common format
+func (u AnthropicUsage) toUsage() *api.Usage {
+	return &api.Usage{
+		InputTokens:              u.InputTokens,
+		OutputTokens:             u.OutputTokens,
+		CacheReadInputTokens:     u.CacheReadInputTokens,
+		CacheCreationInputTokens: u.CacheCreationInputTokens,
+	}
 }
 
 // AnthropicStreamEvent represents a streaming event from Anthropic
@@ -100,8 +168,9 @@ type AnthropicStreamEvent struct {
 
 // AnthropicStreamDelta represents delta content in streaming
 type AnthropicStreamDelta struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+	Type       string `json:"type"`
+	Text       string `json:"text"`
+	StopReason string `json:"stop_reason,omitempty"`
 }
 
 // Chat sends a non-streaming chat request to Anthropic
@@ -135,7 +204,9 @@ func (p *AnthropicProvider) Chat(ctx context.Context, req *api.ChatRequest) (*ap
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return p.parseAnthropicResponse(&anthropicResp), nil
+	response := p.parseAnthropicResponse(&anthropicResp)
+	response.RateLimit = api.ParseRateLimitHeaders(resp.Header)
+	return response, nil
 }
 
 // ChatStream sends a streaming chat request to Anthropic
@@ -174,7 +245,11 @@ func (p *AnthropicProvider) ChatStream(ctx context.Context, req *api.ChatRequest
 			return
 		}
 
-		// Parse the streaming response
+		// Parse the streaming response; the stop reason arrives in the
+		// message_delta event just before message_stop. Input token counts
+		// come with message_start, the output count with message_delta.
+		var stopReason string
+		var usage *api.Usage
 		parseFunc := func(data []byte) (string, bool, error) {
 			var event AnthropicStreamEvent
 			if err := json.Unmarshal(data, &event); err != nil {
@@ -187,6 +262,17 @@ func (p *AnthropicProvider) ChatStream(ctx context.Context, req *api.ChatRequest
 				if event.Delta != nil && event.Delta.Type == "text_delta" {
 					return event.Delta.Text, false, nil
 				}
+			case "message_start":
+				if event.Message != nil {
+					usage = event.Message.Usage.toUsage()
+				}
+			case "message_delta":
+				if event.Delta != nil && event.Delta.StopReason != "" {
+					stopReason = event.Delta.StopReason
+				}
+				if event.Usage != nil && usage != nil {
+					usage.OutputTokens = event.Usage.OutputTokens
+				}
 			case "message_stop":
 				return "", true, nil
 			}
@@ -195,29 +281,54 @@ func (p *AnthropicProvider) ChatStream(ctx context.Context, req *api.ChatRequest
 		}
 
 		streamChunkChan, streamErrorChan := api.ParseSSEStream(ctx, resp.Body, parseFunc)
+		streamChunkChan = api.WithFinishReason(streamChunkChan, func() string { return stopReason })
+		streamChunkChan = api.WithUsage(streamChunkChan, func() *api.Usage { return usage })
+		streamChunkChan = api.WithRateLimit(streamChunkChan, api.ParseRateLimitHeaders(resp.Header))
 
-		for {
-			select {
-			case chunk, ok := <-streamChunkChan:
-				if !ok {
-					return
-				}
-				chunkChan <- chunk
-			case err := <-streamErrorChan:
-				if err != nil {
-					errorChan <- err
-				}
-				return
-			case <-ctx.Done():
-				errorChan <- ctx.Err()
-				return
-			}
-		}
+		api.ForwardStream(ctx, streamChunkChan, streamErrorChan, chunkChan, errorChan)
 	}()
 
 	return chunkChan, errorChan
 }
 
+// AnthropicModelsResponse is a page of the models endpoint
+type AnthropicModelsResponse struct {
+	Data []struct {
+		ID          string `json:"id"`
+		DisplayName string `json:"display_name"`
+	} `json:"data"`
+}
+
+// FetchModels lists the models the API key can use from the models endpoint
+func (p *AnthropicProvider) FetchModels(ctx context.Context) ([]api.Model, error) {
+	resp, err := api.MakeHTTPRequest(ctx, p.httpClient, "GET", p.baseURL+"/models?limit=1000", p.headers, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, api.ParseErrorResponse(resp, "anthropic")
+	}
+
+	var modelsResp AnthropicModelsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&modelsResp); err != nil {
+		return nil, fmt.Errorf("failed to decode models response: %w", err)
+	}
+
+	models := make([]api.Model, 0, len(modelsResp.Data))
+	for _, model := range modelsResp.Data {
+		name := model.DisplayName
+		if name == "" {
+			name = model.ID
+		}
+		models = append(models, api.Model{ID: model.ID, Name: name, Provider: api.ProviderAnthropic})
+	}
+
+	builtin, _ := p.GetModels(ctx)
+	return withKnownLimits(models, builtin), nil
+}
+
 // GetModels returns available Anthropic models
 func (p *AnthropicProvider) GetModels(ctx context.Context) ([]api.Model, error) {
 	return []api.Model{
@@ -290,13 +401,28 @@ func (p *AnthropicProvider) ValidateCredentials(ctx context.Context) error {
 	return nil
 }
 
+// checkConnection lists a single model, the cheapest authenticated request
+func (p *AnthropicProvider) checkConnection(ctx context.Context) error {
+	resp, err := api.MakeHTTPRequest(ctx, p.httpClient, "GET", p.baseURL+"/models?limit=1", p.headers, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return api.ParseErrorResponse(resp, "anthropic")
+	}
+	return nil
+}
+
 // buildAnthropicRequest converts a ChatRequest to Anthropic format
 func (p *AnthropicProvider) buildAnthropicRequest(req *api.ChatRequest, stream bool) *AnthropicRequest {
 	anthropicReq := &AnthropicRequest{
-		Model:     req.Model.ID,
-		MaxTokens: req.MaxTokens,
-		Stream:    stream,
-		Messages:  make([]AnthropicMessage, 0),
+		Model:       req.Model.ID,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		Stream:      stream,
+		Messages:    make([]AnthropicMessage, 0),
 	}
 
 	// Set default max tokens if not specified
@@ -319,6 +445,17 @@ func (p *AnthropicProvider) buildAnthropicRequest(req *api.ChatRequest, stream b
 		}
 	}
 
+	// Cache the system prompt and everything up to the latest message, so
+	// the next turn only pays full price for what it adds
+	if req.PromptCaching {
+		if anthropicReq.System != "" {
+			anthropicReq.SystemCacheControl = ephemeralCache
+		}
+		if last := len(anthropicReq.Messages) - 1; last >= 0 {
+			anthropicReq.Messages[last].CacheControl = ephemeralCache
+		}
+	}
+
 	// Add web search tool if enabled
 	if req.EnableWebSearch {
 		anthropicReq.Tools = []AnthropicTool{
@@ -340,14 +477,10 @@ func (p *AnthropicProvider) parseAnthropicResponse(resp *AnthropicResponse) *api
 		content = resp.Content[0].Text
 	}
 
-	usage := &api.Usage{
-		InputTokens:  resp.Usage.InputTokens,
-		OutputTokens: resp.Usage.OutputTokens,
-	}
-
 	return &api.ChatResponse{
-		Content: content,
-		Usage:   usage,
+		Content:      content,
+		Usage:        resp.Usage.toUsage(),
+		FinishReason: api.NormalizeFinishReason(resp.StopReason),
 	}
 }
 
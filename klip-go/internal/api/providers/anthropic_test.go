@@ -2,8 +2,10 @@ package providers
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -171,6 +173,120 @@ func TestAnthropicBuildRequest(t *testing.T) {
 	}
 }
 
+func TestBuildRequestSendsZeroTemperature(t *testing.T) {
+	zero := 0.0
+	req := &api.ChatRequest{
+		Model:       api.Model{ID: "claude-3-5-sonnet-20241022", MaxTokens: 1000},
+		Messages:    []api.Message{{Role: "user", Content: "Hello"}},
+		Temperature: &zero,
+	}
+
+	anthropicProvider, _ := NewAnthropicProvider("test-key", &http.Client{})
+	openaiProvider, _ := NewOpenAIProvider("test-key", &http.Client{})
+	bodies := map[string]any{
+		"anthropic": anthropicProvider.(*AnthropicProvider).buildAnthropicRequest(req, false),
+		"openai":    openaiProvider.(*OpenAIProvider).buildOpenAIRequest(req, false),
+	}
+	for name, body := range bodies {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("Failed to marshal %s request: %v", name, err)
+		}
+		if !strings.Contains(string(data), `"temperature":0,`) && !strings.Contains(string(data), `"temperature":0}`) {
+			t.Errorf("Expected %s request to send temperature 0, got %s", name, data)
+		}
+	}
+
+	// Unset, OpenAI falls back to its default and Anthropic omits it
+	req.Temperature = nil
+	openaiReq := openaiProvider.(*OpenAIProvider).buildOpenAIRequest(req, false)
+	if openaiReq.Temperature == nil || *openaiReq.Temperature != 0.7 {
+		t.Errorf("Expected default OpenAI temperature 0.7, got %v", openaiReq.Temperature)
+	}
+	if anthropicReq := anthropicProvider.(*AnthropicProvider).buildAnthropicRequest(req, false); anthropicReq.Temperature != nil {
+		t.Errorf("Expected Anthropic temperature to be omitted, got %v", *anthropicReq.Temperature)
+	}
+}
+
+func TestAnthropicPromptCaching(t *testing.T) {
+	provider, err := NewAnthropicProvider("test-key", &http.Client{})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	anthProvider := provider.(*AnthropicProvider)
+
+	req := &api.ChatRequest{
+		Model: api.Model{ID: "claude-3-5-sonnet-20241022"},
+		Messages: []api.Message{
+			{Role: "system", Content: "You are a helpful assistant"},
+			{Role: "user", Content: "Hello"},
+			{Role: "assistant", Content: "Hi!"},
+			{Role: "user", Content: "How are you?"},
+		},
+	}
+	marshal := func() map[string]interface{} {
+		body, err := json.Marshal(anthProvider.buildAnthropicRequest(req, false))
+		if err != nil {
+			t.Fatalf("Failed to marshal request: %v", err)
+		}
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(body, &decoded); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		return decoded
+	}
+	// cacheType returns the cache_control type of content sent as a block
+	cacheType := func(content interface{}) interface{} {
+		blocks, ok := content.([]interface{})
+		if !ok || len(blocks) != 1 {
+			return nil
+		}
+		control, _ := blocks[0].(map[string]interface{})["cache_control"].(map[string]interface{})
+		return control["type"]
+	}
+
+	// Without caching the system prompt and messages are plain strings
+	decoded := marshal()
+	if decoded["system"] != "You are a helpful assistant" {
+		t.Errorf("Expected a plain system prompt, got %v", decoded["system"])
+	}
+	for _, message := range decoded["messages"].([]interface{}) {
+		if _, ok := message.(map[string]interface{})["content"].(string); !ok {
+			t.Errorf("Expected plain message content, got %v", message)
+		}
+	}
+
+	// With caching the system prompt and the latest message are breakpoints
+	req.PromptCaching = true
+	decoded = marshal()
+	if cacheType(decoded["system"]) != "ephemeral" {
+		t.Errorf("Expected the system prompt to carry cache_control, got %v", decoded["system"])
+	}
+	messages := decoded["messages"].([]interface{})
+	last := messages[len(messages)-1].(map[string]interface{})
+	if cacheType(last["content"]) != "ephemeral" {
+		t.Errorf("Expected the latest message to carry cache_control, got %v", last)
+	}
+	if blocks := last["content"].([]interface{}); blocks[0].(map[string]interface{})["text"] != "How are you?" {
+		t.Errorf("Expected the message text in its block, got %v", blocks)
+	}
+	if _, ok := messages[0].(map[string]interface{})["content"].(string); !ok {
+		t.Errorf("Expected earlier messages to stay plain, got %v", messages[0])
+	}
+
+	// Cache token counts are reported with the usage
+	chatResp := anthProvider.parseAnthropicResponse(&AnthropicResponse{
+		Content: []AnthropicContent{{Type: "text", Text: "Fine"}},
+		Usage:   AnthropicUsage{InputTokens: 5, OutputTokens: 2, CacheReadInputTokens: 2000, CacheCreationInputTokens: 12},
+	})
+	if chatResp.Usage.CacheReadInputTokens != 2000 || chatResp.Usage.CacheCreationInputTokens != 12 {
+		t.Errorf("Expected cache token counts in usage, got %+v", chatResp.Usage)
+	}
+	if chatResp.Usage.TotalInputTokens() != 2017 {
+		t.Errorf("Expected 2017 input tokens in total, got %d", chatResp.Usage.TotalInputTokens())
+	}
+}
+
 func TestAnthropicParseResponse(t *testing.T) {
 	httpClient := &http.Client{}
 	provider, err := NewAnthropicProvider("test-key", httpClient)
@@ -331,3 +447,44 @@ func TestAnthropicErrorHandling(t *testing.T) {
 		t.Errorf("Expected APIError, got %T", err)
 	}
 }
+
+func TestAnthropicStreamReportsLengthFinish(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("data: {\"type\":\"content_block_delta\",\"delta\":{\"type\":\"text_delta\",\"text\":\"The answer is\"}}\n\n" +
+			"data: {\"type\":\"message_delta\",\"delta\":{\"stop_reason\":\"max_tokens\"}}\n\n" +
+			"data: {\"type\":\"message_stop\"}\n\n"))
+	}))
+	defer server.Close()
+
+	provider, err := NewAnthropicProvider("test-key", &http.Client{})
+	if err != nil {
+		t.Fatalf("Failed to create provider: %v", err)
+	}
+	provider.(*AnthropicProvider).baseURL = server.URL
+
+	req := &api.ChatRequest{
+		Model:    api.Model{ID: "claude-3-5-haiku-20241022"},
+		Messages: []api.Message{{Role: "user", Content: "Hello"}},
+	}
+	chunks, errs := provider.ChatStream(context.Background(), req)
+
+	var content, finishReason string
+	for chunk := range chunks {
+		content += chunk.Content
+		if chunk.Done {
+			finishReason = chunk.FinishReason
+		}
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if content != "The answer is" {
+		t.Errorf("Expected streamed content, got %q", content)
+	}
+	if finishReason != api.FinishReasonLength {
+		t.Errorf("Expected finish reason %q, got %q", api.FinishReasonLength, finishReason)
+	}
+}
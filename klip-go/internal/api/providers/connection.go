@@ -0,0 +1,102 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/john/klip/internal/api"
+)
+
+// ConnectionStatus classifies the outcome of a connection test
+type ConnectionStatus int
+
+const (
+	ConnectionOK ConnectionStatus = iota
+	ConnectionMissingKey
+	ConnectionInvalidKey
+	ConnectionRateLimited
+	ConnectionNetworkError
+	ConnectionFailed
+)
+
+// ConnectionResult is the outcome of testing a provider's API key
+type ConnectionResult struct {
+	Provider api.Provider
+	Status   ConnectionStatus
+	Latency  time.Duration
+	Err      error
+}
+
+// Message describes the result in a few words for display next to the key
+func (r ConnectionResult) Message() string {
+	switch r.Status {
+	case ConnectionOK:
+		return fmt.Sprintf("Connected (%dms)", r.Latency.Milliseconds())
+	case ConnectionMissingKey:
+		return "No API key"
+	case ConnectionInvalidKey:
+		return "Invalid API key"
+	case ConnectionRateLimited:
+		return "Rate limited, the key works but try again later"
+	case ConnectionNetworkError:
+		return fmt.Sprintf("Network error: %v", r.Err)
+	default:
+		return fmt.Sprintf("Failed: %v", r.Err)
+	}
+}
+
+// connectionChecker is implemented by providers that can verify their key
+// without running a completion
+type connectionChecker interface {
+	checkConnection(ctx context.Context) error
+}
+
+// CheckConnection verifies apiKey with a minimal authenticated request to the
+// provider, through baseURL when it is set
+func CheckConnection(ctx context.Context, providerType api.Provider, apiKey, baseURL string, httpClient *http.Client) ConnectionResult {
+	result := ConnectionResult{Provider: providerType}
+	if apiKey == "" {
+		result.Status = ConnectionMissingKey
+		return result
+	}
+
+	provider, err := NewProvider(providerType, apiKey, httpClient)
+	if err == nil {
+		err = SetBaseURL(provider, baseURL)
+	}
+	if err != nil {
+		result.Status = ConnectionFailed
+		result.Err = err
+		return result
+	}
+
+	start := time.Now()
+	if checker, ok := provider.(connectionChecker); ok {
+		err = checker.checkConnection(ctx)
+	} else {
+		err = provider.ValidateCredentials(ctx)
+	}
+	result.Latency = time.Since(start)
+	result.Status = classifyConnectionError(err)
+	result.Err = err
+	return result
+}
+
+// classifyConnectionError maps a request error to a connection status
+func classifyConnectionError(err error) ConnectionStatus {
+	if err == nil {
+		return ConnectionOK
+	}
+
+	switch api.ClassifyError(err).Kind {
+	case api.ErrorAuth:
+		return ConnectionInvalidKey
+	case api.ErrorRateLimit:
+		return ConnectionRateLimited
+	case api.ErrorNetwork:
+		return ConnectionNetworkError
+	}
+	return ConnectionFailed
+}
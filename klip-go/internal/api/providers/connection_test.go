@@ -0,0 +1,95 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/john/klip/internal/api"
+)
+
+// roundTripFunc fakes an HTTP transport
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func fakeClient(status int, body string) (*http.Client, *[]string) {
+	var paths []string
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.URL.Path)
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})}
+	return client, &paths
+}
+
+func TestCheckConnectionInvalidKey(t *testing.T) {
+	for _, provider := range GetAllProviders() {
+		t.Run(string(provider), func(t *testing.T) {
+			client, _ := fakeClient(http.StatusUnauthorized, `{"error":{"message":"invalid x-api-key"}}`)
+
+			result := CheckConnection(context.Background(), provider, "bad-key", "", client)
+			if result.Status != ConnectionInvalidKey {
+				t.Fatalf("Expected ConnectionInvalidKey, got %d (%v)", result.Status, result.Err)
+			}
+			if result.Message() != "Invalid API key" {
+				t.Errorf("Expected an invalid key message, got %q", result.Message())
+			}
+		})
+	}
+}
+
+func TestCheckConnectionUsesLightweightEndpoint(t *testing.T) {
+	tests := []struct {
+		provider api.Provider
+		path     string
+	}{
+		{api.ProviderAnthropic, "/proxy/models"},
+		{api.ProviderOpenAI, "/proxy/models"},
+		{api.ProviderOpenRouter, "/proxy/key"},
+	}
+
+	for _, tt := range tests {
+		client, paths := fakeClient(http.StatusOK, `{}`)
+
+		result := CheckConnection(context.Background(), tt.provider, "key", "https://gateway.example.com/proxy", client)
+		if result.Status != ConnectionOK {
+			t.Fatalf("%s: expected ConnectionOK, got %q", tt.provider, result.Message())
+		}
+		if len(*paths) != 1 || (*paths)[0] != tt.path {
+			t.Errorf("%s: expected one request to %s, got %v", tt.provider, tt.path, *paths)
+		}
+	}
+}
+
+func TestCheckConnectionClassifiesErrors(t *testing.T) {
+	client, _ := fakeClient(http.StatusTooManyRequests, `{"error":{"message":"slow down"}}`)
+	if result := CheckConnection(context.Background(), api.ProviderOpenAI, "key", "", client); result.Status != ConnectionRateLimited {
+		t.Errorf("Expected ConnectionRateLimited for 429, got %q", result.Message())
+	}
+
+	client, _ = fakeClient(http.StatusInternalServerError, `{"error":{"message":"boom"}}`)
+	if result := CheckConnection(context.Background(), api.ProviderOpenAI, "key", "", client); result.Status != ConnectionFailed {
+		t.Errorf("Expected ConnectionFailed for 500, got %q", result.Message())
+	}
+
+	offline := &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})}
+	if result := CheckConnection(context.Background(), api.ProviderOpenAI, "key", "", offline); result.Status != ConnectionNetworkError {
+		t.Errorf("Expected ConnectionNetworkError, got %q", result.Message())
+	}
+
+	if result := CheckConnection(context.Background(), api.ProviderOpenAI, "", "", offline); result.Status != ConnectionMissingKey {
+		t.Errorf("Expected ConnectionMissingKey without a key, got %q", result.Message())
+	}
+}
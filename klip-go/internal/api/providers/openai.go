@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/john/klip/internal/api"
@@ -35,12 +37,17 @@ func NewOpenAIProvider(apiKey string, httpClient *http.Client) (api.ProviderInte
 	}, nil
 }
 
+// setBaseURL overrides the API root used for every request
+func (p *OpenAIProvider) setBaseURL(baseURL string) {
+	p.baseURL = baseURL
+}
+
 // OpenAIRequest represents the request format for OpenAI API
 type OpenAIRequest struct {
 	Model        string           `json:"model"`
 	Messages     []OpenAIMessage  `json:"messages"`
 	MaxTokens    int              `json:"max_tokens,omitempty"`
-	Temperature  float64          `json:"temperature,omitempty"`
+	Temperature  *float64         `json:"temperature,omitempty"`
 	Stream       bool             `json:"stream,omitempty"`
 	Functions    []OpenAIFunction `json:"functions,omitempty"`
 	FunctionCall interface{}      `json:"function_call,omitempty"`
@@ -151,7 +158,9 @@ func (p *OpenAIProvider) Chat(ctx context.Context, req *api.ChatRequest) (*api.C
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return p.parseOpenAIResponse(&openaiResp), nil
+	response := p.parseOpenAIResponse(&openaiResp)
+	response.RateLimit = api.ParseRateLimitHeaders(resp.Header)
+	return response, nil
 }
 
 // ChatStream sends a streaming chat request to OpenAI
@@ -191,6 +200,7 @@ func (p *OpenAIProvider) ChatStream(ctx context.Context, req *api.ChatRequest) (
 		}
 
 		// Parse the streaming response
+		var finishReason string
 		parseFunc := func(data []byte) (string, bool, error) {
 			var event OpenAIStreamEvent
 			if err := json.Unmarshal(data, &event); err != nil {
@@ -204,6 +214,7 @@ func (p *OpenAIProvider) ChatStream(ctx context.Context, req *api.ChatRequest) (
 					return choice.Delta.Content, false, nil
 				}
 				if choice.FinishReason != "" {
+					finishReason = choice.FinishReason
 					return "", true, nil
 				}
 			}
@@ -212,29 +223,73 @@ func (p *OpenAIProvider) ChatStream(ctx context.Context, req *api.ChatRequest) (
 		}
 
 		streamChunkChan, streamErrorChan := api.ParseSSEStream(ctx, resp.Body, parseFunc)
+		streamChunkChan = api.WithFinishReason(streamChunkChan, func() string { return finishReason })
+		streamChunkChan = api.WithRateLimit(streamChunkChan, api.ParseRateLimitHeaders(resp.Header))
 
-		for {
-			select {
-			case chunk, ok := <-streamChunkChan:
-				if !ok {
-					return
-				}
-				chunkChan <- chunk
-			case err := <-streamErrorChan:
-				if err != nil {
-					errorChan <- err
-				}
-				return
-			case <-ctx.Done():
-				errorChan <- ctx.Err()
-				return
-			}
-		}
+		api.ForwardStream(ctx, streamChunkChan, streamErrorChan, chunkChan, errorChan)
 	}()
 
 	return chunkChan, errorChan
 }
 
+// OpenAIModelsResponse is the response of the models endpoint
+type OpenAIModelsResponse struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
+
+// openAIChatPrefixes select chat models from the models endpoint, which
+// also lists embedding, audio and image models
+var openAIChatPrefixes = []string{"gpt-", "chatgpt-", "o1", "o3", "o4"}
+
+// openAINonChatMarkers exclude specialized variants of chat model families
+var openAINonChatMarkers = []string{"audio", "realtime", "transcribe", "tts", "image", "search", "instruct"}
+
+// FetchModels lists the chat models the API key can use from the models endpoint
+func (p *OpenAIProvider) FetchModels(ctx context.Context) ([]api.Model, error) {
+	resp, err := api.MakeHTTPRequest(ctx, p.httpClient, "GET", p.baseURL+"/models", p.headers, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, api.ParseErrorResponse(resp, "openai")
+	}
+
+	var modelsResp OpenAIModelsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&modelsResp); err != nil {
+		return nil, fmt.Errorf("failed to decode models response: %w", err)
+	}
+
+	models := make([]api.Model, 0, len(modelsResp.Data))
+	for _, model := range modelsResp.Data {
+		if isOpenAIChatModel(model.ID) {
+			models = append(models, api.Model{ID: model.ID, Name: model.ID, Provider: api.ProviderOpenAI})
+		}
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+
+	builtin, _ := p.GetModels(ctx)
+	return withKnownLimits(models, builtin), nil
+}
+
+// isOpenAIChatModel reports whether id names a model the chat API accepts
+func isOpenAIChatModel(id string) bool {
+	for _, marker := range openAINonChatMarkers {
+		if strings.Contains(id, marker) {
+			return false
+		}
+	}
+	for _, prefix := range openAIChatPrefixes {
+		if strings.HasPrefix(id, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetModels returns available OpenAI models
 func (p *OpenAIProvider) GetModels(ctx context.Context) ([]api.Model, error) {
 	return []api.Model{
@@ -321,6 +376,20 @@ func (p *OpenAIProvider) ValidateCredentials(ctx context.Context) error {
 	return nil
 }
 
+// checkConnection lists the models available to the key
+func (p *OpenAIProvider) checkConnection(ctx context.Context) error {
+	resp, err := api.MakeHTTPRequest(ctx, p.httpClient, "GET", p.baseURL+"/models", p.headers, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return api.ParseErrorResponse(resp, "openai")
+	}
+	return nil
+}
+
 // buildOpenAIRequest converts a ChatRequest to OpenAI format
 func (p *OpenAIProvider) buildOpenAIRequest(req *api.ChatRequest, stream bool) *OpenAIRequest {
 	openaiReq := &OpenAIRequest{
@@ -338,8 +407,9 @@ func (p *OpenAIProvider) buildOpenAIRequest(req *api.ChatRequest, stream bool) *
 			openaiReq.MaxTokens = 4096
 		}
 	}
-	if openaiReq.Temperature == 0 {
-		openaiReq.Temperature = 0.7
+	if openaiReq.Temperature == nil {
+		temperature := 0.7
+		openaiReq.Temperature = &temperature
 	}
 
 	// Convert messages
@@ -355,9 +425,10 @@ func (p *OpenAIProvider) buildOpenAIRequest(req *api.ChatRequest, stream bool) *
 
 // parseOpenAIResponse converts OpenAI response to ChatResponse
 func (p *OpenAIProvider) parseOpenAIResponse(resp *OpenAIResponse) *api.ChatResponse {
-	content := ""
+	content, finishReason := "", ""
 	if len(resp.Choices) > 0 {
 		content = resp.Choices[0].Message.Content
+		finishReason = resp.Choices[0].FinishReason
 	}
 
 	usage := &api.Usage{
@@ -366,8 +437,9 @@ func (p *OpenAIProvider) parseOpenAIResponse(resp *OpenAIResponse) *api.ChatResp
 	}
 
 	return &api.ChatResponse{
-		Content: content,
-		Usage:   usage,
+		Content:      content,
+		Usage:        usage,
+		FinishReason: api.NormalizeFinishReason(finishReason),
 	}
 }
 
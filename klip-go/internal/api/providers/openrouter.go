@@ -41,12 +41,17 @@ func NewOpenRouterProvider(apiKey string, httpClient *http.Client) (api.Provider
 	}, nil
 }
 
+// setBaseURL overrides the API root used for every request
+func (p *OpenRouterProvider) setBaseURL(baseURL string) {
+	p.baseURL = baseURL
+}
+
 // OpenRouterRequest represents the request format for OpenRouter API (OpenAI-compatible)
 type OpenRouterRequest struct {
 	Model       string              `json:"model"`
 	Messages    []OpenRouterMessage `json:"messages"`
 	MaxTokens   int                 `json:"max_tokens,omitempty"`
-	Temperature float64             `json:"temperature,omitempty"`
+	Temperature *float64            `json:"temperature,omitempty"`
 	Stream      bool                `json:"stream,omitempty"`
 	Tools       []OpenRouterTool    `json:"tools,omitempty"`
 	ToolChoice  interface{}         `json:"tool_choice,omitempty"`
@@ -187,7 +192,9 @@ func (p *OpenRouterProvider) Chat(ctx context.Context, req *api.ChatRequest) (*a
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	return p.parseOpenRouterResponse(&openrouterResp), nil
+	response := p.parseOpenRouterResponse(&openrouterResp)
+	response.RateLimit = api.ParseRateLimitHeaders(resp.Header)
+	return response, nil
 }
 
 // ChatStream sends a streaming chat request to OpenRouter
@@ -227,6 +234,7 @@ func (p *OpenRouterProvider) ChatStream(ctx context.Context, req *api.ChatReques
 		}
 
 		// Parse the streaming response
+		var finishReason string
 		parseFunc := func(data []byte) (string, bool, error) {
 			var event OpenRouterStreamEvent
 			if err := json.Unmarshal(data, &event); err != nil {
@@ -240,6 +248,7 @@ func (p *OpenRouterProvider) ChatStream(ctx context.Context, req *api.ChatReques
 					return choice.Delta.Content, false, nil
 				}
 				if choice.FinishReason != "" {
+					finishReason = choice.FinishReason
 					return "", true, nil
 				}
 			}
@@ -248,24 +257,10 @@ func (p *OpenRouterProvider) ChatStream(ctx context.Context, req *api.ChatReques
 		}
 
 		streamChunkChan, streamErrorChan := api.ParseSSEStream(ctx, resp.Body, parseFunc)
+		streamChunkChan = api.WithFinishReason(streamChunkChan, func() string { return finishReason })
+		streamChunkChan = api.WithRateLimit(streamChunkChan, api.ParseRateLimitHeaders(resp.Header))
 
-		for {
-			select {
-			case chunk, ok := <-streamChunkChan:
-				if !ok {
-					return
-				}
-				chunkChan <- chunk
-			case err := <-streamErrorChan:
-				if err != nil {
-					errorChan <- err
-				}
-				return
-			case <-ctx.Done():
-				errorChan <- ctx.Err()
-				return
-			}
-		}
+		api.ForwardStream(ctx, streamChunkChan, streamErrorChan, chunkChan, errorChan)
 	}()
 
 	return chunkChan, errorChan
@@ -299,6 +294,12 @@ func (p *OpenRouterProvider) GetModels(ctx context.Context) ([]api.Model, error)
 	return models, nil
 }
 
+// FetchModels lists OpenRouter's models, reporting errors that GetModels
+// hides behind its fallback list
+func (p *OpenRouterProvider) FetchModels(ctx context.Context) ([]api.Model, error) {
+	return p.fetchModelsFromAPI(ctx)
+}
+
 // fetchModelsFromAPI retrieves models from OpenRouter's API
 func (p *OpenRouterProvider) fetchModelsFromAPI(ctx context.Context) ([]api.Model, error) {
 	resp, err := api.MakeHTTPRequest(
@@ -412,6 +413,21 @@ func (p *OpenRouterProvider) ValidateCredentials(ctx context.Context) error {
 	return nil
 }
 
+// checkConnection fetches the key's own details. The model list is public,
+// so it can't tell a bad key from a good one.
+func (p *OpenRouterProvider) checkConnection(ctx context.Context) error {
+	resp, err := api.MakeHTTPRequest(ctx, p.httpClient, "GET", p.baseURL+"/key", p.headers, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return api.ParseErrorResponse(resp, "openrouter")
+	}
+	return nil
+}
+
 // buildOpenRouterRequest converts a ChatRequest to OpenRouter format
 func (p *OpenRouterProvider) buildOpenRouterRequest(req *api.ChatRequest, stream bool) *OpenRouterRequest {
 	openrouterReq := &OpenRouterRequest{
@@ -429,8 +445,9 @@ func (p *OpenRouterProvider) buildOpenRouterRequest(req *api.ChatRequest, stream
 			openrouterReq.MaxTokens = 4096
 		}
 	}
-	if openrouterReq.Temperature == 0 {
-		openrouterReq.Temperature = 0.7
+	if openrouterReq.Temperature == nil {
+		temperature := 0.7
+		openrouterReq.Temperature = &temperature
 	}
 
 	// Convert messages
@@ -446,9 +463,10 @@ func (p *OpenRouterProvider) buildOpenRouterRequest(req *api.ChatRequest, stream
 
 // parseOpenRouterResponse converts OpenRouter response to ChatResponse
 func (p *OpenRouterProvider) parseOpenRouterResponse(resp *OpenRouterResponse) *api.ChatResponse {
-	content := ""
+	content, finishReason := "", ""
 	if len(resp.Choices) > 0 {
 		content = resp.Choices[0].Message.Content
+		finishReason = resp.Choices[0].FinishReason
 	}
 
 	usage := &api.Usage{
@@ -457,8 +475,9 @@ func (p *OpenRouterProvider) parseOpenRouterResponse(resp *OpenRouterResponse) *
 	}
 
 	return &api.ChatResponse{
-		Content: content,
-		Usage:   usage,
+		Content:      content,
+		Usage:        usage,
+		FinishReason: api.NormalizeFinishReason(finishReason),
 	}
 }
 
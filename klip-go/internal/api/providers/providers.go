@@ -4,10 +4,13 @@ package providers
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/john/klip/internal/api"
+	"github.com/john/klip/internal/storage"
 )
 
 // ProviderConfig holds configuration for each provider
@@ -64,6 +67,32 @@ func GetProviderConfig(provider api.Provider) *ProviderConfig {
 	}
 }
 
+// withKnownLimits copies names and token limits from the built-in list to
+// listed models that share an ID, since models endpoints don't report them
+func withKnownLimits(models, builtin []api.Model) []api.Model {
+	known := make(map[string]api.Model, len(builtin))
+	for _, model := range builtin {
+		known[model.ID] = model
+	}
+
+	for i, model := range models {
+		match, ok := known[model.ID]
+		if !ok {
+			continue
+		}
+		if model.Name == model.ID {
+			models[i].Name = match.Name
+		}
+		if model.MaxTokens == 0 {
+			models[i].MaxTokens = match.MaxTokens
+		}
+		if model.ContextWindow == 0 {
+			models[i].ContextWindow = match.ContextWindow
+		}
+	}
+	return models
+}
+
 // GetAllProviders returns all supported providers
 func GetAllProviders() []api.Provider {
 	return []api.Provider{
@@ -92,6 +121,32 @@ func NewProvider(providerType api.Provider, apiKey string, httpClient *http.Clie
 	}
 }
 
+// baseURLSetter is implemented by providers whose endpoint can be overridden
+type baseURLSetter interface {
+	setBaseURL(baseURL string)
+}
+
+// SetBaseURL points provider at a custom API root, such as a self-hosted
+// gateway or an OpenAI-compatible proxy. The URL replaces the provider's
+// default including its version path, e.g. https://gateway.example.com/v1.
+// An empty baseURL keeps the default endpoint.
+func SetBaseURL(provider api.ProviderInterface, baseURL string) error {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		return nil
+	}
+	if err := storage.ValidateBaseURL(baseURL); err != nil {
+		return err
+	}
+
+	setter, ok := provider.(baseURLSetter)
+	if !ok {
+		return fmt.Errorf("provider does not support a custom base URL")
+	}
+	setter.setBaseURL(baseURL)
+	return nil
+}
+
 // ValidateProviderCredentials validates credentials for a specific provider
 func ValidateProviderCredentials(providerType api.Provider, apiKey string, httpClient *http.Client) error {
 	provider, err := NewProvider(providerType, apiKey, httpClient)
@@ -0,0 +1,98 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/john/klip/internal/api"
+)
+
+func TestSetBaseURLRoutesRequests(t *testing.T) {
+	tests := []struct {
+		provider api.Provider
+		path     string
+		body     string
+	}{
+		{api.ProviderAnthropic, "/gateway/v1/messages", `{"content":[{"type":"text","text":"hi"}],"usage":{"input_tokens":1,"output_tokens":1}}`},
+		{api.ProviderOpenAI, "/gateway/v1/chat/completions", `{"choices":[{"message":{"role":"assistant","content":"hi"}}]}`},
+		{api.ProviderOpenRouter, "/gateway/v1/chat/completions", `{"choices":[{"message":{"role":"assistant","content":"hi"}}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.provider), func(t *testing.T) {
+			var gotPath string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			provider, err := NewProvider(tt.provider, "test-key", server.Client())
+			if err != nil {
+				t.Fatalf("Failed to create provider: %v", err)
+			}
+			if err := SetBaseURL(provider, server.URL+"/gateway/v1/"); err != nil {
+				t.Fatalf("SetBaseURL failed: %v", err)
+			}
+
+			req := &api.ChatRequest{
+				Model:    api.Model{ID: "test-model", MaxTokens: 100},
+				Messages: []api.Message{{Role: "user", Content: "Hello", Timestamp: time.Now()}},
+			}
+			if _, err := provider.Chat(context.Background(), req); err != nil {
+				t.Fatalf("Chat failed: %v", err)
+			}
+			if gotPath != tt.path {
+				t.Errorf("Expected request to %s on the overridden host, got %q", tt.path, gotPath)
+			}
+		})
+	}
+}
+
+func TestSetBaseURLValidation(t *testing.T) {
+	provider, _ := NewAnthropicProvider("test-key", &http.Client{})
+
+	if err := SetBaseURL(provider, ""); err != nil {
+		t.Errorf("Empty base URL should keep the default, got %v", err)
+	}
+	if got := provider.(*AnthropicProvider).baseURL; got != "https://api.anthropic.com/v1" {
+		t.Errorf("Expected default endpoint, got %s", got)
+	}
+	if err := SetBaseURL(provider, "gateway.example.com"); err == nil {
+		t.Error("Expected a URL without a scheme to be rejected")
+	}
+}
+
+func TestOpenAIFetchModelsListsChatModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" {
+			t.Errorf("Expected a request to /v1/models, got %q", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[{"id":"gpt-4o"},{"id":"text-embedding-3-small"},{"id":"gpt-4o-realtime-preview"},{"id":"gpt-5"},{"id":"whisper-1"}]}`))
+	}))
+	defer server.Close()
+
+	provider, _ := NewOpenAIProvider("test-key", server.Client())
+	if err := SetBaseURL(provider, server.URL+"/v1"); err != nil {
+		t.Fatalf("SetBaseURL failed: %v", err)
+	}
+
+	models, err := provider.(api.ModelFetcher).FetchModels(context.Background())
+	if err != nil {
+		t.Fatalf("FetchModels failed: %v", err)
+	}
+	if len(models) != 2 || models[0].ID != "gpt-4o" || models[1].ID != "gpt-5" {
+		t.Fatalf("Expected gpt-4o and gpt-5, got %+v", models)
+	}
+	if models[0].Name != "GPT-4o" || models[0].ContextWindow != 128000 {
+		t.Errorf("Expected gpt-4o to keep its built-in details, got %+v", models[0])
+	}
+	if models[1].ContextWindow != 0 {
+		t.Errorf("Expected a new model to have no known context window, got %d", models[1].ContextWindow)
+	}
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit is the request quota a provider reported in its response headers
+type RateLimit struct {
+	Limit     int `json:"limit"`
+	Remaining int `json:"remaining"`
+
+	// RetryAfter is how long the provider asked to wait before the next
+	// request; zero when it didn't say
+	RetryAfter time.Duration `json:"retry_after,omitempty"`
+}
+
+// Used returns how many requests of the current window have been spent
+func (r *RateLimit) Used() int {
+	if r.Limit <= r.Remaining {
+		return 0
+	}
+	return r.Limit - r.Remaining
+}
+
+// Header names providers use for the request quota, in order of preference
+var (
+	rateLimitLimitHeaders = []string{
+		"x-ratelimit-limit-requests",
+		"anthropic-ratelimit-requests-limit",
+		"x-ratelimit-limit",
+	}
+	rateLimitRemainingHeaders = []string{
+		"x-ratelimit-remaining-requests",
+		"anthropic-ratelimit-requests-remaining",
+		"x-ratelimit-remaining",
+	}
+)
+
+// ParseRateLimitHeaders reads the quota and Retry-After headers of a
+// response. It returns nil when the response carries none of them.
+func ParseRateLimitHeaders(header http.Header) *RateLimit {
+	limit, hasLimit := headerInt(header, rateLimitLimitHeaders)
+	remaining, hasRemaining := headerInt(header, rateLimitRemainingHeaders)
+	retryAfter, hasRetry := parseRetryAfter(header, time.Now())
+	if !hasLimit && !hasRemaining && !hasRetry {
+		return nil
+	}
+
+	return &RateLimit{
+		Limit:      limit,
+		Remaining:  remaining,
+		RetryAfter: retryAfter,
+	}
+}
+
+// headerInt returns the first of names that holds an integer
+func headerInt(header http.Header, names []string) (int, bool) {
+	for _, name := range names {
+		if n, err := strconv.Atoi(strings.TrimSpace(header.Get(name))); err == nil {
+			return n, true
+		}
+	}
+	return 0, false
+}
+
+// parseRetryAfter reads retry-after-ms, then Retry-After as either seconds
+// or an HTTP date
+func parseRetryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	if ms, err := strconv.ParseFloat(strings.TrimSpace(header.Get("retry-after-ms")), 64); err == nil && ms >= 0 {
+		return time.Duration(ms * float64(time.Millisecond)), true
+	}
+
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := at.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// WithRateLimit attaches limit to the final chunk of a parsed stream
+func WithRateLimit(chunks <-chan StreamChunk, limit *RateLimit) <-chan StreamChunk {
+	if limit == nil {
+		return chunks
+	}
+	out := make(chan StreamChunk, cap(chunks))
+	go func() {
+		defer close(out)
+		for chunk := range chunks {
+			if chunk.Done {
+				chunk.RateLimit = limit
+			}
+			out <- chunk
+		}
+	}()
+	return out
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/john/klip/internal/storage"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// ProxySettings routes API requests through a proxy. Each empty field falls
+// back to the matching HTTP_PROXY, HTTPS_PROXY or NO_PROXY variable.
+// Credentials in a proxy URL are sent as Proxy-Authorization.
+type ProxySettings struct {
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+}
+
+// ProxySettingsFromConfig returns the proxy settings stored in config
+func ProxySettingsFromConfig(config *storage.Config) ProxySettings {
+	if config == nil {
+		return ProxySettings{}
+	}
+	return ProxySettings{
+		HTTPProxy:  strings.TrimSpace(config.HTTPProxy),
+		HTTPSProxy: strings.TrimSpace(config.HTTPSProxy),
+		NoProxy:    strings.TrimSpace(config.NoProxy),
+	}
+}
+
+// ProxyFunc returns a proxy selector for http.Transport
+func (p ProxySettings) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	cfg := httpproxy.FromEnvironment()
+	if p.HTTPProxy != "" {
+		cfg.HTTPProxy = p.HTTPProxy
+	}
+	if p.HTTPSProxy != "" {
+		cfg.HTTPSProxy = p.HTTPSProxy
+	}
+	if p.NoProxy != "" {
+		cfg.NoProxy = p.NoProxy
+	}
+
+	proxyForURL := cfg.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyForURL(req.URL)
+	}
+}
+
+// NewHTTPClient creates the HTTP client providers use to reach their APIs
+func NewHTTPClient(timeout time.Duration, proxy ProxySettings) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:               proxy.ProxyFunc(),
+			MaxIdleConns:        100,
+			MaxIdleConnsPerHost: 10,
+			IdleConnTimeout:     30 * time.Second,
+		},
+	}
+}
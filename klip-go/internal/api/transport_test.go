@@ -0,0 +1,64 @@
+package api
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/john/klip/internal/storage"
+)
+
+func TestHTTPClientUsesConfiguredProxy(t *testing.T) {
+	var gotHost, gotAuth string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHost = r.URL.Host
+		gotAuth = r.Header.Get("Proxy-Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	proxyURL.User = url.UserPassword("alice", "s3cret")
+
+	config := &storage.Config{HTTPProxy: proxyURL.String()}
+	client := NewHTTPClient(5*time.Second, ProxySettingsFromConfig(config))
+
+	resp, err := client.Get("http://api.example.test/v1/models")
+	if err != nil {
+		t.Fatalf("Request through proxy failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if gotHost != "api.example.test" {
+		t.Errorf("Expected the proxy to receive the request for api.example.test, got %q", gotHost)
+	}
+	want := "Basic " + base64.StdEncoding.EncodeToString([]byte("alice:s3cret"))
+	if gotAuth != want {
+		t.Errorf("Expected proxy credentials %q, got %q", want, gotAuth)
+	}
+}
+
+func TestProxySettingsFallBackToEnvironment(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "http://env-proxy:3128")
+	t.Setenv("NO_PROXY", "")
+
+	req, _ := http.NewRequest("GET", "https://api.anthropic.com/v1/messages", nil)
+
+	got, err := ProxySettings{}.ProxyFunc()(req)
+	if err != nil || got == nil || got.Host != "env-proxy:3128" {
+		t.Errorf("Expected the environment proxy, got %v (%v)", got, err)
+	}
+
+	got, _ = ProxySettings{HTTPSProxy: "http://configured:8080"}.ProxyFunc()(req)
+	if got == nil || got.Host != "configured:8080" {
+		t.Errorf("Expected the configured proxy to win, got %v", got)
+	}
+
+	got, _ = ProxySettings{HTTPSProxy: "http://configured:8080", NoProxy: ".anthropic.com"}.ProxyFunc()(req)
+	if got != nil {
+		t.Errorf("Expected hosts in NoProxy to bypass the proxy, got %v", got)
+	}
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/john/klip/internal/storage"
+	"github.com/john/klip/internal/ui/styles"
+)
+
+// AccessibilityPreferences returns the preferences chosen in config on top
+// of those detected from the environment
+func AccessibilityPreferences(config storage.AccessibilityConfig) *styles.AccessibilityPreferences {
+	prefs := styles.DetectAccessibilityPreferences()
+	prefs.HighContrast = prefs.HighContrast || config.HighContrast
+	prefs.ReducedMotion = prefs.ReducedMotion || config.ReducedMotion
+	prefs.LargeText = prefs.LargeText || config.LargeText
+
+	if config.ScreenReader {
+		prefs.ScreenReaderMode = true
+		prefs.VerboseDescriptions = true
+		prefs.ShowHelpText = true
+	}
+
+	if colorBlind := styles.ParseColorBlindType(config.ColorBlindType); colorBlind != styles.ColorBlindNone {
+		prefs.ColorBlindType = colorBlind
+		prefs.UseColorBlindPalette = true
+	}
+
+	return prefs
+}
+
+// applyAccessibility re-themes the UI with the accessibility preferences
+// in config
+func (m *Model) applyAccessibility(config *storage.Config) {
+	if m.accessibility == nil {
+		m.accessibility = styles.NewAccessibilityManager(styles.GetCurrentTheme(), nil)
+	}
+	m.accessibility.UpdatePreferences(AccessibilityPreferences(config.Accessibility))
+
+	if err := styles.SetAccessibility(m.accessibility); err != nil {
+		m.logger.Warn("Failed to apply accessibility preferences", "error", err)
+	}
+	applyTheme()
+}
+
+// toggleHighContrast flips high contrast, re-themes the UI and remembers
+// the choice in config
+func (m *Model) toggleHighContrast() tea.Cmd {
+	if m.accessibility == nil {
+		m.accessibility = styles.NewAccessibilityManager(styles.GetCurrentTheme(), nil)
+	}
+	prefs := *m.accessibility.GetPreferences()
+	prefs.HighContrast = !prefs.HighContrast
+	m.accessibility.UpdatePreferences(&prefs)
+
+	if err := styles.SetAccessibility(m.accessibility); err != nil {
+		m.logger.Warn("Failed to apply high contrast", "error", err)
+	}
+	applyTheme()
+
+	if m.config != nil {
+		m.config.Accessibility.HighContrast = prefs.HighContrast
+		if m.storage != nil && m.storage.ConfigManager != nil {
+			config := m.config
+			go func() {
+				if err := m.storage.ConfigManager.SaveConfig(config); err != nil {
+					m.logger.Error("Failed to save high contrast", "error", err)
+				}
+			}()
+		}
+	}
+
+	status := "High contrast off"
+	if prefs.HighContrast {
+		status = "High contrast on"
+	}
+	return func() tea.Msg {
+		return statusMsg{status, 2 * time.Second}
+	}
+}
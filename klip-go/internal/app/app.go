@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/log"
 	"github.com/john/klip/internal/api"
 	"github.com/john/klip/internal/storage"
+	"github.com/john/klip/internal/ui/styles"
 )
 
 // Model represents the main application state
@@ -22,6 +24,7 @@ type Model struct {
 	width  int
 	height int
 	ready  bool
+	layout styles.ResponsiveConfig
 
 	// Dependencies
 	storage    *storage.Storage
@@ -30,6 +33,15 @@ type Model struct {
 	ctx        context.Context
 	cancelFunc context.CancelFunc
 
+	// cleanupOnce keeps cleanup from ending the session twice
+	cleanupOnce sync.Once
+
+	// summarizer condenses older turns; nil asks the summary model
+	summarizer Summarizer
+
+	// modelManager caches the model lists fetched from providers
+	modelManager *api.ModelManager
+
 	// Current model and configuration
 	currentModel api.Model
 	config       *storage.Config
@@ -50,12 +62,41 @@ type Model struct {
 	historyIndex int
 
 	// UI state
-	statusMessage  string
-	statusTimeout  time.Time
-	showDebugInfo  bool
+	statusMessage string
+	statusTimeout time.Time
+	eventLog      []LoggedEvent
+	showDebugInfo bool
+	focusMode     bool
+	palette       *CommandPalette
+	commands      *CommandRegistry
+	// commandArgs is the raw text after the name of the command being run,
+	// for handlers that must keep its whitespace
+	commandArgs    string
+	responseCache  *storage.ResponseCache
 	animationFrame int
 	lastUpdate     time.Time
 
+	// connectionState is the streaming connection the API client last
+	// reported through connectionStates
+	connectionState  api.ConnectionState
+	connectionStates chan api.ConnectionState
+
+	// providerHealth is whether each provider passed the startup health
+	// check, nil until it has run
+	providerHealth map[string]bool
+
+	// lastActivity is when the user last pressed a key, for the idle archive
+	lastActivity   time.Time
+	exportProgress *ProgressTracker
+
+	// Accessibility preferences from the config and environment
+	accessibility *styles.AccessibilityManager
+
+	// Frame pacing for slow or remote terminals
+	styler     *styles.AdaptiveStyler
+	skipRender bool
+	lastView   string
+
 	// Feature flags
 	webSearchEnabled bool
 	analyticsEnabled bool
@@ -77,15 +118,19 @@ func New() *Model {
 		ready:            false,
 		loadingState:     NewLoadingState("Initializing Klip..."),
 		chatState:        NewChatState(),
+		styler:           styles.NewAdaptiveStyler(nil, 0, 0),
 		modelsState:      NewModelsState(),
 		settingsState:    NewSettingsState(),
 		historyState:     NewHistoryState(),
 		helpState:        NewHelpState(),
+		commands:         NewCommandRegistry(),
 		inputHistory:     make([]string, 0),
 		historyIndex:     -1,
 		lastUpdate:       time.Now(),
+		lastActivity:     time.Now(),
 		webSearchEnabled: true,
 		analyticsEnabled: true,
+		connectionStates: make(chan api.ConnectionState, 16),
 	}
 }
 
@@ -140,25 +185,46 @@ func (m *Model) onStateTransition(from, to AppState) {
 	}
 }
 
+// Shutdown saves the session and flushes buffered analytics. Run it once
+// the program has exited, however it exited.
+func (m *Model) Shutdown() {
+	m.cleanup()
+}
+
 // cleanup performs cleanup operations
 func (m *Model) cleanup() {
-	if m.storage != nil {
-		if err := m.storage.Shutdown(); err != nil {
-			m.logger.Error("Error during storage shutdown", "error", err)
+	m.cleanupOnce.Do(func() {
+		if m.storage != nil {
+			if err := m.storage.Shutdown(); err != nil {
+				m.logger.Error("Error during storage shutdown", "error", err)
+			}
 		}
-	}
 
-	if m.cancelFunc != nil {
-		m.cancelFunc()
-	}
+		if m.cancelFunc != nil {
+			m.cancelFunc()
+		}
+	})
 }
 
 // setStatusMessage sets a temporary status message
 func (m *Model) setStatusMessage(message string, duration time.Duration) {
+	m.logEvent("info", message)
 	m.statusMessage = message
 	m.statusTimeout = time.Now().Add(duration)
 }
 
+// toggleFocusMode hides or restores the status bar, leaving the whole
+// terminal to the conversation and input
+func (m *Model) toggleFocusMode() tea.Cmd {
+	m.focusMode = !m.focusMode
+	if m.focusMode {
+		return nil
+	}
+	return func() tea.Msg {
+		return statusMsg{"Focus mode off", 2 * time.Second}
+	}
+}
+
 // clearStatusMessage clears the status message
 func (m *Model) clearStatusMessage() {
 	m.statusMessage = ""
@@ -288,6 +354,9 @@ func (m *Model) getInputMode() InputMode {
 
 // setError sets an error state
 func (m *Model) setError(err error, context string, recoverable bool) {
+	// Errors must not go unnoticed behind hidden chrome
+	m.focusMode = false
+	m.logEvent("error", fmt.Sprintf("%s: %v", context, err))
 	m.errorState = NewErrorState(err, context, recoverable, m.GetCurrentState())
 	m.TransitionTo(StateError)
 }
@@ -320,6 +389,43 @@ func (m *Model) updateAnimationFrame() {
 	}
 }
 
+// resize records the new window size and re-evaluates the responsive breakpoint
+func (m *Model) resize(width, height int) {
+	m.width = width
+	m.height = height
+
+	if m.styler == nil {
+		return
+	}
+	m.styler.Resize(width, height)
+
+	previous := m.layout
+	m.layout = m.styler.GetResponsiveConfig()
+	if m.layout.MinWidth != previous.MinWidth {
+		m.logger.Debug("Layout breakpoint changed", "width", width, "sidebar", m.layout.ShowSidebar, "compact", m.layout.CompactMode)
+	}
+}
+
+// tickInterval returns the animation tick interval for the terminal's frame rate
+func (m *Model) tickInterval() time.Duration {
+	if m.styler == nil {
+		return 100 * time.Millisecond
+	}
+	return m.styler.FrameInterval()
+}
+
+// animationTick schedules the next animation frame
+func (m *Model) animationTick() tea.Cmd {
+	return tea.Tick(m.tickInterval(), func(t time.Time) tea.Msg {
+		return tickMsg{t}
+	})
+}
+
+// shouldSkipFrame reports whether the current animation frame can be dropped
+func (m *Model) shouldSkipFrame() bool {
+	return m.styler != nil && m.styler.ShouldSkipFrame(m.animationFrame)
+}
+
 // shouldAnimate determines if animations should be active
 func (m *Model) shouldAnimate() bool {
 	return m.loadingState != nil && m.loadingState.IsLoading
@@ -341,9 +447,8 @@ func (m *Model) formatElapsedTime(start time.Time) string {
 func (m *Model) Init() tea.Cmd {
 	return tea.Batch(
 		m.initializeApp(),
-		tea.Tick(time.Millisecond*100, func(t time.Time) tea.Msg {
-			return tickMsg{t}
-		}),
+		m.animationTick(),
+		waitForConnectionState(m.connectionStates),
 	)
 }
 
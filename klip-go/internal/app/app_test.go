@@ -1,15 +1,24 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/log"
 	"github.com/john/klip/internal/api"
+	"github.com/john/klip/internal/api/providers"
+	"github.com/john/klip/internal/storage"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNew(t *testing.T) {
@@ -319,6 +328,43 @@ func TestAnimationFrame(t *testing.T) {
 	assert.Equal(t, 0, model.animationFrame)
 }
 
+func TestFrameSkippingOnSlowTerminal(t *testing.T) {
+	model := New()
+	model.styler.GetCapabilities().IsSlowTerminal = true
+	model.ready = true
+	model.width, model.height = 80, 24
+
+	assert.Equal(t, 100*time.Millisecond, model.tickInterval())
+
+	var skipped []bool
+	for i := 0; i < 4; i++ {
+		model.Update(tickMsg{time.Now()})
+		skipped = append(skipped, model.skipRender)
+	}
+	assert.Equal(t, []bool{true, false, true, false}, skipped)
+}
+
+func TestSkippedFrameReusesLastView(t *testing.T) {
+	model := New()
+	model.styler.GetCapabilities().IsSlowTerminal = true
+	model.ready = true
+	model.width, model.height = 80, 24
+
+	model.animationFrame = 1
+	model.Update(tickMsg{time.Now()})
+	assert.False(t, model.skipRender)
+	assert.NotEmpty(t, model.View())
+
+	model.Update(tickMsg{time.Now()})
+	assert.True(t, model.skipRender)
+	model.lastView = "cached"
+	assert.Equal(t, "cached", model.View())
+
+	// Any other message forces a fresh render
+	model.Update(statusMsg{"hello", time.Second})
+	assert.NotEqual(t, "cached", model.View())
+}
+
 func TestLoadingState(t *testing.T) {
 	loadingState := NewLoadingState("Test operation")
 
@@ -391,6 +437,287 @@ func TestCleanup(t *testing.T) {
 	}
 }
 
+func TestSystemPromptIncludedInRequest(t *testing.T) {
+	model := New()
+	model.config = &storage.Config{SystemPrompt: "You are concise."}
+
+	cmd := model.sendChatMessage("hello")
+	request := cmd().(apiRequestMsg).request
+
+	assert.Len(t, request.Messages, 2)
+	assert.Equal(t, "system", request.Messages[0].Role)
+	assert.Equal(t, "You are concise.", request.Messages[0].Content)
+	assert.Equal(t, "user", request.Messages[1].Role)
+
+	// The system prompt is not stored in the conversation itself
+	assert.Len(t, model.chatState.Messages, 1)
+}
+
+func TestSystemPromptOmittedWhenEmpty(t *testing.T) {
+	model := New()
+	model.config = &storage.Config{SystemPrompt: "   "}
+
+	cmd := model.sendChatMessage("hello")
+	request := cmd().(apiRequestMsg).request
+
+	assert.Len(t, request.Messages, 1)
+	assert.Equal(t, "user", request.Messages[0].Role)
+}
+
+func TestSessionSystemPromptOverridesConfig(t *testing.T) {
+	model := New()
+	model.config = &storage.Config{SystemPrompt: "configured"}
+	model.handleSystemCommand([]string{"session", "prompt"})
+
+	messages := model.requestMessages()
+	assert.Equal(t, "session prompt", messages[0].Content)
+
+	model.handleSystemCommand([]string{"clear"})
+	messages = model.requestMessages()
+	assert.Equal(t, "configured", messages[0].Content)
+}
+
+func TestExpandPromptVariables(t *testing.T) {
+	now := time.Date(2025, 3, 14, 9, 26, 0, 0, time.UTC)
+	model := api.Model{ID: "gpt-4o", Name: "GPT-4o"}
+
+	expanded := expandPromptVariables("Today is {date} at {time}. You are {model}.", model, now)
+	assert.Equal(t, "Today is 2025-03-14 at 09:26. You are GPT-4o.", expanded)
+}
+
+func TestModelParametersAppliedToRequest(t *testing.T) {
+	model := New()
+	model.currentModel = api.Model{ID: "gpt-4o", Name: "GPT-4o", Provider: api.ProviderOpenAI, MaxTokens: 16384}
+	temperature, maxTokens := 1.2, 2048
+	model.config = &storage.Config{
+		ModelParameters: map[string]storage.ModelParameters{
+			"gpt-4o": {Temperature: &temperature, MaxTokens: &maxTokens},
+		},
+	}
+
+	cmd := model.sendChatMessage("hello")
+	request := cmd().(apiRequestMsg).request
+
+	require.NotNil(t, request.Temperature)
+	assert.Equal(t, 1.2, *request.Temperature)
+	assert.Equal(t, 2048, request.MaxTokens)
+}
+
+func TestModelParametersOutOfRangeForProvider(t *testing.T) {
+	model := New()
+	model.currentModel = api.Model{ID: "claude", Name: "Claude", Provider: api.ProviderAnthropic, MaxTokens: 8192}
+	temperature := 1.5
+	model.config = &storage.Config{
+		ModelParameters: map[string]storage.ModelParameters{
+			"claude": {Temperature: &temperature},
+		},
+	}
+
+	cmd := model.sendChatMessage("hello")
+	request := cmd().(apiRequestMsg).request
+
+	assert.Nil(t, request.Temperature)
+}
+
+func TestParamsCommand(t *testing.T) {
+	model := New()
+	model.currentModel = api.Model{ID: "claude", Name: "Claude", Provider: api.ProviderAnthropic, MaxTokens: 8192}
+
+	model.handleParamsCommand([]string{"temperature", "0.3"})
+	model.handleParamsCommand([]string{"max_tokens", "1024"})
+	params := model.config.ParametersFor("claude")
+	require.NotNil(t, params.Temperature)
+	require.NotNil(t, params.MaxTokens)
+	assert.Equal(t, 0.3, *params.Temperature)
+	assert.Equal(t, 1024, *params.MaxTokens)
+
+	msg := model.handleParamsCommand([]string{"temperature", "1.8"})().(statusMsg)
+	assert.Contains(t, msg.message, "Invalid parameters")
+	assert.Equal(t, 0.3, *model.config.ParametersFor("claude").Temperature)
+
+	// Zero is a temperature, not the provider default
+	msg = model.handleParamsCommand([]string{"temperature", "0"})().(statusMsg)
+	assert.Contains(t, msg.message, "temperature 0,")
+	request := model.sendChatMessage("hello")().(apiRequestMsg).request
+	require.NotNil(t, request.Temperature)
+	assert.Zero(t, *request.Temperature)
+
+	model.handleParamsCommand([]string{"reset"})
+	assert.Empty(t, model.config.ModelParameters)
+}
+
+func interruptedStreamModel(provider api.Provider) *Model {
+	model := New()
+	model.currentModel = api.Model{ID: "test-model", Name: "Test", Provider: provider}
+	model.sendChatMessage("tell me a story")
+	model.chatState.IsStreaming = true
+	model.chatState.StreamBuffer = "Once upon a "
+	return model
+}
+
+// requestFromRetry runs the /retry command and returns the request it sends
+func requestFromRetry(t *testing.T, model *Model) *api.ChatRequest {
+	return sentRequest(t, model.ExecuteCommand("/retry"))
+}
+
+// sentRequest returns the request cmd sends, on its own or in a batch
+func sentRequest(t *testing.T, cmd tea.Cmd) *api.ChatRequest {
+	switch msg := cmd().(type) {
+	case apiRequestMsg:
+		return msg.request
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			if msg, ok := cmd().(apiRequestMsg); ok {
+				return msg.request
+			}
+		}
+	}
+	t.Fatal("no request was sent")
+	return nil
+}
+
+// sentStatus returns the status message cmd shows, on its own or in a batch
+func sentStatus(t *testing.T, cmd tea.Cmd) string {
+	switch msg := cmd().(type) {
+	case statusMsg:
+		return msg.message
+	case tea.BatchMsg:
+		for _, cmd := range msg {
+			if msg, ok := cmd().(statusMsg); ok {
+				return msg.message
+			}
+		}
+	}
+	t.Fatal("no status was shown")
+	return ""
+}
+
+func TestInterruptedStreamOffersRetry(t *testing.T) {
+	model := interruptedStreamModel(api.ProviderAnthropic)
+
+	cmd := model.handleChatState(apiErrorMsg{errors.New("connection reset by peer")})
+	if assert.NotNil(t, cmd) {
+		assert.Contains(t, cmd().(statusMsg).message, "/retry")
+	}
+
+	// The partial response is kept rather than discarded
+	assert.False(t, model.chatState.IsStreaming)
+	assert.Equal(t, "Once upon a ", model.chatState.Messages[len(model.chatState.Messages)-1].Content)
+
+	// Anthropic continues after the partial assistant message
+	request := requestFromRetry(t, model)
+	last := request.Messages[len(request.Messages)-1]
+	assert.Equal(t, "assistant", last.Role)
+	assert.Equal(t, "Once upon a", last.Content)
+
+	model.chatState.StreamBuffer = " time."
+	model.handleChatState(apiStreamDoneMsg{})
+	assert.Len(t, model.chatState.Messages, 2)
+	assert.Equal(t, "Once upon a time.", model.chatState.Messages[1].Content)
+	assert.Nil(t, model.chatState.Recovery)
+}
+
+func TestInterruptedStreamResendsLastTurnWithoutContinuation(t *testing.T) {
+	model := interruptedStreamModel(api.ProviderOpenAI)
+	model.handleChatState(apiErrorMsg{errors.New("connection reset by peer")})
+
+	request := requestFromRetry(t, model)
+	last := request.Messages[len(request.Messages)-1]
+	assert.Equal(t, "user", last.Role)
+	assert.Equal(t, "tell me a story", last.Content)
+	assert.Len(t, model.chatState.Messages, 1)
+}
+
+func TestInterruptedStreamStopsRetryingAfterLimit(t *testing.T) {
+	model := interruptedStreamModel(api.ProviderOpenAI)
+
+	for i := 0; i < maxStreamRecoveryAttempts; i++ {
+		assert.NotNil(t, model.handleChatState(apiErrorMsg{errors.New("connection reset by peer")}))
+		requestFromRetry(t, model)
+		model.chatState.IsStreaming = true
+		model.chatState.StreamBuffer = "Once upon a "
+	}
+
+	assert.Nil(t, model.handleChatState(apiErrorMsg{errors.New("connection reset by peer")}))
+	assert.Nil(t, model.chatState.Recovery)
+	assert.Equal(t, StateError, model.GetCurrentState())
+}
+
+func TestRetryResendsFailedRequest(t *testing.T) {
+	model := New()
+	model.logger = log.New(os.Stderr)
+	model.currentModel = api.Model{ID: "test-model", Name: "Test", Provider: api.ProviderOpenAI}
+	model.chatState.BriefNext = true
+
+	sent := sentRequest(t, model.sendChatMessage("explain monads"))
+	model.handleAPIMessages(apiRequestMsg{sent})
+	assert.Nil(t, model.handleChatState(apiErrorMsg{errors.New("invalid request")}))
+	assert.Equal(t, StateError, model.GetCurrentState())
+	assert.Same(t, sent, model.chatState.FailedRequest)
+
+	// The same request goes out again, limits and all, without a new turn
+	model.TransitionTo(StateChat)
+	retried := requestFromRetry(t, model)
+	assert.Equal(t, sent.Messages, retried.Messages)
+	assert.Equal(t, sent.MaxTokens, retried.MaxTokens)
+	assert.NotZero(t, retried.MaxTokens)
+	assert.Len(t, model.chatState.Messages, 1)
+	assert.Nil(t, model.chatState.FailedRequest)
+
+	// An edited retry replaces the failed message
+	model.handleAPIMessages(apiRequestMsg{retried})
+	model.handleChatState(apiErrorMsg{errors.New("invalid request")})
+	model.TransitionTo(StateChat)
+	model.ExecuteCommand("/retry edit")
+	assert.Equal(t, "/retry explain monads", model.inputBuffer)
+
+	edited := sentRequest(t, model.ExecuteCommand("/retry explain monads briefly"))
+	assert.Equal(t, "explain monads briefly", edited.Messages[len(edited.Messages)-1].Content)
+	assert.Equal(t, "explain monads briefly", model.chatState.Messages[0].Content)
+
+	// Nothing failed once a reply arrives; regenerate targets it instead
+	model.handleAPIMessages(apiResponseMsg{&api.ChatResponse{Content: "A monad is..."}})
+	msg := model.ExecuteCommand("/retry")()
+	assert.Contains(t, msg.(statusMsg).message, "/regenerate")
+
+	model.ExecuteCommand("/regenerate")
+	assert.Len(t, model.chatState.Messages, 1)
+	assert.Equal(t, "user", model.chatState.Messages[0].Role)
+}
+
+func TestRetryEditKeepsLineBreaks(t *testing.T) {
+	model := New()
+	model.logger = log.New(os.Stderr)
+	model.currentModel = api.Model{ID: "test-model", Name: "Test", Provider: api.ProviderOpenAI}
+
+	content := "fix this:\n\n    if x  {\n        return\n    }"
+	sent := sentRequest(t, model.sendChatMessage(content))
+	model.handleAPIMessages(apiRequestMsg{sent})
+	model.handleChatState(apiErrorMsg{errors.New("invalid request")})
+	model.TransitionTo(StateChat)
+
+	model.ExecuteCommand("/retry edit")
+	assert.Equal(t, "/retry "+content, model.inputBuffer)
+
+	edited := sentRequest(t, model.ExecuteCommand(model.inputBuffer))
+	assert.Equal(t, content, edited.Messages[len(edited.Messages)-1].Content)
+	assert.Equal(t, content, model.chatState.Messages[0].Content)
+}
+
+func TestRegenerateKeepsReplyWithoutUserTurn(t *testing.T) {
+	model := New()
+	model.TransitionTo(StateOnboarding)
+	model.TransitionTo(StateChat)
+	model.chatState.Messages = []api.Message{
+		{Role: "system", Content: "Earlier turns were summarized"},
+		{Role: "assistant", Content: "Welcome back"},
+	}
+
+	msg := model.ExecuteCommand("/regenerate")()
+	assert.Equal(t, "No message to regenerate from", msg.(statusMsg).message)
+	assert.Len(t, model.chatState.Messages, 2, "the reply is kept when there is nothing to resend")
+}
+
 // Benchmark tests for performance-critical operations
 
 func BenchmarkInputInsertion(b *testing.B) {
@@ -446,3 +773,908 @@ func BenchmarkModelFiltering(b *testing.B) {
 		modelsState.FilterModels("model")
 	}
 }
+
+func TestResizeCrossingBreakpointTogglesSidebar(t *testing.T) {
+	model := New()
+
+	model.Update(tea.WindowSizeMsg{Width: 59, Height: 24})
+	assert.False(t, model.layout.ShowSidebar)
+	assert.True(t, model.layout.CompactMode)
+
+	model.Update(tea.WindowSizeMsg{Width: 60, Height: 24})
+	assert.True(t, model.layout.ShowSidebar)
+	assert.False(t, model.layout.CompactMode)
+
+	model.Update(tea.WindowSizeMsg{Width: 40, Height: 24})
+	assert.False(t, model.layout.ShowSidebar)
+}
+
+func TestFocusModeHidesStatusBar(t *testing.T) {
+	model := New()
+	model.logger = log.New(os.Stderr)
+	model.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	model.TransitionTo(StateOnboarding)
+	model.TransitionTo(StateChat)
+
+	assert.Contains(t, model.View(), "State: chat")
+
+	model.Update(tea.KeyMsg{Type: tea.KeyF11})
+	assert.True(t, model.focusMode)
+	view := model.View()
+	assert.NotContains(t, view, "State: chat")
+	assert.Contains(t, view, "Message:")
+
+	// An error brings the chrome back
+	model.setError(errors.New("boom"), "API request failed", true)
+	assert.False(t, model.focusMode)
+	assert.Contains(t, model.View(), "State: error")
+}
+
+func TestRateLimitFallsBackToConfiguredModel(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	analytics, err := storage.NewAnalyticsLogger(nil)
+	assert.NoError(t, err)
+
+	model := New()
+	model.logger = log.New(os.Stderr)
+	model.storage = &storage.Storage{AnalyticsLogger: analytics}
+	model.config = &storage.Config{MaxRetries: 2, FallbackModel: "claude-3-5-haiku-20241022"}
+	model.currentModel = api.Model{ID: "claude-3-5-sonnet-20241022", Name: "Claude 3.5 Sonnet", Provider: api.ProviderAnthropic}
+	model.apiClient, err = providers.NewAnthropicProvider("sk-ant-test", http.DefaultClient)
+	assert.NoError(t, err)
+	model.sendChatMessage("hello")
+
+	rateLimited := apiErrorMsg{&api.APIError{StatusCode: http.StatusTooManyRequests, Message: "slow down", Provider: "anthropic"}}
+
+	// The first 429s are retried with the same model
+	for i := 1; i <= 2; i++ {
+		assert.NotNil(t, model.handleChatState(rateLimited))
+		assert.Equal(t, i, model.chatState.RateLimitRetries)
+		assert.Equal(t, "claude-3-5-sonnet-20241022", model.currentModel.ID)
+		assert.True(t, model.chatState.WaitingForAPI)
+	}
+
+	cmd := model.handleChatState(rateLimited)
+	if !assert.NotNil(t, cmd) {
+		return
+	}
+	fallback, ok := cmd().(modelFallbackMsg)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.NoError(t, fallback.err)
+
+	batch := model.handleAPIMessages(fallback)()
+	assert.Equal(t, "claude-3-5-haiku-20241022", model.currentModel.ID)
+	assert.Zero(t, model.chatState.RateLimitRetries)
+	assert.Contains(t, batch.(tea.BatchMsg)[0]().(statusMsg).message, "switched to Claude 3.5 Haiku")
+	assert.Equal(t, "claude-3-5-haiku-20241022", batch.(tea.BatchMsg)[1]().(apiRequestMsg).request.Model.ID)
+
+	assert.NoError(t, analytics.Flush())
+	events, err := analytics.GetAnalyticsData("", "", "model_switch")
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, "claude-3-5-haiku-20241022", events[0].ModelID)
+		assert.Equal(t, "rate_limit", events[0].Metadata["reason"])
+		assert.Equal(t, "claude-3-5-sonnet-20241022", events[0].Metadata["previous_model_id"])
+	}
+
+	// Once on the fallback, exhausted retries surface the error
+	model.chatState.RateLimitRetries = 2
+	assert.Nil(t, model.handleChatState(rateLimited))
+	assert.Equal(t, StateError, model.GetCurrentState())
+}
+
+func TestModelPickerSwitchesAndRemembersModel(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	analytics, err := storage.NewAnalyticsLogger(nil)
+	require.NoError(t, err)
+
+	sonnet := api.Model{ID: "claude-3-5-sonnet-20241022", Name: "Claude 3.5 Sonnet", Provider: api.ProviderAnthropic}
+	haiku := api.Model{ID: "claude-3-5-haiku-20241022", Name: "Claude 3.5 Haiku", Provider: api.ProviderAnthropic}
+	gpt := api.Model{ID: "gpt-4o", Name: "GPT-4o", Provider: api.ProviderOpenAI}
+
+	model := New()
+	model.logger = log.New(os.Stderr)
+	model.storage = &storage.Storage{AnalyticsLogger: analytics}
+	model.config = &storage.Config{RecentModels: []string{"gpt-4o"}, FavoriteModels: []string{haiku.ID}}
+	model.currentModel = sonnet
+	model.modelsState.AvailableModels = []api.Model{sonnet, haiku, gpt}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	require.NotNil(t, model.palette)
+	if assert.Len(t, model.palette.Matches, 3) {
+		assert.Equal(t, "recent", model.palette.Matches[0].Hint)
+		assert.Equal(t, "★", model.palette.Matches[1].Hint)
+		assert.Equal(t, "current", model.palette.Matches[2].Hint)
+	}
+
+	// Typing narrows the list to the model
+	for _, r := range "haiku" {
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	require.Len(t, model.palette.Matches, 1)
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Nil(t, model.palette)
+	require.NotNil(t, cmd)
+	model.Update(cmd())
+
+	assert.Equal(t, haiku.ID, model.currentModel.ID)
+	assert.Equal(t, []string{haiku.ID, "gpt-4o"}, model.config.RecentModels)
+
+	require.NoError(t, analytics.Flush())
+	events, err := analytics.GetAnalyticsData("", "", "model_switch")
+	require.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, haiku.ID, events[0].ModelID)
+		assert.Equal(t, sonnet.ID, events[0].Metadata["previous_model_id"])
+	}
+}
+
+func TestAuthErrorOffersProviderSettings(t *testing.T) {
+	model := New()
+	model.logger = log.New(os.Stderr)
+	model.config = &storage.Config{}
+	model.currentModel = api.Model{ID: "claude-3-5-sonnet-20241022", Name: "Claude 3.5 Sonnet", Provider: api.ProviderAnthropic}
+	model.TransitionTo(StateChat)
+	model.chatState.WaitingForAPI = true
+
+	cmd := model.handleChatState(apiErrorMsg{&api.APIError{StatusCode: http.StatusUnauthorized, Message: "invalid x-api-key", Provider: "anthropic"}})
+	require.NotNil(t, cmd)
+	assert.Equal(t, ProviderAuthErrorMsg{Provider: api.ProviderAnthropic, Message: "Anthropic key invalid or expired · open settings to update it"}, cmd())
+	assert.Equal(t, StateError, model.GetCurrentState())
+	assert.Contains(t, model.renderErrorView(), "Anthropic key invalid or expired")
+	assert.Contains(t, model.renderErrorView(), "Providers settings")
+
+	model.handleErrorState(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	assert.Equal(t, StateSettings, model.GetCurrentState())
+	assert.Equal(t, "providers", model.settingsState.Section)
+}
+
+func TestRateLimitWithoutFallbackReportsError(t *testing.T) {
+	model := New()
+	model.logger = log.New(os.Stderr)
+	model.config = &storage.Config{MaxRetries: 1}
+	model.sendChatMessage("hello")
+	model.chatState.RateLimitRetries = 1
+
+	assert.Nil(t, model.handleChatState(apiErrorMsg{&api.APIError{StatusCode: http.StatusTooManyRequests}}))
+	assert.False(t, model.chatState.WaitingForAPI)
+	assert.Equal(t, StateError, model.GetCurrentState())
+}
+
+func TestRetryAfterDrivesSendCooldown(t *testing.T) {
+	model := New()
+	model.logger = log.New(os.Stderr)
+	model.config = &storage.Config{MaxRetries: 1}
+	model.width = 120
+	model.sendChatMessage("hello")
+
+	rateLimited := &api.APIError{
+		StatusCode: http.StatusTooManyRequests,
+		Provider:   "openai",
+		RateLimit:  &api.RateLimit{Limit: 60, Remaining: 0, RetryAfter: 12 * time.Second},
+	}
+	assert.NotNil(t, model.handleChatState(apiErrorMsg{rateLimited}))
+	assert.Equal(t, 60, model.chatState.RateLimit.Used())
+	assert.InDelta(t, float64(12*time.Second), float64(model.cooldownRemaining()), float64(time.Second))
+	assert.Contains(t, model.renderStatusBar(), "retry in 12s")
+
+	// Sending is disabled until the cooldown elapses, keeping the draft
+	model.chatState.WaitingForAPI = false
+	model.inputBuffer = "again"
+	cmd := model.sendChatMessage("again")
+	assert.Contains(t, cmd().(statusMsg).message, "retry in")
+	assert.Len(t, model.chatState.Messages, 1)
+	assert.Equal(t, "again", model.inputBuffer)
+
+	model.chatState.CooldownUntil = time.Now().Add(-time.Second)
+	assert.Nil(t, model.cooldownTick())
+	assert.NotContains(t, model.renderStatusBar(), "retry in")
+	model.sendChatMessage("again")
+	assert.Len(t, model.chatState.Messages, 2)
+}
+
+func TestSecretInMessageAsksBeforeSending(t *testing.T) {
+	model := New()
+	model.currentModel = api.Model{ID: "test-model", Name: "Test", Provider: api.ProviderAnthropic}
+	message := "why does sk-ant-REDACTED fail?"
+	model.setCurrentInput(message)
+
+	cmd := model.handleChatKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Contains(t, cmd().(statusMsg).message, "Anthropic API key")
+	assert.Empty(t, model.chatState.Messages)
+	assert.Equal(t, message, model.inputBuffer)
+	assert.Contains(t, model.renderInputArea(), "y/r/n")
+
+	// Other keys wait for an answer; n keeps the draft
+	assert.Nil(t, model.handleChatKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")}))
+	model.handleChatKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	assert.Nil(t, model.chatState.SecretCheck)
+	assert.Empty(t, model.chatState.Messages)
+	assert.Equal(t, message, model.inputBuffer)
+
+	// r sends the message with the key redacted
+	model.handleChatKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	request := sentRequest(t, model.handleChatKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")}))
+	sent := request.Messages[len(request.Messages)-1].Content
+	assert.Equal(t, "why does "+storage.RedactedPlaceholder+" fail?", sent)
+	assert.Nil(t, model.chatState.SecretCheck)
+
+	// y sends it as written; with the scan disabled nothing is asked
+	model.chatState.WaitingForAPI = false
+	model.setCurrentInput(message)
+	model.handleChatKeys(tea.KeyMsg{Type: tea.KeyEnter})
+	request = sentRequest(t, model.handleChatKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")}))
+	assert.Equal(t, message, request.Messages[len(request.Messages)-1].Content)
+
+	model.chatState.WaitingForAPI = false
+	model.config = &storage.Config{DisableSecretScan: true}
+	sentRequest(t, model.sendChatMessage(message))
+}
+
+func TestSearchFindsMessagesAcrossSessions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	logger, err := storage.NewChatLogger()
+	require.NoError(t, err)
+
+	var sessionIDs []string
+	for _, conversation := range [][]string{
+		{"How do I profile a Go program?", "Use pprof: import net/http/pprof and open /debug/pprof."},
+		{"What is a goroutine?", "A lightweight thread; pprof can list them too."},
+	} {
+		require.NoError(t, logger.StartSession())
+		sessionIDs = append(sessionIDs, logger.GetCurrentSession().SessionID)
+		require.NoError(t, logger.LogMessage(storage.Message{Role: "user", Content: conversation[0]}))
+		require.NoError(t, logger.LogMessage(storage.Message{Role: "assistant", Content: conversation[1]}))
+	}
+
+	model := New()
+	model.storage = &storage.Storage{ChatLogger: logger}
+	model.Update(model.ExecuteCommand("/search PPROF")())
+
+	search := model.chatState.Search
+	require.NotNil(t, search)
+	var found []string
+	for _, result := range search.Results {
+		found = append(found, result.SessionID)
+		assert.Equal(t, "assistant", result.Message.Role)
+		assert.Equal(t, 1, result.MessageIndex)
+	}
+	assert.ElementsMatch(t, sessionIDs, found)
+	assert.Contains(t, model.renderChatView(), "2 matches")
+
+	// Selecting a result opens its session at the matched message
+	model.handleChatKeys(tea.KeyMsg{Type: tea.KeyDown})
+	selected := search.Results[1]
+	model.Update(model.handleChatKeys(tea.KeyMsg{Type: tea.KeyEnter})())
+	assert.Nil(t, model.chatState.Search)
+	if assert.Len(t, model.chatState.Messages, 2) {
+		assert.Equal(t, selected.Message.Content, model.chatState.Messages[1].Content)
+	}
+	if assert.NotNil(t, model.chatState.OpenedSession) {
+		assert.Equal(t, selected.SessionID, model.chatState.OpenedSession.ID)
+		assert.Equal(t, 1, model.chatState.OpenedSession.Message)
+	}
+}
+
+func TestResumeRestoresSessionModel(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	logger, err := storage.NewChatLogger()
+	require.NoError(t, err)
+
+	var sessionIDs []string
+	for _, modelID := range []string{"claude-3-5-haiku-20241022", "claude-3-opus-20240229"} {
+		require.NoError(t, logger.StartSession())
+		sessionIDs = append(sessionIDs, logger.GetCurrentSession().SessionID)
+		require.NoError(t, logger.LogMessage(storage.Message{Role: "user", Content: "hi", Model: modelID, Provider: "anthropic"}))
+		require.NoError(t, logger.LogMessage(storage.Message{Role: "assistant", Content: "hello", Model: modelID, Provider: "anthropic"}))
+	}
+
+	sonnet := api.Model{ID: "claude-3-5-sonnet-20241022", Name: "Claude 3.5 Sonnet", Provider: api.ProviderAnthropic}
+	haiku := api.Model{ID: "claude-3-5-haiku-20241022", Name: "Claude 3.5 Haiku", Provider: api.ProviderAnthropic}
+	gpt := api.Model{ID: "gpt-4o", Name: "GPT-4o", Provider: api.ProviderOpenAI}
+
+	model := New()
+	model.storage = &storage.Storage{ChatLogger: logger}
+	model.currentModel = sonnet
+	model.apiClient, err = providers.NewAnthropicProvider("sk-ant-test", http.DefaultClient)
+	require.NoError(t, err)
+	model.modelsState.AvailableModels = []api.Model{sonnet, haiku, gpt}
+
+	msg := model.ExecuteCommand("/resume " + sessionIDs[0])()
+	resumed, ok := msg.(sessionResumedMsg)
+	require.True(t, ok)
+	require.NoError(t, resumed.err)
+
+	status := sentStatus(t, model.applyResumedSession(resumed))
+	assert.Equal(t, haiku.ID, model.currentModel.ID)
+	assert.Equal(t, haiku.ID, model.modelsState.CurrentModel.ID)
+	assert.Len(t, model.chatState.Messages, 2)
+	assert.Equal(t, sessionIDs[0], logger.GetCurrentSession().SessionID)
+	assert.NotContains(t, status, "unavailable")
+
+	// A model that's gone is replaced by the closest of the same provider
+	resumed = model.ExecuteCommand("/resume " + sessionIDs[1])().(sessionResumedMsg)
+	require.NoError(t, resumed.err)
+	status = sentStatus(t, model.applyResumedSession(resumed))
+	assert.Equal(t, sonnet.ID, model.currentModel.ID)
+	assert.Contains(t, status, "claude-3-opus-20240229 is unavailable, using Claude 3.5 Sonnet")
+}
+
+// compareProvider answers with the requested model's ID, tracking how many
+// requests are in flight at once
+type compareProvider struct {
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+}
+
+func (p *compareProvider) Chat(ctx context.Context, req *api.ChatRequest) (*api.ChatResponse, error) {
+	n := p.inFlight.Add(1)
+	defer p.inFlight.Add(-1)
+	for {
+		peak := p.maxInFlight.Load()
+		if n <= peak || p.maxInFlight.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	return &api.ChatResponse{
+		Content: "answer from " + req.Model.ID,
+		Usage:   &api.Usage{InputTokens: 12, OutputTokens: 30},
+	}, nil
+}
+
+func (p *compareProvider) ChatStream(ctx context.Context, req *api.ChatRequest) (<-chan api.StreamChunk, <-chan error) {
+	return nil, nil
+}
+
+func (p *compareProvider) GetModels(ctx context.Context) ([]api.Model, error) { return nil, nil }
+
+func (p *compareProvider) ValidateCredentials(ctx context.Context) error { return nil }
+
+func TestStartupHealthCheckPopulatesHealth(t *testing.T) {
+	transport := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		respond := func(status int, body string) (*http.Response, error) {
+			return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body)), Request: req}, nil
+		}
+		switch req.URL.Host {
+		case "api.anthropic.com":
+			return respond(http.StatusOK, `{"data":[]}`)
+		case "api.openai.com":
+			return respond(http.StatusUnauthorized, `{"error":{"message":"Incorrect API key provided"}}`)
+		default:
+			return nil, errors.New("network unreachable")
+		}
+	})
+
+	keys := map[api.Provider]string{
+		api.ProviderAnthropic:  "sk-ant-valid",
+		api.ProviderOpenAI:     "sk-revoked",
+		api.ProviderOpenRouter: "sk-or-offline",
+	}
+	results := probeProviders(context.Background(), keys, nil, &http.Client{Transport: transport})
+	msg := ProviderHealthMsg{Results: results}
+
+	assert.Equal(t, map[string]bool{"anthropic": true, "openai": false, "openrouter": false}, msg.Healthy())
+	require.Len(t, msg.Problems(), 2)
+	assert.Equal(t, providers.ConnectionInvalidKey, msg.Problems()[0].Status)
+
+	// The default provider is checked even without a key
+	results = probeProviders(context.Background(), map[api.Provider]string{api.ProviderOpenAI: ""}, nil, &http.Client{Transport: transport})
+	require.Len(t, results, 1)
+	assert.Equal(t, providers.ConnectionMissingKey, results[0].Status)
+
+	model := New()
+	cmd := model.reportProviderHealth(msg)
+	require.NotNil(t, cmd)
+	status := cmd().(statusMsg).message
+	assert.Contains(t, status, "openai: Invalid API key")
+	assert.Contains(t, status, "openrouter: Network error")
+
+	// The status bar keeps showing each provider's health
+	model.Update(tea.WindowSizeMsg{Width: 160, Height: 24})
+	bar := model.renderStatusBar()
+	assert.Contains(t, bar, "✓ Anthropic")
+	assert.Contains(t, bar, "✗ OpenAI")
+	assert.Contains(t, bar, "✗ OpenRouter")
+
+	// Until a connection to the provider works
+	model.currentModel = api.Model{ID: "gpt-4o", Provider: api.ProviderOpenAI}
+	model.Update(connectionStateMsg{api.ConnectionConnected})
+	assert.Contains(t, model.renderStatusBar(), "✓ OpenAI")
+
+	// Off unless configured
+	model.config = &storage.Config{}
+	assert.Nil(t, model.checkProviderHealth())
+}
+
+func TestCompareShowsEveryModelsAnswer(t *testing.T) {
+	provider := &compareProvider{}
+
+	model := New()
+	model.logger = log.New(os.Stderr)
+	model.config = &storage.Config{MaxConcurrentRequests: 1}
+	model.currentModel = api.Model{ID: "gpt-4o", Name: "GPT-4o", Provider: api.ProviderOpenAI}
+	model.apiClient = provider
+	model.width, model.height = 120, 40
+	model.ready = true
+	model.TransitionTo(StateChat)
+
+	cmd := model.ExecuteCommand("/compare gpt-4o,gpt-4o-mini Explain goroutines")
+	if !assert.NotNil(t, cmd) {
+		return
+	}
+	assert.True(t, model.chatState.WaitingForAPI)
+
+	done, ok := cmd().(tea.BatchMsg)[1]().(compareDoneMsg)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, int32(1), provider.maxInFlight.Load(), "MaxConcurrentRequests should bound the requests")
+
+	results := done.comparison.Results
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, "answer from gpt-4o", results[0].Response.Content)
+		assert.Equal(t, "answer from gpt-4o-mini", results[1].Response.Content)
+	}
+
+	model.handleAPIMessages(done)
+	assert.False(t, model.chatState.WaitingForAPI)
+
+	view := model.View()
+	assert.Contains(t, view, "answer from gpt-4o")
+	assert.Contains(t, view, "answer from gpt-4o-mini")
+	assert.Contains(t, view, "42 tok")
+
+	// Narrow terminals stack the answers instead
+	model.width = 50
+	view = model.renderComparison(model.chatState.Comparison)
+	assert.Less(t, strings.Index(view, "answer from gpt-4o\n"), strings.Index(view, "answer from gpt-4o-mini"))
+}
+
+func TestCompareModelsRunsConcurrently(t *testing.T) {
+	provider := &compareProvider{}
+	models := []api.Model{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}}
+	clientFor := func(api.Model) (api.ProviderInterface, error) { return provider, nil }
+
+	results := compareModels(context.Background(), api.ChatRequest{}, models, 0, clientFor)
+	assert.Len(t, results, 4)
+	assert.Greater(t, provider.maxInFlight.Load(), int32(1))
+
+	provider.maxInFlight.Store(0)
+	compareModels(context.Background(), api.ChatRequest{}, models, 2, clientFor)
+	assert.LessOrEqual(t, provider.maxInFlight.Load(), int32(2))
+}
+
+func TestRepeatedPromptIsAnsweredFromCache(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	model := New()
+	model.logger = log.New(os.Stderr)
+	model.config = &storage.Config{ResponseCache: true, CacheDuration: time.Hour}
+	model.currentModel = api.Model{ID: "gpt-4o", Name: "GPT-4o", Provider: api.ProviderOpenAI}
+
+	// The first send goes to the API and its reply is cached
+	request, ok := model.sendChatMessage("What is a goroutine?")().(apiRequestMsg)
+	if !assert.True(t, ok) {
+		return
+	}
+	model.handleAPIMessages(request)
+	model.handleAPIMessages(apiResponseMsg{&api.ChatResponse{
+		Content: "A lightweight thread.",
+		Usage:   &api.Usage{InputTokens: 10, OutputTokens: 5},
+	}})
+
+	// The same conversation is answered without a request
+	model.chatState.ClearMessages()
+	cmd := model.sendChatMessage("What is a goroutine?")
+	assert.Contains(t, cmd().(statusMsg).message, "Answered from cache")
+	assert.False(t, model.chatState.WaitingForAPI)
+	if assert.Len(t, model.chatState.Messages, 2) {
+		reply := model.chatState.Messages[1]
+		assert.Equal(t, "A lightweight thread.", reply.Content)
+		assert.True(t, reply.Cached)
+		assert.Nil(t, reply.Usage)
+	}
+
+	// /nocache asks the model again
+	model.chatState.ClearMessages()
+	_, ok = model.ExecuteCommand("/nocache What is a goroutine?")().(apiRequestMsg)
+	assert.True(t, ok)
+
+	model.chatState.WaitingForAPI = false
+	assert.Contains(t, model.ExecuteCommand("/cache clear")().(statusMsg).message, "Cleared 1 cached responses")
+}
+
+func TestLengthFinishMarksReplyTruncated(t *testing.T) {
+	model := New()
+	model.logger = log.New(os.Stderr)
+	model.config = &storage.Config{BriefMaxTokens: 150}
+	model.currentModel = api.Model{ID: "claude-3-5-haiku-20241022", Name: "Claude 3.5 Haiku", Provider: api.ProviderAnthropic}
+	model.width = 80
+
+	// /brief caps the next request only
+	sent, ok := model.ExecuteCommand("/brief Summarize Go in one line")().(apiRequestMsg)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, 150, sent.request.MaxTokens)
+	assert.False(t, model.chatState.BriefNext)
+
+	cmd := model.handleAPIMessages(apiResponseMsg{&api.ChatResponse{
+		Content:      "Go is a statically typed",
+		FinishReason: api.FinishReasonLength,
+	}})
+	assert.Contains(t, cmd().(statusMsg).message, "token limit")
+
+	reply := model.chatState.Messages[len(model.chatState.Messages)-1]
+	assert.True(t, reply.Truncated)
+	assert.Contains(t, model.renderSingleMessage(reply), "…(truncated)")
+
+	// Continuing extends the same reply without the limit
+	resumed, ok := model.ExecuteCommand("/continue")().(apiRequestMsg)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Zero(t, resumed.request.MaxTokens)
+	assert.Equal(t, "assistant", resumed.request.Messages[len(resumed.request.Messages)-1].Role)
+
+	model.handleAPIMessages(apiResponseMsg{&api.ChatResponse{Content: ", compiled language.", FinishReason: api.FinishReasonStop}})
+	if assert.Len(t, model.chatState.Messages, 2) {
+		reply = model.chatState.Messages[1]
+		assert.Equal(t, "Go is a statically typed, compiled language.", reply.Content)
+		assert.False(t, reply.Truncated)
+		assert.NotContains(t, model.renderSingleMessage(reply), "truncated")
+	}
+}
+
+func TestContinueJoinsReplyAfterTrailingSpace(t *testing.T) {
+	model := New()
+	model.logger = log.New(os.Stderr)
+	model.currentModel = api.Model{ID: "claude-3-5-haiku-20241022", Name: "Claude 3.5 Haiku", Provider: api.ProviderAnthropic}
+	model.TransitionTo(StateOnboarding)
+	model.TransitionTo(StateChat)
+	model.chatState.Messages = []api.Message{
+		{Role: "user", Content: "Tell me a story"},
+		{Role: "assistant", Content: "Once upon a ", Truncated: true},
+	}
+
+	sent, ok := model.ExecuteCommand("/continue")().(apiRequestMsg)
+	require.True(t, ok)
+	assert.Equal(t, "Once upon a", sent.request.Messages[len(sent.request.Messages)-1].Content)
+
+	// The streamed continuation is joined onto the reply it extends
+	model.handleChatState(apiStreamChunkMsg{" time."})
+	model.handleChatState(apiStreamDoneMsg{finishReason: api.FinishReasonStop})
+	require.Len(t, model.chatState.Messages, 2)
+	assert.Equal(t, "Once upon a time.", model.chatState.Messages[1].Content)
+}
+
+func TestContextWindowWarningAtNinetyPercent(t *testing.T) {
+	model := New()
+	model.logger = log.New(os.Stderr)
+	model.config = &storage.Config{}
+	model.currentModel = api.Model{ID: "tiny", Name: "Tiny", ContextWindow: 100}
+
+	// 80 tokens is past the status bar notice but below the warning
+	model.chatState.AddMessage(api.Message{Role: "user", Content: strings.Repeat("a", 320)})
+	assert.Nil(t, model.checkContextWindow())
+	assert.Contains(t, model.renderContextUsage(), "Context 80%")
+
+	model.chatState.AddMessage(api.Message{Role: "assistant", Content: strings.Repeat("b", 48)})
+	cmd := model.checkContextWindow()
+	require.NotNil(t, cmd)
+	assert.Equal(t, ContextWarningMsg{Model: "Tiny", Used: 92, Window: 100}, cmd())
+
+	// The warning is raised once per crossing
+	assert.Nil(t, model.checkContextWindow())
+
+	// Sends that would overflow the window are refused and keep the draft
+	status, exceeds := model.exceedsContextWindow(strings.Repeat("c", 40))
+	assert.True(t, exceeds)
+	assert.Contains(t, status, "/trim")
+	model.sendMessage(strings.Repeat("c", 40), false)
+	assert.Len(t, model.chatState.Messages, 2)
+
+	// Trimming drops whole turns until the notice clears
+	model.handleTrimCommand(nil)
+	assert.Empty(t, model.chatState.Messages)
+	assert.False(t, model.chatState.ContextWarned)
+}
+
+func TestContextWindowTable(t *testing.T) {
+	assert.Equal(t, 8192, ContextWindow(api.Model{ID: "gpt-4-0613"}))
+	assert.Equal(t, 128000, ContextWindow(api.Model{ID: "gpt-4o-mini"}))
+	assert.Equal(t, 5000, ContextWindow(api.Model{ID: "gpt-4o", ContextWindow: 5000}))
+	assert.Zero(t, ContextWindow(api.Model{ID: "mystery"}))
+}
+
+func TestTokenBudgetDropsWholeTurns(t *testing.T) {
+	turn := func(question, answer int) []api.Message {
+		return []api.Message{
+			{Role: "user", Content: strings.Repeat("q", question*4)},
+			{Role: "assistant", Content: strings.Repeat("a", answer*4)},
+		}
+	}
+	var messages []api.Message
+	for _, sizes := range [][2]int{{10, 50}, {10, 20}, {5, 5}, {20, 30}} {
+		messages = append(messages, turn(sizes[0], sizes[1])...)
+	}
+
+	// 150 tokens in all; fitting 70 drops the first two turns whole
+	kept, turns, tokens := trimToTokenBudget(messages, 70)
+	assert.Equal(t, 2, turns)
+	assert.Equal(t, 90, tokens)
+	assert.Equal(t, messages[4:], kept)
+
+	// The latest turn survives even when it alone is over the budget
+	kept, turns, _ = trimToTokenBudget(messages, 10)
+	assert.Equal(t, 3, turns)
+	assert.Equal(t, messages[6:], kept)
+
+	kept, turns, _ = trimToTokenBudget(messages, 150)
+	assert.Zero(t, turns)
+	assert.Len(t, kept, 8)
+
+	// Sending trims the conversation and says what was dropped
+	model := New()
+	model.logger = log.New(os.Stderr)
+	model.config = &storage.Config{HistoryTokenBudget: 70}
+	model.currentModel = api.Model{ID: "claude-3-5-sonnet-20241022", Name: "Claude 3.5 Sonnet"}
+	model.chatState.Messages = append([]api.Message(nil), messages...)
+	cmd := model.sendMessage(strings.Repeat("n", 40), false)
+
+	request := sentRequest(t, cmd)
+	assert.Len(t, request.Messages, 5)
+	assert.Equal(t, messages[4:], model.chatState.Messages[:4])
+	assert.Contains(t, sentStatus(t, cmd), "Dropped 2 oldest turns (~90 tokens)")
+}
+
+// fakeSummarizer records what it was asked to summarize
+type fakeSummarizer struct {
+	got []api.Message
+}
+
+func (f *fakeSummarizer) Summarize(ctx context.Context, messages []api.Message) (string, error) {
+	f.got = messages
+	return fmt.Sprintf("%d messages about loops", len(messages)), nil
+}
+
+func TestSummarizeOlderReplacesFirstTurns(t *testing.T) {
+	messages := []api.Message{
+		{Role: "user", Content: "one"},
+		{Role: "assistant", Content: "1"},
+		{Role: "user", Content: "two"},
+		{Role: "assistant", Content: "2"},
+		{Role: "user", Content: "three"},
+		{Role: "assistant", Content: "3"},
+	}
+
+	// Keeping three messages would split a turn, so only the first two go
+	summarizer := &fakeSummarizer{}
+	condensed, summarized, err := SummarizeOlder(context.Background(), summarizer, messages, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 4, summarized)
+	assert.Equal(t, messages[:4], summarizer.got)
+	require.Len(t, condensed, 3)
+	assert.Equal(t, "system", condensed[0].Role)
+	assert.Equal(t, summaryPrefix+"4 messages about loops", condensed[0].Content)
+	assert.Equal(t, messages[4:], condensed[1:])
+
+	// A lone summary isn't summarized again
+	_, summarized, err = SummarizeOlder(context.Background(), summarizer, condensed, 2)
+	require.NoError(t, err)
+	assert.Zero(t, summarized)
+
+	// The summary is sent as part of the system prompt
+	model := New()
+	model.logger = log.New(os.Stderr)
+	model.config = &storage.Config{SystemPrompt: "Be brief."}
+	model.chatState.Messages = condensed
+	request := model.requestMessages()
+	require.Len(t, request, 3)
+	assert.Equal(t, "Be brief.\n\n"+condensed[0].Content, request[0].Content)
+	assert.Equal(t, "user", request[1].Role)
+}
+
+func TestAutoSummarizeSendsAfterCondensing(t *testing.T) {
+	model := New()
+	model.logger = log.New(os.Stderr)
+	model.config = &storage.Config{AutoSummarize: true}
+	model.currentModel = api.Model{ID: "tiny", Name: "Tiny", ContextWindow: 100}
+	model.summarizer = &fakeSummarizer{}
+	for i := 0; i < 4; i++ {
+		model.chatState.AddMessage(api.Message{Role: "user", Content: strings.Repeat("q", 40)})
+		model.chatState.AddMessage(api.Message{Role: "assistant", Content: strings.Repeat("a", 40)})
+	}
+
+	cmd := model.sendMessage(strings.Repeat("c", 120), false)
+	require.NotNil(t, cmd)
+	var done summarizeDoneMsg
+	for _, msg := range cmd().(tea.BatchMsg) {
+		if result, ok := msg().(summarizeDoneMsg); ok {
+			done = result
+		}
+	}
+	require.Equal(t, 4, done.summarized)
+
+	model.applySummary(done)
+	require.Len(t, model.chatState.Messages, 6)
+	assert.True(t, isSummary(model.chatState.Messages[0]))
+	assert.Equal(t, strings.Repeat("c", 120), model.chatState.Messages[5].Content)
+}
+
+func TestIdleConversationIsArchived(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	logger, err := storage.NewChatLogger()
+	require.NoError(t, err)
+	require.NoError(t, logger.StartSession())
+	require.NoError(t, logger.LogMessage(storage.Message{Role: "user", Content: "hi"}))
+	archivedID := logger.GetCurrentSession().SessionID
+
+	model := New()
+	model.storage = &storage.Storage{ChatLogger: logger}
+	model.config = &storage.Config{IdleArchiveMinutes: 30}
+	model.TransitionTo(StateChat)
+	model.chatState.AddMessage(api.Message{Role: "user", Content: "hi"})
+
+	// Recent input keeps the conversation open
+	now := time.Now()
+	model.lastActivity = now.Add(-10 * time.Minute)
+	assert.Nil(t, model.checkIdle(now))
+	assert.Len(t, model.chatState.Messages, 1)
+
+	// After the timeout it is saved and a new session begins
+	model.lastActivity = now.Add(-31 * time.Minute)
+	cmd := model.checkIdle(now)
+	require.NotNil(t, cmd)
+	cmd()
+	assert.Empty(t, model.chatState.Messages)
+	assert.True(t, model.chatState.IdleArchived)
+	assert.NotEqual(t, archivedID, logger.GetCurrentSession().SessionID)
+	assert.Contains(t, model.renderMessages(20), "archived due to inactivity")
+
+	archived, err := logger.GetSession(archivedID)
+	require.NoError(t, err)
+	assert.Len(t, archived.Messages, 1)
+
+	// The next message starts the new conversation
+	model.chatState.AddMessage(api.Message{Role: "user", Content: "new topic"})
+	assert.False(t, model.chatState.IdleArchived)
+
+	// The archive is off by default
+	model.config.IdleArchiveMinutes = 0
+	assert.Nil(t, model.checkIdle(now.Add(24*time.Hour)))
+}
+
+func TestNotificationsCommandListsPastEvents(t *testing.T) {
+	model := New()
+	model.logger = log.New(os.Stderr)
+	model.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	model.TransitionTo(StateOnboarding)
+	model.TransitionTo(StateChat)
+
+	// Status messages that have already expired stay in the log
+	model.Update(statusMsg{"Connection lost, retrying", time.Millisecond})
+	model.Update(clearStatusMsg{})
+	model.setError(errors.New("rate limited"), "API request failed", true)
+	model.TransitionTo(StateChat)
+
+	model.Update(model.ExecuteCommand("/notifications")())
+	require.NotNil(t, model.chatState.EventLog)
+	view := model.View()
+	assert.Contains(t, view, "Connection lost, retrying")
+	assert.Contains(t, view, "API request failed: rate limited")
+	assert.Contains(t, view, "error")
+
+	// Esc closes the log; /notifications clear empties it
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Nil(t, model.chatState.EventLog)
+	model.Update(NotificationLogMsg{Clear: true})
+	assert.Empty(t, model.eventLog)
+}
+
+// droppingProvider drops the first stream before any content arrives, then
+// answers on the reconnect
+type droppingProvider struct {
+	calls atomic.Int32
+}
+
+func (p *droppingProvider) Chat(ctx context.Context, req *api.ChatRequest) (*api.ChatResponse, error) {
+	return nil, errors.New("not used")
+}
+
+func (p *droppingProvider) ChatStream(ctx context.Context, req *api.ChatRequest) (<-chan api.StreamChunk, <-chan error) {
+	chunks := make(chan api.StreamChunk, 2)
+	errs := make(chan error, 1)
+	if p.calls.Add(1) == 1 {
+		errs <- io.ErrUnexpectedEOF
+	} else {
+		chunks <- api.StreamChunk{Content: "hello"}
+		chunks <- api.StreamChunk{Done: true, FinishReason: "stop"}
+	}
+	close(chunks)
+	close(errs)
+	return chunks, errs
+}
+
+func (p *droppingProvider) GetModels(ctx context.Context) ([]api.Model, error) { return nil, nil }
+
+func (p *droppingProvider) ValidateCredentials(ctx context.Context) error { return nil }
+
+func TestStatusBarShowsStreamReconnects(t *testing.T) {
+	model := New()
+	model.logger = log.New(os.Stderr)
+	model.Update(tea.WindowSizeMsg{Width: 120, Height: 24})
+	model.TransitionTo(StateOnboarding)
+	model.TransitionTo(StateChat)
+
+	provider := &droppingProvider{}
+	wrapped, err := model.newAPIClient(provider)
+	require.NoError(t, err)
+	client := wrapped.(*api.Client)
+	retry := api.DefaultRetryConfig()
+	retry.BaseDelay, retry.JitterMax = time.Millisecond, 0
+	client.SetRetryConfig(retry)
+
+	chunks, errs := client.ChatStream(context.Background(), &api.ChatRequest{
+		Model:    api.Model{ID: "claude-3-5-sonnet-20241022", Provider: api.ProviderAnthropic},
+		Messages: []api.Message{{Role: "user", Content: "hi"}},
+	})
+	var content string
+	for chunk := range chunks {
+		content += chunk.Content
+	}
+	require.NoError(t, <-errs)
+	assert.Equal(t, "hello", content)
+	assert.Equal(t, int32(2), provider.calls.Load())
+
+	// Connecting, dropped and reconnecting, then connected
+	var states []api.ConnectionState
+	for range 3 {
+		msg := waitForConnectionState(model.connectionStates)()
+		states = append(states, msg.(connectionStateMsg).state)
+		model.Update(msg)
+		if msg.(connectionStateMsg).state == api.ConnectionConnecting {
+			assert.Contains(t, model.View(), "Connecting")
+		}
+	}
+	assert.Equal(t, []api.ConnectionState{api.ConnectionConnecting, api.ConnectionConnecting, api.ConnectionConnected}, states)
+	assert.Contains(t, model.View(), "● Connected")
+}
+
+func TestStreamRecordsReportedUsage(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	store, err := storage.NewUsageStore()
+	require.NoError(t, err)
+
+	model := New()
+	model.logger = log.New(os.Stderr)
+	model.storage = &storage.Storage{UsageStore: store}
+	model.currentModel = api.Model{ID: "claude-3-5-sonnet-20241022", Provider: api.ProviderAnthropic}
+	model.TransitionTo(StateOnboarding)
+	model.TransitionTo(StateChat)
+
+	// Tokens and cost come from the provider's usage, input included
+	model.Update(apiStreamChunkMsg{"one two three"})
+	model.Update(apiStreamDoneMsg{finishReason: "stop", usage: &api.Usage{InputTokens: 1000, OutputTokens: 200}})
+	require.Eventually(t, func() bool {
+		totals, err := store.Load()
+		return err == nil && totals.TotalRequests == 1
+	}, time.Second, 10*time.Millisecond)
+	totals, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1200), totals.TotalTokens)
+	assert.InDelta(t, 0.006, totals.TotalCost, 1e-9)
+
+	// A reply without reported usage isn't guessed at
+	model.Update(apiStreamChunkMsg{"four five"})
+	model.Update(apiStreamDoneMsg{finishReason: "stop"})
+	time.Sleep(50 * time.Millisecond)
+	totals, err = store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), totals.TotalRequests)
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultBriefMaxTokens caps /brief replies when Config.BriefMaxTokens is unset
+const defaultBriefMaxTokens = 300
+
+// truncatedMarker follows replies that stopped at the token limit
+const truncatedMarker = "…(truncated) · /continue for more"
+
+// briefMaxTokens returns the output limit for a /brief message
+func (m *Model) briefMaxTokens() int {
+	if m.config != nil && m.config.BriefMaxTokens > 0 {
+		return m.config.BriefMaxTokens
+	}
+	return defaultBriefMaxTokens
+}
+
+// handleBriefCommand limits the reply to the next message. With a message
+// it is sent right away; without one the limit applies to the next send.
+func (m *Model) handleBriefCommand(args []string) tea.Cmd {
+	content := strings.TrimSpace(strings.Join(args, " "))
+	if content != "" {
+		m.chatState.BriefNext = true
+		return m.sendChatMessage(content)
+	}
+
+	m.chatState.BriefNext = !m.chatState.BriefNext
+	status := "Brief reply off"
+	if m.chatState.BriefNext {
+		status = fmt.Sprintf("Next reply limited to %d tokens", m.briefMaxTokens())
+	}
+	return func() tea.Msg {
+		return statusMsg{status, 3 * time.Second}
+	}
+}
+
+// truncatedStatus tells the user a reply hit the length limit
+func (m *Model) truncatedStatus() tea.Cmd {
+	return func() tea.Msg {
+		return statusMsg{"Reply stopped at the token limit · /continue for more", 5 * time.Second}
+	}
+}
+
+// handleContinueCommand asks for the rest of a truncated reply, without the
+// /brief limit. Providers that support continuation extend the reply in
+// place; for the others the model is asked to go on in a new turn.
+func (m *Model) handleContinueCommand(args []string) tea.Cmd {
+	last := len(m.chatState.Messages) - 1
+	if last < 0 || m.chatState.Messages[last].Role != "assistant" || !m.chatState.Messages[last].Truncated {
+		return func() tea.Msg {
+			return statusMsg{"The last reply wasn't truncated", 2 * time.Second}
+		}
+	}
+
+	m.chatState.Messages[last].Truncated = false
+	m.chatState.TurnMaxTokens = 0
+
+	if !m.currentModel.Provider.SupportsContinuation() {
+		return m.sendMessage("Continue exactly where you stopped.", false)
+	}
+
+	m.chatState.Recovery = &StreamRecovery{Continuing: true}
+	request := m.continuationRequest()
+	m.chatState.WaitingForAPI = true
+	return func() tea.Msg {
+		return apiRequestMsg{request}
+	}
+}
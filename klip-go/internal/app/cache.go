@@ -0,0 +1,165 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/john/klip/internal/api"
+	"github.com/john/klip/internal/storage"
+)
+
+// cache returns the response cache, opening it on first use, or nil when
+// response caching is turned off
+func (m *Model) cache() *storage.ResponseCache {
+	if m.config == nil || !m.config.ResponseCache {
+		return nil
+	}
+	if m.responseCache == nil {
+		cache, err := storage.NewResponseCache(m.config.CacheDuration)
+		if err != nil {
+			m.logger.Warn("Response cache unavailable", "error", err)
+			return nil
+		}
+		m.responseCache = cache
+	}
+	return m.responseCache
+}
+
+// responseCacheKey identifies a request in the response cache, or returns
+// "" when caching is off
+func (m *Model) responseCacheKey(request *api.ChatRequest) string {
+	// Length-limited turns would cache a shortened reply
+	if m.cache() == nil || request == nil || m.chatState.TurnMaxTokens > 0 {
+		return ""
+	}
+
+	messages := make([]storage.Message, len(request.Messages))
+	for i, msg := range request.Messages {
+		messages[i] = storage.Message{Role: msg.Role, Content: msg.Content}
+	}
+	return storage.ResponseCacheKey(request.Model.ID, messages)
+}
+
+// replyFromCache answers request from the response cache, returning nil
+// on a miss so the caller goes to the API
+func (m *Model) replyFromCache(request *api.ChatRequest) tea.Cmd {
+	key := m.responseCacheKey(request)
+	if key == "" {
+		return nil
+	}
+	cached, ok := m.responseCache.Get(key)
+	if !ok {
+		return nil
+	}
+
+	// No usage is recorded, so the reply adds nothing to cost totals
+	assistantMsg := api.Message{
+		Role:      "assistant",
+		Content:   cached.Content,
+		Timestamp: time.Now(),
+		Model:     request.Model.ID,
+		Cached:    true,
+	}
+	m.chatState.AddMessage(assistantMsg)
+
+	if m.storage != nil && m.storage.ChatLogger != nil {
+		provider := string(request.Model.Provider)
+		go func() {
+			storageMsg := storage.Message{
+				Role:      assistantMsg.Role,
+				Content:   assistantMsg.Content,
+				Timestamp: assistantMsg.Timestamp,
+				Model:     assistantMsg.Model,
+				Provider:  provider,
+			}
+			if err := m.storage.ChatLogger.LogMessage(storageMsg); err != nil {
+				m.logger.Error("Failed to log cached message", "error", err)
+			}
+		}()
+	}
+
+	age := time.Since(cached.CreatedAt).Round(time.Second)
+	return func() tea.Msg {
+		return statusMsg{fmt.Sprintf("Answered from cache (%s old) · /nocache to ask again", age), 4 * time.Second}
+	}
+}
+
+// cacheResponse stores a finished reply under the key of the request that
+// produced it
+func (m *Model) cacheResponse(msg api.Message) {
+	key := m.chatState.PendingCacheKey
+	m.chatState.PendingCacheKey = ""
+	if key == "" || m.responseCache == nil || msg.Truncated || strings.TrimSpace(msg.Content) == "" {
+		return
+	}
+
+	response := storage.CachedResponse{Model: msg.Model, Content: msg.Content, Tokens: storageTokens(msg.Usage)}
+	if response.Model == "" {
+		response.Model = m.currentModel.ID
+	}
+
+	if err := m.responseCache.Put(key, response); err != nil {
+		m.logger.Warn("Failed to cache response", "error", err)
+	}
+}
+
+// storageTokens converts reported usage to the token counts stored with a
+// message, counting cached input with the rest of the input
+func storageTokens(usage *api.Usage) *storage.Tokens {
+	if usage == nil {
+		return nil
+	}
+	input := usage.TotalInputTokens()
+	return &storage.Tokens{Input: input, Output: usage.OutputTokens, Total: input + usage.OutputTokens}
+}
+
+// handleNoCacheCommand sends a message to the model even if a cached reply
+// exists; the fresh reply replaces the cached one
+func (m *Model) handleNoCacheCommand(args []string) tea.Cmd {
+	content := strings.TrimSpace(strings.Join(args, " "))
+	if content == "" {
+		return func() tea.Msg {
+			return statusMsg{"Usage: /nocache <message>", 2 * time.Second}
+		}
+	}
+	return m.sendMessage(content, false)
+}
+
+// handleCacheCommand reports on the response cache or clears it, along
+// with the cached model lists
+func (m *Model) handleCacheCommand(args []string) tea.Cmd {
+	clear := len(args) > 0 && args[0] == "clear"
+	if clear && m.modelManager != nil {
+		m.modelManager.ClearCache()
+	}
+
+	cache := m.cache()
+	if cache == nil {
+		if clear {
+			return func() tea.Msg {
+				return statusMsg{"Cleared cached model lists", 3 * time.Second}
+			}
+		}
+		return func() tea.Msg {
+			return statusMsg{"Response cache is off; enable Cache Responses in settings", 3 * time.Second}
+		}
+	}
+
+	if clear {
+		removed, err := cache.Clear()
+		if err != nil {
+			m.setError(err, "Failed to clear response cache", true)
+			return nil
+		}
+		return func() tea.Msg {
+			return statusMsg{fmt.Sprintf("Cleared %d cached responses", removed), 3 * time.Second}
+		}
+	}
+
+	status := fmt.Sprintf("%d cached responses, kept for %s · /cache clear to remove", cache.Len(), cache.TTL())
+	return func() tea.Msg {
+		return statusMsg{status, 4 * time.Second}
+	}
+}
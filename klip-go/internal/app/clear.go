@@ -0,0 +1,112 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/john/klip/internal/api"
+)
+
+// clearUndoWindow is how long a cleared conversation can be restored
+const clearUndoWindow = 30 * time.Second
+
+// ClearedConversation is a conversation /clear removed, kept for a short
+// while so it can be restored
+type ClearedConversation struct {
+	Messages []api.Message
+	At       time.Time
+}
+
+// handleClearCommand asks before clearing the conversation; /clear! skips
+// the question
+func (m *Model) handleClearCommand(args []string) tea.Cmd {
+	if len(m.chatState.Messages) == 0 {
+		return func() tea.Msg {
+			return statusMsg{"Nothing to clear", 2 * time.Second}
+		}
+	}
+
+	m.chatState.ClearPending = true
+	status := fmt.Sprintf("Clear %d messages? y clear · n cancel", len(m.chatState.Messages))
+	return func() tea.Msg {
+		return statusMsg{status, 30 * time.Second}
+	}
+}
+
+// handleForceClearCommand clears the conversation without asking
+func (m *Model) handleForceClearCommand(args []string) tea.Cmd {
+	return m.clearConversation()
+}
+
+// handleClearKeys answers the question raised by handleClearCommand; other
+// keys are ignored until it is answered
+func (m *Model) handleClearKeys(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "y", "Y":
+		m.chatState.ClearPending = false
+		return m.clearConversation()
+	case "n", "N", "esc":
+		m.chatState.ClearPending = false
+		return func() tea.Msg {
+			return statusMsg{"Not cleared", 2 * time.Second}
+		}
+	}
+	return nil
+}
+
+// clearConversation clears the conversation and its saved session, keeping
+// both for clearUndoWindow so ctrl+z or /undo can bring them back
+func (m *Model) clearConversation() tea.Cmd {
+	if len(m.chatState.Messages) > 0 {
+		m.chatState.Cleared = &ClearedConversation{Messages: m.chatState.Messages, At: time.Now()}
+	}
+	m.chatState.ClearMessages()
+
+	// Cleared in line rather than in the background, so an undo straight
+	// after can't overtake it
+	if m.storage != nil && m.storage.ChatLogger != nil {
+		if err := m.storage.ChatLogger.ClearLog(); err != nil {
+			m.logger.Error("Failed to clear chat log", "error", err)
+		}
+	}
+
+	return func() tea.Msg {
+		return statusMsg{"Chat history cleared · ctrl+z or /undo to restore", clearUndoWindow}
+	}
+}
+
+// handleUndoCommand restores the conversation /clear just removed
+func (m *Model) handleUndoCommand(args []string) tea.Cmd {
+	return m.undoClear(time.Now())
+}
+
+// undoClear restores the cleared conversation while it is still within
+// clearUndoWindow and nothing new has been said since
+func (m *Model) undoClear(now time.Time) tea.Cmd {
+	cleared := m.chatState.Cleared
+	if cleared == nil || now.Sub(cleared.At) > clearUndoWindow {
+		m.chatState.Cleared = nil
+		return func() tea.Msg {
+			return statusMsg{"Nothing to undo", 2 * time.Second}
+		}
+	}
+	if len(m.chatState.Messages) > 0 {
+		return func() tea.Msg {
+			return statusMsg{"Can't restore the cleared chat after new messages", 3 * time.Second}
+		}
+	}
+
+	m.chatState.Messages = cleared.Messages
+	m.chatState.Cleared = nil
+	if m.storage != nil && m.storage.ChatLogger != nil {
+		if err := m.storage.ChatLogger.RestoreClearedLog(); err != nil {
+			m.logger.Error("Failed to restore chat log", "error", err)
+		}
+	}
+
+	status := fmt.Sprintf("Restored %d messages", len(cleared.Messages))
+	return func() tea.Msg {
+		return statusMsg{status, 2 * time.Second}
+	}
+}
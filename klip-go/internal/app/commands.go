@@ -1,13 +1,22 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/atotto/clipboard"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/john/klip/internal/api"
+	"github.com/john/klip/internal/storage"
+	"github.com/john/klip/internal/ui/styles"
 )
 
 // Command represents a slash command
@@ -65,10 +74,62 @@ func (cr *CommandRegistry) registerBuiltinCommands() {
 		{
 			Name:        "clear",
 			Aliases:     []string{"cls", "c"},
-			Description: "Clear chat history",
+			Description: "Clear chat history, after confirming",
 			Usage:       "/clear",
 			Handler:     (*Model).handleClearCommand,
 		},
+		{
+			Name:        "clear!",
+			Description: "Clear chat history without confirming",
+			Usage:       "/clear!",
+			Handler:     (*Model).handleForceClearCommand,
+		},
+		{
+			Name:        "undo",
+			Description: "Restore the conversation /clear just removed",
+			Usage:       "/undo",
+			Handler:     (*Model).handleUndoCommand,
+		},
+		{
+			Name:        "trim",
+			Description: "Remove the oldest turns to free up the context window",
+			Usage:       "/trim [turns]",
+			Handler:     (*Model).handleTrimCommand,
+		},
+		{
+			Name:        "estimate",
+			Aliases:     []string{"cost"},
+			Description: "Preview what sending a message would cost, without sending it",
+			Usage:       "/estimate [message]",
+			Handler:     (*Model).handleEstimateCommand,
+		},
+		{
+			Name:        "summarize",
+			Aliases:     []string{"condense"},
+			Description: "Condense older turns into a summary to free up the context window",
+			Usage:       "/summarize [messages to keep]",
+			Handler:     (*Model).handleSummarizeCommand,
+		},
+		{
+			Name:        "focus",
+			Aliases:     []string{"zen"},
+			Description: "Toggle focus mode, hiding everything but the chat",
+			Usage:       "/focus",
+			Handler:     (*Model).handleFocusCommand,
+		},
+		{
+			Name:        "theme",
+			Description: "Switch color theme",
+			Usage:       "/theme [name]",
+			Handler:     (*Model).handleThemeCommand,
+		},
+		{
+			Name:        "contrast",
+			Aliases:     []string{"hc"},
+			Description: "Toggle high contrast colors",
+			Usage:       "/contrast",
+			Handler:     (*Model).handleContrastCommand,
+		},
 		{
 			Name:        "history",
 			Aliases:     []string{"hist"},
@@ -76,18 +137,50 @@ func (cr *CommandRegistry) registerBuiltinCommands() {
 			Usage:       "/history [session-id]",
 			Handler:     (*Model).handleHistoryCommand,
 		},
+		{
+			Name:        "resume",
+			Aliases:     []string{"continue"},
+			Description: "Continue a saved session with the model it used",
+			Usage:       "/resume [session-id]",
+			Handler:     (*Model).handleResumeCommand,
+		},
 		{
 			Name:        "export",
 			Aliases:     []string{"save", "download"},
 			Description: "Export chat session",
-			Usage:       "/export [format] [filename]",
+			Usage:       "/export [json|txt|md|html|pdf] [filename]",
 			Handler:     (*Model).handleExportCommand,
 		},
+		{
+			Name:        "export-all",
+			Description: "Export every session to a directory",
+			Usage:       "/export-all [format] [directory] [--zip]",
+			Handler:     (*Model).handleExportAllCommand,
+		},
+		{
+			Name:        "import",
+			Description: "Import ChatGPT or Claude conversation exports",
+			Usage:       "/import <file>",
+			Handler:     (*Model).handleImportCommand,
+		},
+		{
+			Name:        "share",
+			Aliases:     []string{"gist"},
+			Description: "Share the conversation as a secret gist or Markdown file",
+			Usage:       "/share [title]",
+			Handler:     (*Model).handleShareCommand,
+		},
+		{
+			Name:        "run",
+			Description: "Run a shell or Python block from the last reply, if enabled",
+			Usage:       "/run [block]",
+			Handler:     (*Model).handleRunCommand,
+		},
 		{
 			Name:        "settings",
 			Aliases:     []string{"config", "cfg"},
 			Description: "Open settings",
-			Usage:       "/settings [option] [value]",
+			Usage:       "/settings [section | option value]",
 			Handler:     (*Model).handleSettingsCommand,
 		},
 		{
@@ -97,13 +190,27 @@ func (cr *CommandRegistry) registerBuiltinCommands() {
 			Usage:       "/keys [provider] [key]",
 			Handler:     (*Model).handleKeysCommand,
 		},
+		{
+			Name:        "profile",
+			Aliases:     []string{"profiles"},
+			Description: "List profiles or switch to another, each with its own config, history and analytics",
+			Usage:       "/profile [name]",
+			Handler:     (*Model).handleProfileCommand,
+		},
 		{
 			Name:        "stats",
 			Aliases:     []string{"statistics", "analytics"},
 			Description: "Show usage statistics",
-			Usage:       "/stats",
+			Usage:       "/stats [days | copy]",
 			Handler:     (*Model).handleStatsCommand,
 		},
+		{
+			Name:        "sessioninfo",
+			Aliases:     []string{"session", "sinfo"},
+			Description: "Show statistics for the current or a saved session",
+			Usage:       "/sessioninfo [session-id]",
+			Handler:     (*Model).handleSessionInfoCommand,
+		},
 		{
 			Name:        "edit",
 			Aliases:     []string{"e"},
@@ -114,10 +221,50 @@ func (cr *CommandRegistry) registerBuiltinCommands() {
 		{
 			Name:        "retry",
 			Aliases:     []string{"r"},
-			Description: "Retry the last request",
-			Usage:       "/retry",
+			Description: "Send the request that failed again, optionally with the message edited",
+			Usage:       "/retry [edit | message]",
 			Handler:     (*Model).handleRetryCommand,
 		},
+		{
+			Name:        "regenerate",
+			Aliases:     []string{"regen"},
+			Description: "Ask again for the last reply",
+			Usage:       "/regenerate",
+			Handler:     (*Model).handleRegenerateCommand,
+		},
+		{
+			Name:        "compare",
+			Aliases:     []string{"cmp"},
+			Description: "Send a prompt to several models and compare the answers",
+			Usage:       "/compare <model-a,model-b> [prompt]",
+			Handler:     (*Model).handleCompareCommand,
+		},
+		{
+			Name:        "brief",
+			Aliases:     []string{"short"},
+			Description: "Limit the reply to the next message to a few hundred tokens",
+			Usage:       "/brief [message]",
+			Handler:     (*Model).handleBriefCommand,
+		},
+		{
+			Name:        "continue",
+			Aliases:     []string{"more"},
+			Description: "Continue a reply that stopped at the token limit",
+			Usage:       "/continue",
+			Handler:     (*Model).handleContinueCommand,
+		},
+		{
+			Name:        "nocache",
+			Description: "Send a message straight to the model, refreshing its cached reply",
+			Usage:       "/nocache <message>",
+			Handler:     (*Model).handleNoCacheCommand,
+		},
+		{
+			Name:        "cache",
+			Description: "Show or clear the response cache",
+			Usage:       "/cache [clear]",
+			Handler:     (*Model).handleCacheCommand,
+		},
 		{
 			Name:        "search",
 			Aliases:     []string{"find", "grep"},
@@ -139,6 +286,40 @@ func (cr *CommandRegistry) registerBuiltinCommands() {
 			Usage:       "/debug",
 			Handler:     (*Model).handleDebugCommand,
 		},
+		{
+			Name:        "notifications",
+			Aliases:     []string{"notifs", "events"},
+			Description: "Show the notification log",
+			Usage:       "/notifications [clear]",
+			Handler:     (*Model).handleNotificationsCommand,
+		},
+		{
+			Name:        "system",
+			Aliases:     []string{"prompt"},
+			Description: "Set the system prompt for this session",
+			Usage:       "/system [prompt|clear]",
+			Handler:     (*Model).handleSystemCommand,
+		},
+		{
+			Name:        "template",
+			Aliases:     []string{"tpl"},
+			Description: "Start a fresh conversation from a saved template",
+			Usage:       "/template <name>",
+			Handler:     (*Model).handleTemplateCommand,
+		},
+		{
+			Name:        "templates",
+			Description: "List, save or delete conversation templates",
+			Usage:       "/templates [save <name> [starter message] | delete <name>]",
+			Handler:     (*Model).handleTemplatesCommand,
+		},
+		{
+			Name:        "params",
+			Aliases:     []string{"parameters"},
+			Description: "Show or set temperature and max tokens for the current model",
+			Usage:       "/params [temperature <value>|max_tokens <value>|reset]",
+			Handler:     (*Model).handleParamsCommand,
+		},
 		{
 			Name:        "websearch",
 			Aliases:     []string{"web", "search-web"},
@@ -229,21 +410,26 @@ func (m *Model) ExecuteCommand(input string) tea.Cmd {
 
 	commandName := parts[0]
 	args := parts[1:]
+	m.commandArgs = strings.TrimLeft(input[len(commandName):], " \t")
 
 	// Get command from registry
-	registry := NewCommandRegistry()
-	cmd := registry.Get(commandName)
+	cmd := m.commands.Get(commandName)
 	if cmd == nil {
 		return func() tea.Msg {
 			return statusMsg{fmt.Sprintf("Unknown command: %s", commandName), 3 * time.Second}
 		}
 	}
 
-	// Log command execution
-	// TODO: Implement command logging when method is available
-
-	// Execute command
-	return cmd.Handler(m, args)
+	// Execute command, logging it under its canonical name so aliases
+	// count towards the same command
+	start := time.Now()
+	result := cmd.Handler(m, args)
+	if m.storage != nil && m.storage.AnalyticsLogger != nil {
+		if err := m.storage.AnalyticsLogger.LogCommand(cmd.Name, true, time.Since(start).Milliseconds()); err != nil {
+			m.logger.Warn("Failed to log command", "error", err)
+		}
+	}
+	return result
 }
 
 // Command handlers
@@ -253,8 +439,7 @@ func (m *Model) handleHelpCommand(args []string) tea.Cmd {
 	if len(args) > 0 {
 		// Show help for specific command
 		cmdName := args[0]
-		registry := NewCommandRegistry()
-		cmd := registry.Get(cmdName)
+		cmd := m.commands.Get(cmdName)
 		if cmd == nil {
 			return func() tea.Msg {
 				return statusMsg{fmt.Sprintf("Unknown command: %s", cmdName), 3 * time.Second}
@@ -306,24 +491,72 @@ func (m *Model) handleModelsCommand(args []string) tea.Cmd {
 	}
 }
 
-// handleClearCommand clears chat history
-func (m *Model) handleClearCommand(args []string) tea.Cmd {
-	m.chatState.ClearMessages()
+// handleFocusCommand toggles focus mode
+func (m *Model) handleFocusCommand(args []string) tea.Cmd {
+	return m.toggleFocusMode()
+}
 
-	// Clear log if storage is available
-	if m.storage != nil && m.storage.ChatLogger != nil {
-		go func() {
-			if err := m.storage.ChatLogger.ClearLog(); err != nil {
-				m.logger.Error("Failed to clear chat log", "error", err)
+// handleThemeCommand switches to the named theme, or to the next one
+func (m *Model) handleThemeCommand(args []string) tea.Cmd {
+	names := themeNames()
+	name := ""
+	if len(args) > 0 {
+		name = strings.ToLower(args[0])
+	} else {
+		current := ""
+		if theme := styles.GetCurrentTheme(); theme != nil {
+			current = theme.Name
+		}
+		name = names[0]
+		for i, n := range names {
+			if n == current {
+				name = names[(i+1)%len(names)]
+				break
 			}
-		}()
+		}
+	}
+
+	if err := styles.SetTheme(name); err != nil {
+		return func() tea.Msg {
+			return statusMsg{fmt.Sprintf("Unknown theme %q (available: %s)", name, strings.Join(names, ", ")), 3 * time.Second}
+		}
+	}
+	applyTheme()
+
+	if m.config != nil {
+		m.config.Theme = name
+		if m.storage != nil && m.storage.ConfigManager != nil {
+			config := m.config
+			go func() {
+				if err := m.storage.ConfigManager.SaveConfig(config); err != nil {
+					m.logger.Error("Failed to save theme", "error", err)
+				}
+			}()
+		}
 	}
 
+	display := styles.GetCurrentTheme().DisplayName
 	return func() tea.Msg {
-		return statusMsg{"Chat history cleared", 2 * time.Second}
+		return statusMsg{"Theme: " + display, 2 * time.Second}
 	}
 }
 
+// handleContrastCommand toggles high contrast colors
+func (m *Model) handleContrastCommand(args []string) tea.Cmd {
+	return m.toggleHighContrast()
+}
+
+// themeNames lists the registered themes in a stable order
+func themeNames() []string {
+	themes := styles.DefaultThemeManager.GetAvailableThemes()
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // handleHistoryCommand shows chat history
 func (m *Model) handleHistoryCommand(args []string) tea.Cmd {
 	m.TransitionTo(StateHistory)
@@ -332,18 +565,296 @@ func (m *Model) handleHistoryCommand(args []string) tea.Cmd {
 	}
 }
 
-// handleExportCommand exports chat session
+// handleExportCommand exports the current session. PDF exports fall back to
+// HTML when no renderer can handle the conversation.
 func (m *Model) handleExportCommand(args []string) tea.Cmd {
-	format := "json"
+	if m.storage == nil || m.storage.ChatLogger == nil {
+		return func() tea.Msg {
+			return statusMsg{"Chat history is not available", 3 * time.Second}
+		}
+	}
 
+	format := "json"
+	path := ""
 	if len(args) > 0 {
-		format = args[0]
+		format = strings.ToLower(args[0])
+	}
+	if len(args) > 1 {
+		path = expandHome(strings.Join(args[1:], " "))
+	}
+
+	chatLogger := m.storage.ChatLogger
+	return func() tea.Msg {
+		current := chatLogger.GetCurrentSession()
+		if current == nil || len(current.Messages) == 0 {
+			return statusMsg{"No messages to export", 2 * time.Second}
+		}
+
+		exported, err := chatLogger.ExportSessionTo(current.SessionID, format, path)
+		if errors.Is(err, storage.ErrPDFUnavailable) {
+			return statusMsg{fmt.Sprintf("PDF renderer unavailable, exported HTML to %s", exported), 5 * time.Second}
+		}
+		if err != nil {
+			return statusMsg{fmt.Sprintf("Export failed: %v", err), 5 * time.Second}
+		}
+		return statusMsg{fmt.Sprintf("Exported to %s", exported), 3 * time.Second}
+	}
+}
+
+// handleExportAllCommand writes every logged session to a directory, one file each
+func (m *Model) handleExportAllCommand(args []string) tea.Cmd {
+	if m.storage == nil || m.storage.ChatLogger == nil {
+		return func() tea.Msg {
+			return statusMsg{"Chat history is not available", 3 * time.Second}
+		}
+	}
+
+	format := "markdown"
+	dir := ""
+	zip := false
+	var positional []string
+	for _, arg := range args {
+		if arg == "--zip" || arg == "zip" {
+			zip = true
+			continue
+		}
+		positional = append(positional, arg)
+	}
+	if len(positional) > 0 {
+		format = positional[0]
+	}
+	if len(positional) > 1 {
+		dir = expandHome(strings.Join(positional[1:], " "))
+	}
+
+	if dir == "" {
+		configDir, err := storage.GetConfigDir()
+		if err != nil {
+			return func() tea.Msg {
+				return statusMsg{fmt.Sprintf("Export failed: %v", err), 5 * time.Second}
+			}
+		}
+		dir = filepath.Join(configDir, "exports", "klip-export-"+time.Now().Format("2006-01-02-15-04-05"))
+	}
+
+	m.exportProgress = NewProgressTracker("export-all")
+	chatLogger := m.storage.ChatLogger
+	updates := make(chan tea.Msg, 16)
+
+	go func() {
+		defer close(updates)
+
+		logs, err := chatLogger.ListSessions(0)
+		if err != nil {
+			updates <- exportAllDoneMsg{dir: dir, err: err}
+			return
+		}
+
+		sessions := make([]storage.ChatSession, 0, len(logs))
+		for _, log := range logs {
+			sessions = append(sessions, log.ToSession())
+		}
+
+		err = storage.ExportAllWithOptions(sessions, format, dir, storage.ExportAllOptions{
+			Zip: zip,
+			Progress: func(done, total int, title string) {
+				updates <- exportProgressMsg{done: done, total: total, title: title, updates: updates}
+			},
+		})
+		updates <- exportAllDoneMsg{dir: dir, count: len(sessions), zip: zip, err: err}
+	}()
+
+	return waitForExport(updates)
+}
+
+// waitForExport delivers the next update from a running bulk export
+func waitForExport(updates <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-updates
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// trackExportProgress reflects bulk export progress in the status bar
+func (m *Model) trackExportProgress(msg exportProgressMsg) {
+	if m.exportProgress == nil || msg.total == 0 {
+		return
+	}
+	m.exportProgress.SetProgress(float64(msg.done)/float64(msg.total),
+		fmt.Sprintf("%d/%d", msg.done, msg.total))
+	m.exportProgress.SetSubStatus(msg.title)
+}
+
+// finishExportAll reports the outcome of a bulk export
+func (m *Model) finishExportAll(msg exportAllDoneMsg) {
+	if m.exportProgress != nil {
+		if msg.err != nil {
+			m.exportProgress.SetError(msg.err)
+		} else {
+			m.exportProgress.Complete()
+		}
+	}
+
+	if msg.err != nil {
+		m.setStatusMessage(fmt.Sprintf("Export failed: %v", msg.err), 5*time.Second)
+		return
+	}
+
+	target := msg.dir
+	if msg.zip {
+		target += ".zip"
+	}
+	m.setStatusMessage(fmt.Sprintf("Exported %d sessions to %s", msg.count, target), 5*time.Second)
+}
+
+// expandHome resolves a leading ~/ to the user's home directory
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
+
+// handleImportCommand imports a conversations.json exported from ChatGPT or Claude
+func (m *Model) handleImportCommand(args []string) tea.Cmd {
+	if len(args) == 0 {
+		return func() tea.Msg {
+			return statusMsg{"Usage: /import <file>", 3 * time.Second}
+		}
+	}
+
+	if m.storage == nil || m.storage.ChatLogger == nil {
+		return func() tea.Msg {
+			return statusMsg{"Chat history is not available", 3 * time.Second}
+		}
 	}
 
-	// TODO: Implement export functionality
+	path := expandHome(strings.Join(args, " "))
+
+	chatLogger := m.storage.ChatLogger
 	return func() tea.Msg {
-		return statusMsg{fmt.Sprintf("Export not implemented (format: %s)", format), 3 * time.Second}
+		result, err := chatLogger.ImportConversations(path)
+		if err != nil {
+			return statusMsg{fmt.Sprintf("Import failed: %v", err), 5 * time.Second}
+		}
+
+		noun := "sessions"
+		if result.Imported == 1 {
+			noun = "session"
+		}
+		message := fmt.Sprintf("Imported %d %s from %s export", result.Imported, noun, result.Format)
+		if result.Skipped > 0 {
+			message += fmt.Sprintf(" (%d already imported)", result.Skipped)
+		}
+		return statusMsg{message, 5 * time.Second}
+	}
+}
+
+// shareResult records where a shared conversation ended up
+type shareResult struct {
+	Location string // gist URL or local file path
+	Gist     bool
+	GistErr  error // why the gist upload fell back to a file, if it did
+}
+
+// handleShareCommand exports the conversation to Markdown with secrets
+// redacted, then uploads it as a secret gist or saves it locally
+func (m *Model) handleShareCommand(args []string) tea.Cmd {
+	if len(m.chatState.Messages) == 0 {
+		return func() tea.Msg {
+			return statusMsg{"Nothing to share yet", 2 * time.Second}
+		}
+	}
+
+	title := strings.Join(args, " ")
+	if title == "" {
+		title = "Klip conversation"
+	}
+
+	messages := make([]storage.Message, 0, len(m.chatState.Messages))
+	for _, msg := range m.chatState.Messages {
+		messages = append(messages, storage.Message{Role: msg.Role, Content: msg.Content, Timestamp: msg.Timestamp})
+	}
+	transcript := "# " + title + "\n\n" + storage.FormatMarkdownTranscript(messages, storage.TranscriptOptions{Timestamps: true})
+	transcript, redacted := storage.RedactSecrets(transcript, m.knownSecrets()...)
+
+	var token string
+	var client *http.Client
+	if m.config != nil {
+		token = strings.TrimSpace(m.config.GitHubToken)
+		client = api.NewHTTPClient(30*time.Second, api.ProxySettingsFromConfig(m.config))
+	}
+
+	return func() tea.Msg {
+		dir, err := storage.GetConfigDir()
+		if err != nil {
+			return statusMsg{fmt.Sprintf("Share failed: %v", err), 5 * time.Second}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		result, err := shareTranscript(ctx, client, token, filepath.Join(dir, "shared"), title, transcript)
+		if err != nil {
+			return statusMsg{fmt.Sprintf("Share failed: %v", err), 5 * time.Second}
+		}
+
+		var message string
+		switch {
+		case result.Gist && clipboard.WriteAll(result.Location) == nil:
+			message = fmt.Sprintf("Shared as secret gist %s (copied)", result.Location)
+		case result.Gist:
+			message = fmt.Sprintf("Shared as secret gist %s", result.Location)
+		case result.GistErr != nil:
+			message = fmt.Sprintf("Gist upload failed (%v), saved to %s", result.GistErr, result.Location)
+		default:
+			message = fmt.Sprintf("Saved to %s (set a GitHub token in settings to share as a gist)", result.Location)
+		}
+		if redacted > 0 {
+			message += fmt.Sprintf(", %d secret(s) redacted", redacted)
+		}
+		return statusMsg{message, 8 * time.Second}
+	}
+}
+
+// shareTranscript uploads transcript as a secret gist when a GitHub token is
+// set, and otherwise, or when the upload fails, writes it to a file in dir
+func shareTranscript(ctx context.Context, client *http.Client, token, dir, title, transcript string) (shareResult, error) {
+	var result shareResult
+	if token != "" && client != nil {
+		url, err := api.CreateGist(ctx, client, token, "klip-conversation.md", title, transcript)
+		if err == nil {
+			return shareResult{Location: url, Gist: true}, nil
+		}
+		result.GistErr = err
+	}
+
+	path, err := storage.WriteShareFile(dir, transcript)
+	if err != nil {
+		return result, err
 	}
+	result.Location = path
+	return result, nil
+}
+
+// knownSecrets returns the configured API keys and tokens, which are redacted
+// verbatim in addition to anything matching a key pattern
+func (m *Model) knownSecrets() []string {
+	var secrets []string
+	if m.config != nil {
+		secrets = append(secrets, m.config.AnthropicAPIKey, m.config.OpenAIAPIKey, m.config.OpenRouterAPIKey, m.config.GitHubToken)
+	}
+	if m.storage != nil && m.storage.KeyStore != nil {
+		if keys, err := m.storage.KeyStore.GetKeys(); err == nil {
+			secrets = append(secrets, keys.Anthropic, keys.OpenAI, keys.OpenRouter)
+		}
+	}
+	return secrets
 }
 
 // handleSettingsCommand opens settings
@@ -359,6 +870,11 @@ func (m *Model) handleSettingsCommand(args []string) tea.Cmd {
 		}
 	}
 
+	// A single argument opens the named section
+	m.settingsState.Section = ""
+	if len(args) == 1 {
+		m.settingsState.Section = strings.ToLower(args[0])
+	}
 	m.TransitionTo(StateSettings)
 	return nil
 }
@@ -407,11 +923,46 @@ func (m *Model) handleKeysCommand(args []string) tea.Cmd {
 	}
 }
 
-// handleStatsCommand shows usage statistics
-func (m *Model) handleStatsCommand(args []string) tea.Cmd {
-	// TODO: Implement statistics display
+// SessionInfoMsg carries the statistics shown by the session info panel
+type SessionInfoMsg struct {
+	Session storage.ChatSession
+	Stats   storage.SessionStats
+}
+
+// handleSessionInfoCommand shows statistics for the active session, or for
+// the saved session with the given ID
+func (m *Model) handleSessionInfoCommand(args []string) tea.Cmd {
+	if m.storage == nil || m.storage.ChatLogger == nil {
+		return func() tea.Msg {
+			return statusMsg{"Chat history is not available", 3 * time.Second}
+		}
+	}
+
+	chatLogger := m.storage.ChatLogger
+	analytics := m.storage.AnalyticsLogger
 	return func() tea.Msg {
-		return statusMsg{"Statistics not implemented", 2 * time.Second}
+		var chatLog *storage.ChatLog
+		if len(args) > 0 {
+			var err error
+			if chatLog, err = chatLogger.GetSession(args[0]); err != nil {
+				return statusMsg{fmt.Sprintf("Session info failed: %v", err), 3 * time.Second}
+			}
+		} else if chatLog = chatLogger.GetCurrentSession(); chatLog == nil {
+			return statusMsg{"No active session", 2 * time.Second}
+		}
+
+		session := chatLog.ToSession()
+		end := session.UpdatedAt
+		if len(args) == 0 || end.IsZero() {
+			end = time.Now()
+		}
+
+		var events []storage.AnalyticsEvent
+		if analytics != nil {
+			events, _ = analytics.SessionEvents(session.CreatedAt, end)
+		}
+
+		return SessionInfoMsg{Session: session, Stats: storage.ComputeSessionStats(session, events)}
 	}
 }
 
@@ -433,41 +984,84 @@ func (m *Model) handleEditCommand(args []string) tea.Cmd {
 	}
 }
 
-// handleRetryCommand retries the last request
+// handleRetryCommand resumes an interrupted response or sends the request
+// that failed again. "/retry edit" loads the failed message for editing;
+// any other arguments replace it.
 func (m *Model) handleRetryCommand(args []string) tea.Cmd {
-	lastUserMsg := m.chatState.GetLastUserMessage()
-	if lastUserMsg == nil {
+	if m.chatState.Recovery != nil {
+		return m.resumeInterruptedStream()
+	}
+
+	failed := m.chatState.FailedRequest
+	if failed == nil {
 		return func() tea.Msg {
-			return statusMsg{"No message to retry", 2 * time.Second}
+			return statusMsg{"Nothing failed to retry · /regenerate asks for a new reply", 3 * time.Second}
 		}
 	}
 
-	// Remove the last assistant response if it exists
-	if len(m.chatState.Messages) > 0 {
-		lastMsg := m.chatState.Messages[len(m.chatState.Messages)-1]
-		if lastMsg.Role == "assistant" {
-			m.chatState.Messages = m.chatState.Messages[:len(m.chatState.Messages)-1]
+	if len(args) == 1 && args[0] == "edit" {
+		if msg := lastUserMessage(failed.Messages); msg != nil {
+			m.setCurrentInput("/retry " + msg.Content)
+		}
+		return func() tea.Msg {
+			return statusMsg{"Edit the message and press Enter to retry", 3 * time.Second}
 		}
 	}
 
-	// Resend the last user message
-	return m.sendChatMessage(lastUserMsg.Content)
+	request := *failed
+	if len(args) > 0 {
+		// The raw text keeps the line breaks of a message edited with "/retry edit"
+		edited := m.commandArgs
+		request.Messages = append([]api.Message(nil), failed.Messages...)
+		if msg := lastUserMessage(request.Messages); msg != nil {
+			msg.Content = edited
+		}
+		if msg := lastUserMessage(m.chatState.Messages); msg != nil {
+			msg.Content = edited
+		}
+	}
+
+	m.chatState.FailedRequest = nil
+	m.chatState.RateLimitRetries = 0
+	m.chatState.WaitingForAPI = true
+	return tea.Batch(
+		func() tea.Msg { return statusMsg{"Retrying...", 2 * time.Second} },
+		func() tea.Msg { return apiRequestMsg{&request} },
+	)
 }
 
-// handleSearchCommand searches chat history
-func (m *Model) handleSearchCommand(args []string) tea.Cmd {
-	if len(args) == 0 {
+// handleRegenerateCommand drops the last reply and asks the model again
+func (m *Model) handleRegenerateCommand(args []string) tea.Cmd {
+	if m.chatState.WaitingForAPI {
+		return nil
+	}
+
+	last := len(m.chatState.Messages) - 1
+	if last < 0 || m.chatState.Messages[last].Role != "assistant" {
+		return func() tea.Msg {
+			return statusMsg{"No reply to regenerate", 2 * time.Second}
+		}
+	}
+	if last == 0 || m.chatState.Messages[last-1].Role != "user" || m.chatState.Messages[last-1].Content == "" {
 		return func() tea.Msg {
-			return statusMsg{"Usage: /search <query>", 2 * time.Second}
+			return statusMsg{"No message to regenerate from", 2 * time.Second}
 		}
 	}
 
-	query := strings.Join(args, " ")
+	// sendMessage adds the user turn back
+	content := m.chatState.Messages[last-1].Content
+	m.chatState.Messages = m.chatState.Messages[:last-1]
+	return m.sendMessage(content, false)
+}
 
-	// TODO: Implement search functionality
-	return func() tea.Msg {
-		return statusMsg{fmt.Sprintf("Search not implemented (query: %s)", query), 3 * time.Second}
+// lastUserMessage returns the last user message in messages, or nil
+func lastUserMessage(messages []api.Message) *api.Message {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			return &messages[i]
+		}
 	}
+	return nil
 }
 
 // handleQuitCommand exits the application
@@ -489,6 +1083,30 @@ func (m *Model) handleDebugCommand(args []string) tea.Cmd {
 	}
 }
 
+// NotificationLogMsg opens or clears the event log of past notifications
+type NotificationLogMsg struct {
+	Clear bool
+}
+
+// handleNotificationsCommand opens or clears the notification log
+func (m *Model) handleNotificationsCommand(args []string) tea.Cmd {
+	if len(args) > 0 {
+		if strings.ToLower(args[0]) != "clear" {
+			return func() tea.Msg {
+				return statusMsg{"Usage: /notifications [clear]", 2 * time.Second}
+			}
+		}
+		return tea.Batch(
+			func() tea.Msg { return NotificationLogMsg{Clear: true} },
+			func() tea.Msg { return statusMsg{"Notification log cleared", 2 * time.Second} },
+		)
+	}
+
+	return func() tea.Msg {
+		return NotificationLogMsg{}
+	}
+}
+
 // handleWebSearchCommand toggles web search
 func (m *Model) handleWebSearchCommand(args []string) tea.Cmd {
 	if len(args) > 0 {
@@ -516,6 +1134,118 @@ func (m *Model) handleWebSearchCommand(args []string) tea.Cmd {
 	}
 }
 
+// handleSystemCommand sets or clears the session system prompt
+func (m *Model) handleSystemCommand(args []string) tea.Cmd {
+	if len(args) == 0 {
+		prompt := m.systemPrompt()
+		if prompt == "" {
+			prompt = "none"
+		}
+		return func() tea.Msg {
+			return statusMsg{fmt.Sprintf("System prompt: %s", prompt), 5 * time.Second}
+		}
+	}
+
+	if len(args) == 1 && strings.ToLower(args[0]) == "clear" {
+		m.chatState.SystemPrompt = ""
+		return func() tea.Msg {
+			return statusMsg{"Session system prompt cleared", 2 * time.Second}
+		}
+	}
+
+	m.chatState.SystemPrompt = strings.Join(args, " ")
+	return func() tea.Msg {
+		return statusMsg{"System prompt set for this session", 2 * time.Second}
+	}
+}
+
+// handleParamsCommand shows or updates the current model's generation parameters
+func (m *Model) handleParamsCommand(args []string) tea.Cmd {
+	usage := func() tea.Msg {
+		return statusMsg{"Usage: /params [temperature <value>|max_tokens <value>|reset]", 3 * time.Second}
+	}
+
+	if len(args) == 0 {
+		params := m.modelParameters()
+		return func() tea.Msg {
+			return statusMsg{fmt.Sprintf("%s: %s", m.currentModel.Name, formatModelParameters(params)), 5 * time.Second}
+		}
+	}
+
+	params := m.config.ParametersFor(m.currentModel.ID)
+
+	switch strings.ToLower(args[0]) {
+	case "reset":
+		params = storage.ModelParameters{}
+	case "temperature", "temp":
+		if len(args) < 2 {
+			return usage
+		}
+		value, err := strconv.ParseFloat(args[1], 64)
+		if err != nil {
+			return usage
+		}
+		params.Temperature = &value
+	case "max_tokens", "max-tokens", "tokens":
+		if len(args) < 2 {
+			return usage
+		}
+		value, err := strconv.Atoi(args[1])
+		if err != nil {
+			return usage
+		}
+		params.MaxTokens = &value
+	default:
+		return usage
+	}
+
+	if err := api.ValidateParameters(m.currentModel, params.Temperature, params.MaxTokens); err != nil {
+		return func() tea.Msg {
+			return statusMsg{fmt.Sprintf("Invalid parameters: %v", err), 3 * time.Second}
+		}
+	}
+
+	if m.config == nil {
+		m.config = &storage.Config{}
+	}
+	if m.config.ModelParameters == nil {
+		m.config.ModelParameters = make(map[string]storage.ModelParameters)
+	}
+	if params == (storage.ModelParameters{}) {
+		delete(m.config.ModelParameters, m.currentModel.ID)
+	} else {
+		m.config.ModelParameters[m.currentModel.ID] = params
+	}
+
+	if m.storage != nil && m.storage.ConfigManager != nil {
+		config := m.config
+		go func() {
+			if err := m.storage.ConfigManager.SaveConfig(config); err != nil {
+				m.logger.Error("Failed to save model parameters", "error", err)
+			}
+		}()
+	}
+
+	return func() tea.Msg {
+		return statusMsg{fmt.Sprintf("%s: %s", m.currentModel.Name, formatModelParameters(params)), 3 * time.Second}
+	}
+}
+
+// formatModelParameters renders parameters for display, naming provider defaults
+func formatModelParameters(params storage.ModelParameters) string {
+	temperature := "default"
+	if params.Temperature != nil {
+		temperature = strconv.FormatFloat(*params.Temperature, 'f', -1, 64)
+	}
+
+	maxTokens := "default"
+	if params.MaxTokens != nil {
+		maxTokens = strconv.Itoa(*params.MaxTokens)
+	}
+
+	return fmt.Sprintf("temperature %s, max tokens %s", temperature, maxTokens)
+}
+
 // Additional utility functions for command handling
 
 // parseModelID parses a model ID and returns the appropriate model
@@ -1,12 +1,26 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
 	"github.com/john/klip/internal/api"
+	"github.com/john/klip/internal/storage"
+	"github.com/john/klip/internal/ui/styles"
+	"github.com/muesli/termenv"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCommandRegistry(t *testing.T) {
@@ -264,10 +278,56 @@ func TestClearCommand(t *testing.T) {
 	model.chatState.AddMessage(api.Message{Role: "assistant", Content: "Hi"})
 	assert.Equal(t, 2, len(model.chatState.Messages))
 
-	// Execute clear command
+	// Execute clear command, which asks first
 	cmd := model.handleClearCommand([]string{})
 	assert.NotNil(t, cmd) // Should return status message command
+	assert.Equal(t, 2, len(model.chatState.Messages))
+	assert.True(t, model.chatState.ClearPending)
+
+	// Declining keeps the conversation
+	model.handleChatKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	assert.False(t, model.chatState.ClearPending)
+	assert.Equal(t, 2, len(model.chatState.Messages))
+
+	model.handleClearCommand([]string{})
+	model.handleChatKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
 	assert.Equal(t, 0, len(model.chatState.Messages))
+
+	// /clear! doesn't ask
+	model.chatState.AddMessage(api.Message{Role: "user", Content: "Again"})
+	model.ExecuteCommand("/clear!")
+	assert.Empty(t, model.chatState.Messages)
+	assert.False(t, model.chatState.ClearPending)
+}
+
+func TestClearThenUndoRestoresMessages(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	model := New()
+	store, err := storage.New()
+	require.NoError(t, err)
+	require.NoError(t, store.Initialize())
+	model.storage = store
+
+	messages := []api.Message{{Role: "user", Content: "Hello"}, {Role: "assistant", Content: "Hi"}}
+	for _, message := range messages {
+		model.chatState.AddMessage(message)
+		require.NoError(t, store.ChatLogger.LogMessage(storage.Message{Role: message.Role, Content: message.Content}))
+	}
+
+	assert.Contains(t, sentStatus(t, model.ExecuteCommand("/clear!")), "ctrl+z or /undo")
+	assert.Empty(t, model.chatState.Messages)
+	assert.Empty(t, store.ChatLogger.GetCurrentSession().Messages)
+
+	assert.Equal(t, "Restored 2 messages", sentStatus(t, model.handleChatKeys(tea.KeyMsg{Type: tea.KeyCtrlZ})))
+	assert.Equal(t, messages, model.chatState.Messages)
+	assert.Len(t, store.ChatLogger.GetCurrentSession().Messages, 2)
+
+	// Only the last clear can be undone, and only within the window
+	assert.Equal(t, "Nothing to undo", sentStatus(t, model.ExecuteCommand("/undo")))
+	model.ExecuteCommand("/clear!")
+	assert.Equal(t, "Nothing to undo", sentStatus(t, model.undoClear(time.Now().Add(clearUndoWindow+time.Second))))
+	assert.Empty(t, model.chatState.Messages)
 }
 
 func TestModelsCommand(t *testing.T) {
@@ -377,3 +437,391 @@ func BenchmarkModelParsing(b *testing.B) {
 		model.parseModelID(modelID)
 	}
 }
+
+func TestNotificationsCommand(t *testing.T) {
+	model := New()
+
+	cmd := model.handleNotificationsCommand([]string{})
+	assert.NotNil(t, cmd)
+	assert.Equal(t, NotificationLogMsg{}, cmd())
+
+	cmd = model.handleNotificationsCommand([]string{"clear"})
+	assert.NotNil(t, cmd)
+
+	cmd = model.handleNotificationsCommand([]string{"bogus"})
+	msg, ok := cmd().(statusMsg)
+	assert.True(t, ok)
+	assert.Contains(t, msg.message, "Usage")
+}
+
+func TestShareTranscriptFallsBackToFile(t *testing.T) {
+	dir := t.TempDir()
+
+	// Without a token the transcript is written locally
+	result, err := shareTranscript(context.Background(), nil, "", dir, "Test", "# Test\n")
+	require.NoError(t, err)
+	assert.False(t, result.Gist)
+	assert.NoError(t, result.GistErr)
+	assert.Equal(t, dir, filepath.Dir(result.Location))
+
+	data, err := os.ReadFile(result.Location)
+	require.NoError(t, err)
+	assert.Equal(t, "# Test\n", string(data))
+
+	// A failed upload also falls back, keeping the reason
+	offline := &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		return nil, errors.New("network unreachable")
+	})}
+	result, err = shareTranscript(context.Background(), offline, "ghp_token", dir, "Test", "# Again\n")
+	require.NoError(t, err)
+	assert.False(t, result.Gist)
+	assert.Error(t, result.GistErr)
+	assert.FileExists(t, result.Location)
+}
+
+func TestShareCommandNeedsMessages(t *testing.T) {
+	model := New()
+
+	cmd := model.handleShareCommand(nil)
+	require.NotNil(t, cmd)
+	assert.Equal(t, "Nothing to share yet", cmd().(statusMsg).message)
+}
+
+// roundTripFunc fakes an HTTP transport
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestCommandPaletteRanksThemeActions(t *testing.T) {
+	previous := styles.GetCurrentTheme().Name
+	defer styles.SetTheme(previous)
+
+	model := New()
+	model.logger = log.New(os.Stderr)
+	model.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	model.TransitionTo(StateOnboarding)
+	model.TransitionTo(StateChat)
+
+	model.Update(tea.KeyMsg{Type: tea.KeyCtrlK})
+	require.NotNil(t, model.palette)
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("theme")})
+
+	themes := len(themeNames())
+	require.Greater(t, len(model.palette.Matches), themes)
+	assert.Equal(t, "/theme", model.palette.Matches[0].Hint)
+	for _, action := range model.palette.Matches[:themes+1] {
+		assert.Contains(t, strings.ToLower(action.Title), "theme")
+	}
+	assert.Contains(t, model.View(), "Switch color theme")
+
+	// Fuzzy matches still find actions, ranked below exact words
+	assert.Greater(t, paletteScore("theme", model.palette.Matches[0]), paletteScore("thm", model.palette.Matches[0]))
+	assert.Zero(t, paletteScore("theme", PaletteAction{Title: "Open settings", Hint: "/settings"}))
+
+	model.palette.Selected = 1
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Nil(t, model.palette)
+	require.NotNil(t, cmd)
+	assert.Equal(t, "Theme: "+styles.GetCurrentTheme().DisplayName, cmd().(statusMsg).message)
+}
+
+// restoreTheme puts the theme, and the app styles drawn from it, back after
+// a test changes them, rendering in true color meanwhile
+func restoreTheme(t *testing.T) {
+	previous, profile := styles.GetCurrentTheme().Name, lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	require.NoError(t, styles.DefaultThemeManager.SetColorProfile(termenv.TrueColor))
+	t.Cleanup(func() {
+		lipgloss.SetColorProfile(profile)
+		styles.DefaultThemeManager.SetColorProfile(termenv.ColorProfile())
+		styles.SetAccessibility(nil)
+		styles.SetTheme(previous)
+		applyTheme()
+	})
+}
+
+// chatModel returns a model showing the chat, sized to render
+func chatModel() *Model {
+	model := New()
+	model.logger = log.New(os.Stderr)
+	model.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	model.TransitionTo(StateOnboarding)
+	model.TransitionTo(StateChat)
+	return model
+}
+
+func TestThemeCommandRestylesView(t *testing.T) {
+	restoreTheme(t)
+	model := chatModel()
+	model.ExecuteCommand("/theme charm-dark")()
+	dark := model.View()
+	title := lipgloss.NewStyle().Foreground(styles.GetCurrentTheme().Colors.Primary).Bold(true).Render("Klip Chat")
+	assert.Contains(t, dark, title)
+
+	assert.Equal(t, "Theme: Charm Light", model.ExecuteCommand("/theme charm-light")().(statusMsg).message)
+	light := model.View()
+	assert.NotEqual(t, dark, light)
+	title = lipgloss.NewStyle().Foreground(styles.GetCurrentTheme().Colors.Primary).Bold(true).Render("Klip Chat")
+	assert.Contains(t, light, title)
+}
+
+func TestContrastCommandTogglesHighContrast(t *testing.T) {
+	restoreTheme(t)
+	require.NoError(t, styles.SetTheme("charm-dark"))
+
+	model := chatModel()
+	model.config = &storage.Config{}
+	model.accessibility = styles.NewAccessibilityManager(styles.GetCurrentTheme(), nil)
+	model.accessibility.UpdatePreferences(&styles.AccessibilityPreferences{})
+	applyTheme()
+	before := model.View()
+
+	model.handleContrastCommand(nil)
+	assert.True(t, styles.GetCurrentTheme().IsHighContrast)
+	assert.True(t, model.config.Accessibility.HighContrast)
+
+	// The running view picks up the high contrast palette
+	view := model.View()
+	assert.NotEqual(t, before, view)
+	title := lipgloss.NewStyle().Foreground(styles.GetCurrentTheme().Colors.Primary).Bold(true).Render("Klip Chat")
+	assert.Contains(t, view, title)
+
+	model.handleContrastCommand(nil)
+	assert.False(t, styles.GetCurrentTheme().IsHighContrast)
+	assert.False(t, model.config.Accessibility.HighContrast)
+	assert.Equal(t, before, model.View())
+}
+
+func TestTemplateCommandStartsFromTemplate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	require.NoError(t, storage.SaveTemplate(&storage.Template{
+		Name:           "reviewer",
+		SystemPrompt:   "Act as a code reviewer.",
+		Model:          "claude-3-5-haiku-20241022",
+		StarterMessage: "Review this diff:",
+	}))
+
+	model := New()
+	model.apiClient = &compareProvider{}
+	model.currentModel = api.Model{ID: "claude-3-5-sonnet-20241022", Name: "Claude 3.5 Sonnet", Provider: api.ProviderAnthropic}
+	model.chatState.AddMessage(api.Message{Role: "user", Content: "earlier"})
+
+	msg := model.ExecuteCommand("/template reviewer")()
+	assert.Contains(t, msg.(statusMsg).message, "reviewer")
+	assert.Empty(t, model.chatState.Messages)
+	assert.Equal(t, "claude-3-5-haiku-20241022", model.currentModel.ID)
+	assert.Equal(t, "Review this diff:", model.inputBuffer)
+
+	request := model.buildChatRequest()
+	assert.Equal(t, "claude-3-5-haiku-20241022", request.Model.ID)
+	if assert.NotEmpty(t, request.Messages) {
+		assert.Equal(t, "system", request.Messages[0].Role)
+		assert.Equal(t, "Act as a code reviewer.", request.Messages[0].Content)
+	}
+
+	// The current setup can be saved as a template of its own
+	model.ExecuteCommand("/templates save haiku-review")()
+	saved, err := storage.LoadTemplate("haiku-review")
+	require.NoError(t, err)
+	assert.Equal(t, "Act as a code reviewer.", saved.SystemPrompt)
+	assert.Equal(t, "claude-3-5-haiku-20241022", saved.Model)
+
+	assert.Contains(t, model.ExecuteCommand("/templates")().(statusMsg).message, "haiku-review, reviewer")
+}
+
+func TestRunCommandCapturesOutput(t *testing.T) {
+	model := New()
+	model.logger = log.New(os.Stderr)
+	model.chatState.AddMessage(api.Message{Role: "assistant", Content: "Try:\n```sh\necho hello from klip\n```"})
+
+	// Running code is off unless enabled
+	model.config = &storage.Config{}
+	assert.Contains(t, model.ExecuteCommand("/run")().(statusMsg).message, "off")
+	assert.Nil(t, model.chatState.CodeRun)
+
+	model.config.EnableCodeExecution = true
+	model.config.SystemPrompt = "Be terse."
+	assert.Contains(t, model.ExecuteCommand("/run")().(statusMsg).message, "y run")
+	require.NotNil(t, model.chatState.CodeRun)
+
+	// The whole snippet stays on screen until it is answered
+	model.width, model.height = 80, 24
+	assert.Contains(t, model.renderChatView(), "echo hello from klip")
+
+	// Nothing runs until the code is confirmed
+	batch := model.handleChatKeys(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})().(tea.BatchMsg)
+	assert.Nil(t, model.chatState.CodeRun)
+	done, ok := batch[1]().(codeRunDoneMsg)
+	require.True(t, ok)
+	require.NoError(t, done.err)
+
+	model.Update(done)
+	last := model.chatState.Messages[len(model.chatState.Messages)-1]
+	assert.Equal(t, "system", last.Role)
+	assert.Contains(t, last.Content, "hello from klip")
+
+	// The output goes to the model as context without replacing the
+	// configured system prompt
+	var system []string
+	for _, message := range model.requestMessages() {
+		if message.Role == "system" {
+			system = append(system, message.Content)
+		}
+	}
+	assert.Equal(t, []string{"Be terse."}, system)
+	sent := model.requestMessages()
+	assert.Equal(t, "user", sent[len(sent)-1].Role)
+	assert.Contains(t, sent[len(sent)-1].Content, "hello from klip")
+	assert.Equal(t, "system", model.chatState.Messages[len(model.chatState.Messages)-1].Role)
+}
+
+func TestRunCodeKillsChildrenOnTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("process groups are Unix only")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := runCode(ctx, CodeRun{Language: "sh", Code: "sleep 30 & sleep 30; wait"})
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
+
+func TestStatsCopyCopiesSessionSummary(t *testing.T) {
+	var copied string
+	previous := writeClipboard
+	writeClipboard = func(text string) error {
+		copied = text
+		return nil
+	}
+	defer func() { writeClipboard = previous }()
+
+	model := New()
+	model.currentModel = api.Model{ID: "claude-3-5-sonnet-20241022", Name: "Claude 3.5 Sonnet", Provider: api.ProviderAnthropic}
+
+	// Nothing to summarize before the first reply
+	assert.Contains(t, model.ExecuteCommand("/stats copy")().(statusMsg).message, "No replies")
+	assert.Empty(t, copied)
+
+	start := time.Now().Add(-90 * time.Second)
+	model.chatState.AddMessage(api.Message{Role: "user", Content: "hi", Timestamp: start})
+	model.chatState.AddMessage(api.Message{
+		Role:      "assistant",
+		Content:   "hello",
+		Timestamp: start.Add(time.Second),
+		Model:     "claude-3-5-sonnet-20241022",
+		Usage:     &api.Usage{InputTokens: 1000, OutputTokens: 500},
+	})
+	model.chatState.AddMessage(api.Message{
+		Role:    "assistant",
+		Content: "again",
+		Model:   "claude-3-5-sonnet-20241022",
+		Usage:   &api.Usage{InputTokens: 200, CacheReadInputTokens: 1000, OutputTokens: 100},
+	})
+
+	msg := model.ExecuteCommand("/stats copy")().(statusMsg)
+	assert.Equal(t, "Session summary copied", msg.message)
+
+	// 1,200 uncached and 1,000 cached input tokens at $3/M, 600 output at $15/M
+	assert.Contains(t, copied, "| Model | Claude 3.5 Sonnet |")
+	assert.Contains(t, copied, "| Requests | 2 |")
+	assert.Contains(t, copied, "| Tokens | 2,800 (2,200 in · 600 out) |")
+	assert.Contains(t, copied, "| Cost | $0.0129 |")
+	assert.Contains(t, copied, "| Duration | 1m3")
+}
+
+func TestEstimateCommandPricesPromptTokens(t *testing.T) {
+	model := New()
+	model.currentModel = api.Model{ID: "claude-3-5-sonnet-20241022", Name: "Claude 3.5 Sonnet", Provider: api.ProviderAnthropic}
+
+	// 400 characters estimate to 100 tokens, at $3 per 1M input tokens
+	prompt := strings.Repeat("abcd", 100)
+	estimate := model.estimateSend(prompt)
+	assert.Equal(t, 100, estimate.Tokens)
+	assert.True(t, estimate.Priced)
+	assert.InDelta(t, 100*3.0/1_000_000, estimate.InputCost, 1e-12)
+	assert.InDelta(t, estimate.InputCost+shortReplyTokens*15.0/1_000_000, estimate.Low, 1e-12)
+	assert.InDelta(t, estimate.InputCost+longReplyTokens*15.0/1_000_000, estimate.High, 1e-12)
+
+	msg := model.ExecuteCommand("/estimate " + prompt)().(statusMsg)
+	assert.Contains(t, msg.message, "~100 tokens · $0.0003 input")
+
+	// Nothing is sent
+	assert.Empty(t, model.chatState.Messages)
+	assert.False(t, model.chatState.WaitingForAPI)
+
+	model.currentModel = api.Model{ID: "unlisted-model", Name: "Unlisted"}
+	assert.Contains(t, model.ExecuteCommand("/estimate hi")().(statusMsg).message, "pricing unknown for Unlisted")
+}
+
+func TestProfileCommandSwitchesConfigAndSessions(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Cleanup(func() { storage.SetProfile("") })
+
+	work := filepath.Join(home, ".klip", "profiles", "work")
+	require.NoError(t, os.MkdirAll(work, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(work, "config.json"), []byte(`{"default_model":"gpt-4o"}`), 0600))
+
+	model := New()
+	personal, config, err := openProfileStorage(storage.DefaultProfile)
+	require.NoError(t, err)
+	model.storage, model.config = personal, config
+	require.NoError(t, personal.ChatLogger.LogMessage(storage.Message{Role: "user", Content: "personal note"}))
+	model.chatState.AddMessage(api.Message{Role: "user", Content: "personal note"})
+
+	assert.Contains(t, model.ExecuteCommand("/profile")().(statusMsg).message, "Profile: default · available: default, work")
+
+	msg := model.ExecuteCommand("/profile work")().(profileSwitchedMsg)
+	require.NoError(t, msg.err)
+	// Opening the profile in the background leaves the active one alone
+	// until Update applies it
+	assert.Equal(t, storage.DefaultProfile, storage.ActiveProfile())
+	model.applyProfileSwitch(msg)
+
+	assert.Equal(t, "work", storage.ActiveProfile())
+	assert.Equal(t, "gpt-4o", model.config.DefaultModel)
+	assert.NotSame(t, personal, model.storage)
+	assert.Empty(t, model.chatState.Messages)
+
+	// The work profile keeps its own sessions
+	sessions, err := model.storage.ChatLogger.ListSessions(10)
+	require.NoError(t, err)
+	for _, session := range sessions {
+		assert.Empty(t, session.Messages)
+	}
+
+	// Switching back finds the personal session again
+	msg = model.ExecuteCommand("/profile default")().(profileSwitchedMsg)
+	require.NoError(t, msg.err)
+	model.applyProfileSwitch(msg)
+	assert.Equal(t, storage.DefaultProfile, storage.ActiveProfile())
+	sessions, err = model.storage.ChatLogger.ListSessions(10)
+	require.NoError(t, err)
+	var contents []string
+	for _, session := range sessions {
+		for _, message := range session.Messages {
+			contents = append(contents, message.Content)
+		}
+	}
+	assert.Contains(t, contents, "personal note")
+
+	// Bad names leave the profile alone
+	msg = model.ExecuteCommand("/profile ../escape")().(profileSwitchedMsg)
+	assert.Error(t, msg.err)
+	assert.Equal(t, storage.DefaultProfile, storage.ActiveProfile())
+
+	// An alternate config file stays in use, and the switch says so
+	override := filepath.Join(home, "other.json")
+	_, err = storage.SetConfigFile(override)
+	require.NoError(t, err)
+	t.Cleanup(func() { storage.SetConfigFile("") })
+	msg = model.ExecuteCommand("/profile work")().(profileSwitchedMsg)
+	require.NoError(t, msg.err)
+	status := model.applyProfileSwitch(msg)().(tea.BatchMsg)[0]().(statusMsg)
+	assert.Contains(t, status.message, "config still from "+override)
+}
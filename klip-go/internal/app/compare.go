@@ -0,0 +1,225 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+	"github.com/john/klip/internal/api"
+	"github.com/john/klip/internal/storage"
+)
+
+// compareTimeout bounds a whole comparison, including the slowest model
+const compareTimeout = 2 * time.Minute
+
+// minCompareColumnWidth is the narrowest column before results stack
+const minCompareColumnWidth = 32
+
+// Comparison holds one prompt answered by several models
+type Comparison struct {
+	Prompt  string
+	Results []CompareResult
+}
+
+// CompareResult is one model's answer in a comparison
+type CompareResult struct {
+	Model    api.Model
+	Response *api.ChatResponse
+	Err      error
+	Latency  time.Duration
+}
+
+// compareDoneMsg delivers a finished comparison
+type compareDoneMsg struct {
+	comparison *Comparison
+}
+
+// handleCompareCommand sends a prompt to several models at once. The prompt
+// is the rest of the arguments, or the last message sent.
+func (m *Model) handleCompareCommand(args []string) tea.Cmd {
+	if len(args) == 0 {
+		return func() tea.Msg {
+			return statusMsg{"Usage: /compare model-a,model-b [prompt]", 3 * time.Second}
+		}
+	}
+
+	var models []api.Model
+	for _, id := range strings.Split(args[0], ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			models = append(models, m.lookupModel(id))
+		}
+	}
+	if len(models) < 2 {
+		return func() tea.Msg {
+			return statusMsg{"Compare needs at least two models, e.g. /compare gpt-4o,claude-3-5-haiku-20241022", 3 * time.Second}
+		}
+	}
+
+	prompt := strings.Join(args[1:], " ")
+	if prompt == "" {
+		if last := m.chatState.GetLastUserMessage(); last != nil {
+			prompt = last.Content
+		}
+	}
+	if strings.TrimSpace(prompt) == "" {
+		return func() tea.Msg {
+			return statusMsg{"Nothing to compare: add a prompt or send a message first", 3 * time.Second}
+		}
+	}
+
+	// The conversation so far gives every model the same context
+	messages := m.requestMessages()
+	if last := len(messages) - 1; last >= 0 && messages[last].Role == "user" && messages[last].Content == prompt {
+		messages = messages[:last]
+	}
+	messages = append(messages, api.Message{Role: "user", Content: prompt, Timestamp: time.Now()})
+
+	params := m.modelParameters()
+	request := api.ChatRequest{
+		Messages:    messages,
+		MaxTokens:   requestMaxTokens(params),
+		Temperature: params.Temperature,
+	}
+
+	limit := 0
+	if m.config != nil {
+		limit = m.config.MaxConcurrentRequests
+	}
+
+	current, currentClient := m.currentModel, m.apiClient
+	clientFor := func(model api.Model) (api.ProviderInterface, error) {
+		if currentClient != nil && model.Provider == current.Provider {
+			return currentClient, nil
+		}
+		return m.newProviderClient(model)
+	}
+
+	m.chatState.Comparison = nil
+	m.chatState.WaitingForAPI = true
+	ctx := m.ctx
+	return tea.Batch(
+		func() tea.Msg {
+			return statusMsg{fmt.Sprintf("Comparing %d models...", len(models)), 3 * time.Second}
+		},
+		func() tea.Msg {
+			ctx, cancel := context.WithTimeout(ctx, compareTimeout)
+			defer cancel()
+
+			results := compareModels(ctx, request, models, limit, clientFor)
+			return compareDoneMsg{&Comparison{Prompt: prompt, Results: results}}
+		},
+	)
+}
+
+// compareModels sends request to each model concurrently, running at most
+// limit requests at a time (unbounded when limit < 1). Results keep the
+// order of models.
+func compareModels(ctx context.Context, request api.ChatRequest, models []api.Model, limit int,
+	clientFor func(api.Model) (api.ProviderInterface, error)) []CompareResult {
+	if limit < 1 {
+		limit = len(models)
+	}
+
+	results := make([]CompareResult, len(models))
+	semaphore := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+
+	for i, model := range models {
+		wg.Add(1)
+		go func(i int, model api.Model) {
+			defer wg.Done()
+			results[i].Model = model
+
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				results[i].Err = ctx.Err()
+				return
+			}
+
+			client, err := clientFor(model)
+			if err != nil {
+				results[i].Err = err
+				return
+			}
+
+			req := request
+			req.Model = model
+			req.Stream = false
+
+			start := time.Now()
+			results[i].Response, results[i].Err = client.Chat(ctx, &req)
+			results[i].Latency = time.Since(start)
+		}(i, model)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// lookupModel finds a model by ID among the loaded and built-in models. IDs
+// that aren't known are assumed to belong to the current provider.
+func (m *Model) lookupModel(id string) api.Model {
+	for _, model := range append(m.modelsState.AvailableModels, m.getStaticModels()...) {
+		if model.ID == id {
+			return model
+		}
+	}
+	return api.Model{ID: id, Name: id, Provider: m.currentModel.Provider}
+}
+
+// renderComparison lays the answers out side by side, or stacked when the
+// columns would be too narrow to read
+func (m *Model) renderComparison(comparison *Comparison) string {
+	count := len(comparison.Results)
+	if count == 0 {
+		return ""
+	}
+
+	header := titleStyle.Render("Compare") + " " + mutedStyle.Render(truncateText(comparison.Prompt, max(m.width-12, 10)))
+
+	columnWidth := (m.width - 2*(count-1)) / count
+	if columnWidth < minCompareColumnWidth {
+		blocks := make([]string, count)
+		for i, result := range comparison.Results {
+			blocks[i] = m.renderCompareResult(result, m.width-4)
+		}
+		return header + "\n" + strings.Join(blocks, "\n\n")
+	}
+
+	columns := make([]string, count)
+	for i, result := range comparison.Results {
+		columns[i] = compareColumnStyle.Width(columnWidth - 2).Render(m.renderCompareResult(result, columnWidth-4))
+	}
+	return header + "\n" + lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+}
+
+// renderCompareResult renders one model's answer with its usage and cost
+func (m *Model) renderCompareResult(result CompareResult, width int) string {
+	title := lipgloss.NewStyle().Foreground(primaryColor).Bold(true).Render(modelDisplayName(result.Model))
+
+	if result.Err != nil {
+		return title + "\n" + errorStyle.Render(m.wrapText(result.Err.Error(), width))
+	}
+
+	meta := []string{fmt.Sprintf("%.1fs", result.Latency.Seconds())}
+	if usage := result.Response.Usage; usage != nil {
+		meta = append(meta, humanize.Comma(int64(usage.InputTokens+usage.OutputTokens))+" tok")
+		if _, known := storage.LookupCostEstimate(result.Model.ID); known {
+			cost := storage.EstimateCost(result.Model.ID, usage.InputTokens, usage.OutputTokens)
+			meta = append(meta, fmt.Sprintf("$%.4f", cost))
+		}
+	}
+
+	return title + " " + mutedStyle.Render(strings.Join(meta, " · ")) + "\n" +
+		m.wrapText(result.Response.Content, width)
+}
+
+// Compare styles, built by buildStyles
+var compareColumnStyle lipgloss.Style
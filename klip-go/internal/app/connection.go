@@ -0,0 +1,67 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/john/klip/internal/api"
+	"github.com/john/klip/internal/storage"
+)
+
+// connectionStateMsg reports a change in the streaming connection to the
+// provider, including reconnects after a dropped stream
+type connectionStateMsg struct {
+	state api.ConnectionState
+}
+
+// newAPIClient wraps a provider in the client that retries and reconnects
+// dropped streams, reporting connection changes to the status bar. Rate
+// limits are left to the cooldown and model fallback.
+func (m *Model) newAPIClient(provider api.ProviderInterface) (api.ProviderInterface, error) {
+	var analytics *storage.AnalyticsLogger
+	if m.storage != nil {
+		analytics = m.storage.AnalyticsLogger
+	}
+	client, err := api.NewClient(provider, analytics)
+	if err != nil {
+		return nil, err
+	}
+
+	retry := api.DefaultRetryConfig()
+	retry.SkipRateLimits = true
+	client.SetRetryConfig(retry)
+
+	// The client calls back from its own goroutine; a full channel means
+	// Update is behind, and the next state will do
+	states := m.connectionStates
+	client.SetConnectionStateHandler(func(state api.ConnectionState) {
+		select {
+		case states <- state:
+		default:
+		}
+	})
+	return client, nil
+}
+
+// waitForConnectionState delivers the next connection change
+func waitForConnectionState(states <-chan api.ConnectionState) tea.Cmd {
+	if states == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return connectionStateMsg{<-states}
+	}
+}
+
+// renderConnectionState shows the streaming connection in the status bar
+// while there is one to show
+func (m *Model) renderConnectionState() string {
+	switch m.connectionState {
+	case api.ConnectionConnecting:
+		return warningStyle.Render("◌ Connecting")
+	case api.ConnectionConnected:
+		return successStyle.Render("● Connected")
+	case api.ConnectionError:
+		return errorStyle.Render("● Connection lost")
+	default:
+		return ""
+	}
+}
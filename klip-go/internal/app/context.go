@@ -0,0 +1,230 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/john/klip/internal/api"
+)
+
+// Share of the context window at which the status bar turns amber, and at
+// which a notification offers to trim the conversation
+const (
+	contextNoticeRatio  = 0.75
+	contextWarningRatio = 0.9
+)
+
+// contextWindows holds the context window of models whose listing doesn't
+// include one, keyed by model ID prefix. Longer prefixes are checked first.
+var contextWindows = []struct {
+	prefix string
+	tokens int
+}{
+	{"gpt-4o", 128000},
+	{"gpt-4-turbo", 128000},
+	{"gpt-4-32k", 32768},
+	{"gpt-4", 8192},
+	{"gpt-3.5-turbo", 16385},
+	{"o1", 200000},
+	{"o3", 200000},
+	{"claude-", 200000},
+	{"anthropic/claude-", 200000},
+	{"openai/gpt-4o", 128000},
+	{"meta-llama/llama-3.1", 131072},
+	{"google/gemini", 1000000},
+}
+
+// ContextWarningMsg reports that the conversation is close to filling the
+// current model's context window
+type ContextWarningMsg struct {
+	Model  string
+	Used   int
+	Window int
+}
+
+// ContextWindow returns the number of tokens model accepts, or zero when
+// it isn't known
+func ContextWindow(model api.Model) int {
+	if model.ContextWindow > 0 {
+		return model.ContextWindow
+	}
+
+	id := strings.ToLower(model.ID)
+	best, tokens := 0, 0
+	for _, entry := range contextWindows {
+		if strings.HasPrefix(id, entry.prefix) && len(entry.prefix) > best {
+			best, tokens = len(entry.prefix), entry.tokens
+		}
+	}
+	return tokens
+}
+
+// contextUsage estimates the prompt tokens the next request sends, system
+// prompt included, and the current model's window
+func (m *Model) contextUsage() (used, window int) {
+	counter := NewTokenCounter()
+	for _, msg := range m.requestMessages() {
+		used += counter.EstimateTokens(msg.Content)
+	}
+	return used, ContextWindow(m.currentModel)
+}
+
+// contextRatio returns how full the context window is, or zero when the
+// window isn't known
+func (m *Model) contextRatio() float64 {
+	used, window := m.contextUsage()
+	if window <= 0 {
+		return 0
+	}
+	return float64(used) / float64(window)
+}
+
+// checkContextWindow warns once when the conversation crosses
+// contextWarningRatio; trimming below it re-arms the warning
+func (m *Model) checkContextWindow() tea.Cmd {
+	used, window := m.contextUsage()
+	if window <= 0 || float64(used) < contextWarningRatio*float64(window) {
+		m.chatState.ContextWarned = false
+		return nil
+	}
+	if m.chatState.ContextWarned {
+		return nil
+	}
+
+	m.chatState.ContextWarned = true
+	warning := ContextWarningMsg{Model: m.currentModel.Name, Used: used, Window: window}
+	return func() tea.Msg {
+		return warning
+	}
+}
+
+// exceedsContextWindow reports whether sending content would overflow the
+// current model's context window, with a message explaining why
+func (m *Model) exceedsContextWindow(content string) (string, bool) {
+	used, window := m.contextUsage()
+	if window <= 0 {
+		return "", false
+	}
+	used += NewTokenCounter().EstimateTokens(content)
+	if used <= window {
+		return "", false
+	}
+	return fmt.Sprintf("Message would exceed %s's context window (~%d of %d tokens) · /summarize, /trim or /clear",
+		m.currentModel.Name, used, window), true
+}
+
+// handleTrimCommand drops the oldest turns, either the given number or as
+// many as it takes to get back under the status bar warning
+func (m *Model) handleTrimCommand(args []string) tea.Cmd {
+	count := -1
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n <= 0 {
+			return func() tea.Msg {
+				return statusMsg{"Usage: /trim [turns]", 2 * time.Second}
+			}
+		}
+		count = n
+	}
+
+	trimmed := 0
+	for len(m.chatState.Messages) > 0 && trimmed != count {
+		if count < 0 && m.contextRatio() < contextNoticeRatio {
+			break
+		}
+		m.dropOldestTurn()
+		trimmed++
+	}
+	m.checkContextWindow()
+
+	status := fmt.Sprintf("Removed %d oldest turns", trimmed)
+	if trimmed == 1 {
+		status = "Removed the oldest turn"
+	}
+	return func() tea.Msg {
+		return statusMsg{status, 3 * time.Second}
+	}
+}
+
+// dropOldestTurn removes the first message and the replies that follow it,
+// up to the next user message
+func (m *Model) dropOldestTurn() {
+	messages := m.chatState.Messages
+	end := 1
+	for end < len(messages) && messages[end].Role != "user" {
+		end++
+	}
+	m.chatState.Messages = messages[end:]
+}
+
+// applyTokenBudget drops the oldest turns once the conversation passes
+// Config.HistoryTokenBudget, reporting what was dropped
+func (m *Model) applyTokenBudget() tea.Cmd {
+	if m.config == nil || m.config.HistoryTokenBudget <= 0 {
+		return nil
+	}
+
+	kept, turns, tokens := trimToTokenBudget(m.chatState.Messages, m.config.HistoryTokenBudget)
+	if turns == 0 {
+		return nil
+	}
+	m.chatState.Messages = kept
+
+	noun := "turns"
+	if turns == 1 {
+		noun = "turn"
+	}
+	status := fmt.Sprintf("Dropped %d oldest %s (~%d tokens) to stay within the %d token history budget",
+		turns, noun, tokens, m.config.HistoryTokenBudget)
+	return func() tea.Msg {
+		return statusMsg{status, 4 * time.Second}
+	}
+}
+
+// trimToTokenBudget drops the oldest turns until the messages fit in budget
+// tokens. A turn is a user message with the replies that follow it, so a
+// question never loses its answer; the latest turn is always kept. It
+// returns the kept messages, the number of turns dropped and their tokens.
+func trimToTokenBudget(messages []api.Message, budget int) ([]api.Message, int, int) {
+	counter := NewTokenCounter()
+	total := 0
+	var starts []int
+	for i, msg := range messages {
+		if i == 0 || msg.Role == "user" {
+			starts = append(starts, i)
+		}
+		total += counter.EstimateTokens(msg.Content)
+	}
+
+	turns, dropped, cut := 0, 0, 0
+	for total > budget && turns < len(starts)-1 {
+		next := starts[turns+1]
+		for _, msg := range messages[cut:next] {
+			tokens := counter.EstimateTokens(msg.Content)
+			total -= tokens
+			dropped += tokens
+		}
+		cut = next
+		turns++
+	}
+	return messages[cut:], turns, dropped
+}
+
+// renderContextUsage shows how full the context window is once it passes
+// contextNoticeRatio, in amber and then red
+func (m *Model) renderContextUsage() string {
+	ratio := m.contextRatio()
+	if ratio < contextNoticeRatio {
+		return ""
+	}
+
+	color := warningColor
+	if ratio >= contextWarningRatio {
+		color = errorColor
+	}
+	return lipgloss.NewStyle().Foreground(color).Render(fmt.Sprintf("Context %d%%", int(ratio*100)))
+}
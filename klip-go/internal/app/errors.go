@@ -13,6 +13,7 @@ import (
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/john/klip/internal/api"
 )
 
 // ErrorType categorizes different types of errors
@@ -182,8 +183,26 @@ func (eh *ErrorHandler) classifyError(err error) *AppError {
 			MakeRecoverable()
 	}
 
-	// HTTP errors - check for wrapped HTTP response errors
-	// This would need to be implemented based on actual HTTP client error types
+	// Provider errors carry their status code
+	var apiErr *api.APIError
+	if errors.As(err, &apiErr) {
+		classified := api.ClassifyError(err)
+		switch classified.Kind {
+		case api.ErrorAuth:
+			return NewAppError(ErrorTypeAuth, "Authentication failed", err).
+				WithUserMessage(classified.Message).
+				MakeRecoverable()
+		case api.ErrorRateLimit:
+			return NewAppError(ErrorTypeRateLimit, "Rate limit exceeded", err).
+				WithUserMessage(classified.Message).
+				WithRetryAfter(time.Minute).
+				MakeRecoverable()
+		default:
+			return NewAppError(ErrorTypeAPI, "API error", err).
+				WithUserMessage(classified.Message).
+				MakeRecoverable()
+		}
+	}
 
 	// Context errors
 	if errors.Is(err, context.Canceled) {
@@ -656,3 +675,20 @@ func (rm *RecoveryManager) defaultRecovery(appErr *AppError) tea.Cmd {
 		return statusMsg{"Ready to retry", 2 * time.Second}
 	})
 }
+
+// ProviderAuthErrorMsg reports that a provider rejected its API key, so the
+// UI can offer to open the Providers settings
+type ProviderAuthErrorMsg struct {
+	Provider api.Provider
+	Message  string
+}
+
+// reportAuthError shows a rejected key as an actionable error rather than
+// a generic request failure
+func (m *Model) reportAuthError(classified api.ClassifiedError) tea.Cmd {
+	m.setError(classified.Err, classified.Message, true)
+	msg := ProviderAuthErrorMsg{Provider: classified.Provider, Message: classified.Message}
+	return func() tea.Msg {
+		return msg
+	}
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dustin/go-humanize"
+)
+
+// The reply lengths a cost preview brackets, since the reply isn't known
+// until it arrives
+const (
+	shortReplyTokens = 250
+	longReplyTokens  = 1000
+)
+
+// PromptEstimate is what sending a prompt is expected to cost
+type PromptEstimate struct {
+	Tokens int
+
+	// InputCost prices the prompt alone; Low and High add a short and a
+	// long reply. All are zero when the model's pricing isn't known.
+	InputCost float64
+	Low       float64
+	High      float64
+	Priced    bool
+}
+
+// estimatePrompt prices tokens of prompt for a model
+func estimatePrompt(info ModelInfo, tokens int) PromptEstimate {
+	estimate := PromptEstimate{Tokens: tokens, Priced: info.HasPricing}
+	if !info.HasPricing {
+		return estimate
+	}
+
+	outputRate := info.Pricing.Output / 1_000_000
+	estimate.InputCost = info.EstimateCost(tokens)
+	estimate.Low = estimate.InputCost + shortReplyTokens*outputRate
+	estimate.High = estimate.InputCost + longReplyTokens*outputRate
+	return estimate
+}
+
+// estimateSend estimates sending content with the conversation so far,
+// system prompt included, to the current model
+func (m *Model) estimateSend(content string) PromptEstimate {
+	used, _ := m.contextUsage()
+	used += NewTokenCounter().EstimateTokens(content)
+	return estimatePrompt(NewModelInfo(m.currentModel), used)
+}
+
+// handleEstimateCommand previews what sending a message would cost without
+// sending it; with no message it prices the conversation as it stands
+func (m *Model) handleEstimateCommand(args []string) tea.Cmd {
+	estimate := m.estimateSend(strings.Join(args, " "))
+
+	status := fmt.Sprintf("~%s tokens", humanize.Comma(int64(estimate.Tokens)))
+	if estimate.Priced {
+		status += fmt.Sprintf(" · $%.4f input · $%.4f–$%.4f with a reply", estimate.InputCost, estimate.Low, estimate.High)
+	} else {
+		status += " · pricing unknown for " + m.currentModel.Name
+	}
+	return func() tea.Msg {
+		return statusMsg{status, 6 * time.Second}
+	}
+}
+
+// renderCostHint shows what the message being typed would cost to send,
+// when the cost preview is on
+func (m *Model) renderCostHint() string {
+	if m.config == nil || !m.config.ShowCostEstimate || m.GetCurrentState() != StateChat {
+		return ""
+	}
+	if strings.TrimSpace(m.inputBuffer) == "" || m.getInputMode() != InputModeNormal {
+		return ""
+	}
+
+	estimate := m.estimateSend(m.inputBuffer)
+	if !estimate.Priced {
+		return ""
+	}
+	return fmt.Sprintf("~$%.4f–$%.4f to send", estimate.Low, estimate.High)
+}
@@ -0,0 +1,182 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/john/klip/internal/api"
+)
+
+// modelFallbackMsg reports the client for a rate-limit fallback model, or
+// why it couldn't be created
+type modelFallbackMsg struct {
+	from   api.Model
+	to     api.Model
+	client api.ProviderInterface
+	err    error
+}
+
+// handleRateLimit retries a rate-limited request with backoff up to the
+// configured MaxRetries, then switches to Config.FallbackModel when one is
+// set. It returns nil when err isn't a rate limit or nothing is left to
+// try, leaving the caller to report the error.
+func (m *Model) handleRateLimit(err error) tea.Cmd {
+	var apiErr *api.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+	cooldownCmd := m.recordRateLimit(apiErr.RateLimit)
+
+	maxRetries := m.maxRetries()
+	if m.chatState.RateLimitRetries < maxRetries {
+		m.chatState.RateLimitRetries++
+		attempt := m.chatState.RateLimitRetries
+		delay := NewRetryStrategy().WithMaxRetries(maxRetries).CalculateDelay(attempt)
+		if wait := m.cooldownRemaining(); wait > 0 {
+			// The provider said how long to wait
+			delay = wait
+		}
+		request := m.buildChatRequest()
+
+		status := fmt.Sprintf("Rate limited, retrying in %s (%d/%d)", delay.Round(time.Second), attempt, maxRetries)
+		return tea.Batch(
+			func() tea.Msg { return statusMsg{status, delay} },
+			tea.Tick(delay, func(time.Time) tea.Msg { return apiRequestMsg{request} }),
+			cooldownCmd,
+		)
+	}
+
+	fallback, ok := m.fallbackModel()
+	if !ok {
+		return nil
+	}
+
+	from := m.currentModel
+	client := m.apiClient
+	m.logger.Warn("Rate limited after retries, switching model", "from", from.ID, "to", fallback.ID)
+
+	return func() tea.Msg {
+		// Models from the same provider share the existing client
+		if client == nil || fallback.Provider != from.Provider {
+			var err error
+			client, err = m.newProviderClient(fallback)
+			if err != nil {
+				return modelFallbackMsg{from: from, to: fallback, err: err}
+			}
+		}
+		return modelFallbackMsg{from: from, to: fallback, client: client}
+	}
+}
+
+// applyModelFallback makes the fallback model current and resends the
+// conversation to it
+func (m *Model) applyModelFallback(msg modelFallbackMsg) tea.Cmd {
+	if msg.err != nil {
+		m.chatState.WaitingForAPI = false
+		m.setError(fmt.Errorf("rate limited on %s; switching to %s failed: %w", msg.from.Name, msg.to.Name, msg.err), "API request failed", true)
+		return nil
+	}
+
+	m.apiClient = msg.client
+	m.currentModel = msg.to
+	m.modelsState.CurrentModel = msg.to
+	m.chatState.RateLimitRetries = 0
+
+	if m.storage != nil && m.storage.AnalyticsLogger != nil {
+		if err := m.storage.AnalyticsLogger.LogModelSwitchWithReason(
+			msg.from.ID, msg.from.Name, string(msg.from.Provider),
+			msg.to.ID, msg.to.Name, string(msg.to.Provider), "rate_limit",
+		); err != nil {
+			m.logger.Warn("Failed to log model switch", "error", err)
+		}
+	}
+
+	request := m.buildChatRequest()
+	status := fmt.Sprintf("%s is rate limited, switched to %s", msg.from.Name, msg.to.Name)
+	return tea.Batch(
+		func() tea.Msg { return statusMsg{status, 5 * time.Second} },
+		func() tea.Msg { return apiRequestMsg{request} },
+	)
+}
+
+// fallbackModel resolves Config.FallbackModel, reporting false when no
+// fallback is configured or it is already the current model
+func (m *Model) fallbackModel() (api.Model, bool) {
+	if m.config == nil {
+		return api.Model{}, false
+	}
+	id := strings.TrimSpace(m.config.FallbackModel)
+	if id == "" || id == m.currentModel.ID {
+		return api.Model{}, false
+	}
+
+	return m.lookupModel(id), true
+}
+
+// maxRetries returns how often a rate-limited request is retried
+func (m *Model) maxRetries() int {
+	if m.config != nil && m.config.MaxRetries > 0 {
+		return m.config.MaxRetries
+	}
+	return api.DefaultRetryConfig().MaxRetries
+}
+
+// cooldownTickMsg refreshes the rate limit countdown in the status bar
+type cooldownTickMsg struct{}
+
+// recordRateLimit keeps the quota a response reported and starts a
+// cooldown when it carried a Retry-After
+func (m *Model) recordRateLimit(limit *api.RateLimit) tea.Cmd {
+	if limit == nil {
+		return nil
+	}
+	m.chatState.RateLimit = limit
+	if limit.RetryAfter <= 0 {
+		return nil
+	}
+	m.chatState.CooldownUntil = time.Now().Add(limit.RetryAfter)
+	return m.cooldownTick()
+}
+
+// cooldownRemaining returns how long until sending is allowed again
+func (m *Model) cooldownRemaining() time.Duration {
+	if wait := time.Until(m.chatState.CooldownUntil); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// cooldownTick schedules the next countdown refresh while cooling down
+func (m *Model) cooldownTick() tea.Cmd {
+	wait := m.cooldownRemaining()
+	if wait <= 0 {
+		return nil
+	}
+	// Land on whole seconds so the countdown reads 12s, 11s, ...
+	next := wait % time.Second
+	if next == 0 {
+		next = time.Second
+	}
+	return tea.Tick(next, func(time.Time) tea.Msg { return cooldownTickMsg{} })
+}
+
+// renderCooldown shows the rate limit countdown, or nothing when sending
+// is allowed
+func (m *Model) renderCooldown() string {
+	wait := m.cooldownRemaining()
+	if wait <= 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(warningColor).Render(formatRetryIn(wait))
+}
+
+// formatRetryIn renders a cooldown as "retry in 12s", rounding up so it
+// never reads zero while still waiting
+func formatRetryIn(wait time.Duration) string {
+	return fmt.Sprintf("retry in %ds", int((wait+time.Second-1)/time.Second))
+}
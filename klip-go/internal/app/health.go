@@ -0,0 +1,132 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/john/klip/internal/api"
+	"github.com/john/klip/internal/api/providers"
+)
+
+// healthCheckTimeout bounds the startup health check as a whole
+const healthCheckTimeout = 10 * time.Second
+
+// ProviderHealthMsg reports the startup health check, one connection
+// result per provider tested
+type ProviderHealthMsg struct {
+	Results []providers.ConnectionResult
+}
+
+// Healthy maps each tested provider to whether its key worked, the shape
+// of the status bar's api_health update. A rate limited key still works.
+func (msg ProviderHealthMsg) Healthy() map[string]bool {
+	healthy := make(map[string]bool, len(msg.Results))
+	for _, result := range msg.Results {
+		healthy[string(result.Provider)] = result.Status == providers.ConnectionOK ||
+			result.Status == providers.ConnectionRateLimited
+	}
+	return healthy
+}
+
+// Problems returns the results whose provider can't be used
+func (msg ProviderHealthMsg) Problems() []providers.ConnectionResult {
+	var problems []providers.ConnectionResult
+	for _, result := range msg.Results {
+		if result.Status != providers.ConnectionOK && result.Status != providers.ConnectionRateLimited {
+			problems = append(problems, result)
+		}
+	}
+	return problems
+}
+
+// checkProviderHealth tests the providers with a key, and the default
+// provider even without one, when Config.StartupHealthCheck is set
+func (m *Model) checkProviderHealth() tea.Cmd {
+	if m.config == nil || !m.config.StartupHealthCheck || m.storage == nil || m.storage.KeyStore == nil {
+		return nil
+	}
+
+	keys := make(map[api.Provider]string)
+	baseURLs := make(map[api.Provider]string)
+	for _, provider := range []api.Provider{api.ProviderAnthropic, api.ProviderOpenAI, api.ProviderOpenRouter} {
+		key, _ := m.storage.KeyStore.GetKey(string(provider))
+		if key == "" && string(provider) != m.config.DefaultProvider {
+			continue
+		}
+		keys[provider] = key
+		baseURLs[provider] = m.config.BaseURLFor(string(provider))
+	}
+
+	httpClient := api.NewHTTPClient(healthCheckTimeout, api.ProxySettingsFromConfig(m.config))
+	ctx := m.ctx
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		defer cancel()
+		return ProviderHealthMsg{Results: probeProviders(ctx, keys, baseURLs, httpClient)}
+	}
+}
+
+// probeProviders tests each provider's key concurrently, returning the
+// results in provider order
+func probeProviders(ctx context.Context, keys, baseURLs map[api.Provider]string, httpClient *http.Client) []providers.ConnectionResult {
+	var results []providers.ConnectionResult
+	for _, provider := range []api.Provider{api.ProviderAnthropic, api.ProviderOpenAI, api.ProviderOpenRouter} {
+		if _, ok := keys[provider]; ok {
+			results = append(results, providers.ConnectionResult{Provider: provider})
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			provider := results[i].Provider
+			results[i] = providers.CheckConnection(ctx, provider, strings.TrimSpace(keys[provider]), baseURLs[provider], httpClient)
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+// reportProviderHealth keeps the health check for the status bar and names
+// the providers that failed it
+func (m *Model) reportProviderHealth(msg ProviderHealthMsg) tea.Cmd {
+	m.providerHealth = msg.Healthy()
+
+	problems := msg.Problems()
+	if len(problems) == 0 {
+		return nil
+	}
+
+	parts := make([]string, len(problems))
+	for i, result := range problems {
+		parts[i] = fmt.Sprintf("%s: %s", result.Provider, result.Message())
+	}
+	status := "Provider check · " + strings.Join(parts, " · ")
+	return func() tea.Msg {
+		return statusMsg{status, 10 * time.Second}
+	}
+}
+
+// renderProviderHealth shows the health check in the status bar, marking
+// each provider tested as usable or not
+func (m *Model) renderProviderHealth() string {
+	var parts []string
+	for _, provider := range []api.Provider{api.ProviderAnthropic, api.ProviderOpenAI, api.ProviderOpenRouter} {
+		healthy, tested := m.providerHealth[string(provider)]
+		switch {
+		case !tested:
+		case healthy:
+			parts = append(parts, successStyle.Render("✓ "+provider.DisplayName()))
+		default:
+			parts = append(parts, errorStyle.Render("✗ "+provider.DisplayName()))
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// idleCheckInterval is how often the idle detector looks for an idle
+// conversation to archive
+const idleCheckInterval = time.Minute
+
+// idleCheckMsg wakes the idle detector
+type idleCheckMsg struct{ time time.Time }
+
+// idleTick schedules the next idle check. It keeps running while the
+// archive is off, so turning it on in settings takes effect right away.
+func (m *Model) idleTick() tea.Cmd {
+	return tea.Tick(idleCheckInterval, func(t time.Time) tea.Msg {
+		return idleCheckMsg{t}
+	})
+}
+
+// idleArchiveTimeout returns how long a conversation may sit untouched
+// before it is archived, or zero when the archive is off
+func (m *Model) idleArchiveTimeout() time.Duration {
+	if m.config == nil || m.config.IdleArchiveMinutes <= 0 {
+		return 0
+	}
+	return time.Duration(m.config.IdleArchiveMinutes) * time.Minute
+}
+
+// checkIdle archives the conversation once there has been no input for the
+// idle timeout, unless a reply is still on its way
+func (m *Model) checkIdle(now time.Time) tea.Cmd {
+	timeout := m.idleArchiveTimeout()
+	if timeout <= 0 || len(m.chatState.Messages) == 0 {
+		return nil
+	}
+	if m.chatState.IsStreaming || m.chatState.WaitingForAPI || now.Sub(m.lastActivity) < timeout {
+		return nil
+	}
+	return m.archiveIdleConversation()
+}
+
+// archiveIdleConversation saves the current session and starts a new one,
+// so the next message doesn't continue a stale thread. The archived
+// session stays available to /resume.
+func (m *Model) archiveIdleConversation() tea.Cmd {
+	m.logger.Info("Archiving idle conversation", "messages", len(m.chatState.Messages))
+
+	m.chatState.ClearMessages()
+	m.chatState.ContextWarned = false
+	m.chatState.Recovery = nil
+	m.chatState.FailedRequest = nil
+	m.chatState.IdleArchived = true
+
+	if m.storage == nil || m.storage.ChatLogger == nil {
+		return nil
+	}
+	chatLogger := m.storage.ChatLogger
+	return func() tea.Msg {
+		if err := chatLogger.EndSession(); err != nil {
+			m.logger.Error("Failed to save idle conversation", "error", err)
+		}
+		if err := chatLogger.StartSession(); err != nil {
+			m.logger.Error("Failed to start a new session", "error", err)
+		}
+		return nil
+	}
+}
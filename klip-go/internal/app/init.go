@@ -3,7 +3,6 @@ package app
 import (
 	"context"
 	"fmt"
-	"net/http"
 	"os"
 	"time"
 
@@ -12,6 +11,7 @@ import (
 	"github.com/john/klip/internal/api"
 	"github.com/john/klip/internal/api/providers"
 	"github.com/john/klip/internal/storage"
+	"github.com/john/klip/internal/ui/styles"
 )
 
 // initializeApp initializes all application components
@@ -161,24 +161,33 @@ func (m *Model) initializeAPIClient() tea.Cmd {
 
 // initAPIClientForModel initializes the API client for a specific model
 func (m *Model) initAPIClientForModel(model api.Model) error {
+	provider, err := m.newProviderClient(model)
+	if err != nil {
+		return err
+	}
+
+	m.apiClient = provider
+	return nil
+}
+
+// newProviderClient creates and validates a client for the model's provider
+func (m *Model) newProviderClient(model api.Model) (api.ProviderInterface, error) {
 	if m.storage == nil || m.storage.KeyStore == nil {
-		return fmt.Errorf("keystore not available")
+		return nil, fmt.Errorf("keystore not available")
 	}
 
 	// Get API key for the model's provider
 	apiKey, err := m.storage.KeyStore.GetKey(string(model.Provider))
 	if err != nil {
-		return fmt.Errorf("failed to get API key for %s: %w", model.Provider, err)
+		return nil, fmt.Errorf("failed to get API key for %s: %w", model.Provider, err)
 	}
 
 	if apiKey == "" {
-		return fmt.Errorf("no API key found for provider %s", model.Provider)
+		return nil, fmt.Errorf("no API key found for provider %s", model.Provider)
 	}
 
-	// Create HTTP client
-	httpClient := &http.Client{
-		Timeout: 120 * time.Second,
-	}
+	// Create HTTP client, routed through the configured proxy
+	httpClient := api.NewHTTPClient(120*time.Second, api.ProxySettingsFromConfig(m.config))
 
 	// Create provider-specific client
 	var provider api.ProviderInterface
@@ -191,11 +200,15 @@ func (m *Model) initAPIClientForModel(model api.Model) error {
 	case api.ProviderOpenRouter:
 		provider, err = providers.NewOpenRouterProvider(apiKey, httpClient)
 	default:
-		return fmt.Errorf("unsupported provider: %s", model.Provider)
+		return nil, fmt.Errorf("unsupported provider: %s", model.Provider)
 	}
 
 	if err != nil {
-		return fmt.Errorf("failed to create provider client: %w", err)
+		return nil, fmt.Errorf("failed to create provider client: %w", err)
+	}
+
+	if err := m.applyBaseURL(provider, model.Provider); err != nil {
+		return nil, err
 	}
 
 	// Validate credentials
@@ -203,10 +216,24 @@ func (m *Model) initAPIClientForModel(model api.Model) error {
 	defer cancel()
 
 	if err := provider.ValidateCredentials(ctx); err != nil {
-		return fmt.Errorf("credential validation failed: %w", err)
+		return nil, fmt.Errorf("credential validation failed: %w", err)
 	}
 
-	m.apiClient = provider
+	return m.newAPIClient(provider)
+}
+
+// applyBaseURL routes provider through the base URL configured for it, if any
+func (m *Model) applyBaseURL(provider api.ProviderInterface, name api.Provider) error {
+	baseURL := m.config.BaseURLFor(string(name))
+	if baseURL == "" {
+		m.logger.Debug("Using default API endpoint", "provider", name)
+		return nil
+	}
+
+	if err := providers.SetBaseURL(provider, baseURL); err != nil {
+		return fmt.Errorf("invalid base URL for %s: %w", name, err)
+	}
+	m.logger.Info("Using custom API endpoint", "provider", name, "base_url", baseURL)
 	return nil
 }
 
@@ -228,6 +255,18 @@ func (m *Model) getDefaultModel() api.Model {
 	}
 }
 
+// configRecoveryNotice explains that the config file couldn't be read and
+// where the original went, or returns "" when it loaded cleanly
+func (m *Model) configRecoveryNotice() string {
+	if m.storage == nil || m.storage.ConfigManager == nil {
+		return ""
+	}
+	if recovery := m.storage.ConfigManager.Recovery(); recovery != nil {
+		return recovery.Notice()
+	}
+	return ""
+}
+
 // applyConfiguration applies loaded configuration to the application
 func (m *Model) applyConfiguration(config *storage.Config) {
 	if config.Analytics != nil {
@@ -239,28 +278,57 @@ func (m *Model) applyConfiguration(config *storage.Config) {
 		// UI config will be used in rendering
 	}
 
+	// Themes saved by /theme; others, like the old "dark", keep the
+	// detected default
+	if _, ok := styles.DefaultThemeManager.GetAvailableThemes()[config.Theme]; ok {
+		if err := styles.SetTheme(config.Theme); err != nil {
+			m.logger.Warn("Failed to apply theme", "theme", config.Theme, "error", err)
+		}
+	}
+	m.applyAccessibility(config)
+
 	// Apply other configuration options as needed
 }
 
-// loadAvailableModels loads available models from all providers
+// modelListTimeout bounds fetching one provider's model list
+const modelListTimeout = 30 * time.Second
+
+// defaultModelCacheDuration keeps model lists when Config.CacheModels is
+// set without a Config.CacheDuration
+const defaultModelCacheDuration = 5 * time.Minute
+
+// loadAvailableModels lists the models of every provider with an API key,
+// using the built-in models for the others and when a list can't be fetched
 func (m *Model) loadAvailableModels() tea.Cmd {
+	catalog := m.modelCatalog()
+	staticModels := m.getStaticModels()
+	ctx := m.ctx
+
 	return tea.Cmd(func() tea.Msg {
 		var allModels []api.Model
-
-		// Get static models first
-		staticModels := m.getStaticModels()
-		allModels = append(allModels, staticModels...)
-
-		// Try to load dynamic models from providers that support it
-		if m.storage != nil && m.storage.KeyStore != nil {
-			// Check OpenRouter for dynamic models
-			if hasKey, err := m.storage.KeyStore.HasKey(string(api.ProviderOpenRouter)); err == nil && hasKey {
-				if dynamicModels, err := m.loadOpenRouterModels(); err == nil {
-					allModels = append(allModels, dynamicModels...)
-				} else {
-					m.logger.Warn("Failed to load OpenRouter models", "error", err)
+		for _, provider := range []api.Provider{api.ProviderAnthropic, api.ProviderOpenAI, api.ProviderOpenRouter} {
+			var builtin []api.Model
+			for _, model := range staticModels {
+				if model.Provider == provider {
+					builtin = append(builtin, model)
 				}
 			}
+
+			client, err := m.modelListClient(provider)
+			if err != nil {
+				allModels = append(allModels, builtin...)
+				continue
+			}
+			catalog.RegisterProvider(provider, client)
+
+			fetchCtx, cancel := context.WithTimeout(ctx, modelListTimeout)
+			models, err := catalog.FetchModels(fetchCtx, provider)
+			cancel()
+			if err != nil || len(models) == 0 {
+				m.logger.Warn("Failed to fetch models, using built-in list", "provider", provider, "error", err)
+				models = builtin
+			}
+			allModels = append(allModels, models...)
 		}
 
 		m.logger.Info("Loaded models", "count", len(allModels))
@@ -268,6 +336,24 @@ func (m *Model) loadAvailableModels() tea.Cmd {
 	})
 }
 
+// modelCatalog returns the model list cache, applying the configured
+// caching to it
+func (m *Model) modelCatalog() *api.ModelManager {
+	if m.modelManager == nil {
+		m.modelManager = api.NewModelManager()
+	}
+
+	duration := time.Duration(0)
+	if m.config != nil && m.config.CacheModels {
+		duration = m.config.CacheDuration
+		if duration <= 0 {
+			duration = defaultModelCacheDuration
+		}
+	}
+	m.modelManager.SetCacheDuration(duration)
+	return m.modelManager
+}
+
 // getStaticModels returns a list of static models
 func (m *Model) getStaticModels() []api.Model {
 	return []api.Model{
@@ -335,34 +421,27 @@ func (m *Model) getStaticModels() []api.Model {
 	}
 }
 
-// loadOpenRouterModels loads dynamic models from OpenRouter
-func (m *Model) loadOpenRouterModels() ([]api.Model, error) {
+// modelListClient creates a client for listing provider's models, without
+// the credential check a chat client gets
+func (m *Model) modelListClient(provider api.Provider) (api.ProviderInterface, error) {
 	if m.storage == nil || m.storage.KeyStore == nil {
 		return nil, fmt.Errorf("keystore not available")
 	}
 
-	apiKey, err := m.storage.KeyStore.GetKey(string(api.ProviderOpenRouter))
+	apiKey, err := m.storage.KeyStore.GetKey(string(provider))
 	if err != nil || apiKey == "" {
-		return nil, fmt.Errorf("no OpenRouter API key found")
+		return nil, fmt.Errorf("no API key found for provider %s", provider)
 	}
 
-	// Create OpenRouter provider
-	httpClient := &http.Client{Timeout: 30 * time.Second}
-	provider, err := providers.NewOpenRouterProvider(apiKey, httpClient)
+	httpClient := api.NewHTTPClient(modelListTimeout, api.ProxySettingsFromConfig(m.config))
+	client, err := providers.NewProvider(provider, apiKey, httpClient)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create OpenRouter provider: %w", err)
+		return nil, fmt.Errorf("failed to create %s provider: %w", provider, err)
 	}
-
-	// Get models with timeout
-	ctx, cancel := context.WithTimeout(m.ctx, 30*time.Second)
-	defer cancel()
-
-	models, err := provider.GetModels(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch OpenRouter models: %w", err)
+	if err := m.applyBaseURL(client, provider); err != nil {
+		return nil, err
 	}
-
-	return models, nil
+	return client, nil
 }
 
 // performAPIRequest performs an API request with the current client
@@ -426,7 +505,7 @@ func (m *Model) performStreamingRequest(request *api.ChatRequest) tea.Cmd {
 
 					if chunk.Done {
 						tea.Batch(func() tea.Msg {
-							return apiStreamDoneMsg{}
+							return apiStreamDoneMsg{chunk.FinishReason, chunk.RateLimit, chunk.Usage}
 						})()
 						return
 					}
@@ -460,12 +539,6 @@ func (m *Model) switchModel(model api.Model) tea.Cmd {
 			return apiErrorMsg{fmt.Errorf("failed to switch to model %s: %w", model.Name, err)}
 		}
 
-		// Update current model
-		m.currentModel = model
-
-		// Log the model switch
-		// TODO: Implement model switch logging when method is available
-
 		return modelSwitchMsg{model}
 	})
 }
@@ -0,0 +1,105 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/john/klip/internal/api"
+)
+
+// maxRecentModels caps how many recently used models are remembered
+const maxRecentModels = 8
+
+// openModelPicker shows the palette with only models: recent ones first,
+// then favorites, then the rest of the available models
+func (m *Model) openModelPicker() {
+	m.palette = &CommandPalette{Active: true, actions: m.modelPickerActions(), noun: "models"}
+	m.palette.filter()
+}
+
+// modelPickerActions builds one switch action per available model, ordered
+// for the quick picker
+func (m *Model) modelPickerActions() []PaletteAction {
+	byID := make(map[string]api.Model, len(m.modelsState.AvailableModels))
+	for _, model := range m.modelsState.AvailableModels {
+		byID[model.ID] = model
+	}
+
+	var recent, favorites []string
+	if m.config != nil {
+		recent, favorites = m.config.RecentModels, m.config.FavoriteModels
+	}
+
+	var actions []PaletteAction
+	added := make(map[string]bool)
+	add := func(model api.Model, hint string) {
+		if added[model.ID] {
+			return
+		}
+		added[model.ID] = true
+		if model.ID == m.currentModel.ID {
+			hint = "current"
+		}
+		actions = append(actions, PaletteAction{
+			Title:    modelDisplayName(model),
+			Hint:     hint,
+			Keywords: []string{model.ID},
+			run:      func(m *Model) tea.Cmd { return m.switchToModel(model) },
+		})
+	}
+
+	for _, id := range recent {
+		if model, ok := byID[id]; ok {
+			add(model, "recent")
+		}
+	}
+	for _, id := range favorites {
+		if model, ok := byID[id]; ok {
+			add(model, "★")
+		}
+	}
+	for _, model := range m.modelsState.AvailableModels {
+		add(model, "")
+	}
+	return actions
+}
+
+// recordModelSwitch logs a model_switch event and remembers the new model
+// as the most recent one
+func (m *Model) recordModelSwitch(from, to api.Model) {
+	if from.ID == to.ID {
+		return
+	}
+
+	if m.storage != nil && m.storage.AnalyticsLogger != nil {
+		if err := m.storage.AnalyticsLogger.LogModelSwitch(
+			from.ID, from.Name, string(from.Provider),
+			to.ID, to.Name, string(to.Provider),
+		); err != nil {
+			m.logger.Warn("Failed to log model switch", "error", err)
+		}
+	}
+
+	if m.config == nil {
+		return
+	}
+	m.config.RecentModels = pushRecentModel(m.config.RecentModels, to.ID)
+	if m.storage != nil && m.storage.ConfigManager != nil {
+		config := m.config
+		go func() {
+			if err := m.storage.ConfigManager.SaveConfig(config); err != nil {
+				m.logger.Error("Failed to save recent models", "error", err)
+			}
+		}()
+	}
+}
+
+// pushRecentModel moves id to the front of recent, dropping the oldest
+// entries past maxRecentModels
+func pushRecentModel(recent []string, id string) []string {
+	updated := []string{id}
+	for _, existing := range recent {
+		if existing != id && len(updated) < maxRecentModels {
+			updated = append(updated, existing)
+		}
+	}
+	return updated
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxEventLog caps how many past notifications the event log keeps
+const maxEventLog = 500
+
+// LoggedEvent is a status message or error kept after it left the screen
+type LoggedEvent struct {
+	Time    time.Time
+	Kind    string
+	Message string
+}
+
+// EventLogView is the /notifications list, shown in place of the
+// conversation
+type EventLogView struct {
+	Scroll int
+}
+
+// logEvent keeps a notification in the event log, dropping the oldest
+// once it is full
+func (m *Model) logEvent(kind, message string) {
+	if message == "" {
+		return
+	}
+	m.eventLog = append(m.eventLog, LoggedEvent{Time: time.Now(), Kind: kind, Message: message})
+	if len(m.eventLog) > maxEventLog {
+		m.eventLog = m.eventLog[len(m.eventLog)-maxEventLog:]
+	}
+}
+
+// handleNotificationLog opens the event log, or clears it
+func (m *Model) handleNotificationLog(msg NotificationLogMsg) {
+	if msg.Clear {
+		m.eventLog = nil
+		return
+	}
+	m.chatState.EventLog = &EventLogView{}
+}
+
+// handleEventLogKeys scrolls, clears or closes the event log; other keys go
+// on to the composer after closing it
+func (m *Model) handleEventLogKeys(msg tea.KeyMsg) bool {
+	view := m.chatState.EventLog
+	last := max(len(m.eventLog)-1, 0)
+	switch msg.String() {
+	case "up", "k":
+		view.Scroll = max(view.Scroll-1, 0)
+	case "down", "j":
+		view.Scroll = min(view.Scroll+1, last)
+	case "pgup":
+		view.Scroll = max(view.Scroll-10, 0)
+	case "pgdown":
+		view.Scroll = min(view.Scroll+10, last)
+	case "c":
+		m.eventLog = nil
+		view.Scroll = 0
+	case "esc", "q":
+		m.chatState.EventLog = nil
+	default:
+		m.chatState.EventLog = nil
+		return false
+	}
+	return true
+}
+
+// renderEventLog lists past notifications, newest first
+func (m *Model) renderEventLog(height int) string {
+	header := titleStyle.Render("Notifications") + " " +
+		mutedStyle.Render(fmt.Sprintf("%d events", len(m.eventLog)))
+	footer := mutedStyle.Render("↑/↓ PgUp/PgDn: Scroll | c: Clear | Esc: Close")
+	if len(m.eventLog) == 0 {
+		return strings.Join([]string{header, "", mutedStyle.Render("No notifications yet"), "", footer}, "\n")
+	}
+
+	visible := max(height-4, 1)
+	start := min(m.chatState.EventLog.Scroll, max(len(m.eventLog)-visible, 0))
+	end := min(start+visible, len(m.eventLog))
+
+	lines := []string{header, ""}
+	for i := start; i < end; i++ {
+		event := m.eventLog[len(m.eventLog)-1-i]
+		kind := mutedStyle.Render(fmt.Sprintf("%-5s", event.Kind))
+		if event.Kind == "error" {
+			kind = errorStyle.Render(fmt.Sprintf("%-5s", event.Kind))
+		}
+		lines = append(lines, fmt.Sprintf("%s %s %s",
+			mutedStyle.Render(event.Time.Format("15:04:05")),
+			kind,
+			truncateText(event.Message, max(m.width-20, 10))))
+	}
+	lines = append(lines, "", footer)
+	return strings.Join(lines, "\n")
+}
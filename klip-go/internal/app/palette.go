@@ -0,0 +1,313 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/john/klip/internal/ui/styles"
+)
+
+// maxPaletteResults limits how many matches the palette lists at once
+const maxPaletteResults = 10
+
+// PaletteAction is one entry in the command palette
+type PaletteAction struct {
+	Title string
+	// Hint is shown beside the title, e.g. the equivalent slash command
+	Hint string
+	// Keywords are matched by the search but not shown
+	Keywords []string
+	run      func(m *Model) tea.Cmd
+}
+
+// CommandPalette is the Ctrl+K overlay that searches every action
+type CommandPalette struct {
+	Active   bool
+	Query    string
+	Selected int
+	Matches  []PaletteAction
+	actions  []PaletteAction
+
+	// noun names the entries in the footer; empty means "actions"
+	noun string
+}
+
+// openPalette collects the current actions and shows the palette
+func (m *Model) openPalette() {
+	m.palette = &CommandPalette{Active: true, actions: m.paletteActions()}
+	m.palette.filter()
+}
+
+// closePalette hides the palette
+func (m *Model) closePalette() {
+	m.palette = nil
+}
+
+// paletteActions lists the slash commands followed by actions built from
+// the current models, themes and sessions
+func (m *Model) paletteActions() []PaletteAction {
+	var actions []PaletteAction
+
+	commands := m.commands.List()
+	sort.Slice(commands, func(i, j int) bool { return commands[i].Name < commands[j].Name })
+	for _, command := range commands {
+		command := command
+		actions = append(actions, PaletteAction{
+			Title:    command.Description,
+			Hint:     "/" + command.Name,
+			Keywords: append([]string{command.Name}, command.Aliases...),
+			run: func(m *Model) tea.Cmd {
+				// Commands that need arguments are started in the input instead
+				if strings.Contains(command.Usage, "<") {
+					m.TransitionTo(StateChat)
+					m.inputBuffer = "/" + command.Name + " "
+					m.cursorPos = len(m.inputBuffer)
+					return nil
+				}
+				return m.ExecuteCommand("/" + command.Name)
+			},
+		})
+	}
+
+	for _, model := range m.modelsState.AvailableModels {
+		model := model
+		actions = append(actions, PaletteAction{
+			Title:    "Switch model: " + modelDisplayName(model),
+			Hint:     string(model.Provider),
+			Keywords: []string{model.ID},
+			run:      func(m *Model) tea.Cmd { return m.switchToModel(model) },
+		})
+	}
+
+	for _, name := range themeNames() {
+		name := name
+		actions = append(actions, PaletteAction{
+			Title: "Theme: " + styles.DefaultThemeManager.GetAvailableThemes()[name].DisplayName,
+			Hint:  "/theme " + name,
+			run:   func(m *Model) tea.Cmd { return m.ExecuteCommand("/theme " + name) },
+		})
+	}
+
+	for i, session := range m.historyState.Sessions {
+		i, session := i, session
+		title := session.Title
+		if title == "" {
+			title = session.CreatedAt.Format("2006-01-02 15:04")
+		}
+		actions = append(actions, PaletteAction{
+			Title:    "Open session: " + title,
+			Hint:     fmt.Sprintf("%d messages", len(session.Messages)),
+			Keywords: []string{"session", "history", session.ID},
+			run: func(m *Model) tea.Cmd {
+				m.TransitionTo(StateHistory)
+				m.historyState.SelectedIndex = i
+				m.historyState.ViewingDetail = true
+				m.historyState.DetailSession = &session
+				return nil
+			},
+		})
+	}
+
+	return actions
+}
+
+// filter ranks the actions against the query, keeping their original order
+// among equal scores
+func (p *CommandPalette) filter() {
+	type match struct {
+		action PaletteAction
+		score  int
+	}
+
+	var matches []match
+	for _, action := range p.actions {
+		if score := paletteScore(p.Query, action); score > 0 {
+			matches = append(matches, match{action, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	p.Matches = p.Matches[:0]
+	for _, m := range matches {
+		p.Matches = append(p.Matches, m.action)
+	}
+	if p.Selected >= len(p.Matches) {
+		p.Selected = max(len(p.Matches)-1, 0)
+	}
+}
+
+// paletteScore rates how well an action matches a query. Every word of the
+// query must match; whole-word and prefix matches in the title outrank
+// substrings, which outrank keyword hits and scattered fuzzy matches.
+func paletteScore(query string, action PaletteAction) int {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return 1
+	}
+
+	title := strings.ToLower(action.Title)
+	words := strings.FieldsFunc(title, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || '0' <= r && r <= '9')
+	})
+	keywords := strings.ToLower(strings.Join(append(action.Keywords, action.Hint), " "))
+
+	total := 0
+	for _, term := range terms {
+		score := 0
+		for _, word := range words {
+			switch {
+			case word == term:
+				score = max(score, 100)
+			case strings.HasPrefix(word, term):
+				score = max(score, 80)
+			}
+		}
+		switch {
+		case score > 0:
+		case strings.Contains(title, term):
+			score = 60
+		case strings.Contains(keywords, term):
+			score = 50
+		default:
+			score = fuzzyScore(title, term)
+		}
+		if score == 0 {
+			return 0
+		}
+		total += score
+	}
+	return total
+}
+
+// fuzzyScore matches term as a subsequence of text, scoring tighter matches
+// higher; it returns 0 when term doesn't occur
+func fuzzyScore(text, term string) int {
+	first, last, next := -1, -1, 0
+	runes := []rune(term)
+	for i, r := range text {
+		if next < len(runes) && r == runes[next] {
+			if first < 0 {
+				first = i
+			}
+			last = i
+			next++
+		}
+	}
+	if next < len(runes) {
+		return 0
+	}
+	spread := last - first + 1 - len(runes)
+	return max(40-spread*2, 10)
+}
+
+// handlePaletteKeys drives the open palette; it consumes every key
+func (m *Model) handlePaletteKeys(msg tea.KeyMsg) tea.Cmd {
+	p := m.palette
+	switch msg.String() {
+	case "esc", "ctrl+k", "ctrl+o", "ctrl+c":
+		m.closePalette()
+	case "enter":
+		if len(p.Matches) == 0 {
+			return nil
+		}
+		action := p.Matches[p.Selected]
+		m.closePalette()
+		return action.run(m)
+	case "up", "ctrl+p":
+		if p.Selected > 0 {
+			p.Selected--
+		}
+	case "down", "ctrl+n", "tab":
+		if p.Selected < len(p.Matches)-1 {
+			p.Selected++
+		}
+	case "backspace":
+		if p.Query != "" {
+			runes := []rune(p.Query)
+			p.Query = string(runes[:len(runes)-1])
+			p.Selected = 0
+			p.filter()
+		}
+	case "ctrl+u":
+		p.Query = ""
+		p.Selected = 0
+		p.filter()
+	default:
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			p.Query += string(msg.Runes)
+			p.Selected = 0
+			p.filter()
+		}
+	}
+	return nil
+}
+
+// renderPalette draws the palette as a centered modal
+func (m *Model) renderPalette() string {
+	p := m.palette
+	width := min(max(m.width-8, 30), 72)
+
+	lines := []string{
+		paletteQueryStyle.Render("> "+p.Query) + paletteCursorStyle.Render(" "),
+		"",
+	}
+
+	// Keep the selection in view
+	start := 0
+	if p.Selected >= maxPaletteResults {
+		start = p.Selected - maxPaletteResults + 1
+	}
+	end := min(start+maxPaletteResults, len(p.Matches))
+
+	for i := start; i < end; i++ {
+		action := p.Matches[i]
+		hint := mutedStyle.Render(action.Hint)
+		titleWidth := width - 6 - lipgloss.Width(hint)
+		title := truncateText(action.Title, titleWidth)
+
+		style := paletteItemStyle
+		marker := "  "
+		if i == p.Selected {
+			style = paletteSelectedStyle
+			marker = "▸ "
+		}
+		gap := strings.Repeat(" ", max(titleWidth-lipgloss.Width(title), 1))
+		lines = append(lines, style.Render(marker+title)+gap+hint)
+	}
+
+	noun := p.noun
+	if noun == "" {
+		noun = "actions"
+	}
+	if len(p.Matches) == 0 {
+		lines = append(lines, mutedStyle.Render("  No matching "+noun))
+	}
+	lines = append(lines, "", mutedStyle.Render(fmt.Sprintf("%d %s · ↑/↓ select · enter run · esc close", len(p.Matches), noun)))
+
+	modal := paletteStyle.Width(width).Render(strings.Join(lines, "\n"))
+	return lipgloss.Place(m.width, max(m.height-1, 0), lipgloss.Center, lipgloss.Center, modal)
+}
+
+// truncateText shortens text to width cells, ending with an ellipsis
+func truncateText(text string, width int) string {
+	if lipgloss.Width(text) <= width || width < 2 {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// Palette styles, built by buildStyles
+var (
+	paletteStyle         lipgloss.Style
+	paletteQueryStyle    lipgloss.Style
+	paletteCursorStyle   lipgloss.Style
+	paletteItemStyle     lipgloss.Style
+	paletteSelectedStyle lipgloss.Style
+)
@@ -0,0 +1,130 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/john/klip/internal/storage"
+)
+
+// profileSwitchedMsg delivers the storage opened for a profile
+type profileSwitchedMsg struct {
+	name    string
+	storage *storage.Storage
+	config  *storage.Config
+	err     error
+}
+
+// handleProfileCommand lists profiles, or switches to the named one,
+// creating it on first use
+func (m *Model) handleProfileCommand(args []string) tea.Cmd {
+	current := storage.ActiveProfile()
+	if len(args) == 0 {
+		profiles, err := storage.ListProfiles()
+		if err != nil {
+			return func() tea.Msg {
+				return statusMsg{"Failed to list profiles: " + err.Error(), 3 * time.Second}
+			}
+		}
+		status := fmt.Sprintf("Profile: %s · available: %s · /profile <name> to switch", current, strings.Join(profiles, ", "))
+		return func() tea.Msg {
+			return statusMsg{status, 5 * time.Second}
+		}
+	}
+
+	name := args[0]
+	if name == current {
+		return func() tea.Msg {
+			return statusMsg{"Already using profile " + name, 2 * time.Second}
+		}
+	}
+	if m.chatState.IsStreaming || m.chatState.WaitingForAPI {
+		return func() tea.Msg {
+			return statusMsg{"Wait for the reply to finish before switching profiles", 3 * time.Second}
+		}
+	}
+
+	return func() tea.Msg {
+		return switchProfile(name)
+	}
+}
+
+// switchProfile opens the storage of the named profile without making it
+// active yet; applyProfileSwitch does that on the Update goroutine
+func switchProfile(name string) profileSwitchedMsg {
+	store, config, err := openProfileStorage(name)
+	if err != nil {
+		return profileSwitchedMsg{name: name, err: err}
+	}
+	if name == "" {
+		name = storage.DefaultProfile
+	}
+	return profileSwitchedMsg{name: name, storage: store, config: config}
+}
+
+// openProfileStorage opens storage for the named profile and starts a chat
+// session in it
+func openProfileStorage(name string) (*storage.Storage, *storage.Config, error) {
+	store, err := storage.NewForProfile(name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open profile: %w", err)
+	}
+	if err := store.Initialize(); err != nil {
+		return nil, nil, fmt.Errorf("failed to open profile: %w", err)
+	}
+	config, err := store.ConfigManager.LoadConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load profile config: %w", err)
+	}
+	return store, config, nil
+}
+
+// applyProfileSwitch makes the profile active, saves and closes the
+// previous profile's storage and moves the app over to the new one,
+// starting a fresh conversation, and reconnects the current model with the
+// profile's connection settings
+func (m *Model) applyProfileSwitch(msg profileSwitchedMsg) tea.Cmd {
+	if msg.err == nil {
+		msg.err = storage.SetProfile(msg.name)
+	}
+	if msg.err != nil {
+		if msg.storage != nil {
+			msg.storage.Shutdown()
+		}
+		return func() tea.Msg {
+			return statusMsg{"Couldn't switch profile: " + msg.err.Error(), 4 * time.Second}
+		}
+	}
+
+	if m.storage != nil {
+		m.storage.Shutdown()
+	}
+	m.storage = msg.storage
+	m.config = msg.config
+	m.applyConfiguration(msg.config)
+	m.responseCache = nil
+
+	m.chatState.ClearMessages()
+	m.chatState.ContextWarned = false
+	m.chatState.Recovery = nil
+	m.chatState.FailedRequest = nil
+	m.chatState.Stats = nil
+
+	m.logger.Info("Switched profile", "profile", msg.name)
+	status := statusMsg{"Switched to profile " + msg.name, 3 * time.Second}
+	if path := storage.ConfigFileOverride(); path != "" {
+		// --config wins over the profile's own config
+		status = statusMsg{"Switched to profile " + msg.name + " · config still from " + path, 6 * time.Second}
+	}
+	if notice := m.configRecoveryNotice(); notice != "" {
+		status = statusMsg{status.message + " · " + notice, 10 * time.Second}
+	}
+	return tea.Batch(
+		func() tea.Msg {
+			return status
+		},
+		m.switchModel(m.currentModel),
+	)
+}
@@ -0,0 +1,152 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/john/klip/internal/api"
+	"github.com/john/klip/internal/storage"
+)
+
+// sessionResumedMsg delivers a saved session that /resume made current
+type sessionResumedMsg struct {
+	session *storage.ChatLog
+	err     error
+}
+
+// handleResumeCommand continues a saved session, by default the most
+// recent one other than the current session
+func (m *Model) handleResumeCommand(args []string) tea.Cmd {
+	if m.storage == nil || m.storage.ChatLogger == nil {
+		return func() tea.Msg {
+			return statusMsg{"Chat history is not available", 3 * time.Second}
+		}
+	}
+
+	chatLogger := m.storage.ChatLogger
+	var currentID string
+	if current := chatLogger.GetCurrentSession(); current != nil {
+		currentID = current.SessionID
+	}
+
+	return func() tea.Msg {
+		var sessionID string
+		if len(args) > 0 {
+			sessionID = args[0]
+		} else {
+			sessions, err := chatLogger.ListSessions(0)
+			if err != nil {
+				return sessionResumedMsg{err: err}
+			}
+			for _, session := range sessions {
+				if session.SessionID != currentID && len(session.Messages) > 0 {
+					sessionID = session.SessionID
+					break
+				}
+			}
+			if sessionID == "" {
+				return statusMsg{"No session to resume", 2 * time.Second}
+			}
+		}
+
+		session, err := chatLogger.ResumeSession(sessionID)
+		return sessionResumedMsg{session: session, err: err}
+	}
+}
+
+// applyResumedSession shows the resumed conversation and switches back to
+// the model it used
+func (m *Model) applyResumedSession(msg sessionResumedMsg) tea.Cmd {
+	if msg.err != nil {
+		return func() tea.Msg {
+			return statusMsg{fmt.Sprintf("Can't resume session: %v", msg.err), 5 * time.Second}
+		}
+	}
+
+	m.loadSessionMessages(msg.session)
+	m.chatState.ContextWarned = false
+	m.TransitionTo(StateChat)
+
+	switchCmd, note := m.restoreSessionModel(msg.session.ToSession())
+	status := fmt.Sprintf("Resumed %s · %s", sessionTitle(msg.session), m.currentModel.Name)
+	if note != "" {
+		status += " · " + note
+	}
+	return tea.Batch(
+		switchCmd,
+		func() tea.Msg { return statusMsg{status, 5 * time.Second} },
+		m.checkContextWindow(),
+	)
+}
+
+// restoreSessionModel makes the session's model current. When that model
+// is no longer available the closest one is used instead, and note says so.
+func (m *Model) restoreSessionModel(session storage.ChatSession) (tea.Cmd, string) {
+	id := strings.TrimSpace(session.Model)
+	if id == "" {
+		return nil, ""
+	}
+
+	model, exact := m.closestModel(id, session.Provider)
+	var note string
+	if !exact {
+		note = fmt.Sprintf("%s is unavailable, using %s", id, model.Name)
+	}
+	if model.ID == m.currentModel.ID {
+		return nil, note
+	}
+
+	provider := m.currentModel.Provider
+	m.currentModel = model
+	m.modelsState.CurrentModel = model
+
+	// Models from another provider need their own client
+	if m.apiClient == nil || model.Provider != provider {
+		return m.switchModel(model), note
+	}
+	return nil, note
+}
+
+// closestModel finds the model with the given ID, reporting true, or else
+// the model of the same provider whose ID shares the longest prefix with it
+func (m *Model) closestModel(id, provider string) (api.Model, bool) {
+	candidates := m.modelsState.AvailableModels
+	if len(candidates) == 0 {
+		candidates = m.getStaticModels()
+	}
+
+	var best api.Model
+	bestPrefix := -1
+	for _, model := range candidates {
+		if model.ID == id {
+			return model, true
+		}
+		if provider != "" && string(model.Provider) != provider {
+			continue
+		}
+		if prefix := commonPrefixLength(model.ID, id); prefix > bestPrefix {
+			best, bestPrefix = model, prefix
+		}
+	}
+
+	switch {
+	case bestPrefix >= 0:
+		return best, false
+	case m.currentModel.ID != "":
+		return m.currentModel, false
+	case len(candidates) > 0:
+		return candidates[0], false
+	}
+	return m.lookupModel(id), true
+}
+
+// commonPrefixLength counts the leading bytes a and b share
+func commonPrefixLength(a, b string) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
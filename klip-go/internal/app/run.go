@@ -0,0 +1,238 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/john/klip/internal/api"
+)
+
+const (
+	// codeRunTimeout stops a /run snippet that hasn't finished
+	codeRunTimeout = 30 * time.Second
+
+	// maxCodeRunOutput caps how much of a snippet's output is kept
+	maxCodeRunOutput = 16 * 1024
+)
+
+// CodeRun is a code block from a reply waiting for the user to confirm
+// running it
+type CodeRun struct {
+	Language string
+	Code     string
+
+	// Scroll is the first line of the code shown while it waits
+	Scroll int
+}
+
+// codeRunDoneMsg delivers what a snippet printed
+type codeRunDoneMsg struct {
+	run    CodeRun
+	output string
+	err    error
+}
+
+// codeInterpreters maps the code block languages /run accepts to the
+// command that runs a snippet
+var codeInterpreters = map[string][]string{
+	"sh":      {"sh", "-c"},
+	"shell":   {"sh", "-c"},
+	"bash":    {"bash", "-c"},
+	"zsh":     {"zsh", "-c"},
+	"python":  {"python3", "-c"},
+	"python3": {"python3", "-c"},
+	"py":      {"python3", "-c"},
+}
+
+// runnableCodeBlocks returns the fenced shell and Python blocks in content
+func runnableCodeBlocks(content string) []CodeRun {
+	var blocks []CodeRun
+	var current *CodeRun
+	var body []string
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "```") {
+			if current != nil {
+				body = append(body, line)
+			}
+			continue
+		}
+
+		if current == nil {
+			lang := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(trimmed, "```")))
+			current, body = &CodeRun{Language: lang}, nil
+			continue
+		}
+		if _, ok := codeInterpreters[current.Language]; ok {
+			current.Code = strings.Join(body, "\n")
+			blocks = append(blocks, *current)
+		}
+		current = nil
+	}
+	return blocks
+}
+
+// handleRunCommand offers to run a shell or Python block from the last
+// reply, the last one by default or the nth with /run n. Nothing runs
+// until the user confirms the code shown.
+func (m *Model) handleRunCommand(args []string) tea.Cmd {
+	if m.config == nil || !m.config.EnableCodeExecution {
+		return func() tea.Msg {
+			return statusMsg{"Running code is off; turn on Allow Running Code in /settings first", 4 * time.Second}
+		}
+	}
+
+	var blocks []CodeRun
+	for i := len(m.chatState.Messages) - 1; i >= 0; i-- {
+		if message := m.chatState.Messages[i]; message.Role == "assistant" {
+			blocks = runnableCodeBlocks(message.Content)
+			break
+		}
+	}
+	if len(blocks) == 0 {
+		return func() tea.Msg {
+			return statusMsg{"The last reply has no shell or Python code block", 3 * time.Second}
+		}
+	}
+
+	index := len(blocks) - 1
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 1 || n > len(blocks) {
+			return func() tea.Msg {
+				return statusMsg{fmt.Sprintf("Usage: /run [1-%d]", len(blocks)), 3 * time.Second}
+			}
+		}
+		index = n - 1
+	}
+
+	run := blocks[index]
+	m.chatState.CodeRun = &run
+	status := fmt.Sprintf("Review the %s code above · y run · n cancel", run.Language)
+	return func() tea.Msg {
+		return statusMsg{status, 5 * time.Second}
+	}
+}
+
+// handleCodeRunKeys answers the question raised by handleRunCommand and
+// scrolls the code shown; other keys are ignored until it is answered
+func (m *Model) handleCodeRunKeys(msg tea.KeyMsg) tea.Cmd {
+	run := *m.chatState.CodeRun
+	lines := strings.Count(run.Code, "\n") + 1
+	switch strings.ToLower(msg.String()) {
+	case "up", "k":
+		m.chatState.CodeRun.Scroll = max(run.Scroll-1, 0)
+	case "down", "j":
+		m.chatState.CodeRun.Scroll = min(run.Scroll+1, lines-1)
+	case "pgup":
+		m.chatState.CodeRun.Scroll = max(run.Scroll-10, 0)
+	case "pgdown":
+		m.chatState.CodeRun.Scroll = min(run.Scroll+10, lines-1)
+	case "y":
+		m.chatState.CodeRun = nil
+		ctx := m.ctx
+		return tea.Batch(
+			func() tea.Msg { return statusMsg{"Running " + run.Language + "…", codeRunTimeout} },
+			func() tea.Msg {
+				output, err := runCode(ctx, run)
+				return codeRunDoneMsg{run: run, output: output, err: err}
+			},
+		)
+	case "n", "esc":
+		m.chatState.CodeRun = nil
+		return func() tea.Msg {
+			return statusMsg{"Not run", 2 * time.Second}
+		}
+	}
+	return nil
+}
+
+// runCode runs a snippet in a scratch directory with a minimal environment,
+// stopping it after codeRunTimeout, and returns its combined output. This
+// limits accidents, not malice: the snippet still runs as the current user.
+func runCode(ctx context.Context, run CodeRun) (string, error) {
+	interpreter, ok := codeInterpreters[run.Language]
+	if !ok {
+		return "", fmt.Errorf("can't run %s code", run.Language)
+	}
+
+	dir, err := os.MkdirTemp("", "klip-run-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, codeRunTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, interpreter[0], append(interpreter[1:], run.Code)...)
+	killProcessGroupOnCancel(cmd)
+	cmd.Dir = dir
+	cmd.Env = []string{"PATH=" + os.Getenv("PATH"), "HOME=" + dir, "TMPDIR=" + dir, "LANG=C.UTF-8"}
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	err = cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", codeRunTimeout)
+	}
+
+	text := output.String()
+	if len(text) > maxCodeRunOutput {
+		text = text[:maxCodeRunOutput] + "\n… output truncated"
+	}
+	return text, err
+}
+
+// renderCodeRun shows the whole snippet /run is waiting to run, scrolled
+// with the arrow keys, so nothing runs unseen
+func (m *Model) renderCodeRun(height int) string {
+	run := m.chatState.CodeRun
+	header := titleStyle.Render("Run "+run.Language+" code?") + " " +
+		mutedStyle.Render("runs as you, in a scratch directory, for up to "+codeRunTimeout.String())
+	footer := warningStyle.Render("y run · n cancel") + mutedStyle.Render(" · ↑/↓ PgUp/PgDn: scroll")
+
+	lines := strings.Split(run.Code, "\n")
+	visible := max(height-4, 1)
+	start := min(run.Scroll, max(len(lines)-visible, 0))
+	end := min(start+visible, len(lines))
+
+	shown := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		shown = append(shown, mutedStyle.Render(fmt.Sprintf("%3d ", i+1))+lines[i])
+	}
+	position := ""
+	if len(lines) > visible {
+		position = mutedStyle.Render(fmt.Sprintf(" · lines %d-%d of %d", start+1, end, len(lines)))
+	}
+	return strings.Join([]string{header, "", strings.Join(shown, "\n"), "", footer + position}, "\n")
+}
+
+// applyCodeRun adds a snippet's output to the conversation as a system
+// message; requestMessages sends it to the model as context with the next
+// turn
+func (m *Model) applyCodeRun(msg codeRunDoneMsg) tea.Cmd {
+	result := "exited successfully"
+	if msg.err != nil {
+		result = msg.err.Error()
+	}
+
+	content := fmt.Sprintf("Ran %s code (%s):\n```\n%s\n```", msg.run.Language, result, strings.TrimRight(msg.output, "\n"))
+	m.chatState.AddMessage(api.Message{Role: "system", Content: content, Timestamp: time.Now()})
+
+	return func() tea.Msg {
+		return statusMsg{"Code " + result, 3 * time.Second}
+	}
+}
@@ -0,0 +1,7 @@
+package app
+
+import "os/exec"
+
+// killProcessGroupOnCancel leaves cmd as it is; Windows has no process
+// groups to kill, so only the interpreter is stopped on timeout
+func killProcessGroupOnCancel(cmd *exec.Cmd) {}
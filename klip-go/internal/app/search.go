@@ -0,0 +1,202 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/john/klip/internal/api"
+	"github.com/john/klip/internal/storage"
+)
+
+// maxSearchResults caps how many matches /search lists
+const maxSearchResults = 50
+
+// HistorySearch holds the matches of a /search across saved sessions
+type HistorySearch struct {
+	Query    string
+	Results  []storage.SearchResult
+	Selected int
+}
+
+// OpenedSession is a saved session loaded into the chat from a search,
+// with the matched message kept in view until the next send
+type OpenedSession struct {
+	ID      string
+	Title   string
+	Message int
+}
+
+// searchDoneMsg delivers the matches of a history search
+type searchDoneMsg struct {
+	query   string
+	results []storage.SearchResult
+	err     error
+}
+
+// sessionOpenedMsg delivers a saved session to show at a matched message
+type sessionOpenedMsg struct {
+	session *storage.ChatLog
+	message int
+	err     error
+}
+
+// handleSearchCommand searches the messages of all saved sessions
+func (m *Model) handleSearchCommand(args []string) tea.Cmd {
+	if len(args) == 0 {
+		return func() tea.Msg {
+			return statusMsg{"Usage: /search <query>", 2 * time.Second}
+		}
+	}
+	if m.storage == nil || m.storage.ChatLogger == nil {
+		return func() tea.Msg {
+			return statusMsg{"Chat history is not available", 3 * time.Second}
+		}
+	}
+
+	query := strings.Join(args, " ")
+	logger := m.storage.ChatLogger
+	return func() tea.Msg {
+		results, err := logger.SearchSessions(query, maxSearchResults)
+		return searchDoneMsg{query: query, results: results, err: err}
+	}
+}
+
+// applySearchResults lists the matches in place of the conversation
+func (m *Model) applySearchResults(msg searchDoneMsg) tea.Cmd {
+	if msg.err != nil {
+		return func() tea.Msg {
+			return statusMsg{fmt.Sprintf("Search failed: %v", msg.err), 5 * time.Second}
+		}
+	}
+	if len(msg.results) == 0 {
+		m.chatState.Search = nil
+		return func() tea.Msg {
+			return statusMsg{fmt.Sprintf("No messages match %q", msg.query), 3 * time.Second}
+		}
+	}
+
+	m.chatState.Search = &HistorySearch{Query: msg.query, Results: msg.results}
+	return nil
+}
+
+// handleSearchKeys moves through the search results, opening the selected
+// one on enter. It reports false for keys that should close the results
+// and reach the composer instead.
+func (m *Model) handleSearchKeys(msg tea.KeyMsg) (tea.Cmd, bool) {
+	search := m.chatState.Search
+	switch msg.String() {
+	case "up", "k":
+		if search.Selected > 0 {
+			search.Selected--
+		}
+	case "down", "j":
+		if search.Selected < len(search.Results)-1 {
+			search.Selected++
+		}
+	case "enter":
+		result := search.Results[search.Selected]
+		m.chatState.Search = nil
+		return m.openSearchResult(result), true
+	case "esc":
+		m.chatState.Search = nil
+	default:
+		m.chatState.Search = nil
+		return nil, false
+	}
+	return nil, true
+}
+
+// openSearchResult loads the session a result belongs to
+func (m *Model) openSearchResult(result storage.SearchResult) tea.Cmd {
+	logger := m.storage.ChatLogger
+	return func() tea.Msg {
+		session, err := logger.GetSession(result.SessionID)
+		return sessionOpenedMsg{session: session, message: result.MessageIndex, err: err}
+	}
+}
+
+// applyOpenedSession shows a saved session in the chat, scrolled to the
+// matched message
+func (m *Model) applyOpenedSession(msg sessionOpenedMsg) tea.Cmd {
+	if msg.err != nil {
+		return func() tea.Msg {
+			return statusMsg{fmt.Sprintf("Can't open session: %v", msg.err), 5 * time.Second}
+		}
+	}
+
+	m.loadSessionMessages(msg.session)
+	title := sessionTitle(msg.session)
+	m.chatState.OpenedSession = &OpenedSession{ID: msg.session.SessionID, Title: title, Message: msg.message}
+	return tea.Batch(
+		func() tea.Msg {
+			return statusMsg{fmt.Sprintf("Opened %s at message %d", title, msg.message+1), 3 * time.Second}
+		},
+		m.checkContextWindow(),
+	)
+}
+
+// loadSessionMessages replaces the conversation with a saved session's
+func (m *Model) loadSessionMessages(session *storage.ChatLog) {
+	m.chatState.ClearMessages()
+	for _, message := range session.Messages {
+		m.chatState.AddMessage(api.Message{
+			Role:      message.Role,
+			Content:   message.Content,
+			Timestamp: message.Timestamp,
+			Model:     message.Model,
+		})
+	}
+	m.chatState.Recovery = nil
+	m.chatState.FailedRequest = nil
+	m.chatState.Comparison = nil
+}
+
+// sessionTitle names a session by its title, or when it started
+func sessionTitle(session *storage.ChatLog) string {
+	if session.Title != "" {
+		return session.Title
+	}
+	return session.Timestamp.Format("2006-01-02 15:04")
+}
+
+// renderSearchResults lists the matches with their session, keeping the
+// selected one in view
+func (m *Model) renderSearchResults(height int) string {
+	search := m.chatState.Search
+	header := titleStyle.Render("Search") + " " +
+		mutedStyle.Render(fmt.Sprintf("%q · %d matches", search.Query, len(search.Results)))
+	footer := mutedStyle.Render("↑/↓: Navigate | Enter: Open session | Esc: Close")
+
+	// Each result takes two lines; leave room for the header and footer
+	visible := max((height-4)/2, 1)
+	start := 0
+	if search.Selected >= visible {
+		start = search.Selected - visible + 1
+	}
+	end := min(start+visible, len(search.Results))
+
+	lines := []string{header, ""}
+	for i := start; i < end; i++ {
+		result := search.Results[i]
+		title := result.SessionTitle
+		if title == "" {
+			title = result.SessionTime.Format("2006-01-02 15:04")
+		}
+
+		prefix := "  "
+		style := mutedStyle
+		if i == search.Selected {
+			prefix = "▶ "
+			style = selectedButtonStyle
+		}
+		context := fmt.Sprintf("%s · %s #%d", title, result.Message.Role, result.MessageIndex+1)
+		lines = append(lines,
+			prefix+style.Render(truncateText(context, max(m.width-4, 10))),
+			"    "+truncateText(result.Snippet, max(m.width-6, 10)),
+		)
+	}
+	lines = append(lines, "", footer)
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/john/klip/internal/storage"
+)
+
+// SecretCheck holds a message that looks like it contains secrets until the
+// user decides whether to send it
+type SecretCheck struct {
+	Content  string
+	UseCache bool
+	Kinds    []string
+
+	// Approved lets the next send of Content through unchecked
+	Approved bool
+}
+
+// secretPatterns returns the built-in secret patterns with the configured
+// ones applied, falling back to the built-ins when the config is invalid
+func (m *Model) secretPatterns() []storage.SecretPattern {
+	var custom map[string]string
+	if m.config != nil {
+		custom = m.config.SecretPatterns
+	}
+	patterns, err := storage.SecretPatterns(custom)
+	if err != nil {
+		m.logger.Warn("Ignoring secret patterns", "error", err)
+		patterns, _ = storage.SecretPatterns(nil)
+	}
+	return patterns
+}
+
+// checkSecrets holds back a message that looks like it contains secrets and
+// asks what to do with it. It returns nil when the message may be sent.
+func (m *Model) checkSecrets(content string, useCache bool) tea.Cmd {
+	if check := m.chatState.SecretCheck; check != nil && check.Approved && check.Content == content {
+		m.chatState.SecretCheck = nil
+		return nil
+	}
+	if m.config != nil && m.config.DisableSecretScan {
+		return nil
+	}
+
+	kinds := storage.DetectSecrets(content, m.secretPatterns(), m.knownSecrets()...)
+	if len(kinds) == 0 {
+		return nil
+	}
+
+	m.chatState.SecretCheck = &SecretCheck{Content: content, UseCache: useCache, Kinds: kinds}
+	status := fmt.Sprintf("Message looks like it contains %s · y send anyway · r redact and send · n cancel",
+		strings.Join(kinds, ", "))
+	return func() tea.Msg {
+		return statusMsg{status, 30 * time.Second}
+	}
+}
+
+// handleSecretCheckKeys answers the question raised by checkSecrets; other
+// keys are ignored until it is answered
+func (m *Model) handleSecretCheckKeys(msg tea.KeyMsg) tea.Cmd {
+	check := m.chatState.SecretCheck
+	switch strings.ToLower(msg.String()) {
+	case "y":
+		check.Approved = true
+		return m.sendMessage(check.Content, check.UseCache)
+	case "r":
+		m.chatState.SecretCheck = nil
+		redacted, _ := storage.RedactSecretsWith(check.Content, m.secretPatterns(), m.knownSecrets()...)
+		return m.sendMessage(redacted, check.UseCache)
+	case "n", "esc":
+		m.chatState.SecretCheck = nil
+		return func() tea.Msg {
+			return statusMsg{"Not sent", 2 * time.Second}
+		}
+	}
+	return nil
+}
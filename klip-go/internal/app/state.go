@@ -1,6 +1,7 @@
 package app
 
 import (
+	"sort"
 	"strings"
 	"time"
 
@@ -134,6 +135,74 @@ type ChatState struct {
 	HistoryIndex     int
 	WaitingForAPI    bool
 	InterruptChannel chan struct{}
+
+	// SystemPrompt overrides the configured system prompt for this session
+	SystemPrompt string
+
+	// Recovery holds a response that was cut off mid-stream, if any
+	Recovery *StreamRecovery
+
+	// LastRequest is the most recent request sent; FailedRequest is kept
+	// when it errored, for /retry, until a reply arrives or a new turn starts
+	LastRequest   *api.ChatRequest
+	FailedRequest *api.ChatRequest
+
+	// RateLimitRetries counts rate-limited attempts at the current turn
+	RateLimitRetries int
+
+	// RateLimit is the quota from the latest response headers, if any;
+	// sending is disabled until CooldownUntil after a Retry-After
+	RateLimit     *api.RateLimit
+	CooldownUntil time.Time
+
+	// Comparison holds the latest /compare answers, shown below the chat
+	Comparison *Comparison
+
+	// PendingCacheKey is the response cache key of the request in flight
+	PendingCacheKey string
+
+	// BriefNext limits the reply to the next message sent; TurnMaxTokens
+	// is the resulting limit for the current turn, or zero for none
+	BriefNext     bool
+	TurnMaxTokens int
+
+	// Search lists the matches of a /search in place of the conversation;
+	// OpenedSession is set while a saved session opened from it is shown
+	Search        *HistorySearch
+	OpenedSession *OpenedSession
+
+	// Stats is the /stats report, shown in place of the conversation
+	Stats *UsageReport
+
+	// EventLog is set while /notifications lists past notifications
+	EventLog *EventLogView
+
+	// SecretCheck is a message held back because it looks like it contains
+	// secrets, waiting for the user to send, redact or cancel it
+	SecretCheck *SecretCheck
+
+	// CodeRun is a code block /run is waiting to be confirmed before running
+	CodeRun *CodeRun
+
+	// ClearPending is set while /clear waits to be confirmed; Cleared
+	// keeps the conversation it removed, for a short while, for /undo
+	ClearPending bool
+	Cleared      *ClearedConversation
+
+	// ContextWarned is set once the context window warning was raised for
+	// the conversation, until it is trimmed back under the threshold
+	ContextWarned bool
+
+	// IdleArchived is set when the previous conversation was archived for
+	// inactivity, until the next message starts a new one
+	IdleArchived bool
+}
+
+// StreamRecovery tracks an interrupted response so it can be retried
+type StreamRecovery struct {
+	Err        error
+	Attempts   int
+	Continuing bool
 }
 
 // InputMode represents different input modes
@@ -158,11 +227,14 @@ func NewChatState() *ChatState {
 // AddMessage adds a message to the chat history
 func (cs *ChatState) AddMessage(message api.Message) {
 	cs.Messages = append(cs.Messages, message)
+	cs.IdleArchived = false
 }
 
 // ClearMessages clears all messages
 func (cs *ChatState) ClearMessages() {
 	cs.Messages = make([]api.Message, 0)
+	cs.OpenedSession = nil
+	cs.IdleArchived = false
 }
 
 // GetLastUserMessage returns the last user message
@@ -175,6 +247,58 @@ func (cs *ChatState) GetLastUserMessage() *api.Message {
 	return nil
 }
 
+// EstimateTokens approximates the token count of the conversation so far
+func (cs *ChatState) EstimateTokens() int {
+	counter := NewTokenCounter()
+	total := 0
+	for _, msg := range cs.Messages {
+		total += counter.EstimateTokens(msg.Content)
+	}
+	return total
+}
+
+// ModelSort orders the models list
+type ModelSort int
+
+const (
+	ModelSortDefault ModelSort = iota
+	ModelSortPrice
+	ModelSortProvider
+)
+
+// String returns the display name of a sort order
+func (s ModelSort) String() string {
+	switch s {
+	case ModelSortPrice:
+		return "price"
+	case ModelSortProvider:
+		return "provider"
+	default:
+		return "default"
+	}
+}
+
+// ModelInfo joins an available model with its pricing
+type ModelInfo struct {
+	api.Model
+	Pricing    storage.CostEstimate
+	HasPricing bool
+}
+
+// NewModelInfo looks up pricing for a model
+func NewModelInfo(model api.Model) ModelInfo {
+	pricing, ok := storage.LookupCostEstimate(model.ID)
+	return ModelInfo{Model: model, Pricing: pricing, HasPricing: ok}
+}
+
+// EstimateCost returns the cost of sending inputTokens of conversation to the model
+func (mi ModelInfo) EstimateCost(inputTokens int) float64 {
+	if !mi.HasPricing {
+		return 0
+	}
+	return float64(inputTokens) / 1_000_000 * mi.Pricing.Input
+}
+
 // ModelsState manages model selection state
 type ModelsState struct {
 	AvailableModels []api.Model
@@ -184,6 +308,8 @@ type ModelsState struct {
 	Error           error
 	SearchQuery     string
 	FilteredModels  []api.Model
+	SortBy          ModelSort
+	ProviderFilter  api.Provider
 }
 
 // NewModelsState creates a new models state
@@ -197,28 +323,75 @@ func NewModelsState() *ModelsState {
 // FilterModels filters models based on search query
 func (ms *ModelsState) FilterModels(query string) {
 	ms.SearchQuery = query
-	if query == "" {
-		ms.FilteredModels = ms.AvailableModels
-		return
-	}
-
-	filtered := make([]api.Model, 0)
+	filtered := make([]api.Model, 0, len(ms.AvailableModels))
 	query = strings.ToLower(query)
 
 	for _, model := range ms.AvailableModels {
-		if strings.Contains(strings.ToLower(model.Name), query) ||
+		if ms.ProviderFilter != "" && model.Provider != ms.ProviderFilter {
+			continue
+		}
+		if query == "" ||
+			strings.Contains(strings.ToLower(model.Name), query) ||
 			strings.Contains(strings.ToLower(model.ID), query) ||
 			strings.Contains(strings.ToLower(model.Provider.String()), query) {
 			filtered = append(filtered, model)
 		}
 	}
 
+	ms.sortModels(filtered)
 	ms.FilteredModels = filtered
 	if ms.SelectedIndex >= len(filtered) {
 		ms.SelectedIndex = 0
 	}
 }
 
+// sortModels orders models by the current sort, keeping API order for ties.
+// Models without known pricing sort after priced ones.
+func (ms *ModelsState) sortModels(models []api.Model) {
+	switch ms.SortBy {
+	case ModelSortPrice:
+		sort.SliceStable(models, func(i, j int) bool {
+			a, b := NewModelInfo(models[i]), NewModelInfo(models[j])
+			if a.HasPricing != b.HasPricing {
+				return a.HasPricing
+			}
+			return a.Pricing.Input < b.Pricing.Input
+		})
+	case ModelSortProvider:
+		sort.SliceStable(models, func(i, j int) bool {
+			return models[i].Provider < models[j].Provider
+		})
+	}
+}
+
+// CycleSort switches to the next sort order and reapplies it
+func (ms *ModelsState) CycleSort() {
+	ms.SortBy = (ms.SortBy + 1) % 3
+	ms.FilterModels(ms.SearchQuery)
+}
+
+// CycleProviderFilter narrows the list to the next provider, then back to all
+func (ms *ModelsState) CycleProviderFilter() {
+	providers := []api.Provider{"", api.ProviderAnthropic, api.ProviderOpenAI, api.ProviderOpenRouter}
+	for i, provider := range providers {
+		if provider == ms.ProviderFilter {
+			ms.ProviderFilter = providers[(i+1)%len(providers)]
+			break
+		}
+	}
+	ms.SelectedIndex = 0
+	ms.FilterModels(ms.SearchQuery)
+}
+
+// FilteredModelInfos returns the visible models joined with their pricing
+func (ms *ModelsState) FilteredModelInfos() []ModelInfo {
+	infos := make([]ModelInfo, len(ms.FilteredModels))
+	for i, model := range ms.FilteredModels {
+		infos[i] = NewModelInfo(model)
+	}
+	return infos
+}
+
 // GetSelectedModel returns the currently selected model
 func (ms *ModelsState) GetSelectedModel() *api.Model {
 	if len(ms.FilteredModels) == 0 || ms.SelectedIndex < 0 || ms.SelectedIndex >= len(ms.FilteredModels) {
@@ -235,6 +408,9 @@ type SettingsState struct {
 	SelectedItem    int
 	EditingValue    bool
 	TempValue       string
+
+	// Section names the settings section to open on, e.g. "providers"
+	Section string
 }
 
 // NewSettingsState creates a new settings state
@@ -387,3 +387,40 @@ func BenchmarkModelsFiltering(b *testing.B) {
 		modelsState.FilterModels(query)
 	}
 }
+
+func TestModelInfoEstimateMatchesRate(t *testing.T) {
+	info := NewModelInfo(api.Model{ID: "claude-3-5-sonnet-20241022", Name: "Claude 3.5 Sonnet", Provider: api.ProviderAnthropic})
+	assert.True(t, info.HasPricing)
+
+	tokens := 250_000
+	assert.InDelta(t, float64(tokens)*info.Pricing.Input/1_000_000, info.EstimateCost(tokens), 1e-9)
+	assert.Contains(t, formatModelPricing(info, tokens), "~$0.7500 this chat")
+
+	unknown := NewModelInfo(api.Model{ID: "not-a-real-model"})
+	assert.False(t, unknown.HasPricing)
+	assert.Equal(t, "pricing unknown", formatModelPricing(unknown, tokens))
+}
+
+func TestModelsStateSortAndProviderFilter(t *testing.T) {
+	modelsState := NewModelsState()
+	modelsState.AvailableModels = []api.Model{
+		{ID: "claude-3-opus-20240229", Name: "Claude 3 Opus", Provider: api.ProviderAnthropic},
+		{ID: "unpriced", Name: "Unpriced", Provider: api.ProviderOpenRouter},
+		{ID: "gpt-4o-mini", Name: "GPT-4o mini", Provider: api.ProviderOpenAI},
+		{ID: "claude-3-haiku-20240307", Name: "Claude 3 Haiku", Provider: api.ProviderAnthropic},
+	}
+	modelsState.FilterModels("")
+
+	modelsState.CycleSort()
+	assert.Equal(t, ModelSortPrice, modelsState.SortBy)
+	ids := make([]string, 0)
+	for _, model := range modelsState.FilteredModels {
+		ids = append(ids, model.ID)
+	}
+	assert.Equal(t, []string{"gpt-4o-mini", "claude-3-haiku-20240307", "claude-3-opus-20240229", "unpriced"}, ids)
+	assert.Equal(t, "claude-3-opus-20240229", modelsState.AvailableModels[0].ID, "sorting must not reorder the source list")
+
+	modelsState.CycleProviderFilter()
+	assert.Equal(t, api.ProviderAnthropic, modelsState.ProviderFilter)
+	assert.Len(t, modelsState.FilteredModels, 2)
+}
@@ -0,0 +1,409 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+	"github.com/john/klip/internal/api"
+	"github.com/john/klip/internal/storage"
+	"github.com/john/klip/internal/ui/styles"
+)
+
+const (
+	// defaultStatsDays is the range /stats covers without an argument
+	defaultStatsDays = 7
+
+	// topCommandCount is how many of the most used commands /stats lists
+	topCommandCount = 5
+)
+
+// UsageReport is the analytics summary /stats shows in place of the
+// conversation
+type UsageReport struct {
+	Days      int
+	Requests  int
+	Tokens    int
+	Cost      float64
+	ErrorRate float64
+
+	AvgLatency time.Duration
+	P50Latency time.Duration
+	P95Latency time.Duration
+	Histogram  []storage.LatencyBucket
+
+	// Commands counts how often each command was run, by canonical name
+	Commands map[string]int
+
+	// Hours counts the requests sent in each hour of the local day
+	Hours [24]int
+
+	// SessionID is this run's analytics session and ChatSessionID the
+	// saved conversation, to match events with stored sessions
+	SessionID     string
+	ChatSessionID string
+}
+
+// SessionSummary is what the current conversation used, as copied by
+// /stats copy
+type SessionSummary struct {
+	Model        string
+	Requests     int
+	InputTokens  int
+	OutputTokens int
+	Cost         float64
+	Duration     time.Duration
+
+	// Estimated is set when some replies reported no usage, so their
+	// tokens were estimated from the text
+	Estimated bool
+}
+
+// writeClipboard is replaced in tests to keep them off the system clipboard
+var writeClipboard = clipboard.WriteAll
+
+// usageStatsMsg delivers the usage statistics for /stats
+type usageStatsMsg struct {
+	days      int
+	stats     map[string]interface{}
+	sessionID string
+	err       error
+}
+
+// handleStatsCommand shows usage statistics for the last 7 days, or the
+// number of days given. "/stats copy" copies a summary of the current
+// session instead.
+func (m *Model) handleStatsCommand(args []string) tea.Cmd {
+	if len(args) > 0 && args[0] == "copy" {
+		return m.copySessionSummary()
+	}
+	if m.storage == nil || m.storage.AnalyticsLogger == nil {
+		return func() tea.Msg {
+			return statusMsg{"Analytics are not available", 3 * time.Second}
+		}
+	}
+
+	days := defaultStatsDays
+	if len(args) > 0 {
+		n, err := strconv.Atoi(strings.TrimSuffix(args[0], "d"))
+		if err != nil || n <= 0 {
+			return func() tea.Msg {
+				return statusMsg{"Usage: /stats [days | copy]", 2 * time.Second}
+			}
+		}
+		days = n
+	}
+
+	analytics := m.storage.AnalyticsLogger
+	return func() tea.Msg {
+		// Include events still waiting to be written
+		if err := analytics.Flush(); err != nil {
+			m.logger.Warn("Failed to flush analytics", "error", err)
+		}
+		stats, err := analytics.GetUsageStats(days)
+		return usageStatsMsg{days: days, stats: stats, sessionID: analytics.SessionID(), err: err}
+	}
+}
+
+// applyUsageStats shows the statistics in place of the conversation
+func (m *Model) applyUsageStats(msg usageStatsMsg) tea.Cmd {
+	if msg.err != nil {
+		return func() tea.Msg {
+			return statusMsg{fmt.Sprintf("Can't load statistics: %v", msg.err), 5 * time.Second}
+		}
+	}
+
+	report := newUsageReport(msg.days, msg.stats)
+	report.SessionID = msg.sessionID
+	if m.storage != nil && m.storage.ChatLogger != nil {
+		if current := m.storage.ChatLogger.GetCurrentSession(); current != nil {
+			report.ChatSessionID = current.SessionID
+		}
+	}
+	m.chatState.Stats = report
+	return nil
+}
+
+// newUsageReport reads the figures of a GetUsageStats result
+func newUsageReport(days int, stats map[string]interface{}) *UsageReport {
+	ms := func(key string) time.Duration {
+		switch value := stats[key].(type) {
+		case int64:
+			return time.Duration(value) * time.Millisecond
+		case float64:
+			return time.Duration(value * float64(time.Millisecond))
+		}
+		return 0
+	}
+
+	report := &UsageReport{
+		Days:       days,
+		AvgLatency: ms("avg_latency"),
+		P50Latency: ms("p50_latency"),
+		P95Latency: ms("p95_latency"),
+	}
+	report.Requests, _ = stats["total_requests"].(int)
+	report.Tokens, _ = stats["total_tokens"].(int)
+	report.Cost, _ = stats["total_cost"].(float64)
+	report.ErrorRate, _ = stats["error_rate"].(float64)
+	report.Histogram, _ = stats["latency_histogram"].([]storage.LatencyBucket)
+	report.Commands, _ = stats["commands_used"].(map[string]int)
+	report.Hours, _ = stats["hourly_activity"].([24]int)
+	return report
+}
+
+// handleStatsKeys closes the statistics; other keys go on to the composer
+func (m *Model) handleStatsKeys(msg tea.KeyMsg) bool {
+	m.chatState.Stats = nil
+	return msg.String() == "esc" || msg.String() == "q"
+}
+
+// copySessionSummary copies the current session's summary to the
+// clipboard as markdown
+func (m *Model) copySessionSummary() tea.Cmd {
+	summary := m.sessionSummary(time.Now())
+	if summary.Requests == 0 {
+		return func() tea.Msg {
+			return statusMsg{"No replies in this session yet", 2 * time.Second}
+		}
+	}
+
+	text := summary.Markdown()
+	return func() tea.Msg {
+		if err := writeClipboard(text); err != nil {
+			return statusMsg{fmt.Sprintf("Copy failed: %v", err), 3 * time.Second}
+		}
+		return statusMsg{"Session summary copied", 2 * time.Second}
+	}
+}
+
+// sessionSummary totals the usage the replies of the conversation reported,
+// pricing each at the model that wrote it
+func (m *Model) sessionSummary(now time.Time) SessionSummary {
+	summary := SessionSummary{Model: m.currentModel.Name}
+	if summary.Model == "" {
+		summary.Model = m.currentModel.ID
+	}
+
+	counter := NewTokenCounter()
+	var start time.Time
+	for _, msg := range m.chatState.Messages {
+		if start.IsZero() && !msg.Timestamp.IsZero() {
+			start = msg.Timestamp
+		}
+		if msg.Role != "assistant" {
+			continue
+		}
+		summary.Requests++
+
+		usage := msg.Usage
+		if usage == nil {
+			summary.OutputTokens += counter.EstimateTokens(msg.Content)
+			summary.Estimated = true
+			continue
+		}
+		summary.InputTokens += usage.TotalInputTokens()
+		summary.OutputTokens += usage.OutputTokens
+
+		modelID := msg.Model
+		if modelID == "" {
+			modelID = m.currentModel.ID
+		}
+		summary.Cost += usageCost(modelID, usage)
+	}
+	if !start.IsZero() {
+		summary.Duration = now.Sub(start)
+	}
+	return summary
+}
+
+// usageCost prices the usage a provider reported for one reply
+func usageCost(modelID string, usage *api.Usage) float64 {
+	return storage.EstimateCachedCost(modelID, usage.InputTokens,
+		usage.CacheReadInputTokens, usage.CacheCreationInputTokens, usage.OutputTokens)
+}
+
+// recordUsage adds the usage a provider reported for a reply to the
+// all-time totals; replies without reported usage are left out rather
+// than guessed at
+func (m *Model) recordUsage(modelID string, usage *api.Usage) {
+	if usage == nil || m.storage == nil || m.storage.UsageStore == nil {
+		return
+	}
+	store, logger := m.storage.UsageStore, m.logger
+	go func() {
+		tokens := int64(usage.TotalInputTokens() + usage.OutputTokens)
+		if _, err := store.Record(tokens, usageCost(modelID, usage)); err != nil {
+			logger.Error("Failed to record usage totals", "error", err)
+		}
+	}()
+}
+
+// Markdown formats the summary as a table for pasting into reports
+func (s SessionSummary) Markdown() string {
+	tokens := humanize.Comma(int64(s.InputTokens + s.OutputTokens))
+	if s.Estimated {
+		tokens = "~" + tokens
+	}
+
+	var b strings.Builder
+	b.WriteString("## Klip session summary\n\n")
+	b.WriteString("| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Model | %s |\n", s.Model)
+	fmt.Fprintf(&b, "| Requests | %d |\n", s.Requests)
+	fmt.Fprintf(&b, "| Tokens | %s (%s in · %s out) |\n", tokens,
+		humanize.Comma(int64(s.InputTokens)), humanize.Comma(int64(s.OutputTokens)))
+	fmt.Fprintf(&b, "| Cost | $%.4f |\n", s.Cost)
+	fmt.Fprintf(&b, "| Duration | %s |\n", s.Duration.Round(time.Second))
+	return b.String()
+}
+
+// renderUsageReport draws the usage figures and a response time histogram
+func (m *Model) renderUsageReport() string {
+	report := m.chatState.Stats
+	header := titleStyle.Render("Usage") + " " +
+		mutedStyle.Render(fmt.Sprintf("last %d days", report.Days))
+
+	lines := []string{header}
+	if report.SessionID != "" {
+		ids := "Analytics session " + report.SessionID
+		if report.ChatSessionID != "" {
+			ids += " · chat session " + report.ChatSessionID
+		}
+		lines = append(lines, mutedStyle.Render(ids))
+	}
+	lines = append(lines,
+		"",
+		fmt.Sprintf("Requests      %d", report.Requests),
+		fmt.Sprintf("Tokens        %d", report.Tokens),
+		fmt.Sprintf("Cost          $%.4f", report.Cost),
+		fmt.Sprintf("Error rate    %.1f%%", report.ErrorRate*100),
+		fmt.Sprintf("Latency       avg %s · p50 %s · p95 %s",
+			formatLatency(report.AvgLatency), formatLatency(report.P50Latency), formatLatency(report.P95Latency)),
+		"",
+		subtitleStyle.Render("Response times"),
+	)
+	lines = append(lines, renderLatencyHistogram(report.Histogram, max(m.width-24, 10))...)
+	lines = append(lines, "", subtitleStyle.Render("Activity by hour"))
+	lines = append(lines, m.renderHourlyActivity(report.Hours)...)
+	lines = append(lines, "", subtitleStyle.Render("Top commands"))
+	lines = append(lines, m.renderTopCommands(report.Commands)...)
+	lines = append(lines, "", mutedStyle.Render("Esc: Close"))
+	return strings.Join(lines, "\n")
+}
+
+// renderTopCommands lists the most used commands with their counts, then
+// the commands never run in the range so they can be discovered
+func (m *Model) renderTopCommands(counts map[string]int) []string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] > counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	var lines []string
+	if len(names) == 0 {
+		lines = append(lines, mutedStyle.Render("  No commands run yet"))
+	}
+	for _, name := range names[:min(len(names), topCommandCount)] {
+		lines = append(lines, fmt.Sprintf("  /%-14s %d", name, counts[name]))
+	}
+
+	var unused []string
+	for _, command := range NewCommandRegistry().List() {
+		if counts[command.Name] == 0 {
+			unused = append(unused, "/"+command.Name)
+		}
+	}
+	if len(unused) > 0 {
+		sort.Strings(unused)
+		lines = append(lines, mutedStyle.Render(truncateText("  Not used yet: "+strings.Join(unused, " "), max(m.width-2, 20))))
+	}
+	return lines
+}
+
+// renderLatencyHistogram draws one horizontal bar per bucket, the busiest
+// bucket filling width
+func renderLatencyHistogram(buckets []storage.LatencyBucket, width int) []string {
+	peak := 0
+	for _, bucket := range buckets {
+		peak = max(peak, bucket.Count)
+	}
+
+	barStyle := lipgloss.NewStyle().Foreground(primaryColor)
+	labelStyle := lipgloss.NewStyle().Width(6)
+	lines := make([]string, len(buckets))
+	for i, bucket := range buckets {
+		bar := 0
+		if peak > 0 {
+			bar = bucket.Count * width / peak
+			// Keep any responses visible however few
+			if bucket.Count > 0 && bar == 0 {
+				bar = 1
+			}
+		}
+		lines[i] = "  " + labelStyle.Render(bucket.Label) + " " +
+			barStyle.Render(strings.Repeat("█", bar)) +
+			mutedStyle.Render(fmt.Sprintf(" %d", bucket.Count))
+	}
+	return lines
+}
+
+// renderHourlyActivity draws requests per hour of the day as a sparkline
+// over an hour axis, noting the busiest hour
+func (m *Model) renderHourlyActivity(hours [24]int) []string {
+	charset := styles.UnicodeCharacterSet
+	if m.styler != nil {
+		charset = m.styler.GetOptimalCharset()
+	}
+	levels := charset.Sparkline
+
+	peak, busiest := 0, 0
+	for hour, count := range hours {
+		if count > peak {
+			peak, busiest = count, hour
+		}
+	}
+	if peak == 0 {
+		return []string{mutedStyle.Render("  No requests yet")}
+	}
+
+	barStyle := lipgloss.NewStyle().Foreground(primaryColor)
+	var line strings.Builder
+	for _, count := range hours {
+		// Any activity shows above the empty level
+		level := 0
+		if count > 0 {
+			level = max(1, count*(len(levels)-1)/peak)
+		}
+		line.WriteString(levels[level])
+	}
+
+	return []string{
+		"  " + barStyle.Render(line.String()) + mutedStyle.Render(fmt.Sprintf("  busiest %02d:00 (%d)", busiest, peak)),
+		"  " + mutedStyle.Render("0     6     12    18  23"),
+	}
+}
+
+// formatLatency shows a latency to a tenth of a second, or in
+// milliseconds below one second
+func formatLatency(latency time.Duration) string {
+	if latency <= 0 {
+		return "–"
+	}
+	if latency < time.Second {
+		return fmt.Sprintf("%dms", latency.Milliseconds())
+	}
+	return fmt.Sprintf("%.1fs", latency.Seconds())
+}
@@ -22,6 +22,8 @@ type StreamingState struct {
 	BytesReceived int64
 	Error         error
 	Interrupted   bool
+	// Usage is what the provider reported on the final chunk, if anything
+	Usage *api.Usage
 }
 
 // NewStreamingState creates a new streaming state
@@ -42,6 +44,7 @@ func (ss *StreamingState) Start() {
 	ss.BytesReceived = 0
 	ss.Error = nil
 	ss.Interrupted = false
+	ss.Usage = nil
 }
 
 // AddChunk adds a chunk to the streaming buffer
@@ -183,6 +186,9 @@ func (sm *StreamingManager) handleStreamingResponse(ctx context.Context, chunkCh
 
 			// Add chunk to buffer
 			sm.currentStream.AddChunk(chunk.Content)
+			if chunk.Usage != nil {
+				sm.currentStream.Usage = chunk.Usage
+			}
 
 			// Send progress update
 			progress := StreamProgressMsg{
@@ -239,12 +245,15 @@ func (sm *StreamingManager) finishStreaming() {
 	}
 
 	content := sm.currentStream.Complete()
+	usage := sm.currentStream.Usage
 
 	// Create assistant message
 	assistantMsg := api.Message{
 		Role:      "assistant",
 		Content:   content,
 		Timestamp: time.Now(),
+		Model:     sm.model.currentModel.ID,
+		Usage:     usage,
 	}
 
 	// Add to chat history
@@ -252,11 +261,15 @@ func (sm *StreamingManager) finishStreaming() {
 
 	// Log the message (convert to storage format)
 	if sm.model.storage != nil && sm.model.storage.ChatLogger != nil {
+		model := sm.model.currentModel
 		go func() {
 			storageMsg := storage.Message{
 				Role:      assistantMsg.Role,
 				Content:   assistantMsg.Content,
 				Timestamp: assistantMsg.Timestamp,
+				Model:     model.ID,
+				Provider:  string(model.Provider),
+				Tokens:    storageTokens(usage),
 			}
 			if err := sm.model.storage.ChatLogger.LogMessage(storageMsg); err != nil {
 				sm.model.logger.Error("Failed to log assistant message", "error", err)
@@ -264,20 +277,8 @@ func (sm *StreamingManager) finishStreaming() {
 		}()
 	}
 
-	// Log analytics
-	if sm.model.storage != nil && sm.model.storage.AnalyticsLogger != nil {
-		go func() {
-			metrics := api.ResponseMetrics{
-				LatencyMs:      sm.currentStream.GetDuration().Milliseconds(),
-				TokensInput:    0,                            // TODO: Calculate from request
-				TokensOutput:   len(strings.Fields(content)), // Rough estimate
-				ResponseLength: len(content),
-			}
-
-			// TODO: Implement API response logging when method is available
-			_ = metrics // Avoid unused variable warning
-		}()
-	}
+	// Persist cumulative usage totals
+	sm.model.recordUsage(assistantMsg.Model, usage)
 
 	// Send completion message
 	tea.Batch(func() tea.Msg {
@@ -308,6 +309,95 @@ func (sm *StreamingManager) InterruptStreaming() {
 	})()
 }
 
+// maxStreamRecoveryAttempts bounds how often one response is retried after interruptions
+const maxStreamRecoveryAttempts = 3
+
+// handleInterruptedStream keeps the partial content of a stream that failed
+// mid-response and offers to retry from where it stopped
+func (m *Model) handleInterruptedStream(err error) tea.Cmd {
+	partial := m.chatState.StreamBuffer
+	if m.continuingResponse() {
+		last := len(m.chatState.Messages) - 1
+		m.chatState.Messages[last].Content += partial
+	} else {
+		m.chatState.AddMessage(api.Message{
+			Role:      "assistant",
+			Content:   partial,
+			Timestamp: time.Now(),
+		})
+	}
+	m.chatState.StreamBuffer = ""
+	m.chatState.IsStreaming = false
+	m.chatState.WaitingForAPI = false
+
+	m.logger.Warn("Interrupted", "error", err, "received", len(partial))
+
+	attempts := 0
+	if m.chatState.Recovery != nil {
+		attempts = m.chatState.Recovery.Attempts
+	}
+	if !api.IsRetryable(err) || attempts >= maxStreamRecoveryAttempts {
+		m.chatState.Recovery = nil
+		m.setError(err, "Response interrupted", true)
+		return nil
+	}
+
+	m.chatState.Recovery = &StreamRecovery{Err: err, Attempts: attempts}
+	return func() tea.Msg {
+		return statusMsg{"Response interrupted · /retry to retry from here", 30 * time.Second}
+	}
+}
+
+// resumeInterruptedStream re-requests an interrupted response. Providers that
+// support continuation pick up after the partial message; for the others the
+// partial message is dropped and the last user turn is sent again.
+func (m *Model) resumeInterruptedStream() tea.Cmd {
+	recovery := m.chatState.Recovery
+	recovery.Attempts++
+	recovery.Continuing = m.currentModel.Provider.SupportsContinuation()
+
+	last := len(m.chatState.Messages) - 1
+	if !recovery.Continuing && last >= 0 && m.chatState.Messages[last].Role == "assistant" {
+		m.chatState.Messages = m.chatState.Messages[:last]
+	}
+
+	var request *api.ChatRequest
+	if recovery.Continuing {
+		request = m.continuationRequest()
+	} else {
+		request = m.buildChatRequest()
+	}
+
+	m.chatState.WaitingForAPI = true
+
+	status := fmt.Sprintf("Retrying (attempt %d/%d)...", recovery.Attempts, maxStreamRecoveryAttempts)
+	return tea.Batch(
+		func() tea.Msg { return statusMsg{status, 3 * time.Second} },
+		func() tea.Msg { return apiRequestMsg{request} },
+	)
+}
+
+// continuationRequest prepares a request ending with the partial assistant
+// message. Continuation requests may not end with whitespace, so the stored
+// partial is trimmed too, keeping it in step with the text the
+// continuation is joined onto.
+func (m *Model) continuationRequest() *api.ChatRequest {
+	if last := len(m.chatState.Messages) - 1; last >= 0 {
+		tail := &m.chatState.Messages[last]
+		tail.Content = strings.TrimRight(tail.Content, " \t\n")
+	}
+	return m.buildChatRequest()
+}
+
+// continuingResponse reports whether the active stream extends the last message
+func (m *Model) continuingResponse() bool {
+	recovery := m.chatState.Recovery
+	if recovery == nil || !recovery.Continuing || len(m.chatState.Messages) == 0 {
+		return false
+	}
+	return m.chatState.Messages[len(m.chatState.Messages)-1].Role == "assistant"
+}
+
 // IsStreaming returns true if currently streaming
 func (sm *StreamingManager) IsStreaming() bool {
 	return sm.currentStream != nil && sm.currentStream.Active
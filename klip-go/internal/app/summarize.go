@@ -0,0 +1,219 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/john/klip/internal/api"
+)
+
+// summarizeTimeout bounds the request that condenses older turns
+const summarizeTimeout = time.Minute
+
+// defaultSummaryKeepRecent is how many of the latest messages /summarize
+// leaves untouched when no count is given
+const defaultSummaryKeepRecent = 4
+
+// summaryPrefix starts the system message that stands in for summarized turns
+const summaryPrefix = "Summary of the earlier conversation:\n"
+
+// summaryInstructions asks the summary model for a note the conversation
+// can continue from
+const summaryInstructions = "Summarize the conversation below for the assistant that continues it. " +
+	"Keep names, decisions, open questions, code identifiers and anything the user asked to remember. " +
+	"Write plain prose, at most a few paragraphs."
+
+// summaryModels are the inexpensive models used to summarize per provider
+// when Config.SummaryModel is unset
+var summaryModels = map[api.Provider]string{
+	api.ProviderAnthropic:  "claude-3-5-haiku-20241022",
+	api.ProviderOpenAI:     "gpt-4o-mini",
+	api.ProviderOpenRouter: "openai/gpt-4o-mini",
+}
+
+// Summarizer condenses messages into a note the conversation can continue from
+type Summarizer interface {
+	Summarize(ctx context.Context, messages []api.Message) (string, error)
+}
+
+// modelSummarizer asks a model for the summary
+type modelSummarizer struct {
+	client api.ProviderInterface
+	model  api.Model
+}
+
+// Summarize sends the messages as a transcript and returns the model's summary
+func (s modelSummarizer) Summarize(ctx context.Context, messages []api.Message) (string, error) {
+	var transcript strings.Builder
+	for _, msg := range messages {
+		fmt.Fprintf(&transcript, "%s: %s\n\n", msg.Role, msg.Content)
+	}
+
+	response, err := s.client.Chat(ctx, &api.ChatRequest{
+		Model: s.model,
+		Messages: []api.Message{
+			{Role: "system", Content: summaryInstructions, Timestamp: time.Now()},
+			{Role: "user", Content: transcript.String(), Timestamp: time.Now()},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(response.Content), nil
+}
+
+// SummarizeOlder replaces all but the last keepRecent messages with a
+// single system message summarizing them. The cut moves forward to the
+// next user message so turns stay whole. It returns the new messages and
+// how many were summarized, which is zero when there is nothing to condense.
+func SummarizeOlder(ctx context.Context, summarizer Summarizer, messages []api.Message, keepRecent int) ([]api.Message, int, error) {
+	cut := len(messages) - keepRecent
+	if cut < 0 {
+		cut = 0
+	}
+	for cut < len(messages) && messages[cut].Role != "user" {
+		cut++
+	}
+	if cut == 0 || (cut == 1 && isSummary(messages[0])) {
+		return messages, 0, nil
+	}
+
+	summary, err := summarizer.Summarize(ctx, messages[:cut])
+	if err != nil {
+		return messages, 0, err
+	}
+
+	condensed := make([]api.Message, 0, len(messages)-cut+1)
+	condensed = append(condensed, api.Message{
+		Role:      "system",
+		Content:   summaryPrefix + summary,
+		Timestamp: time.Now(),
+	})
+	return append(condensed, messages[cut:]...), cut, nil
+}
+
+// isSummary reports whether msg is a summary left by SummarizeOlder
+func isSummary(msg api.Message) bool {
+	return msg.Role == "system" && strings.HasPrefix(msg.Content, summaryPrefix)
+}
+
+// summarizeDoneMsg delivers condensed messages. before is the conversation
+// length they were made from; pending is a message to send afterwards.
+type summarizeDoneMsg struct {
+	messages   []api.Message
+	summarized int
+	before     int
+	pending    string
+	err        error
+}
+
+// handleSummarizeCommand condenses all but the latest messages
+func (m *Model) handleSummarizeCommand(args []string) tea.Cmd {
+	keep := defaultSummaryKeepRecent
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n < 0 {
+			return func() tea.Msg {
+				return statusMsg{"Usage: /summarize [messages to keep]", 2 * time.Second}
+			}
+		}
+		keep = n
+	}
+	return m.summarizeOlder(keep, "")
+}
+
+// summarizeOlder condenses older turns in the background, then sends
+// pending if it isn't empty
+func (m *Model) summarizeOlder(keep int, pending string) tea.Cmd {
+	summarizer := m.summarizer
+	if summarizer == nil {
+		model := m.summaryModel()
+		client := m.apiClient
+		if client == nil || model.Provider != m.currentModel.Provider {
+			var err error
+			if client, err = m.newProviderClient(model); err != nil {
+				return func() tea.Msg {
+					return statusMsg{fmt.Sprintf("Can't summarize with %s: %v", model.Name, err), 5 * time.Second}
+				}
+			}
+		}
+		summarizer = modelSummarizer{client: client, model: model}
+	}
+
+	messages := append([]api.Message(nil), m.chatState.Messages...)
+	m.chatState.WaitingForAPI = true
+	ctx := m.ctx
+	return tea.Batch(
+		func() tea.Msg {
+			return statusMsg{"Summarizing earlier messages...", 3 * time.Second}
+		},
+		func() tea.Msg {
+			ctx, cancel := context.WithTimeout(ctx, summarizeTimeout)
+			defer cancel()
+
+			condensed, summarized, err := SummarizeOlder(ctx, summarizer, messages, keep)
+			return summarizeDoneMsg{
+				messages:   condensed,
+				summarized: summarized,
+				before:     len(messages),
+				pending:    pending,
+				err:        err,
+			}
+		},
+	)
+}
+
+// applySummary swaps in the condensed conversation unless it changed
+// while the summary was written
+func (m *Model) applySummary(msg summarizeDoneMsg) tea.Cmd {
+	m.chatState.WaitingForAPI = false
+	if msg.err != nil {
+		return func() tea.Msg {
+			return statusMsg{fmt.Sprintf("Summarizing failed: %v", msg.err), 5 * time.Second}
+		}
+	}
+	if len(m.chatState.Messages) != msg.before {
+		return func() tea.Msg {
+			return statusMsg{"Conversation changed while summarizing, try again", 3 * time.Second}
+		}
+	}
+	if msg.summarized == 0 {
+		return func() tea.Msg {
+			return statusMsg{"Nothing old enough to summarize", 3 * time.Second}
+		}
+	}
+
+	m.chatState.Messages = msg.messages
+	m.checkContextWindow()
+
+	status := func() tea.Msg {
+		return statusMsg{fmt.Sprintf("Summarized %d earlier messages into a system note", msg.summarized), 5 * time.Second}
+	}
+	if msg.pending != "" {
+		return tea.Batch(status, m.sendChatMessage(msg.pending))
+	}
+	return status
+}
+
+// summaryModel returns Config.SummaryModel, or the inexpensive model of the
+// current provider
+func (m *Model) summaryModel() api.Model {
+	if m.config != nil {
+		if id := strings.TrimSpace(m.config.SummaryModel); id != "" {
+			return m.lookupModel(id)
+		}
+	}
+	if id, ok := summaryModels[m.currentModel.Provider]; ok {
+		return m.lookupModel(id)
+	}
+	return m.currentModel
+}
+
+// autoSummarize reports whether overflowing sends summarize older turns
+func (m *Model) autoSummarize() bool {
+	return m.config != nil && m.config.AutoSummarize
+}
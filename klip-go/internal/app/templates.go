@@ -0,0 +1,137 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/john/klip/internal/storage"
+)
+
+// handleTemplateCommand starts a fresh conversation from a saved template:
+// its system prompt and model apply to the session and its starter message
+// is left in the input to edit or send
+func (m *Model) handleTemplateCommand(args []string) tea.Cmd {
+	if len(args) == 0 {
+		return m.handleTemplatesCommand(nil)
+	}
+
+	template, err := storage.LoadTemplate(strings.Join(args, " "))
+	if err != nil {
+		return func() tea.Msg {
+			return statusMsg{fmt.Sprintf("Can't load template: %v", err), 3 * time.Second}
+		}
+	}
+	return m.applyTemplate(template)
+}
+
+// applyTemplate clears the conversation and sets it up from template
+func (m *Model) applyTemplate(template *storage.Template) tea.Cmd {
+	m.clearConversation()
+	m.chatState.SystemPrompt = template.SystemPrompt
+	m.chatState.Recovery = nil
+	m.chatState.FailedRequest = nil
+	m.chatState.Comparison = nil
+	m.chatState.ContextWarned = false
+	m.setCurrentInput(template.StarterMessage)
+
+	var cmds []tea.Cmd
+	if id := strings.TrimSpace(template.Model); id != "" && id != m.currentModel.ID {
+		model := m.lookupModel(id)
+		provider := m.currentModel.Provider
+		m.currentModel = model
+		m.modelsState.CurrentModel = model
+
+		// Models from another provider need their own client
+		if m.apiClient == nil || model.Provider != provider {
+			cmds = append(cmds, m.switchModel(model))
+		}
+	}
+
+	status := fmt.Sprintf("Started from template %s · %s", template.Name, m.currentModel.Name)
+	cmds = append(cmds, func() tea.Msg {
+		return statusMsg{status, 3 * time.Second}
+	})
+	return tea.Batch(cmds...)
+}
+
+// handleTemplatesCommand lists templates, saves the current session setup
+// as one, or deletes one
+func (m *Model) handleTemplatesCommand(args []string) tea.Cmd {
+	usage := func() tea.Msg {
+		return statusMsg{"Usage: /templates [save <name> [starter message] | delete <name>]", 3 * time.Second}
+	}
+
+	if len(args) == 0 {
+		templates, err := storage.ListTemplates()
+		if err != nil {
+			return func() tea.Msg {
+				return statusMsg{fmt.Sprintf("Can't list templates: %v", err), 3 * time.Second}
+			}
+		}
+		if len(templates) == 0 {
+			return func() tea.Msg {
+				return statusMsg{"No templates yet · /templates save <name> keeps the current system prompt and model", 5 * time.Second}
+			}
+		}
+
+		names := make([]string, len(templates))
+		for i, template := range templates {
+			names[i] = template.Name
+			if template.Description != "" {
+				names[i] += " (" + template.Description + ")"
+			}
+		}
+		return func() tea.Msg {
+			return statusMsg{"Templates: " + strings.Join(names, ", ") + " · /template <name>", 8 * time.Second}
+		}
+	}
+
+	switch strings.ToLower(args[0]) {
+	case "save":
+		if len(args) < 2 {
+			return usage
+		}
+		template := &storage.Template{
+			Name:           args[1],
+			SystemPrompt:   m.systemPromptTemplate(),
+			Model:          m.currentModel.ID,
+			StarterMessage: strings.Join(args[2:], " "),
+		}
+		if err := storage.SaveTemplate(template); err != nil {
+			return func() tea.Msg {
+				return statusMsg{fmt.Sprintf("Can't save template: %v", err), 3 * time.Second}
+			}
+		}
+		return func() tea.Msg {
+			return statusMsg{fmt.Sprintf("Saved template %s", template.Name), 3 * time.Second}
+		}
+
+	case "delete", "rm":
+		if len(args) < 2 {
+			return usage
+		}
+		if err := storage.DeleteTemplate(args[1]); err != nil {
+			return func() tea.Msg {
+				return statusMsg{fmt.Sprintf("Can't delete template: %v", err), 3 * time.Second}
+			}
+		}
+		return func() tea.Msg {
+			return statusMsg{fmt.Sprintf("Deleted template %s", args[1]), 3 * time.Second}
+		}
+	}
+	return usage
+}
+
+// systemPromptTemplate returns the session or configured system prompt with
+// its template variables left unexpanded
+func (m *Model) systemPromptTemplate() string {
+	if m.chatState.SystemPrompt != "" {
+		return m.chatState.SystemPrompt
+	}
+	if m.config != nil {
+		return m.config.SystemPrompt
+	}
+	return ""
+}
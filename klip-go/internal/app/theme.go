@@ -0,0 +1,114 @@
+package app
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/john/klip/internal/ui/styles"
+)
+
+func init() {
+	buildStyles()
+}
+
+// applyTheme draws the app with the current theme's colors, as adapted to
+// the terminal and the accessibility preferences
+func applyTheme() {
+	theme := styles.GetCurrentTheme()
+	if theme == nil {
+		return
+	}
+
+	colors := theme.Colors
+	primaryColor = colors.Primary
+	secondaryColor = colors.Success
+	accentColor = colors.Accent
+	errorColor = colors.Error
+	warningColor = colors.Warning
+
+	textPrimary = colors.Text
+	textSecondary = colors.TextSubtle
+	textMuted = colors.TextMuted
+
+	bgPrimary = colors.Background
+	bgSecondary = colors.Surface
+	bgAccent = colors.SurfaceAlt
+
+	borderPrimary = colors.Border
+	borderSecondary = colors.BorderSubtle
+
+	buildStyles()
+}
+
+// buildStyles rebuilds the styles drawn with the color palette
+func buildStyles() {
+	baseStyle = lipgloss.NewStyle().
+		Foreground(textPrimary).
+		Background(bgPrimary)
+
+	titleStyle = lipgloss.NewStyle().
+		Foreground(primaryColor).
+		Bold(true).
+		MarginBottom(1)
+
+	subtitleStyle = lipgloss.NewStyle().
+		Foreground(textSecondary).
+		Italic(true)
+
+	errorStyle = lipgloss.NewStyle().
+		Foreground(errorColor).
+		Bold(true)
+
+	warningStyle = lipgloss.NewStyle().
+		Foreground(warningColor)
+
+	successStyle = lipgloss.NewStyle().
+		Foreground(secondaryColor)
+
+	mutedStyle = lipgloss.NewStyle().
+		Foreground(textMuted)
+
+	inputStyle = lipgloss.NewStyle().
+		Foreground(textPrimary).
+		Background(bgSecondary).
+		Padding(0, 1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(borderPrimary)
+
+	focusedInputStyle = inputStyle.
+		BorderForeground(primaryColor)
+
+	buttonStyle = lipgloss.NewStyle().
+		Foreground(textPrimary).
+		Background(bgAccent).
+		Padding(0, 2).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(borderPrimary)
+
+	selectedButtonStyle = buttonStyle.
+		Foreground(bgPrimary).
+		Background(primaryColor).
+		BorderForeground(primaryColor)
+
+	compareColumnStyle = lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder(), false, true, false, false).
+		BorderForeground(borderPrimary).
+		MarginRight(1)
+
+	paletteStyle = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(primaryColor).
+		Padding(0, 1)
+
+	paletteQueryStyle = lipgloss.NewStyle().
+		Foreground(textPrimary).
+		Bold(true)
+
+	paletteCursorStyle = lipgloss.NewStyle().
+		Reverse(true)
+
+	paletteItemStyle = lipgloss.NewStyle().
+		Foreground(textSecondary)
+
+	paletteSelectedStyle = lipgloss.NewStyle().
+		Foreground(primaryColor).
+		Bold(true)
+}
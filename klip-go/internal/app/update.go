@@ -2,6 +2,7 @@ package app
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 	"unicode"
@@ -27,8 +28,12 @@ type (
 	apiRequestMsg     struct{ request *api.ChatRequest }
 	apiResponseMsg    struct{ response *api.ChatResponse }
 	apiStreamChunkMsg struct{ chunk string }
-	apiStreamDoneMsg  struct{}
 	apiErrorMsg       struct{ error }
+	apiStreamDoneMsg  struct {
+		finishReason string
+		rateLimit    *api.RateLimit
+		usage        *api.Usage
+	}
 
 	// Model management messages
 	modelsLoadStartMsg   struct{}
@@ -46,6 +51,19 @@ type (
 	historyLoadSuccessMsg struct{ sessions []storage.ChatSession }
 	historyLoadErrorMsg   struct{ error }
 
+	// Bulk export messages
+	exportProgressMsg struct {
+		done, total int
+		title       string
+		updates     <-chan tea.Msg
+	}
+	exportAllDoneMsg struct {
+		dir   string
+		count int
+		zip   bool
+		err   error
+	}
+
 	// Status and animation messages
 	statusMsg struct {
 		message  string
@@ -66,23 +84,25 @@ type (
 // Update handles all messages and updates the model state
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	m.lastUpdate = time.Now()
-	m.updateAnimationFrame()
+
+	// Only animation ticks may reuse the previous frame
+	m.skipRender = false
 
 	var cmds []tea.Cmd
 
 	// Handle global messages first
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
+		m.resize(msg.Width, msg.Height)
 		m.ready = true
 		return m, nil
 
 	case tickMsg:
+		m.updateAnimationFrame()
+		m.skipRender = m.shouldSkipFrame()
+
 		if m.shouldAnimate() {
-			cmds = append(cmds, tea.Tick(time.Millisecond*100, func(t time.Time) tea.Msg {
-				return tickMsg{t}
-			}))
+			cmds = append(cmds, m.animationTick())
 		}
 
 		// Clear expired status messages
@@ -96,7 +116,38 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case statusMsg:
 		m.setStatusMessage(msg.message, msg.duration)
 
+	case NotificationLogMsg:
+		m.handleNotificationLog(msg)
+
+	case connectionStateMsg:
+		m.connectionState = msg.state
+		// A working connection outdates a failed health check
+		if _, tested := m.providerHealth[string(m.currentModel.Provider)]; tested && msg.state == api.ConnectionConnected {
+			m.providerHealth[string(m.currentModel.Provider)] = true
+		}
+		cmds = append(cmds, waitForConnectionState(m.connectionStates))
+
+	case cooldownTickMsg:
+		cmds = append(cmds, m.cooldownTick())
+
+	case idleCheckMsg:
+		cmds = append(cmds, m.checkIdle(msg.time), m.idleTick())
+
+	case exportProgressMsg:
+		m.trackExportProgress(msg)
+		cmds = append(cmds, waitForExport(msg.updates))
+
+	case exportAllDoneMsg:
+		m.finishExportAll(msg)
+
 	case tea.KeyMsg:
+		m.lastActivity = time.Now()
+
+		// The command palette takes every key while it's open
+		if m.palette != nil {
+			return m, m.handlePaletteKeys(msg)
+		}
+
 		// Handle global key bindings
 		cmd := m.handleGlobalKeys(msg)
 		if cmd != nil {
@@ -146,6 +197,16 @@ func (m *Model) handleGlobalKeys(msg tea.KeyMsg) tea.Cmd {
 			return tea.Quit
 		}
 
+	case "ctrl+k":
+		// In the composer, ctrl+k keeps deleting to the end of the line
+		// while there is text after the cursor
+		if m.GetCurrentState() != StateChat || m.cursorPos >= len(m.inputBuffer) {
+			m.openPalette()
+		}
+
+	case "ctrl+o":
+		m.openModelPicker()
+
 	case "f1":
 		if m.GetCurrentState() != StateHelp {
 			m.TransitionTo(StateHelp)
@@ -174,6 +235,12 @@ func (m *Model) handleGlobalKeys(msg tea.KeyMsg) tea.Cmd {
 			m.stateManager.Back()
 		}
 
+	case "f9":
+		return m.toggleHighContrast()
+
+	case "f11":
+		return m.toggleFocusMode()
+
 	case "f12":
 		m.showDebugInfo = !m.showDebugInfo
 
@@ -217,9 +284,17 @@ func (m *Model) handleInitializingState(msg tea.Msg) tea.Cmd {
 	case initCompleteMsg:
 		// Transition to chat state after successful initialization
 		m.TransitionTo(StateChat)
-		return func() tea.Msg {
-			return statusMsg{"Klip is ready!", 3 * time.Second}
+		ready := statusMsg{"Klip is ready!", 3 * time.Second}
+		if notice := m.configRecoveryNotice(); notice != "" {
+			ready = statusMsg{notice, 10 * time.Second}
 		}
+		return tea.Batch(
+			func() tea.Msg {
+				return ready
+			},
+			m.checkProviderHealth(),
+			m.idleTick(),
+		)
 	case initErrorMsg:
 		return nil // Error handling is done elsewhere
 	}
@@ -241,22 +316,40 @@ func (m *Model) handleChatState(msg tea.Msg) tea.Cmd {
 		m.chatState.StreamBuffer += msg.chunk
 		m.chatState.IsStreaming = true
 	case apiStreamDoneMsg:
+		// A resumed response is finalized together with the partial message it continues
+		if m.continuingResponse() {
+			last := len(m.chatState.Messages) - 1
+			m.chatState.StreamBuffer = m.chatState.Messages[last].Content + m.chatState.StreamBuffer
+			m.chatState.Messages = m.chatState.Messages[:last]
+		}
+		m.chatState.Recovery = nil
+		m.chatState.FailedRequest = nil
+
 		// Finalize the streaming response
 		if m.chatState.StreamBuffer != "" {
 			assistantMsg := api.Message{
 				Role:      "assistant",
 				Content:   m.chatState.StreamBuffer,
 				Timestamp: time.Now(),
+				Model:     m.currentModel.ID,
+				Usage:     msg.usage,
+				Truncated: msg.finishReason == api.FinishReasonLength,
 			}
 			m.chatState.AddMessage(assistantMsg)
+			m.cacheResponse(assistantMsg)
+			m.recordUsage(assistantMsg.Model, assistantMsg.Usage)
 
 			// Log the message (convert to storage format)
 			if m.storage != nil && m.storage.ChatLogger != nil {
+				model := m.currentModel
 				go func() {
 					storageMsg := storage.Message{
 						Role:      assistantMsg.Role,
 						Content:   assistantMsg.Content,
 						Timestamp: assistantMsg.Timestamp,
+						Model:     model.ID,
+						Provider:  string(model.Provider),
+						Tokens:    storageTokens(assistantMsg.Usage),
 					}
 					if err := m.storage.ChatLogger.LogMessage(storageMsg); err != nil {
 						m.logger.Error("Failed to log assistant message", "error", err)
@@ -267,9 +360,25 @@ func (m *Model) handleChatState(msg tea.Msg) tea.Cmd {
 		m.chatState.IsStreaming = false
 		m.chatState.StreamBuffer = ""
 		m.chatState.WaitingForAPI = false
+		cooldownCmd := m.recordRateLimit(msg.rateLimit)
+		if msg.finishReason == api.FinishReasonLength {
+			return tea.Batch(m.truncatedStatus(), m.checkContextWindow(), cooldownCmd)
+		}
+		return tea.Batch(m.checkContextWindow(), cooldownCmd)
 	case apiErrorMsg:
+		if m.chatState.IsStreaming && m.chatState.StreamBuffer != "" {
+			return m.handleInterruptedStream(msg.error)
+		}
+		if cmd := m.handleRateLimit(msg.error); cmd != nil {
+			m.chatState.IsStreaming = false
+			return cmd
+		}
 		m.chatState.IsStreaming = false
 		m.chatState.WaitingForAPI = false
+		m.chatState.FailedRequest = m.chatState.LastRequest
+		if classified := api.ClassifyError(msg.error); classified.Kind == api.ErrorAuth {
+			return m.reportAuthError(classified)
+		}
 		m.setError(msg.error, "API request failed", true)
 	}
 	return nil
@@ -277,6 +386,27 @@ func (m *Model) handleChatState(msg tea.Msg) tea.Cmd {
 
 // handleChatKeys handles key input in chat state
 func (m *Model) handleChatKeys(msg tea.KeyMsg) tea.Cmd {
+	if m.chatState.SecretCheck != nil && !m.chatState.SecretCheck.Approved {
+		return m.handleSecretCheckKeys(msg)
+	}
+	if m.chatState.CodeRun != nil {
+		return m.handleCodeRunKeys(msg)
+	}
+	if m.chatState.ClearPending {
+		return m.handleClearKeys(msg)
+	}
+	if m.chatState.Search != nil {
+		if cmd, handled := m.handleSearchKeys(msg); handled {
+			return cmd
+		}
+	}
+	if m.chatState.Stats != nil && m.handleStatsKeys(msg) {
+		return nil
+	}
+	if m.chatState.EventLog != nil && m.handleEventLogKeys(msg) {
+		return nil
+	}
+
 	switch msg.String() {
 	case "enter":
 		if m.chatState.WaitingForAPI {
@@ -337,13 +467,19 @@ func (m *Model) handleChatKeys(msg tea.KeyMsg) tea.Cmd {
 		m.cursorPos = 0
 
 	case "ctrl+k":
-		// Clear from cursor to end
-		m.inputBuffer = m.inputBuffer[:m.cursorPos]
+		// Clear from cursor to end, unless the palette just opened
+		if m.palette == nil {
+			m.inputBuffer = m.inputBuffer[:m.cursorPos]
+		}
 
 	case "ctrl+l":
 		// Clear screen (clear chat)
 		return m.executeCommand("/clear")
 
+	case "ctrl+z":
+		// Restore a conversation just cleared
+		return m.undoClear(time.Now())
+
 	default:
 		// Insert character
 		if len(msg.Runes) > 0 && unicode.IsPrint(msg.Runes[0]) {
@@ -392,6 +528,12 @@ func (m *Model) handleModelsKeys(msg tea.KeyMsg) tea.Cmd {
 			return m.switchToModel(*selectedModel)
 		}
 
+	case "tab":
+		m.modelsState.CycleSort()
+
+	case "ctrl+f":
+		m.modelsState.CycleProviderFilter()
+
 	case "backspace":
 		if len(m.modelsState.SearchQuery) > 0 {
 			m.modelsState.SearchQuery = m.modelsState.SearchQuery[:len(m.modelsState.SearchQuery)-1]
@@ -450,10 +592,19 @@ func (m *Model) handleErrorState(msg tea.Msg) tea.Cmd {
 		case "r":
 			// Retry
 			if m.recoverFromError() {
+				if m.chatState.FailedRequest != nil {
+					return m.handleRetryCommand(nil)
+				}
 				return func() tea.Msg {
 					return statusMsg{"Retrying...", 2 * time.Second}
 				}
 			}
+		case "s":
+			// Auth errors are fixed in the Providers settings
+			if m.errorState != nil && api.ClassifyError(m.errorState.Error).Kind == api.ErrorAuth {
+				m.errorState = nil
+				return m.ExecuteCommand("/settings providers")
+			}
 		case "enter", "esc":
 			// Try to go back to previous state
 			if m.errorState != nil {
@@ -512,6 +663,8 @@ func (m *Model) handleAPIMessages(msg tea.Msg) tea.Cmd {
 	switch msg := msg.(type) {
 	case apiRequestMsg:
 		// Handle API request
+		m.chatState.PendingCacheKey = m.responseCacheKey(msg.request)
+		m.chatState.LastRequest = msg.request
 		return m.performAPIRequest(msg.request)
 
 	case apiResponseMsg:
@@ -521,22 +674,49 @@ func (m *Model) handleAPIMessages(msg tea.Msg) tea.Cmd {
 				Role:      "assistant",
 				Content:   msg.response.Content,
 				Timestamp: time.Now(),
+				Model:     m.currentModel.ID,
+				Usage:     msg.response.Usage,
+				Truncated: msg.response.FinishReason == api.FinishReasonLength,
 			}
+
+			// A continuation is finalized together with the reply it extends
+			if m.continuingResponse() {
+				last := len(m.chatState.Messages) - 1
+				assistantMsg.Content = m.chatState.Messages[last].Content + assistantMsg.Content
+				m.chatState.Messages = m.chatState.Messages[:last]
+			}
+			m.chatState.Recovery = nil
+			m.chatState.FailedRequest = nil
+
 			m.chatState.AddMessage(assistantMsg)
+			m.cacheResponse(assistantMsg)
+			m.recordUsage(assistantMsg.Model, assistantMsg.Usage)
+			contextCmd := tea.Batch(m.checkContextWindow(), m.recordRateLimit(msg.response.RateLimit))
 
 			// Log the message (convert to storage format)
 			if m.storage != nil && m.storage.ChatLogger != nil {
+				provider := string(m.currentModel.Provider)
 				go func() {
 					storageMsg := storage.Message{
 						Role:      assistantMsg.Role,
 						Content:   assistantMsg.Content,
 						Timestamp: assistantMsg.Timestamp,
+						Model:     assistantMsg.Model,
+						Provider:  provider,
+						Tokens:    storageTokens(assistantMsg.Usage),
 					}
 					if err := m.storage.ChatLogger.LogMessage(storageMsg); err != nil {
 						m.logger.Error("Failed to log assistant message", "error", err)
 					}
 				}()
 			}
+
+			if assistantMsg.Truncated {
+				m.chatState.WaitingForAPI = false
+				return tea.Batch(m.truncatedStatus(), contextCmd)
+			}
+			m.chatState.WaitingForAPI = false
+			return contextCmd
 		}
 		m.chatState.WaitingForAPI = false
 
@@ -544,7 +724,40 @@ func (m *Model) handleAPIMessages(msg tea.Msg) tea.Cmd {
 		m.modelsState.Loading = true
 		return m.loadAvailableModels()
 
+	case modelFallbackMsg:
+		return m.applyModelFallback(msg)
+
+	case summarizeDoneMsg:
+		return m.applySummary(msg)
+
+	case searchDoneMsg:
+		return m.applySearchResults(msg)
+
+	case sessionOpenedMsg:
+		return m.applyOpenedSession(msg)
+
+	case sessionResumedMsg:
+		return m.applyResumedSession(msg)
+
+	case codeRunDoneMsg:
+		return m.applyCodeRun(msg)
+
+	case profileSwitchedMsg:
+		return m.applyProfileSwitch(msg)
+
+	case usageStatsMsg:
+		return m.applyUsageStats(msg)
+
+	case ProviderHealthMsg:
+		return m.reportProviderHealth(msg)
+
+	case compareDoneMsg:
+		m.chatState.WaitingForAPI = false
+		m.chatState.Comparison = msg.comparison
+		return nil
+
 	case modelSwitchMsg:
+		m.recordModelSwitch(m.currentModel, msg.model)
 		m.currentModel = msg.model
 		m.modelsState.CurrentModel = msg.model
 		return func() tea.Msg {
@@ -579,6 +792,37 @@ func (m *Model) deleteWordBackward() {
 
 // sendChatMessage sends a chat message to the API
 func (m *Model) sendChatMessage(content string) tea.Cmd {
+	return m.sendMessage(content, true)
+}
+
+// sendMessage adds a user message and requests the reply, answering from
+// the response cache when useCache is set and it holds one
+func (m *Model) sendMessage(content string, useCache bool) tea.Cmd {
+	// Ask before anything that looks like a secret leaves the machine
+	if cmd := m.checkSecrets(content, useCache); cmd != nil {
+		return cmd
+	}
+
+	// Keep the draft while the provider asked us to wait
+	if wait := m.cooldownRemaining(); wait > 0 {
+		return func() tea.Msg {
+			return statusMsg{"Rate limited, " + formatRetryIn(wait), 2 * time.Second}
+		}
+	}
+
+	// Keep the draft when it can't fit in the context window, or send it
+	// once older turns are summarized
+	if status, exceeds := m.exceedsContextWindow(content); exceeds {
+		if m.autoSummarize() {
+			m.inputBuffer = ""
+			m.cursorPos = 0
+			return m.summarizeOlder(defaultSummaryKeepRecent, content)
+		}
+		return func() tea.Msg {
+			return statusMsg{status, 5 * time.Second}
+		}
+	}
+
 	// Create user message
 	userMsg := api.Message{
 		Role:      "user",
@@ -588,14 +832,18 @@ func (m *Model) sendChatMessage(content string) tea.Cmd {
 
 	// Add to chat history
 	m.chatState.AddMessage(userMsg)
+	budgetCmd := m.applyTokenBudget()
 
 	// Log the user message (convert to storage format)
 	if m.storage != nil && m.storage.ChatLogger != nil {
+		model := m.currentModel
 		go func() {
 			storageMsg := storage.Message{
 				Role:      userMsg.Role,
 				Content:   userMsg.Content,
 				Timestamp: userMsg.Timestamp,
+				Model:     model.ID,
+				Provider:  string(model.Provider),
 			}
 			if err := m.storage.ChatLogger.LogMessage(storageMsg); err != nil {
 				m.logger.Error("Failed to log user message", "error", err)
@@ -607,19 +855,130 @@ func (m *Model) sendChatMessage(content string) tea.Cmd {
 	m.inputBuffer = ""
 	m.cursorPos = 0
 
-	// Create API request
-	request := &api.ChatRequest{
+	// A new turn abandons any interrupted or failed response
+	m.chatState.Recovery = nil
+	m.chatState.FailedRequest = nil
+	m.chatState.OpenedSession = nil
+	m.chatState.RateLimitRetries = 0
+	m.chatState.Comparison = nil
+
+	// /brief applies to this turn only
+	m.chatState.TurnMaxTokens = 0
+	if m.chatState.BriefNext {
+		m.chatState.BriefNext = false
+		m.chatState.TurnMaxTokens = m.briefMaxTokens()
+	}
+
+	request := m.buildChatRequest()
+	if useCache {
+		if cmd := m.replyFromCache(request); cmd != nil {
+			return tea.Batch(cmd, m.checkContextWindow(), budgetCmd)
+		}
+	}
+	m.chatState.WaitingForAPI = true
+
+	return tea.Batch(
+		func() tea.Msg { return apiRequestMsg{request} },
+		m.checkContextWindow(),
+		budgetCmd,
+	)
+}
+
+// buildChatRequest creates a streaming request for the current conversation
+func (m *Model) buildChatRequest() *api.ChatRequest {
+	params := m.modelParameters()
+	maxTokens := requestMaxTokens(params)
+	if limit := m.chatState.TurnMaxTokens; limit > 0 && (maxTokens == 0 || limit < maxTokens) {
+		maxTokens = limit
+	}
+	return &api.ChatRequest{
 		Model:           m.currentModel,
-		Messages:        m.chatState.Messages,
+		Messages:        m.requestMessages(),
+		MaxTokens:       maxTokens,
+		Temperature:     params.Temperature,
 		EnableWebSearch: m.webSearchEnabled,
+		PromptCaching:   m.config != nil && m.config.EnablePromptCaching,
 		Stream:          true,
 	}
+}
 
-	m.chatState.WaitingForAPI = true
+// systemPrompt returns the active system prompt with template variables expanded
+func (m *Model) systemPrompt() string {
+	prompt := m.chatState.SystemPrompt
+	if prompt == "" && m.config != nil {
+		prompt = m.config.SystemPrompt
+	}
+	if strings.TrimSpace(prompt) == "" {
+		return ""
+	}
 
-	return func() tea.Msg {
-		return apiRequestMsg{request}
+	return expandPromptVariables(prompt, m.currentModel, time.Now())
+}
+
+// requestMessages returns the conversation to send, prefixed by the system
+// prompt. A summary of older turns joins the system prompt, since some
+// providers accept only one system message; other system messages, like
+// /run output, go as user context so they can't replace it.
+func (m *Model) requestMessages() []api.Message {
+	prompt := m.systemPrompt()
+	conversation := m.chatState.Messages
+	if len(conversation) > 0 && isSummary(conversation[0]) {
+		prompt = strings.TrimSpace(prompt + "\n\n" + conversation[0].Content)
+		conversation = conversation[1:]
+	}
+	if slices.ContainsFunc(conversation, func(msg api.Message) bool { return msg.Role == "system" }) {
+		conversation = slices.Clone(conversation)
+		for i := range conversation {
+			if conversation[i].Role == "system" {
+				conversation[i].Role = "user"
+			}
+		}
 	}
+	if prompt == "" {
+		return conversation
+	}
+
+	messages := make([]api.Message, 0, len(conversation)+1)
+	messages = append(messages, api.Message{
+		Role:      "system",
+		Content:   prompt,
+		Timestamp: time.Now(),
+	})
+	return append(messages, conversation...)
+}
+
+// modelParameters returns the configured parameters for the current model,
+// falling back to provider defaults if they are out of range for it
+func (m *Model) modelParameters() storage.ModelParameters {
+	params := m.config.ParametersFor(m.currentModel.ID)
+	if err := api.ValidateParameters(m.currentModel, params.Temperature, params.MaxTokens); err != nil {
+		m.logger.Warn("Ignoring invalid model parameters", "model", m.currentModel.ID, "error", err)
+		return storage.ModelParameters{}
+	}
+	return params
+}
+
+// requestMaxTokens returns the configured output limit, or zero for the
+// provider default
+func requestMaxTokens(params storage.ModelParameters) int {
+	if params.MaxTokens == nil {
+		return 0
+	}
+	return *params.MaxTokens
+}
+
+// expandPromptVariables substitutes {date}, {time} and {model} in a prompt
+func expandPromptVariables(prompt string, model api.Model, now time.Time) string {
+	modelName := model.Name
+	if modelName == "" {
+		modelName = model.ID
+	}
+
+	return strings.NewReplacer(
+		"{date}", now.Format("2006-01-02"),
+		"{time}", now.Format("15:04"),
+		"{model}", modelName,
+	).Replace(prompt)
 }
 
 // executeCommand executes a slash command
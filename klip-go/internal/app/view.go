@@ -7,9 +7,11 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/john/klip/internal/api"
+	"github.com/john/klip/internal/storage"
 )
 
-// Color palette for consistent theming
+// Color palette for consistent theming; applyTheme replaces it with that
+// of the current theme
 var (
 	// Primary colors
 	primaryColor   = lipgloss.Color("#7C3AED") // Purple
@@ -33,55 +35,19 @@ var (
 	borderSecondary = lipgloss.Color("#6B7280") // Light gray
 )
 
-// Base styles
+// Base styles, built from the colors above by buildStyles
 var (
-	baseStyle = lipgloss.NewStyle().
-			Foreground(textPrimary).
-			Background(bgPrimary)
-
-	titleStyle = lipgloss.NewStyle().
-			Foreground(primaryColor).
-			Bold(true).
-			MarginBottom(1)
-
-	subtitleStyle = lipgloss.NewStyle().
-			Foreground(textSecondary).
-			Italic(true)
-
-	errorStyle = lipgloss.NewStyle().
-			Foreground(errorColor).
-			Bold(true)
-
-	warningStyle = lipgloss.NewStyle().
-			Foreground(warningColor)
-
-	successStyle = lipgloss.NewStyle().
-			Foreground(secondaryColor)
-
-	mutedStyle = lipgloss.NewStyle().
-			Foreground(textMuted)
-
-	inputStyle = lipgloss.NewStyle().
-			Foreground(textPrimary).
-			Background(bgSecondary).
-			Padding(0, 1).
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(borderPrimary)
-
-	focusedInputStyle = inputStyle.Copy().
-				BorderForeground(primaryColor)
-
-	buttonStyle = lipgloss.NewStyle().
-			Foreground(textPrimary).
-			Background(bgAccent).
-			Padding(0, 2).
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(borderPrimary)
-
-	selectedButtonStyle = buttonStyle.Copy().
-				Foreground(bgPrimary).
-				Background(primaryColor).
-				BorderForeground(primaryColor)
+	baseStyle           lipgloss.Style
+	titleStyle          lipgloss.Style
+	subtitleStyle       lipgloss.Style
+	errorStyle          lipgloss.Style
+	warningStyle        lipgloss.Style
+	successStyle        lipgloss.Style
+	mutedStyle          lipgloss.Style
+	inputStyle          lipgloss.Style
+	focusedInputStyle   lipgloss.Style
+	buttonStyle         lipgloss.Style
+	selectedButtonStyle lipgloss.Style
 )
 
 // View renders the current view based on the application state
@@ -90,24 +56,33 @@ func (m *Model) View() string {
 		return m.renderLoading("Initializing...")
 	}
 
+	// Reuse the last frame when the styler drops this animation tick
+	if m.skipRender && m.lastView != "" {
+		return m.lastView
+	}
+
 	// Create the main container
-	content := m.renderStateView()
+	view := m.renderStateView()
+	if m.palette != nil {
+		view = m.renderPalette()
+	}
 
-	// Add status bar at the bottom
-	statusBar := m.renderStatusBar()
+	// Add status bar at the bottom, unless focus mode hides it
+	if !m.focusMode {
+		view = lipgloss.JoinVertical(
+			lipgloss.Left,
+			view,
+			m.renderStatusBar(),
+		)
+	}
 
 	// Combine everything
 	mainStyle := baseStyle.Copy().
 		Width(m.width).
 		Height(m.height)
 
-	view := lipgloss.JoinVertical(
-		lipgloss.Left,
-		content,
-		statusBar,
-	)
-
-	return mainStyle.Render(view)
+	m.lastView = mainStyle.Render(view)
+	return m.lastView
 }
 
 // renderStateView renders the view for the current state
@@ -189,9 +164,23 @@ func (m *Model) renderOnboardingView() string {
 // renderChatView renders the main chat interface
 func (m *Model) renderChatView() string {
 	contentHeight := m.height - 4 // Reserve space for input and status bar
-
-	// Chat messages area
-	messagesView := m.renderMessages(contentHeight - 3)
+	if m.focusMode {
+		contentHeight = m.height - 3
+	}
+
+	// Chat messages area, or the matches of a history search or /stats
+	messagesView := ""
+	if m.chatState.CodeRun != nil {
+		messagesView = m.renderCodeRun(contentHeight - 3)
+	} else if m.chatState.Search != nil {
+		messagesView = m.renderSearchResults(contentHeight - 3)
+	} else if m.chatState.Stats != nil {
+		messagesView = m.renderUsageReport()
+	} else if m.chatState.EventLog != nil {
+		messagesView = m.renderEventLog(contentHeight - 3)
+	} else {
+		messagesView = m.renderMessages(contentHeight - 3)
+	}
 
 	// Input area
 	inputView := m.renderInputArea()
@@ -205,19 +194,34 @@ func (m *Model) renderChatView() string {
 
 // renderMessages renders the chat messages
 func (m *Model) renderMessages(height int) string {
-	if len(m.chatState.Messages) == 0 {
+	if len(m.chatState.Messages) == 0 && m.chatState.Comparison == nil {
 		welcome := titleStyle.Render("Klip Chat") + "\n\n" +
 			"Welcome! Start chatting with AI or type /help for commands.\n" +
 			fmt.Sprintf("Current model: %s", successStyle.Render(m.currentModel.Name)) + "\n\n" +
 			mutedStyle.Render("Commands: /help, /model, /clear, /history")
+		if m.chatState.IdleArchived {
+			welcome += "\n\n" + mutedStyle.Render("Previous conversation archived due to inactivity · /resume to continue it")
+		}
 
 		return m.centerContent(welcome)
 	}
 
 	var messageViews []string
 
-	for _, msg := range m.chatState.Messages {
+	// A session opened from a search is shown from the matched message
+	focus := -1
+	if opened := m.chatState.OpenedSession; opened != nil && opened.Message < len(m.chatState.Messages) {
+		focus = opened.Message
+	}
+	focusLine := 0
+
+	for i, msg := range m.chatState.Messages {
 		messageView := m.renderSingleMessage(msg)
+		if i == focus {
+			messageView = selectedButtonStyle.Render("▶") + " " + messageView
+		} else if i < focus {
+			focusLine += strings.Count(messageView, "\n") + 1
+		}
 		messageViews = append(messageViews, messageView)
 	}
 
@@ -232,11 +236,26 @@ func (m *Model) renderMessages(height int) string {
 		messageViews = append(messageViews, messageView)
 	}
 
-	content := strings.Join(messageViews, "\n\n")
+	if m.chatState.Comparison != nil {
+		messageViews = append(messageViews, m.renderComparison(m.chatState.Comparison))
+	}
+
+	// Narrow terminals drop the blank line between messages
+	separator := "\n\n"
+	if m.layout.CompactMode {
+		separator = "\n"
+	}
+	content := strings.Join(messageViews, separator)
+	if focus > 0 {
+		focusLine += focus * (strings.Count(separator, "\n") - 1)
+	}
 
-	// Scroll to bottom if content is too long
+	// Scroll to the focused message, or to the bottom, if content is too long
 	lines := strings.Split(content, "\n")
-	if len(lines) > height {
+	if focus >= 0 && len(lines) > height {
+		start := min(focusLine, len(lines)-height)
+		content = strings.Join(lines[start:start+height], "\n")
+	} else if len(lines) > height {
 		lines = lines[len(lines)-height:]
 		content = strings.Join(lines, "\n")
 	}
@@ -269,9 +288,15 @@ func (m *Model) renderSingleMessage(msg api.Message) string {
 
 	// Header line
 	header := fmt.Sprintf("%s %s:", roleStyle.Render(rolePrefix), timestamp)
+	if msg.Cached {
+		header += " " + mutedStyle.Render("(cached)")
+	}
 
 	// Message content (word wrap)
 	content := m.wrapText(msg.Content, m.width-4)
+	if msg.Truncated {
+		content += "\n" + mutedStyle.Render(truncatedMarker)
+	}
 
 	return header + "\n" + content
 }
@@ -285,6 +310,15 @@ func (m *Model) renderInputArea() string {
 	if m.chatState.WaitingForAPI {
 		prompt = "Thinking..."
 		style = warningStyle
+	} else if m.chatState.SecretCheck != nil {
+		prompt = "Send secret? y/r/n"
+		style = errorStyle
+	} else if m.chatState.CodeRun != nil {
+		prompt = "Run code? y/n"
+		style = warningStyle
+	} else if m.chatState.ClearPending {
+		prompt = "Clear chat? y/n"
+		style = warningStyle
 	} else {
 		mode := m.getInputMode()
 		switch mode {
@@ -296,6 +330,9 @@ func (m *Model) renderInputArea() string {
 			style = lipgloss.NewStyle().Foreground(primaryColor)
 		default:
 			prompt = "Message"
+			if m.chatState.BriefNext {
+				prompt = "Brief"
+			}
 			style = successStyle
 		}
 	}
@@ -345,30 +382,63 @@ func (m *Model) renderModelsView() string {
 		searchBar = fmt.Sprintf("Search: %s\n\n", inputStyle.Render(m.modelsState.SearchQuery))
 	}
 
+	// Sort, filter and the conversation size the estimates are based on
+	tokens := m.chatState.EstimateTokens()
+	provider := "all"
+	if m.modelsState.ProviderFilter != "" {
+		provider = m.modelsState.ProviderFilter.String()
+	}
+	summary := mutedStyle.Render(fmt.Sprintf("Sort: %s | Provider: %s | Conversation: ~%d tokens",
+		m.modelsState.SortBy, provider, tokens)) + "\n\n"
+
+	infos := m.modelsState.FilteredModelInfos()
+	nameWidth := 0
+	for _, info := range infos {
+		if w := lipgloss.Width(modelDisplayName(info.Model)); w > nameWidth {
+			nameWidth = w
+		}
+	}
+
 	// Model list
 	var modelItems []string
-	for i, model := range m.modelsState.FilteredModels {
+	for i, info := range infos {
 		prefix := "  "
 		style := lipgloss.NewStyle()
 
 		if i == m.modelsState.SelectedIndex {
 			prefix = "▶ "
 			style = selectedButtonStyle
-		} else if model.ID == m.currentModel.ID {
+		} else if info.ID == m.currentModel.ID {
 			prefix = "✓ "
 			style = successStyle
 		}
 
-		name := fmt.Sprintf("%s (%s)", model.Name, model.Provider)
-		item := fmt.Sprintf("%s%s", prefix, style.Render(name))
+		name := modelDisplayName(info.Model)
+		name += strings.Repeat(" ", nameWidth-lipgloss.Width(name))
+		item := fmt.Sprintf("%s%s  %s", prefix, style.Render(name), mutedStyle.Render(formatModelPricing(info, tokens)))
 		modelItems = append(modelItems, item)
 	}
 
 	modelList := strings.Join(modelItems, "\n")
 
-	footer := "\n\n" + mutedStyle.Render("↑/↓: Navigate | Enter: Select | /: Search | Esc: Back")
+	footer := "\n\n" + mutedStyle.Render("↑/↓: Navigate | Enter: Select | /: Search | Tab: Sort | Ctrl+F: Provider | Esc: Back")
 
-	return header + searchBar + modelList + footer
+	return header + searchBar + summary + modelList + footer
+}
+
+// modelDisplayName renders a model as "Name (provider)"
+func modelDisplayName(model api.Model) string {
+	return fmt.Sprintf("%s (%s)", model.Name, model.Provider)
+}
+
+// formatModelPricing shows per-1M-token rates and what sending the current
+// conversation would cost
+func formatModelPricing(info ModelInfo, tokens int) string {
+	if !info.HasPricing {
+		return "pricing unknown"
+	}
+	return fmt.Sprintf("$%.2f in / $%.2f out per 1M | ~$%.4f this chat",
+		info.Pricing.Input, info.Pricing.Output, info.EstimateCost(tokens))
 }
 
 // renderSettingsView renders the settings view
@@ -417,6 +487,7 @@ func (m *Model) renderHelpView() string {
 		"  /model    - Switch AI model",
 		"  /models   - List all models",
 		"  /clear    - Clear chat history",
+		"  /focus    - Hide the status bar",
 		"  /history  - View chat history",
 		"  /settings - Open settings",
 		"  /quit     - Exit application",
@@ -426,9 +497,14 @@ func (m *Model) renderHelpView() string {
 		"  F2        - Model selection",
 		"  F3        - Settings",
 		"  F4        - History",
+		"  F9        - High contrast",
+		"  F11       - Focus mode",
+		"  Ctrl+K    - Command palette",
+		"  Ctrl+O    - Switch model",
 		"  F12       - Debug info",
 		"  Ctrl+C    - Interrupt/Quit",
 		"  Ctrl+L    - Clear screen",
+		"  Ctrl+Z    - Undo clear",
 		"  ↑/↓       - Input history",
 		"",
 		"💡 Tips:",
@@ -459,6 +535,9 @@ func (m *Model) renderErrorView() string {
 	}
 
 	var actions []string
+	if api.ClassifyError(m.errorState.Error).Kind == api.ErrorAuth {
+		actions = append(actions, "Press 's' to open Providers settings")
+	}
 	if m.errorState.Recoverable {
 		actions = append(actions, "Press 'r' to retry")
 	}
@@ -481,9 +560,38 @@ func (m *Model) renderStatusBar() string {
 		fmt.Sprintf("State: %s", m.GetCurrentState().String()),
 		fmt.Sprintf("Model: %s", m.currentModel.Name),
 	}
+	if profile := storage.ActiveProfile(); profile != storage.DefaultProfile {
+		leftItems = append(leftItems, "Profile: "+profile)
+	}
+	if connection := m.renderConnectionState(); connection != "" {
+		leftItems = append(leftItems, connection)
+	}
+	if health := m.renderProviderHealth(); health != "" {
+		leftItems = append(leftItems, health)
+	}
 
 	rightItems := []string{}
 
+	// Bulk export progress
+	if m.exportProgress != nil && m.exportProgress.IsActive() {
+		rightItems = append(rightItems, fmt.Sprintf("Exporting %d%%", m.exportProgress.GetProgressPercent()))
+	}
+
+	// Waiting out a provider's Retry-After
+	if cooldown := m.renderCooldown(); cooldown != "" {
+		rightItems = append(rightItems, cooldown)
+	}
+
+	// Context window filling up
+	if usage := m.renderContextUsage(); usage != "" {
+		rightItems = append(rightItems, usage)
+	}
+
+	// What the message being typed would cost
+	if hint := m.renderCostHint(); hint != "" {
+		rightItems = append(rightItems, hint)
+	}
+
 	// Add status message if active
 	if m.hasActiveStatusMessage() {
 		rightItems = append(rightItems, m.statusMessage)
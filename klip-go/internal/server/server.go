@@ -0,0 +1,327 @@
+// Package server exposes klip's chat pipeline over a small local HTTP API,
+// for scripts and editors that want to use it without the terminal UI
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/john/klip/internal/api"
+	"github.com/john/klip/internal/api/providers"
+	"github.com/john/klip/internal/storage"
+)
+
+// DefaultAddr keeps the server on the loopback interface unless told
+// otherwise, since it spends the user's API keys
+const DefaultAddr = "127.0.0.1:8787"
+
+// maxRequestBody caps the size of a chat request
+const maxRequestBody = 8 << 20
+
+// ProviderFactory creates the provider client that serves a model
+type ProviderFactory func(model api.Model) (api.ProviderInterface, error)
+
+// ChatRequest is the body of POST /chat. Model is a model ID; Provider is
+// only needed for models klip doesn't know.
+type ChatRequest struct {
+	Model       string        `json:"model"`
+	Provider    string        `json:"provider,omitempty"`
+	Messages    []api.Message `json:"messages"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Temperature *float64      `json:"temperature,omitempty"`
+	Stream      bool          `json:"stream,omitempty"`
+}
+
+// doneEvent ends a streamed reply
+type doneEvent struct {
+	FinishReason string     `json:"finish_reason,omitempty"`
+	Usage        *api.Usage `json:"usage,omitempty"`
+}
+
+// errorBody reports a failed request
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+// Server answers chat requests through the same clients, retries and
+// analytics as the terminal UI
+type Server struct {
+	newProvider ProviderFactory
+	analytics   *storage.AnalyticsLogger
+	token       string
+	logger      *log.Logger
+
+	// remote accepts requests addressed to any host, once ListenAndServe
+	// is bound beyond loopback with a token
+	remote bool
+}
+
+// New creates a Server. When token is set, requests must send it as a
+// bearer token.
+func New(newProvider ProviderFactory, analytics *storage.AnalyticsLogger, token string) *Server {
+	return &Server{
+		newProvider: newProvider,
+		analytics:   analytics,
+		token:       token,
+		logger:      log.New(os.Stderr),
+	}
+}
+
+// Handler returns the routes the server answers
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/chat", s.handleChat)
+	return mux
+}
+
+// ListenAndServe serves on addr until ctx is canceled. An address beyond
+// loopback needs a token, since anyone who can reach it could otherwise
+// spend the user's API keys.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid address %s: %w", addr, err)
+	}
+	if !isLoopback(host) {
+		if s.token == "" {
+			return fmt.Errorf("refusing to serve on %s without a token; set -token or KLIP_SERVE_TOKEN", addr)
+		}
+		s.remote = true
+	}
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- httpServer.ListenAndServe()
+	}()
+	s.logger.Info("Serving klip API", "addr", addr, "token", s.token != "")
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return httpServer.Shutdown(shutdownCtx)
+	}
+}
+
+// handleChat sends the conversation to the model, streaming the reply as
+// server-sent events when asked
+func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeError(w, http.StatusMethodNotAllowed, "use POST")
+		return
+	}
+	// Only local clients may call a loopback server, so a web page can't
+	// reach it through DNS rebinding
+	if !s.remote && !isLoopback(requestHost(r)) {
+		writeError(w, http.StatusForbidden, "host not allowed")
+		return
+	}
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "missing or wrong token")
+		return
+	}
+	// Requiring JSON keeps browsers from sending chats cross-origin as
+	// simple requests
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+		return
+	}
+
+	var body ChatRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody)).Decode(&body); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	if len(body.Messages) == 0 {
+		writeError(w, http.StatusBadRequest, "messages is required")
+		return
+	}
+	model, err := resolveModel(body.Model, body.Provider)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	provider, err := s.newProvider(model)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+	client, err := api.NewClient(provider, s.analytics)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	req := &api.ChatRequest{
+		Model:       model,
+		Messages:    body.Messages,
+		MaxTokens:   body.MaxTokens,
+		Temperature: body.Temperature,
+		Stream:      body.Stream,
+	}
+	if body.Stream {
+		s.streamChat(r.Context(), w, client, req)
+		return
+	}
+
+	response, err := client.Chat(r.Context(), req)
+	if err != nil {
+		writeError(w, statusFor(err), api.ClassifyError(err).Message)
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// streamChat relays the reply as it arrives: a "message" event per chunk,
+// then "done" with the finish reason and usage, or "error"
+func (s *Server) streamChat(ctx context.Context, w http.ResponseWriter, client *api.Client, req *api.ChatRequest) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	chunks, errs := client.ChatStream(ctx, req)
+	for chunk := range chunks {
+		if chunk.Done {
+			writeEvent(w, "done", doneEvent{FinishReason: chunk.FinishReason, Usage: chunk.Usage})
+		} else if chunk.Content != "" {
+			writeEvent(w, "message", map[string]string{"content": chunk.Content})
+		}
+		flusher.Flush()
+	}
+	if err := <-errs; err != nil {
+		writeEvent(w, "error", errorBody{api.ClassifyError(err).Message})
+		flusher.Flush()
+	}
+}
+
+// authorized checks the bearer token, when one is required
+func (s *Server) authorized(r *http.Request) bool {
+	if s.token == "" {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1
+}
+
+// requestHost returns the host a request was addressed to, without its port
+func requestHost(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.Host); err == nil {
+		return host
+	}
+	return r.Host
+}
+
+// isLoopback reports whether host names the local machine; an empty host
+// listens on every interface, so it doesn't
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.IsLoopback()
+}
+
+// resolveModel finds a known model by ID, or describes an unknown one by
+// its provider
+func resolveModel(id, provider string) (api.Model, error) {
+	if id == "" {
+		return api.Model{}, errors.New("model is required")
+	}
+	if model, ok := api.PredefinedModels[id]; ok {
+		return model, nil
+	}
+	if provider == "" {
+		return api.Model{}, fmt.Errorf("unknown model %s; set provider to use it", id)
+	}
+	return api.Model{ID: id, Name: id, Provider: api.Provider(provider)}, nil
+}
+
+// statusFor maps a failed provider request to the status to answer with
+func statusFor(err error) int {
+	switch api.ClassifyError(err).Kind {
+	case api.ErrorAuth:
+		return http.StatusUnauthorized
+	case api.ErrorRateLimit:
+		return http.StatusTooManyRequests
+	case api.ErrorBadRequest:
+		return http.StatusBadRequest
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// StorageProviders creates providers from the keys in store, routed through
+// the proxy and base URLs in config, like the terminal UI does
+func StorageProviders(store *storage.Storage, config *storage.Config) ProviderFactory {
+	return func(model api.Model) (api.ProviderInterface, error) {
+		apiKey, err := store.KeyStore.GetKey(string(model.Provider))
+		if err != nil || apiKey == "" {
+			return nil, fmt.Errorf("no API key found for provider %s", model.Provider)
+		}
+
+		httpClient := api.NewHTTPClient(120*time.Second, api.ProxySettingsFromConfig(config))
+		provider, err := providers.NewProvider(model.Provider, apiKey, httpClient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create %s provider: %w", model.Provider, err)
+		}
+		if baseURL := config.BaseURLFor(string(model.Provider)); baseURL != "" {
+			if err := providers.SetBaseURL(provider, baseURL); err != nil {
+				return nil, fmt.Errorf("invalid base URL for %s: %w", model.Provider, err)
+			}
+		}
+		return provider, nil
+	}
+}
+
+// writeEvent writes one server-sent event
+func writeEvent(w http.ResponseWriter, event string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorBody{message})
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/john/klip/internal/api"
+)
+
+// streamingProvider replies with a fixed set of chunks, recording the
+// request it was sent
+type streamingProvider struct {
+	chunks []string
+	got    *api.ChatRequest
+}
+
+func (p *streamingProvider) Chat(ctx context.Context, req *api.ChatRequest) (*api.ChatResponse, error) {
+	p.got = req
+	return &api.ChatResponse{Content: strings.Join(p.chunks, ""), FinishReason: "stop"}, nil
+}
+
+func (p *streamingProvider) ChatStream(ctx context.Context, req *api.ChatRequest) (<-chan api.StreamChunk, <-chan error) {
+	p.got = req
+	chunks := make(chan api.StreamChunk, len(p.chunks)+1)
+	errs := make(chan error, 1)
+	for _, content := range p.chunks {
+		chunks <- api.StreamChunk{Content: content}
+	}
+	chunks <- api.StreamChunk{Done: true, FinishReason: "stop", Usage: &api.Usage{InputTokens: 5, OutputTokens: 3}}
+	close(chunks)
+	close(errs)
+	return chunks, errs
+}
+
+func (p *streamingProvider) GetModels(ctx context.Context) ([]api.Model, error) { return nil, nil }
+
+func (p *streamingProvider) ValidateCredentials(ctx context.Context) error { return nil }
+
+func newTestServer(provider api.ProviderInterface, token string) *httptest.Server {
+	factory := func(model api.Model) (api.ProviderInterface, error) { return provider, nil }
+	return httptest.NewServer(New(factory, nil, token).Handler())
+}
+
+func postChat(t *testing.T, url, token, body string) *http.Response {
+	req, err := http.NewRequest(http.MethodPost, url+"/chat", strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestChatStreamsServerSentEvents(t *testing.T) {
+	provider := &streamingProvider{chunks: []string{"Hel", "lo"}}
+	ts := newTestServer(provider, "")
+	defer ts.Close()
+
+	resp := postChat(t, ts.URL, "", `{"model":"claude-3-5-sonnet-20241022","stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t,
+		"event: message\ndata: {\"content\":\"Hel\"}\n\n"+
+			"event: message\ndata: {\"content\":\"lo\"}\n\n"+
+			"event: done\ndata: {\"finish_reason\":\"stop\",\"usage\":{\"input_tokens\":5,\"output_tokens\":3}}\n\n",
+		string(body))
+
+	// The known model is resolved with its provider
+	require.NotNil(t, provider.got)
+	assert.Equal(t, api.ProviderAnthropic, provider.got.Model.Provider)
+	assert.Equal(t, "hi", provider.got.Messages[0].Content)
+}
+
+func TestChatReturnsJSONWithoutStream(t *testing.T) {
+	ts := newTestServer(&streamingProvider{chunks: []string{"Hello"}}, "")
+	defer ts.Close()
+
+	resp := postChat(t, ts.URL, "", `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"content":"Hello"`)
+}
+
+func TestChatRequiresToken(t *testing.T) {
+	ts := newTestServer(&streamingProvider{chunks: []string{"Hello"}}, "secret")
+	defer ts.Close()
+
+	body := `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`
+	assert.Equal(t, http.StatusUnauthorized, postChat(t, ts.URL, "", body).StatusCode)
+	assert.Equal(t, http.StatusUnauthorized, postChat(t, ts.URL, "wrong", body).StatusCode)
+	assert.Equal(t, http.StatusOK, postChat(t, ts.URL, "secret", body).StatusCode)
+}
+
+func TestChatRejectsBadRequests(t *testing.T) {
+	ts := newTestServer(&streamingProvider{}, "")
+	defer ts.Close()
+
+	assert.Equal(t, http.StatusBadRequest, postChat(t, ts.URL, "", `{"model":"gpt-4o"}`).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, postChat(t, ts.URL, "", `{"model":"mystery","messages":[{"role":"user","content":"hi"}]}`).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, postChat(t, ts.URL, "", `not json`).StatusCode)
+
+	resp, err := http.Get(ts.URL + "/chat")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func TestChatRejectsCrossSiteRequests(t *testing.T) {
+	ts := newTestServer(&streamingProvider{chunks: []string{"Hello"}}, "")
+	defer ts.Close()
+	body := `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`
+
+	send := func(mutate func(req *http.Request)) int {
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/chat", strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		mutate(req)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// A page served from another host, as after DNS rebinding
+	assert.Equal(t, http.StatusForbidden, send(func(req *http.Request) { req.Host = "evil.example:8787" }))
+	// A form or fetch a browser sends without a preflight
+	assert.Equal(t, http.StatusUnsupportedMediaType, send(func(req *http.Request) { req.Header.Set("Content-Type", "text/plain") }))
+	assert.Equal(t, http.StatusUnsupportedMediaType, send(func(req *http.Request) { req.Header.Del("Content-Type") }))
+	assert.Equal(t, http.StatusOK, send(func(req *http.Request) { req.Host = "localhost:8787" }))
+}
+
+func TestChatRejectsOversizedBody(t *testing.T) {
+	ts := newTestServer(&streamingProvider{}, "")
+	defer ts.Close()
+
+	huge := `{"model":"gpt-4o","messages":[{"role":"user","content":"` + strings.Repeat("a", maxRequestBody) + `"}]}`
+	assert.Equal(t, http.StatusRequestEntityTooLarge, postChat(t, ts.URL, "", huge).StatusCode)
+}
+
+func TestListenRefusesRemoteAddressWithoutToken(t *testing.T) {
+	srv := New(nil, nil, "")
+	err := srv.ListenAndServe(context.Background(), "0.0.0.0:0")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "token")
+}
@@ -4,10 +4,12 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/log"
@@ -48,6 +50,11 @@ type ResponseData struct {
 	LatencyMs      int64 `json:"latency_ms"`
 	IsStream       bool  `json:"is_stream"`
 	Interrupted    bool  `json:"interrupted"`
+
+	// Input tokens read from or written to the provider's prompt cache,
+	// which TokensInput doesn't include
+	TokensCacheRead  int `json:"tokens_cache_read,omitempty"`
+	TokensCacheWrite int `json:"tokens_cache_write,omitempty"`
 }
 
 // ErrorData contains error-specific information
@@ -64,6 +71,10 @@ type CostData struct {
 	EstimatedCostOutput float64 `json:"estimated_cost_output,omitempty"`
 	EstimatedCostTotal  float64 `json:"estimated_cost_total,omitempty"`
 	Currency            string  `json:"currency"`
+
+	// CacheSavings is how much less the input cost than it would have
+	// without prompt caching
+	CacheSavings float64 `json:"cache_savings,omitempty"`
 }
 
 // CommandData contains command execution information
@@ -100,6 +111,11 @@ type ResponseMetrics struct {
 	ErrorMessage   string
 	StatusCode     int
 	RetryCount     int
+
+	// CacheReadTokens and CacheWriteTokens are input tokens served from
+	// and added to the prompt cache, on top of TokensInput
+	CacheReadTokens  int
+	CacheWriteTokens int
 }
 
 // CostEstimate represents cost per 1M tokens for a model
@@ -137,6 +153,48 @@ var costEstimates = map[string]CostEstimate{
 	"meta-llama/llama-3.1-405b-instruct": {Input: 2.7, Output: 2.7, Currency: "USD"},
 }
 
+// Prompt cache pricing relative to a model's input price: reading cached
+// tokens costs a tenth of the usual rate, writing them a quarter more
+const (
+	cacheReadPriceFactor  = 0.1
+	cacheWritePriceFactor = 1.25
+)
+
+// LookupCostEstimate returns the per-1M-token pricing for a model, if known
+func LookupCostEstimate(modelID string) (CostEstimate, bool) {
+	estimate, exists := costEstimates[modelID]
+	return estimate, exists
+}
+
+// EstimateCost returns the approximate USD cost of a request for a known model
+func EstimateCost(modelID string, inputTokens, outputTokens int) float64 {
+	return EstimateCachedCost(modelID, inputTokens, 0, 0, outputTokens)
+}
+
+// EstimateCachedCost returns the approximate USD cost of a request that
+// read cacheReadTokens from the prompt cache and wrote cacheWriteTokens to
+// it, in addition to its uncached inputTokens
+func EstimateCachedCost(modelID string, inputTokens, cacheReadTokens, cacheWriteTokens, outputTokens int) float64 {
+	estimate, exists := costEstimates[modelID]
+	if !exists {
+		return 0
+	}
+
+	return estimate.inputCost(inputTokens, cacheReadTokens, cacheWriteTokens) + (float64(outputTokens)/1_000_000)*estimate.Output
+}
+
+// inputCost prices input tokens, applying the prompt cache rates
+func (e CostEstimate) inputCost(inputTokens, cacheReadTokens, cacheWriteTokens int) float64 {
+	perToken := e.Input / 1_000_000
+	return float64(inputTokens)*perToken +
+		float64(cacheReadTokens)*perToken*cacheReadPriceFactor +
+		float64(cacheWriteTokens)*perToken*cacheWritePriceFactor
+}
+
+// analyticsFlushInterval is how often buffered events are written even
+// when the buffer isn't full, bounding what a crash can lose
+const analyticsFlushInterval = 30 * time.Second
+
 // AnalyticsLogger handles collection and storage of analytics data
 type AnalyticsLogger struct {
 	analyticsDir  string
@@ -145,10 +203,35 @@ type AnalyticsLogger struct {
 	currentDate   string
 	pendingEvents []AnalyticsEvent
 	logger        *log.Logger
+
+	// mu guards pendingEvents and the files they are written to, so
+	// requests, the flush loop and cleanup can log from any goroutine
+	mu        sync.Mutex
+	stopFlush chan struct{}
+	closeOnce sync.Once
 }
 
 // NewAnalyticsLogger creates a new AnalyticsLogger instance
 func NewAnalyticsLogger(config *AnalyticsConfig) (*AnalyticsLogger, error) {
+	return NewAnalyticsLoggerWithIDs(config, generateSessionID)
+}
+
+// NewAnalyticsLoggerWithIDs creates an AnalyticsLogger that takes its
+// session IDs from newSessionID, so tests can use a known sequence
+func NewAnalyticsLoggerWithIDs(config *AnalyticsConfig, newSessionID func() string) (*AnalyticsLogger, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return newAnalyticsLoggerIn(configDir, config, newSessionID)
+}
+
+// newAnalyticsLoggerIn creates an AnalyticsLogger keeping its events in
+// configDir
+func newAnalyticsLoggerIn(configDir string, config *AnalyticsConfig, newSessionID func() string) (*AnalyticsLogger, error) {
+	if newSessionID == nil {
+		newSessionID = generateSessionID
+	}
 	if config == nil {
 		config = &AnalyticsConfig{
 			Enabled:            true,
@@ -159,11 +242,6 @@ func NewAnalyticsLogger(config *AnalyticsConfig) (*AnalyticsLogger, error) {
 		}
 	}
 
-	configDir, err := GetConfigDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get config directory: %w", err)
-	}
-
 	analyticsDir := filepath.Join(configDir, "analytics")
 	if config.Enabled {
 		if err := os.MkdirAll(analyticsDir, 0700); err != nil {
@@ -171,7 +249,7 @@ func NewAnalyticsLogger(config *AnalyticsConfig) (*AnalyticsLogger, error) {
 		}
 	}
 
-	sessionID := generateSessionID()
+	sessionID := newSessionID()
 	currentDate := time.Now().Format("2006-01-02")
 
 	al := &AnalyticsLogger{
@@ -181,6 +259,7 @@ func NewAnalyticsLogger(config *AnalyticsConfig) (*AnalyticsLogger, error) {
 		currentDate:   currentDate,
 		pendingEvents: make([]AnalyticsEvent, 0),
 		logger:        log.New(os.Stderr),
+		stopFlush:     make(chan struct{}),
 	}
 
 	if config.Enabled {
@@ -201,11 +280,17 @@ func NewAnalyticsLogger(config *AnalyticsConfig) (*AnalyticsLogger, error) {
 		if os.Getenv("GO_TEST_MODE") == "" {
 			go al.startCleanupRoutine()
 		}
+		go al.flushLoop(analyticsFlushInterval)
 	}
 
 	return al, nil
 }
 
+// SessionID returns the ID this logger's events are recorded under
+func (al *AnalyticsLogger) SessionID() string {
+	return al.sessionID
+}
+
 // LogRequest logs a request event
 func (al *AnalyticsLogger) LogRequest(metrics RequestMetrics) error {
 	if !al.config.Enabled {
@@ -245,7 +330,8 @@ func (al *AnalyticsLogger) LogResponse(requestMetrics RequestMetrics, responseMe
 	}
 
 	latency := responseMetrics.EndTime.Sub(requestMetrics.StartTime).Milliseconds()
-	costData := al.calculateCost(requestMetrics.ModelID, responseMetrics.TokensInput, responseMetrics.TokensOutput)
+	costData := al.calculateCachedCost(requestMetrics.ModelID, responseMetrics.TokensInput,
+		responseMetrics.CacheReadTokens, responseMetrics.CacheWriteTokens, responseMetrics.TokensOutput)
 
 	eventType := "response"
 	if !responseMetrics.Success {
@@ -271,10 +357,13 @@ func (al *AnalyticsLogger) LogResponse(requestMetrics RequestMetrics, responseMe
 			ResponseLength: responseMetrics.ResponseLength,
 			TokensInput:    responseMetrics.TokensInput,
 			TokensOutput:   responseMetrics.TokensOutput,
-			TotalTokens:    responseMetrics.TokensInput + responseMetrics.TokensOutput,
-			LatencyMs:      latency,
-			IsStream:       requestMetrics.IsStream,
-			Interrupted:    responseMetrics.Interrupted,
+			TotalTokens: responseMetrics.TokensInput + responseMetrics.CacheReadTokens +
+				responseMetrics.CacheWriteTokens + responseMetrics.TokensOutput,
+			TokensCacheRead:  responseMetrics.CacheReadTokens,
+			TokensCacheWrite: responseMetrics.CacheWriteTokens,
+			LatencyMs:        latency,
+			IsStream:         requestMetrics.IsStream,
+			Interrupted:      responseMetrics.Interrupted,
 		},
 		CostData: costData,
 	}
@@ -313,6 +402,12 @@ func (al *AnalyticsLogger) LogCommand(command string, success bool, executionTim
 
 // LogModelSwitch logs a model switch event
 func (al *AnalyticsLogger) LogModelSwitch(oldModelID, oldModelName, oldProvider, newModelID, newModelName, newProvider string) error {
+	return al.LogModelSwitchWithReason(oldModelID, oldModelName, oldProvider, newModelID, newModelName, newProvider, "")
+}
+
+// LogModelSwitchWithReason logs a model switch event along with why the
+// model changed, e.g. "rate_limit" for an automatic fallback
+func (al *AnalyticsLogger) LogModelSwitchWithReason(oldModelID, oldModelName, oldProvider, newModelID, newModelName, newProvider, reason string) error {
 	if !al.config.Enabled {
 		return nil
 	}
@@ -330,6 +425,9 @@ func (al *AnalyticsLogger) LogModelSwitch(oldModelID, oldModelName, oldProvider,
 			"previous_provider":   oldProvider,
 		},
 	}
+	if reason != "" {
+		event.Metadata["reason"] = reason
+	}
 
 	return al.logEvent(event)
 }
@@ -363,18 +461,59 @@ func (al *AnalyticsLogger) logEvent(event AnalyticsEvent) error {
 		return nil
 	}
 
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
 	al.pendingEvents = append(al.pendingEvents, event)
 
 	// Flush events if we have accumulated enough or if it's an important event
 	if len(al.pendingEvents) >= 10 || event.EventType == "session_end" || event.EventType == "error" {
-		return al.flushEvents()
+		return al.writePendingEvents()
 	}
 
 	return nil
 }
 
+// Flush writes buffered events to disk
+func (al *AnalyticsLogger) Flush() error {
+	return al.flushEvents()
+}
+
+// Close stops the flush loop and writes any buffered events. It is safe to
+// call more than once and is what the app runs on exit.
+func (al *AnalyticsLogger) Close() error {
+	al.closeOnce.Do(func() {
+		close(al.stopFlush)
+	})
+	return al.flushEvents()
+}
+
+// flushLoop writes buffered events every interval until Close is called
+func (al *AnalyticsLogger) flushLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := al.flushEvents(); err != nil {
+				al.logger.Warn("Failed to flush analytics events", "error", err)
+			}
+		case <-al.stopFlush:
+			return
+		}
+	}
+}
+
 // flushEvents writes pending events to disk
 func (al *AnalyticsLogger) flushEvents() error {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	return al.writePendingEvents()
+}
+
+// writePendingEvents writes pending events to disk; al.mu must be held
+func (al *AnalyticsLogger) writePendingEvents() error {
 	if len(al.pendingEvents) == 0 {
 		return nil
 	}
@@ -427,7 +566,13 @@ func (al *AnalyticsLogger) flushEvents() error {
 
 // calculateCost estimates the cost of a request/response
 func (al *AnalyticsLogger) calculateCost(modelID string, inputTokens, outputTokens int) *CostData {
-	if !al.config.EnableCostTracking || inputTokens == 0 || outputTokens == 0 {
+	return al.calculateCachedCost(modelID, inputTokens, 0, 0, outputTokens)
+}
+
+// calculateCachedCost estimates the cost of a request/response that used
+// the prompt cache, and how much the cache saved
+func (al *AnalyticsLogger) calculateCachedCost(modelID string, inputTokens, cacheReadTokens, cacheWriteTokens, outputTokens int) *CostData {
+	if !al.config.EnableCostTracking || inputTokens+cacheReadTokens+cacheWriteTokens == 0 || outputTokens == 0 {
 		return nil
 	}
 
@@ -436,15 +581,17 @@ func (al *AnalyticsLogger) calculateCost(modelID string, inputTokens, outputToke
 		return nil
 	}
 
-	inputCost := (float64(inputTokens) / 1_000_000) * estimate.Input
+	inputCost := estimate.inputCost(inputTokens, cacheReadTokens, cacheWriteTokens)
 	outputCost := (float64(outputTokens) / 1_000_000) * estimate.Output
 	totalCost := inputCost + outputCost
+	uncachedCost := estimate.inputCost(inputTokens+cacheReadTokens+cacheWriteTokens, 0, 0)
 
 	return &CostData{
 		EstimatedCostInput:  inputCost,
 		EstimatedCostOutput: outputCost,
 		EstimatedCostTotal:  totalCost,
 		Currency:            estimate.Currency,
+		CacheSavings:        max(uncachedCost-inputCost, 0),
 	}
 }
 
@@ -474,6 +621,10 @@ func (al *AnalyticsLogger) cleanupOldFiles() error {
 	cutoffDate := time.Now().AddDate(0, 0, -al.config.RetainDays)
 	cutoffDateStr := cutoffDate.Format("2006-01-02")
 
+	// Don't delete files while events are being written or rotated
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
 	files, err := os.ReadDir(al.analyticsDir)
 	if err != nil {
 		return fmt.Errorf("failed to read analytics directory: %w", err)
@@ -607,9 +758,11 @@ func (al *AnalyticsLogger) GetUsageStats(days int) (map[string]interface{}, erro
 		"models_used":    make(map[string]int),
 		"daily_usage":    make(map[string]int),
 		"providers_used": make(map[string]int),
+		"commands_used":  make(map[string]int),
 	}
 
 	var totalLatency int64
+	var latencies []int64
 	var requestCount, errorCount int
 
 	for _, event := range events {
@@ -636,6 +789,7 @@ func (al *AnalyticsLogger) GetUsageStats(days int) (map[string]interface{}, erro
 		case "response":
 			if event.ResponseData != nil {
 				totalLatency += event.ResponseData.LatencyMs
+				latencies = append(latencies, event.ResponseData.LatencyMs)
 				if event.ResponseData.TotalTokens > 0 {
 					stats["total_tokens"] = stats["total_tokens"].(int) + event.ResponseData.TotalTokens
 				}
@@ -647,6 +801,12 @@ func (al *AnalyticsLogger) GetUsageStats(days int) (map[string]interface{}, erro
 
 		case "error":
 			errorCount++
+
+		case "command_usage":
+			if event.CommandData != nil && event.CommandData.Command != "" {
+				commands := stats["commands_used"].(map[string]int)
+				commands[event.CommandData.Command]++
+			}
 		}
 	}
 
@@ -656,5 +816,73 @@ func (al *AnalyticsLogger) GetUsageStats(days int) (map[string]interface{}, erro
 		stats["error_rate"] = float64(errorCount) / float64(requestCount)
 	}
 
+	// Averages hide slow outliers, so the distribution is reported too
+	stats["latency_histogram"] = LatencyHistogram(latencies)
+	stats["p50_latency"] = LatencyPercentile(latencies, 50)
+	stats["p95_latency"] = LatencyPercentile(latencies, 95)
+	stats["hourly_activity"] = HourlyActivity(events, time.Local)
+
 	return stats, nil
 }
+
+// HourlyActivity counts the requests sent in each hour of the day, on the
+// clock of loc
+func HourlyActivity(events []AnalyticsEvent, loc *time.Location) [24]int {
+	var hours [24]int
+	for _, event := range events {
+		if event.EventType == "request" {
+			hours[event.Timestamp.In(loc).Hour()]++
+		}
+	}
+	return hours
+}
+
+// LatencyBucket counts the responses whose latency falls in [Min, Max);
+// a zero Max leaves the bucket open-ended
+type LatencyBucket struct {
+	Label string
+	Min   time.Duration
+	Max   time.Duration
+	Count int
+}
+
+// latencyBuckets are the ranges of the response time histogram
+var latencyBuckets = []LatencyBucket{
+	{Label: "<1s", Max: time.Second},
+	{Label: "1–3s", Min: time.Second, Max: 3 * time.Second},
+	{Label: "3–10s", Min: 3 * time.Second, Max: 10 * time.Second},
+	{Label: ">10s", Min: 10 * time.Second},
+}
+
+// LatencyHistogram sorts response latencies, in milliseconds, into the
+// histogram's buckets
+func LatencyHistogram(latenciesMs []int64) []LatencyBucket {
+	buckets := make([]LatencyBucket, len(latencyBuckets))
+	copy(buckets, latencyBuckets)
+
+	for _, ms := range latenciesMs {
+		latency := time.Duration(ms) * time.Millisecond
+		for i := range buckets {
+			if latency >= buckets[i].Min && (buckets[i].Max == 0 || latency < buckets[i].Max) {
+				buckets[i].Count++
+				break
+			}
+		}
+	}
+	return buckets
+}
+
+// LatencyPercentile returns the pth percentile of latencies in
+// milliseconds by the nearest-rank method, or 0 when there are none
+func LatencyPercentile(latenciesMs []int64, p float64) int64 {
+	if len(latenciesMs) == 0 {
+		return 0
+	}
+
+	sorted := append([]int64(nil), latenciesMs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	rank = min(max(rank, 1), len(sorted))
+	return sorted[rank-1]
+}
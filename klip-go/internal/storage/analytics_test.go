@@ -1,7 +1,10 @@
 package storage
 
 import (
+	"fmt"
+	"math"
 	"os"
+	"sync"
 	"testing"
 	"time"
 )
@@ -354,6 +357,261 @@ func TestAnalyticsLogger_GetUsageStats(t *testing.T) {
 	}
 }
 
+func TestAnalyticsLogger_LatencyHistogram(t *testing.T) {
+	analyticsLogger, _ := setupTestAnalyticsLogger(t)
+
+	// Ten responses: 4 under 1s, 3 within 1-3s, 2 within 3-10s, 1 over 10s
+	latencies := []time.Duration{
+		200 * time.Millisecond, 500 * time.Millisecond, 800 * time.Millisecond, 999 * time.Millisecond,
+		time.Second, 2 * time.Second, 2900 * time.Millisecond,
+		3 * time.Second, 9 * time.Second,
+		25 * time.Second,
+	}
+	startTime := time.Now()
+	for _, latency := range latencies {
+		requestMetrics := RequestMetrics{StartTime: startTime, ModelID: "claude-3-5-haiku-20241022"}
+		responseMetrics := ResponseMetrics{EndTime: startTime.Add(latency), Success: true}
+		if err := analyticsLogger.LogResponse(requestMetrics, responseMetrics); err != nil {
+			t.Fatalf("Failed to log response: %v", err)
+		}
+	}
+	if err := analyticsLogger.flushEvents(); err != nil {
+		t.Fatalf("Failed to flush events: %v", err)
+	}
+
+	stats, err := analyticsLogger.GetUsageStats(7)
+	if err != nil {
+		t.Fatalf("Failed to get usage stats: %v", err)
+	}
+
+	histogram, ok := stats["latency_histogram"].([]LatencyBucket)
+	if !ok {
+		t.Fatalf("Expected latency_histogram to be []LatencyBucket, got %T", stats["latency_histogram"])
+	}
+	expected := map[string]int{"<1s": 4, "1–3s": 3, "3–10s": 2, ">10s": 1}
+	if len(histogram) != len(expected) {
+		t.Fatalf("Expected %d buckets, got %d", len(expected), len(histogram))
+	}
+	for _, bucket := range histogram {
+		if bucket.Count != expected[bucket.Label] {
+			t.Errorf("Expected %d responses in %s, got %d", expected[bucket.Label], bucket.Label, bucket.Count)
+		}
+	}
+
+	if p50 := stats["p50_latency"]; p50 != int64(1000) {
+		t.Errorf("Expected p50 of 1000ms, got %v", p50)
+	}
+	if p95 := stats["p95_latency"]; p95 != int64(25000) {
+		t.Errorf("Expected p95 of 25000ms, got %v", p95)
+	}
+
+	if LatencyPercentile(nil, 95) != 0 {
+		t.Error("Expected no percentile without responses")
+	}
+}
+
+func TestAnalyticsLogger_CommandsUsed(t *testing.T) {
+	analyticsLogger, _ := setupTestAnalyticsLogger(t)
+
+	for _, command := range []string{"model", "clear", "model", "stats", "model", "clear"} {
+		if err := analyticsLogger.LogCommand(command, true, 1); err != nil {
+			t.Fatalf("Failed to log command: %v", err)
+		}
+	}
+	if err := analyticsLogger.flushEvents(); err != nil {
+		t.Fatalf("Failed to flush events: %v", err)
+	}
+
+	stats, err := analyticsLogger.GetUsageStats(7)
+	if err != nil {
+		t.Fatalf("Failed to get usage stats: %v", err)
+	}
+
+	commands, ok := stats["commands_used"].(map[string]int)
+	if !ok {
+		t.Fatalf("Expected commands_used to be map[string]int, got %T", stats["commands_used"])
+	}
+	expected := map[string]int{"model": 3, "clear": 2, "stats": 1}
+	if len(commands) != len(expected) {
+		t.Errorf("Expected %d commands, got %v", len(expected), commands)
+	}
+	for command, count := range expected {
+		if commands[command] != count {
+			t.Errorf("Expected /%s to be counted %d times, got %d", command, count, commands[command])
+		}
+	}
+
+	// Commands aren't requests
+	if stats["total_requests"] != 0 {
+		t.Errorf("Expected no requests, got %v", stats["total_requests"])
+	}
+}
+
+func TestAnalyticsLogger_SeededSessionIDs(t *testing.T) {
+	_, _ = setupTestAnalyticsLogger(t)
+
+	next := 0
+	sequence := func() string {
+		next++
+		return fmt.Sprintf("test-%d", next)
+	}
+	config := &AnalyticsConfig{Enabled: true, RetainDays: 365, MaxFileSizeMB: 10, EnableCostTracking: true}
+
+	analyticsLogger, err := NewAnalyticsLoggerWithIDs(config, sequence)
+	if err != nil {
+		t.Fatalf("Failed to create AnalyticsLogger: %v", err)
+	}
+	if analyticsLogger.SessionID() != "test-1" {
+		t.Errorf("Expected session ID 'test-1', got '%s'", analyticsLogger.SessionID())
+	}
+
+	request := RequestMetrics{StartTime: time.Now(), ModelID: "claude-3-5-sonnet-20241022", Provider: "anthropic"}
+	if err := analyticsLogger.LogRequest(request); err != nil {
+		t.Fatalf("Failed to log request: %v", err)
+	}
+	if err := analyticsLogger.LogResponse(request, ResponseMetrics{EndTime: time.Now(), Success: true}); err != nil {
+		t.Fatalf("Failed to log response: %v", err)
+	}
+	if err := analyticsLogger.LogCommand("stats", true, 1); err != nil {
+		t.Fatalf("Failed to log command: %v", err)
+	}
+	if err := analyticsLogger.flushEvents(); err != nil {
+		t.Fatalf("Failed to flush events: %v", err)
+	}
+
+	events, err := analyticsLogger.GetAnalyticsData("", "", "")
+	if err != nil {
+		t.Fatalf("Failed to get analytics data: %v", err)
+	}
+	if len(events) < 4 {
+		t.Fatalf("Expected session start, request, response and command events, got %d", len(events))
+	}
+	for _, event := range events {
+		if event.SessionID != "test-1" {
+			t.Errorf("Expected %s event in session 'test-1', got '%s'", event.EventType, event.SessionID)
+		}
+	}
+
+	// Each logger takes the next ID
+	second, err := NewAnalyticsLoggerWithIDs(config, sequence)
+	if err != nil {
+		t.Fatalf("Failed to create AnalyticsLogger: %v", err)
+	}
+	if second.SessionID() != "test-2" {
+		t.Errorf("Expected session ID 'test-2', got '%s'", second.SessionID())
+	}
+}
+
+func TestHourlyActivity(t *testing.T) {
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	event := func(eventType string, hour, minute int) AnalyticsEvent {
+		return AnalyticsEvent{EventType: eventType, Timestamp: day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)}
+	}
+	events := []AnalyticsEvent{
+		event("request", 9, 0),
+		event("request", 9, 59),
+		event("request", 14, 30),
+		event("request", 23, 15),
+		event("response", 9, 1),
+		event("command_usage", 14, 31),
+	}
+
+	hours := HourlyActivity(events, time.UTC)
+	expected := map[int]int{9: 2, 14: 1, 23: 1}
+	for hour, count := range hours {
+		if count != expected[hour] {
+			t.Errorf("Expected %d requests at %02d:00, got %d", expected[hour], hour, count)
+		}
+	}
+
+	// Hours follow the given clock, wrapping past midnight
+	hours = HourlyActivity(events, time.FixedZone("UTC+2", 2*60*60))
+	expected = map[int]int{11: 2, 16: 1, 1: 1}
+	for hour, count := range hours {
+		if count != expected[hour] {
+			t.Errorf("Expected %d requests at %02d:00 UTC+2, got %d", expected[hour], hour, count)
+		}
+	}
+}
+
+func TestAnalyticsLogger_CloseFlushesPendingEvents(t *testing.T) {
+	analyticsLogger, _ := setupTestAnalyticsLogger(t)
+
+	if err := analyticsLogger.LogCommand("stats", true, 1); err != nil {
+		t.Fatalf("Failed to log command: %v", err)
+	}
+
+	// One command doesn't fill the buffer, so nothing is on disk yet
+	events, err := analyticsLogger.GetAnalyticsData("", "", "command_usage")
+	if err != nil {
+		t.Fatalf("Failed to get analytics data: %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("Expected the command to be buffered, found %d on disk", len(events))
+	}
+
+	if err := analyticsLogger.Close(); err != nil {
+		t.Fatalf("Failed to close analytics logger: %v", err)
+	}
+	events, err = analyticsLogger.GetAnalyticsData("", "", "command_usage")
+	if err != nil {
+		t.Fatalf("Failed to get analytics data: %v", err)
+	}
+	if len(events) != 1 {
+		t.Errorf("Expected the buffered command to be written on close, got %d events", len(events))
+	}
+
+	// Closing again is harmless
+	if err := analyticsLogger.Close(); err != nil {
+		t.Errorf("Expected a second close to succeed, got %v", err)
+	}
+}
+
+func TestAnalyticsLogger_ConcurrentLogging(t *testing.T) {
+	analyticsLogger, _ := setupTestAnalyticsLogger(t)
+
+	const goroutines, perGoroutine = 8, 25
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				if err := analyticsLogger.LogCommand(fmt.Sprintf("cmd%d", g), true, int64(i)); err != nil {
+					t.Errorf("Failed to log command: %v", err)
+				}
+				if i%10 == 0 {
+					if err := analyticsLogger.Flush(); err != nil {
+						t.Errorf("Failed to flush events: %v", err)
+					}
+				}
+			}
+		}(g)
+	}
+
+	// Cleanup runs alongside logging in the app
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := analyticsLogger.cleanupOldFiles(); err != nil {
+			t.Errorf("Failed to clean up: %v", err)
+		}
+	}()
+	wg.Wait()
+
+	if err := analyticsLogger.Close(); err != nil {
+		t.Fatalf("Failed to close analytics logger: %v", err)
+	}
+
+	events, err := analyticsLogger.GetAnalyticsData("", "", "command_usage")
+	if err != nil {
+		t.Fatalf("Failed to get analytics data: %v", err)
+	}
+	if len(events) != goroutines*perGoroutine {
+		t.Errorf("Expected %d command events, got %d", goroutines*perGoroutine, len(events))
+	}
+}
+
 func TestAnalyticsLogger_DisabledConfig(t *testing.T) {
 	tempDir := t.TempDir()
 
@@ -457,6 +715,42 @@ func TestAnalyticsLogger_CostCalculation(t *testing.T) {
 	}
 }
 
+func TestAnalyticsLogger_CachedCost(t *testing.T) {
+	analyticsLogger, _ := setupTestAnalyticsLogger(t)
+	model := "claude-3-5-sonnet-20241022"
+
+	uncached := analyticsLogger.calculateCost(model, 10000, 500)
+	cached := analyticsLogger.calculateCachedCost(model, 1000, 9000, 0, 500)
+	if uncached == nil || cached == nil {
+		t.Fatal("Expected cost data to be calculated")
+	}
+
+	if cached.EstimatedCostTotal >= uncached.EstimatedCostTotal {
+		t.Errorf("Expected cached tokens to reduce the cost, got %f vs %f", cached.EstimatedCostTotal, uncached.EstimatedCostTotal)
+	}
+	if cached.EstimatedCostOutput != uncached.EstimatedCostOutput {
+		t.Errorf("Expected the output cost to be unaffected by caching")
+	}
+
+	// 9000 tokens read at a tenth of $3 per 1M
+	expectedSavings := 9000 * 3.0 / 1_000_000 * 0.9
+	if math.Abs(cached.CacheSavings-expectedSavings) > 1e-9 {
+		t.Errorf("Expected savings of %f, got %f", expectedSavings, cached.CacheSavings)
+	}
+	if math.Abs(uncached.EstimatedCostTotal-cached.EstimatedCostTotal-expectedSavings) > 1e-9 {
+		t.Errorf("Expected the savings to account for the cost difference")
+	}
+	if uncached.CacheSavings != 0 {
+		t.Errorf("Expected no savings without caching, got %f", uncached.CacheSavings)
+	}
+
+	// Writing to the cache costs more than plain input
+	written := EstimateCachedCost(model, 0, 0, 10000, 500)
+	if written <= EstimateCost(model, 10000, 500) {
+		t.Errorf("Expected cache writes to cost more than uncached input")
+	}
+}
+
 func TestAnalyticsLogger_ErrorLogging(t *testing.T) {
 	analyticsLogger, _ := setupTestAnalyticsLogger(t)
 
@@ -2,9 +2,12 @@ package storage
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/log"
@@ -32,21 +35,112 @@ type Config struct {
 	OpenAIAPIKey     string `json:"openai_api_key"`
 	OpenRouterAPIKey string `json:"openrouter_api_key"`
 
+	// GitHubToken lets /share upload conversations as secret gists; it
+	// needs the gist scope
+	GitHubToken string `json:"github_token,omitempty"`
+
+	// Chat behavior
+	SystemPrompt    string                     `json:"system_prompt,omitempty"`
+	ModelParameters map[string]ModelParameters `json:"model_parameters,omitempty"`
+
+	// BriefMaxTokens caps the reply to a /brief message; zero uses the
+	// built-in default
+	BriefMaxTokens int `json:"brief_max_tokens,omitempty"`
+
 	// Feature flags
-	EnableWebSearch bool   `json:"enable_web_search"`
-	BaseURL         string `json:"base_url"`
-	MaxRetries      int    `json:"max_retries"`
+	EnableWebSearch bool              `json:"enable_web_search"`
+	BaseURL         string            `json:"base_url"`
+	BaseURLs        map[string]string `json:"base_urls,omitempty"`
+	MaxRetries      int               `json:"max_retries"`
+
+	// FallbackModel is the model ID to switch to when the current model is
+	// still rate limited after MaxRetries; empty disables the switch
+	FallbackModel string `json:"fallback_model,omitempty"`
+
+	// RecentModels lists the model IDs most recently switched to, newest
+	// first; FavoriteModels are pinned in the model quick picker
+	RecentModels   []string `json:"recent_models,omitempty"`
+	FavoriteModels []string `json:"favorite_models,omitempty"`
+
+	// StartupHealthCheck tests every provider with a key, and the default
+	// provider, on launch so a missing or rejected key shows up right away
+	StartupHealthCheck bool `json:"startup_health_check,omitempty"`
+
+	// AutoSummarize condenses the oldest turns into a system note when a
+	// message would overflow the context window, instead of refusing it.
+	// SummaryModel writes the note; empty picks an inexpensive model of
+	// the current provider.
+	AutoSummarize bool   `json:"auto_summarize"`
+	SummaryModel  string `json:"summary_model,omitempty"`
+
+	// HistoryTokenBudget caps the estimated tokens of the conversation sent
+	// with each message; the oldest whole turns are dropped to stay under
+	// it. Zero leaves the conversation untrimmed.
+	HistoryTokenBudget int `json:"history_token_budget,omitempty"`
+
+	// IdleArchiveMinutes saves and sets aside the conversation after this
+	// many minutes without input, so the next message starts a new one.
+	// Zero keeps conversations open indefinitely.
+	IdleArchiveMinutes int `json:"idle_archive_minutes,omitempty"`
+
+	// EnablePromptCaching asks providers that support it (Anthropic) to
+	// cache the system prompt and conversation between turns. Cached input
+	// is billed at a fraction of the usual rate; writing it costs a little
+	// more, so it pays off for long system prompts and conversations.
+	EnablePromptCaching bool `json:"enable_prompt_caching,omitempty"`
+
+	// ShowCostEstimate shows in the status bar what the message being
+	// typed would cost to send, from its estimated tokens and the model's
+	// pricing; /estimate gives the same preview on demand
+	ShowCostEstimate bool `json:"show_cost_estimate,omitempty"`
+
+	// Outgoing messages are checked for API keys, tokens and private keys
+	// before they are sent, unless DisableSecretScan is set. SecretPatterns
+	// adds expressions to look for, keyed by name; naming a built-in kind
+	// such as "JSON web token" replaces it, and an empty expression
+	// turns it off.
+	DisableSecretScan bool              `json:"disable_secret_scan,omitempty"`
+	SecretPatterns    map[string]string `json:"secret_patterns,omitempty"`
+
+	// EnableCodeExecution lets /run execute shell and Python blocks from a
+	// reply after confirming the code. Snippets run as the current user with
+	// a scratch working directory, a trimmed environment and a timeout, but
+	// otherwise unconfined: they can read and change any file and reach the
+	// network. Only turn this on if you read every snippet before running it.
+	EnableCodeExecution bool `json:"enable_code_execution,omitempty"`
+
+	// Proxy settings; empty values fall back to the environment
+	HTTPProxy  string `json:"http_proxy,omitempty"`
+	HTTPSProxy string `json:"https_proxy,omitempty"`
+	NoProxy    string `json:"no_proxy,omitempty"`
 
 	// UI preferences
 	Theme               string        `json:"theme"`
 	ShowTimestamps      bool          `json:"show_timestamps"`
 	SyntaxHighlighting  bool          `json:"syntax_highlighting"`
 	ShowTokenCount      bool          `json:"show_token_count"`
+	ShowMessageUsage    bool          `json:"show_message_usage"`
 	AutoScroll          bool          `json:"auto_scroll"`
 	MaxLineLength       int           `json:"max_line_length"`
 	EnableAnimations    bool          `json:"enable_animations"`
 	ShowTypingIndicator bool          `json:"show_typing_indicator"`
 	AnimationSpeed      time.Duration `json:"animation_speed"`
+	SpinnerStyle        string        `json:"spinner_style,omitempty"`
+	SubmitKey           string        `json:"submit_key,omitempty"`
+	SaveDrafts          bool          `json:"save_drafts"`
+	VimMode             bool          `json:"vim_mode"`
+	HighlightTypos      bool          `json:"highlight_typos"`
+	NotificationBell    bool          `json:"notification_bell"`
+	StatusBarSections   []string      `json:"status_bar_sections,omitempty"`
+	ShowGitContext      bool          `json:"show_git_context"`
+
+	// LegacySpellCheck is the name highlight_typos was saved under before it
+	// was renamed; it is moved over when the config is loaded
+	LegacySpellCheck bool `json:"spell_check,omitempty"`
+
+	// Accessibility is combined with the preferences set through
+	// environment variables such as ACCESSIBILITY_HIGH_CONTRAST
+	Accessibility AccessibilityConfig `json:"accessibility"`
 
 	// System settings
 	DebugMode bool   `json:"debug_mode"`
@@ -59,6 +153,12 @@ type Config struct {
 	MaxConcurrentRequests int           `json:"max_concurrent_requests"`
 	CacheModels           bool          `json:"cache_models"`
 	CacheDuration         time.Duration `json:"cache_duration"`
+	ResponseCache         bool          `json:"response_cache"`
+
+	// LowBandwidthMode buffers streamed replies and redraws them a few
+	// times a second, with animations off: "auto" turns it on over SSH,
+	// "on" and "off" force it
+	LowBandwidthMode string `json:"low_bandwidth_mode,omitempty"`
 }
 
 // Settings contains general application settings
@@ -71,6 +171,21 @@ type Settings struct {
 	EnableWebSearch   bool     `json:"enable_web_search"`
 }
 
+// ModelParameters contains per-model generation settings. Unset fields
+// leave the choice to the provider default.
+type ModelParameters struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	MaxTokens   *int     `json:"max_tokens,omitempty"`
+}
+
+// ParametersFor returns the generation settings configured for a model
+func (c *Config) ParametersFor(modelID string) ModelParameters {
+	if c == nil || c.ModelParameters == nil {
+		return ModelParameters{}
+	}
+	return c.ModelParameters[modelID]
+}
+
 // UIPreferences contains user interface preferences
 type UIPreferences struct {
 	Theme           string `json:"theme"`
@@ -81,6 +196,15 @@ type UIPreferences struct {
 	SyntaxHighlight bool   `json:"syntax_highlight"`
 }
 
+// AccessibilityConfig contains accessibility preferences
+type AccessibilityConfig struct {
+	HighContrast   bool   `json:"high_contrast"`
+	ReducedMotion  bool   `json:"reduced_motion"`
+	LargeText      bool   `json:"large_text"`
+	ScreenReader   bool   `json:"screen_reader"`
+	ColorBlindType string `json:"color_blind_type,omitempty"`
+}
+
 // AnalyticsConfig contains analytics settings
 type AnalyticsConfig struct {
 	Enabled            bool `json:"enabled"`
@@ -99,11 +223,151 @@ type LoggingConfig struct {
 	MaxFileSizeMB int    `json:"max_file_size_mb"`
 }
 
+// DefaultStatusBarSections returns the status bar sections shown when none are configured
+func DefaultStatusBarSections() []string {
+	return []string{"connection", "model", "git", "usage", "performance", "system"}
+}
+
+// SpinnerStyles returns the loading spinner patterns that can be configured
+func SpinnerStyles() []string {
+	return []string{"braille", "line", "dot", "minidot", "jump", "points", "moon"}
+}
+
+// SubmitKeys returns the keys that can be configured to send a message.
+// Terminals report Ctrl+Enter and Shift+Enter as plain Enter, so only keys
+// that arrive distinctly are offered.
+func SubmitKeys() []string {
+	return []string{"enter", "alt+enter", "ctrl+j"}
+}
+
+// LowBandwidthModes returns the values LowBandwidthMode accepts
+func LowBandwidthModes() []string {
+	return []string{"auto", "on", "off"}
+}
+
 // ConfigManager handles configuration storage and retrieval
 type ConfigManager struct {
 	configDir  string
 	configFile string
 	logger     *log.Logger
+
+	// recovery is set once a config file that couldn't be read was set aside
+	recovery *ConfigRecovery
+}
+
+// ConfigRecovery describes a config file that couldn't be read as it was.
+// The original is kept at BackupFile; Partial is set when the readable
+// settings were kept and only the rest reset to defaults.
+type ConfigRecovery struct {
+	BackupFile string
+	Partial    bool
+	Err        error
+}
+
+// Notice tells the user what happened to their config file
+func (r *ConfigRecovery) Notice() string {
+	if r.Partial {
+		return fmt.Sprintf("Some settings in the config file were invalid and were reset · original saved as %s", r.BackupFile)
+	}
+	return fmt.Sprintf("Config file was unreadable, using defaults · original saved as %s", r.BackupFile)
+}
+
+// BaseURLFor returns the API base URL override for provider, or "" to use
+// the provider's default endpoint. A per-provider entry wins; the general
+// BaseURL applies to the default provider only, since one gateway rarely
+// serves every provider's API.
+func (c *Config) BaseURLFor(provider string) string {
+	if c == nil {
+		return ""
+	}
+	if baseURL := strings.TrimSpace(c.BaseURLs[provider]); baseURL != "" {
+		return baseURL
+	}
+	if provider == c.DefaultProvider {
+		return strings.TrimSpace(c.BaseURL)
+	}
+	return ""
+}
+
+// ValidateBaseURL checks that a base URL override is empty or an absolute
+// http or https URL
+func ValidateBaseURL(raw string) error {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("base URL must start with http:// or https://")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("base URL must include a host")
+	}
+	return nil
+}
+
+// ValidateProxyURL checks that a proxy setting is empty or an absolute
+// http, https or socks5 URL, optionally with credentials
+func ValidateProxyURL(raw string) error {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("proxy URL must start with http://, https:// or socks5://")
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("proxy URL must include a host")
+	}
+	return nil
+}
+
+// ConfigFileEnv names the environment variable that points klip at a
+// config file, as --config does
+const ConfigFileEnv = "KLIP_CONFIG"
+
+// configFileOverride is the config file set by SetConfigFile, if any
+var configFileOverride string
+
+// SetConfigFile makes config managers created afterwards read and write
+// path instead of ~/.klip/config.json, so profiles can keep their own
+// settings. A directory holds config.json; a file that doesn't exist yet
+// is created with defaults on first load. Keys and history stay in ~/.klip.
+// An empty path restores the default. It returns the file that will be used.
+func SetConfigFile(path string) (string, error) {
+	if path == "" {
+		configFileOverride = ""
+		return "", nil
+	}
+
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid config path: %w", err)
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, "config.json")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	configFileOverride = path
+	return path, nil
+}
+
+// ConfigFileOverride returns the config file set by SetConfigFile, or ""
+// when the default is in use
+func ConfigFileOverride() string {
+	return configFileOverride
 }
 
 // NewConfigManager creates a new ConfigManager instance
@@ -112,27 +376,28 @@ func NewConfigManager() (*ConfigManager, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get config directory: %w", err)
 	}
+	return newConfigManagerIn(configDir), nil
+}
+
+// newConfigManagerIn creates a ConfigManager for the config in configDir,
+// or for the file set by SetConfigFile
+func newConfigManagerIn(configDir string) *ConfigManager {
+	configFile := filepath.Join(configDir, "config.json")
+	if configFileOverride != "" {
+		configDir, configFile = filepath.Dir(configFileOverride), configFileOverride
+	}
 
 	return &ConfigManager{
 		configDir:  configDir,
-		configFile: filepath.Join(configDir, "config.json"),
+		configFile: configFile,
 		logger:     log.New(os.Stderr),
-	}, nil
+	}
 }
 
-// GetConfigDir returns the configuration directory path
+// GetConfigDir returns the configuration directory path, that of the
+// active profile when one is set
 func GetConfigDir() (string, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", fmt.Errorf("failed to get home directory: %w", err)
-	}
-
-	configDir := filepath.Join(homeDir, ".klip")
-	if err := os.MkdirAll(configDir, 0700); err != nil {
-		return "", fmt.Errorf("failed to create config directory: %w", err)
-	}
-
-	return configDir, nil
+	return ProfileDir(ActiveProfile())
 }
 
 // LoadConfig loads the application configuration
@@ -154,7 +419,7 @@ func (cm *ConfigManager) LoadConfig() (*Config, error) {
 
 	var config Config
 	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+		return cm.recoverConfig(&config, err)
 	}
 
 	// Ensure config has all required fields with defaults
@@ -163,6 +428,38 @@ func (cm *ConfigManager) LoadConfig() (*Config, error) {
 	return &config, nil
 }
 
+// recoverConfig deals with a config file that failed to parse. Values of
+// the wrong type leave the rest of the file readable, so those settings are
+// kept; anything else starts over from defaults. Either way the original
+// is backed up before the repaired config replaces it.
+func (cm *ConfigManager) recoverConfig(config *Config, parseErr error) (*Config, error) {
+	var typeErr *json.UnmarshalTypeError
+	partial := errors.As(parseErr, &typeErr)
+	if partial {
+		cm.applyDefaults(config)
+	} else {
+		config = cm.getDefaultConfig()
+	}
+
+	backupFile := fmt.Sprintf("%s.corrupt-%s", cm.configFile, time.Now().Format("20060102-150405"))
+	if err := os.Rename(cm.configFile, backupFile); err != nil {
+		return nil, fmt.Errorf("failed to parse config file (%v) or back it up: %w", parseErr, err)
+	}
+	cm.logger.Warn("Config file couldn't be read, backed it up", "backup", backupFile, "partial", partial, "error", parseErr)
+
+	if err := cm.SaveConfig(config); err != nil {
+		cm.logger.Warn("Failed to save repaired config", "error", err)
+	}
+	cm.recovery = &ConfigRecovery{BackupFile: backupFile, Partial: partial, Err: parseErr}
+	return config, nil
+}
+
+// Recovery reports the config file this manager had to set aside, or nil
+// when it loaded cleanly
+func (cm *ConfigManager) Recovery() *ConfigRecovery {
+	return cm.recovery
+}
+
 // SaveConfig saves the application configuration
 func (cm *ConfigManager) SaveConfig(config *Config) error {
 	data, err := json.MarshalIndent(config, "", "  ")
@@ -215,7 +512,14 @@ func (cm *ConfigManager) getDefaultConfig() *Config {
 			RetainDays:    30,
 			MaxFileSizeMB: 50,
 		},
-		CustomPreferences: make(map[string]interface{}),
+		CustomPreferences:   make(map[string]interface{}),
+		StatusBarSections:   DefaultStatusBarSections(),
+		SpinnerStyle:        "braille",
+		SubmitKey:           "enter",
+		SaveDrafts:          true,
+		ShowTypingIndicator: true,
+		ShowMessageUsage:    true,
+		LowBandwidthMode:    "auto",
 	}
 }
 
@@ -274,6 +578,31 @@ func (cm *ConfigManager) applyDefaults(config *Config) {
 	if config.CustomPreferences == nil {
 		config.CustomPreferences = make(map[string]interface{})
 	}
+
+	if len(config.StatusBarSections) == 0 {
+		config.StatusBarSections = DefaultStatusBarSections()
+	}
+
+	if config.SpinnerStyle == "" {
+		config.SpinnerStyle = "braille"
+	}
+
+	switch config.SubmitKey {
+	case "":
+		config.SubmitKey = "enter"
+	case "ctrl+enter", "shift+enter":
+		// Earlier versions offered these, but no key press ever matched them
+		config.SubmitKey = "alt+enter"
+	}
+
+	if config.LegacySpellCheck {
+		config.HighlightTypos = true
+		config.LegacySpellCheck = false
+	}
+
+	if config.LowBandwidthMode == "" {
+		config.LowBandwidthMode = "auto"
+	}
 }
 
 // UpdateProvider updates the default provider
@@ -328,7 +657,12 @@ func (cm *ConfigManager) MigrateFromDeno() error {
 		return fmt.Errorf("failed to get home directory: %w", err)
 	}
 
+	// The Deno config only ever lived in ~/.klip; a profile or an alternate
+	// config file must not take it over
 	denoConfigFile := filepath.Join(homeDir, ".klip", "config.json")
+	if cm.configFile != denoConfigFile {
+		return nil
+	}
 	if _, err := os.Stat(denoConfigFile); os.IsNotExist(err) {
 		cm.logger.Info("No Deno config found to migrate")
 		return nil
@@ -405,15 +739,7 @@ func (cm *ConfigManager) Validate(config *Config) error {
 	}
 
 	// Validate provider is supported
-	supportedProviders := []string{"anthropic", "openai", "openrouter"}
-	validProvider := false
-	for _, provider := range supportedProviders {
-		if config.DefaultProvider == provider {
-			validProvider = true
-			break
-		}
-	}
-	if !validProvider {
+	if !validProviderName(config.DefaultProvider) {
 		return fmt.Errorf("unsupported provider: %s", config.DefaultProvider)
 	}
 
@@ -431,6 +757,21 @@ func (cm *ConfigManager) Validate(config *Config) error {
 		}
 	}
 
+	for modelID, params := range config.ModelParameters {
+		if params.Temperature != nil {
+			provider := modelProvider(modelID, config.DefaultProvider)
+			minTemp, maxTemp := TemperatureRange(provider)
+			if *params.Temperature < minTemp || *params.Temperature > maxTemp {
+				return fmt.Errorf("temperature for %s must be between %.1f and %.1f on %s", modelID, minTemp, maxTemp, provider)
+			}
+		}
+		if params.MaxTokens != nil {
+			if *params.MaxTokens < 1 || *params.MaxTokens > 200000 {
+				return fmt.Errorf("max tokens for %s must be between 1 and 200000", modelID)
+			}
+		}
+	}
+
 	// Validate analytics settings
 	if config.Analytics != nil {
 		if config.Analytics.RetainDays < 1 {
@@ -463,5 +804,116 @@ func (cm *ConfigManager) Validate(config *Config) error {
 		}
 	}
 
+	// Validate base URL overrides
+	if err := ValidateBaseURL(config.BaseURL); err != nil {
+		return err
+	}
+	for provider, baseURL := range config.BaseURLs {
+		if !validProviderName(provider) {
+			return fmt.Errorf("base URL set for unsupported provider: %s", provider)
+		}
+		if err := ValidateBaseURL(baseURL); err != nil {
+			return fmt.Errorf("%s %w", provider, err)
+		}
+	}
+
+	// Validate proxy settings
+	if err := ValidateProxyURL(config.HTTPProxy); err != nil {
+		return fmt.Errorf("HTTP proxy: %w", err)
+	}
+	if err := ValidateProxyURL(config.HTTPSProxy); err != nil {
+		return fmt.Errorf("HTTPS proxy: %w", err)
+	}
+
+	// Validate status bar sections
+	for _, section := range config.StatusBarSections {
+		valid := false
+		for _, known := range DefaultStatusBarSections() {
+			if section == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("unknown status bar section: %s", section)
+		}
+	}
+
+	// Validate spinner style
+	if config.SpinnerStyle != "" {
+		valid := false
+		for _, style := range SpinnerStyles() {
+			if config.SpinnerStyle == style {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("unknown spinner style: %s", config.SpinnerStyle)
+		}
+	}
+
+	// Validate submit key
+	if config.SubmitKey != "" {
+		valid := false
+		for _, k := range SubmitKeys() {
+			if config.SubmitKey == k {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("unknown submit key: %s", config.SubmitKey)
+		}
+	}
+
+	// Validate low bandwidth mode
+	if config.LowBandwidthMode != "" {
+		valid := false
+		for _, mode := range LowBandwidthModes() {
+			if config.LowBandwidthMode == mode {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("unknown low bandwidth mode: %s", config.LowBandwidthMode)
+		}
+	}
+
 	return nil
 }
+
+// TemperatureRange returns the lowest and highest temperature a provider accepts
+func TemperatureRange(provider string) (float64, float64) {
+	if provider == "anthropic" {
+		return 0, 1
+	}
+	return 0, 2
+}
+
+// modelProvider names the provider that serves modelID. OpenRouter IDs are
+// namespaced ("vendor/model"); other IDs are recognised by their family,
+// falling back to the default provider.
+func modelProvider(modelID, defaultProvider string) string {
+	switch {
+	case strings.Contains(modelID, "/"):
+		return "openrouter"
+	case strings.HasPrefix(modelID, "claude"):
+		return "anthropic"
+	case strings.HasPrefix(modelID, "gpt-"), strings.HasPrefix(modelID, "chatgpt"),
+		strings.HasPrefix(modelID, "o1"), strings.HasPrefix(modelID, "o3"), strings.HasPrefix(modelID, "o4"):
+		return "openai"
+	}
+	return defaultProvider
+}
+
+// validProviderName reports whether provider is one klip can talk to
+func validProviderName(provider string) bool {
+	for _, supported := range []string{"anthropic", "openai", "openrouter"} {
+		if provider == supported {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// dictionaryPath returns the location of the personal dictionary
+func dictionaryPath() (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(configDir, "dictionary.txt"), nil
+}
+
+// LoadDictionary returns the words the user told typo highlighting to accept,
+// one per line in dictionary.txt
+func LoadDictionary() ([]string, error) {
+	path, err := dictionaryPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dictionary: %w", err)
+	}
+
+	var words []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if word := strings.TrimSpace(line); word != "" && !strings.HasPrefix(word, "#") {
+			words = append(words, word)
+		}
+	}
+	return words, nil
+}
+
+// AddDictionaryWord adds a word to the personal dictionary, keeping it sorted
+// and free of duplicates
+func AddDictionaryWord(word string) error {
+	word = strings.TrimSpace(word)
+	if word == "" || strings.ContainsAny(word, " \t\n") {
+		return fmt.Errorf("invalid dictionary word: %q", word)
+	}
+
+	words, err := LoadDictionary()
+	if err != nil {
+		return err
+	}
+	for _, existing := range words {
+		if strings.EqualFold(existing, word) {
+			return nil
+		}
+	}
+
+	words = append(words, word)
+	sort.Strings(words)
+
+	path, err := dictionaryPath()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(words, "\n")+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write dictionary: %w", err)
+	}
+	return nil
+}
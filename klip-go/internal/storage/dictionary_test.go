@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestDictionary_AddAndLoad(t *testing.T) {
+	tempDir := t.TempDir()
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", tempDir)
+	t.Cleanup(func() {
+		os.Setenv("HOME", oldHome)
+	})
+
+	words, err := LoadDictionary()
+	if err != nil {
+		t.Fatalf("Failed to load missing dictionary: %v", err)
+	}
+	if len(words) != 0 {
+		t.Errorf("Expected empty dictionary, got %v", words)
+	}
+
+	for _, word := range []string{"klip", "bubbletea", "Klip"} {
+		if err := AddDictionaryWord(word); err != nil {
+			t.Fatalf("Failed to add %q: %v", word, err)
+		}
+	}
+	if err := AddDictionaryWord("two words"); err == nil {
+		t.Error("Expected error for a word containing spaces")
+	}
+
+	words, err = LoadDictionary()
+	if err != nil {
+		t.Fatalf("Failed to load dictionary: %v", err)
+	}
+	if expected := []string{"bubbletea", "klip"}; !reflect.DeepEqual(words, expected) {
+		t.Errorf("Expected %v, got %v", expected, words)
+	}
+}
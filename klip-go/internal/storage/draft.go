@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Draft is unsent composer text kept on disk so it survives a crash or quit
+type Draft struct {
+	SessionID string    `json:"session_id,omitempty"`
+	Content   string    `json:"content"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// draftPath returns the location of the saved draft
+func draftPath() (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(configDir, "draft"), nil
+}
+
+// SaveDraft stores the composer text for a session, replacing any previous
+// draft. Saving empty text removes the draft.
+func SaveDraft(sessionID, content string) error {
+	if content == "" {
+		return ClearDraft()
+	}
+
+	path, err := draftPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(Draft{
+		SessionID: sessionID,
+		Content:   content,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal draft: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write draft: %w", err)
+	}
+
+	return nil
+}
+
+// LoadDraft returns the saved draft for a session, or an empty string if
+// there is none or it belongs to a different session
+func LoadDraft(sessionID string) (string, error) {
+	path, err := draftPath()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read draft: %w", err)
+	}
+
+	var draft Draft
+	if err := json.Unmarshal(data, &draft); err != nil {
+		return "", fmt.Errorf("failed to parse draft: %w", err)
+	}
+
+	if draft.SessionID != sessionID {
+		return "", nil
+	}
+	return draft.Content, nil
+}
+
+// ClearDraft removes the saved draft, if any
+func ClearDraft() error {
+	path, err := draftPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove draft: %w", err)
+	}
+	return nil
+}
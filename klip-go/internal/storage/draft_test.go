@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func setupTestDraft(t *testing.T) string {
+	tempDir := t.TempDir()
+
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", tempDir)
+	t.Cleanup(func() {
+		os.Setenv("HOME", oldHome)
+	})
+
+	return tempDir
+}
+
+func TestDraft_SaveRestoreClear(t *testing.T) {
+	tempDir := setupTestDraft(t)
+
+	if err := SaveDraft("session-1", "half-written question"); err != nil {
+		t.Fatalf("Failed to save draft: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(tempDir, ".klip", "draft"))
+	if err != nil {
+		t.Fatalf("Expected draft file to exist: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected draft permissions 0600, got %o", info.Mode().Perm())
+	}
+
+	content, err := LoadDraft("session-1")
+	if err != nil {
+		t.Fatalf("Failed to load draft: %v", err)
+	}
+	if content != "half-written question" {
+		t.Errorf("Expected restored draft, got %q", content)
+	}
+
+	// A different conversation does not pick up the draft
+	content, err = LoadDraft("session-2")
+	if err != nil {
+		t.Fatalf("Failed to load draft: %v", err)
+	}
+	if content != "" {
+		t.Errorf("Expected no draft for another session, got %q", content)
+	}
+
+	if err := ClearDraft(); err != nil {
+		t.Fatalf("Failed to clear draft: %v", err)
+	}
+	content, err = LoadDraft("session-1")
+	if err != nil {
+		t.Fatalf("Failed to load draft after clearing: %v", err)
+	}
+	if content != "" {
+		t.Errorf("Expected no draft after clearing, got %q", content)
+	}
+
+	// Clearing twice is not an error
+	if err := ClearDraft(); err != nil {
+		t.Errorf("Expected clearing a missing draft to succeed, got %v", err)
+	}
+}
+
+func TestDraft_SaveEmptyRemoves(t *testing.T) {
+	tempDir := setupTestDraft(t)
+
+	if err := SaveDraft("", "draft"); err != nil {
+		t.Fatalf("Failed to save draft: %v", err)
+	}
+	if err := SaveDraft("", ""); err != nil {
+		t.Fatalf("Failed to save empty draft: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, ".klip", "draft")); !os.IsNotExist(err) {
+		t.Error("Expected empty draft to remove the draft file")
+	}
+}
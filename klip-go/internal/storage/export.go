@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ExportManifestFile is the name of the index written alongside bulk exports
+const ExportManifestFile = "manifest.json"
+
+// ExportManifest describes the contents of a bulk export directory
+type ExportManifest struct {
+	ExportedAt time.Time             `json:"exported_at"`
+	Format     string                `json:"format"`
+	Count      int                   `json:"count"`
+	Sessions   []ExportManifestEntry `json:"sessions"`
+}
+
+// ExportManifestEntry records which file holds which session
+type ExportManifestEntry struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title,omitempty"`
+	File      string    `json:"file"`
+	Messages  int       `json:"messages"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ExportAllOptions controls optional behavior of a bulk export
+type ExportAllOptions struct {
+	// Zip additionally archives the directory as <dir>.zip
+	Zip bool
+	// Progress is called after each session is written
+	Progress func(done, total int, title string)
+}
+
+// ExportAll writes one file per session into dir, plus a manifest
+func ExportAll(sessions []ChatSession, format, dir string) error {
+	return ExportAllWithOptions(sessions, format, dir, ExportAllOptions{})
+}
+
+// ExportAllWithOptions writes one file per session into dir, plus a manifest,
+// reporting progress and zipping the result when requested
+func ExportAllWithOptions(sessions []ChatSession, format, dir string, opts ExportAllOptions) error {
+	// Validate the format before touching the filesystem
+	if _, _, err := formatSession(&ChatLog{}, format); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	manifest := ExportManifest{
+		ExportedAt: time.Now(),
+		Format:     format,
+		Sessions:   make([]ExportManifestEntry, 0, len(sessions)),
+	}
+	used := map[string]bool{ExportManifestFile: true}
+
+	for i, session := range sessions {
+		data, ext, err := formatSession(chatLogFromSession(session), format)
+		if err != nil {
+			return err
+		}
+
+		filename := uniqueExportName(dir, exportBaseName(session), ext, used)
+		if err := os.WriteFile(filepath.Join(dir, filename), data, 0600); err != nil {
+			return fmt.Errorf("failed to write export file: %w", err)
+		}
+
+		manifest.Sessions = append(manifest.Sessions, ExportManifestEntry{
+			ID:        session.ID,
+			Title:     session.Title,
+			File:      filename,
+			Messages:  len(session.Messages),
+			CreatedAt: session.CreatedAt,
+		})
+
+		if opts.Progress != nil {
+			opts.Progress(i+1, len(sessions), session.Title)
+		}
+	}
+	manifest.Count = len(manifest.Sessions)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ExportManifestFile), data, 0600); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	if opts.Zip {
+		if _, err := ZipDirectory(dir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteShareFile saves a conversation prepared for sharing as a Markdown file
+// in dir, never overwriting an earlier one, and returns its path
+func WriteShareFile(dir, content string) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create share directory: %w", err)
+	}
+
+	base := "klip-share-" + time.Now().Format("2006-01-02-15-04-05")
+	path := filepath.Join(dir, uniqueExportName(dir, base, "md", map[string]bool{}))
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		return "", fmt.Errorf("failed to write share file: %w", err)
+	}
+	return path, nil
+}
+
+// exportBaseName names a session file by its date and sanitized title
+func exportBaseName(session ChatSession) string {
+	date := session.CreatedAt
+	if date.IsZero() {
+		date = session.StartTime
+	}
+
+	title := sanitizeFilename(session.Title)
+	if title == "" {
+		title = sanitizeFilename(session.ID)
+	}
+	if title == "" {
+		title = "session"
+	}
+
+	if date.IsZero() {
+		return title
+	}
+	return date.Format("2006-01-02") + "-" + title
+}
+
+// uniqueExportName appends a counter until the name is unused in this export
+// and does not clobber a file already in the directory
+func uniqueExportName(dir, base, ext string, used map[string]bool) string {
+	name := base + "." + ext
+	for n := 2; used[name] || fileExists(filepath.Join(dir, name)); n++ {
+		name = fmt.Sprintf("%s-%d.%s", base, n, ext)
+	}
+	used[name] = true
+	return name
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// sanitizeFilename lowercases a title and reduces it to a portable slug
+func sanitizeFilename(title string) string {
+	const maxLength = 60
+
+	var builder strings.Builder
+	lastDash := true
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			builder.WriteRune(r)
+			lastDash = false
+		case !lastDash:
+			builder.WriteRune('-')
+			lastDash = true
+		}
+		if builder.Len() >= maxLength {
+			break
+		}
+	}
+
+	return strings.Trim(builder.String(), "-")
+}
+
+// ZipDirectory archives dir as <dir>.zip and returns the archive path
+func ZipDirectory(dir string) (string, error) {
+	dir = filepath.Clean(dir)
+	zipPath := dir + ".zip"
+
+	file, err := os.OpenFile(zipPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	root := filepath.Base(dir)
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		writer, err := archive.Create(filepath.ToSlash(filepath.Join(root, rel)))
+		if err != nil {
+			return err
+		}
+
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		_, err = io.Copy(writer, src)
+		return err
+	})
+	if err != nil {
+		archive.Close()
+		return "", fmt.Errorf("failed to archive export: %w", err)
+	}
+
+	if err := archive.Close(); err != nil {
+		return "", fmt.Errorf("failed to finalize archive: %w", err)
+	}
+	return zipPath, nil
+}
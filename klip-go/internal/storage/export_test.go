@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExportAll_WritesFilesAndManifest(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	sessions := []ChatSession{
+		{ID: "s1", Title: "Go: slices & maps?", CreatedAt: created,
+			Messages: []Message{{Role: "user", Content: "hi"}}},
+		{ID: "s2", Title: "Go: slices & maps?", CreatedAt: created,
+			Messages: []Message{{Role: "user", Content: "again"}}},
+		{ID: "s3", CreatedAt: created.Add(24 * time.Hour),
+			Messages: []Message{{Role: "assistant", Content: "untitled"}}},
+	}
+
+	dir := filepath.Join(t.TempDir(), "export")
+	var progress []int
+	err := ExportAllWithOptions(sessions, "markdown", dir, ExportAllOptions{
+		Zip: true,
+		Progress: func(done, total int, title string) {
+			progress = append(progress, done)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to export: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read export directory: %v", err)
+	}
+	if len(entries) != 4 {
+		t.Errorf("Expected 3 session files and a manifest, got %d entries", len(entries))
+	}
+	if len(progress) != 3 || progress[2] != 3 {
+		t.Errorf("Expected progress for each session, got %v", progress)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ExportManifestFile))
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+
+	var manifest ExportManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("Failed to parse manifest: %v", err)
+	}
+	if manifest.Count != 3 || manifest.Format != "markdown" {
+		t.Errorf("Unexpected manifest header: %+v", manifest)
+	}
+
+	expected := []string{
+		"2024-05-01-go-slices-maps.md",
+		"2024-05-01-go-slices-maps-2.md",
+		"2024-05-02-s3.md",
+	}
+	for i, entry := range manifest.Sessions {
+		if entry.File != expected[i] {
+			t.Errorf("Expected file %q for %s, got %q", expected[i], entry.ID, entry.File)
+		}
+		if _, err := os.Stat(filepath.Join(dir, entry.File)); err != nil {
+			t.Errorf("Manifest references missing file %q", entry.File)
+		}
+	}
+
+	archive, err := zip.OpenReader(dir + ".zip")
+	if err != nil {
+		t.Fatalf("Failed to open archive: %v", err)
+	}
+	defer archive.Close()
+	if len(archive.File) != 4 {
+		t.Errorf("Expected 4 files in archive, got %d", len(archive.File))
+	}
+}
+
+func TestExportAll_RejectsUnknownFormat(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "export")
+	if err := ExportAll([]ChatSession{{ID: "s1"}}, "pdf", dir); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("Expected no directory to be created for an unsupported format")
+	}
+}
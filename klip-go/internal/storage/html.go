@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// htmlStyles keeps HTML exports self-contained; role colors match the chat
+// view's message headers
+const htmlStyles = `body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 48rem; margin: 2rem auto; padding: 0 1rem; color: #1F2937; line-height: 1.5; }
+header { border-bottom: 1px solid #E5E7EB; margin-bottom: 1.5rem; }
+header p { color: #6B7280; margin: 0.25rem 0; }
+.message { margin-bottom: 1.5rem; }
+.message h2 { font-size: 1rem; margin: 0 0 0.5rem; }
+.message h2 time { color: #9CA3AF; font-weight: normal; font-size: 0.85rem; margin-left: 0.5rem; }
+.role-user h2 { color: #2563EB; }
+.role-assistant h2 { color: #7C3AED; }
+.role-system h2 { color: #D97706; }
+pre { background: #F3F4F6; border-radius: 6px; padding: 0.75rem; overflow-x: auto; }
+code { font-family: "SFMono-Regular", Menlo, Consolas, monospace; font-size: 0.9em; }
+`
+
+// formatAsHTML formats a session as a standalone HTML page. Message bodies
+// keep their paragraphs, fenced code blocks and inline code; other Markdown
+// is shown as written.
+func formatAsHTML(session *ChatLog) string {
+	var builder strings.Builder
+
+	title := session.Title
+	if title == "" {
+		title = "Chat Log - " + session.Timestamp.Format("2006-01-02 15:04:05")
+	}
+
+	builder.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<meta charset=\"utf-8\">\n")
+	builder.WriteString(fmt.Sprintf("<title>%s</title>\n<style>\n%s</style>\n</head>\n<body>\n", html.EscapeString(title), htmlStyles))
+
+	builder.WriteString(fmt.Sprintf("<header>\n<h1>%s</h1>\n", html.EscapeString(title)))
+	builder.WriteString(fmt.Sprintf("<p>Session ID: %s</p>\n", html.EscapeString(session.SessionID)))
+	if session.ModelUsed != "" {
+		model := session.ModelUsed
+		if session.ProviderUsed != "" {
+			model += " (" + session.ProviderUsed + ")"
+		}
+		builder.WriteString(fmt.Sprintf("<p>Model: %s</p>\n", html.EscapeString(model)))
+	}
+	if session.TotalTokens > 0 {
+		builder.WriteString(fmt.Sprintf("<p>Total Tokens: %d</p>\n", session.TotalTokens))
+	}
+	builder.WriteString("</header>\n")
+
+	for _, message := range session.Messages {
+		role := strings.ToLower(message.Role)
+		builder.WriteString(fmt.Sprintf("<section class=\"message role-%s\">\n", html.EscapeString(role)))
+		builder.WriteString(fmt.Sprintf("<h2>%s", html.EscapeString(strings.Title(role))))
+		if !message.Timestamp.IsZero() {
+			builder.WriteString(fmt.Sprintf("<time>%s</time>", message.Timestamp.Format("2006-01-02 15:04:05")))
+		}
+		builder.WriteString("</h2>\n")
+		writeHTMLBody(&builder, message.Content)
+		builder.WriteString("</section>\n")
+	}
+
+	builder.WriteString("</body>\n</html>\n")
+	return builder.String()
+}
+
+// writeHTMLBody converts message Markdown into paragraphs and code blocks
+func writeHTMLBody(builder *strings.Builder, content string) {
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			builder.WriteString("<p>" + strings.Join(paragraph, "<br>\n") + "</p>\n")
+			paragraph = nil
+		}
+	}
+
+	lines := strings.Split(content, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			flush()
+			language := strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, html.EscapeString(lines[i]))
+			}
+
+			class := ""
+			if language != "" {
+				class = fmt.Sprintf(" class=\"language-%s\"", html.EscapeString(language))
+			}
+			builder.WriteString(fmt.Sprintf("<pre><code%s>%s</code></pre>\n", class, strings.Join(code, "\n")))
+			continue
+		}
+
+		if trimmed == "" {
+			flush()
+			continue
+		}
+		paragraph = append(paragraph, inlineCodeHTML(line))
+	}
+	flush()
+}
+
+// inlineCodeHTML escapes a line, wrapping `code` spans in <code>
+func inlineCodeHTML(line string) string {
+	parts := strings.Split(line, "`")
+	if len(parts)%2 == 0 {
+		// Unbalanced backticks are shown literally
+		return html.EscapeString(line)
+	}
+
+	var builder strings.Builder
+	for i, part := range parts {
+		if i%2 == 1 {
+			builder.WriteString("<code>" + html.EscapeString(part) + "</code>")
+		} else {
+			builder.WriteString(html.EscapeString(part))
+		}
+	}
+	return builder.String()
+}
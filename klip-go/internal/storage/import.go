@@ -0,0 +1,372 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ImportFormat identifies the web UI a conversation export came from
+type ImportFormat string
+
+const (
+	ImportFormatOpenAI    ImportFormat = "openai"
+	ImportFormatAnthropic ImportFormat = "anthropic"
+)
+
+// ImportResult summarizes an import run
+type ImportResult struct {
+	Format   ImportFormat
+	Imported int
+	Skipped  int
+}
+
+// openAIConversation is one entry of ChatGPT's conversations.json
+type openAIConversation struct {
+	ID          string                `json:"id"`
+	Title       string                `json:"title"`
+	CreateTime  float64               `json:"create_time"`
+	UpdateTime  float64               `json:"update_time"`
+	CurrentNode string                `json:"current_node"`
+	Mapping     map[string]openAINode `json:"mapping"`
+}
+
+type openAINode struct {
+	ID       string         `json:"id"`
+	Parent   string         `json:"parent"`
+	Children []string       `json:"children"`
+	Message  *openAIMessage `json:"message"`
+}
+
+type openAIMessage struct {
+	Author struct {
+		Role string `json:"role"`
+	} `json:"author"`
+	CreateTime float64 `json:"create_time"`
+	Content    struct {
+		ContentType string            `json:"content_type"`
+		Parts       []json.RawMessage `json:"parts"`
+	} `json:"content"`
+	Metadata struct {
+		ModelSlug string `json:"model_slug"`
+	} `json:"metadata"`
+}
+
+// anthropicConversation is one entry of Claude's conversations.json
+type anthropicConversation struct {
+	UUID         string             `json:"uuid"`
+	Name         string             `json:"name"`
+	Model        string             `json:"model"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+	ChatMessages []anthropicMessage `json:"chat_messages"`
+}
+
+type anthropicMessage struct {
+	UUID      string    `json:"uuid"`
+	Sender    string    `json:"sender"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+	Content   []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+}
+
+// ParseConversationExport detects the export format and converts it to sessions
+func ParseConversationExport(data []byte) ([]ChatSession, ImportFormat, error) {
+	var probe []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, "", fmt.Errorf("failed to parse export: %w", err)
+	}
+
+	for _, entry := range probe {
+		if _, ok := entry["mapping"]; ok {
+			sessions, err := ParseOpenAIExport(data)
+			return sessions, ImportFormatOpenAI, err
+		}
+		if _, ok := entry["chat_messages"]; ok {
+			sessions, err := ParseAnthropicExport(data)
+			return sessions, ImportFormatAnthropic, err
+		}
+	}
+
+	if len(probe) == 0 {
+		return nil, "", fmt.Errorf("export contains no conversations")
+	}
+	return nil, "", fmt.Errorf("unrecognized export format")
+}
+
+// ParseOpenAIExport converts ChatGPT's conversations.json into sessions
+func ParseOpenAIExport(data []byte) ([]ChatSession, error) {
+	var conversations []openAIConversation
+	if err := json.Unmarshal(data, &conversations); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAI export: %w", err)
+	}
+
+	var sessions []ChatSession
+	for _, conv := range conversations {
+		var messages []Message
+		for _, node := range openAIThread(conv) {
+			if node.Message == nil {
+				continue
+			}
+
+			role := node.Message.Author.Role
+			if role != "user" && role != "assistant" {
+				continue
+			}
+
+			content := openAIText(node.Message)
+			if strings.TrimSpace(content) == "" {
+				continue
+			}
+
+			message := Message{
+				Role:      role,
+				Content:   content,
+				Timestamp: unixSeconds(node.Message.CreateTime),
+			}
+			if role == "assistant" {
+				message.Model = node.Message.Metadata.ModelSlug
+				message.Provider = string(ImportFormatOpenAI)
+			}
+			messages = append(messages, message)
+		}
+
+		if len(messages) == 0 {
+			continue
+		}
+
+		session := importedSession(conv.Title, messages, unixSeconds(conv.CreateTime), unixSeconds(conv.UpdateTime))
+		session.Provider = string(ImportFormatOpenAI)
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+// openAIThread walks the mapping tree from the current node back to the root,
+// returning the branch the user last saw in chronological order
+func openAIThread(conv openAIConversation) []openAINode {
+	current := conv.CurrentNode
+	if _, ok := conv.Mapping[current]; !ok {
+		current = openAILatestLeaf(conv.Mapping)
+	}
+
+	var thread []openAINode
+	seen := make(map[string]bool)
+	for current != "" && !seen[current] {
+		node, ok := conv.Mapping[current]
+		if !ok {
+			break
+		}
+		seen[current] = true
+		thread = append(thread, node)
+		current = node.Parent
+	}
+
+	for i, j := 0, len(thread)-1; i < j; i, j = i+1, j-1 {
+		thread[i], thread[j] = thread[j], thread[i]
+	}
+	return thread
+}
+
+// openAILatestLeaf picks the newest leaf for exports without a current node
+func openAILatestLeaf(mapping map[string]openAINode) string {
+	var latest string
+	var latestTime float64 = -1
+	for id, node := range mapping {
+		if len(node.Children) > 0 {
+			continue
+		}
+		created := 0.0
+		if node.Message != nil {
+			created = node.Message.CreateTime
+		}
+		if created > latestTime || (created == latestTime && id > latest) {
+			latest, latestTime = id, created
+		}
+	}
+	return latest
+}
+
+// openAIText joins the text parts of a message, ignoring attachments
+func openAIText(message *openAIMessage) string {
+	var parts []string
+	for _, raw := range message.Content.Parts {
+		var text string
+		if err := json.Unmarshal(raw, &text); err == nil && text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// ParseAnthropicExport converts Claude's conversations.json into sessions
+func ParseAnthropicExport(data []byte) ([]ChatSession, error) {
+	var conversations []anthropicConversation
+	if err := json.Unmarshal(data, &conversations); err != nil {
+		return nil, fmt.Errorf("failed to parse Anthropic export: %w", err)
+	}
+
+	var sessions []ChatSession
+	for _, conv := range conversations {
+		var messages []Message
+		for _, msg := range conv.ChatMessages {
+			role := "user"
+			if msg.Sender == "assistant" {
+				role = "assistant"
+			} else if msg.Sender != "human" {
+				continue
+			}
+
+			content := anthropicText(msg)
+			if strings.TrimSpace(content) == "" {
+				continue
+			}
+
+			message := Message{
+				Role:      role,
+				Content:   content,
+				Timestamp: msg.CreatedAt,
+			}
+			if role == "assistant" {
+				message.Model = conv.Model
+				message.Provider = string(ImportFormatAnthropic)
+			}
+			messages = append(messages, message)
+		}
+
+		if len(messages) == 0 {
+			continue
+		}
+
+		session := importedSession(conv.Name, messages, conv.CreatedAt, conv.UpdatedAt)
+		session.Provider = string(ImportFormatAnthropic)
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+// anthropicText prefers the plain text field and falls back to text blocks
+func anthropicText(msg anthropicMessage) string {
+	if msg.Text != "" {
+		return msg.Text
+	}
+
+	var parts []string
+	for _, block := range msg.Content {
+		if block.Type == "text" && block.Text != "" {
+			parts = append(parts, block.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// importedSession builds a session with a stable, content-derived ID
+func importedSession(title string, messages []Message, created, updated time.Time) ChatSession {
+	if created.IsZero() {
+		created = messages[0].Timestamp
+	}
+	if updated.IsZero() {
+		updated = messages[len(messages)-1].Timestamp
+	}
+
+	session := ChatSession{
+		StartTime: created,
+		EndTime:   updated,
+		CreatedAt: created,
+		UpdatedAt: updated,
+		Messages:  messages,
+		Title:     title,
+	}
+	for _, msg := range messages {
+		if msg.Model != "" {
+			session.Model = msg.Model
+			break
+		}
+	}
+
+	session.ID = "import-" + SessionContentHash(messages)[:16]
+	return session
+}
+
+// SessionContentHash fingerprints a conversation by its roles and content so
+// the same history imported twice, or already logged by klip, is recognized
+func SessionContentHash(messages []Message) string {
+	hash := sha256.New()
+	for _, msg := range messages {
+		hash.Write([]byte(msg.Role))
+		hash.Write([]byte{0})
+		hash.Write([]byte(strings.TrimSpace(msg.Content)))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// unixSeconds converts the fractional epoch seconds used by OpenAI exports
+func unixSeconds(seconds float64) time.Time {
+	if seconds <= 0 {
+		return time.Time{}
+	}
+	whole, frac := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(frac*1e9)).UTC()
+}
+
+// ImportConversations reads a ChatGPT or Claude export and saves each new
+// conversation as a session log, skipping ones that already exist
+func (cl *ChatLogger) ImportConversations(path string) (*ImportResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read export file: %w", err)
+	}
+
+	sessions, format, err := ParseConversationExport(data)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := cl.ListSessions(0)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(existing))
+	for _, log := range existing {
+		if len(log.Messages) > 0 {
+			known[SessionContentHash(log.Messages)] = true
+		}
+	}
+
+	// Oldest first so the resulting files sort the same way as the originals
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+
+	result := &ImportResult{Format: format}
+	for _, session := range sessions {
+		hash := SessionContentHash(session.Messages)
+		if known[hash] {
+			result.Skipped++
+			continue
+		}
+
+		if err := cl.writeLog(chatLogFromSession(session)); err != nil {
+			return result, fmt.Errorf("failed to import %q: %w", session.Title, err)
+		}
+		known[hash] = true
+		result.Imported++
+	}
+
+	cl.logger.Info("Imported conversations", "file", path, "format", format,
+		"imported", result.Imported, "skipped", result.Skipped)
+	return result, nil
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseOpenAIExport(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "openai_conversations.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	sessions, format, err := ParseConversationExport(data)
+	if err != nil {
+		t.Fatalf("Failed to parse export: %v", err)
+	}
+	if format != ImportFormatOpenAI {
+		t.Errorf("Expected format %q, got %q", ImportFormatOpenAI, format)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("Expected 1 session, got %d", len(sessions))
+	}
+
+	session := sessions[0]
+	if session.Title != "Sorting a slice in Go" {
+		t.Errorf("Unexpected title: %q", session.Title)
+	}
+	if session.Model != "gpt-4o" {
+		t.Errorf("Expected model from the current branch, got %q", session.Model)
+	}
+	if len(session.Messages) != 2 {
+		t.Fatalf("Expected system message and abandoned branch to be dropped, got %d messages", len(session.Messages))
+	}
+	if session.Messages[0].Role != "user" || session.Messages[1].Content != "Use slices.Sort(s)." {
+		t.Errorf("Unexpected messages: %+v", session.Messages)
+	}
+	if session.Messages[0].Timestamp.Unix() != 1714564810 {
+		t.Errorf("Unexpected timestamp: %v", session.Messages[0].Timestamp)
+	}
+	if session.CreatedAt.Unix() != 1714564800 {
+		t.Errorf("Unexpected creation time: %v", session.CreatedAt)
+	}
+}
+
+func TestParseAnthropicExport(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "anthropic_conversations.json"))
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+
+	sessions, format, err := ParseConversationExport(data)
+	if err != nil {
+		t.Fatalf("Failed to parse export: %v", err)
+	}
+	if format != ImportFormatAnthropic {
+		t.Errorf("Expected format %q, got %q", ImportFormatAnthropic, format)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("Expected empty conversation to be skipped, got %d sessions", len(sessions))
+	}
+
+	session := sessions[0]
+	if session.Title != "Haiku about terminals" || session.Provider != "anthropic" {
+		t.Errorf("Unexpected session metadata: %q %q", session.Title, session.Provider)
+	}
+	if session.Messages[0].Role != "user" || session.Messages[1].Role != "assistant" {
+		t.Errorf("Expected human/assistant to map to user/assistant, got %+v", session.Messages)
+	}
+	if session.Messages[1].Content != "Green cursor blinking" {
+		t.Errorf("Expected content blocks to be used when text is empty, got %q", session.Messages[1].Content)
+	}
+}
+
+func TestParseConversationExport_Unrecognized(t *testing.T) {
+	if _, _, err := ParseConversationExport([]byte(`[{"foo": 1}]`)); err == nil {
+		t.Error("Expected error for unrecognized format")
+	}
+	if _, _, err := ParseConversationExport([]byte(`{}`)); err == nil {
+		t.Error("Expected error for non-array export")
+	}
+}
+
+func TestChatLogger_ImportConversationsDeduplicates(t *testing.T) {
+	chatLogger, _ := setupTestChatLogger(t)
+	path := filepath.Join("testdata", "anthropic_conversations.json")
+
+	result, err := chatLogger.ImportConversations(path)
+	if err != nil {
+		t.Fatalf("Failed to import: %v", err)
+	}
+	if result.Imported != 1 || result.Skipped != 0 {
+		t.Errorf("Expected 1 imported, 0 skipped, got %+v", result)
+	}
+
+	sessions, err := chatLogger.ListSessions(0)
+	if err != nil {
+		t.Fatalf("Failed to list sessions: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].Title != "Haiku about terminals" {
+		t.Fatalf("Expected imported session to be listed, got %+v", sessions)
+	}
+
+	result, err = chatLogger.ImportConversations(path)
+	if err != nil {
+		t.Fatalf("Failed to re-import: %v", err)
+	}
+	if result.Imported != 0 || result.Skipped != 1 {
+		t.Errorf("Expected re-import to be skipped, got %+v", result)
+	}
+}
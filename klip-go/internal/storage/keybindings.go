@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// KeyBindings maps action names such as "chat.copy" to the keys bound to them
+type KeyBindings map[string][]string
+
+// keyBindingsPath returns the location of keys.json
+func keyBindingsPath() (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(configDir, "keys.json"), nil
+}
+
+// LoadKeyBindings loads user keybinding overrides, returning an empty set if none exist
+func LoadKeyBindings() (KeyBindings, error) {
+	path, err := keyBindingsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return KeyBindings{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keybindings file: %w", err)
+	}
+
+	var bindings KeyBindings
+	if err := json.Unmarshal(data, &bindings); err != nil {
+		return nil, fmt.Errorf("failed to parse keybindings file: %w", err)
+	}
+	if bindings == nil {
+		bindings = KeyBindings{}
+	}
+
+	return bindings, nil
+}
+
+// SaveKeyBindings writes user keybinding overrides to keys.json
+func SaveKeyBindings(bindings KeyBindings) error {
+	path, err := keyBindingsPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(bindings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal keybindings: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write keybindings file: %w", err)
+	}
+
+	return nil
+}
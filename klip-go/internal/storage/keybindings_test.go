@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func setupTestKeyBindings(t *testing.T) string {
+	tempDir := t.TempDir()
+
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", tempDir)
+	t.Cleanup(func() {
+		os.Setenv("HOME", oldHome)
+	})
+
+	return tempDir
+}
+
+func TestKeyBindings_LoadMissing(t *testing.T) {
+	setupTestKeyBindings(t)
+
+	bindings, err := LoadKeyBindings()
+	if err != nil {
+		t.Fatalf("Failed to load keybindings: %v", err)
+	}
+	if len(bindings) != 0 {
+		t.Errorf("Expected no keybindings, got %v", bindings)
+	}
+}
+
+func TestKeyBindings_SaveAndLoad(t *testing.T) {
+	tempDir := setupTestKeyBindings(t)
+
+	expected := KeyBindings{
+		"chat.copy":    {"ctrl+y"},
+		"input.submit": {"enter", "ctrl+j"},
+	}
+	if err := SaveKeyBindings(expected); err != nil {
+		t.Fatalf("Failed to save keybindings: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(tempDir, ".klip", "keys.json"))
+	if err != nil {
+		t.Fatalf("Keybindings file not created: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected permissions 0600, got %v", info.Mode().Perm())
+	}
+
+	bindings, err := LoadKeyBindings()
+	if err != nil {
+		t.Fatalf("Failed to load keybindings: %v", err)
+	}
+	if !reflect.DeepEqual(bindings, expected) {
+		t.Errorf("Expected %v, got %v", expected, bindings)
+	}
+}
+
+func TestKeyBindings_LoadInvalid(t *testing.T) {
+	tempDir := setupTestKeyBindings(t)
+
+	configDir := filepath.Join(tempDir, ".klip")
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(configDir, "keys.json"), []byte("{not json"), 0600); err != nil {
+		t.Fatalf("Failed to write keybindings file: %v", err)
+	}
+
+	if _, err := LoadKeyBindings(); err == nil {
+		t.Error("Expected error for invalid keybindings file")
+	}
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -61,6 +62,9 @@ type ChatLogger struct {
 	sessionID  string
 	currentLog *ChatLog
 	logger     *log.Logger
+
+	// cleared holds what ClearLog removed, for RestoreClearedLog
+	cleared *ChatLog
 }
 
 // NewChatLogger creates a new ChatLogger instance
@@ -69,7 +73,11 @@ func NewChatLogger() (*ChatLogger, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get config directory: %w", err)
 	}
+	return newChatLoggerIn(configDir)
+}
 
+// newChatLoggerIn creates a ChatLogger keeping its logs in configDir
+func newChatLoggerIn(configDir string) (*ChatLogger, error) {
 	logDir := filepath.Join(configDir, "logs")
 	if err := os.MkdirAll(logDir, 0700); err != nil {
 		return nil, fmt.Errorf("failed to create log directory: %w", err)
@@ -110,6 +118,8 @@ func (cl *ChatLog) ToSession() ChatSession {
 		CreatedAt: cl.Timestamp,
 		UpdatedAt: cl.LastUpdated,
 		Messages:  cl.Messages,
+		Model:     cl.ModelUsed,
+		Provider:  cl.ProviderUsed,
 		Title:     cl.Title,
 	}
 
@@ -132,6 +142,19 @@ func (cl *ChatLog) ToSession() ChatSession {
 	return session
 }
 
+// chatLogFromSession converts a ChatSession back to the on-disk log format
+func chatLogFromSession(session ChatSession) *ChatLog {
+	return &ChatLog{
+		Timestamp:    session.CreatedAt,
+		SessionID:    session.ID,
+		Title:        session.Title,
+		Messages:     session.Messages,
+		LastUpdated:  session.UpdatedAt,
+		ModelUsed:    session.Model,
+		ProviderUsed: session.Provider,
+	}
+}
+
 // StartSession starts a new chat session
 func (cl *ChatLogger) StartSession() error {
 	now := time.Now()
@@ -148,6 +171,19 @@ func (cl *ChatLogger) StartSession() error {
 	return cl.saveLog()
 }
 
+// ResumeSession makes a saved session current, so later messages are
+// appended to it instead of a new session
+func (cl *ChatLogger) ResumeSession(sessionID string) (*ChatLog, error) {
+	session, err := cl.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	cl.sessionID = session.SessionID
+	cl.currentLog = session
+	return session, nil
+}
+
 // LogMessage adds a message to the current session
 func (cl *ChatLogger) LogMessage(message Message) error {
 	message.Timestamp = time.Now()
@@ -184,13 +220,18 @@ func (cl *ChatLogger) saveLog() error {
 		return fmt.Errorf("no current log to save")
 	}
 
+	return cl.writeLog(cl.currentLog)
+}
+
+// writeLog writes a log to disk, named by its timestamp and session ID
+func (cl *ChatLogger) writeLog(chatLog *ChatLog) error {
 	// Generate filename based on timestamp and session ID
-	timestamp := cl.currentLog.Timestamp.Format("2006-01-02-15-04-05")
-	filename := fmt.Sprintf("%s-%s.json", timestamp, cl.sessionID)
+	timestamp := chatLog.Timestamp.Format("2006-01-02-15-04-05")
+	filename := fmt.Sprintf("%s-%s.json", timestamp, chatLog.SessionID)
 	filepath := filepath.Join(cl.logDir, filename)
 
 	// Marshal to JSON
-	data, err := json.MarshalIndent(cl.currentLog, "", "  ")
+	data, err := json.MarshalIndent(chatLog, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal log: %w", err)
 	}
@@ -209,6 +250,12 @@ func (cl *ChatLogger) ClearLog() error {
 		return fmt.Errorf("no current log to clear")
 	}
 
+	cl.cleared = &ChatLog{
+		SessionID:   cl.currentLog.SessionID,
+		Messages:    cl.currentLog.Messages,
+		TotalTokens: cl.currentLog.TotalTokens,
+		TotalCost:   cl.currentLog.TotalCost,
+	}
 	cl.currentLog.Messages = make([]Message, 0)
 	cl.currentLog.LastUpdated = time.Now()
 	cl.currentLog.TotalTokens = 0
@@ -217,6 +264,22 @@ func (cl *ChatLogger) ClearLog() error {
 	return cl.saveLog()
 }
 
+// RestoreClearedLog puts back the messages the last ClearLog removed from
+// the current session
+func (cl *ChatLogger) RestoreClearedLog() error {
+	if cl.cleared == nil || cl.currentLog == nil || cl.cleared.SessionID != cl.currentLog.SessionID {
+		return fmt.Errorf("no cleared log to restore")
+	}
+
+	cl.currentLog.Messages = append(cl.cleared.Messages, cl.currentLog.Messages...)
+	cl.currentLog.TotalTokens += cl.cleared.TotalTokens
+	cl.currentLog.TotalCost += cl.cleared.TotalCost
+	cl.currentLog.LastUpdated = time.Now()
+	cl.cleared = nil
+
+	return cl.saveLog()
+}
+
 // GetCurrentSession returns the current session
 func (cl *ChatLogger) GetCurrentSession() *ChatLog {
 	return cl.currentLog
@@ -296,55 +359,74 @@ func (cl *ChatLogger) GetSession(sessionID string) (*ChatLog, error) {
 
 // ExportSession exports a session to a file in the specified format
 func (cl *ChatLogger) ExportSession(sessionID string, format string) (string, error) {
+	return cl.ExportSessionTo(sessionID, format, "")
+}
+
+// ExportSessionTo exports a session to path, or to a timestamped file in the
+// log directory when path is empty. A "pdf" export that falls back to HTML
+// returns the HTML path along with a *HTMLFallbackError.
+func (cl *ChatLogger) ExportSessionTo(sessionID, format, path string) (string, error) {
 	session, err := cl.GetSession(sessionID)
 	if err != nil {
 		return "", err
 	}
 
-	timestamp := time.Now().Format("2006-01-02-15-04-05")
-	var filename, exportPath string
-
-	switch format {
-	case "json":
-		filename = fmt.Sprintf("klip-export-%s-%s.json", timestamp, sessionID)
-		exportPath = filepath.Join(cl.logDir, filename)
-
-		data, err := json.MarshalIndent(session, "", "  ")
-		if err != nil {
-			return "", fmt.Errorf("failed to marshal session: %w", err)
+	exportPath := path
+	if exportPath == "" {
+		ext := "pdf"
+		if format != "pdf" {
+			if _, ext, err = formatSession(&ChatLog{}, format); err != nil {
+				return "", err
+			}
 		}
+		timestamp := time.Now().Format("2006-01-02-15-04-05")
+		exportPath = filepath.Join(cl.logDir, fmt.Sprintf("klip-export-%s-%s.%s", timestamp, sessionID, ext))
+	}
 
-		if err := os.WriteFile(exportPath, data, 0600); err != nil {
-			return "", fmt.Errorf("failed to write export file: %w", err)
+	if format == "pdf" {
+		var fallback *HTMLFallbackError
+		if err := writePDF(session, exportPath); errors.As(err, &fallback) {
+			return fallback.HTMLPath, err
+		} else if err != nil {
+			return "", err
 		}
+		return exportPath, nil
+	}
 
-	case "txt", "text":
-		filename = fmt.Sprintf("klip-export-%s-%s.txt", timestamp, sessionID)
-		exportPath = filepath.Join(cl.logDir, filename)
+	data, _, err := formatSession(session, format)
+	if err != nil {
+		return "", err
+	}
 
-		textContent := cl.formatAsText(session)
-		if err := os.WriteFile(exportPath, []byte(textContent), 0600); err != nil {
-			return "", fmt.Errorf("failed to write export file: %w", err)
-		}
+	if err := os.WriteFile(exportPath, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write export file: %w", err)
+	}
 
-	case "md", "markdown":
-		filename = fmt.Sprintf("klip-export-%s-%s.md", timestamp, sessionID)
-		exportPath = filepath.Join(cl.logDir, filename)
+	return exportPath, nil
+}
 
-		markdownContent := cl.formatAsMarkdown(session)
-		if err := os.WriteFile(exportPath, []byte(markdownContent), 0600); err != nil {
-			return "", fmt.Errorf("failed to write export file: %w", err)
+// formatSession renders a session in an export format, returning the file extension to use
+func formatSession(session *ChatLog, format string) ([]byte, string, error) {
+	switch format {
+	case "json":
+		data, err := json.MarshalIndent(session, "", "  ")
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to marshal session: %w", err)
 		}
-
+		return data, "json", nil
+	case "txt", "text":
+		return []byte(formatAsText(session)), "txt", nil
+	case "md", "markdown":
+		return []byte(formatAsMarkdown(session)), "md", nil
+	case "html":
+		return []byte(formatAsHTML(session)), "html", nil
 	default:
-		return "", fmt.Errorf("unsupported export format: %s", format)
+		return nil, "", fmt.Errorf("unsupported export format: %s", format)
 	}
-
-	return exportPath, nil
 }
 
 // formatAsText formats a session as plain text
-func (cl *ChatLogger) formatAsText(session *ChatLog) string {
+func formatAsText(session *ChatLog) string {
 	var builder strings.Builder
 
 	builder.WriteString(fmt.Sprintf("Chat Log - %s\n", session.Timestamp.Format("2006-01-02 15:04:05")))
@@ -379,7 +461,7 @@ func (cl *ChatLogger) formatAsText(session *ChatLog) string {
 }
 
 // formatAsMarkdown formats a session as Markdown
-func (cl *ChatLogger) formatAsMarkdown(session *ChatLog) string {
+func formatAsMarkdown(session *ChatLog) string {
 	var builder strings.Builder
 
 	builder.WriteString(fmt.Sprintf("# Chat Log - %s\n\n", session.Timestamp.Format("2006-01-02 15:04:05")))
@@ -397,20 +479,96 @@ func (cl *ChatLogger) formatAsMarkdown(session *ChatLog) string {
 	builder.WriteString("\n---\n\n")
 
 	for _, message := range session.Messages {
-		timestamp := message.Timestamp.Format("2006-01-02 15:04:05")
+		writeMarkdownMessage(&builder, message, true)
+		builder.WriteString("\n---\n\n")
+	}
+
+	return builder.String()
+}
+
+// TranscriptOptions controls how FormatMarkdownTranscript renders messages
+type TranscriptOptions struct {
+	Timestamps    bool
+	AssistantOnly bool
+}
+
+// FormatMarkdownTranscript renders messages as Markdown suitable for pasting
+// into documents and issues, without the session header of an export
+func FormatMarkdownTranscript(messages []Message, opts TranscriptOptions) string {
+	var builder strings.Builder
+
+	written := 0
+	for _, message := range messages {
+		if opts.AssistantOnly && message.Role != "assistant" {
+			continue
+		}
+		if written > 0 {
+			builder.WriteString("\n---\n\n")
+		}
+		writeMarkdownMessage(&builder, message, opts.Timestamps)
+		written++
+	}
+
+	return builder.String()
+}
+
+// FormatMessage serializes a single message as "markdown", "plain" or "json"
+// for copying elsewhere. The timestamp is left out of the text formats
+// unless withTimestamp is set.
+func FormatMessage(message Message, format string, withTimestamp bool) (string, error) {
+	switch format {
+	case "md", "markdown":
+		var builder strings.Builder
+		writeMarkdownMessage(&builder, message, withTimestamp)
+		return builder.String(), nil
+	case "plain", "txt", "text":
 		role := strings.Title(strings.ToLower(message.Role))
+		if withTimestamp {
+			role += " (" + message.Timestamp.Format("2006-01-02 15:04:05") + ")"
+		}
+		return fmt.Sprintf("%s:\n%s\n", role, strings.TrimRight(message.Content, "\n")), nil
+	case "json":
+		data, err := json.MarshalIndent(message, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal message: %w", err)
+		}
+		return string(data), nil
+	default:
+		return "", fmt.Errorf("unsupported export format: %s", format)
+	}
+}
 
+// writeMarkdownMessage writes one message as a level-two heading followed by
+// its content, which is already Markdown and is kept as written
+func writeMarkdownMessage(builder *strings.Builder, message Message, withTimestamp bool) {
+	role := strings.Title(strings.ToLower(message.Role))
+	if withTimestamp {
+		timestamp := message.Timestamp.Format("2006-01-02 15:04:05")
 		builder.WriteString(fmt.Sprintf("## %s - %s\n\n", role, timestamp))
-		builder.WriteString(message.Content + "\n")
+	} else {
+		builder.WriteString(fmt.Sprintf("## %s\n\n", role))
+	}
 
-		if message.Tokens != nil && message.Tokens.Total > 0 {
-			builder.WriteString(fmt.Sprintf("\n*Tokens: %d*\n", message.Tokens.Total))
-		}
+	builder.WriteString(closeCodeFence(strings.TrimRight(message.Content, "\n")) + "\n")
 
-		builder.WriteString("\n---\n\n")
+	if message.Tokens != nil && message.Tokens.Total > 0 {
+		builder.WriteString(fmt.Sprintf("\n*Tokens: %d*\n", message.Tokens.Total))
 	}
+}
 
-	return builder.String()
+// closeCodeFence terminates a code block left open by an interrupted response
+// so it does not swallow the messages that follow
+func closeCodeFence(content string) string {
+	open := false
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			open = !open
+		}
+	}
+	if open {
+		return content + "\n```"
+	}
+	return content
 }
 
 // DeleteSession removes a session log file
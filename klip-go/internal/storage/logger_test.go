@@ -250,6 +250,42 @@ func TestChatLogger_GetSession(t *testing.T) {
 	}
 }
 
+func TestChatLogger_ResumeSession(t *testing.T) {
+	chatLogger, _ := setupTestChatLogger(t)
+
+	if err := chatLogger.StartSession(); err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+	if err := chatLogger.LogMessage(Message{Role: "user", Content: "first", Model: "gpt-4o", Provider: "openai"}); err != nil {
+		t.Fatalf("Failed to log message: %v", err)
+	}
+	sessionID := chatLogger.GetCurrentSession().SessionID
+
+	if err := chatLogger.StartSession(); err != nil {
+		t.Fatalf("Failed to start session: %v", err)
+	}
+
+	resumed, err := chatLogger.ResumeSession(sessionID)
+	if err != nil {
+		t.Fatalf("Failed to resume session: %v", err)
+	}
+	if session := resumed.ToSession(); session.Model != "gpt-4o" || session.Provider != "openai" {
+		t.Errorf("Expected the session to record gpt-4o (openai), got %s (%s)", session.Model, session.Provider)
+	}
+
+	// New messages continue the resumed session
+	if err := chatLogger.LogMessage(Message{Role: "user", Content: "second"}); err != nil {
+		t.Fatalf("Failed to log message: %v", err)
+	}
+	retrieved, err := chatLogger.GetSession(sessionID)
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+	if len(retrieved.Messages) != 2 {
+		t.Errorf("Expected 2 messages, got %d", len(retrieved.Messages))
+	}
+}
+
 func TestChatLogger_ExportSession(t *testing.T) {
 	chatLogger, _ := setupTestChatLogger(t)
 
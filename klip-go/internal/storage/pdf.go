@@ -0,0 +1,459 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// ErrPDFUnavailable reports that a conversation could not be rendered to
+// PDF; the export falls back to HTML
+var ErrPDFUnavailable = errors.New("no PDF renderer available")
+
+// HTMLFallbackError is returned by ExportPDF when only the HTML version of
+// the conversation could be written
+type HTMLFallbackError struct {
+	HTMLPath string
+	Reason   error
+}
+
+func (e *HTMLFallbackError) Error() string {
+	return fmt.Sprintf("PDF rendering unavailable (%v), exported HTML to %s", e.Reason, e.HTMLPath)
+}
+
+func (e *HTMLFallbackError) Unwrap() error {
+	return ErrPDFUnavailable
+}
+
+// ExportPDF renders session to HTML and converts it to a PDF at path. A
+// headless browser on PATH is preferred for its full Unicode and CSS
+// support; otherwise a built-in renderer lays the HTML out with the standard
+// PDF fonts. When neither can represent the conversation, the HTML is saved
+// beside path and a *HTMLFallbackError returned.
+func ExportPDF(session ChatSession, path string) error {
+	return writePDF(chatLogFromSession(session), path)
+}
+
+// writePDF renders and writes a session log as described by ExportPDF
+func writePDF(session *ChatLog, path string) error {
+	document := formatAsHTML(session)
+
+	var reason error
+	if renderer := headlessPDFRenderer(); renderer != nil {
+		if reason = renderer(document, path); reason == nil {
+			return nil
+		}
+	}
+
+	data, err := renderPDF(document)
+	if err == nil {
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return fmt.Errorf("failed to write PDF: %w", err)
+		}
+		return nil
+	}
+	if reason == nil {
+		reason = err
+	}
+
+	htmlPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".html"
+	if err := os.WriteFile(htmlPath, []byte(document), 0600); err != nil {
+		return fmt.Errorf("failed to write HTML export: %w", err)
+	}
+	return &HTMLFallbackError{HTMLPath: htmlPath, Reason: reason}
+}
+
+// headlessPDFRenderer returns a converter backed by wkhtmltopdf or a
+// Chromium-based browser, or nil when none is installed
+func headlessPDFRenderer() func(document, path string) error {
+	var args func(input, output string) []string
+	var command string
+
+	if found, err := exec.LookPath("wkhtmltopdf"); err == nil {
+		command = found
+		args = func(input, output string) []string {
+			return []string{"--quiet", "--encoding", "utf-8", input, output}
+		}
+	} else {
+		for _, name := range []string{"chromium", "chromium-browser", "google-chrome", "google-chrome-stable"} {
+			if found, err := exec.LookPath(name); err == nil {
+				command = found
+				break
+			}
+		}
+		if command == "" {
+			return nil
+		}
+		args = func(input, output string) []string {
+			return []string{"--headless", "--disable-gpu", "--no-pdf-header-footer", "--print-to-pdf=" + output, input}
+		}
+	}
+
+	return func(document, path string) error {
+		input, err := os.CreateTemp("", "klip-export-*.html")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(input.Name())
+
+		if _, err := input.WriteString(document); err != nil {
+			input.Close()
+			return err
+		}
+		input.Close()
+
+		output, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if out, err := exec.CommandContext(ctx, command, args(input.Name(), output)...).CombinedOutput(); err != nil {
+			return fmt.Errorf("%s failed: %v: %s", filepath.Base(command), err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+}
+
+// PDF page geometry in points (A4)
+const (
+	pdfPageWidth  = 595.0
+	pdfPageHeight = 842.0
+	pdfMargin     = 56.0
+)
+
+// pdfFont identifies one of the standard fonts the built-in renderer uses
+type pdfFont string
+
+const (
+	pdfRegular pdfFont = "F1" // Helvetica
+	pdfBold    pdfFont = "F2" // Helvetica-Bold
+	pdfMono    pdfFont = "F3" // Courier
+)
+
+// pdfRoleColors mirror the role colors of the HTML export, as RGB fractions
+var pdfRoleColors = map[string][3]float64{
+	"user":      {0.15, 0.39, 0.92},
+	"assistant": {0.49, 0.23, 0.93},
+	"system":    {0.85, 0.47, 0.02},
+}
+
+// pdfLayout places text on pages top to bottom
+type pdfLayout struct {
+	pages   []*bytes.Buffer
+	page    *bytes.Buffer
+	y       float64
+	invalid []rune
+}
+
+// renderPDF lays out an HTML export with Helvetica for prose and Courier for
+// code. The standard fonts only cover Latin-1, so text outside it makes the
+// document unrenderable rather than silently dropping characters.
+func renderPDF(document string) ([]byte, error) {
+	root, err := html.Parse(strings.NewReader(document))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	layout := &pdfLayout{}
+	layout.newPage()
+	layout.walk(root)
+
+	if len(layout.invalid) > 0 {
+		return nil, fmt.Errorf("characters outside the built-in fonts, such as %q", string(layout.invalid[0]))
+	}
+	return layout.bytes(), nil
+}
+
+// walk renders the block elements of the export
+func (l *pdfLayout) walk(node *html.Node) {
+	if node.Type == html.ElementNode {
+		switch node.Data {
+		case "head", "style", "title":
+			return
+		case "h1":
+			l.text(textContent(node), pdfBold, 18, [3]float64{0.12, 0.16, 0.22}, 10)
+			return
+		case "h2":
+			l.space(6)
+			role := strings.TrimPrefix(attr(node.Parent, "class"), "message role-")
+			l.text(textContent(node), pdfBold, 12, pdfRoleColors[role], 4)
+			return
+		case "p":
+			l.paragraph(node)
+			return
+		case "pre":
+			l.code(textContent(node))
+			return
+		case "header":
+			for child := node.FirstChild; child != nil; child = child.NextSibling {
+				l.walk(child)
+			}
+			l.rule()
+			return
+		}
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		l.walk(child)
+	}
+}
+
+// paragraph renders a <p>, honoring <br> line breaks and inline <code>
+func (l *pdfLayout) paragraph(node *html.Node) {
+	var lines []string
+	var current strings.Builder
+	var collect func(*html.Node)
+	collect = func(n *html.Node) {
+		switch {
+		case n.Type == html.TextNode:
+			current.WriteString(strings.ReplaceAll(n.Data, "\n", ""))
+		case n.Type == html.ElementNode && n.Data == "br":
+			lines = append(lines, current.String())
+			current.Reset()
+		default:
+			for child := n.FirstChild; child != nil; child = child.NextSibling {
+				collect(child)
+			}
+		}
+	}
+	collect(node)
+	lines = append(lines, current.String())
+
+	for _, line := range lines {
+		l.text(line, pdfRegular, 11, [3]float64{0.12, 0.16, 0.22}, 0)
+	}
+	l.space(6)
+}
+
+// text writes wrapped text, followed by after points of space
+func (l *pdfLayout) text(s string, font pdfFont, size float64, color [3]float64, after float64) {
+	lineHeight := size * 1.35
+	for _, line := range wrapPDFText(s, font, size, pdfPageWidth-2*pdfMargin) {
+		l.ensure(lineHeight)
+		l.y -= lineHeight
+		l.show(line, font, size, color, pdfMargin, l.y+size*0.3)
+	}
+	l.space(after)
+}
+
+// code renders a code block on a shaded background, hard-wrapping long lines
+func (l *pdfLayout) code(s string) {
+	const size, padding = 9.0, 6.0
+	lineHeight := size * 1.3
+	width := pdfPageWidth - 2*pdfMargin - 2*padding
+	maxChars := int(width / (size * 0.6))
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		runes := []rune(line)
+		for len(runes) > maxChars {
+			lines = append(lines, string(runes[:maxChars]))
+			runes = runes[maxChars:]
+		}
+		lines = append(lines, string(runes))
+	}
+
+	l.space(2)
+	for _, line := range lines {
+		l.ensure(lineHeight)
+		fmt.Fprintf(l.page, "0.953 0.957 0.965 rg %.2f %.2f %.2f %.2f re f\n",
+			pdfMargin, l.y-lineHeight, pdfPageWidth-2*pdfMargin, lineHeight)
+		l.y -= lineHeight
+		l.show(line, pdfMono, size, [3]float64{0.12, 0.16, 0.22}, pdfMargin+padding, l.y+size*0.35)
+	}
+	l.space(8)
+}
+
+// rule draws a horizontal separator
+func (l *pdfLayout) rule() {
+	l.ensure(12)
+	l.y -= 6
+	fmt.Fprintf(l.page, "0.898 0.906 0.922 RG 0.5 w %.2f %.2f m %.2f %.2f l S\n",
+		pdfMargin, l.y, pdfPageWidth-pdfMargin, l.y)
+	l.y -= 12
+}
+
+// show draws one line of text at x, y
+func (l *pdfLayout) show(s string, font pdfFont, size float64, color [3]float64, x, y float64) {
+	fmt.Fprintf(l.page, "BT %.3f %.3f %.3f rg /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n",
+		color[0], color[1], color[2], font, size, x, y, l.encode(s))
+}
+
+// encode converts s to WinAnsi bytes escaped for a PDF string, recording
+// characters the standard fonts can't show
+func (l *pdfLayout) encode(s string) string {
+	var builder strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			builder.WriteByte('\\')
+			builder.WriteRune(r)
+		case r >= 0x20 && r < 0x7F:
+			builder.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&builder, "\\%03o", r)
+		default:
+			if code, ok := winAnsiExtras[r]; ok {
+				fmt.Fprintf(&builder, "\\%03o", code)
+				continue
+			}
+			l.invalid = append(l.invalid, r)
+			builder.WriteByte('?')
+		}
+	}
+	return builder.String()
+}
+
+// winAnsiExtras maps common typographic characters to their WinAnsi codes
+var winAnsiExtras = map[rune]int{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '•': 0x95, '–': 0x96,
+	'—': 0x97, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '™': 0x99,
+}
+
+// space adds vertical space, never crossing onto a new page
+func (l *pdfLayout) space(points float64) {
+	l.y = max(l.y-points, pdfMargin)
+}
+
+// ensure starts a new page when fewer than height points remain
+func (l *pdfLayout) ensure(height float64) {
+	if l.y-height < pdfMargin {
+		l.newPage()
+	}
+}
+
+func (l *pdfLayout) newPage() {
+	l.page = &bytes.Buffer{}
+	l.pages = append(l.pages, l.page)
+	l.y = pdfPageHeight - pdfMargin
+}
+
+// bytes assembles the PDF file: catalog, page tree, fonts, then each page
+// and its content stream, followed by the cross-reference table
+func (l *pdfLayout) bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	const firstPage = 6
+	kids := make([]string, len(l.pages))
+	for i := range l.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(l.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range l.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R /F3 5 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.Bytes()
+}
+
+// wrapPDFText breaks s into lines no wider than width, estimating Helvetica
+// glyph widths by character class
+func wrapPDFText(s string, font pdfFont, size, width float64) []string {
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	var lines []string
+	current := ""
+	for _, word := range words {
+		candidate := word
+		if current != "" {
+			candidate = current + " " + word
+		}
+		if current != "" && textWidth(candidate, font, size) > width {
+			lines = append(lines, current)
+			candidate = word
+		}
+		current = candidate
+	}
+	return append(lines, current)
+}
+
+// textWidth approximates the rendered width of s in points
+func textWidth(s string, font pdfFont, size float64) float64 {
+	if font == pdfMono {
+		return float64(len([]rune(s))) * 0.6 * size
+	}
+
+	em := 0.0
+	for _, r := range s {
+		switch {
+		case strings.ContainsRune("iljtf.,;:'|!I ", r):
+			em += 0.28
+		case strings.ContainsRune("mwMW@", r):
+			em += 0.86
+		case r >= 'A' && r <= 'Z':
+			em += 0.70
+		default:
+			em += 0.56
+		}
+	}
+	if font == pdfBold {
+		em *= 1.06
+	}
+	return em * size
+}
+
+// textContent returns the concatenated text beneath node
+func textContent(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+
+	var builder strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.Data == "time" {
+			builder.WriteString("  ")
+		}
+		builder.WriteString(textContent(child))
+	}
+	return builder.String()
+}
+
+// attr returns the value of an element's attribute, or ""
+func attr(node *html.Node, name string) string {
+	if node == nil {
+		return ""
+	}
+	for _, a := range node.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func pdfTestSession(reply string) *ChatLog {
+	at := time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC)
+	return &ChatLog{
+		SessionID: "pdf-test",
+		Title:     "Reversing slices",
+		Timestamp: at,
+		ModelUsed: "claude-sonnet-4-20250514",
+		Messages: []Message{
+			{Role: "user", Content: "How do I reverse a slice in Go?", Timestamp: at},
+			{Role: "assistant", Content: reply, Timestamp: at.Add(5 * time.Second)},
+		},
+	}
+}
+
+func TestFormatAsHTML(t *testing.T) {
+	document := formatAsHTML(pdfTestSession("Use `slices.Reverse`:\n\n```go\nif a < b {\n\tslices.Reverse(s)\n}\n```"))
+
+	for _, want := range []string{
+		"<h1>Reversing slices</h1>",
+		`<section class="message role-assistant">`,
+		"<code>slices.Reverse</code>",
+		"<pre><code class=\"language-go\">if a &lt; b {\n\tslices.Reverse(s)\n}</code></pre>",
+	} {
+		if !strings.Contains(document, want) {
+			t.Errorf("HTML export missing %q", want)
+		}
+	}
+}
+
+func TestRenderPDF(t *testing.T) {
+	data, err := renderPDF(formatAsHTML(pdfTestSession("Use `slices.Reverse` (it’s in place):\n\n```go\nslices.Reverse(s)\n```")))
+	if err != nil {
+		t.Fatalf("Failed to render PDF: %v", err)
+	}
+
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) {
+		t.Errorf("Expected a PDF header, got %q", data[:min(len(data), 16)])
+	}
+	if !bytes.HasSuffix(data, []byte("%%EOF\n")) {
+		t.Error("Expected the PDF to end with an EOF marker")
+	}
+	if !bytes.Contains(data, []byte("/BaseFont /Courier")) {
+		t.Error("Expected code blocks to use a monospace font")
+	}
+	if !bytes.Contains(data, []byte("(slices.Reverse\\(s\\)) Tj")) {
+		t.Error("Expected the code block text with escaped parentheses")
+	}
+}
+
+func TestRenderPDFRejectsUnsupportedText(t *testing.T) {
+	if _, err := renderPDF(formatAsHTML(pdfTestSession("スライスを反転します"))); err == nil {
+		t.Fatal("Expected text outside the standard fonts to be rejected")
+	}
+}
+
+func TestWritePDFFallsBackToHTML(t *testing.T) {
+	// Keep any installed headless browser out of the test
+	t.Setenv("PATH", "")
+
+	path := filepath.Join(t.TempDir(), "chat.pdf")
+	err := writePDF(pdfTestSession("スライスを反転します"), path)
+
+	var fallback *HTMLFallbackError
+	if !errors.As(err, &fallback) || !errors.Is(err, ErrPDFUnavailable) {
+		t.Fatalf("Expected an HTML fallback error, got %v", err)
+	}
+	if fallback.HTMLPath != strings.TrimSuffix(path, ".pdf")+".html" {
+		t.Errorf("Unexpected HTML path %s", fallback.HTMLPath)
+	}
+	if data, err := os.ReadFile(fallback.HTMLPath); err != nil || !strings.Contains(string(data), "スライス") {
+		t.Errorf("Expected the HTML export to be written: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected no PDF to be written")
+	}
+}
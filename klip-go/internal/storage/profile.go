@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// DefaultProfile names the data kept directly in ~/.klip
+const DefaultProfile = "default"
+
+// ProfileEnv names the environment variable that selects a profile, as
+// --profile does
+const ProfileEnv = "KLIP_PROFILE"
+
+// profileNamePattern keeps profile names usable as directory names
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+// activeProfile is the profile set by SetProfile; empty is the default.
+// Stores opened lazily read it from background commands, hence the lock.
+var (
+	activeProfile string
+	profileMu     sync.RWMutex
+)
+
+// SetProfile makes storage opened afterwards use the named profile, kept
+// in ~/.klip/profiles/<name> with its own config, history and analytics.
+// API keys are shared by all profiles. "default" or an empty name goes
+// back to ~/.klip itself.
+func SetProfile(name string) error {
+	name, err := profileName(name)
+	if err != nil {
+		return err
+	}
+
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	activeProfile = name
+	return nil
+}
+
+// ActiveProfile returns the name of the profile in use
+func ActiveProfile() string {
+	profileMu.RLock()
+	defer profileMu.RUnlock()
+	if activeProfile == "" {
+		return DefaultProfile
+	}
+	return activeProfile
+}
+
+// profileName checks a profile name, returning "" for the default profile
+func profileName(name string) (string, error) {
+	if name == "" || name == DefaultProfile {
+		return "", nil
+	}
+	if !profileNamePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid profile name %q: use letters, digits, - and _", name)
+	}
+	return name, nil
+}
+
+// ProfileDir returns the directory holding the named profile's data,
+// creating it if needed; that of the default profile is ~/.klip
+func ProfileDir(name string) (string, error) {
+	name, err := profileName(name)
+	if err != nil {
+		return "", err
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	configDir := filepath.Join(homeDir, ".klip")
+	if name != "" {
+		configDir = filepath.Join(configDir, "profiles", name)
+	}
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	return configDir, nil
+}
+
+// ListProfiles returns the default profile and those created so far, sorted
+func ListProfiles() ([]string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(homeDir, ".klip", "profiles"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read profiles: %w", err)
+	}
+
+	var profiles []string
+	for _, entry := range entries {
+		if entry.IsDir() && profileNamePattern.MatchString(entry.Name()) && entry.Name() != DefaultProfile {
+			profiles = append(profiles, entry.Name())
+		}
+	}
+	sort.Strings(profiles)
+	return append([]string{DefaultProfile}, profiles...), nil
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetProfileMovesConfigDir(t *testing.T) {
+	_, tempDir := setupTestConfigManager(t)
+	t.Cleanup(func() { SetProfile("") })
+
+	if err := SetProfile("work"); err != nil {
+		t.Fatalf("Failed to set profile: %v", err)
+	}
+	if ActiveProfile() != "work" {
+		t.Errorf("Expected active profile 'work', got '%s'", ActiveProfile())
+	}
+
+	configDir, err := GetConfigDir()
+	if err != nil {
+		t.Fatalf("Failed to get config directory: %v", err)
+	}
+	if expected := filepath.Join(tempDir, ".klip", "profiles", "work"); configDir != expected {
+		t.Errorf("Expected config directory %s, got %s", expected, configDir)
+	}
+
+	// The default profile's config is not migrated into the profile
+	defaultConfig := filepath.Join(tempDir, ".klip", "config.json")
+	if err := os.WriteFile(defaultConfig, []byte(`{"default_model":"gpt-4o"}`), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	configManager, err := NewConfigManager()
+	if err != nil {
+		t.Fatalf("Failed to create ConfigManager: %v", err)
+	}
+	if err := configManager.MigrateFromDeno(); err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+	if _, err := os.Stat(defaultConfig); err != nil {
+		t.Errorf("Expected the default config to stay in place, got %v", err)
+	}
+
+	profiles, err := ListProfiles()
+	if err != nil {
+		t.Fatalf("Failed to list profiles: %v", err)
+	}
+	if len(profiles) != 2 || profiles[0] != DefaultProfile || profiles[1] != "work" {
+		t.Errorf("Expected [default work], got %v", profiles)
+	}
+
+	for _, name := range []string{"../escape", "a/b", ".hidden"} {
+		if err := SetProfile(name); err == nil {
+			t.Errorf("Expected profile name %q to be rejected", name)
+		}
+	}
+	if ActiveProfile() != "work" {
+		t.Errorf("Expected a rejected name to leave the profile alone, got '%s'", ActiveProfile())
+	}
+
+	if err := SetProfile(DefaultProfile); err != nil {
+		t.Fatalf("Failed to reset profile: %v", err)
+	}
+	if configDir, _ := GetConfigDir(); configDir != filepath.Join(tempDir, ".klip") {
+		t.Errorf("Expected the default profile to use ~/.klip, got %s", configDir)
+	}
+}
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RedactedPlaceholder replaces secrets removed by RedactSecrets
+const RedactedPlaceholder = "[REDACTED]"
+
+// SecretPattern names a kind of secret and the expression that finds it.
+// A capture group keeps its text, such as "Bearer ", when redacting.
+type SecretPattern struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// secretPatterns match the API keys and tokens most likely to be pasted into
+// a conversation. Longer, more specific prefixes come first so an Anthropic
+// key isn't half-matched as an OpenAI one.
+var secretPatterns = []SecretPattern{
+	{"Anthropic API key", regexp.MustCompile(`sk-ant-[A-Za-z0-9_-]{20,}`)},
+	{"OpenRouter API key", regexp.MustCompile(`sk-or-[A-Za-z0-9_-]{20,}`)},
+	{"OpenAI API key", regexp.MustCompile(`sk-(?:proj-|svcacct-)?[A-Za-z0-9_-]{20,}`)},
+	{"GitHub token", regexp.MustCompile(`(?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36,}`)},
+	{"GitHub token", regexp.MustCompile(`github_pat_[A-Za-z0-9_]{22,}`)},
+	{"Google API key", regexp.MustCompile(`AIza[0-9A-Za-z_-]{35}`)},
+	{"AWS access key", regexp.MustCompile(`(?:AKIA|ASIA)[0-9A-Z]{16}`)},
+	{"Slack token", regexp.MustCompile(`xox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{"private key", regexp.MustCompile(`-----BEGIN (?:[A-Z]+ )*PRIVATE KEY-----[\s\S]*?-----END (?:[A-Z]+ )*PRIVATE KEY-----`)},
+	{"JSON web token", regexp.MustCompile(`eyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+	{"bearer token", regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/-]{20,}=*`)},
+}
+
+// SecretPatterns returns the built-in patterns with custom ones added,
+// keyed by name. A custom pattern replaces built-ins of the same name and
+// an empty expression removes them.
+func SecretPatterns(custom map[string]string) ([]SecretPattern, error) {
+	patterns := make([]SecretPattern, 0, len(secretPatterns)+len(custom))
+	for _, pattern := range secretPatterns {
+		if _, overridden := custom[pattern.Name]; !overridden {
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	names := make([]string, 0, len(custom))
+	for name := range custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		expr := custom[name]
+		if strings.TrimSpace(expr) == "" {
+			continue
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret pattern %q: %w", name, err)
+		}
+		patterns = append(patterns, SecretPattern{Name: name, Pattern: re})
+	}
+	return patterns, nil
+}
+
+// DetectSecrets returns the kinds of secret found in text by patterns,
+// each once and in pattern order, plus "known secret" when one of known
+// appears verbatim
+func DetectSecrets(text string, patterns []SecretPattern, known ...string) []string {
+	var kinds []string
+	seen := make(map[string]bool)
+	add := func(kind string) {
+		if !seen[kind] {
+			seen[kind] = true
+			kinds = append(kinds, kind)
+		}
+	}
+
+	for _, secret := range known {
+		if secret = strings.TrimSpace(secret); len(secret) >= 8 && strings.Contains(text, secret) {
+			add("known secret")
+		}
+	}
+	// Matches are blanked as they're found so a key isn't also reported
+	// as a less specific kind
+	for _, pattern := range patterns {
+		if pattern.Pattern.MatchString(text) {
+			add(pattern.Name)
+			text = pattern.Pattern.ReplaceAllString(text, RedactedPlaceholder)
+		}
+	}
+	return kinds
+}
+
+// RedactSecrets replaces anything that looks like an API key or token in
+// text, plus each of the known secrets verbatim, and reports how many
+// replacements it made
+func RedactSecrets(text string, known ...string) (string, int) {
+	return RedactSecretsWith(text, secretPatterns, known...)
+}
+
+// RedactSecretsWith is RedactSecrets using the given patterns
+func RedactSecretsWith(text string, patterns []SecretPattern, known ...string) (string, int) {
+	count := 0
+	for _, secret := range known {
+		if secret = strings.TrimSpace(secret); len(secret) < 8 {
+			continue
+		}
+		count += strings.Count(text, secret)
+		text = strings.ReplaceAll(text, secret, RedactedPlaceholder)
+	}
+
+	for _, secret := range patterns {
+		pattern := secret.Pattern
+		text = pattern.ReplaceAllStringFunc(text, func(match string) string {
+			count++
+			// Keep a captured prefix such as "Bearer " for context
+			if groups := pattern.FindStringSubmatch(match); len(groups) > 1 {
+				return groups[1] + RedactedPlaceholder
+			}
+			return RedactedPlaceholder
+		})
+	}
+
+	return text, count
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultResponseCacheTTL is how long responses are kept when
+// Config.CacheDuration is unset
+const DefaultResponseCacheTTL = time.Hour
+
+// CachedResponse is a model reply stored for an identical conversation
+type CachedResponse struct {
+	Model     string    `json:"model"`
+	Content   string    `json:"content"`
+	Tokens    *Tokens   `json:"tokens,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ResponseCache keeps replies on disk, one file per conversation hash, so
+// repeating a prompt while iterating costs nothing
+type ResponseCache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewResponseCache opens the response cache in the config directory.
+// Entries older than ttl are treated as missing.
+func NewResponseCache(ttl time.Duration) (*ResponseCache, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config directory: %w", err)
+	}
+
+	dir := filepath.Join(configDir, "cache", "responses")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create response cache directory: %w", err)
+	}
+
+	if ttl <= 0 {
+		ttl = DefaultResponseCacheTTL
+	}
+
+	return &ResponseCache{dir: dir, ttl: ttl, now: time.Now}, nil
+}
+
+// ResponseCacheKey hashes a model and conversation. Timestamps are ignored
+// and whitespace is collapsed, so re-sending the same text hits the cache.
+func ResponseCacheKey(modelID string, messages []Message) string {
+	hash := sha256.New()
+	hash.Write([]byte(modelID))
+	for _, msg := range messages {
+		hash.Write([]byte{0})
+		hash.Write([]byte(msg.Role))
+		hash.Write([]byte{0})
+		hash.Write([]byte(strings.Join(strings.Fields(msg.Content), " ")))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Get returns the cached response for key, if there is one that hasn't
+// expired. Expired entries are removed.
+func (rc *ResponseCache) Get(key string) (*CachedResponse, bool) {
+	data, err := os.ReadFile(rc.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var response CachedResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, false
+	}
+
+	if rc.now().Sub(response.CreatedAt) > rc.ttl {
+		_ = rc.Invalidate(key)
+		return nil, false
+	}
+
+	return &response, true
+}
+
+// Put stores a response under key, replacing any earlier one
+func (rc *ResponseCache) Put(key string, response CachedResponse) error {
+	if response.CreatedAt.IsZero() {
+		response.CreatedAt = rc.now()
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cached response: %w", err)
+	}
+
+	if err := os.WriteFile(rc.path(key), data, 0600); err != nil {
+		return fmt.Errorf("failed to write cached response: %w", err)
+	}
+	return nil
+}
+
+// Invalidate removes the response stored under key
+func (rc *ResponseCache) Invalidate(key string) error {
+	if err := os.Remove(rc.path(key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove cached response: %w", err)
+	}
+	return nil
+}
+
+// Clear removes every cached response, returning how many there were
+func (rc *ResponseCache) Clear() (int, error) {
+	entries, err := os.ReadDir(rc.dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read response cache: %w", err)
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		if err := os.Remove(filepath.Join(rc.dir, entry.Name())); err != nil {
+			return removed, fmt.Errorf("failed to remove cached response: %w", err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// Len returns how many responses are stored, including expired ones not
+// yet removed
+func (rc *ResponseCache) Len() int {
+	matches, _ := filepath.Glob(filepath.Join(rc.dir, "*.json"))
+	return len(matches)
+}
+
+// TTL returns how long responses stay valid
+func (rc *ResponseCache) TTL() time.Duration {
+	return rc.ttl
+}
+
+func (rc *ResponseCache) path(key string) string {
+	return filepath.Join(rc.dir, key+".json")
+}
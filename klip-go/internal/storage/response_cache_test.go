@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func setupTestResponseCache(t *testing.T, ttl time.Duration) *ResponseCache {
+	t.Setenv("HOME", t.TempDir())
+
+	cache, err := NewResponseCache(ttl)
+	if err != nil {
+		t.Fatalf("Failed to create response cache: %v", err)
+	}
+	return cache
+}
+
+func TestResponseCache_HitAndMiss(t *testing.T) {
+	cache := setupTestResponseCache(t, time.Hour)
+
+	conversation := []Message{
+		{Role: "system", Content: "Be brief."},
+		{Role: "user", Content: "What is a goroutine?", Timestamp: time.Now()},
+	}
+	key := ResponseCacheKey("gpt-4o", conversation)
+
+	if _, ok := cache.Get(key); ok {
+		t.Fatal("Expected a miss before anything is stored")
+	}
+
+	err := cache.Put(key, CachedResponse{
+		Model:   "gpt-4o",
+		Content: "A lightweight thread.",
+		Tokens:  &Tokens{Input: 10, Output: 5, Total: 15},
+	})
+	if err != nil {
+		t.Fatalf("Failed to store response: %v", err)
+	}
+
+	// The same text sent later, with different spacing, is a hit
+	resent := []Message{
+		{Role: "system", Content: "Be brief."},
+		{Role: "user", Content: "What is a  goroutine?\n", Timestamp: time.Now().Add(time.Minute)},
+	}
+	response, ok := cache.Get(ResponseCacheKey("gpt-4o", resent))
+	if !ok {
+		t.Fatal("Expected a hit for the same conversation")
+	}
+	if response.Content != "A lightweight thread." || response.Tokens.Total != 15 {
+		t.Errorf("Unexpected cached response: %+v", response)
+	}
+
+	// Another model or conversation misses
+	if _, ok := cache.Get(ResponseCacheKey("gpt-4o-mini", conversation)); ok {
+		t.Error("Expected a miss for a different model")
+	}
+	changed := append(conversation[:1:1], Message{Role: "user", Content: "What is a channel?"})
+	if _, ok := cache.Get(ResponseCacheKey("gpt-4o", changed)); ok {
+		t.Error("Expected a miss for a different prompt")
+	}
+
+	if err := cache.Invalidate(key); err != nil {
+		t.Fatalf("Failed to invalidate: %v", err)
+	}
+	if _, ok := cache.Get(key); ok {
+		t.Error("Expected a miss after invalidation")
+	}
+}
+
+func TestResponseCache_TTLExpiry(t *testing.T) {
+	cache := setupTestResponseCache(t, 15*time.Minute)
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	key := ResponseCacheKey("claude-3-5-haiku-20241022", []Message{{Role: "user", Content: "hi"}})
+	if err := cache.Put(key, CachedResponse{Content: "Hello!"}); err != nil {
+		t.Fatalf("Failed to store response: %v", err)
+	}
+
+	now = now.Add(14 * time.Minute)
+	if _, ok := cache.Get(key); !ok {
+		t.Fatal("Expected a hit within the TTL")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := cache.Get(key); ok {
+		t.Fatal("Expected a miss once the TTL has passed")
+	}
+	if cache.Len() != 0 {
+		t.Errorf("Expected the expired entry to be removed, %d left", cache.Len())
+	}
+}
+
+func TestResponseCache_Clear(t *testing.T) {
+	cache := setupTestResponseCache(t, 0)
+	if cache.TTL() != DefaultResponseCacheTTL {
+		t.Errorf("Expected default TTL, got %v", cache.TTL())
+	}
+
+	for _, prompt := range []string{"one", "two", "three"} {
+		key := ResponseCacheKey("gpt-4o", []Message{{Role: "user", Content: prompt}})
+		if err := cache.Put(key, CachedResponse{Content: prompt}); err != nil {
+			t.Fatalf("Failed to store response: %v", err)
+		}
+	}
+
+	removed, err := cache.Clear()
+	if err != nil {
+		t.Fatalf("Failed to clear cache: %v", err)
+	}
+	if removed != 3 || cache.Len() != 0 {
+		t.Errorf("Expected 3 entries removed and none left, got %d removed and %d left", removed, cache.Len())
+	}
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// searchSnippetContext is how many characters of context a search snippet
+// keeps on each side of the match
+const searchSnippetContext = 40
+
+// SearchResult is a message matching a history search, with the session
+// it belongs to
+type SearchResult struct {
+	SessionID    string    `json:"session_id"`
+	SessionTitle string    `json:"session_title,omitempty"`
+	SessionTime  time.Time `json:"session_time"`
+
+	// MessageIndex is the position of Message in its session
+	MessageIndex int     `json:"message_index"`
+	Message      Message `json:"message"`
+
+	// Snippet is the matched text with some context around it, on one line
+	Snippet string `json:"snippet"`
+}
+
+// SearchSessions finds messages containing query, ignoring case, across all
+// saved sessions. Results come newest session first and in message order
+// within a session; limit caps their number unless it is zero.
+func (cl *ChatLogger) SearchSessions(query string, limit int) ([]SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, nil
+	}
+
+	sessions, err := cl.ListSessions(0)
+	if err != nil {
+		return nil, err
+	}
+
+	needle := strings.ToLower(query)
+	var results []SearchResult
+	for _, session := range sessions {
+		for i, message := range session.Messages {
+			at := strings.Index(strings.ToLower(message.Content), needle)
+			if at < 0 {
+				continue
+			}
+
+			results = append(results, SearchResult{
+				SessionID:    session.SessionID,
+				SessionTitle: session.Title,
+				SessionTime:  session.Timestamp,
+				MessageIndex: i,
+				Message:      message,
+				Snippet:      searchSnippet(message.Content, at, len(needle)),
+			})
+			if limit > 0 && len(results) >= limit {
+				return results, nil
+			}
+		}
+	}
+	return results, nil
+}
+
+// searchSnippet cuts the match at [at, at+length) out of content with
+// searchSnippetContext characters around it, flattened to one line
+func searchSnippet(content string, at, length int) string {
+	// Lowercasing can change byte lengths, so the match may not line up
+	// exactly with content
+	at = min(at, len(content))
+	start := at
+	for n := 0; start > 0 && n < searchSnippetContext; n++ {
+		_, size := utf8.DecodeLastRuneInString(content[:start])
+		start -= size
+	}
+	end := min(at+length, len(content))
+	for n := 0; end < len(content) && n < searchSnippetContext; n++ {
+		_, size := utf8.DecodeRuneInString(content[end:])
+		end += size
+	}
+
+	snippet := strings.Join(strings.Fields(content[start:end]), " ")
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(content) {
+		snippet += "…"
+	}
+	return snippet
+}
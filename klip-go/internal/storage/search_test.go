@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChatLogger_SearchSessions(t *testing.T) {
+	chatLogger, _ := setupTestChatLogger(t)
+
+	older := &ChatLog{
+		Timestamp: time.Now().Add(-48 * time.Hour),
+		SessionID: "older",
+		Title:     "Go generics",
+		Messages: []Message{
+			{Role: "user", Content: "How do generics work in Go?"},
+			{Role: "assistant", Content: "Type parameters let functions accept any type satisfying a constraint."},
+		},
+	}
+	newer := &ChatLog{
+		Timestamp: time.Now().Add(-time.Hour),
+		SessionID: "newer",
+		Messages: []Message{
+			{Role: "user", Content: "Unrelated question"},
+			{Role: "assistant", Content: "Rust GENERICS\nare monomorphized at compile time, " + strings.Repeat("much like C++ templates ", 5)},
+		},
+	}
+	for _, log := range []*ChatLog{older, newer} {
+		if err := chatLogger.writeLog(log); err != nil {
+			t.Fatalf("Failed to write session: %v", err)
+		}
+	}
+
+	results, err := chatLogger.SearchSessions("generics", 0)
+	if err != nil {
+		t.Fatalf("Failed to search: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d: %+v", len(results), results)
+	}
+
+	// Newest session first, with its context
+	if results[0].SessionID != "newer" || results[0].MessageIndex != 1 {
+		t.Errorf("Expected the newer session's reply first, got %+v", results[0])
+	}
+	if results[1].SessionID != "older" || results[1].SessionTitle != "Go generics" || results[1].MessageIndex != 0 {
+		t.Errorf("Expected the older session's question second, got %+v", results[1])
+	}
+
+	snippet := results[0].Snippet
+	if !strings.HasPrefix(snippet, "Rust GENERICS are") || !strings.HasSuffix(snippet, "…") || strings.Contains(snippet, "\n") {
+		t.Errorf("Expected a one-line snippet around the match, got %q", snippet)
+	}
+
+	limited, err := chatLogger.SearchSessions("generics", 1)
+	if err != nil || len(limited) != 1 {
+		t.Errorf("Expected the limit to apply, got %d results (%v)", len(limited), err)
+	}
+	if none, _ := chatLogger.SearchSessions("   ", 0); len(none) != 0 {
+		t.Errorf("Expected a blank query to match nothing, got %v", none)
+	}
+}
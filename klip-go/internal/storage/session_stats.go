@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"sort"
+	"time"
+)
+
+// SessionStats summarizes a single chat session, combining its messages with
+// the response metrics recorded by the analytics logger
+type SessionStats struct {
+	RoleCounts   map[string]int
+	Responses    int
+	TotalLatency time.Duration
+	TotalTokens  int
+	TotalCost    float64
+	Models       []string
+	Duration     time.Duration
+}
+
+// AverageLatency returns the mean response latency, or zero without responses
+func (s SessionStats) AverageLatency() time.Duration {
+	if s.Responses == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Responses)
+}
+
+// ComputeSessionStats builds the statistics for session from its messages
+// and the analytics response events recorded while it was active
+func ComputeSessionStats(session ChatSession, events []AnalyticsEvent) SessionStats {
+	stats := SessionStats{RoleCounts: make(map[string]int)}
+
+	models := make(map[string]bool)
+	var first, last time.Time
+	for _, msg := range session.Messages {
+		stats.RoleCounts[msg.Role]++
+		if msg.Model != "" {
+			models[msg.Model] = true
+		}
+		if msg.Tokens != nil {
+			stats.TotalTokens += msg.Tokens.Total
+		}
+		if !msg.Timestamp.IsZero() {
+			if first.IsZero() || msg.Timestamp.Before(first) {
+				first = msg.Timestamp
+			}
+			if msg.Timestamp.After(last) {
+				last = msg.Timestamp
+			}
+		}
+	}
+	stats.Duration = last.Sub(first)
+
+	// Analytics tokens are exact, so they replace the per-message counts
+	analyticsTokens := 0
+	for _, event := range events {
+		if event.EventType != "response" {
+			continue
+		}
+		stats.Responses++
+		if event.ModelID != "" {
+			models[event.ModelID] = true
+		}
+		if event.ResponseData != nil {
+			stats.TotalLatency += time.Duration(event.ResponseData.LatencyMs) * time.Millisecond
+			analyticsTokens += event.ResponseData.TotalTokens
+		}
+		if event.CostData != nil {
+			stats.TotalCost += event.CostData.EstimatedCostTotal
+		}
+	}
+	if analyticsTokens > 0 {
+		stats.TotalTokens = analyticsTokens
+	}
+
+	for model := range models {
+		stats.Models = append(stats.Models, model)
+	}
+	sort.Strings(stats.Models)
+
+	return stats
+}
+
+// SessionEvents returns the response events logged between start and end,
+// including ones not yet flushed to disk. Analytics and chat logs keep
+// separate session IDs, so a chat session is matched by its time span.
+func (al *AnalyticsLogger) SessionEvents(start, end time.Time) ([]AnalyticsEvent, error) {
+	// Hold the lock so the flush loop can't move events between the read
+	// and the buffer
+	al.mu.Lock()
+	events, err := al.GetAnalyticsData(start.Format("2006-01-02"), end.Format("2006-01-02"), "response")
+	if err != nil && len(al.pendingEvents) == 0 {
+		al.mu.Unlock()
+		return nil, err
+	}
+	events = append(events, al.pendingEvents...)
+	al.mu.Unlock()
+
+	var matched []AnalyticsEvent
+	for _, event := range events {
+		if event.EventType != "response" || event.Timestamp.Before(start) || event.Timestamp.After(end) {
+			continue
+		}
+		matched = append(matched, event)
+	}
+
+	return matched, nil
+}
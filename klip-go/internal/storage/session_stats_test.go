@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeSessionStatsRoleBreakdown(t *testing.T) {
+	start := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	session := ChatSession{
+		ID: "sample",
+		Messages: []Message{
+			{Role: "system", Content: "Be brief.", Timestamp: start},
+			{Role: "user", Content: "Hi", Timestamp: start.Add(time.Second)},
+			{Role: "assistant", Content: "Hello", Timestamp: start.Add(3 * time.Second), Model: "gpt-4o"},
+			{Role: "user", Content: "Bye", Timestamp: start.Add(time.Minute)},
+			{Role: "assistant", Content: "Goodbye", Timestamp: start.Add(2 * time.Minute), Model: "gpt-4o-mini"},
+		},
+	}
+	events := []AnalyticsEvent{
+		{EventType: "request", ModelID: "ignored"},
+		{EventType: "response", ModelID: "gpt-4o", ResponseData: &ResponseData{LatencyMs: 2000, TotalTokens: 30}, CostData: &CostData{EstimatedCostTotal: 0.01}},
+		{EventType: "response", ModelID: "gpt-4o-mini", ResponseData: &ResponseData{LatencyMs: 1000, TotalTokens: 20}, CostData: &CostData{EstimatedCostTotal: 0.002}},
+	}
+
+	stats := ComputeSessionStats(session, events)
+
+	want := map[string]int{"system": 1, "user": 2, "assistant": 2}
+	if len(stats.RoleCounts) != len(want) {
+		t.Fatalf("Expected %d roles, got %v", len(want), stats.RoleCounts)
+	}
+	for role, count := range want {
+		if stats.RoleCounts[role] != count {
+			t.Errorf("Expected %d %s messages, got %d", count, role, stats.RoleCounts[role])
+		}
+	}
+
+	if stats.Responses != 2 || stats.TotalLatency != 3*time.Second || stats.AverageLatency() != 1500*time.Millisecond {
+		t.Errorf("Unexpected latency: %d responses, %s total, %s average", stats.Responses, stats.TotalLatency, stats.AverageLatency())
+	}
+	if stats.TotalTokens != 50 {
+		t.Errorf("Expected 50 tokens, got %d", stats.TotalTokens)
+	}
+	if stats.TotalCost < 0.0119 || stats.TotalCost > 0.0121 {
+		t.Errorf("Expected cost 0.012, got %f", stats.TotalCost)
+	}
+	if stats.Duration != 2*time.Minute {
+		t.Errorf("Expected 2m duration, got %s", stats.Duration)
+	}
+	if len(stats.Models) != 2 || stats.Models[0] != "gpt-4o" || stats.Models[1] != "gpt-4o-mini" {
+		t.Errorf("Unexpected models: %v", stats.Models)
+	}
+}
+
+func TestAnalyticsLoggerSessionEvents(t *testing.T) {
+	analyticsLogger, _ := setupTestAnalyticsLogger(t)
+
+	start := time.Now().Add(-time.Minute)
+	request := RequestMetrics{StartTime: start.Add(-time.Hour), ModelID: "gpt-4o"}
+	analyticsLogger.LogResponse(request, ResponseMetrics{EndTime: start.Add(-time.Hour).Add(time.Second), Success: true})
+	request.StartTime = start.Add(10 * time.Second)
+	analyticsLogger.LogResponse(request, ResponseMetrics{EndTime: start.Add(12 * time.Second), Success: true})
+
+	events, err := analyticsLogger.SessionEvents(start, time.Now())
+	if err != nil {
+		t.Fatalf("SessionEvents failed: %v", err)
+	}
+	if len(events) != 1 || events[0].ResponseData.LatencyMs != 2000 {
+		t.Errorf("Expected only the response inside the session window, got %+v", events)
+	}
+}
@@ -13,13 +13,26 @@ type Storage struct {
 	ConfigManager   *ConfigManager
 	ChatLogger      *ChatLogger
 	AnalyticsLogger *AnalyticsLogger
+	UsageStore      *UsageStore
 	logger          *log.Logger
 }
 
-// New creates a new Storage instance with all components initialized
+// New creates a new Storage instance with all components initialized, for
+// the active profile
 func New() (*Storage, error) {
+	return NewForProfile(ActiveProfile())
+}
+
+// NewForProfile creates a Storage instance for the named profile, without
+// making it the active one
+func NewForProfile(profile string) (*Storage, error) {
 	logger := log.New(os.Stderr)
 
+	configDir, err := ProfileDir(profile)
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize KeyStore
 	keyStore, err := NewKeyStore()
 	if err != nil {
@@ -27,10 +40,7 @@ func New() (*Storage, error) {
 	}
 
 	// Initialize ConfigManager
-	configManager, err := NewConfigManager()
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize config manager: %w", err)
-	}
+	configManager := newConfigManagerIn(configDir)
 
 	// Load config to get analytics settings
 	config, err := configManager.LoadConfig()
@@ -40,7 +50,7 @@ func New() (*Storage, error) {
 	}
 
 	// Initialize ChatLogger
-	chatLogger, err := NewChatLogger()
+	chatLogger, err := newChatLoggerIn(configDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize chat logger: %w", err)
 	}
@@ -51,11 +61,14 @@ func New() (*Storage, error) {
 		analyticsConfig = config.Analytics
 	}
 
-	analyticsLogger, err := NewAnalyticsLogger(analyticsConfig)
+	analyticsLogger, err := newAnalyticsLoggerIn(configDir, analyticsConfig, generateSessionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize analytics logger: %w", err)
 	}
 
+	// Initialize UsageStore
+	usageStore := newUsageStoreIn(configDir)
+
 	// Attempt to migrate from Deno if needed
 	if err := configManager.MigrateFromDeno(); err != nil {
 		logger.Warn("Failed to migrate from Deno config", "error", err)
@@ -66,6 +79,7 @@ func New() (*Storage, error) {
 		ConfigManager:   configManager,
 		ChatLogger:      chatLogger,
 		AnalyticsLogger: analyticsLogger,
+		UsageStore:      usageStore,
 		logger:          logger,
 	}, nil
 }
@@ -76,6 +90,9 @@ func (s *Storage) Shutdown() error {
 		if err := s.AnalyticsLogger.LogSessionEnd(); err != nil {
 			s.logger.Warn("Failed to log session end", "error", err)
 		}
+		if err := s.AnalyticsLogger.Close(); err != nil {
+			s.logger.Warn("Failed to flush analytics", "error", err)
+		}
 	}
 
 	if s.ChatLogger != nil {
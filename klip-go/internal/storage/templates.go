@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Template is a reusable conversation setup, stored as
+// ~/.klip/templates/<name>.json
+type Template struct {
+	Name           string `json:"name"`
+	Description    string `json:"description,omitempty"`
+	SystemPrompt   string `json:"system_prompt"`
+	Model          string `json:"model,omitempty"`
+	StarterMessage string `json:"starter_message,omitempty"`
+}
+
+// templatesDir returns the directory holding conversation templates
+func templatesDir() (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return filepath.Join(configDir, "templates"), nil
+}
+
+// templatePath returns the file of the named template, rejecting names
+// that would leave the templates directory
+func templatePath(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid template name %q", name)
+	}
+	dir, err := templatesDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// ListTemplates returns the saved templates sorted by name
+func ListTemplates() ([]Template, error) {
+	dir, err := templatesDir()
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+
+	templates := make([]Template, 0, len(paths))
+	for _, path := range paths {
+		template, err := readTemplate(path)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *template)
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
+	return templates, nil
+}
+
+// LoadTemplate loads the named template
+func LoadTemplate(name string) (*Template, error) {
+	path, err := templatePath(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, fmt.Errorf("template %q not found", name)
+	}
+	return readTemplate(path)
+}
+
+// readTemplate parses a template file, naming it after the file when the
+// JSON leaves the name out
+func readTemplate(path string) (*Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template: %w", err)
+	}
+
+	var template Template
+	if err := json.Unmarshal(data, &template); err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", filepath.Base(path), err)
+	}
+	if template.Name == "" {
+		template.Name = strings.TrimSuffix(filepath.Base(path), ".json")
+	}
+	return &template, nil
+}
+
+// SaveTemplate writes template to the templates directory, replacing any
+// template of the same name
+func SaveTemplate(template *Template) error {
+	path, err := templatePath(template.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create templates directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(template, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal template: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write template: %w", err)
+	}
+	return nil
+}
+
+// DeleteTemplate removes the named template
+func DeleteTemplate(name string) error {
+	path, err := templatePath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("template %q not found", name)
+		}
+		return fmt.Errorf("failed to delete template: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTemplates_SaveListLoadDelete(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("HOME", tempDir)
+
+	templates, err := ListTemplates()
+	if err != nil {
+		t.Fatalf("Failed to list templates: %v", err)
+	}
+	if len(templates) != 0 {
+		t.Errorf("Expected no templates, got %v", templates)
+	}
+
+	reviewer := &Template{Name: "reviewer", SystemPrompt: "Act as a code reviewer.", Model: "gpt-4o"}
+	if err := SaveTemplate(reviewer); err != nil {
+		t.Fatalf("Failed to save template: %v", err)
+	}
+	if err := SaveTemplate(&Template{Name: "../escape"}); err == nil {
+		t.Error("Expected names with path separators to be rejected")
+	}
+
+	// Hand-written templates may leave the name to the file
+	path := filepath.Join(tempDir, ".klip", "templates", "editor.json")
+	if err := os.WriteFile(path, []byte(`{"system_prompt": "Edit for clarity."}`), 0600); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+
+	templates, err = ListTemplates()
+	if err != nil {
+		t.Fatalf("Failed to list templates: %v", err)
+	}
+	if len(templates) != 2 || templates[0].Name != "editor" || templates[1].Name != "reviewer" {
+		t.Errorf("Expected editor and reviewer, got %v", templates)
+	}
+
+	loaded, err := LoadTemplate("reviewer")
+	if err != nil {
+		t.Fatalf("Failed to load template: %v", err)
+	}
+	if *loaded != *reviewer {
+		t.Errorf("Expected %+v, got %+v", reviewer, loaded)
+	}
+
+	if err := DeleteTemplate("reviewer"); err != nil {
+		t.Fatalf("Failed to delete template: %v", err)
+	}
+	if _, err := LoadTemplate("reviewer"); err == nil {
+		t.Error("Expected deleted template to be gone")
+	}
+}
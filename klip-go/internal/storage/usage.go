@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// UsageTotals holds all-time token and cost totals
+type UsageTotals struct {
+	TotalTokens   int64     `json:"total_tokens"`
+	TotalCost     float64   `json:"total_cost"`
+	TotalRequests int64     `json:"total_requests"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// UsageStore persists cumulative usage totals across sessions
+type UsageStore struct {
+	usageFile string
+	mu        sync.Mutex
+}
+
+// NewUsageStore creates a new UsageStore backed by ~/.klip/usage.json
+func NewUsageStore() (*UsageStore, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config directory: %w", err)
+	}
+	return newUsageStoreIn(configDir), nil
+}
+
+// newUsageStoreIn creates a UsageStore keeping its totals in configDir
+func newUsageStoreIn(configDir string) *UsageStore {
+	return &UsageStore{
+		usageFile: filepath.Join(configDir, "usage.json"),
+	}
+}
+
+// Load returns the persisted totals, or zero totals if none exist yet
+func (us *UsageStore) Load() (*UsageTotals, error) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	return us.read()
+}
+
+// Record adds a completed response to the running totals and returns them
+func (us *UsageStore) Record(tokens int64, cost float64) (*UsageTotals, error) {
+	us.mu.Lock()
+	defer us.mu.Unlock()
+
+	// Re-read under the lock so concurrent records in this process each add
+	// to the latest totals. Another klip process writing at the same moment
+	// can still overwrite them; there is no cross-process lock.
+	totals, err := us.read()
+	if err != nil {
+		return nil, err
+	}
+
+	totals.TotalTokens += tokens
+	totals.TotalCost += cost
+	totals.TotalRequests++
+	totals.UpdatedAt = time.Now()
+
+	if err := us.write(totals); err != nil {
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+// read loads totals from disk; callers must hold the lock
+func (us *UsageStore) read() (*UsageTotals, error) {
+	data, err := os.ReadFile(us.usageFile)
+	if os.IsNotExist(err) {
+		return &UsageTotals{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage file: %w", err)
+	}
+
+	var totals UsageTotals
+	if err := json.Unmarshal(data, &totals); err != nil {
+		return nil, fmt.Errorf("failed to parse usage file: %w", err)
+	}
+
+	return &totals, nil
+}
+
+// write atomically replaces the usage file; callers must hold the lock
+func (us *UsageStore) write(totals *UsageTotals) error {
+	data, err := json.MarshalIndent(totals, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal usage totals: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(us.usageFile), "usage-*.json.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp usage file: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write usage file: %w", err)
+	}
+	if err := tmpFile.Chmod(0600); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to set usage file permissions: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to close usage file: %w", err)
+	}
+
+	if err := os.Rename(tmpFile.Name(), us.usageFile); err != nil {
+		return fmt.Errorf("failed to replace usage file: %w", err)
+	}
+
+	return nil
+}
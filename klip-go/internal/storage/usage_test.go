@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"os"
+	"sync"
+	"testing"
+)
+
+func setupTestUsageStore(t *testing.T) *UsageStore {
+	tempDir := t.TempDir()
+
+	oldHome := os.Getenv("HOME")
+	os.Setenv("HOME", tempDir)
+	t.Cleanup(func() {
+		os.Setenv("HOME", oldHome)
+	})
+
+	usageStore, err := NewUsageStore()
+	if err != nil {
+		t.Fatalf("Failed to create UsageStore: %v", err)
+	}
+
+	return usageStore
+}
+
+func TestUsageStore_LoadEmpty(t *testing.T) {
+	usageStore := setupTestUsageStore(t)
+
+	totals, err := usageStore.Load()
+	if err != nil {
+		t.Fatalf("Failed to load usage totals: %v", err)
+	}
+	if totals.TotalTokens != 0 || totals.TotalCost != 0 {
+		t.Errorf("Expected zero totals, got %+v", totals)
+	}
+}
+
+func TestUsageStore_AccumulatesAcrossSessions(t *testing.T) {
+	firstSession := setupTestUsageStore(t)
+	if _, err := firstSession.Record(100, 0.5); err != nil {
+		t.Fatalf("Failed to record usage: %v", err)
+	}
+
+	// A new store simulates the next run of the application
+	secondSession, err := NewUsageStore()
+	if err != nil {
+		t.Fatalf("Failed to create UsageStore: %v", err)
+	}
+	if _, err := secondSession.Record(50, 0.25); err != nil {
+		t.Fatalf("Failed to record usage: %v", err)
+	}
+
+	totals, err := secondSession.Load()
+	if err != nil {
+		t.Fatalf("Failed to load usage totals: %v", err)
+	}
+	if totals.TotalTokens != 150 {
+		t.Errorf("Expected 150 total tokens, got %d", totals.TotalTokens)
+	}
+	if totals.TotalCost != 0.75 {
+		t.Errorf("Expected total cost 0.75, got %f", totals.TotalCost)
+	}
+	if totals.TotalRequests != 2 {
+		t.Errorf("Expected 2 requests, got %d", totals.TotalRequests)
+	}
+}
+
+func TestUsageStore_ConcurrentRecords(t *testing.T) {
+	usageStore := setupTestUsageStore(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := usageStore.Record(10, 0); err != nil {
+				t.Errorf("Failed to record usage: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	totals, err := usageStore.Load()
+	if err != nil {
+		t.Fatalf("Failed to load usage totals: %v", err)
+	}
+	if totals.TotalTokens != 200 {
+		t.Errorf("Expected 200 total tokens, got %d", totals.TotalTokens)
+	}
+}
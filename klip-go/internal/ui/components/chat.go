@@ -2,17 +2,30 @@ package components
 
 import (
 	"fmt"
+	"math"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
 	"github.com/john/klip/internal/api"
 	"github.com/john/klip/internal/app"
+	"github.com/john/klip/internal/storage"
+	"github.com/john/klip/internal/ui/styles"
 )
 
+// writeClipboard is replaced in tests to keep them off the system clipboard
+var writeClipboard = clipboard.WriteAll
+
 // ContextMenu represents a context menu for messages
 type ContextMenu struct {
 	visible    bool
@@ -42,6 +55,7 @@ type ChatView struct {
 	messages      []api.Message
 	streamBuffer  string
 	isStreaming   bool
+	fence         streamFence
 	width         int
 	height        int
 	showTimestamp bool
@@ -57,6 +71,111 @@ type ChatView struct {
 	messageReactions map[int][]string
 	contextMenu      *ContextMenu
 	exportFormats    []string
+	keys             ChatKeyMap
+
+	// System prompt shown above the conversation
+	systemPrompt         string
+	systemPromptExpanded bool
+
+	// Relative timestamps are refreshed on a tick; tickID drops ticks from
+	// a previous toggle so only one refresh loop runs
+	relativeTime bool
+	tickID       int
+	now          func() time.Time
+
+	// Rendered message bodies keyed by role and content, so refreshing
+	// headers doesn't re-highlight every message
+	bodyCache map[string]string
+
+	// First content line of each message, rebuilt on every render
+	messageOffsets []int
+
+	// Goto prompt opened with ":"; message indices show in the gutter while active
+	gotoMode  bool
+	gotoInput string
+
+	// Reaction picker opened from the context menu; emojiSupported picks
+	// between emoji and shortcodes for the reactions it adds
+	reactionPicker *reactionPicker
+	emojiSupported bool
+
+	// Low bandwidth mode redraws streamed chunks on a flush tick instead of
+	// as they arrive. lowBandwidthMode is "auto", "on" or "off"; auto
+	// follows the terminal's detected bandwidth.
+	lowBandwidthMode     string
+	lowBandwidthDetected bool
+	streamDirty          bool
+	flushPending         bool
+	flushTickID          int
+
+	// "Assistant is typing" shown between sending and the first chunk
+	typingIndicator bool
+	reducedMotion   bool
+	typingFrame     int
+	typingTickID    int
+	interactive     *styles.InteractiveStyler
+
+	// Token and cost annotations in message headers
+	usageAnnotations bool
+
+	// Screen reader mode renders plain, role-prefixed text; largeText is
+	// the large text setting the viewport was last sized for
+	accessibility *styles.AccessibilityManager
+	largeText     bool
+
+	// Latest screen reader announcement, shown in its own plain line below
+	// the conversation; announcedAt throttles routine announcements
+	announcement string
+	announcedAt  time.Time
+
+	// Reading position per session, restored when its messages are set
+	// again; shownSession is the session currently in the viewport
+	sessionID       string
+	shownSession    string
+	scrollPositions map[string]scrollPosition
+}
+
+// streamFence follows fenced code blocks through the streaming buffer,
+// scanning each line once when its newline arrives
+type streamFence struct {
+	// scanned is the length of the buffer prefix holding complete lines
+	scanned int
+	open    bool
+}
+
+// scrollPosition is where the reader left a conversation
+type scrollPosition struct {
+	offset   int
+	atBottom bool
+}
+
+// typingFrameInterval is how often the typing indicator's dots advance
+const typingFrameInterval = 400 * time.Millisecond
+
+// typingTickMsg advances the typing indicator animation
+type typingTickMsg struct {
+	id int
+}
+
+// streamFlushInterval is how often buffered chunks are drawn in low
+// bandwidth mode
+const streamFlushInterval = 250 * time.Millisecond
+
+// streamFlushMsg draws the chunks buffered since the last flush
+type streamFlushMsg struct {
+	id int
+}
+
+// announceInterval is the minimum time between routine screen reader
+// announcements; errors are always announced
+const announceInterval = 2 * time.Second
+
+// timestampRefreshInterval is how often relative timestamps are re-rendered
+const timestampRefreshInterval = 30 * time.Second
+
+// timestampTickMsg triggers a refresh of relative timestamps
+type timestampTickMsg struct {
+	id int
 }
 
 // NewChatView creates a new chat view component
@@ -81,9 +200,26 @@ func NewChatView(width, height int) *ChatView {
 		messageReactions: make(map[int][]string),
 		exportFormats:    []string{"markdown", "text", "json", "html"},
 		contextMenu:      &ContextMenu{},
+		keys:             DefaultChatKeyMap(),
+		now:              time.Now,
+		bodyCache:        make(map[string]string),
+		typingIndicator:  true,
+		usageAnnotations: true,
+		scrollPositions:  make(map[string]scrollPosition),
+		emojiSupported:   true,
 	}
 }
 
+// KeyMap returns the chat view keybindings
+func (cv *ChatView) KeyMap() help.KeyMap {
+	return cv.keys
+}
+
+// SetKeyMap replaces the chat keybindings
+func (cv *ChatView) SetKeyMap(keys ChatKeyMap) {
+	cv.keys = keys
+}
+
 // Init initializes the chat view
 func (cv *ChatView) Init() tea.Cmd {
 	return nil
@@ -95,34 +231,76 @@ func (cv *ChatView) Update(msg tea.Msg) (*ChatView, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		cv.width = msg.Width
-		cv.height = msg.Height
-		cv.viewport.Width = msg.Width
-		cv.viewport.Height = msg.Height - 2
+		cv.Resize(msg.Width, msg.Height)
+
+	case timestampTickMsg:
+		if msg.id != cv.tickID || !cv.relativeTime {
+			return cv, nil
+		}
+		if cv.showTimestamp {
+			cv.refreshTimestamps()
+		}
+		return cv, cv.timestampTick()
+
+	case typingTickMsg:
+		if msg.id != cv.typingTickID || !cv.awaitingFirstChunk() {
+			return cv, nil
+		}
+		cv.typingFrame++
 		cv.updateContent()
+		if cv.autoScroll {
+			cv.viewport.GotoBottom()
+		}
+		return cv, cv.typingTick()
+
+	case streamFlushMsg:
+		cv.flushStream(msg.id)
 
 	case ChatViewMsg:
 		switch msg.Type {
+		case "goto":
+			switch target := msg.Data.(type) {
+			case int:
+				cv.GotoMessage(target)
+			case string:
+				if n, ok := ParseGotoCommand(target); ok {
+					cv.GotoMessage(n)
+				}
+			}
 		case "add_message":
 			if apiMsg, ok := msg.Data.(api.Message); ok {
 				cv.AddMessage(apiMsg)
 			}
 		case "stream_chunk":
 			if chunk, ok := msg.Data.(string); ok {
-				cv.AddStreamChunk(chunk)
+				return cv, cv.bufferStreamChunk(chunk)
 			}
 		case "stream_start":
 			cv.StartStreaming()
+			return cv, cv.typingTick()
 		case "stream_end":
 			cv.EndStreaming()
+		case "stream_error":
+			cv.EndStreaming()
+			if errText, ok := msg.Data.(string); ok {
+				cv.AnnounceError(errText)
+			}
 		case "clear":
 			cv.Clear()
 		case "toggle_timestamp":
 			cv.ToggleTimestamp()
+		case "toggle_relative_time":
+			return cv, cv.ToggleRelativeTime()
 		case "toggle_line_numbers":
 			cv.ToggleLineNumbers()
 		case "toggle_word_wrap":
 			cv.ToggleWordWrap()
+		case "set_system_prompt":
+			if prompt, ok := msg.Data.(string); ok {
+				cv.SetSystemPrompt(prompt)
+			}
+		case "toggle_system_prompt":
+			cv.ToggleSystemPrompt()
 		case "set_theme":
 			if theme, ok := msg.Data.(string); ok {
 				cv.SetTheme(theme)
@@ -131,6 +309,10 @@ func (cv *ChatView) Update(msg tea.Msg) (*ChatView, tea.Cmd) {
 			if query, ok := msg.Data.(string); ok {
 				cv.SetSearchHighlight(query)
 			}
+		case "reaction_prompt":
+			if messageIdx, ok := msg.Data.(int); ok {
+				cv.openReactionPicker(messageIdx)
+			}
 		case "add_reaction":
 			if data, ok := msg.Data.(map[string]interface{}); ok {
 				messageIdx := data["message"].(int)
@@ -139,76 +321,88 @@ func (cv *ChatView) Update(msg tea.Msg) (*ChatView, tea.Cmd) {
 			}
 		case "export_message":
 			if data, ok := msg.Data.(map[string]interface{}); ok {
-				messageIdx := data["message"].(int)
-				format := data["format"].(string)
+				messageIdx, _ := data["message"].(int)
+				format, ok := data["format"].(string)
+				if !ok {
+					format = "markdown"
+				}
 				return cv, cv.exportMessage(messageIdx, format)
 			}
 		}
 
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "j", "down":
+		if cv.gotoMode {
+			cv.handleGotoKey(msg)
+			return cv, nil
+		}
+		if cv.reactionPicker != nil {
+			cv.handleReactionPickerKey(msg)
+			return cv, nil
+		}
+
+		switch {
+		case cv.contextMenu.visible && key.Matches(msg, cv.keys.Up):
+			cv.navigateContextMenu(-1)
+		case cv.contextMenu.visible && key.Matches(msg, cv.keys.Down):
+			cv.navigateContextMenu(1)
+		case key.Matches(msg, cv.keys.Down):
 			cv.viewport.LineDown(1)
-		case "k", "up":
+		case key.Matches(msg, cv.keys.Up):
 			cv.viewport.LineUp(1)
-		case "d", "pgdown":
+		case key.Matches(msg, cv.keys.HalfPageDown):
 			cv.viewport.HalfViewDown()
-		case "u", "pgup":
+		case key.Matches(msg, cv.keys.HalfPageUp):
 			cv.viewport.HalfViewUp()
-		case "g":
+		case key.Matches(msg, cv.keys.Top):
 			cv.viewport.GotoTop()
-		case "G":
+		case key.Matches(msg, cv.keys.Bottom):
 			cv.viewport.GotoBottom()
-		case "t":
+		case key.Matches(msg, cv.keys.GotoMessage):
+			cv.setGotoMode(true)
+		case key.Matches(msg, cv.keys.ToggleTimestamp):
 			cv.ToggleTimestamp()
-		case "l":
+		case key.Matches(msg, cv.keys.ToggleRelativeTime):
+			return cv, cv.ToggleRelativeTime()
+		case key.Matches(msg, cv.keys.ToggleLineNumbers):
 			cv.ToggleLineNumbers()
-		case "w":
+		case key.Matches(msg, cv.keys.ToggleWordWrap):
 			cv.ToggleWordWrap()
-		case "y":
+		case key.Matches(msg, cv.keys.ToggleSystemPrompt):
+			cv.ToggleSystemPrompt()
+		case key.Matches(msg, cv.keys.Copy):
 			if cv.selectedMessage >= 0 && cv.selectedMessage < len(cv.messages) {
 				return cv, cv.copyMessage(cv.selectedMessage)
 			}
-		case "Y":
-			return cv, cv.copyAllMessages()
-		case "r":
+		case key.Matches(msg, cv.keys.CopyAll):
+			return cv, cv.copyAllMessages(false)
+		case key.Matches(msg, cv.keys.CopyReplies):
+			return cv, cv.copyAllMessages(true)
+		case key.Matches(msg, cv.keys.Actions):
 			if cv.selectedMessage >= 0 && cv.selectedMessage < len(cv.messages) {
 				cv.showContextMenu(cv.selectedMessage)
 			}
-		case "enter":
+		case msg.String() == "enter":
 			if cv.contextMenu.visible {
 				return cv, cv.executeContextAction()
 			} else if cv.selectedMessage >= 0 {
 				cv.showContextMenu(cv.selectedMessage)
 			}
-		case "esc":
+		case msg.String() == "esc":
 			if cv.contextMenu.visible {
 				cv.contextMenu.visible = false
 			} else {
 				cv.selectedMessage = -1
 			}
-		case "space":
+		case key.Matches(msg, cv.keys.Select):
 			cv.toggleMessageSelection()
-		case "/":
+		case key.Matches(msg, cv.keys.Search):
 			return cv, cv.startSearch()
-		case "n":
+		case key.Matches(msg, cv.keys.NextResult):
 			cv.nextSearchResult()
-		case "N":
+		case key.Matches(msg, cv.keys.PrevResult):
 			cv.prevSearchResult()
 		default:
-			// Handle context menu navigation
-			if cv.contextMenu.visible {
-				switch msg.String() {
-				case "up", "k":
-					cv.navigateContextMenu(-1)
-				case "down", "j":
-					cv.navigateContextMenu(1)
-				default:
-					cv.viewport, cmd = cv.viewport.Update(msg)
-				}
-			} else {
-				cv.viewport, cmd = cv.viewport.Update(msg)
-			}
+			cv.viewport, cmd = cv.viewport.Update(msg)
 		}
 	default:
 		cv.viewport, cmd = cv.viewport.Update(msg)
@@ -219,7 +413,100 @@ func (cv *ChatView) Update(msg tea.Msg) (*ChatView, tea.Cmd) {
 
 // View renders the chat view
 func (cv *ChatView) View() string {
-	return ChatContainerStyle.Render(cv.viewport.View())
+	cv.syncLargeText()
+
+	container := cv.accessibility.LargeTextStyle(ChatContainerStyle)
+	if cv.accessibility.IsScreenReaderEnabled() {
+		container = lipgloss.NewStyle()
+	}
+
+	content := cv.viewport.View()
+	if cv.gotoMode {
+		prompt := GotoPromptStyle.Render(fmt.Sprintf(":%s", cv.gotoInput)) +
+			LineNumberStyle.Render(fmt.Sprintf("  1-%d, enter to jump, esc to cancel", len(cv.messages)))
+		content += "\n" + prompt
+	}
+	if cv.reactionPicker != nil {
+		content += "\n" + cv.renderReactionPicker()
+	}
+	if cv.showsAnnouncement() {
+		content += "\n" + cv.announcement
+	}
+	return container.Render(content)
+}
+
+// Announcement returns the latest screen reader announcement
+func (cv *ChatView) Announcement() string {
+	return cv.announcement
+}
+
+// AnnounceError tells screen reader users that a response failed
+func (cv *ChatView) AnnounceError(errText string) {
+	cv.announce("Error: "+errText, true)
+}
+
+// announce replaces the announcement line in screen reader mode. Routine
+// announcements arriving within announceInterval of the last are dropped.
+func (cv *ChatView) announce(text string, urgent bool) {
+	if !cv.accessibility.IsScreenReaderEnabled() {
+		return
+	}
+	now := cv.now()
+	if !urgent && now.Sub(cv.announcedAt) < announceInterval {
+		return
+	}
+
+	cv.announcement = text
+	cv.announcedAt = now
+	cv.viewport.Height = cv.viewportHeight()
+}
+
+// showsAnnouncement reports whether the announcement line is drawn
+func (cv *ChatView) showsAnnouncement() bool {
+	return cv.announcement != "" && cv.accessibility.IsScreenReaderEnabled()
+}
+
+// completionAnnouncement summarizes a finished response by its paragraphs
+func completionAnnouncement(content string) string {
+	paragraphs := 0
+	for _, block := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n\n") {
+		if strings.TrimSpace(block) != "" {
+			paragraphs++
+		}
+	}
+	if paragraphs == 1 {
+		return "Response complete, 1 paragraph"
+	}
+	return fmt.Sprintf("Response complete, %d paragraphs", paragraphs)
+}
+
+// SetAccessibility sets the manager whose screen reader mode decides how
+// messages are rendered
+func (cv *ChatView) SetAccessibility(am *styles.AccessibilityManager) {
+	cv.accessibility = am
+	cv.viewport.Height = cv.viewportHeight()
+	cv.updateContent()
+	cv.syncLargeText()
+}
+
+// syncLargeText resizes the viewport when large text was turned on or off
+// since it was last sized, so the wider padding still fits
+func (cv *ChatView) syncLargeText() {
+	if cv.accessibility.IsLargeTextEnabled() != cv.largeText {
+		cv.largeText = !cv.largeText
+		cv.Resize(cv.width, cv.height)
+	}
+}
+
+// largeTextPadding returns the rows and columns large text adds to the
+// panel's padding
+func (cv *ChatView) largeTextPadding() (int, int) {
+	if !cv.largeText {
+		return 0, 0
+	}
+	padded := cv.accessibility.LargeTextStyle(ChatContainerStyle)
+	return padded.GetVerticalPadding() - ChatContainerStyle.GetVerticalPadding(),
+		padded.GetHorizontalPadding() - ChatContainerStyle.GetHorizontalPadding()
 }
 
 // AddMessage adds a new message to the chat
@@ -234,17 +521,149 @@ func (cv *ChatView) AddMessage(msg api.Message) {
 // AddStreamChunk adds a chunk to the streaming buffer
 func (cv *ChatView) AddStreamChunk(chunk string) {
 	cv.streamBuffer += chunk
+	cv.streamDirty = false
 	cv.updateContent()
 	if cv.autoScroll {
 		cv.viewport.GotoBottom()
 	}
 }
 
+// bufferStreamChunk adds a chunk to the streaming buffer. In low bandwidth
+// mode it is drawn on the next flush, scheduled by the first chunk since
+// the last one, rather than right away.
+func (cv *ChatView) bufferStreamChunk(chunk string) tea.Cmd {
+	if !cv.LowBandwidth() {
+		cv.AddStreamChunk(chunk)
+		return nil
+	}
+
+	cv.streamBuffer += chunk
+	cv.streamDirty = true
+	if cv.flushPending {
+		return nil
+	}
+	cv.flushPending = true
+	id := cv.flushTickID
+	return tea.Tick(streamFlushInterval, func(time.Time) tea.Msg {
+		return streamFlushMsg{id: id}
+	})
+}
+
+// flushStream draws the chunks buffered since the last flush, ignoring
+// ticks scheduled before streaming restarted
+func (cv *ChatView) flushStream(id int) {
+	if id != cv.flushTickID {
+		return
+	}
+	cv.flushPending = false
+	if !cv.streamDirty {
+		return
+	}
+	cv.streamDirty = false
+	cv.updateContent()
+	if cv.autoScroll {
+		cv.viewport.GotoBottom()
+	}
+}
+
+// resetStreamFlush drops buffered-chunk bookkeeping when the buffer is
+// replaced or drawn in full
+func (cv *ChatView) resetStreamFlush() {
+	cv.streamDirty = false
+	cv.flushPending = false
+	cv.flushTickID++
+}
+
+// SetStyler adapts reactions and streaming redraws to the terminal's
+// capabilities
+func (cv *ChatView) SetStyler(styler *styles.AdaptiveStyler) {
+	caps := styler.GetCapabilities()
+	cv.emojiSupported = caps.SupportsEmoji
+	cv.lowBandwidthDetected = caps.HasLowBandwidth
+}
+
+// SetLowBandwidthMode sets low bandwidth mode to "on", "off" or "auto",
+// which follows the terminal
+func (cv *ChatView) SetLowBandwidthMode(mode string) {
+	cv.lowBandwidthMode = mode
+	if !cv.LowBandwidth() && cv.streamDirty {
+		cv.resetStreamFlush()
+		cv.updateContent()
+	}
+}
+
+// LowBandwidth reports whether streamed chunks are buffered and
+// animations are off
+func (cv *ChatView) LowBandwidth() bool {
+	switch cv.lowBandwidthMode {
+	case "on":
+		return true
+	case "off":
+		return false
+	default:
+		return cv.lowBandwidthDetected
+	}
+}
+
 // StartStreaming begins streaming mode
 func (cv *ChatView) StartStreaming() {
 	cv.isStreaming = true
 	cv.streamBuffer = ""
+	cv.fence = streamFence{}
+	cv.resetStreamFlush()
+	cv.typingFrame = 0
+	cv.typingTickID++
 	cv.updateContent()
+	if cv.autoScroll {
+		cv.viewport.GotoBottom()
+	}
+}
+
+// SetTypingIndicator controls the indicator shown while waiting for a
+// response. With reduced motion it is drawn without animating.
+func (cv *ChatView) SetTypingIndicator(enabled, reducedMotion bool) {
+	cv.typingIndicator = enabled
+	cv.reducedMotion = reducedMotion
+	cv.updateContent()
+}
+
+// SetUsageAnnotations shows or hides the token and cost annotations in
+// message headers
+func (cv *ChatView) SetUsageAnnotations(enabled bool) {
+	cv.usageAnnotations = enabled
+	cv.updateContent()
+}
+
+// awaitingFirstChunk reports whether a response has been requested but no
+// text has arrived yet
+func (cv *ChatView) awaitingFirstChunk() bool {
+	return cv.typingIndicator && cv.isStreaming && cv.streamBuffer == ""
+}
+
+// typingTick schedules the next typing indicator frame
+func (cv *ChatView) typingTick() tea.Cmd {
+	if !cv.awaitingFirstChunk() || cv.reducedMotion || cv.LowBandwidth() {
+		return nil
+	}
+
+	id := cv.typingTickID
+	return tea.Tick(typingFrameInterval, func(time.Time) tea.Msg {
+		return typingTickMsg{id: id}
+	})
+}
+
+// renderTypingIndicator renders "Assistant is typing" with animated dots
+func (cv *ChatView) renderTypingIndicator() string {
+	dots := "..."
+	if !cv.reducedMotion && !cv.LowBandwidth() {
+		if cv.interactive == nil {
+			cv.interactive = styles.NewInteractiveStyler(styles.GetCurrentTheme(), cv.width, cv.height)
+		}
+		// AnimatedLoadingDots cycles through three frames over one unit of progress
+		dots = cv.interactive.AnimatedLoadingDots(float64(cv.typingFrame%3) / 3)
+	}
+
+	return AssistantMessageHeaderStyle.Render("Assistant") + " " + TypingIndicatorStyle.Render("is typing") + dots
 }
 
 // EndStreaming ends streaming mode and finalizes the message
@@ -256,17 +675,153 @@ func (cv *ChatView) EndStreaming() {
 			Timestamp: time.Now(),
 		}
 		cv.messages = append(cv.messages, msg)
+		cv.announce(completionAnnouncement(cv.streamBuffer), false)
 	}
 	cv.isStreaming = false
 	cv.streamBuffer = ""
+	cv.fence = streamFence{}
+	cv.resetStreamFlush()
 	cv.updateContent()
 }
 
 // Clear clears all messages
 func (cv *ChatView) Clear() {
 	cv.messages = make([]api.Message, 0)
+	cv.bodyCache = make(map[string]string)
 	cv.streamBuffer = ""
+	cv.fence = streamFence{}
 	cv.isStreaming = false
+	cv.resetStreamFlush()
+	cv.updateContent()
+}
+
+// Resize relayouts the chat, keeping the reader at the bottom if they were
+// following the conversation and at the same offset otherwise
+func (cv *ChatView) Resize(width, height int) {
+	atBottom := cv.viewport.AtBottom()
+	offset := cv.viewport.YOffset
+
+	cv.width = width
+	cv.height = height
+	_, columns := cv.largeTextPadding()
+	cv.viewport.Width = width - columns
+	cv.viewport.Height = cv.viewportHeight()
+	cv.updateContent()
+
+	if atBottom {
+		cv.viewport.GotoBottom()
+	} else {
+		cv.viewport.SetYOffset(offset)
+	}
+}
+
+// viewportHeight leaves room for borders, large text padding and, while
+// shown, the goto prompt, reaction picker and announcement line
+func (cv *ChatView) viewportHeight() int {
+	rows, _ := cv.largeTextPadding()
+	if cv.gotoMode {
+		rows++
+	}
+	if cv.reactionPicker != nil {
+		rows++
+	}
+	if cv.showsAnnouncement() {
+		rows++
+	}
+	return cv.height - 2 - rows
+}
+
+// ParseGotoCommand reads a message number from ":N" or "/goto N"
+func ParseGotoCommand(input string) (int, bool) {
+	input = strings.TrimSpace(input)
+	switch {
+	case strings.HasPrefix(input, ":"):
+		input = input[1:]
+	case strings.HasPrefix(input, "/goto"):
+		input = input[len("/goto"):]
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(input))
+	if err != nil || n < 1 {
+		return 0, false
+	}
+	return n, true
+}
+
+// GotoMessage scrolls so the header of the nth message (1-based) is at the
+// top of the viewport. Out of range numbers are clamped to the conversation.
+func (cv *ChatView) GotoMessage(n int) {
+	if len(cv.messageOffsets) == 0 {
+		return
+	}
+
+	if n < 1 {
+		n = 1
+	} else if n > len(cv.messageOffsets) {
+		n = len(cv.messageOffsets)
+	}
+
+	cv.autoScroll = n == len(cv.messageOffsets)
+	cv.selectedMessage = n - 1
+	cv.viewport.SetYOffset(cv.messageOffsets[n-1])
+}
+
+// MessageAtLine returns the index of the message rendered on a content line,
+// or -1 above the first message
+func (cv *ChatView) MessageAtLine(line int) int {
+	idx := sort.Search(len(cv.messageOffsets), func(i int) bool {
+		return cv.messageOffsets[i] > line
+	})
+	return idx - 1
+}
+
+// setGotoMode opens or closes the goto prompt, keeping the scroll position
+func (cv *ChatView) setGotoMode(active bool) {
+	if cv.gotoMode == active {
+		return
+	}
+
+	offset := cv.viewport.YOffset
+	cv.gotoMode = active
+	cv.gotoInput = ""
+	cv.viewport.Height = cv.viewportHeight()
+	cv.updateContent()
+	cv.viewport.SetYOffset(offset)
+}
+
+// handleGotoKey edits the goto prompt and jumps on enter
+func (cv *ChatView) handleGotoKey(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		cv.setGotoMode(false)
+	case tea.KeyEnter:
+		n, ok := ParseGotoCommand(cv.gotoInput)
+		cv.setGotoMode(false)
+		if ok {
+			cv.GotoMessage(n)
+		}
+	case tea.KeyBackspace:
+		if cv.gotoInput != "" {
+			cv.gotoInput = cv.gotoInput[:len(cv.gotoInput)-1]
+		}
+	case tea.KeyRunes:
+		for _, r := range msg.Runes {
+			if r >= '0' && r <= '9' && len(cv.gotoInput) < 6 {
+				cv.gotoInput += string(r)
+			}
+		}
+	}
+}
+
+// SetSystemPrompt sets the system prompt shown above the conversation
+func (cv *ChatView) SetSystemPrompt(prompt string) {
+	cv.systemPrompt = strings.TrimSpace(prompt)
+	cv.updateContent()
+}
+
+// ToggleSystemPrompt expands or collapses the system prompt
+func (cv *ChatView) ToggleSystemPrompt() {
+	cv.systemPromptExpanded = !cv.systemPromptExpanded
 	cv.updateContent()
 }
 
@@ -276,11 +831,67 @@ func (cv *ChatView) ToggleTimestamp() {
 	cv.updateContent()
 }
 
-// SetMessages sets the messages directly
+// ToggleRelativeTime switches timestamps between "15:04:05" and "2 minutes ago",
+// returning the command that keeps relative times current
+func (cv *ChatView) ToggleRelativeTime() tea.Cmd {
+	cv.relativeTime = !cv.relativeTime
+	cv.tickID++
+	cv.updateContent()
+
+	if cv.relativeTime {
+		return cv.timestampTick()
+	}
+	return nil
+}
+
+// timestampTick schedules the next relative timestamp refresh
+func (cv *ChatView) timestampTick() tea.Cmd {
+	id := cv.tickID
+	return tea.Tick(timestampRefreshInterval, func(time.Time) tea.Msg {
+		return timestampTickMsg{id: id}
+	})
+}
+
+// refreshTimestamps re-renders headers while keeping the reader's position
+func (cv *ChatView) refreshTimestamps() {
+	atBottom := cv.viewport.AtBottom()
+	offset := cv.viewport.YOffset
+
+	cv.updateContent()
+
+	if atBottom {
+		cv.viewport.GotoBottom()
+	} else {
+		cv.viewport.SetYOffset(offset)
+	}
+}
+
+// SetSessionID names the conversation the next SetMessages shows, so each
+// session keeps its own scroll position
+func (cv *ChatView) SetSessionID(id string) {
+	cv.sessionID = id
+}
+
+// SetMessages sets the messages directly, returning to where the reader
+// left this session. Auto-scroll applies only if they were at the bottom.
 func (cv *ChatView) SetMessages(messages []api.Message) {
+	if len(cv.messages) > 0 {
+		cv.scrollPositions[cv.shownSession] = scrollPosition{
+			offset:   cv.viewport.YOffset,
+			atBottom: cv.viewport.AtBottom(),
+		}
+	}
+
 	cv.messages = messages
+	cv.bodyCache = make(map[string]string)
 	cv.updateContent()
-	if cv.autoScroll {
+	cv.shownSession = cv.sessionID
+
+	position, ok := cv.scrollPositions[cv.sessionID]
+	switch {
+	case ok && !position.atBottom:
+		cv.viewport.SetYOffset(position.offset)
+	case cv.autoScroll:
 		cv.viewport.GotoBottom()
 	}
 }
@@ -292,14 +903,40 @@ func (cv *ChatView) GetMessages() []api.Message {
 
 // updateContent updates the viewport content
 func (cv *ChatView) updateContent() {
+	if cv.accessibility.IsScreenReaderEnabled() {
+		cv.updateScreenReaderContent()
+		return
+	}
+
 	var content strings.Builder
+	lines := 0
+	write := func(s string) {
+		content.WriteString(s)
+		lines += strings.Count(s, "\n")
+	}
 
+	if cv.systemPrompt != "" {
+		write(cv.renderSystemPrompt())
+		if len(cv.messages) > 0 || cv.isStreaming {
+			write("\n\n")
+		}
+	}
+
+	cv.messageOffsets = cv.messageOffsets[:0]
 	for i, msg := range cv.messages {
-		rendered := cv.renderMessage(msg, i == len(cv.messages)-1)
-		content.WriteString(rendered)
+		cv.messageOffsets = append(cv.messageOffsets, lines)
+		write(cv.renderMessage(msg, i, i == len(cv.messages)-1))
 		if i < len(cv.messages)-1 {
+			write("\n")
+		}
+	}
+
+	// Show the typing indicator until the first chunk replaces it
+	if cv.awaitingFirstChunk() {
+		if len(cv.messages) > 0 {
 			content.WriteString("\n")
 		}
+		content.WriteString(cv.renderTypingIndicator())
 	}
 
 	// Add streaming content if active
@@ -309,28 +946,208 @@ func (cv *ChatView) updateContent() {
 		}
 		streamMsg := api.Message{
 			Role:      "assistant",
-			Content:   cv.streamBuffer,
+			Content:   cv.streamingContent(),
 			Timestamp: time.Now(),
 		}
-		content.WriteString(cv.renderMessage(streamMsg, true))
+		content.WriteString(cv.renderMessage(streamMsg, -1, true))
 		content.WriteString(StreamingIndicatorStyle.Render(" ▋"))
 	}
 
 	cv.viewport.SetContent(content.String())
 }
 
+// updateScreenReaderContent fills the viewport with the conversation as
+// plain text: each message starts with its role, and code fences and
+// headings are announced rather than drawn
+func (cv *ChatView) updateScreenReaderContent() {
+	var blocks []string
+	lines := 0
+	add := func(block string) {
+		if width := cv.width - 4; width > 0 {
+			block = lipgloss.NewStyle().Width(width).Render(block)
+		}
+		blocks = append(blocks, block)
+		lines += strings.Count(block, "\n") + 2
+	}
+
+	if cv.systemPrompt != "" {
+		add("System prompt: " + cv.linearize(cv.systemPrompt))
+	}
+
+	cv.messageOffsets = cv.messageOffsets[:0]
+	for i, msg := range cv.messages {
+		cv.messageOffsets = append(cv.messageOffsets, lines)
+		add(cv.renderScreenReaderMessage(msg, i))
+	}
+
+	if cv.awaitingFirstChunk() {
+		add("Assistant is typing.")
+	}
+	if cv.isStreaming && cv.streamBuffer != "" {
+		add("Assistant, still writing: " + cv.linearize(cv.streamingContent()))
+	}
+
+	cv.viewport.SetContent(strings.Join(blocks, "\n\n"))
+}
+
+// renderScreenReaderMessage renders a message as "Role: content", with its
+// position while navigating and its time when timestamps are shown
+func (cv *ChatView) renderScreenReaderMessage(msg api.Message, index int) string {
+	role := strings.Title(msg.Role)
+	if msg.Role == "user" {
+		role = "You"
+	}
+
+	var details []string
+	if cv.gotoMode {
+		details = append(details, fmt.Sprintf("message %d of %d", index+1, len(cv.messages)))
+	}
+	if cv.showTimestamp && !msg.Timestamp.IsZero() {
+		details = append(details, "sent "+cv.formatTimestamp(msg.Timestamp))
+	}
+	if len(details) > 0 {
+		role += ", " + strings.Join(details, ", ")
+	}
+
+	text := role + ": " + cv.linearize(msg.Content)
+	if msg.Truncated {
+		text += "\nReply cut off at the length limit; type /continue for more."
+	}
+	if reactions := cv.messageReactions[index]; len(reactions) > 0 {
+		text += "\nReactions: " + strings.Join(reactions, " ")
+	}
+	return text
+}
+
+// linearize turns markdown into text a screen reader reads in order:
+// fences become "Code block" announcements, headings state their level and
+// horizontal rules are dropped
+func (cv *ChatView) linearize(content string) string {
+	var lines []string
+	inCode := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case cv.isCodeBlockDelimiter(line):
+			if inCode {
+				lines = append(lines, "End of code block.")
+			} else if language := cv.extractCodeLanguage(line); language != "" {
+				lines = append(lines, "Code block, "+language+":")
+			} else {
+				lines = append(lines, "Code block:")
+			}
+			inCode = !inCode
+		case inCode:
+			lines = append(lines, line)
+		case strings.Trim(trimmed, "-*_ ") == "" && len(trimmed) >= 3:
+			// Horizontal rule
+		case strings.HasPrefix(trimmed, "#"):
+			level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+			text := strings.TrimSpace(trimmed[level:])
+			if level > 6 || text == "" {
+				lines = append(lines, line)
+				continue
+			}
+			lines = append(lines, cv.accessibility.CreateHeadingHierarchy(level, text))
+		default:
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// streamingContent returns the part of the streaming buffer that renders
+// stably. A trailing line that may still grow into a fence delimiter is
+// held back until its newline arrives: inside a block only a run of
+// backticks can close it, while outside one "```lang" is held too so the
+// block opens with its language known.
+func (cv *ChatView) streamingContent() string {
+	cv.advanceStreamFence()
+
+	pending := strings.TrimSpace(cv.streamBuffer[cv.fence.scanned:])
+	if pending == "" {
+		return cv.streamBuffer
+	}
+
+	partial := strings.Trim(pending, "`") == ""
+	if !cv.fence.open && strings.HasPrefix(pending, "```") {
+		partial = true
+	}
+	if partial {
+		return cv.streamBuffer[:cv.fence.scanned]
+	}
+	return cv.streamBuffer
+}
+
+// advanceStreamFence scans the lines completed since the last call,
+// starting over if the buffer was replaced
+func (cv *ChatView) advanceStreamFence() {
+	if len(cv.streamBuffer) < cv.fence.scanned {
+		cv.fence = streamFence{}
+	}
+
+	end := strings.LastIndexByte(cv.streamBuffer, '\n') + 1
+	for cv.fence.scanned < end {
+		next := cv.fence.scanned + strings.IndexByte(cv.streamBuffer[cv.fence.scanned:], '\n')
+		if cv.isCodeBlockDelimiter(cv.streamBuffer[cv.fence.scanned:next]) {
+			cv.fence.open = !cv.fence.open
+		}
+		cv.fence.scanned = next + 1
+	}
+}
+
+// renderSystemPrompt renders the system prompt, collapsed to its first line unless expanded
+func (cv *ChatView) renderSystemPrompt() string {
+	header := SystemMessageHeaderStyle.Render("System")
+
+	if cv.systemPromptExpanded {
+		return header + " ▾\n" + cv.renderMessageContent(cv.systemPrompt, "system")
+	}
+
+	preview := strings.SplitN(cv.systemPrompt, "\n", 2)[0]
+	maxWidth := cv.width - 16
+	if maxWidth < 10 {
+		maxWidth = 10
+	}
+	if len([]rune(preview)) > maxWidth || strings.Contains(cv.systemPrompt, "\n") {
+		runes := []rune(preview)
+		if len(runes) > maxWidth {
+			runes = runes[:maxWidth]
+		}
+		preview = string(runes) + "…"
+	}
+
+	return header + " ▸ " + SystemMessageStyle.Render(preview)
+}
+
+// truncatedMarker follows replies that stopped at the token limit
+const truncatedMarker = "…(truncated) · /continue for more"
+
 // renderMessage renders a single message with appropriate styling
-func (cv *ChatView) renderMessage(msg api.Message, isLast bool) string {
+func (cv *ChatView) renderMessage(msg api.Message, index int, isLast bool) string {
 	var content strings.Builder
 
-	// Message header
+	// Message header, with its number in the gutter while navigating
 	header := cv.renderMessageHeader(msg)
+	if cv.gotoMode && index >= 0 {
+		content.WriteString(LineNumberStyle.Render(fmt.Sprintf("%*d ", len(strconv.Itoa(len(cv.messages))), index+1)))
+	}
 	content.WriteString(header)
 	content.WriteString("\n")
 
-	// Message content with syntax highlighting
-	renderedContent := cv.renderMessageContent(msg.Content, msg.Role)
-	content.WriteString(renderedContent)
+	// Message content with syntax highlighting; the streaming reply has no
+	// index and changes with every chunk, so it isn't cached
+	if index < 0 {
+		content.WriteString(cv.renderStreamingContent(msg.Content, msg.Role))
+	} else {
+		content.WriteString(cv.renderCachedContent(msg.Content, msg.Role))
+	}
+	if msg.Truncated {
+		content.WriteString("\n" + UsageAnnotationStyle.Render(truncatedMarker))
+	}
+	if reactions := cv.messageReactions[index]; len(reactions) > 0 {
+		content.WriteString("\n" + ReactionsStyle.Render(strings.Join(reactions, " ")))
+	}
 
 	if !isLast {
 		content.WriteString("\n")
@@ -356,17 +1173,153 @@ func (cv *ChatView) renderMessageHeader(msg api.Message) string {
 	}
 
 	// Timestamp if enabled
-	if cv.showTimestamp {
-		timestamp := msg.Timestamp.Format("15:04:05")
+	if cv.showTimestamp && !msg.Timestamp.IsZero() {
+		header.WriteString(" ")
+		header.WriteString(TimestampStyle.Render(cv.formatTimestamp(msg.Timestamp)))
+	}
+
+	if annotation := cv.usageAnnotation(msg); annotation != "" {
 		header.WriteString(" ")
-		header.WriteString(TimestampStyle.Render(timestamp))
+		header.WriteString(UsageAnnotationStyle.Render(annotation))
+	}
+
+	// Long replies note how long they take to read
+	if info := NewMessageInfo(msg); info.Words >= longMessageWords {
+		header.WriteString(" ")
+		header.WriteString(UsageAnnotationStyle.Render(fmt.Sprintf("· %s words · %s read", humanize.Comma(int64(info.Words)), info.ReadingTimeString())))
 	}
 
 	return header.String()
 }
 
+// usageAnnotation summarizes what a message cost: the reported tokens and
+// estimated price of a reply, or the estimated prompt tokens of a user
+// message. Replies without reported usage get no annotation.
+func (cv *ChatView) usageAnnotation(msg api.Message) string {
+	if !cv.usageAnnotations {
+		return ""
+	}
+
+	switch msg.Role {
+	case "user":
+		if tokens := estimateTokens(msg.Content); tokens > 0 {
+			return fmt.Sprintf("· ~%s tok", humanize.Comma(int64(tokens)))
+		}
+	case "assistant":
+		if msg.Usage == nil {
+			return ""
+		}
+		usage := msg.Usage
+		total := usage.TotalInputTokens() + usage.OutputTokens
+		annotation := fmt.Sprintf("· %s tok", humanize.Comma(int64(total)))
+		if usage.CacheReadInputTokens > 0 {
+			annotation += fmt.Sprintf(" (%s cached)", humanize.Comma(int64(usage.CacheReadInputTokens)))
+		}
+		if _, known := storage.LookupCostEstimate(msg.Model); known {
+			cost := storage.EstimateCachedCost(msg.Model, usage.InputTokens,
+				usage.CacheReadInputTokens, usage.CacheCreationInputTokens, usage.OutputTokens)
+			annotation += " · " + formatMessageCost(cost)
+		}
+		return annotation
+	}
+	return ""
+}
+
+// readingWordsPerMinute is the average silent reading speed used for
+// reading-time estimates
+const readingWordsPerMinute = 200
+
+// longMessageWords is the length from which message headers show the
+// word count and reading time
+const longMessageWords = 300
+
+// MessageInfo describes the length of a message
+type MessageInfo struct {
+	Role        string
+	Words       int
+	Characters  int
+	Lines       int
+	ReadingTime time.Duration
+}
+
+// NewMessageInfo measures a message's content
+func NewMessageInfo(msg api.Message) MessageInfo {
+	words := len(strings.Fields(msg.Content))
+	return MessageInfo{
+		Role:        msg.Role,
+		Words:       words,
+		Characters:  utf8.RuneCountInString(msg.Content),
+		Lines:       strings.Count(msg.Content, "\n") + 1,
+		ReadingTime: time.Duration(float64(words) / readingWordsPerMinute * float64(time.Minute)),
+	}
+}
+
+// ReadingTimeString formats the reading time to the nearest half minute,
+// e.g. "2.5 min"
+func (i MessageInfo) ReadingTimeString() string {
+	minutes := math.Round(i.ReadingTime.Minutes()*2) / 2
+	if minutes < 1 {
+		return "<1 min"
+	}
+	return strconv.FormatFloat(minutes, 'f', -1, 64) + " min"
+}
+
+// String summarizes the message for the "Message Info" action
+func (i MessageInfo) String() string {
+	return fmt.Sprintf("%s words · %s characters · %d lines · %s read",
+		humanize.Comma(int64(i.Words)), humanize.Comma(int64(i.Characters)), i.Lines, i.ReadingTimeString())
+}
+
+// formatMessageCost renders a per-message cost to a tenth of a cent
+func formatMessageCost(cost float64) string {
+	if cost > 0 && cost < 0.001 {
+		return "<$0.001"
+	}
+	return "$" + humanize.CommafWithDigits(math.Round(cost*1000)/1000, 3)
+}
+
+// formatTimestamp renders a message time as absolute or relative to now
+func (cv *ChatView) formatTimestamp(t time.Time) string {
+	if !cv.relativeTime {
+		return t.Format("15:04:05")
+	}
+
+	now := cv.now()
+	if now.Sub(t) < time.Minute {
+		return "just now"
+	}
+	return humanize.RelTime(t, now, "ago", "from now")
+}
+
+// renderCachedContent renders a message body, reusing the previous render of
+// identical content. The streaming buffer changes on every chunk, so callers
+// rendering it go through renderMessageContent directly.
+func (cv *ChatView) renderCachedContent(content, role string) string {
+	key := role + "\x00" + content
+	if rendered, ok := cv.bodyCache[key]; ok {
+		return rendered
+	}
+
+	rendered := cv.renderMessageContent(content, role)
+	cv.bodyCache[key] = rendered
+	return rendered
+}
+
 // renderMessageContent renders message content with syntax highlighting
 func (cv *ChatView) renderMessageContent(content, role string) string {
+	return cv.renderContent(content, role, false)
+}
+
+// renderStreamingContent renders the reply being streamed. Its last line is
+// still arriving, so it stays plain until the newline comes; otherwise a
+// line could flip between prose and heading or list styling as it grows.
+func (cv *ChatView) renderStreamingContent(content, role string) string {
+	return cv.renderContent(content, role, !strings.HasSuffix(content, "\n"))
+}
+
+// renderContent renders content line by line. With partialTail the last
+// line is drawn as plain prose unless it is inside a code block.
+func (cv *ChatView) renderContent(content, role string, partialTail bool) string {
 	var result strings.Builder
 
 	// Apply role-specific styling
@@ -386,31 +1339,53 @@ func (cv *ChatView) renderMessageContent(content, role string) string {
 	lines := strings.Split(content, "\n")
 	inCodeBlock := false
 	codeBlockLang := ""
-
-	for i, line := range lines {
-		if cv.isCodeBlockDelimiter(line) {
+	diffBlock := false
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if expr, end, ok := mathBlockAt(lines, i); ok && !inCodeBlock {
+			// Display math, centered on its own lines
+			result.WriteString(cv.renderMathBlock(expr, baseStyle))
+			i = end
+		} else if table, end, ok := markdownTableAt(lines, i); ok && !inCodeBlock {
+			// GFM table, drawn with aligned columns
+			result.WriteString(cv.renderTable(table, baseStyle))
+			i = end
+		} else if cv.isCodeBlockDelimiter(line) {
 			if !inCodeBlock {
 				// Starting code block
 				inCodeBlock = true
 				codeBlockLang = cv.extractCodeLanguage(line)
+				diffBlock = cv.isDiffBlock(codeBlockLang, cv.codeBlockBody(lines[i+1:]))
 				result.WriteString(CodeBlockDelimiterStyle.Render(line))
 			} else {
 				// Ending code block
 				inCodeBlock = false
 				codeBlockLang = ""
+				diffBlock = false
 				result.WriteString(CodeBlockDelimiterStyle.Render(line))
 			}
+		} else if inCodeBlock && diffBlock {
+			// Diff content, colored by change type
+			result.WriteString(CodeBlockStyle.Render(diffLineStyle(styles.GetCurrentTheme(), line).Render(line)))
 		} else if inCodeBlock {
 			// Code content
 			highlighted := cv.highlightCode(line, codeBlockLang)
 			result.WriteString(CodeBlockStyle.Render(highlighted))
+		} else if partialTail && i == len(lines)-1 {
+			// Still arriving; markdown styling waits for the newline
+			result.WriteString(baseStyle.Render(cv.renderInlineMath(line)))
+		} else if level, text, ok := markdownHeading(line); ok {
+			result.WriteString(cv.renderHeading(level, text, baseStyle))
+		} else if indent, marker, text, ok := markdownListItem(line); ok {
+			result.WriteString(cv.renderListItem(indent, marker, text, baseStyle))
 		} else if cv.isInlineCode(line) {
 			// Inline code
-			highlighted := cv.highlightInlineCode(line)
+			highlighted := cv.highlightInlineCode(cv.renderInlineMath(line))
 			result.WriteString(baseStyle.Render(highlighted))
 		} else {
 			// Regular text
-			result.WriteString(baseStyle.Render(line))
+			result.WriteString(baseStyle.Render(cv.renderInlineMath(line)))
 		}
 
 		// Add newline if not last line
@@ -422,6 +1397,51 @@ func (cv *ChatView) renderMessageContent(content, role string) string {
 	return result.String()
 }
 
+// renderInlineMath replaces $...$ spans with their Unicode rendering, or
+// styled source when the expression is too complex to convert
+func (cv *ChatView) renderInlineMath(line string) string {
+	spans := inlineMathSpans(line)
+	if len(spans) == 0 {
+		return line
+	}
+
+	var result strings.Builder
+	last := 0
+	for _, span := range spans {
+		result.WriteString(line[last:span[0]])
+		if text, ok := texToUnicode(line[span[0]+1 : span[1]-1]); ok && !strings.Contains(text, "\n") {
+			result.WriteString(MathStyle.Render(text))
+		} else {
+			result.WriteString(MathSourceStyle.Render(line[span[0]:span[1]]))
+		}
+		last = span[1]
+	}
+	result.WriteString(line[last:])
+
+	return result.String()
+}
+
+// renderMathBlock renders a $$ block centered in the message area, one row
+// per \\ line break, falling back to the styled source
+func (cv *ChatView) renderMathBlock(expr string, baseStyle lipgloss.Style) string {
+	text, ok := texToUnicode(expr)
+	if !ok {
+		var source []string
+		for _, line := range strings.Split("$$"+expr+"$$", "\n") {
+			source = append(source, baseStyle.Render(MathSourceStyle.Render(line)))
+		}
+		return strings.Join(source, "\n")
+	}
+
+	width := cv.width - baseStyle.GetHorizontalPadding()
+	rows := strings.Split(text, "\n")
+	for i, row := range rows {
+		row = MathStyle.Render(strings.TrimSpace(row))
+		rows[i] = baseStyle.Render(lipgloss.PlaceHorizontal(width, lipgloss.Center, row))
+	}
+	return strings.Join(rows, "\n")
+}
+
 // isCodeBlockDelimiter checks if a line is a code block delimiter
 func (cv *ChatView) isCodeBlockDelimiter(line string) bool {
 	trimmed := strings.TrimSpace(line)
@@ -437,6 +1457,68 @@ func (cv *ChatView) extractCodeLanguage(line string) string {
 	return ""
 }
 
+// codeBlockBody returns the lines up to the closing code block delimiter
+func (cv *ChatView) codeBlockBody(lines []string) []string {
+	for i, line := range lines {
+		if cv.isCodeBlockDelimiter(line) {
+			return lines[:i]
+		}
+	}
+	return lines
+}
+
+// isDiffBlock reports whether a code block holds a unified diff. Blocks
+// tagged diff or patch always qualify; untagged blocks need a hunk or file
+// header, or nothing but +/- and context lines with both additions and removals.
+func (cv *ChatView) isDiffBlock(lang string, body []string) bool {
+	switch strings.ToLower(lang) {
+	case "diff", "patch":
+		return true
+	case "":
+	default:
+		return false
+	}
+
+	added, removed := false, false
+	for _, line := range body {
+		switch {
+		case strings.HasPrefix(line, "@@"), strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+			return true
+		case strings.HasPrefix(line, "+"):
+			added = true
+		case strings.HasPrefix(line, "-"):
+			removed = true
+		case line == "", strings.HasPrefix(line, " "):
+		default:
+			return false
+		}
+	}
+	return added && removed
+}
+
+// diffLineStyle colors a diff line by its prefix using the theme's
+// success, error and info colors. Additions are also bold so they stay
+// distinguishable on monochrome terminals.
+func diffLineStyle(theme *styles.Theme, line string) lipgloss.Style {
+	style := lipgloss.NewStyle()
+	if theme == nil {
+		return style
+	}
+
+	switch {
+	case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+		return style.Foreground(theme.Colors.TextSubtle).Bold(true)
+	case strings.HasPrefix(line, "@@"):
+		return style.Foreground(theme.Colors.Info)
+	case strings.HasPrefix(line, "+"):
+		return style.Foreground(theme.Colors.Success).Bold(true)
+	case strings.HasPrefix(line, "-"):
+		return style.Foreground(theme.Colors.Error)
+	default:
+		return style
+	}
+}
+
 // isInlineCode checks if a line contains inline code
 func (cv *ChatView) isInlineCode(line string) bool {
 	return strings.Contains(line, "`")
@@ -644,6 +1726,9 @@ var (
 			Foreground(lipgloss.Color("#9CA3AF")).
 			Faint(true)
 
+	UsageAnnotationStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#9CA3AF"))
+
 	// Message content styles
 	UserMessageStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color("#1F2937")).
@@ -808,6 +1893,7 @@ func (cv *ChatView) showContextMenu(messageIdx int) {
 		{Label: "Export Message", Action: "export", Hotkey: "e", Enabled: true},
 		{Label: "Reply to Message", Action: "reply", Hotkey: "R", Enabled: true},
 		{Label: "Edit Message", Action: "edit", Hotkey: "E", Enabled: messageIdx < len(cv.messages) && cv.messages[messageIdx].Role == "user"},
+		{Label: "Message Info", Action: "info", Hotkey: "i", Enabled: true},
 	}
 }
 
@@ -840,59 +1926,93 @@ func (cv *ChatView) executeContextAction() tea.Cmd {
 	case "copy":
 		return cv.copyMessage(messageIdx)
 	case "react":
-		return cv.addReactionPrompt(messageIdx)
+		cv.openReactionPicker(messageIdx)
 	case "export":
 		return cv.exportMessage(messageIdx, "markdown")
 	case "reply":
 		return cv.replyToMessage(messageIdx)
 	case "edit":
 		return cv.editMessage(messageIdx)
+	case "info":
+		return cv.messageInfo(messageIdx)
 	}
 
 	return nil
 }
 
 // Message action commands
-func (cv *ChatView) copyMessage(messageIdx int) tea.Cmd {
+func (cv *ChatView) messageInfo(messageIdx int) tea.Cmd {
 	return func() tea.Msg {
 		if messageIdx >= 0 && messageIdx < len(cv.messages) {
-			return ChatViewMsg{Type: "copy_message", Data: cv.messages[messageIdx].Content}
+			return ChatViewMsg{Type: "message_info", Data: NewMessageInfo(cv.messages[messageIdx])}
 		}
 		return nil
 	}
 }
 
-func (cv *ChatView) copyAllMessages() tea.Cmd {
+func (cv *ChatView) copyMessage(messageIdx int) tea.Cmd {
 	return func() tea.Msg {
-		var content strings.Builder
-		for i, msg := range cv.messages {
-			content.WriteString(fmt.Sprintf("[%s] %s\n", strings.Title(msg.Role), msg.Content))
-			if i < len(cv.messages)-1 {
-				content.WriteString("\n")
-			}
+		if messageIdx >= 0 && messageIdx < len(cv.messages) {
+			return ChatViewMsg{Type: "copy_message", Data: cv.messages[messageIdx].Content}
 		}
-		return ChatViewMsg{Type: "copy_all", Data: content.String()}
+		return nil
 	}
 }
 
-func (cv *ChatView) addReactionPrompt(messageIdx int) tea.Cmd {
+// copyAllMessages copies the conversation as Markdown, optionally keeping
+// only the assistant's replies. Timestamps follow the timestamp toggle.
+func (cv *ChatView) copyAllMessages(assistantOnly bool) tea.Cmd {
 	return func() tea.Msg {
-		return ChatViewMsg{Type: "reaction_prompt", Data: messageIdx}
+		return ChatViewMsg{Type: "copy_all", Data: cv.MarkdownTranscript(assistantOnly)}
+	}
+}
+
+// MarkdownTranscript renders the conversation with the markdown exporter
+func (cv *ChatView) MarkdownTranscript(assistantOnly bool) string {
+	messages := make([]storage.Message, len(cv.messages))
+	for i, msg := range cv.messages {
+		messages[i] = storageMessage(msg)
 	}
+
+	return storage.FormatMarkdownTranscript(messages, storage.TranscriptOptions{
+		Timestamps:    cv.showTimestamp,
+		AssistantOnly: assistantOnly,
+	})
 }
 
+// exportMessage copies a message to the clipboard serialized as plain text,
+// markdown or JSON, reporting the outcome as "message_exported" or
+// "export_failed"
 func (cv *ChatView) exportMessage(messageIdx int, format string) tea.Cmd {
+	if messageIdx < 0 || messageIdx >= len(cv.messages) {
+		return nil
+	}
+	text, err := cv.FormatMessage(messageIdx, format)
 	return func() tea.Msg {
-		if messageIdx >= 0 && messageIdx < len(cv.messages) {
-			return ChatViewMsg{Type: "export_message", Data: map[string]interface{}{
-				"message": cv.messages[messageIdx],
-				"format":  format,
-			}}
+		if err == nil {
+			err = writeClipboard(text)
 		}
-		return nil
+		if err != nil {
+			return ChatViewMsg{Type: "export_failed", Data: err}
+		}
+		return ChatViewMsg{Type: "message_exported", Data: format}
 	}
 }
 
+// FormatMessage serializes one message with the exporter. Timestamps
+// follow the timestamp toggle.
+func (cv *ChatView) FormatMessage(messageIdx int, format string) (string, error) {
+	if messageIdx < 0 || messageIdx >= len(cv.messages) {
+		return "", fmt.Errorf("no message %d", messageIdx+1)
+	}
+	return storage.FormatMessage(storageMessage(cv.messages[messageIdx]), format, cv.showTimestamp)
+}
+
+// storageMessage converts a message for the exporter
+func storageMessage(msg api.Message) storage.Message {
+	return storage.Message{Role: msg.Role, Content: msg.Content, Timestamp: msg.Timestamp, Model: msg.Model}
+}
+
 func (cv *ChatView) replyToMessage(messageIdx int) tea.Cmd {
 	return func() tea.Msg {
 		if messageIdx >= 0 && messageIdx < len(cv.messages) {
@@ -960,8 +2080,49 @@ var (
 					Bold(true).
 					PaddingLeft(1)
 
+	// Typing indicator shown before a response starts streaming
+	TypingIndicatorStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#9CA3AF")).
+				Italic(true)
+
+	// Goto prompt style
+	GotoPromptStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#7C3AED")).
+			Bold(true)
+
 	// Search highlight style
 	SearchHighlightStyle = lipgloss.NewStyle().
 				Background(lipgloss.Color("#FEF3C7")).
 				Foreground(lipgloss.Color("#92400E"))
+
+	// Math rendered as Unicode, and source shown when it can't be
+	MathStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#0E7490"))
+
+	MathSourceStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#6B7280")).
+			Italic(true)
+
+	// Markdown headings by level, and list bullets and numbers
+	Heading1Style = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#7C3AED")).
+			Bold(true).
+			Underline(true)
+
+	Heading2Style = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#7C3AED")).
+			Bold(true)
+
+	Heading3Style = lipgloss.NewStyle().
+			Bold(true)
+
+	ListMarkerStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#7C3AED"))
+
+	// Markdown table borders and header cells
+	TableBorderStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#9CA3AF"))
+
+	TableHeaderStyle = lipgloss.NewStyle().
+				Bold(true)
 )
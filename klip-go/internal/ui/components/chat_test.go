@@ -0,0 +1,617 @@
+package components
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/john/klip/internal/api"
+	"github.com/john/klip/internal/ui/styles"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatViewSystemPromptCollapsible(t *testing.T) {
+	cv := NewChatView(80, 20)
+	cv.SetSystemPrompt("Be brief.\nAnswer in English.")
+
+	collapsed := cv.View()
+	assert.Contains(t, collapsed, "Be brief.")
+	assert.NotContains(t, collapsed, "Answer in English.")
+
+	cv, _ = cv.Update(runeKey('S'))
+	assert.Contains(t, cv.View(), "Answer in English.")
+
+	cv.SetSystemPrompt("")
+	assert.NotContains(t, cv.View(), "System")
+}
+
+func TestChatViewResizeKeepsScrollPosition(t *testing.T) {
+	cv := NewChatView(80, 12)
+	for i := 0; i < 20; i++ {
+		cv.AddMessage(api.Message{Role: "user", Content: fmt.Sprintf("message %d", i)})
+	}
+
+	cv.viewport.GotoBottom()
+	cv.Resize(60, 12)
+	assert.True(t, cv.viewport.AtBottom())
+
+	cv.viewport.SetYOffset(3)
+	cv.Resize(70, 14)
+	assert.Equal(t, 3, cv.viewport.YOffset)
+}
+
+func TestChatViewRelativeTimestamps(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	cv := NewChatView(80, 20)
+	cv.now = func() time.Time { return now }
+	cv.ToggleTimestamp()
+	cv.AddMessage(api.Message{Role: "user", Content: "hello", Timestamp: now.Add(-90 * time.Second)})
+
+	assert.Contains(t, cv.View(), "11:58:30")
+
+	cmd := cv.ToggleRelativeTime()
+	assert.NotNil(t, cmd)
+	assert.Contains(t, cv.View(), "1 minute ago")
+
+	// Stale ticks from an earlier toggle don't reschedule
+	_, cmd = cv.Update(timestampTickMsg{id: cv.tickID - 1})
+	assert.Nil(t, cmd)
+
+	now = now.Add(2 * time.Minute)
+	_, cmd = cv.Update(timestampTickMsg{id: cv.tickID})
+	assert.NotNil(t, cmd)
+	assert.Contains(t, cv.View(), "3 minutes ago")
+}
+
+func TestChatViewGotoMessageScrollsToHeader(t *testing.T) {
+	cv := NewChatView(80, 12)
+	for i := 0; i < 20; i++ {
+		cv.AddMessage(api.Message{Role: "user", Content: fmt.Sprintf("message %d", i)})
+	}
+
+	// Each message renders as a header, one content line and a separator
+	for _, key := range []rune(":5") {
+		cv, _ = cv.Update(runeKey(key))
+	}
+	assert.True(t, cv.gotoMode)
+	assert.Contains(t, cv.View(), "20 ")
+	assert.Contains(t, cv.View(), ":5")
+
+	cv, _ = cv.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.False(t, cv.gotoMode)
+	assert.Equal(t, 12, cv.viewport.YOffset)
+	assert.Contains(t, strings.SplitN(cv.viewport.View(), "\n", 3)[1], "message 4")
+	assert.Equal(t, 4, cv.MessageAtLine(13))
+
+	cv, _ = cv.Update(ChatViewMsg{Type: "goto", Data: "/goto 2"})
+	assert.Equal(t, 3, cv.viewport.YOffset)
+
+	_, ok := ParseGotoCommand("/goto zero")
+	assert.False(t, ok)
+}
+
+func TestChatViewColorsDiffBlocks(t *testing.T) {
+	previous := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	defer lipgloss.SetColorProfile(previous)
+
+	cv := NewChatView(80, 20)
+	diff := "```\n@@ -1,2 +1,2 @@\n-removed\n+added\n context\n```"
+	rendered := strings.Split(cv.renderMessageContent(diff, "assistant"), "\n")
+
+	added, removed := rendered[3], rendered[2]
+	assert.Contains(t, ansi.Strip(added), "+added")
+	assert.Contains(t, ansi.Strip(removed), "-removed")
+	assert.NotEqual(t, strings.Replace(added, "+added", "", 1), strings.Replace(removed, "-removed", "", 1),
+		"additions and removals should be styled differently")
+
+	theme := styles.GetCurrentTheme()
+	assert.Equal(t, theme.Colors.Success, diffLineStyle(theme, "+added").GetForeground())
+	assert.Equal(t, theme.Colors.Error, diffLineStyle(theme, "-removed").GetForeground())
+	assert.Equal(t, theme.Colors.Info, diffLineStyle(theme, "@@ -1 +1 @@").GetForeground())
+
+	// Ordinary code keeps regular highlighting
+	assert.False(t, cv.isDiffBlock("go", []string{"-x", "+y"}))
+	assert.False(t, cv.isDiffBlock("", []string{"- item one", "- item two"}))
+	assert.True(t, cv.isDiffBlock("diff", nil))
+}
+
+func TestChatViewTypingIndicatorUntilFirstChunk(t *testing.T) {
+	cv := NewChatView(80, 20)
+	cv.AddMessage(api.Message{Role: "user", Content: "hi"})
+
+	cv, cmd := cv.Update(ChatViewMsg{Type: "stream_start"})
+	assert.NotNil(t, cmd, "the indicator should animate")
+	assert.Contains(t, cv.View(), "is typing")
+
+	cv, _ = cv.Update(typingTickMsg{id: cv.typingTickID})
+	assert.Equal(t, 1, cv.typingFrame)
+
+	cv, _ = cv.Update(ChatViewMsg{Type: "stream_chunk", Data: "Hello"})
+	assert.NotContains(t, cv.View(), "is typing")
+	assert.Contains(t, cv.View(), "Hello")
+
+	// The animation stops once text arrives
+	_, cmd = cv.Update(typingTickMsg{id: cv.typingTickID})
+	assert.Nil(t, cmd)
+}
+
+func TestChatViewTypingIndicatorSettings(t *testing.T) {
+	cv := NewChatView(80, 20)
+	cv.SetTypingIndicator(true, true)
+
+	cv, cmd := cv.Update(ChatViewMsg{Type: "stream_start"})
+	assert.Nil(t, cmd, "reduced motion should not animate")
+	assert.Contains(t, cv.View(), "is typing...")
+
+	cv.SetTypingIndicator(false, false)
+	assert.NotContains(t, cv.View(), "is typing")
+}
+
+func transcriptChatView() *ChatView {
+	at := time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC)
+	cv := NewChatView(80, 20)
+	cv.AddMessage(api.Message{Role: "user", Content: "How do I reverse a slice in Go?", Timestamp: at})
+	cv.AddMessage(api.Message{Role: "assistant", Timestamp: at.Add(5 * time.Second), Content: "Use `slices.Reverse` from the standard library:\n\n" +
+		"```go\ns := []int{1, 2, 3}\nslices.Reverse(s)\n```\n\nIt reverses **in place**.\n"})
+	cv.AddMessage(api.Message{Role: "user", Content: "And a string?", Timestamp: at.Add(70 * time.Second)})
+	// The last reply was interrupted before its code fence closed
+	cv.AddMessage(api.Message{Role: "assistant", Timestamp: at.Add(72 * time.Second), Content: "Convert it to runes first:\n\n" +
+		"```go\nr := []rune(s)\nslices.Reverse(r)"})
+	return cv
+}
+
+func TestChatViewCopyAllAsMarkdown(t *testing.T) {
+	golden, err := os.ReadFile(filepath.Join("testdata", "transcript.md"))
+	require.NoError(t, err)
+
+	cv := transcriptChatView()
+	cv.ToggleTimestamp()
+
+	_, cmd := cv.Update(runeKey('Y'))
+	require.NotNil(t, cmd)
+	msg, ok := cmd().(ChatViewMsg)
+	require.True(t, ok)
+	assert.Equal(t, "copy_all", msg.Type)
+	assert.Equal(t, string(golden), msg.Data)
+}
+
+func TestChatViewCopyAssistantReplies(t *testing.T) {
+	cv := transcriptChatView()
+
+	_, cmd := cv.Update(runeKey('A'))
+	require.NotNil(t, cmd)
+	transcript := cmd().(ChatViewMsg).Data.(string)
+
+	assert.NotContains(t, transcript, "## User")
+	assert.NotContains(t, transcript, "2025-03-14", "timestamps follow the timestamp toggle")
+	assert.True(t, strings.HasPrefix(transcript, "## Assistant\n\nUse `slices.Reverse`"))
+	assert.Equal(t, 2, strings.Count(transcript, "## Assistant"))
+}
+
+func TestChatViewExportMessage(t *testing.T) {
+	var copied string
+	writeClipboard = func(text string) error {
+		copied = text
+		return nil
+	}
+	defer func() { writeClipboard = clipboard.WriteAll }()
+
+	cv := transcriptChatView()
+	cv.ToggleTimestamp()
+
+	msg := cv.exportMessage(1, "markdown")().(ChatViewMsg)
+	assert.Equal(t, "message_exported", msg.Type)
+	assert.Equal(t, "## Assistant - 2025-03-14 09:30:05\n\n"+
+		"Use `slices.Reverse` from the standard library:\n\n"+
+		"```go\ns := []int{1, 2, 3}\nslices.Reverse(s)\n```\n\n"+
+		"It reverses **in place**.\n", copied)
+
+	cv.exportMessage(3, "markdown")()
+	assert.True(t, strings.HasSuffix(copied, "slices.Reverse(r)\n```\n"), "an unterminated fence is closed")
+
+	cv.ToggleTimestamp()
+	cv.exportMessage(0, "plain")()
+	assert.Equal(t, "User:\nHow do I reverse a slice in Go?\n", copied)
+
+	cv.exportMessage(0, "json")()
+	assert.Contains(t, copied, `"content": "How do I reverse a slice in Go?"`)
+
+	msg = cv.exportMessage(0, "pdf")().(ChatViewMsg)
+	assert.Equal(t, "export_failed", msg.Type)
+}
+
+func TestChatViewReactionPicker(t *testing.T) {
+	cv := transcriptChatView()
+
+	// Open the picker on the second message from the context menu
+	cv.showContextMenu(1)
+	cv.navigateContextMenu(1)
+	assert.Nil(t, cv.executeContextAction())
+	require.NotNil(t, cv.reactionPicker)
+	assert.Contains(t, cv.View(), "more…")
+
+	cv, _ = cv.Update(tea.KeyMsg{Type: tea.KeyRight})
+	cv, _ = cv.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Nil(t, cv.reactionPicker)
+	assert.Equal(t, []string{"👎"}, cv.messageReactions[1])
+	assert.Contains(t, cv.View(), "👎")
+
+	// "more" searches every shortcode
+	cv.openReactionPicker(3)
+	cv, _ = cv.Update(runeKey('/'))
+	for _, r := range "rock" {
+		cv, _ = cv.Update(runeKey(r))
+	}
+	cv, _ = cv.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, []string{"🚀"}, cv.messageReactions[3])
+
+	// Terminals without emoji get the shortcode
+	cv.emojiSupported = false
+	cv.openReactionPicker(0)
+	cv, _ = cv.Update(runeKey('5'))
+	assert.Equal(t, []string{":tada:"}, cv.messageReactions[0])
+	assert.Empty(t, cv.messageReactions[2])
+}
+
+func TestTexToUnicode(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+		ok   bool
+	}{
+		{`x^2`, "x²", true},
+		{`a_{ij} + b_n`, "aᵢⱼ + bₙ", true},
+		{`\frac{a+b}{2}`, "(a+b)/2", true},
+		{`\sqrt{x} \leq \pi r^2`, "√x ≤ π r²", true},
+		{`\sum_{i=1}^{n} i`, "∑ᵢ₌₁ⁿ i", true},
+		{`e^{i\pi}`, "", false},
+		{`\begin{matrix} a \end{matrix}`, "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := texToUnicode(tt.expr)
+		assert.Equal(t, tt.ok, ok, tt.expr)
+		if tt.ok {
+			assert.Equal(t, tt.want, got, tt.expr)
+		}
+	}
+}
+
+func TestChatViewRendersMath(t *testing.T) {
+	cv := NewChatView(60, 20)
+
+	inline := ansi.Strip(cv.renderMessageContent("The area grows with $x^2$, not $5 or $10.", "assistant"))
+	assert.Contains(t, inline, "grows with x², not $5 or $10.")
+
+	code := ansi.Strip(cv.renderMessageContent("Keep `$x^2$` as code", "assistant"))
+	assert.Contains(t, code, "`$x^2$`")
+
+	block := ansi.Strip(cv.renderMessageContent("Energy:\n$$\nE = mc^2\n$$\nDone", "assistant"))
+	lines := strings.Split(block, "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "Done", strings.TrimSpace(lines[2]))
+
+	row := lines[1]
+	left := len(row) - len(strings.TrimLeft(row, " "))
+	right := len(row) - len(strings.TrimRight(row, " "))
+	assert.Equal(t, "E = mc²", strings.TrimSpace(row))
+	assert.InDelta(t, left, right, 3, "block math should be centered: %q", row)
+	assert.Greater(t, left, 10)
+
+	complex := ansi.Strip(cv.renderMessageContent("$$\\begin{pmatrix} 1 \\end{pmatrix}$$", "assistant"))
+	assert.Contains(t, complex, "$$\\begin{pmatrix} 1 \\end{pmatrix}$$")
+}
+
+func TestChatViewRendersTables(t *testing.T) {
+	cv := NewChatView(80, 20)
+
+	content := "Results:\n| Name | Score | Notes |\n|:-----|:-----:|------:|\n| Ada | 9 | first |\n| Grace Hopper | 10 | a \\| b |\nDone"
+	rendered := strings.Split(ansi.Strip(cv.renderMessageContent(content, "assistant")), "\n")
+	require.Len(t, rendered, 8)
+	assert.Equal(t, "Done", strings.TrimSpace(rendered[7]))
+
+	table := make([]string, 0, 6)
+	for _, line := range rendered[1:7] {
+		table = append(table, strings.TrimSpace(line))
+	}
+	assert.Equal(t, []string{
+		"┌──────────────┬───────┬───────┐",
+		"│ Name         │ Score │ Notes │",
+		"├──────────────┼───────┼───────┤",
+		"│ Ada          │   9   │ first │",
+		"│ Grace Hopper │  10   │ a | b │",
+		"└──────────────┴───────┴───────┘",
+	}, table)
+
+	// Tables inside code blocks stay as written
+	code := ansi.Strip(cv.renderMessageContent("```\n| a | b |\n|---|---|\n```", "assistant"))
+	assert.Contains(t, code, "|---|---|")
+
+	// Wide tables are narrowed to the chat width
+	narrow := NewChatView(30, 20)
+	wide := "| Column | Description |\n|---|---|\n| x | " + strings.Repeat("long text ", 10) + "|"
+	for _, line := range strings.Split(ansi.Strip(narrow.renderMessageContent(wide, "assistant")), "\n") {
+		assert.LessOrEqual(t, lipgloss.Width(line), 30, line)
+	}
+}
+
+func TestChatViewUsageAnnotations(t *testing.T) {
+	cv := NewChatView(80, 20)
+	reply := api.Message{
+		Role:    "assistant",
+		Content: "Done.",
+		Model:   "claude-sonnet-4-20250514",
+		Usage:   &api.Usage{InputTokens: 204, OutputTokens: 1000},
+	}
+
+	assert.Equal(t, "Assistant · 1,204 tok · $0.016", headerText(cv.renderMessageHeader(reply)))
+
+	// Prompts show the estimate; replies without usage show nothing
+	assert.Contains(t, headerText(cv.renderMessageHeader(api.Message{Role: "user", Content: "How do I reverse a slice?"})), "· ~")
+	assert.Equal(t, "Assistant", headerText(cv.renderMessageHeader(api.Message{Role: "assistant", Content: "Hi"})))
+
+	cv.SetUsageAnnotations(false)
+	assert.Equal(t, "Assistant", headerText(cv.renderMessageHeader(reply)))
+}
+
+// headerText strips styling and padding from a rendered message header
+func headerText(header string) string {
+	return strings.Join(strings.Fields(ansi.Strip(header)), " ")
+}
+
+func TestChatViewRestoresScrollPositionPerSession(t *testing.T) {
+	conversation := func(label string) []api.Message {
+		messages := make([]api.Message, 20)
+		for i := range messages {
+			messages[i] = api.Message{Role: "user", Content: fmt.Sprintf("%s %d", label, i)}
+		}
+		return messages
+	}
+
+	cv := NewChatView(80, 12)
+	cv.SetSessionID("first")
+	cv.SetMessages(conversation("first"))
+	assert.True(t, cv.viewport.AtBottom())
+	cv.viewport.SetYOffset(9)
+
+	cv.SetSessionID("second")
+	cv.SetMessages(conversation("second"))
+	assert.True(t, cv.viewport.AtBottom(), "a new session starts at the bottom")
+
+	cv.SetSessionID("first")
+	cv.SetMessages(conversation("first"))
+	assert.Equal(t, 9, cv.viewport.YOffset)
+
+	// Refreshing the same session keeps the reader in place
+	cv.SetMessages(append(conversation("first"), api.Message{Role: "assistant", Content: "new"}))
+	assert.Equal(t, 9, cv.viewport.YOffset)
+
+	cv.SetSessionID("second")
+	cv.SetMessages(conversation("second"))
+	assert.True(t, cv.viewport.AtBottom(), "sessions left at the bottom keep following")
+}
+
+func TestMessageInfoReadingTime(t *testing.T) {
+	content := strings.TrimSpace(strings.Repeat("word ", 500))
+	info := NewMessageInfo(api.Message{Role: "assistant", Content: content})
+
+	assert.Equal(t, 500, info.Words)
+	assert.Equal(t, 150*time.Second, info.ReadingTime)
+	assert.Equal(t, "2.5 min", info.ReadingTimeString())
+
+	cv := NewChatView(80, 20)
+	cv.AddMessage(api.Message{Role: "assistant", Content: content})
+	assert.Equal(t, "Assistant · 500 words · 2.5 min read", headerText(cv.renderMessageHeader(cv.messages[0])))
+
+	cv.showContextMenu(0)
+	cv.contextMenu.selected = len(cv.contextMenu.items) - 1
+	cmd := cv.executeContextAction()
+	require.NotNil(t, cmd)
+	msg := cmd().(ChatViewMsg)
+	assert.Equal(t, "message_info", msg.Type)
+	assert.Contains(t, msg.Data.(MessageInfo).String(), "500 words")
+}
+
+func TestChatViewStreamingCodeBlockAcrossChunks(t *testing.T) {
+	previous := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	defer lipgloss.SetColorProfile(previous)
+
+	cv := NewChatView(80, 20)
+	cv.StartStreaming()
+	code := CodeBlockStyle.Render(cv.highlightCode(`fmt.Println("hi")`, "go"))
+	rendered := func() []string {
+		return strings.Split(cv.renderMessageContent(cv.streamingContent(), "assistant"), "\n")
+	}
+
+	// A half-received fence is held back rather than shown as prose
+	cv.AddStreamChunk("Run this:\n``")
+	assert.Equal(t, "Run this:\n", cv.streamingContent())
+	assert.NotContains(t, ansi.Strip(cv.View()), "``")
+
+	cv.AddStreamChunk("`go\nfmt.Println(\"hi\")\n``")
+	assert.True(t, cv.fence.open)
+	lines := rendered()
+	assert.Equal(t, CodeBlockDelimiterStyle.Render("```go"), lines[1])
+	assert.Equal(t, code, lines[2])
+	assert.True(t, strings.HasSuffix(cv.streamingContent(), "fmt.Println(\"hi\")\n"), "the closing fence is still arriving")
+
+	cv.AddStreamChunk("`\nDone.")
+	assert.False(t, cv.fence.open)
+	lines = rendered()
+	assert.Equal(t, code, lines[2], "code keeps its styling once the block closes")
+	assert.Equal(t, CodeBlockDelimiterStyle.Render("```"), lines[3])
+	assert.Contains(t, ansi.Strip(lines[4]), "Done.")
+
+	// A block still open when the stream ends renders as code
+	cv.StartStreaming()
+	cv.AddStreamChunk("```\necho hi")
+	assert.Contains(t, cv.streamingContent(), "echo hi")
+	cv.EndStreaming()
+	last := cv.messages[len(cv.messages)-1]
+	lines = strings.Split(cv.renderMessageContent(last.Content, "assistant"), "\n")
+	assert.Equal(t, CodeBlockStyle.Render(cv.highlightCode("echo hi", "")), lines[1])
+}
+
+func TestChatViewStreamingStylesLinesOnceComplete(t *testing.T) {
+	previous := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	defer lipgloss.SetColorProfile(previous)
+
+	cv := NewChatView(80, 20)
+	baseStyle := AssistantMessageStyle
+	heading := cv.renderHeading(1, "Title", baseStyle)
+	rendered := func() []string {
+		return strings.Split(cv.renderStreamingContent(cv.streamingContent(), "assistant"), "\n")
+	}
+
+	// The heading is plain while its line is still arriving
+	cv.StartStreaming()
+	cv.AddStreamChunk("# Title")
+	lines := rendered()
+	assert.NotEqual(t, heading, lines[0])
+	assert.Contains(t, ansi.Strip(lines[0]), "# Title")
+
+	cv.AddStreamChunk("\n- one")
+	lines = rendered()
+	assert.Equal(t, heading, lines[0], "the heading is styled once its newline arrives")
+	assert.Contains(t, ansi.Strip(lines[1]), "- one", "the list item is still arriving")
+
+	cv.AddStreamChunk("\n")
+	lines = rendered()
+	assert.Equal(t, cv.renderListItem("", "-", "one", baseStyle), lines[1])
+	assert.Contains(t, ansi.Strip(lines[1]), "• one")
+
+	// Finished messages style their last line too
+	lines = strings.Split(cv.renderMessageContent("## Done", "assistant"), "\n")
+	assert.Equal(t, cv.renderHeading(2, "Done", baseStyle), lines[0])
+}
+
+func TestChatViewLowBandwidthBatchesChunks(t *testing.T) {
+	cv := NewChatView(80, 20)
+	cv.SetLowBandwidthMode("on")
+	cv.AddMessage(api.Message{Role: "user", Content: "hi"})
+	cv, _ = cv.Update(ChatViewMsg{Type: "stream_start"})
+	before := cv.viewport.View()
+	assert.Contains(t, before, "is typing...", "the indicator doesn't animate")
+
+	// Only the first chunk schedules a flush; none are drawn until it fires
+	cv, flush := cv.Update(ChatViewMsg{Type: "stream_chunk", Data: "Hello"})
+	require.NotNil(t, flush)
+	for _, chunk := range []string{", wor", "ld"} {
+		var cmd tea.Cmd
+		cv, cmd = cv.Update(ChatViewMsg{Type: "stream_chunk", Data: chunk})
+		assert.Nil(t, cmd)
+	}
+	assert.Equal(t, before, cv.viewport.View())
+
+	cv, _ = cv.Update(flush())
+	assert.Contains(t, cv.viewport.View(), "Hello, world")
+
+	// The next chunk starts a new interval
+	_, cmd := cv.Update(ChatViewMsg{Type: "stream_chunk", Data: "!"})
+	assert.NotNil(t, cmd)
+
+	cv.SetLowBandwidthMode("off")
+	_, cmd = cv.Update(ChatViewMsg{Type: "stream_chunk", Data: "?"})
+	assert.Nil(t, cmd)
+	assert.Contains(t, cv.viewport.View(), "Hello, world!?")
+}
+
+func TestChatViewScreenReaderMode(t *testing.T) {
+	am := styles.NewAccessibilityManager(styles.GetCurrentTheme(), nil)
+	am.UpdatePreferences(&styles.AccessibilityPreferences{ScreenReaderMode: true})
+
+	cv := NewChatView(80, 30)
+	cv.SetAccessibility(am)
+	cv.AddMessage(api.Message{Role: "user", Content: "Show me a loop"})
+	cv.AddMessage(api.Message{Role: "assistant", Content: "## Loops\n\n```go\nfor i := range 3 {}\n```\nDone.", Truncated: true})
+
+	view := ansi.Strip(cv.View())
+	assert.Contains(t, view, "You: Show me a loop")
+	assert.Contains(t, view, "Assistant: Heading level 2: Loops")
+	assert.Contains(t, view, "Code block, go:")
+	assert.Contains(t, view, "End of code block.")
+	assert.Contains(t, view, "/continue")
+	assert.NotContains(t, view, "```")
+	for _, r := range view {
+		assert.False(t, r >= 0x2500 && r <= 0x259F, "box-drawing or block glyph %q in %q", r, view)
+	}
+
+	// Turning screen reader mode off brings the decorated view back
+	am.UpdatePreferences(&styles.AccessibilityPreferences{})
+	cv.SetAccessibility(am)
+	assert.Contains(t, cv.View(), "╭")
+}
+
+func TestChatViewLargeTextPadding(t *testing.T) {
+	am := styles.NewAccessibilityManager(styles.GetCurrentTheme(), nil)
+	cv := NewChatView(80, 30)
+	cv.SetAccessibility(am)
+	cv.AddMessage(api.Message{Role: "user", Content: "Hello"})
+
+	leading := func(view string) int {
+		for _, line := range strings.Split(ansi.Strip(view), "\n") {
+			if i := strings.Index(line, "Hello"); i >= 0 {
+				return i
+			}
+		}
+		t.Fatalf("message missing from %q", view)
+		return 0
+	}
+	normal := cv.View()
+
+	// The viewport shrinks by the added padding so the panel keeps its size
+	am.UpdatePreferences(&styles.AccessibilityPreferences{LargeText: true})
+	large := cv.View()
+	assert.Greater(t, leading(large), leading(normal))
+	assert.Equal(t, lipgloss.Width(normal), lipgloss.Width(large))
+	assert.Equal(t, lipgloss.Height(normal), lipgloss.Height(large))
+}
+
+func TestChatViewAnnouncesStreamEnd(t *testing.T) {
+	am := styles.NewAccessibilityManager(styles.GetCurrentTheme(), nil)
+	am.UpdatePreferences(&styles.AccessibilityPreferences{ScreenReaderMode: true})
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cv := NewChatView(80, 30)
+	cv.now = func() time.Time { return now }
+	cv.SetAccessibility(am)
+
+	cv.StartStreaming()
+	cv.AddStreamChunk("First paragraph.\n\nSecond one\nwraps here.\n\nThird.")
+	cv.EndStreaming()
+	assert.Equal(t, "Response complete, 3 paragraphs", cv.Announcement())
+	assert.Contains(t, ansi.Strip(cv.View()), "Response complete, 3 paragraphs")
+
+	// A second reply right away is throttled, but errors still get through
+	cv.StartStreaming()
+	cv.AddStreamChunk("Quick.")
+	cv.EndStreaming()
+	assert.Equal(t, "Response complete, 3 paragraphs", cv.Announcement())
+	cv.AnnounceError("rate limited")
+	assert.Equal(t, "Error: rate limited", cv.Announcement())
+
+	now = now.Add(announceInterval)
+	cv.StartStreaming()
+	cv.AddStreamChunk("Later.")
+	cv.EndStreaming()
+	assert.Equal(t, "Response complete, 1 paragraph", cv.Announcement())
+
+	// Without screen reader mode nothing is announced
+	plain := NewChatView(80, 30)
+	plain.StartStreaming()
+	plain.AddStreamChunk("Hi")
+	plain.EndStreaming()
+	assert.Empty(t, plain.Announcement())
+}
@@ -4,9 +4,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/john/klip/internal/app"
+	"github.com/john/klip/internal/storage"
+	"github.com/john/klip/internal/ui/styles"
 )
 
 // ComponentRegistry manages all UI components for the Klip application
@@ -22,6 +25,30 @@ type ComponentRegistry struct {
 	spinner       *LoadingSpinner
 	notifications *NotificationCenter
 	tokenUsage    *TokenUsageDisplay
+	keyHelp       *KeyHelpOverlay
+	sidebar       *Sidebar
+	keyMaps       KeyMaps
+	styler        *styles.AdaptiveStyler
+	layout        styles.ResponsiveConfig
+	accessibility *styles.AccessibilityManager
+	focusMode     bool
+	// focus names the component receiving keys: Chat, History, Settings or Sidebar
+	focus string
+
+	// config is the last configuration applied, kept for components
+	// created by a later Initialize
+	config *storage.Config
+	// usageStore holds the all-time totals the token usage display starts from
+	usageStore *storage.UsageStore
+	// runCommand executes a slash command the way typing it would, for
+	// component requests the app's commands carry out
+	runCommand func(input string) tea.Cmd
+
+	// streamStart and streamTokens track the running output estimate of the
+	// active stream for the status bar's throughput
+	streamStart  time.Time
+	streamTokens int
+	now          func() time.Time
 
 	width  int
 	height int
@@ -55,6 +82,7 @@ func NewComponentRegistry(width, height int) *ComponentRegistry {
 	return &ComponentRegistry{
 		width:  width,
 		height: height,
+		now:    time.Now,
 	}
 }
 
@@ -63,10 +91,19 @@ func (cr *ComponentRegistry) Initialize() {
 	cr.mu.Lock()
 	defer cr.mu.Unlock()
 
+	// Match glyphs and layout to the terminal
+	cr.styler = styles.NewAdaptiveStyler(nil, cr.width, cr.height)
+	cr.layout = cr.styler.GetResponsiveConfig()
+
 	// Initialize core components
-	cr.chat = NewChatView(cr.width-20, cr.height-10)
+	cr.sidebar = NewSidebar(cr.layout.SidebarWidth, cr.height-10)
+	cr.sidebar.SetLayout(cr.layout)
+	sidebarWidth, chatWidth := SidebarWidths(cr.width, cr.layout, false)
+	cr.sidebar.Resize(sidebarWidth, cr.height-10)
+	cr.chat = NewChatView(chatWidth, cr.height-10)
 	cr.input = NewEnhancedInput(InputTypeText, cr.width-20, 3)
-	cr.statusBar = NewStatusBar(cr.width, 1)
+	cr.input.SetValidatorRegistry(DefaultValidatorRegistry())
+	cr.statusBar = NewStatusBarFromConfig(cr.config, cr.width, 1)
 
 	// Initialize secondary components
 	cr.models = NewModelSelector(cr.width-10, cr.height-5)
@@ -79,6 +116,179 @@ func (cr *ComponentRegistry) Initialize() {
 	cr.spinner = NewLoadingSpinner(cr.width-20, cr.height-20)
 	cr.notifications = NewNotificationCenter(cr.width, cr.height)
 	cr.tokenUsage = NewTokenUsageDisplay(cr.width-30, cr.height-25)
+	cr.keyHelp = NewKeyHelpOverlay(cr.width, cr.height)
+
+	// Match glyphs to what the terminal can display
+	cr.statusBar.SetStyler(cr.styler)
+	cr.progress.SetStyler(cr.styler)
+	cr.spinner.SetStyler(cr.styler)
+	cr.history.SetStyler(cr.styler)
+	cr.input.SetStyler(cr.styler)
+	cr.chat.SetStyler(cr.styler)
+
+	// One accessibility manager, so screen reader mode set in settings
+	// applies everywhere
+	cr.accessibility = styles.NewAccessibilityManager(styles.GetCurrentTheme(), cr.styler.GetCapabilities())
+	cr.settings.SetAccessibility(cr.accessibility)
+	cr.chat.SetAccessibility(cr.accessibility)
+	cr.statusBar.SetAccessibility(cr.accessibility)
+	cr.input.SetAccessibility(cr.accessibility)
+	cr.sidebar.SetAccessibility(cr.accessibility)
+
+	// Load user keybindings, keeping the defaults if keys.json is invalid
+	keyMaps, err := LoadKeyMaps()
+	if err != nil {
+		cr.notifications.AddNotification(Notification{
+			Type:     NotificationError,
+			Title:    "Invalid keybindings",
+			Message:  err.Error(),
+			Duration: 10 * time.Second,
+		})
+	}
+	cr.applyKeyMaps(keyMaps)
+
+	if cr.usageStore != nil {
+		cr.loadUsageTotals()
+	}
+
+	if cr.config != nil {
+		cr.applyConfig(cr.config)
+	}
+}
+
+// SetUsageStore sets where the token usage display loads its all-time
+// totals from, loading them now if the components already exist
+func (cr *ComponentRegistry) SetUsageStore(store *storage.UsageStore) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	cr.usageStore = store
+	if cr.tokenUsage != nil {
+		cr.loadUsageTotals()
+	}
+}
+
+// loadUsageTotals shows the persisted totals, warning when usage.json can't
+// be read; callers must hold the lock
+func (cr *ComponentRegistry) loadUsageTotals() {
+	if err := cr.tokenUsage.LoadTotals(cr.usageStore); err != nil {
+		cr.notifications.AddNotification(Notification{
+			Type:     NotificationWarning,
+			Title:    "Usage totals unavailable",
+			Message:  err.Error(),
+			Duration: 10 * time.Second,
+		})
+	}
+}
+
+// applyKeyMaps distributes keybindings to the components; callers must hold the lock
+func (cr *ComponentRegistry) applyKeyMaps(keyMaps KeyMaps) {
+	cr.keyMaps = keyMaps
+	cr.chat.SetKeyMap(keyMaps.Chat)
+	cr.input.SetKeyMap(keyMaps.Input)
+	cr.history.SetKeyMap(keyMaps.History)
+	cr.settings.SetKeyMap(keyMaps.Settings)
+	cr.settings.SetKeyMaps(keyMaps)
+	cr.sidebar.SetKeyMap(keyMaps.Sidebar)
+	cr.applyFocus()
+}
+
+// SetFocus moves keyboard focus to the named component (Chat, History,
+// Settings or Sidebar) so the keybinding overlay shows its keys
+func (cr *ComponentRegistry) SetFocus(component string) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	cr.focus = component
+	cr.applyFocus()
+}
+
+// Focus returns the name of the component receiving keys
+func (cr *ComponentRegistry) Focus() string {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	return cr.focusedComponent()
+}
+
+// focusedComponent returns the focus, defaulting to the chat; callers must hold the lock
+func (cr *ComponentRegistry) focusedComponent() string {
+	switch cr.focus {
+	case "History", "Settings", "Sidebar":
+		return cr.focus
+	}
+	return "Chat"
+}
+
+// applyFocus points the keybinding overlay at the focused component and
+// its configured help key; callers must hold the lock
+func (cr *ComponentRegistry) applyFocus() {
+	if cr.keyHelp == nil {
+		return
+	}
+	focus := cr.focusedComponent()
+	if cr.sidebar != nil {
+		if focus == "Sidebar" {
+			cr.sidebar.Focus()
+		} else {
+			cr.sidebar.Blur()
+		}
+	}
+
+	toggle := cr.keyMaps.Chat.Help
+	switch focus {
+	case "History":
+		cr.keyHelp.SetKeyMap(focus, cr.history.KeyMap())
+		toggle = cr.keyMaps.History.Help
+	case "Settings":
+		cr.keyHelp.SetKeyMap(focus, cr.settings.KeyMap())
+	case "Sidebar":
+		cr.keyHelp.SetKeyMap(focus, cr.keyMaps.Sidebar)
+	default:
+		cr.keyHelp.SetKeyMap(focus, cr.chat.KeyMap())
+	}
+	cr.keyHelp.SetToggle(toggle)
+}
+
+// SetConfig applies config to the components, and to those a later
+// Initialize creates
+func (cr *ComponentRegistry) SetConfig(config *storage.Config) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	cr.applyConfig(config)
+}
+
+// applyConfig pushes configuration that components read directly; callers must hold the lock
+func (cr *ComponentRegistry) applyConfig(config *storage.Config) {
+	cr.config = config
+	if cr.statusBar != nil {
+		cr.statusBar.SetSections(config.StatusBarSections)
+		cr.statusBar.SetShowGitContext(config.ShowGitContext)
+	}
+	if cr.notifications != nil {
+		cr.notifications.SetBell(config.NotificationBell)
+	}
+	if cr.spinner != nil {
+		cr.spinner.SetSpinnerStyle(config.SpinnerStyle)
+	}
+	if cr.input != nil {
+		cr.input.SetSubmitKey(config.SubmitKey)
+		cr.input.SetDraftsEnabled(config.SaveDrafts)
+		cr.input.SetVimMode(config.VimMode)
+		cr.input.SetTypoHighlighting(config.HighlightTypos)
+	}
+	if cr.chat != nil {
+		cr.chat.SetTypingIndicator(config.ShowTypingIndicator, !config.EnableAnimations || styles.PrefersReducedMotion())
+		cr.chat.SetUsageAnnotations(config.ShowMessageUsage)
+		cr.chat.SetLowBandwidthMode(config.LowBandwidthMode)
+	}
+}
+
+// SetKeyMaps replaces the keybindings of all components
+func (cr *ComponentRegistry) SetKeyMaps(keyMaps KeyMaps) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	cr.applyKeyMaps(keyMaps)
 }
 
 // Update updates all components with a message
@@ -88,6 +298,57 @@ func (cr *ComponentRegistry) Update(msg tea.Msg) tea.Cmd {
 
 	var cmds []tea.Cmd
 
+	// Propagate keybindings saved from the settings form
+	if settingsMsg, ok := msg.(SettingsMsg); ok && settingsMsg.Type == "save_success" && cr.settings != nil {
+		cr.applyKeyMaps(cr.settings.KeyMaps())
+		if config := cr.settings.GetConfig(); config != nil {
+			cr.applyConfig(config)
+		}
+	}
+	if settingsMsg, ok := msg.(SettingsMsg); ok && settingsMsg.Type == "set_config" {
+		if config, ok := settingsMsg.Data.(*storage.Config); ok {
+			cr.applyConfig(config)
+		}
+	}
+
+	// Exporting every session from the history browser is /export-all
+	if historyMsg, ok := msg.(HistoryMsg); ok && historyMsg.Type == "export_all_requested" && cr.runCommand != nil {
+		cmds = append(cmds, cr.runCommand("/export-all"))
+	}
+
+	if sidebarMsg, ok := msg.(SidebarMsg); ok {
+		switch sidebarMsg.Type {
+		case "session_selected":
+			if sessionID, ok := sidebarMsg.Data.(string); ok {
+				cmds = append(cmds, cr.switchSession(sessionID))
+			}
+		case "focus":
+			cr.focus = "Sidebar"
+			cr.applyFocus()
+		case "blur":
+			cr.focus = "Chat"
+			cr.applyFocus()
+		}
+	}
+
+	// The help key opens the keybinding overlay unless it's being typed into the input
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && cr.keyHelp != nil && cr.keyHelpTakesKey(keyMsg) {
+		cr.keyHelp, _ = cr.keyHelp.Update(keyMsg)
+		return nil
+	}
+
+	// The sidebar can be collapsed even when the breakpoint would show it
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && cr.sidebar != nil && key.Matches(keyMsg, cr.keyMaps.Chat.ToggleSidebar) {
+		cr.sidebar.Toggle()
+		cr.layoutChat()
+	}
+
+	if chatMsg, ok := msg.(ChatViewMsg); ok {
+		if statusMsg, ok := cr.trackStream(chatMsg); ok && cr.statusBar != nil {
+			cr.statusBar, _ = cr.statusBar.Update(statusMsg)
+		}
+	}
+
 	// Update components if they exist
 	if cr.chat != nil {
 		var cmd tea.Cmd
@@ -121,6 +382,14 @@ func (cr *ComponentRegistry) Update(msg tea.Msg) tea.Cmd {
 		}
 	}
 
+	if cr.sidebar != nil {
+		var cmd tea.Cmd
+		cr.sidebar, cmd = cr.sidebar.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+
 	if cr.settings != nil {
 		var cmd tea.Cmd
 		cr.settings, cmd = cr.settings.Update(msg)
@@ -137,6 +406,16 @@ func (cr *ComponentRegistry) Update(msg tea.Msg) tea.Cmd {
 		}
 	}
 
+	// Keep the sidebar's session list and model quick-switch in sync
+	if cr.sidebar != nil && cr.history != nil && cr.models != nil {
+		cr.sidebar.SetSessions(cr.history.SessionItems())
+		current := ""
+		if cr.models.selectedModel != nil {
+			current = cr.models.selectedModel.ID
+		}
+		cr.sidebar.SetModels(cr.models.models, current)
+	}
+
 	if cr.help != nil {
 		var cmd tea.Cmd
 		cr.help, cmd = cr.help.Update(msg)
@@ -177,23 +456,109 @@ func (cr *ComponentRegistry) Update(msg tea.Msg) tea.Cmd {
 		}
 	}
 
+	if _, isKey := msg.(tea.KeyMsg); cr.keyHelp != nil && !isKey {
+		var cmd tea.Cmd
+		cr.keyHelp, cmd = cr.keyHelp.Update(msg)
+		if cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	// Components size themselves to the whole window; apply the registry layout on top
+	if size, ok := msg.(tea.WindowSizeMsg); ok {
+		cr.resize(size.Width, size.Height)
+	}
+
 	return tea.Batch(cmds...)
 }
 
+// SetCommandRunner sets how component requests run slash commands, such as
+// app.Model.ExecuteCommand
+func (cr *ComponentRegistry) SetCommandRunner(run func(input string) tea.Cmd) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	cr.runCommand = run
+}
+
+// SetSession tells the components which session is shown, so the input
+// saves and restores that session's draft. Call it when a session is
+// created or loaded.
+func (cr *ComponentRegistry) SetSession(sessionID string) tea.Cmd {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	return cr.switchSession(sessionID)
+}
+
+// switchSession sends the session to the input; callers must hold the lock
+func (cr *ComponentRegistry) switchSession(sessionID string) tea.Cmd {
+	if cr.input == nil {
+		return nil
+	}
+	var cmd tea.Cmd
+	cr.input, cmd = cr.input.Update(InputMsg{Type: "set_session", Data: sessionID})
+	return cmd
+}
+
+// trackStream updates the running output estimate from the chat's stream
+// messages and returns the status update it produces; callers must hold the lock
+func (cr *ComponentRegistry) trackStream(msg ChatViewMsg) (StatusMsg, bool) {
+	switch msg.Type {
+	case "stream_start":
+		cr.streamStart = cr.now()
+		cr.streamTokens = 0
+		return StatusMsg{Type: "stream_start"}, true
+	case "stream_chunk":
+		chunk, ok := msg.Data.(string)
+		if !ok || cr.streamStart.IsZero() {
+			return StatusMsg{}, false
+		}
+		cr.streamTokens += estimateTokens(chunk)
+		return StatusMsg{Type: "stream_throughput", Data: StreamThroughput{
+			Tokens:  cr.streamTokens,
+			Elapsed: cr.now().Sub(cr.streamStart),
+		}}, true
+	case "stream_end", "stream_error":
+		cr.streamStart = time.Time{}
+		cr.streamTokens = 0
+		return StatusMsg{Type: "stream_end"}, true
+	}
+	return StatusMsg{}, false
+}
+
+// keyHelpTakesKey reports whether a key opens or closes the keybinding
+// overlay rather than going to the input; callers must hold the lock
+func (cr *ComponentRegistry) keyHelpTakesKey(msg tea.KeyMsg) bool {
+	if cr.keyHelp.Visible() {
+		return key.Matches(msg, cr.keyHelp.toggle, cr.keyHelp.close)
+	}
+	if !key.Matches(msg, cr.keyHelp.toggle) {
+		return false
+	}
+	return cr.input == nil || !cr.input.focused || cr.input.Value() == ""
+}
+
 // Resize updates component dimensions
 func (cr *ComponentRegistry) Resize(width, height int) {
 	cr.mu.Lock()
 	defer cr.mu.Unlock()
 
+	cr.resize(width, height)
+}
+
+// resize updates component dimensions; callers must hold the lock
+func (cr *ComponentRegistry) resize(width, height int) {
 	cr.width = width
 	cr.height = height
 
-	// Resize all components
-	if cr.chat != nil {
-		cr.chat.width = width - 20
-		cr.chat.height = height - 10
+	// Re-evaluate the breakpoint so layout decisions follow the new size
+	if cr.styler != nil {
+		cr.styler.Resize(width, height)
+		cr.layout = cr.styler.GetResponsiveConfig()
 	}
 
+	// Resize all components
+	cr.layoutChat()
+
 	if cr.input != nil {
 		cr.input.width = width - 20
 		cr.input.height = 3
@@ -248,6 +613,66 @@ func (cr *ComponentRegistry) Resize(width, height int) {
 		cr.tokenUsage.width = width - 30
 		cr.tokenUsage.height = height - 25
 	}
+
+	if cr.keyHelp != nil {
+		cr.keyHelp.width = width
+		cr.keyHelp.height = height
+		cr.keyHelp.help.Width = width
+	}
+}
+
+// layoutChat splits the width between the sidebar and the chat; callers must hold the lock
+func (cr *ComponentRegistry) layoutChat() {
+	hidden := cr.focusMode
+	if cr.sidebar != nil {
+		cr.sidebar.SetLayout(cr.layout)
+		hidden = hidden || cr.sidebar.Hidden()
+	}
+
+	sidebarWidth, chatWidth := SidebarWidths(cr.width, cr.layout, hidden)
+	if cr.sidebar != nil {
+		cr.sidebar.Resize(sidebarWidth, cr.height-10)
+	}
+	if cr.chat != nil {
+		cr.chat.Resize(chatWidth, cr.height-10)
+	}
+}
+
+// SetFocusMode hides the sidebar so the chat takes the full width
+func (cr *ComponentRegistry) SetFocusMode(enabled bool) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	cr.focusMode = enabled
+	cr.layoutChat()
+}
+
+// FocusMode reports whether focus mode is on
+func (cr *ComponentRegistry) FocusMode() bool {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	return cr.focusMode
+}
+
+// ChatLayout renders the chat with the sidebar beside it when shown
+func (cr *ComponentRegistry) ChatLayout() string {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+
+	if cr.chat == nil {
+		return ""
+	}
+	if cr.focusMode || cr.sidebar == nil || !cr.sidebar.Visible() {
+		return cr.chat.View()
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, cr.sidebar.View(), cr.chat.View())
+}
+
+// Layout returns the responsive configuration for the current size
+func (cr *ComponentRegistry) Layout() styles.ResponsiveConfig {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	return cr.layout
 }
 
 // Component accessors with thread safety
@@ -263,6 +688,12 @@ func (cr *ComponentRegistry) Input() *EnhancedInput {
 	return cr.input
 }
 
+func (cr *ComponentRegistry) Sidebar() *Sidebar {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	return cr.sidebar
+}
+
 func (cr *ComponentRegistry) Models() *ModelSelector {
 	cr.mu.RLock()
 	defer cr.mu.RUnlock()
@@ -317,6 +748,12 @@ func (cr *ComponentRegistry) TokenUsage() *TokenUsageDisplay {
 	return cr.tokenUsage
 }
 
+func (cr *ComponentRegistry) KeyHelp() *KeyHelpOverlay {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	return cr.keyHelp
+}
+
 // NewComponentManager creates a new component manager
 func NewComponentManager(width, height int) *ComponentManager {
 	return &ComponentManager{
@@ -358,9 +795,15 @@ func (cm *ComponentManager) UpdateFromAppState(state *app.State) {
 		chat.UpdateFromState(state.Chat)
 	}
 
-	// Update input component from chat state
+	// Update input component from chat state, switching drafts to the
+	// opened session or back to the live conversation
 	if input := cm.registry.Input(); input != nil && state.Chat != nil {
 		input.UpdateFromState(state.Chat)
+		sessionID := ""
+		if state.Chat.OpenedSession != nil {
+			sessionID = state.Chat.OpenedSession.ID
+		}
+		cm.registry.SetSession(sessionID)
 	}
 
 	// Update models component from models state
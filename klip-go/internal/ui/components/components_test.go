@@ -0,0 +1,191 @@
+package components
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/john/klip/internal/api"
+	"github.com/john/klip/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponentRegistryAppliesConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// Sections saved before the git section existed
+	config := &storage.Config{
+		NotificationBell:  true,
+		StatusBarSections: []string{"system", "model"},
+		ShowGitContext:    true,
+	}
+
+	cr := NewComponentRegistry(200, 40)
+	cr.SetConfig(config)
+	cr.Initialize()
+
+	var bell bytes.Buffer
+	cr.Notifications().bellOutput = &bell
+	cr.Notifications().AddNotification(Notification{Type: NotificationError, Title: "failed"})
+	assert.Equal(t, "\a", bell.String())
+
+	sb := cr.StatusBar()
+	sb, _ = sb.Update(StatusMsg{Type: "model_changed", Data: api.Model{Name: "test-model", Provider: api.ProviderAnthropic}})
+	sb, _ = sb.Update(StatusMsg{Type: "git_context", Data: GitContext{Branch: "main"}})
+	view := sb.View()
+	require.Contains(t, view, "⎇ main")
+	assert.Less(t, strings.Index(view, "⏱"), strings.Index(view, "test-model"))
+	assert.Less(t, strings.Index(view, "test-model"), strings.Index(view, "⎇ main"))
+
+	// Settings saved later are applied to the running components
+	cr.Update(SettingsMsg{Type: "set_config", Data: &storage.Config{StatusBarSections: []string{"model"}}})
+	bell.Reset()
+	cr.Notifications().AddNotification(Notification{Type: NotificationError, Title: "failed again"})
+	assert.Empty(t, bell.String())
+	view = cr.StatusBar().View()
+	assert.NotContains(t, view, "⎇")
+	assert.NotContains(t, view, "⏱")
+}
+
+func TestComponentRegistryLoadsUsageTotals(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	store, err := storage.NewUsageStore()
+	require.NoError(t, err)
+	_, err = store.Record(1500, 0.25)
+	require.NoError(t, err)
+
+	cr := NewComponentRegistry(200, 40)
+	cr.SetUsageStore(store)
+	cr.Initialize()
+
+	assert.Equal(t, int64(1500), cr.TokenUsage().totalTokens)
+	assert.Equal(t, 0.25, cr.TokenUsage().totalCost)
+}
+
+func TestQuestionMarkTypedIntoInput(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cr := NewComponentRegistry(200, 40)
+	cr.Initialize()
+	question := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")}
+
+	// Mid-sentence, "?" is part of the message
+	for _, r := range "why" {
+		cr.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	cr.Update(question)
+	assert.Equal(t, "why?", cr.Input().Value())
+	assert.False(t, cr.keyHelp.Visible())
+
+	// In an empty input it opens the overlay, and closes it again
+	cr.Input().SetValue("")
+	cr.Update(question)
+	assert.True(t, cr.keyHelp.Visible())
+	assert.Empty(t, cr.Input().Value())
+	cr.Update(question)
+	assert.False(t, cr.keyHelp.Visible())
+
+	// With the input unfocused it always toggles
+	cr.Input().SetValue("draft")
+	cr.Input().Blur()
+	cr.Update(question)
+	assert.True(t, cr.keyHelp.Visible())
+	assert.Equal(t, "draft", cr.Input().Value())
+}
+
+func TestKeyHelpFollowsFocus(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cr := NewComponentRegistry(200, 40)
+	cr.Initialize()
+	cr.Input().Blur()
+	question := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")}
+
+	cr.SetFocus("History")
+	cr.Update(question)
+	assert.Contains(t, cr.KeyHelp().View(), "History Keybindings")
+	assert.Contains(t, cr.KeyHelp().View(), "delete")
+	cr.Update(question)
+
+	cr.Update(SidebarMsg{Type: "focus"})
+	assert.Equal(t, "Sidebar", cr.Focus())
+	cr.Update(question)
+	assert.Contains(t, cr.KeyHelp().View(), "Sidebar Keybindings")
+	cr.Update(question)
+
+	cr.Update(SidebarMsg{Type: "blur"})
+	cr.Update(question)
+	assert.Contains(t, cr.KeyHelp().View(), "Chat Keybindings")
+}
+
+func TestKeyHelpUsesRemappedHelpKey(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cr := NewComponentRegistry(200, 40)
+	cr.Initialize()
+
+	keyMaps := DefaultKeyMaps()
+	keyMaps.Chat.Help = key.NewBinding(key.WithKeys("f9"), key.WithHelp("f9", "keybindings"))
+	cr.SetKeyMaps(keyMaps)
+
+	cr.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("?")})
+	assert.False(t, cr.KeyHelp().Visible())
+	assert.Equal(t, "?", cr.Input().Value())
+
+	cr.Input().SetValue("")
+	cr.Update(tea.KeyMsg{Type: tea.KeyF9})
+	assert.True(t, cr.KeyHelp().Visible())
+	assert.Contains(t, cr.KeyHelp().View(), "f9 or esc to close")
+}
+
+func TestComponentRegistryReportsStreamThroughput(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cr := NewComponentRegistry(200, 40)
+	cr.Initialize()
+
+	clock := time.Now()
+	cr.now = func() time.Time { return clock }
+
+	cr.Update(ChatViewMsg{Type: "stream_start"})
+	for i := 0; i < 3; i++ {
+		clock = clock.Add(500 * time.Millisecond)
+		cr.Update(ChatViewMsg{Type: "stream_chunk", Data: "a b c d e f g h i j "})
+	}
+
+	// 30 tokens over a second and a half
+	assert.Contains(t, cr.StatusBar().renderPerformanceMetrics(), "20 tok/s")
+
+	cr.Update(ChatViewMsg{Type: "stream_end"})
+	assert.NotContains(t, cr.StatusBar().renderPerformanceMetrics(), "tok/s")
+}
+
+func TestSwitchingSessionsRestoresDraft(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	require.NoError(t, storage.SaveDraft("session-1", "half-written question"))
+
+	cr := NewComponentRegistry(200, 40)
+	cr.SetConfig(&storage.Config{SaveDrafts: true})
+	cr.Initialize()
+
+	cr.SetSession("session-2")
+	assert.Empty(t, cr.Input().Value())
+
+	cr.Update(SidebarMsg{Type: "session_selected", Data: "session-1"})
+	assert.Equal(t, "half-written question", cr.Input().Value())
+}
+
+func TestExportAllRunsExportCommand(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cr := NewComponentRegistry(200, 40)
+	cr.Initialize()
+
+	var ran []string
+	cr.SetCommandRunner(func(input string) tea.Cmd {
+		ran = append(ran, input)
+		return nil
+	})
+
+	cr.Update(cr.History().exportAll()())
+	assert.Equal(t, []string{"/export-all"}, ran)
+}
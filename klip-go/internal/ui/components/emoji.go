@@ -0,0 +1,114 @@
+package components
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// emojiShortcodes maps GitHub/Slack style shortcodes to emoji
+var emojiShortcodes = map[string]string{
+	"+1":                "👍",
+	"-1":                "👎",
+	"100":               "💯",
+	"angry":             "😠",
+	"bug":               "🐛",
+	"bulb":              "💡",
+	"check":             "✔️",
+	"clap":              "👏",
+	"coffee":            "☕",
+	"confused":          "😕",
+	"cry":               "😢",
+	"eyes":              "👀",
+	"fire":              "🔥",
+	"gift":              "🎁",
+	"grin":              "😁",
+	"heart":             "❤️",
+	"heart_eyes":        "😍",
+	"heavy_check_mark":  "✔️",
+	"hourglass":         "⌛",
+	"hugs":              "🤗",
+	"joy":               "😂",
+	"laughing":          "😆",
+	"lock":              "🔒",
+	"memo":              "📝",
+	"muscle":            "💪",
+	"ok_hand":           "👌",
+	"party":             "🥳",
+	"pencil":            "✏️",
+	"point_right":       "👉",
+	"pray":              "🙏",
+	"question":          "❓",
+	"rainbow":           "🌈",
+	"rocket":            "🚀",
+	"rofl":              "🤣",
+	"sad":               "😞",
+	"see_no_evil":       "🙈",
+	"shrug":             "🤷",
+	"smile":             "😄",
+	"smiley":            "😃",
+	"smirk":             "😏",
+	"sob":               "😭",
+	"sparkles":          "✨",
+	"star":              "⭐",
+	"sunglasses":        "😎",
+	"sweat_smile":       "😅",
+	"tada":              "🎉",
+	"thinking":          "🤔",
+	"thumbsdown":        "👎",
+	"thumbsup":          "👍",
+	"warning":           "⚠️",
+	"wave":              "👋",
+	"white_check_mark":  "✅",
+	"wink":              "😉",
+	"wrench":            "🔧",
+	"x":                 "❌",
+	"zap":               "⚡",
+	"zipper_mouth_face": "🤐",
+}
+
+// maxEmojiSuggestions caps the popup so it stays compact
+const maxEmojiSuggestions = 8
+
+// emojiQueryPattern matches a shortcode being typed: a colon at the start of
+// a word followed by at least two shortcode characters
+var emojiQueryPattern = regexp.MustCompile(`(?:^|\s):([a-z0-9_+\-]{2,})$`)
+
+// emojiQuery returns the partial shortcode ending at the cursor, if any
+func emojiQuery(textBeforeCursor string) string {
+	match := emojiQueryPattern.FindStringSubmatch(textBeforeCursor)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// EmojiSuggestions returns shortcodes starting with query, shortest first
+func EmojiSuggestions(query string) []CommandSuggestion {
+	query = strings.ToLower(query)
+
+	var names []string
+	for name := range emojiShortcodes {
+		if strings.HasPrefix(name, query) {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) < len(names[j])
+		}
+		return names[i] < names[j]
+	})
+	if len(names) > maxEmojiSuggestions {
+		names = names[:maxEmojiSuggestions]
+	}
+
+	suggestions := make([]CommandSuggestion, len(names))
+	for i, name := range names {
+		suggestions[i] = CommandSuggestion{
+			Command:     ":" + name + ":",
+			Description: emojiShortcodes[name],
+		}
+	}
+	return suggestions
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
@@ -230,6 +232,8 @@ func (ih *InteractiveHelp) getKeybindingsContent() string {
 - **F2** - Model selection
 - **F3** - Settings
 - **F4** - History
+- **F9** - High contrast
+- **F11** - Focus mode
 - **F12** - Debug info
 - **Ctrl+C** - Interrupt/Quit
 - **Ctrl+D** - Quit (empty input)
@@ -529,3 +533,103 @@ func (ih *InteractiveHelp) UpdateFromState(state *app.HelpState) {
 	// Update based on app state if needed
 	// This would depend on how the app manages help state
 }
+
+// KeyHelpOverlay shows the active component's keybindings in columns
+type KeyHelpOverlay struct {
+	width   int
+	height  int
+	title   string
+	keyMap  help.KeyMap
+	help    help.Model
+	visible bool
+	toggle  key.Binding
+	close   key.Binding
+}
+
+// NewKeyHelpOverlay creates a new keybinding help overlay
+func NewKeyHelpOverlay(width, height int) *KeyHelpOverlay {
+	h := help.New()
+	h.ShowAll = true
+	h.Width = width
+
+	return &KeyHelpOverlay{
+		width:  width,
+		height: height,
+		help:   h,
+		toggle: key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "toggle keybindings")),
+		close:  key.NewBinding(key.WithKeys("esc", "q"), key.WithHelp("esc", "close")),
+	}
+}
+
+// SetKeyMap sets the keybindings shown by the overlay
+func (ko *KeyHelpOverlay) SetKeyMap(title string, keyMap help.KeyMap) {
+	ko.title = title
+	ko.keyMap = keyMap
+}
+
+// SetToggle replaces the key that opens and closes the overlay
+func (ko *KeyHelpOverlay) SetToggle(toggle key.Binding) {
+	ko.toggle = toggle
+}
+
+// Toggle shows or hides the overlay
+func (ko *KeyHelpOverlay) Toggle() {
+	ko.visible = !ko.visible && ko.keyMap != nil
+}
+
+// Visible reports whether the overlay is shown
+func (ko *KeyHelpOverlay) Visible() bool {
+	return ko.visible
+}
+
+// Update handles overlay updates
+func (ko *KeyHelpOverlay) Update(msg tea.Msg) (*KeyHelpOverlay, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		ko.width = msg.Width
+		ko.height = msg.Height
+		ko.help.Width = msg.Width
+
+	case tea.KeyMsg:
+		switch {
+		case key.Matches(msg, ko.toggle):
+			ko.Toggle()
+		case ko.visible && key.Matches(msg, ko.close):
+			ko.visible = false
+		}
+	}
+
+	return ko, nil
+}
+
+// View renders the overlay
+func (ko *KeyHelpOverlay) View() string {
+	if !ko.visible || ko.keyMap == nil {
+		return ""
+	}
+
+	title := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#7C3AED")).
+		Bold(true).
+		MarginBottom(1).
+		Render(fmt.Sprintf("%s Keybindings", ko.title))
+
+	footer := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#9CA3AF")).
+		Italic(true).
+		MarginTop(1).
+		Render(fmt.Sprintf("%s or esc to close", ko.toggle.Help().Key))
+
+	content := lipgloss.JoinVertical(lipgloss.Left, title, ko.help.FullHelpView(ko.keyMap.FullHelp()), footer)
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("#7C3AED")).
+		Padding(1, 2).
+		Render(content)
+
+	if ko.width > 0 && ko.height > 0 {
+		return lipgloss.Place(ko.width, ko.height, lipgloss.Center, lipgloss.Center, box)
+	}
+	return box
+}
@@ -0,0 +1,31 @@
+package components
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyHelpOverlayShowsChatBindings(t *testing.T) {
+	overlay := NewKeyHelpOverlay(120, 40)
+	overlay.SetKeyMap("Chat", NewChatView(80, 20).KeyMap())
+	assert.Empty(t, overlay.View())
+
+	overlay, _ = overlay.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	assert.True(t, overlay.Visible())
+
+	view := overlay.View()
+	assert.Contains(t, view, "Chat Keybindings")
+	assert.Contains(t, view, "copy message")
+	assert.Contains(t, view, "go to bottom")
+
+	overlay, _ = overlay.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.False(t, overlay.Visible())
+}
+
+func TestKeyHelpOverlayRequiresKeyMap(t *testing.T) {
+	overlay := NewKeyHelpOverlay(120, 40)
+	overlay.Toggle()
+	assert.False(t, overlay.Visible())
+}
@@ -2,10 +2,13 @@ package components
 
 import (
 	"fmt"
+	"math"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -15,6 +18,7 @@ import (
 	"github.com/dustin/go-humanize"
 	"github.com/john/klip/internal/app"
 	"github.com/john/klip/internal/storage"
+	"github.com/john/klip/internal/ui/styles"
 )
 
 // HistoryMsg represents messages for the history component
@@ -31,6 +35,7 @@ const (
 	HistoryViewTable
 	HistoryViewPreview
 	HistoryViewExport
+	HistoryViewSessionInfo
 )
 
 // SessionItem represents a chat session in the list
@@ -99,6 +104,7 @@ type HistoryBrowser struct {
 	sessions         []storage.ChatSession
 	filteredSessions []SessionItem
 	selectedSession  *storage.ChatSession
+	sessionStats     *storage.SessionStats
 	viewMode         HistoryViewMode
 	searchActive     bool
 	searchQuery      string
@@ -111,6 +117,8 @@ type HistoryBrowser struct {
 	analytics        *HistoryAnalytics
 	sortBy           string
 	sortDesc         bool
+	keys             HistoryKeyMap
+	styler           *styles.AdaptiveStyler
 }
 
 // HistoryAnalytics contains analytics about chat history
@@ -190,6 +198,7 @@ func NewHistoryBrowser(width, height int) *HistoryBrowser {
 		sortBy:        "date",
 		sortDesc:      true,
 		analytics:     &HistoryAnalytics{},
+		keys:          DefaultHistoryKeyMap(),
 	}
 }
 
@@ -245,15 +254,18 @@ func (hb *HistoryBrowser) Update(msg tea.Msg) (*HistoryBrowser, tea.Cmd) {
 			return hb, hb.refresh()
 		}
 
+	case app.SessionInfoMsg:
+		hb.showSessionInfo(msg.Session, msg.Stats)
+
 	case tea.KeyMsg:
 		// Handle global shortcuts
-		switch msg.String() {
-		case "ctrl+f", "/":
+		switch {
+		case key.Matches(msg, hb.keys.Search):
 			if !hb.searchActive {
 				hb.searchActive = true
 				return hb, hb.searchInput.Focus()
 			}
-		case "esc":
+		case key.Matches(msg, hb.keys.Back):
 			if hb.searchActive {
 				hb.searchActive = false
 				hb.searchInput.Blur()
@@ -266,7 +278,7 @@ func (hb *HistoryBrowser) Update(msg tea.Msg) (*HistoryBrowser, tea.Cmd) {
 				hb.viewMode = HistoryViewList
 				return hb, nil
 			}
-		case "enter":
+		case key.Matches(msg, hb.keys.Open):
 			if hb.searchActive {
 				hb.searchQuery = hb.searchInput.Value()
 				hb.searchActive = false
@@ -282,38 +294,48 @@ func (hb *HistoryBrowser) Update(msg tea.Msg) (*HistoryBrowser, tea.Cmd) {
 					hb.updatePreview()
 				}
 			}
-		case "1":
+		case key.Matches(msg, hb.keys.ListView):
 			hb.viewMode = HistoryViewList
-		case "2":
+		case key.Matches(msg, hb.keys.TableView):
 			hb.viewMode = HistoryViewTable
 			hb.updateTable()
-		case "3":
+		case key.Matches(msg, hb.keys.Preview):
 			if hb.selectedSession != nil {
 				hb.viewMode = HistoryViewPreview
 				hb.updatePreview()
 			}
-		case "4":
+		case key.Matches(msg, hb.keys.ExportView):
 			hb.viewMode = HistoryViewExport
-		case "d":
+		case key.Matches(msg, hb.keys.SessionInfo):
+			if !hb.searchActive {
+				if item, ok := hb.list.SelectedItem().(SessionItem); ok && hb.viewMode == HistoryViewList {
+					hb.selectedSession = &item.session
+				}
+				if hb.selectedSession != nil {
+					hb.showSessionInfo(*hb.selectedSession, storage.ComputeSessionStats(*hb.selectedSession, nil))
+					return hb, hb.requestSessionInfo(hb.selectedSession.ID)
+				}
+			}
+		case key.Matches(msg, hb.keys.Delete):
 			if !hb.searchActive && hb.viewMode == HistoryViewList {
 				if item, ok := hb.list.SelectedItem().(SessionItem); ok {
 					return hb, hb.deleteSession(item.session.ID)
 				}
 			}
-		case "e":
+		case key.Matches(msg, hb.keys.Export):
 			if !hb.searchActive && hb.selectedSession != nil {
 				hb.viewMode = HistoryViewExport
 			}
-		case "r":
+		case key.Matches(msg, hb.keys.Refresh):
 			if !hb.searchActive {
 				return hb, hb.refresh()
 			}
-		case "s":
+		case key.Matches(msg, hb.keys.Sort):
 			if !hb.searchActive {
 				hb.cycleSortOrder()
 				hb.sortSessions()
 			}
-		case "ctrl+a":
+		case key.Matches(msg, hb.keys.ExportAll):
 			return hb, hb.exportAll()
 		}
 
@@ -417,6 +439,8 @@ func (hb *HistoryBrowser) View() string {
 		content.WriteString(hb.renderPreview())
 	case HistoryViewExport:
 		content.WriteString(hb.renderExportView())
+	case HistoryViewSessionInfo:
+		content.WriteString(hb.renderSessionInfo())
 	}
 
 	// Footer
@@ -426,6 +450,11 @@ func (hb *HistoryBrowser) View() string {
 	return HistoryContainerStyle.Render(content.String())
 }
 
+// SessionItems returns the sessions as currently filtered and sorted
+func (hb *HistoryBrowser) SessionItems() []SessionItem {
+	return hb.filteredSessions
+}
+
 // SetSessions sets the chat sessions
 func (hb *HistoryBrowser) SetSessions(sessions []storage.ChatSession) {
 	hb.sessions = sessions
@@ -764,8 +793,13 @@ func (hb *HistoryBrowser) renderHeader() string {
 	content.WriteString(HistoryTitleStyle.Render(title))
 	content.WriteString("\n")
 
+	if activity := hb.renderActivity(); activity != "" {
+		content.WriteString(activity)
+		content.WriteString("\n")
+	}
+
 	// View mode tabs
-	tabs := []string{"List", "Table", "Preview", "Export"}
+	tabs := []string{"List", "Table", "Preview", "Export", "Info"}
 	var tabRendered []string
 
 	for i, tab := range tabs {
@@ -790,6 +824,98 @@ func (hb *HistoryBrowser) renderPreview() string {
 	return HistoryPreviewContainerStyle.Render(hb.preview.View())
 }
 
+// showSessionInfo switches to the statistics panel for session
+func (hb *HistoryBrowser) showSessionInfo(session storage.ChatSession, stats storage.SessionStats) {
+	hb.selectedSession = &session
+	hb.sessionStats = &stats
+	hb.viewMode = HistoryViewSessionInfo
+}
+
+// renderSessionInfo renders message counts, latency, usage and models for
+// the selected session
+func (hb *HistoryBrowser) renderSessionInfo() string {
+	if hb.selectedSession == nil || hb.sessionStats == nil {
+		return HistoryPreviewStyle.Render("No session selected. Press Esc to return to list.")
+	}
+
+	session := *hb.selectedSession
+	stats := *hb.sessionStats
+	metadata := hb.createSessionItem(session).metadata
+
+	title := session.Title
+	if title == "" {
+		title = "Session " + session.ID
+	}
+
+	var content strings.Builder
+	content.WriteString(HistoryPreviewTitleStyle.Render(title))
+	content.WriteString("\n")
+	content.WriteString(HistoryPreviewMetaStyle.Render("Started " + session.CreatedAt.Format("2006-01-02 15:04:05")))
+	content.WriteString("\n\n")
+
+	roles := make([]string, 0, len(stats.RoleCounts))
+	for role := range stats.RoleCounts {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	breakdown := make([]string, len(roles))
+	for i, role := range roles {
+		breakdown[i] = fmt.Sprintf("%d %s", stats.RoleCounts[role], role)
+	}
+
+	duration := stats.Duration
+	if duration == 0 {
+		duration = metadata.Duration
+	}
+
+	tokens := stats.TotalTokens
+	tokenNote := ""
+	if tokens == 0 {
+		tokens = metadata.TokenCount
+		tokenNote = " (estimated)"
+	}
+
+	latency := "no recorded responses"
+	if stats.Responses > 0 {
+		latency = fmt.Sprintf("%s total, %s average over %d responses",
+			stats.TotalLatency.Round(time.Millisecond),
+			stats.AverageLatency().Round(time.Millisecond),
+			stats.Responses)
+	}
+
+	models := stats.Models
+	if len(models) == 0 {
+		models = metadata.Models
+	}
+	modelList := "unknown"
+	if len(models) > 0 {
+		modelList = strings.Join(models, ", ")
+	}
+
+	rows := [][2]string{
+		{"Messages", fmt.Sprintf("%d (%s)", metadata.MessageCount, strings.Join(breakdown, ", "))},
+		{"Duration", duration.Round(time.Second).String()},
+		{"Latency", latency},
+		{"Tokens", humanize.Comma(int64(tokens)) + tokenNote},
+		{"Cost", fmt.Sprintf("$%.4f", stats.TotalCost)},
+		{"Models", modelList},
+	}
+	for _, row := range rows {
+		content.WriteString(HistoryStatsLabelStyle.Render(fmt.Sprintf("%-10s", row[0])))
+		content.WriteString(row[1])
+		content.WriteString("\n")
+	}
+
+	return HistoryPreviewContainerStyle.Render(strings.TrimSuffix(content.String(), "\n"))
+}
+
+// requestSessionInfo asks the app to load analytics for the session's stats
+func (hb *HistoryBrowser) requestSessionInfo(sessionID string) tea.Cmd {
+	return func() tea.Msg {
+		return HistoryMsg{Type: "session_info_requested", Data: sessionID}
+	}
+}
+
 // renderExportView renders the export selection view
 func (hb *HistoryBrowser) renderExportView() string {
 	var content strings.Builder
@@ -824,32 +950,156 @@ func (hb *HistoryBrowser) renderExportView() string {
 
 // renderFooter renders the footer with keyboard shortcuts
 func (hb *HistoryBrowser) renderFooter() string {
-	var shortcuts []string
+	var shortcuts string
 
 	if hb.searchActive {
-		shortcuts = []string{"enter: search", "esc: cancel"}
+		shortcuts = bindingHints(
+			key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "search")),
+			key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "cancel")),
+		)
 	} else {
 		switch hb.viewMode {
 		case HistoryViewList:
-			shortcuts = []string{
-				"enter: preview", "d: delete", "e: export", "/: search", "s: sort", "r: refresh",
-			}
+			shortcuts = bindingHints(hb.keys.Open, hb.keys.Delete, hb.keys.Export, hb.keys.Search, hb.keys.Sort, hb.keys.Refresh)
 		case HistoryViewTable:
-			shortcuts = []string{
-				"1: list", "3: preview", "s: sort", "/: search", "r: refresh",
-			}
+			shortcuts = bindingHints(hb.keys.ListView, hb.keys.Preview, hb.keys.Sort, hb.keys.Search, hb.keys.Refresh)
 		case HistoryViewPreview:
-			shortcuts = []string{
-				"esc: back", "e: export", "d: delete",
-			}
+			shortcuts = bindingHints(hb.keys.Back, hb.keys.SessionInfo, hb.keys.Export, hb.keys.Delete)
+		case HistoryViewSessionInfo:
+			shortcuts = bindingHints(hb.keys.Back, hb.keys.Preview, hb.keys.Export)
 		case HistoryViewExport:
-			shortcuts = []string{
-				"enter: export", "esc: back", "ctrl+a: export all",
-			}
+			shortcuts = bindingHints(
+				key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "export")),
+				hb.keys.Back,
+				hb.keys.ExportAll,
+			)
 		}
 	}
 
-	return HistoryFooterStyle.Render(strings.Join(shortcuts, " • "))
+	return HistoryFooterStyle.Render(shortcuts)
+}
+
+// maxActivityDays is the longest window shown in the activity sparkline
+const maxActivityDays = 30
+
+// renderActivity renders recent daily message counts as a sparkline that
+// shrinks to fit the header
+func (hb *HistoryBrowser) renderActivity() string {
+	if hb.analytics == nil || len(hb.analytics.DailyActivity) == 0 {
+		return ""
+	}
+
+	// Leave room for the label and peak annotation
+	days := hb.width - 22
+	if days > maxActivityDays {
+		days = maxActivityDays
+	}
+	if days < 7 {
+		return ""
+	}
+
+	counts := dailyMessageCounts(hb.analytics.DailyActivity, time.Now(), days)
+	glyphs := sparklineGlyphs(counts, charsetFor(hb.styler).Sparkline)
+
+	peak := 0
+	for _, count := range counts {
+		if count > peak {
+			peak = count
+		}
+	}
+
+	theme := styles.GetCurrentTheme()
+	var line strings.Builder
+	for i, glyph := range glyphs {
+		line.WriteString(sparklineStyle(theme, counts[i], peak).Render(glyph))
+	}
+
+	return HistoryActivityLabelStyle.Render(fmt.Sprintf("Last %dd ", days)) +
+		line.String() +
+		HistoryActivityLabelStyle.Render(fmt.Sprintf(" peak %d", peak))
+}
+
+// dailyMessageCounts buckets message counts into the days ending at end, oldest first
+func dailyMessageCounts(activity []DayActivity, end time.Time, days int) []int {
+	counts := make([]int, days)
+	endDay := startOfDay(end)
+
+	for _, day := range activity {
+		// Round to absorb daylight saving shifts
+		offset := int(math.Round(endDay.Sub(startOfDay(day.Date.In(end.Location()))).Hours() / 24))
+		if offset >= 0 && offset < days {
+			counts[days-1-offset] += day.Messages
+		}
+	}
+
+	return counts
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// sparklineGlyphs maps each count to a glyph, with empty days on the lowest
+// level and the busiest day on the highest
+func sparklineGlyphs(counts []int, levels []string) []string {
+	if len(levels) == 0 {
+		levels = styles.ASCIICharacterSet.Sparkline
+	}
+
+	peak := 0
+	for _, count := range counts {
+		if count > peak {
+			peak = count
+		}
+	}
+
+	glyphs := make([]string, len(counts))
+	for i, count := range counts {
+		glyphs[i] = levels[sparklineLevel(count, peak, len(levels))]
+	}
+	return glyphs
+}
+
+// sparklineLevel scales a count to 0..levels-1, keeping any activity above zero
+func sparklineLevel(count, peak, levels int) int {
+	if count <= 0 || peak <= 0 || levels < 2 {
+		return 0
+	}
+	return int(math.Ceil(float64(count) / float64(peak) * float64(levels-1)))
+}
+
+// sparklineStyle colors a day by its share of the busiest day
+func sparklineStyle(theme *styles.Theme, count, peak int) lipgloss.Style {
+	style := lipgloss.NewStyle()
+	if theme == nil {
+		return style
+	}
+
+	switch ratio := float64(count) / math.Max(float64(peak), 1); {
+	case count <= 0:
+		return style.Foreground(theme.Colors.TextMuted)
+	case ratio < 0.34:
+		return style.Foreground(theme.Colors.PrimaryDark)
+	case ratio < 0.67:
+		return style.Foreground(theme.Colors.Primary)
+	default:
+		return style.Foreground(theme.Colors.PrimaryLight)
+	}
+}
+
+// KeyMap returns the history browser keybindings
+func (hb *HistoryBrowser) KeyMap() help.KeyMap {
+	return hb.keys
+}
+
+// SetStyler adapts the activity sparkline to the terminal's capabilities
+func (hb *HistoryBrowser) SetStyler(styler *styles.AdaptiveStyler) {
+	hb.styler = styler
+}
+
+// SetKeyMap replaces the history browser keybindings
+func (hb *HistoryBrowser) SetKeyMap(keys HistoryKeyMap) {
+	hb.keys = keys
 }
 
 // Command functions
@@ -920,6 +1170,9 @@ var (
 				Foreground(lipgloss.Color("#7C3AED")).
 				Bold(true)
 
+	HistoryActivityLabelStyle = lipgloss.NewStyle().
+					Foreground(lipgloss.Color("#6B7280"))
+
 	// Tab styles
 	HistoryTabContainerStyle = lipgloss.NewStyle().
 					BorderBottom(true).
@@ -987,6 +1240,10 @@ var (
 				Foreground(lipgloss.Color("#6B7280")).
 				Italic(true)
 
+	HistoryStatsLabelStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#6B7280")).
+				Bold(true)
+
 	HistoryPreviewUserHeaderStyle = lipgloss.NewStyle().
 					Foreground(lipgloss.Color("#3B82F6")).
 					Bold(true)
@@ -0,0 +1,50 @@
+package components
+
+import (
+	"testing"
+	"time"
+
+	"github.com/john/klip/internal/storage"
+	"github.com/john/klip/internal/ui/styles"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActivitySparklineScalesToPeak(t *testing.T) {
+	end := time.Date(2024, 5, 10, 18, 0, 0, 0, time.UTC)
+	activity := []DayActivity{
+		{Date: end, Messages: 3},
+		{Date: end.AddDate(0, 0, -2), Messages: 12},
+		{Date: end.AddDate(0, 0, -5), Messages: 1},
+		{Date: end.AddDate(0, 0, -40), Messages: 99},
+	}
+
+	counts := dailyMessageCounts(activity, end, 14)
+	assert.Len(t, counts, 14)
+	assert.Equal(t, 12, counts[11])
+
+	levels := styles.UnicodeCharacterSet.Sparkline
+	glyphs := sparklineGlyphs(counts, levels)
+	assert.Len(t, glyphs, 14)
+	assert.Equal(t, levels[len(levels)-1], glyphs[11])
+	assert.Equal(t, levels[0], glyphs[0])
+	assert.NotEqual(t, levels[0], glyphs[8], "days with any activity should rise above the baseline")
+
+	ascii := sparklineGlyphs(counts, styles.ASCIICharacterSet.Sparkline)
+	assert.Equal(t, "#", ascii[11])
+}
+
+func TestHistoryHeaderShowsActivity(t *testing.T) {
+	now := time.Now()
+	hb := NewHistoryBrowser(80, 20)
+	hb.SetSessions([]storage.ChatSession{{
+		ID:        "session-activity",
+		CreatedAt: now,
+		UpdatedAt: now,
+		Messages:  []storage.Message{{Role: "user", Content: "hello"}},
+	}})
+
+	assert.Contains(t, hb.renderHeader(), "Last 30d")
+
+	hb.width = 24
+	assert.NotContains(t, hb.renderHeader(), "Last")
+}
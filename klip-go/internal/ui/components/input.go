@@ -3,14 +3,20 @@ package components
 import (
 	"fmt"
 	"strings"
+	"time"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/john/klip/internal/api"
 	"github.com/john/klip/internal/app"
+	"github.com/john/klip/internal/storage"
+	"github.com/john/klip/internal/ui/styles"
 )
 
 // InputType represents different input modes
@@ -54,9 +60,52 @@ type EnhancedInput struct {
 	showCharCount      bool
 	showTokenCount     bool
 	tokenEstimate      int
-	validator          func(string) error
+	validator          Validator
+	validators         *ValidatorRegistry
 	errorMessage       string
 	focused            bool
+	keys               InputKeyMap
+	submitKey          string
+
+	// Draft autosave; draftSeq debounces saves so only the latest edit is written
+	draftsEnabled bool
+	draftSession  string
+	draftSeq      int
+
+	// Modal editing, nil unless Vim mode is enabled
+	vim *vimEditor
+
+	// Typo checking runs in a command; typoSeq discards stale results
+	typoChecker     *TypoChecker
+	typos           []Typo
+	typoSeq         int
+	styledUnderline bool
+
+	// Emoji shortcode completion; emojiQuery is the partial shortcode being typed
+	emojiQuery     string
+	emojiMatches   []CommandSuggestion
+	emojiSupported bool
+
+	// Multi-line text pasted in single-line mode, waiting for the user to
+	// choose between switching modes and joining the lines
+	pendingPaste string
+
+	// Large text widens the input's padding
+	accessibility *styles.AccessibilityManager
+}
+
+// typoCheckMsg carries the result of checking the input text
+type typoCheckMsg struct {
+	seq   int
+	typos []Typo
+}
+
+// draftSaveDelay is how long typing must pause before the draft is written
+const draftSaveDelay = time.Second
+
+// draftSaveMsg writes the draft if no edits happened since it was scheduled
+type draftSaveMsg struct {
+	seq int
 }
 
 // NewEnhancedInput creates a new enhanced input component
@@ -72,6 +121,9 @@ func NewEnhancedInput(inputType InputType, width, height int) *EnhancedInput {
 		showCharCount:  true,
 		showTokenCount: true,
 		focused:        true,
+		keys:           DefaultInputKeyMap(),
+		submitKey:      "enter",
+		emojiSupported: true,
 	}
 
 	switch inputType {
@@ -80,19 +132,17 @@ func NewEnhancedInput(inputType InputType, width, height int) *EnhancedInput {
 		ta.SetWidth(width - 4) // Account for padding and borders
 		ta.SetHeight(height - 2)
 		ta.Focus()
-		ta.Placeholder = "Type your message (Ctrl+Enter to send, Esc to switch modes)..."
 		ta.ShowLineNumbers = false
-		ta.KeyMap.InsertNewline.SetKeys("enter")
 		ei.textArea = ta
 	default:
 		ti := textinput.New()
 		ti.Width = width - 4
 		ti.Focus()
-		ti.Placeholder = "Type your message or /command..."
 		ti.CharLimit = 4000 // Default character limit
 		ei.textInput = ti
 	}
 
+	ei.applySubmitKey()
 	ei.updateTokenEstimate()
 	return ei
 }
@@ -113,12 +163,7 @@ func (ei *EnhancedInput) Update(msg tea.Msg) (*EnhancedInput, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		ei.width = msg.Width
 		ei.height = msg.Height
-		if ei.inputType == InputTypeMultiline {
-			ei.textArea.SetWidth(msg.Width - 4)
-			ei.textArea.SetHeight(ei.height - 2)
-		} else {
-			ei.textInput.Width = msg.Width - 4
-		}
+		ei.sizeField()
 
 	case InputMsg:
 		switch msg.Type {
@@ -142,36 +187,83 @@ func (ei *EnhancedInput) Update(msg tea.Msg) (*EnhancedInput, tea.Cmd) {
 			if value, ok := msg.Data.(string); ok {
 				ei.AddToHistory(value)
 			}
+		case "set_session":
+			if sessionID, ok := msg.Data.(string); ok {
+				ei.SetDraftSession(sessionID)
+			}
+		case "add_to_dictionary":
+			word, _ := msg.Data.(string)
+			return ei, ei.AddToDictionary(word)
+		}
+
+	case ChatViewMsg:
+		if msg.Type == "reply_to_message" {
+			if message, ok := msg.Data.(api.Message); ok {
+				ei.QuoteReply(message)
+				return ei, ei.valueChanged()
+			}
+		}
+
+	case draftSaveMsg:
+		if msg.seq == ei.draftSeq {
+			return ei, ei.saveDraft()
+		}
+
+	case typoCheckMsg:
+		if msg.seq == ei.typoSeq && ei.typoChecker != nil {
+			ei.typos = msg.typos
 		}
 
 	case tea.KeyMsg:
+		// Pasted text is inserted verbatim, never run as keys or commands
+		if msg.Paste {
+			return ei, ei.handlePaste(string(msg.Runes))
+		}
+		if ei.pendingPaste != "" {
+			return ei, ei.resolvePendingPaste(msg)
+		}
+
 		// Handle global shortcuts first
 		switch msg.String() {
 		case "ctrl+c":
 			if ei.inputType == InputTypeMultiline {
 				return ei, tea.Quit
 			}
-		case "ctrl+v":
+		}
+
+		if ei.vim != nil {
+			if handled, cmd := ei.handleVimKey(msg); handled {
+				return ei, cmd
+			}
+		}
+
+		switch {
+		case key.Matches(msg, ei.keys.Paste):
 			cmd = ei.pasteFromClipboard()
 			cmds = append(cmds, cmd)
-		case "ctrl+x":
+		case key.Matches(msg, ei.keys.Cut):
 			cmd = ei.cutToClipboard()
 			cmds = append(cmds, cmd)
-		case "ctrl+z":
+		case key.Matches(msg, ei.keys.Undo):
 			cmd = ei.undo()
 			cmds = append(cmds, cmd)
-		case "tab":
-			if ei.showSuggestions && len(ei.suggestions) > 0 {
+		case ei.typoChecker != nil && key.Matches(msg, ei.keys.AddToDictionary):
+			return ei, ei.AddToDictionary("")
+		case ei.emojiQuery != "" && ei.showSuggestions && (key.Matches(msg, ei.keys.AcceptSuggestion) || msg.Type == tea.KeyEnter):
+			ei.acceptSuggestion()
+			return ei, ei.valueChanged()
+		case key.Matches(msg, ei.keys.AcceptSuggestion):
+			if ei.showSuggestions && len(ei.activeSuggestions()) > 0 {
 				ei.acceptSuggestion()
 				ei.updateSuggestions()
 				return ei, tea.Batch(cmds...)
 			}
-		case "esc":
+		case key.Matches(msg, ei.keys.Dismiss):
 			if ei.showSuggestions {
 				ei.showSuggestions = false
 				return ei, tea.Batch(cmds...)
 			}
-		case "up":
+		case key.Matches(msg, ei.keys.HistoryPrev):
 			if ei.showSuggestions {
 				ei.navigateSuggestions(-1)
 				return ei, tea.Batch(cmds...)
@@ -179,7 +271,7 @@ func (ei *EnhancedInput) Update(msg tea.Msg) (*EnhancedInput, tea.Cmd) {
 				ei.navigateHistory(-1)
 				return ei, tea.Batch(cmds...)
 			}
-		case "down":
+		case key.Matches(msg, ei.keys.HistoryNext):
 			if ei.showSuggestions {
 				ei.navigateSuggestions(1)
 				return ei, tea.Batch(cmds...)
@@ -187,27 +279,19 @@ func (ei *EnhancedInput) Update(msg tea.Msg) (*EnhancedInput, tea.Cmd) {
 				ei.navigateHistory(1)
 				return ei, tea.Batch(cmds...)
 			}
-		case "enter":
-			if ei.inputType != InputTypeMultiline {
-				value := ei.Value()
-				if value != "" {
-					ei.AddToHistory(value)
-					return ei, ei.submitValue(value)
-				}
-				return ei, tea.Batch(cmds...)
-			}
-		case "ctrl+enter":
-			if ei.inputType == InputTypeMultiline {
-				value := ei.Value()
-				if value != "" {
-					ei.AddToHistory(value)
-					return ei, ei.submitValue(value)
-				}
-				return ei, tea.Batch(cmds...)
+		case ei.inputType != InputTypeMultiline && key.Matches(msg, ei.keys.Submit),
+			ei.inputType == InputTypeMultiline && key.Matches(msg, ei.keys.SubmitMultiline):
+			value := ei.Value()
+			ei.validateInput()
+			if value != "" && ei.errorMessage == "" {
+				ei.AddToHistory(value)
+				return ei, tea.Batch(ei.submitValue(value), ei.clearDraft())
 			}
+			return ei, tea.Batch(cmds...)
 		}
 
 		// Update the underlying input component
+		before := ei.Value()
 		if ei.inputType == InputTypeMultiline {
 			ei.textArea, cmd = ei.textArea.Update(msg)
 		} else {
@@ -215,6 +299,10 @@ func (ei *EnhancedInput) Update(msg tea.Msg) (*EnhancedInput, tea.Cmd) {
 		}
 		cmds = append(cmds, cmd)
 
+		if ei.Value() != before {
+			cmds = append(cmds, ei.valueChanged())
+		}
+
 		// Update suggestions and token estimate after text changes
 		ei.updateSuggestions()
 		ei.updateTokenEstimate()
@@ -238,21 +326,29 @@ func (ei *EnhancedInput) View() string {
 
 	// Render main input area
 	if ei.inputType == InputTypeMultiline {
-		content.WriteString(MultilineInputStyle.Render(ei.textArea.View()))
+		field := ei.underlineTypos(ei.textArea.View())
+		content.WriteString(ei.accessibility.LargeTextStyle(MultilineInputStyle).Render(field))
 	} else {
-		inputView := ei.textInput.View()
+		style := InputStyle
 		if ei.errorMessage != "" {
-			inputView = ErrorInputStyle.Render(inputView)
+			style = ErrorInputStyle
 		} else if ei.focused {
-			inputView = FocusedInputStyle.Render(inputView)
-		} else {
-			inputView = InputStyle.Render(inputView)
+			style = FocusedInputStyle
 		}
-		content.WriteString(inputView)
+		field := ei.underlineTypos(ei.textInput.View())
+		content.WriteString(ei.accessibility.LargeTextStyle(style).Render(field))
+	}
+
+	// Ask how to paste multi-line text into a single-line input
+	if ei.pendingPaste != "" {
+		lines := strings.Count(ei.pendingPaste, "\n") + 1
+		content.WriteString("\n")
+		content.WriteString(PasteNoticeStyle.Render(fmt.Sprintf(
+			"Pasted %d lines: enter switches to multiline, esc pastes as one line", lines)))
 	}
 
 	// Render suggestions if shown
-	if ei.showSuggestions && len(ei.suggestions) > 0 {
+	if ei.showSuggestions && len(ei.activeSuggestions()) > 0 {
 		content.WriteString("\n")
 		content.WriteString(ei.renderSuggestions())
 	}
@@ -321,6 +417,471 @@ func (ei *EnhancedInput) SetPlaceholder(placeholder string) {
 	}
 }
 
+// SetKeyMap replaces the input keybindings
+func (ei *EnhancedInput) SetKeyMap(keys InputKeyMap) {
+	ei.keys = keys
+	ei.applySubmitKey()
+}
+
+// SetVimMode turns modal Vim editing on or off, starting in insert mode
+func (ei *EnhancedInput) SetVimMode(enabled bool) {
+	switch {
+	case enabled && ei.vim == nil:
+		ei.vim = newVimEditor()
+	case !enabled:
+		ei.vim = nil
+	}
+}
+
+// VimMode returns the current Vim editing mode and whether Vim mode is enabled
+func (ei *EnhancedInput) VimMode() (VimMode, bool) {
+	if ei.vim == nil {
+		return VimInsert, false
+	}
+	return ei.vim.mode, true
+}
+
+// handleVimKey routes a key through the Vim editor, reporting whether it was
+// consumed. Keys the editor passes through get the usual input handling.
+func (ei *EnhancedInput) handleVimKey(msg tea.KeyMsg) (bool, tea.Cmd) {
+	before := ei.Value()
+	buf, cursor, action := ei.vim.handleKey([]rune(before), ei.cursorOffset(), msg.String())
+
+	switch action {
+	case vimPassThrough:
+		return false, nil
+	case vimSubmit:
+		if before == "" {
+			return true, nil
+		}
+		ei.AddToHistory(before)
+		return true, tea.Batch(ei.submitValue(before), ei.clearDraft())
+	}
+
+	ei.showSuggestions = false
+	value := string(buf)
+	ei.setValueAndCursor(value, cursor)
+	if value == before {
+		return true, nil
+	}
+
+	ei.updateTokenEstimate()
+	ei.validateInput()
+	return true, ei.valueChanged()
+}
+
+// cursorOffset returns the cursor as a rune offset into Value()
+func (ei *EnhancedInput) cursorOffset() int {
+	if ei.inputType != InputTypeMultiline {
+		return ei.textInput.Position()
+	}
+
+	lines := strings.Split(ei.textArea.Value(), "\n")
+	row := ei.textArea.Line()
+	offset := 0
+	for i := 0; i < row && i < len(lines); i++ {
+		offset += len([]rune(lines[i])) + 1
+	}
+
+	info := ei.textArea.LineInfo()
+	return offset + info.StartColumn + info.ColumnOffset
+}
+
+// setValueAndCursor replaces the text and places the cursor at a rune offset
+func (ei *EnhancedInput) setValueAndCursor(value string, offset int) {
+	if ei.inputType != InputTypeMultiline {
+		ei.textInput.SetValue(value)
+		ei.textInput.SetCursor(offset)
+		return
+	}
+
+	runes := []rune(value)
+	offset = clampInt(offset, 0, len(runes))
+	row := strings.Count(string(runes[:offset]), "\n")
+	col := offset - lineStart(runes, offset)
+
+	// SetValue leaves the cursor at the end; walk back up to the target row
+	ei.textArea.SetValue(value)
+	for guard := len(runes); ei.textArea.Line() > row && guard > 0; guard-- {
+		ei.textArea.CursorUp()
+	}
+	ei.textArea.SetCursor(col)
+}
+
+// handlePaste inserts bracketed-paste text at the cursor without treating
+// newlines as submit or a leading "/" as a command. Multi-line text in
+// single-line mode waits for the user to pick how to insert it.
+func (ei *EnhancedInput) handlePaste(text string) tea.Cmd {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	if ei.inputType != InputTypeMultiline {
+		text = strings.TrimRight(text, "\n")
+		if strings.Contains(text, "\n") {
+			ei.pendingPaste = text
+			ei.showSuggestions = false
+			return nil
+		}
+	}
+
+	ei.insertPaste(text)
+	return ei.valueChanged()
+}
+
+// resolvePendingPaste handles the answer to the multi-line paste prompt
+func (ei *EnhancedInput) resolvePendingPaste(msg tea.KeyMsg) tea.Cmd {
+	text := ei.pendingPaste
+
+	switch msg.String() {
+	case "enter", "y":
+		ei.pendingPaste = ""
+		ei.ToggleMode()
+	case "esc", "n":
+		ei.pendingPaste = ""
+		text = strings.Join(strings.Split(text, "\n"), " ")
+	default:
+		return nil
+	}
+
+	ei.insertPaste(text)
+	return ei.valueChanged()
+}
+
+// insertPaste puts text at the cursor, keeping the suggestion popup closed
+func (ei *EnhancedInput) insertPaste(text string) {
+	if ei.inputType == InputTypeMultiline {
+		ei.textArea.InsertString(text)
+	} else {
+		runes := []rune(ei.Value())
+		cursor := ei.cursorOffset()
+		value := string(runes[:cursor]) + text + string(runes[cursor:])
+		ei.setValueAndCursor(value, cursor+len([]rune(text)))
+	}
+
+	ei.showSuggestions = false
+	ei.emojiQuery = ""
+	ei.updateTokenEstimate()
+	ei.validateInput()
+}
+
+// valueChanged runs the background work that follows an edit
+func (ei *EnhancedInput) valueChanged() tea.Cmd {
+	return tea.Batch(ei.scheduleDraftSave(), ei.scheduleTypoCheck())
+}
+
+// SetStyler adapts input rendering to the terminal's capabilities
+func (ei *EnhancedInput) SetStyler(styler *styles.AdaptiveStyler) {
+	caps := styler.GetCapabilities()
+	// Terminals with truecolor support generally understand styled
+	// underlines, which keeps typos from looking like links
+	ei.styledUnderline = caps.SupportsTrueColor
+	ei.emojiSupported = caps.SupportsEmoji
+}
+
+// SetAccessibility sets the manager whose large text setting widens the
+// input's padding
+func (ei *EnhancedInput) SetAccessibility(am *styles.AccessibilityManager) {
+	ei.accessibility = am
+	ei.sizeField()
+}
+
+// sizeField fits the text field inside the input's borders and padding,
+// which grow with large text
+func (ei *EnhancedInput) sizeField() {
+	if ei.inputType == InputTypeMultiline {
+		padded := ei.accessibility.LargeTextStyle(MultilineInputStyle)
+		ei.textArea.SetWidth(ei.width - 4 - (padded.GetHorizontalPadding() - MultilineInputStyle.GetHorizontalPadding()))
+		ei.textArea.SetHeight(ei.height - 2 - (padded.GetVerticalPadding() - MultilineInputStyle.GetVerticalPadding()))
+		return
+	}
+	padded := ei.accessibility.LargeTextStyle(InputStyle)
+	ei.textInput.Width = ei.width - 4 - (padded.GetHorizontalPadding() - InputStyle.GetHorizontalPadding())
+}
+
+// SetTypoHighlighting turns common typo highlighting on or off, loading the
+// personal dictionary when it is enabled
+func (ei *EnhancedInput) SetTypoHighlighting(enabled bool) {
+	if !enabled {
+		ei.typoChecker = nil
+		ei.typos = nil
+		ei.typoSeq++
+		return
+	}
+	if ei.typoChecker != nil {
+		return
+	}
+
+	words, _ := storage.LoadDictionary()
+	ei.typoChecker = NewTypoChecker(words)
+	ei.typos = ei.typoChecker.Check(ei.Value())
+}
+
+// Typos returns the words flagged in the current text
+func (ei *EnhancedInput) Typos() []Typo {
+	return ei.typos
+}
+
+// scheduleTypoCheck checks the current text off the update loop so long
+// prompts never slow down typing
+func (ei *EnhancedInput) scheduleTypoCheck() tea.Cmd {
+	if ei.typoChecker == nil {
+		return nil
+	}
+
+	ei.typoSeq++
+	seq, checker, text := ei.typoSeq, ei.typoChecker, ei.Value()
+	return func() tea.Msg {
+		return typoCheckMsg{seq: seq, typos: checker.Check(text)}
+	}
+}
+
+// AddToDictionary accepts a word from now on, defaulting to the first flagged
+// word, and saves it to the personal dictionary
+func (ei *EnhancedInput) AddToDictionary(word string) tea.Cmd {
+	if ei.typoChecker == nil {
+		return nil
+	}
+	if word == "" {
+		if len(ei.typos) == 0 {
+			return nil
+		}
+		word = ei.typos[0].Word
+	}
+
+	ei.typoChecker.AddWord(word)
+	remaining := ei.typos[:0:0]
+	for _, m := range ei.typos {
+		if !strings.EqualFold(m.Word, word) {
+			remaining = append(remaining, m)
+		}
+	}
+	ei.typos = remaining
+
+	return func() tea.Msg {
+		if err := storage.AddDictionaryWord(word); err != nil {
+			return InputMsg{Type: "dictionary_error", Data: err}
+		}
+		return InputMsg{Type: "dictionary_added", Data: word}
+	}
+}
+
+// underlineTypos marks the flagged words in the rendered field with a
+// dotted red underline where the terminal supports styled underlines and a
+// plain one elsewhere
+func (ei *EnhancedInput) underlineTypos(field string) string {
+	on, off := "\x1b[4m", "\x1b[24m"
+	if ei.styledUnderline {
+		on, off = "\x1b[4:4m\x1b[58:2::220:38:38m", "\x1b[4:0m\x1b[59m"
+	}
+	return underlineSpans(field, ei.Value(), ei.typos, on, off)
+}
+
+// typoHint names the first flagged word's correction for the footer
+func (ei *EnhancedInput) typoHint() string {
+	first := ei.typos[0]
+	hint := first.Word + " → " + first.Suggestion
+	if more := len(ei.typos) - 1; more > 0 {
+		hint += fmt.Sprintf(" +%d", more)
+	}
+	return TypoHintStyle.Render(hint)
+}
+
+// SetDraftsEnabled turns draft autosave on or off. Turning it off deletes any
+// saved draft so unsent text doesn't linger on disk.
+func (ei *EnhancedInput) SetDraftsEnabled(enabled bool) {
+	wasEnabled := ei.draftsEnabled
+	ei.draftsEnabled = enabled
+
+	switch {
+	case enabled && !wasEnabled:
+		ei.RestoreDraft()
+	case !enabled && wasEnabled:
+		ei.draftSeq++
+		_ = storage.ClearDraft()
+	}
+}
+
+// SetDraftSession switches the conversation drafts are saved for, restoring
+// that conversation's draft into an empty composer
+func (ei *EnhancedInput) SetDraftSession(sessionID string) {
+	if sessionID == ei.draftSession {
+		return
+	}
+	ei.draftSession = sessionID
+	ei.draftSeq++
+
+	if ei.Value() == "" {
+		ei.RestoreDraft()
+	}
+}
+
+// RestoreDraft loads the saved draft for the current session into the input,
+// reporting whether one was found
+func (ei *EnhancedInput) RestoreDraft() bool {
+	if !ei.draftsEnabled {
+		return false
+	}
+
+	content, err := storage.LoadDraft(ei.draftSession)
+	if err != nil || content == "" {
+		return false
+	}
+
+	ei.SetValue(content)
+	return true
+}
+
+// scheduleDraftSave debounces draft writes while the user is typing
+func (ei *EnhancedInput) scheduleDraftSave() tea.Cmd {
+	if !ei.draftsEnabled {
+		return nil
+	}
+
+	ei.draftSeq++
+	seq := ei.draftSeq
+	return tea.Tick(draftSaveDelay, func(time.Time) tea.Msg {
+		return draftSaveMsg{seq: seq}
+	})
+}
+
+// saveDraft writes the current input for the current session. Drafts are
+// best effort, so failures are ignored.
+func (ei *EnhancedInput) saveDraft() tea.Cmd {
+	if !ei.draftsEnabled {
+		return nil
+	}
+
+	sessionID, content := ei.draftSession, ei.Value()
+	return func() tea.Msg {
+		_ = storage.SaveDraft(sessionID, content)
+		return nil
+	}
+}
+
+// clearDraft removes the saved draft once its text has been sent
+func (ei *EnhancedInput) clearDraft() tea.Cmd {
+	if !ei.draftsEnabled {
+		return nil
+	}
+
+	ei.draftSeq++
+	return func() tea.Msg {
+		_ = storage.ClearDraft()
+		return nil
+	}
+}
+
+// SetSubmitKey sets the key that sends a message in both input modes. In
+// multiline mode the remaining send keys insert a newline instead.
+func (ei *EnhancedInput) SetSubmitKey(submitKey string) {
+	if submitKey == "" {
+		submitKey = "enter"
+	}
+	ei.submitKey = submitKey
+	ei.applySubmitKey()
+}
+
+// applySubmitKey rebinds submission and newlines to the configured send key
+// and refreshes the placeholder to match. The send key setting takes
+// precedence over the input.submit keybindings. A single line has no use
+// for Enter other than sending, so it always sends there too.
+func (ei *EnhancedInput) applySubmitKey() {
+	ei.keys.Submit = key.NewBinding(key.WithKeys(ei.submitKey, "enter"), key.WithHelp(ei.submitKey, "send"))
+	ei.keys.SubmitMultiline = key.NewBinding(key.WithKeys(ei.submitKey), key.WithHelp(ei.submitKey, "send (multiline)"))
+
+	if ei.inputType == InputTypeMultiline {
+		var newlineKeys []string
+		for _, k := range storage.SubmitKeys() {
+			if k != ei.submitKey {
+				newlineKeys = append(newlineKeys, k)
+			}
+		}
+		ei.textArea.KeyMap.InsertNewline.SetKeys(newlineKeys...)
+	}
+
+	if ei.placeholder == "" {
+		if ei.inputType == InputTypeMultiline {
+			ei.textArea.Placeholder = ei.defaultPlaceholder()
+		} else {
+			ei.textInput.Placeholder = ei.defaultPlaceholder()
+		}
+	}
+}
+
+// defaultPlaceholder describes the active send key for the current mode
+func (ei *EnhancedInput) defaultPlaceholder() string {
+	if ei.inputType == InputTypeMultiline {
+		return fmt.Sprintf("Type your message (%s to send, Esc to switch modes)...", submitKeyLabel(ei.submitKey))
+	}
+	return "Type your message or /command..."
+}
+
+// submitKeyLabel formats a key name the way placeholders spell it, e.g. "Alt+Enter"
+func submitKeyLabel(k string) string {
+	parts := strings.Split(k, "+")
+	for i, part := range parts {
+		if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+// Limits on how much of a message a quoted reply carries
+const (
+	maxQuoteLines = 4
+	maxQuoteRunes = 280
+)
+
+// QuoteReply starts a reply to message by putting a quoted excerpt above
+// whatever is already in the composer. The quote travels inline with the
+// next prompt, so every provider sees it. Quotes span several lines, so
+// single-line input switches to multi-line.
+func (ei *EnhancedInput) QuoteReply(message api.Message) {
+	if ei.inputType != InputTypeMultiline {
+		ei.ToggleMode()
+	}
+
+	value := quoteExcerpt(message) + "\n\n" + strings.TrimLeft(ei.Value(), "\n")
+	ei.SetValue(value)
+	ei.Focus()
+}
+
+// quoteExcerpt renders the start of a message as a Markdown block quote,
+// skipping blank lines and code fences and marking any truncation
+func quoteExcerpt(message api.Message) string {
+	author := "You"
+	if message.Role != "user" {
+		author = strings.Title(message.Role)
+	}
+
+	var lines []string
+	length := 0
+	truncated := false
+	for _, line := range strings.Split(message.Content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "```") {
+			continue
+		}
+		if len(lines) == maxQuoteLines || length >= maxQuoteRunes {
+			truncated = true
+			break
+		}
+
+		if remaining := maxQuoteRunes - length; utf8.RuneCountInString(line) > remaining {
+			line = strings.TrimSpace(string([]rune(line)[:remaining]))
+			truncated = true
+		}
+		length += utf8.RuneCountInString(line)
+		lines = append(lines, "> "+line)
+	}
+	if truncated && len(lines) > 0 {
+		lines[len(lines)-1] += " …"
+	}
+
+	return "> **" + author + " wrote:**\n" + strings.Join(lines, "\n")
+}
+
 // ToggleMode toggles between single-line and multi-line input
 func (ei *EnhancedInput) ToggleMode() {
 	currentValue := ei.Value()
@@ -328,21 +889,19 @@ func (ei *EnhancedInput) ToggleMode() {
 	if ei.inputType == InputTypeMultiline {
 		ei.inputType = InputTypeText
 		ei.textInput = textinput.New()
-		ei.textInput.Width = ei.width - 4
 		ei.textInput.SetValue(currentValue)
 		ei.textInput.Focus()
-		ei.textInput.Placeholder = "Type your message or /command..."
 	} else {
 		ei.inputType = InputTypeMultiline
 		ei.textArea = textarea.New()
-		ei.textArea.SetWidth(ei.width - 4)
-		ei.textArea.SetHeight(ei.height - 2)
 		ei.textArea.SetValue(currentValue)
 		ei.textArea.Focus()
-		ei.textArea.Placeholder = "Type your message (Ctrl+Enter to send)..."
 	}
 
+	ei.sizeField()
+	ei.applySubmitKey()
 	ei.updateTokenEstimate()
+	ei.validateInput()
 }
 
 // AddToHistory adds a value to input history
@@ -396,6 +955,18 @@ func (ei *EnhancedInput) navigateHistory(direction int) {
 func (ei *EnhancedInput) updateSuggestions() {
 	value := ei.Value()
 
+	// A shortcode being typed takes over the popup
+	ei.emojiQuery = ""
+	if query := emojiQuery(string([]rune(value)[:ei.cursorOffset()])); query != "" {
+		if matches := EmojiSuggestions(query); len(matches) > 0 {
+			ei.emojiQuery = query
+			ei.emojiMatches = matches
+			ei.showSuggestions = true
+			ei.selectedSuggestion = 0
+			return
+		}
+	}
+
 	if !strings.HasPrefix(value, ei.commandPrefix) {
 		ei.showSuggestions = false
 		return
@@ -425,16 +996,50 @@ func (ei *EnhancedInput) updateSuggestions() {
 	}
 }
 
+// activeSuggestions returns the entries shown in the suggestion popup
+func (ei *EnhancedInput) activeSuggestions() []CommandSuggestion {
+	if ei.emojiQuery != "" {
+		return ei.emojiMatches
+	}
+	return ei.suggestions
+}
+
+// emojiText is what accepting an emoji suggestion inserts: the emoji itself,
+// or the full shortcode on terminals that can't display it
+func (ei *EnhancedInput) emojiText(suggestion CommandSuggestion) string {
+	if ei.emojiSupported {
+		return suggestion.Description
+	}
+	return suggestion.Command
+}
+
+// insertEmoji replaces the partial shortcode before the cursor
+func (ei *EnhancedInput) insertEmoji(suggestion CommandSuggestion) {
+	runes := []rune(ei.Value())
+	cursor := ei.cursorOffset()
+	start := cursor - len([]rune(ei.emojiQuery)) - 1 // include the colon
+
+	insert := []rune(ei.emojiText(suggestion))
+	value := string(runes[:start]) + string(insert) + string(runes[cursor:])
+
+	ei.setValueAndCursor(value, start+len(insert))
+	ei.emojiQuery = ""
+	ei.showSuggestions = false
+	ei.updateTokenEstimate()
+	ei.validateInput()
+}
+
 // navigateSuggestions navigates through command suggestions
 func (ei *EnhancedInput) navigateSuggestions(direction int) {
-	if len(ei.suggestions) == 0 {
+	suggestions := ei.activeSuggestions()
+	if len(suggestions) == 0 {
 		return
 	}
 
 	newIndex := ei.selectedSuggestion + direction
 	if newIndex < 0 {
-		newIndex = len(ei.suggestions) - 1
-	} else if newIndex >= len(ei.suggestions) {
+		newIndex = len(suggestions) - 1
+	} else if newIndex >= len(suggestions) {
 		newIndex = 0
 	}
 
@@ -443,11 +1048,17 @@ func (ei *EnhancedInput) navigateSuggestions(direction int) {
 
 // acceptSuggestion accepts the currently selected suggestion
 func (ei *EnhancedInput) acceptSuggestion() {
-	if !ei.showSuggestions || len(ei.suggestions) == 0 {
+	suggestions := ei.activeSuggestions()
+	if !ei.showSuggestions || len(suggestions) == 0 {
+		return
+	}
+
+	if ei.emojiQuery != "" {
+		ei.insertEmoji(suggestions[ei.selectedSuggestion])
 		return
 	}
 
-	suggestion := ei.suggestions[ei.selectedSuggestion]
+	suggestion := suggestions[ei.selectedSuggestion]
 	ei.SetValue(ei.commandPrefix + suggestion.Command + " ")
 	ei.showSuggestions = false
 }
@@ -460,18 +1071,34 @@ func (ei *EnhancedInput) updateTokenEstimate() {
 
 // validateInput validates the current input
 func (ei *EnhancedInput) validateInput() {
-	if ei.validator == nil {
+	validate := ComposeValidators(ei.validator, ei.validators.For(ei.inputType))
+	value := ei.Value()
+	// Nothing typed yet isn't an error
+	if validate == nil || value == "" {
 		ei.errorMessage = ""
 		return
 	}
 
-	if err := ei.validator(ei.Value()); err != nil {
+	if err := validate(value); err != nil {
 		ei.errorMessage = err.Error()
 	} else {
 		ei.errorMessage = ""
 	}
 }
 
+// SetValidator sets a validator applied on top of those registered for the
+// input type; compose several with ComposeValidators
+func (ei *EnhancedInput) SetValidator(validator Validator) {
+	ei.validator = validator
+	ei.validateInput()
+}
+
+// SetValidatorRegistry sets the validators applied per input type
+func (ei *EnhancedInput) SetValidatorRegistry(registry *ValidatorRegistry) {
+	ei.validators = registry
+	ei.validateInput()
+}
+
 // Clipboard operations
 func (ei *EnhancedInput) pasteFromClipboard() tea.Cmd {
 	return func() tea.Msg {
@@ -520,17 +1147,23 @@ func (ei *EnhancedInput) submitValue(value string) tea.Cmd {
 
 // renderSuggestions renders the command suggestions list
 func (ei *EnhancedInput) renderSuggestions() string {
-	if len(ei.suggestions) == 0 {
+	suggestions := ei.activeSuggestions()
+	if len(suggestions) == 0 {
 		return ""
 	}
 
+	header := "Commands:"
+	if ei.emojiQuery != "" {
+		header = "Emoji:"
+	}
+
 	var content strings.Builder
-	content.WriteString(SuggestionsHeaderStyle.Render("Commands:"))
+	content.WriteString(SuggestionsHeaderStyle.Render(header))
 	content.WriteString("\n")
 
 	maxVisible := 5
 	start := 0
-	end := len(ei.suggestions)
+	end := len(suggestions)
 
 	if end > maxVisible {
 		// Center the selection
@@ -539,14 +1172,14 @@ func (ei *EnhancedInput) renderSuggestions() string {
 			start = 0
 		}
 		end = start + maxVisible
-		if end > len(ei.suggestions) {
-			end = len(ei.suggestions)
+		if end > len(suggestions) {
+			end = len(suggestions)
 			start = end - maxVisible
 		}
 	}
 
 	for i := start; i < end; i++ {
-		suggestion := ei.suggestions[i]
+		suggestion := suggestions[i]
 		prefix := "  "
 		style := SuggestionStyle
 
@@ -560,6 +1193,9 @@ func (ei *EnhancedInput) renderSuggestions() string {
 			ei.commandPrefix,
 			suggestion.Command,
 			suggestion.Description)
+		if ei.emojiQuery != "" {
+			line = fmt.Sprintf("%s%s %s", prefix, suggestion.Command, ei.emojiText(suggestion))
+		}
 
 		content.WriteString(style.Render(line))
 		content.WriteString("\n")
@@ -593,6 +1229,11 @@ func (ei *EnhancedInput) renderFooter() string {
 		}
 	}
 
+	// Correction for the words underlined in the text
+	if len(ei.typos) > 0 {
+		parts = append(parts, ei.typoHint())
+	}
+
 	// Token estimate
 	if ei.showTokenCount {
 		tokenText := fmt.Sprintf("~%d tokens", ei.tokenEstimate)
@@ -613,6 +1254,17 @@ func (ei *EnhancedInput) renderFooter() string {
 	}
 	parts = append(parts, ModeIndicatorStyle.Render(modeText))
 
+	// Vim editing mode, with the command line while typing one
+	if ei.vim != nil {
+		vimText := ei.vim.mode.String()
+		if ei.vim.mode == VimCommand {
+			vimText = ":" + ei.vim.command
+		} else if ei.vim.pending != "" {
+			vimText += " " + ei.vim.pending
+		}
+		parts = append(parts, ModeIndicatorStyle.Render(vimText))
+	}
+
 	if len(parts) == 0 {
 		return ""
 	}
@@ -627,14 +1279,27 @@ func getDefaultCommands() []CommandSuggestion {
 		{Command: "model", Description: "Switch AI model", Usage: "/model [model-name]"},
 		{Command: "models", Description: "List available models", Usage: "/models"},
 		{Command: "clear", Description: "Clear chat history", Usage: "/clear"},
+		{Command: "focus", Description: "Toggle focus mode", Usage: "/focus"},
+		{Command: "theme", Description: "Switch color theme", Usage: "/theme [name]"},
 		{Command: "history", Description: "View chat history", Usage: "/history"},
 		{Command: "settings", Description: "Open settings", Usage: "/settings"},
-		{Command: "export", Description: "Export chat history", Usage: "/export [format]"},
+		{Command: "export", Description: "Export chat history", Usage: "/export [json|txt|md|html|pdf] [file]"},
+		{Command: "export-all", Description: "Export every session to a directory", Usage: "/export-all [format] [dir] [--zip]"},
+		{Command: "import", Description: "Import ChatGPT or Claude exports", Usage: "/import <file>"},
+		{Command: "share", Description: "Share as a secret gist or Markdown file", Usage: "/share [title]"},
+		{Command: "compare", Description: "Compare answers from several models", Usage: "/compare <model-a,model-b> [prompt]"},
+		{Command: "brief", Description: "Limit the next reply's length", Usage: "/brief [message]"},
+		{Command: "continue", Description: "Continue a truncated reply", Usage: "/continue"},
+		{Command: "nocache", Description: "Send without using the response cache", Usage: "/nocache <message>"},
+		{Command: "cache", Description: "Show or clear the response cache", Usage: "/cache [clear]"},
 		{Command: "quit", Description: "Quit application", Usage: "/quit"},
 		{Command: "save", Description: "Save current chat", Usage: "/save [name]"},
 		{Command: "load", Description: "Load saved chat", Usage: "/load [name]"},
 		{Command: "search", Description: "Search chat history", Usage: "/search [query]"},
 		{Command: "stats", Description: "Show usage statistics", Usage: "/stats"},
+		{Command: "notifications", Description: "Show notification log", Usage: "/notifications [clear]"},
+		{Command: "system", Description: "Set the session system prompt", Usage: "/system [prompt|clear]"},
+		{Command: "params", Description: "Show or set model parameters", Usage: "/params [temperature|max_tokens <value>|reset]"},
 		{Command: "theme", Description: "Change theme", Usage: "/theme [theme-name]"},
 		{Command: "debug", Description: "Toggle debug mode", Usage: "/debug"},
 		{Command: "version", Description: "Show version info", Usage: "/version"},
@@ -737,6 +1402,13 @@ var (
 	ModeIndicatorStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color("#059669")).
 				Bold(true)
+
+	// Typo highlighting styles
+	TypoHintStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#6B7280"))
+
+	PasteNoticeStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#F59E0B"))
 )
 
 // Helper functions for integration with app state
@@ -778,7 +1450,19 @@ func (ei *EnhancedInput) UpdateFromState(state *app.ChatState) {
 		ei.inputType = targetType
 		// Re-initialize with new type (simplified)
 		currentValue := ei.Value()
+		previous := *ei
 		*ei = *NewEnhancedInput(targetType, ei.width, ei.height)
+		ei.keys = previous.keys
+		ei.draftsEnabled = previous.draftsEnabled
+		ei.draftSession = previous.draftSession
+		ei.draftSeq = previous.draftSeq
+		ei.vim = previous.vim
+		ei.typoChecker = previous.typoChecker
+		ei.styledUnderline = previous.styledUnderline
+		ei.emojiSupported = previous.emojiSupported
+		ei.validator = previous.validator
+		ei.validators = previous.validators
+		ei.SetSubmitKey(previous.submitKey)
 		ei.SetValue(currentValue)
 	}
 
@@ -0,0 +1,313 @@
+package components
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/john/klip/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// altEnter is Enter pressed with Alt, which terminals report distinctly
+var altEnter = tea.KeyMsg{Type: tea.KeyEnter, Alt: true}
+
+func TestSubmitKeyDefaultsToEnter(t *testing.T) {
+	ei := NewEnhancedInput(InputTypeText, 80, 3)
+	ei.SetValue("hello")
+
+	ei, _ = ei.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, []string{"hello"}, ei.history)
+
+	ml := NewEnhancedInput(InputTypeMultiline, 80, 6)
+	ml.SetValue("hello")
+
+	ml, _ = ml.Update(altEnter)
+	assert.Empty(t, ml.history, "alt+enter should insert a newline when enter sends")
+	assert.Equal(t, "hello\n", ml.Value())
+
+	ml, _ = ml.Update(tea.KeyMsg{Type: tea.KeyCtrlJ})
+	assert.Empty(t, ml.history, "ctrl+j should insert a newline when enter sends")
+	assert.Equal(t, "hello\n\n", ml.Value())
+
+	ml, _ = ml.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, []string{"hello\n\n"}, ml.history)
+}
+
+func TestSubmitKeyAltEnter(t *testing.T) {
+	ml := NewEnhancedInput(InputTypeMultiline, 80, 6)
+	ml.SetSubmitKey("alt+enter")
+	ml.SetValue("hello")
+	assert.Contains(t, ml.textArea.Placeholder, "Alt+Enter to send")
+
+	ml, _ = ml.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Empty(t, ml.history)
+	assert.Equal(t, "hello\n", ml.Value())
+
+	ml, _ = ml.Update(altEnter)
+	assert.Equal(t, []string{"hello\n"}, ml.history)
+
+	// The setting carries over when switching modes, where Enter still sends
+	ml.ToggleMode()
+	ml.SetValue("single")
+	ml, _ = ml.Update(altEnter)
+	assert.Equal(t, "single", ml.history[0])
+
+	ml.SetValue("again")
+	ml, _ = ml.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, "again", ml.history[0])
+}
+
+func TestDraftAutosaveRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	ei := NewEnhancedInput(InputTypeText, 80, 3)
+	ei.SetDraftsEnabled(true)
+	ei.SetDraftSession("session-1")
+
+	ei, _ = ei.Update(runeKey('h'))
+	ei, _ = ei.Update(runeKey('i'))
+
+	// Only the save scheduled by the latest keystroke writes
+	_, cmd := ei.Update(draftSaveMsg{seq: ei.draftSeq - 1})
+	assert.Nil(t, cmd)
+	_, cmd = ei.Update(draftSaveMsg{seq: ei.draftSeq})
+	if assert.NotNil(t, cmd) {
+		cmd()
+	}
+
+	restored := NewEnhancedInput(InputTypeText, 80, 3)
+	restored.SetDraftSession("session-1")
+	restored.SetDraftsEnabled(true)
+	assert.Equal(t, "hi", restored.Value())
+
+	other := NewEnhancedInput(InputTypeText, 80, 3)
+	other.SetDraftsEnabled(true)
+	other.SetDraftSession("session-2")
+	assert.Empty(t, other.Value(), "another conversation should not restore the draft")
+
+	// Sending clears the draft
+	_, cmd = restored.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if assert.NotNil(t, cmd) {
+		for _, c := range cmd().(tea.BatchMsg) {
+			c()
+		}
+	}
+
+	fresh := NewEnhancedInput(InputTypeText, 80, 3)
+	fresh.SetDraftSession("session-1")
+	fresh.SetDraftsEnabled(true)
+	assert.Empty(t, fresh.Value())
+}
+
+func TestTypoCheckSkipsCodeAndURLs(t *testing.T) {
+	sc := NewTypoChecker([]string{"wich"})
+
+	found := sc.Check("I recieve `recieve` and https://example.com/recieve Teh end, wich is fine")
+	if assert.Len(t, found, 2) {
+		assert.Equal(t, Typo{Word: "recieve", Suggestion: "receive", Start: 2, End: 9}, found[0])
+		assert.Equal(t, "Teh", found[1].Word)
+		assert.Equal(t, "The", found[1].Suggestion)
+	}
+
+	assert.Empty(t, sc.Check("```\nrecieve\n```"))
+}
+
+func TestTypoCheckRunsAsync(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	ei := NewEnhancedInput(InputTypeText, 80, 3)
+	ei.SetTypoHighlighting(true)
+
+	ei.SetValue("definately")
+	cmd := ei.scheduleTypoCheck()
+	assert.Empty(t, ei.Typos(), "checking happens in the returned command")
+
+	ei, _ = ei.Update(cmd())
+	assert.Len(t, ei.Typos(), 1)
+	assert.Contains(t, ei.View(), "definitely")
+
+	msg := ei.AddToDictionary("")()
+	assert.Equal(t, InputMsg{Type: "dictionary_added", Data: "definately"}, msg)
+	assert.Empty(t, ei.Typos())
+
+	reloaded := NewEnhancedInput(InputTypeText, 80, 3)
+	reloaded.SetValue("definately")
+	reloaded.SetTypoHighlighting(true)
+	assert.Empty(t, reloaded.Typos(), "the personal dictionary is persisted")
+}
+
+func TestTyposUnderlinedInPlace(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	ei := NewEnhancedInput(InputTypeText, 80, 3)
+	ei.SetTypoHighlighting(true)
+	ei.SetValue("`teh` is teh word")
+	ei, _ = ei.Update(ei.scheduleTypoCheck()())
+	require.Len(t, ei.Typos(), 1)
+
+	// The second "teh" is underlined where it is typed, not the one in code
+	view := ei.View()
+	assert.Contains(t, view, "is \x1b[4mteh\x1b[24m word")
+	assert.Contains(t, view, "`teh`")
+	assert.NotContains(t, view, "Typo:")
+	assert.Contains(t, view, "teh → the")
+
+	// Styling inside the word, such as the cursor, keeps the underline going
+	rendered := "a w\x1b[7mi\x1b[0mch b"
+	assert.Equal(t, "a \x1b[4mw\x1b[7m\x1b[4mi\x1b[0m\x1b[4mch\x1b[24m b",
+		underlineSpans(rendered, "a wich b", []Typo{{Word: "wich", Start: 2, End: 6}}, "\x1b[4m", "\x1b[24m"))
+
+	// Stale results for text that has since changed are skipped
+	assert.Equal(t, "a b", underlineSpans("a b", "a b", []Typo{{Word: "wich", Start: 2, End: 6}}, "\x1b[4m", "\x1b[24m"))
+}
+
+func TestTypoCheckAcceptsValidVariants(t *testing.T) {
+	sc := NewTypoChecker(nil)
+	assert.Empty(t, sc.Check("The dependant was listed on the calender"))
+}
+
+func typeText(ei *EnhancedInput, text string) *EnhancedInput {
+	for _, r := range text {
+		ei, _ = ei.Update(runeKey(r))
+	}
+	return ei
+}
+
+func TestEmojiShortcodeCompletion(t *testing.T) {
+	ei := typeText(NewEnhancedInput(InputTypeText, 80, 3), "love :heart")
+
+	assert.True(t, ei.showSuggestions)
+	if assert.NotEmpty(t, ei.activeSuggestions()) {
+		assert.Equal(t, ":heart:", ei.activeSuggestions()[0].Command)
+	}
+	assert.Contains(t, ei.View(), "Emoji:")
+
+	ei, _ = ei.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, "love ❤️", ei.Value())
+	assert.Empty(t, ei.history, "enter accepts the emoji instead of sending")
+	assert.False(t, ei.showSuggestions)
+
+	plain := NewEnhancedInput(InputTypeText, 80, 3)
+	plain.emojiSupported = false
+	plain = typeText(plain, ":tada")
+	plain, _ = plain.Update(tea.KeyMsg{Type: tea.KeyTab})
+	assert.Equal(t, ":tada:", plain.Value())
+}
+
+func TestEmojiQueryNeedsWordStart(t *testing.T) {
+	assert.Equal(t, "smi", emojiQuery("hi :smi"))
+	assert.Empty(t, emojiQuery("at 10:30"))
+	assert.Empty(t, emojiQuery("http://sm"))
+	assert.Empty(t, emojiQuery(":s"))
+}
+
+func paste(text string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text), Paste: true}
+}
+
+func TestBracketedPasteIsVerbatim(t *testing.T) {
+	ei := NewEnhancedInput(InputTypeText, 80, 3)
+
+	ei, cmd := ei.Update(paste("/clear\n"))
+	assert.Equal(t, "/clear", ei.Value())
+	assert.Empty(t, ei.history, "a pasted newline must not submit")
+	assert.False(t, ei.showSuggestions, "pasted text is not a command being typed")
+	if cmd != nil {
+		if msg, ok := cmd().(InputMsg); ok {
+			assert.NotEqual(t, "clear", msg.Type)
+		}
+	}
+
+	ml := NewEnhancedInput(InputTypeMultiline, 80, 6)
+	ml, _ = ml.Update(paste("/clear\nsecond line"))
+	assert.Equal(t, "/clear\nsecond line", ml.Value())
+	assert.Empty(t, ml.history)
+}
+
+func TestMultilinePasteInSingleLineMode(t *testing.T) {
+	ei := NewEnhancedInput(InputTypeText, 80, 6)
+	ei, _ = ei.Update(paste("one\r\ntwo"))
+	assert.Empty(t, ei.Value())
+	assert.Contains(t, ei.View(), "Pasted 2 lines")
+
+	ei, _ = ei.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, InputTypeMultiline, ei.inputType)
+	assert.Equal(t, "one\ntwo", ei.Value())
+	assert.Empty(t, ei.history)
+
+	joined := NewEnhancedInput(InputTypeText, 80, 6)
+	joined, _ = joined.Update(paste("one\ntwo"))
+	joined, _ = joined.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, InputTypeText, joined.inputType)
+	assert.Equal(t, "one two", joined.Value())
+}
+
+func TestReplySeedsComposerWithQuote(t *testing.T) {
+	cv := NewChatView(80, 20)
+	cv.AddMessage(api.Message{Role: "user", Content: "How do I reverse a slice?"})
+	cv.AddMessage(api.Message{Role: "assistant", Content: "Use slices.Reverse:\n\n```go\nslices.Reverse(s)\n```\n\n" + strings.Repeat("It works in place. ", 20)})
+
+	cv.showContextMenu(1)
+	for i, item := range cv.contextMenu.items {
+		if item.Action == "reply" {
+			cv.contextMenu.selected = i
+		}
+	}
+	cmd := cv.executeContextAction()
+	require.NotNil(t, cmd)
+
+	ei := NewEnhancedInput(InputTypeText, 80, 6)
+	ei.SetValue("And strings?")
+	ei, _ = ei.Update(cmd())
+
+	assert.Equal(t, InputTypeMultiline, ei.inputType)
+	lines := strings.Split(ei.Value(), "\n")
+	assert.Equal(t, "> **Assistant wrote:**", lines[0])
+	assert.Equal(t, "> Use slices.Reverse:", lines[1])
+	assert.Equal(t, "> slices.Reverse(s)", lines[2])
+	assert.True(t, strings.HasSuffix(lines[3], " …"), "long replies are truncated: %q", lines[3])
+	assert.Equal(t, []string{"", "And strings?"}, lines[4:])
+}
+
+func TestMaxLengthValidatorShowsFooterError(t *testing.T) {
+	ei := NewEnhancedInput(InputTypeText, 80, 3)
+	ei.SetValidator(ComposeValidators(NonEmpty(), MaxLength(10)))
+
+	ei.SetValue("0123456789")
+	assert.NotContains(t, ei.renderFooter(), "✗")
+
+	ei.SetValue("0123456789ab")
+	assert.Contains(t, ei.renderFooter(), "✗ 2 characters over the 10 limit")
+
+	// Invalid input isn't submitted
+	ei, cmd := ei.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Empty(t, ei.history)
+	if cmd != nil {
+		_, submitted := cmd().(InputMsg)
+		assert.False(t, submitted)
+	}
+
+	ei.SetValue("   ")
+	assert.Contains(t, ei.renderFooter(), "✗ input is empty")
+}
+
+func TestValidatorRegistryAppliesPerInputType(t *testing.T) {
+	registry := DefaultValidatorRegistry()
+	secret := "sk-ant-" + strings.Repeat("a", 30)
+
+	assert.Error(t, registry.For(InputTypeText)("my key is "+secret))
+	assert.Error(t, registry.For(InputTypeSearch)(strings.Repeat("q", maxSearchLength+1)))
+	assert.NoError(t, registry.For(InputTypeSearch)("find "+secret))
+	assert.Nil(t, registry.For(InputTypeCommand))
+
+	ei := NewEnhancedInput(InputTypeText, 80, 3)
+	ei.SetValidatorRegistry(registry)
+	ei.SetValue("my key is " + secret)
+	assert.Contains(t, ei.renderFooter(), "API key")
+
+	// Switching modes keeps the registry and revalidates
+	ei.ToggleMode()
+	assert.Contains(t, ei.renderFooter(), "API key")
+}
@@ -0,0 +1,451 @@
+package components
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/john/klip/internal/storage"
+)
+
+// ChatKeyMap defines the keybindings for the chat view
+type ChatKeyMap struct {
+	Down               key.Binding
+	Up                 key.Binding
+	HalfPageDown       key.Binding
+	HalfPageUp         key.Binding
+	Top                key.Binding
+	Bottom             key.Binding
+	GotoMessage        key.Binding
+	ToggleTimestamp    key.Binding
+	ToggleRelativeTime key.Binding
+	ToggleLineNumbers  key.Binding
+	ToggleWordWrap     key.Binding
+	ToggleSystemPrompt key.Binding
+	ToggleSidebar      key.Binding
+	Copy               key.Binding
+	CopyAll            key.Binding
+	CopyReplies        key.Binding
+	Actions            key.Binding
+	Select             key.Binding
+	Search             key.Binding
+	NextResult         key.Binding
+	PrevResult         key.Binding
+	Help               key.Binding
+}
+
+// DefaultChatKeyMap returns the default chat keybindings
+func DefaultChatKeyMap() ChatKeyMap {
+	return ChatKeyMap{
+		Down:               key.NewBinding(key.WithKeys("j", "down"), key.WithHelp("j/↓", "scroll down")),
+		Up:                 key.NewBinding(key.WithKeys("k", "up"), key.WithHelp("k/↑", "scroll up")),
+		HalfPageDown:       key.NewBinding(key.WithKeys("d", "pgdown"), key.WithHelp("d", "half page down")),
+		HalfPageUp:         key.NewBinding(key.WithKeys("u", "pgup"), key.WithHelp("u", "half page up")),
+		Top:                key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "go to top")),
+		Bottom:             key.NewBinding(key.WithKeys("G"), key.WithHelp("G", "go to bottom")),
+		GotoMessage:        key.NewBinding(key.WithKeys(":"), key.WithHelp(":N", "go to message")),
+		ToggleTimestamp:    key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "toggle timestamps")),
+		ToggleRelativeTime: key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "relative/absolute time")),
+		ToggleLineNumbers:  key.NewBinding(key.WithKeys("l"), key.WithHelp("l", "toggle line numbers")),
+		ToggleWordWrap:     key.NewBinding(key.WithKeys("w"), key.WithHelp("w", "toggle word wrap")),
+		ToggleSystemPrompt: key.NewBinding(key.WithKeys("S"), key.WithHelp("S", "toggle system prompt")),
+		ToggleSidebar:      key.NewBinding(key.WithKeys("ctrl+b"), key.WithHelp("ctrl+b", "toggle sidebar")),
+		Copy:               key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "copy message")),
+		CopyAll:            key.NewBinding(key.WithKeys("Y"), key.WithHelp("Y", "copy all")),
+		CopyReplies:        key.NewBinding(key.WithKeys("A"), key.WithHelp("A", "copy replies")),
+		Actions:            key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "message actions")),
+		Select:             key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "select message")),
+		Search:             key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "search")),
+		NextResult:         key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "next result")),
+		PrevResult:         key.NewBinding(key.WithKeys("N"), key.WithHelp("N", "previous result")),
+		Help:               key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "keybindings")),
+	}
+}
+
+// ShortHelp returns the most common chat keybindings
+func (km ChatKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{km.Down, km.Up, km.Copy, km.Search, km.Help}
+}
+
+// FullHelp returns all chat keybindings grouped into columns
+func (km ChatKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{km.Down, km.Up, km.HalfPageDown, km.HalfPageUp, km.Top, km.Bottom, km.GotoMessage},
+		{km.ToggleTimestamp, km.ToggleRelativeTime, km.ToggleLineNumbers, km.ToggleWordWrap, km.ToggleSystemPrompt, km.ToggleSidebar},
+		{km.Copy, km.CopyAll, km.CopyReplies, km.Actions, km.Select},
+		{km.Search, km.NextResult, km.PrevResult, km.Help},
+	}
+}
+
+// HistoryKeyMap defines the keybindings for the history browser
+type HistoryKeyMap struct {
+	Search      key.Binding
+	Back        key.Binding
+	Open        key.Binding
+	ListView    key.Binding
+	TableView   key.Binding
+	Preview     key.Binding
+	ExportView  key.Binding
+	SessionInfo key.Binding
+	Delete      key.Binding
+	Export      key.Binding
+	Refresh     key.Binding
+	Sort        key.Binding
+	ExportAll   key.Binding
+	Help        key.Binding
+}
+
+// DefaultHistoryKeyMap returns the default history browser keybindings
+func DefaultHistoryKeyMap() HistoryKeyMap {
+	return HistoryKeyMap{
+		Search:      key.NewBinding(key.WithKeys("ctrl+f", "/"), key.WithHelp("/", "search")),
+		Back:        key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
+		Open:        key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "preview")),
+		ListView:    key.NewBinding(key.WithKeys("1"), key.WithHelp("1", "list")),
+		TableView:   key.NewBinding(key.WithKeys("2"), key.WithHelp("2", "table")),
+		Preview:     key.NewBinding(key.WithKeys("3"), key.WithHelp("3", "preview")),
+		ExportView:  key.NewBinding(key.WithKeys("4"), key.WithHelp("4", "export view")),
+		SessionInfo: key.NewBinding(key.WithKeys("i", "5"), key.WithHelp("i", "session info")),
+		Delete:      key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete")),
+		Export:      key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "export")),
+		Refresh:     key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "refresh")),
+		Sort:        key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "sort")),
+		ExportAll:   key.NewBinding(key.WithKeys("ctrl+a"), key.WithHelp("ctrl+a", "export all")),
+		Help:        key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "keybindings")),
+	}
+}
+
+// ShortHelp returns the most common history keybindings
+func (km HistoryKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{km.Open, km.Delete, km.Export, km.Search, km.Sort, km.Refresh}
+}
+
+// FullHelp returns all history keybindings grouped into columns
+func (km HistoryKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{km.ListView, km.TableView, km.Preview, km.ExportView, km.SessionInfo},
+		{km.Open, km.Back, km.Search, km.Sort},
+		{km.Delete, km.Export, km.ExportAll, km.Refresh, km.Help},
+	}
+}
+
+// SettingsKeyMap defines the keybindings for the settings form
+type SettingsKeyMap struct {
+	Save           key.Binding
+	Reset          key.Binding
+	Undo           key.Binding
+	NextSection    key.Binding
+	PrevSection    key.Binding
+	General        key.Binding
+	Providers      key.Binding
+	Display        key.Binding
+	Advanced       key.Binding
+	About          key.Binding
+	JumpSections   key.Binding
+	Search         key.Binding
+	TestConnection key.Binding
+	ColorPreview   key.Binding
+}
+
+// DefaultSettingsKeyMap returns the default settings keybindings
+func DefaultSettingsKeyMap() SettingsKeyMap {
+	return SettingsKeyMap{
+		Save:           key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("ctrl+s", "save")),
+		Reset:          key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("ctrl+r", "reset")),
+		Undo:           key.NewBinding(key.WithKeys("ctrl+z"), key.WithHelp("ctrl+z", "discard changes")),
+		NextSection:    key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "next section")),
+		PrevSection:    key.NewBinding(key.WithKeys("shift+tab"), key.WithHelp("shift+tab", "previous section")),
+		General:        key.NewBinding(key.WithKeys("f1"), key.WithHelp("f1", "general")),
+		Providers:      key.NewBinding(key.WithKeys("f2"), key.WithHelp("f2", "providers")),
+		Display:        key.NewBinding(key.WithKeys("f3"), key.WithHelp("f3", "display")),
+		Advanced:       key.NewBinding(key.WithKeys("f4"), key.WithHelp("f4", "advanced")),
+		About:          key.NewBinding(key.WithKeys("f5"), key.WithHelp("f5", "about")),
+		JumpSections:   key.NewBinding(key.WithKeys("f1", "f2", "f3", "f4", "f5"), key.WithHelp("f1-f5", "jump to section")),
+		Search:         key.NewBinding(key.WithKeys("ctrl+f"), key.WithHelp("ctrl+f", "search settings")),
+		TestConnection: key.NewBinding(key.WithKeys("ctrl+t"), key.WithHelp("ctrl+t", "test connection")),
+		ColorPreview:   key.NewBinding(key.WithKeys("ctrl+p"), key.WithHelp("ctrl+p", "color vision preview")),
+	}
+}
+
+// ShortHelp returns the most common settings keybindings
+func (km SettingsKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{km.Save, km.Reset, km.NextSection, km.JumpSections, km.Search}
+}
+
+// FullHelp returns all settings keybindings grouped into columns
+func (km SettingsKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{km.Save, km.Reset, km.Undo, km.TestConnection, km.ColorPreview},
+		{km.NextSection, km.PrevSection, km.Search},
+		{km.General, km.Providers, km.Display, km.Advanced, km.About},
+	}
+}
+
+// InputKeyMap defines the keybindings for the enhanced input
+type InputKeyMap struct {
+	Submit           key.Binding
+	SubmitMultiline  key.Binding
+	Paste            key.Binding
+	Cut              key.Binding
+	Undo             key.Binding
+	AcceptSuggestion key.Binding
+	Dismiss          key.Binding
+	HistoryPrev      key.Binding
+	HistoryNext      key.Binding
+	AddToDictionary  key.Binding
+}
+
+// DefaultInputKeyMap returns the default input keybindings
+func DefaultInputKeyMap() InputKeyMap {
+	return InputKeyMap{
+		Submit:           key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "send")),
+		SubmitMultiline:  key.NewBinding(key.WithKeys("alt+enter"), key.WithHelp("alt+enter", "send (multiline)")),
+		Paste:            key.NewBinding(key.WithKeys("ctrl+v"), key.WithHelp("ctrl+v", "paste")),
+		Cut:              key.NewBinding(key.WithKeys("ctrl+x"), key.WithHelp("ctrl+x", "cut")),
+		Undo:             key.NewBinding(key.WithKeys("ctrl+z"), key.WithHelp("ctrl+z", "undo")),
+		AcceptSuggestion: key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "accept suggestion")),
+		Dismiss:          key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "dismiss suggestions")),
+		HistoryPrev:      key.NewBinding(key.WithKeys("up"), key.WithHelp("↑", "previous input")),
+		HistoryNext:      key.NewBinding(key.WithKeys("down"), key.WithHelp("↓", "next input")),
+		AddToDictionary:  key.NewBinding(key.WithKeys("ctrl+o"), key.WithHelp("ctrl+o", "add word to dictionary")),
+	}
+}
+
+// ShortHelp returns the most common input keybindings
+func (km InputKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{km.Submit, km.AcceptSuggestion, km.HistoryPrev, km.HistoryNext}
+}
+
+// FullHelp returns all input keybindings grouped into columns
+func (km InputKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{km.Submit, km.SubmitMultiline, km.AcceptSuggestion, km.Dismiss},
+		{km.HistoryPrev, km.HistoryNext},
+		{km.Paste, km.Cut, km.Undo, km.AddToDictionary},
+	}
+}
+
+// SidebarKeyMap defines the keybindings for the sidebar
+type SidebarKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Select key.Binding
+}
+
+// DefaultSidebarKeyMap returns the default sidebar keybindings
+func DefaultSidebarKeyMap() SidebarKeyMap {
+	return SidebarKeyMap{
+		Up:     key.NewBinding(key.WithKeys("k", "up"), key.WithHelp("k/↑", "previous item")),
+		Down:   key.NewBinding(key.WithKeys("j", "down"), key.WithHelp("j/↓", "next item")),
+		Select: key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "open")),
+	}
+}
+
+// ShortHelp returns the sidebar keybindings
+func (km SidebarKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{km.Up, km.Down, km.Select}
+}
+
+// FullHelp returns the sidebar keybindings in a single column
+func (km SidebarKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{km.ShortHelp()}
+}
+
+// KeyMaps groups the keybindings of every component
+type KeyMaps struct {
+	Chat     ChatKeyMap
+	History  HistoryKeyMap
+	Input    InputKeyMap
+	Settings SettingsKeyMap
+	Sidebar  SidebarKeyMap
+}
+
+// DefaultKeyMaps returns the default keybindings for all components
+func DefaultKeyMaps() KeyMaps {
+	return KeyMaps{
+		Chat:     DefaultChatKeyMap(),
+		History:  DefaultHistoryKeyMap(),
+		Input:    DefaultInputKeyMap(),
+		Settings: DefaultSettingsKeyMap(),
+		Sidebar:  DefaultSidebarKeyMap(),
+	}
+}
+
+// LoadKeyMaps loads keybinding overrides from keys.json on top of the
+// defaults. On error the defaults are returned alongside the error.
+func LoadKeyMaps() (KeyMaps, error) {
+	bindings, err := storage.LoadKeyBindings()
+	if err != nil {
+		return DefaultKeyMaps(), err
+	}
+
+	keyMaps := DefaultKeyMaps()
+	if err := keyMaps.Apply(bindings); err != nil {
+		return DefaultKeyMaps(), err
+	}
+	return keyMaps, nil
+}
+
+// actions returns every rebindable binding keyed by "scope.action"
+func (km *KeyMaps) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"chat.down":                 &km.Chat.Down,
+		"chat.up":                   &km.Chat.Up,
+		"chat.half_page_down":       &km.Chat.HalfPageDown,
+		"chat.half_page_up":         &km.Chat.HalfPageUp,
+		"chat.top":                  &km.Chat.Top,
+		"chat.bottom":               &km.Chat.Bottom,
+		"chat.goto_message":         &km.Chat.GotoMessage,
+		"chat.toggle_timestamp":     &km.Chat.ToggleTimestamp,
+		"chat.toggle_relative_time": &km.Chat.ToggleRelativeTime,
+		"chat.toggle_line_numbers":  &km.Chat.ToggleLineNumbers,
+		"chat.toggle_word_wrap":     &km.Chat.ToggleWordWrap,
+		"chat.toggle_system_prompt": &km.Chat.ToggleSystemPrompt,
+		"chat.toggle_sidebar":       &km.Chat.ToggleSidebar,
+		"chat.copy":                 &km.Chat.Copy,
+		"chat.copy_all":             &km.Chat.CopyAll,
+		"chat.copy_replies":         &km.Chat.CopyReplies,
+		"chat.actions":              &km.Chat.Actions,
+		"chat.select":               &km.Chat.Select,
+		"chat.search":               &km.Chat.Search,
+		"chat.next_result":          &km.Chat.NextResult,
+		"chat.prev_result":          &km.Chat.PrevResult,
+		"chat.help":                 &km.Chat.Help,
+
+		"history.search":       &km.History.Search,
+		"history.back":         &km.History.Back,
+		"history.open":         &km.History.Open,
+		"history.list_view":    &km.History.ListView,
+		"history.table_view":   &km.History.TableView,
+		"history.preview":      &km.History.Preview,
+		"history.export_view":  &km.History.ExportView,
+		"history.session_info": &km.History.SessionInfo,
+		"history.delete":       &km.History.Delete,
+		"history.export":       &km.History.Export,
+		"history.refresh":      &km.History.Refresh,
+		"history.sort":         &km.History.Sort,
+		"history.export_all":   &km.History.ExportAll,
+		"history.help":         &km.History.Help,
+
+		"input.submit":            &km.Input.Submit,
+		"input.submit_multiline":  &km.Input.SubmitMultiline,
+		"input.paste":             &km.Input.Paste,
+		"input.cut":               &km.Input.Cut,
+		"input.undo":              &km.Input.Undo,
+		"input.accept_suggestion": &km.Input.AcceptSuggestion,
+		"input.dismiss":           &km.Input.Dismiss,
+		"input.history_prev":      &km.Input.HistoryPrev,
+		"input.history_next":      &km.Input.HistoryNext,
+		"input.add_to_dictionary": &km.Input.AddToDictionary,
+
+		"settings.save":            &km.Settings.Save,
+		"settings.reset":           &km.Settings.Reset,
+		"settings.undo":            &km.Settings.Undo,
+		"settings.next_section":    &km.Settings.NextSection,
+		"settings.prev_section":    &km.Settings.PrevSection,
+		"settings.general":         &km.Settings.General,
+		"settings.providers":       &km.Settings.Providers,
+		"settings.display":         &km.Settings.Display,
+		"settings.advanced":        &km.Settings.Advanced,
+		"settings.about":           &km.Settings.About,
+		"settings.search":          &km.Settings.Search,
+		"settings.test_connection": &km.Settings.TestConnection,
+		"settings.color_preview":   &km.Settings.ColorPreview,
+
+		"sidebar.up":     &km.Sidebar.Up,
+		"sidebar.down":   &km.Sidebar.Down,
+		"sidebar.select": &km.Sidebar.Select,
+	}
+}
+
+// Apply rebinds actions from user overrides and validates the result
+func (km *KeyMaps) Apply(bindings storage.KeyBindings) error {
+	actions := km.actions()
+
+	for name, keys := range bindings {
+		binding, ok := actions[name]
+		if !ok {
+			return fmt.Errorf("unknown keybinding action: %s", name)
+		}
+		if len(keys) == 0 {
+			return fmt.Errorf("keybinding %s has no keys", name)
+		}
+
+		normalized := make([]string, len(keys))
+		for i, k := range keys {
+			if k == "space" {
+				k = " "
+			}
+			normalized[i] = k
+		}
+
+		binding.SetKeys(normalized...)
+		binding.SetHelp(strings.Join(keys, "/"), binding.Help().Desc)
+	}
+
+	return km.Validate()
+}
+
+// Validate reports keys bound to more than one action within a component
+func (km *KeyMaps) Validate() error {
+	owners := make(map[string]string)
+
+	names := make([]string, 0)
+	actions := km.actions()
+	for name := range actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		scope := name[:strings.Index(name, ".")]
+		for _, k := range actions[name].Keys() {
+			scoped := scope + ":" + k
+			if owner, exists := owners[scoped]; exists {
+				return fmt.Errorf("key %q is bound to both %s and %s", k, owner, name)
+			}
+			owners[scoped] = name
+		}
+	}
+
+	return nil
+}
+
+// Overrides returns the bindings that differ from the defaults
+func (km *KeyMaps) Overrides() storage.KeyBindings {
+	defaults := DefaultKeyMaps()
+	defaultActions := defaults.actions()
+
+	overrides := storage.KeyBindings{}
+	for name, binding := range km.actions() {
+		keys := binding.Keys()
+		if strings.Join(keys, "\x00") != strings.Join(defaultActions[name].Keys(), "\x00") {
+			overrides[name] = append([]string(nil), keys...)
+		}
+	}
+	return overrides
+}
+
+// bindingHints renders bindings as "key: description" hints for footers
+func bindingHints(bindings ...key.Binding) string {
+	var hints []string
+	for _, binding := range bindings {
+		if !binding.Enabled() {
+			continue
+		}
+		h := binding.Help()
+		hints = append(hints, h.Key+": "+h.Desc)
+	}
+	return strings.Join(hints, " • ")
+}
+
+// Ensure keymaps satisfy the help.KeyMap interface
+var (
+	_ help.KeyMap = ChatKeyMap{}
+	_ help.KeyMap = HistoryKeyMap{}
+	_ help.KeyMap = SettingsKeyMap{}
+	_ help.KeyMap = InputKeyMap{}
+	_ help.KeyMap = SidebarKeyMap{}
+)
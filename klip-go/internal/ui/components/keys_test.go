@@ -0,0 +1,64 @@
+package components
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/john/klip/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runeKey(r rune) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}
+}
+
+func TestRemappedChatKeyTriggersAction(t *testing.T) {
+	keyMaps := DefaultKeyMaps()
+	require.NoError(t, keyMaps.Apply(storage.KeyBindings{"chat.toggle_timestamp": {"T"}}))
+
+	cv := NewChatView(80, 20)
+	cv.SetKeyMap(keyMaps.Chat)
+
+	cv, _ = cv.Update(runeKey('t'))
+	assert.False(t, cv.showTimestamp)
+
+	cv, _ = cv.Update(runeKey('T'))
+	assert.True(t, cv.showTimestamp)
+}
+
+func TestRemappedInputKeyTriggersAction(t *testing.T) {
+	keyMaps := DefaultKeyMaps()
+	require.NoError(t, keyMaps.Apply(storage.KeyBindings{"input.history_next": {"ctrl+n"}}))
+
+	ei := NewEnhancedInput(InputTypeText, 80, 3)
+	ei.SetKeyMap(keyMaps.Input)
+	ei.AddToHistory("previous message")
+
+	ei, _ = ei.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	assert.Equal(t, "previous message", ei.Value())
+}
+
+func TestKeyMapsApplyRejectsConflicts(t *testing.T) {
+	keyMaps := DefaultKeyMaps()
+	err := keyMaps.Apply(storage.KeyBindings{"chat.copy": {"j"}})
+	assert.ErrorContains(t, err, `"j"`)
+}
+
+func TestKeyMapsApplyRejectsUnknownActions(t *testing.T) {
+	keyMaps := DefaultKeyMaps()
+	assert.Error(t, keyMaps.Apply(storage.KeyBindings{"chat.launch_rockets": {"x"}}))
+}
+
+func TestKeyMapsAllowSameKeyAcrossComponents(t *testing.T) {
+	keyMaps := DefaultKeyMaps()
+	assert.NoError(t, keyMaps.Validate())
+}
+
+func TestKeyMapsOverrides(t *testing.T) {
+	keyMaps := DefaultKeyMaps()
+	assert.Empty(t, keyMaps.Overrides())
+
+	require.NoError(t, keyMaps.Apply(storage.KeyBindings{"chat.select": {"space", "x"}}))
+	assert.Equal(t, storage.KeyBindings{"chat.select": {" ", "x"}}, keyMaps.Overrides())
+}
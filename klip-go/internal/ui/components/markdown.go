@@ -0,0 +1,79 @@
+package components
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// listItemPattern matches a bullet or numbered list item, capturing its
+// indentation, marker and text
+var listItemPattern = regexp.MustCompile(`^(\s*)([-*+]|\d{1,9}[.)])\s+(.*)$`)
+
+// markdownHeading reports whether line is an ATX heading, returning its
+// level and text
+func markdownHeading(line string) (int, string, bool) {
+	trimmed := strings.TrimSpace(line)
+	level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+	if level == 0 || level > 6 {
+		return 0, "", false
+	}
+
+	rest := trimmed[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return 0, "", false
+	}
+	text := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(rest), "#"))
+	if text == "" {
+		return 0, "", false
+	}
+	return level, text, true
+}
+
+// markdownListItem reports whether line is a list item, returning its
+// indentation, marker and text. Horizontal rules such as "* * *" are not
+// list items.
+func markdownListItem(line string) (string, string, string, bool) {
+	trimmed := strings.TrimSpace(line)
+	if len(trimmed) >= 3 && strings.Trim(trimmed, "-*_ ") == "" {
+		return "", "", "", false
+	}
+
+	match := listItemPattern.FindStringSubmatch(line)
+	if match == nil {
+		return "", "", "", false
+	}
+	return match[1], match[2], match[3], true
+}
+
+// renderInline renders the spans a line of prose may hold: inline math
+// and inline code
+func (cv *ChatView) renderInline(text string) string {
+	text = cv.renderInlineMath(text)
+	if cv.isInlineCode(text) {
+		text = cv.highlightInlineCode(text)
+	}
+	return text
+}
+
+// renderHeading draws a heading without its # markers, styled by level
+func (cv *ChatView) renderHeading(level int, text string, baseStyle lipgloss.Style) string {
+	style := Heading3Style
+	switch level {
+	case 1:
+		style = Heading1Style
+	case 2:
+		style = Heading2Style
+	}
+	return baseStyle.Render(style.Render(cv.renderInline(text)))
+}
+
+// renderListItem draws a list item with its marker highlighted; bullets of
+// any kind become •, numbers are kept
+func (cv *ChatView) renderListItem(indent, marker, text string, baseStyle lipgloss.Style) string {
+	if marker == "-" || marker == "*" || marker == "+" {
+		marker = "•"
+	}
+	return baseStyle.Render(indent + ListMarkerStyle.Render(marker) + " " + cv.renderInline(text))
+}
@@ -0,0 +1,270 @@
+package components
+
+import (
+	"strings"
+	"unicode"
+)
+
+// texSymbols maps TeX commands to the Unicode characters that stand in for them
+var texSymbols = map[string]string{
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ε",
+	"varepsilon": "ε", "zeta": "ζ", "eta": "η", "theta": "θ", "iota": "ι",
+	"kappa": "κ", "lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ", "pi": "π",
+	"rho": "ρ", "sigma": "σ", "tau": "τ", "upsilon": "υ", "phi": "φ",
+	"varphi": "φ", "chi": "χ", "psi": "ψ", "omega": "ω",
+	"Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ",
+	"Pi": "Π", "Sigma": "Σ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+
+	"sum": "∑", "prod": "∏", "int": "∫", "oint": "∮", "partial": "∂",
+	"nabla": "∇", "infty": "∞", "sqrt": "√",
+	"times": "×", "cdot": "·", "div": "÷", "pm": "±", "mp": "∓", "ast": "∗",
+	"le": "≤", "leq": "≤", "ge": "≥", "geq": "≥", "ne": "≠", "neq": "≠",
+	"approx": "≈", "equiv": "≡", "sim": "∼", "propto": "∝", "ll": "≪", "gg": "≫",
+	"to": "→", "rightarrow": "→", "leftarrow": "←", "Rightarrow": "⇒",
+	"Leftarrow": "⇐", "iff": "⇔", "implies": "⇒", "mapsto": "↦",
+	"in": "∈", "notin": "∉", "subset": "⊂", "subseteq": "⊆", "supset": "⊃",
+	"supseteq": "⊇", "cup": "∪", "cap": "∩", "emptyset": "∅", "forall": "∀",
+	"exists": "∃", "neg": "¬", "land": "∧", "lor": "∨", "wedge": "∧", "vee": "∨",
+	"ldots": "…", "cdots": "⋯", "dots": "…", "circ": "∘", "deg": "°",
+	"langle": "⟨", "rangle": "⟩", "lfloor": "⌊", "rfloor": "⌋", "lceil": "⌈",
+	"rceil": "⌉", "mid": "∣", "prime": "′", "hbar": "ℏ", "ell": "ℓ",
+	"log": "log", "ln": "ln", "exp": "exp", "sin": "sin", "cos": "cos",
+	"tan": "tan", "lim": "lim", "max": "max", "min": "min",
+	"quad": "  ", "qquad": "    ",
+}
+
+var superscripts = map[rune]rune{
+	'0': '⁰', '1': '¹', '2': '²', '3': '³', '4': '⁴', '5': '⁵', '6': '⁶',
+	'7': '⁷', '8': '⁸', '9': '⁹', '+': '⁺', '-': '⁻', '=': '⁼', '(': '⁽',
+	')': '⁾', 'a': 'ᵃ', 'b': 'ᵇ', 'c': 'ᶜ', 'd': 'ᵈ', 'e': 'ᵉ', 'f': 'ᶠ',
+	'g': 'ᵍ', 'h': 'ʰ', 'i': 'ⁱ', 'j': 'ʲ', 'k': 'ᵏ', 'l': 'ˡ', 'm': 'ᵐ',
+	'n': 'ⁿ', 'o': 'ᵒ', 'p': 'ᵖ', 'r': 'ʳ', 's': 'ˢ', 't': 'ᵗ', 'u': 'ᵘ',
+	'v': 'ᵛ', 'w': 'ʷ', 'x': 'ˣ', 'y': 'ʸ', 'z': 'ᶻ', 'T': 'ᵀ', '′': '′',
+}
+
+var subscripts = map[rune]rune{
+	'0': '₀', '1': '₁', '2': '₂', '3': '₃', '4': '₄', '5': '₅', '6': '₆',
+	'7': '₇', '8': '₈', '9': '₉', '+': '₊', '-': '₋', '=': '₌', '(': '₍',
+	')': '₎', 'a': 'ₐ', 'e': 'ₑ', 'h': 'ₕ', 'i': 'ᵢ', 'j': 'ⱼ', 'k': 'ₖ',
+	'l': 'ₗ', 'm': 'ₘ', 'n': 'ₙ', 'o': 'ₒ', 'p': 'ₚ', 'r': 'ᵣ', 's': 'ₛ',
+	't': 'ₜ', 'u': 'ᵤ', 'v': 'ᵥ', 'x': 'ₓ',
+}
+
+// texToUnicode converts a TeX math expression to plain Unicode text. It
+// reports false when the expression uses something the converter can't
+// express faithfully, in which case callers show the source instead.
+func texToUnicode(expr string) (string, bool) {
+	c := &texConverter{src: []rune(expr), ok: true}
+	out := c.parse(false)
+	return strings.TrimSpace(out), c.ok
+}
+
+// texConverter is a small recursive-descent pass over a TeX expression
+type texConverter struct {
+	src []rune
+	pos int
+	ok  bool
+}
+
+// parse converts runes until the input ends or, inside a group, a closing brace
+func (c *texConverter) parse(inGroup bool) string {
+	var out strings.Builder
+
+	for c.pos < len(c.src) {
+		r := c.src[c.pos]
+		switch r {
+		case '}':
+			c.pos++
+			if inGroup {
+				return out.String()
+			}
+			c.ok = false
+		case '{':
+			c.pos++
+			out.WriteString(c.parse(true))
+		case '\\':
+			out.WriteString(c.command())
+		case '^':
+			c.pos++
+			out.WriteString(c.script(superscripts, "^"))
+		case '_':
+			c.pos++
+			out.WriteString(c.script(subscripts, "_"))
+		case '&':
+			c.pos++
+		default:
+			c.pos++
+			out.WriteRune(r)
+		}
+	}
+
+	if inGroup {
+		c.ok = false
+	}
+	return out.String()
+}
+
+// command converts the control sequence at the current position
+func (c *texConverter) command() string {
+	c.pos++ // backslash
+	start := c.pos
+	for c.pos < len(c.src) && unicode.IsLetter(c.src[c.pos]) {
+		c.pos++
+	}
+	name := string(c.src[start:c.pos])
+
+	if name == "" {
+		if c.pos >= len(c.src) {
+			c.ok = false
+			return "\\"
+		}
+		// Escaped characters and spacing commands like \{ and \,
+		r := c.src[c.pos]
+		c.pos++
+		switch r {
+		case ',', ';', ':', '!', ' ':
+			return " "
+		case '\\':
+			return "\n"
+		}
+		return string(r)
+	}
+
+	switch name {
+	case "frac", "dfrac", "tfrac":
+		num, den := c.argument(), c.argument()
+		return parenthesize(num) + "/" + parenthesize(den)
+	case "sqrt":
+		return "√" + parenthesize(c.argument())
+	case "text", "mathrm", "mathbf", "mathit", "mathsf", "operatorname", "textbf", "textit":
+		return c.argument()
+	case "left", "right", "big", "Big", "bigl", "bigr", "displaystyle":
+		return ""
+	}
+
+	if symbol, ok := texSymbols[name]; ok {
+		return symbol
+	}
+
+	c.ok = false
+	return "\\" + name
+}
+
+// argument reads a command argument: a braced group, a command or one rune
+func (c *texConverter) argument() string {
+	for c.pos < len(c.src) && c.src[c.pos] == ' ' {
+		c.pos++
+	}
+	if c.pos >= len(c.src) {
+		c.ok = false
+		return ""
+	}
+
+	switch c.src[c.pos] {
+	case '{':
+		c.pos++
+		return c.parse(true)
+	case '\\':
+		return c.command()
+	}
+
+	r := c.src[c.pos]
+	c.pos++
+	return string(r)
+}
+
+// script converts a superscript or subscript argument using table, giving
+// up when any character has no raised or lowered form
+func (c *texConverter) script(table map[rune]rune, marker string) string {
+	arg := c.argument()
+
+	var out strings.Builder
+	for _, r := range arg {
+		mapped, ok := table[r]
+		if !ok {
+			c.ok = false
+			return marker + parenthesize(arg)
+		}
+		out.WriteRune(mapped)
+	}
+	return out.String()
+}
+
+// parenthesize wraps multi-character operands so a/b and √x stay unambiguous
+func parenthesize(s string) string {
+	if len([]rune(s)) <= 1 {
+		return s
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return "(" + s + ")"
+		}
+	}
+	return s
+}
+
+// inlineMathSpans returns the [start, end) byte ranges of $...$ spans in
+// line, outside inline code. Like Pandoc, the opening $ must be followed by
+// a non-space and the closing $ preceded by one and not followed by a digit,
+// so prices such as "$5 and $10" are left alone.
+func inlineMathSpans(line string) [][]int {
+	var spans [][]int
+	inCode := false
+	open := -1
+
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '`':
+			inCode = !inCode
+			open = -1
+		case '\\':
+			i++
+		case '$':
+			if inCode {
+				continue
+			}
+			if i+1 < len(line) && line[i+1] == '$' {
+				// Display math delimiters are handled per block
+				i++
+				open = -1
+				continue
+			}
+			if open >= 0 && line[i-1] != ' ' && (i+1 >= len(line) || line[i+1] < '0' || line[i+1] > '9') {
+				spans = append(spans, []int{open, i + 1})
+				open = -1
+				continue
+			}
+			if i+1 < len(line) && line[i+1] != ' ' && line[i+1] != '$' {
+				open = i
+			}
+		}
+	}
+
+	return spans
+}
+
+// mathBlockAt reports whether a $$ display block starts at lines[start],
+// returning its expression and the index of its closing line
+func mathBlockAt(lines []string, start int) (string, int, bool) {
+	first := strings.TrimSpace(lines[start])
+	if !strings.HasPrefix(first, "$$") {
+		return "", 0, false
+	}
+
+	// Single-line block: $$ expr $$
+	if rest := first[2:]; len(rest) >= 2 && strings.HasSuffix(rest, "$$") {
+		return strings.TrimSpace(rest[:len(rest)-2]), start, true
+	}
+
+	body := []string{strings.TrimPrefix(first, "$$")}
+	for i := start + 1; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if strings.HasSuffix(line, "$$") {
+			body = append(body, strings.TrimSuffix(line, "$$"))
+			return strings.TrimSpace(strings.Join(body, "\n")), i, true
+		}
+		body = append(body, line)
+	}
+
+	return "", 0, false
+}
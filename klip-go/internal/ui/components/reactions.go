@@ -0,0 +1,163 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// commonReactions are the shortcodes offered in the reaction picker's row,
+// ahead of the "more" search over all shortcodes
+var commonReactions = []string{"+1", "-1", "heart", "joy", "tada", "eyes", "rocket", "thinking"}
+
+// reactionPicker chooses a reaction for a message from a row of common
+// reactions, or by searching shortcodes once "more" is picked
+type reactionPicker struct {
+	messageIdx int
+
+	// selected is an index into the row, where len(commonReactions) is
+	// "more", or into matches while searching
+	selected  int
+	searching bool
+	query     string
+	matches   []CommandSuggestion
+}
+
+// openReactionPicker shows the reaction picker for a message
+func (cv *ChatView) openReactionPicker(messageIdx int) {
+	if messageIdx < 0 || messageIdx >= len(cv.messages) {
+		return
+	}
+	cv.setReactionPicker(&reactionPicker{messageIdx: messageIdx})
+}
+
+// setReactionPicker opens or closes the picker, keeping the scroll position
+// as the viewport makes room for it
+func (cv *ChatView) setReactionPicker(picker *reactionPicker) {
+	offset := cv.viewport.YOffset
+	cv.reactionPicker = picker
+	cv.viewport.Height = cv.viewportHeight()
+	cv.viewport.SetYOffset(offset)
+}
+
+// reactionText is what a reaction shortcode adds to a message: the emoji
+// itself, or the shortcode on terminals that can't display it
+func (cv *ChatView) reactionText(name string) string {
+	if cv.emojiSupported {
+		return emojiShortcodes[name]
+	}
+	return ":" + name + ":"
+}
+
+// handleReactionPickerKey moves through the picker and adds the chosen
+// reaction. Number keys pick from the row directly.
+func (cv *ChatView) handleReactionPickerKey(msg tea.KeyMsg) {
+	picker := cv.reactionPicker
+	if picker.searching {
+		cv.handleReactionSearchKey(msg)
+		return
+	}
+
+	switch msg.String() {
+	case "left", "h":
+		picker.selected = (picker.selected + len(commonReactions)) % (len(commonReactions) + 1)
+	case "right", "l", "tab":
+		picker.selected = (picker.selected + 1) % (len(commonReactions) + 1)
+	case "/":
+		picker.searching = true
+		picker.selected = 0
+	case "enter":
+		if picker.selected == len(commonReactions) {
+			picker.searching = true
+			picker.selected = 0
+			return
+		}
+		cv.pickReaction(commonReactions[picker.selected])
+	case "esc", "q":
+		cv.setReactionPicker(nil)
+	default:
+		if s := msg.String(); len(s) == 1 && s[0] >= '1' && int(s[0]-'0') <= len(commonReactions) {
+			cv.pickReaction(commonReactions[s[0]-'1'])
+		}
+	}
+}
+
+// handleReactionSearchKey edits the shortcode search and picks a match on
+// enter; esc returns to the row
+func (cv *ChatView) handleReactionSearchKey(msg tea.KeyMsg) {
+	picker := cv.reactionPicker
+	switch msg.Type {
+	case tea.KeyEsc:
+		picker.searching = false
+		picker.query = ""
+		picker.matches = nil
+		picker.selected = len(commonReactions)
+	case tea.KeyEnter:
+		if picker.selected < len(picker.matches) {
+			cv.pickReaction(strings.Trim(picker.matches[picker.selected].Command, ":"))
+		}
+	case tea.KeyLeft, tea.KeyShiftTab:
+		if picker.selected > 0 {
+			picker.selected--
+		}
+	case tea.KeyRight, tea.KeyTab:
+		if picker.selected < len(picker.matches)-1 {
+			picker.selected++
+		}
+	case tea.KeyBackspace:
+		if picker.query != "" {
+			picker.query = picker.query[:len(picker.query)-1]
+			cv.searchReactions()
+		}
+	case tea.KeyRunes:
+		picker.query += strings.ToLower(string(msg.Runes))
+		cv.searchReactions()
+	}
+}
+
+// searchReactions refreshes the matches for the picker's query
+func (cv *ChatView) searchReactions() {
+	picker := cv.reactionPicker
+	picker.selected = 0
+	picker.matches = nil
+	if picker.query != "" {
+		picker.matches = EmojiSuggestions(picker.query)
+	}
+}
+
+// pickReaction adds a reaction to the picker's message and closes it
+func (cv *ChatView) pickReaction(name string) {
+	messageIdx := cv.reactionPicker.messageIdx
+	cv.setReactionPicker(nil)
+	cv.AddReaction(messageIdx, cv.reactionText(name))
+}
+
+// renderReactionPicker renders the picker as one line below the conversation
+func (cv *ChatView) renderReactionPicker() string {
+	picker := cv.reactionPicker
+	item := func(text string, selected bool) string {
+		if selected {
+			return ContextMenuSelectedStyle.Render(text)
+		}
+		return ContextMenuItemStyle.Render(text)
+	}
+
+	var items []string
+	var hint string
+	if picker.searching {
+		items = append(items, GotoPromptStyle.Render(":"+picker.query))
+		for i, match := range picker.matches {
+			items = append(items, item(cv.reactionText(strings.Trim(match.Command, ":")), i == picker.selected))
+		}
+		hint = "type to search, enter to react, esc to go back"
+	} else {
+		for i, name := range commonReactions {
+			items = append(items, item(fmt.Sprintf("%d %s", i+1, cv.reactionText(name)), i == picker.selected))
+		}
+		items = append(items, item("more…", picker.selected == len(commonReactions)))
+		hint = "←/→ and enter to react, / to search, esc to cancel"
+	}
+
+	return strings.Join(items, " ") + "  " + LineNumberStyle.Render(hint)
+}
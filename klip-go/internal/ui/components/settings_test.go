@@ -0,0 +1,159 @@
+package components
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/john/klip/internal/storage"
+	"github.com/john/klip/internal/ui/styles"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettingsSearchFindsFieldsInOtherSections(t *testing.T) {
+	sf := NewSettingsForm(&storage.Config{}, 100, 40)
+	sf.currentSection = SectionDisplay
+	sf.buildForm()
+
+	matches := sf.searchFields("timeout")
+	require.NotEmpty(t, matches)
+	assert.Equal(t, "Request Timeout", matches[0].Title)
+	assert.Equal(t, SectionGeneral, matches[0].Section)
+	assert.Equal(t, "Maximum time to wait for API responses", matches[0].Description)
+
+	// Descriptions are searched too
+	titles := make([]string, 0)
+	for _, match := range sf.searchFields("web") {
+		titles = append(titles, match.Title)
+	}
+	assert.Contains(t, titles, "Enable Web Search")
+
+	assert.Empty(t, sf.searchFields("no such setting"))
+}
+
+func TestSettingsSearchJumpsToField(t *testing.T) {
+	sf := NewSettingsForm(&storage.Config{}, 100, 40)
+	sf.currentSection = SectionAbout
+	sf.buildForm()
+
+	sf, _ = sf.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	require.True(t, sf.searching)
+	for _, r := range "timeout" {
+		sf, _ = sf.Update(runeKey(r))
+	}
+	assert.Contains(t, sf.View(), "Request Timeout")
+
+	sf, _ = sf.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.False(t, sf.searching)
+	assert.Equal(t, SectionGeneral, sf.currentSection)
+
+	title, _ := fieldHeader(sf.form.GetFocusedField())
+	assert.Equal(t, "Request Timeout", title)
+	assert.Contains(t, sf.View(), "→ Request Timeout")
+}
+
+func TestSettingsFormFitsNarrowTerminal(t *testing.T) {
+	sf := NewSettingsForm(&storage.Config{}, 30, 24)
+	require.True(t, sf.narrow())
+
+	width, _ := sf.formSize()
+	assert.LessOrEqual(t, width, 30)
+	for _, line := range strings.Split(sf.View(), "\n") {
+		assert.LessOrEqual(t, lipgloss.Width(line), 30, "line overflows: %q", line)
+	}
+	assert.Contains(t, sf.View(), "General 1/")
+
+	// Tab still moves between sections
+	sf, _ = sf.Update(tea.KeyMsg{Type: tea.KeyTab})
+	assert.Equal(t, SectionProviders, sf.currentSection)
+	assert.Contains(t, sf.View(), "Providers 2/")
+
+	// Search still sees whole descriptions
+	matches := sf.searchFields("timeout")
+	require.NotEmpty(t, matches)
+	assert.Equal(t, "Maximum time to wait for API responses", matches[0].Description)
+}
+
+func TestSettingsHighContrastRethemesLive(t *testing.T) {
+	t.Setenv("ACCESSIBILITY_HIGH_CONTRAST", "")
+	t.Setenv("FORCE_HIGH_CONTRAST", "")
+	t.Setenv("HIGH_CONTRAST", "")
+	t.Cleanup(func() { _ = styles.SetAccessibility(nil) })
+
+	sf := NewSettingsForm(&storage.Config{}, 100, 40)
+	sf.currentSection = SectionAccessibility
+	sf.buildForm()
+	before := styles.GetCurrentTheme().Colors
+
+	title, _ := fieldHeader(sf.form.GetFocusedField())
+	require.Equal(t, "High Contrast", title)
+
+	// Toggle on
+	sf, _ = sf.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	require.True(t, sf.GetConfig().Accessibility.HighContrast)
+	assert.True(t, sf.accessibility.GetPreferences().HighContrast)
+	assert.Contains(t, sf.accessibility.GetAccessibilityStatus(), "High Contrast")
+	assert.NotEqual(t, before, styles.GetCurrentTheme().Colors)
+	assert.True(t, sf.HasUnsavedChanges())
+
+	// Toggle off restores the theme
+	sf, _ = sf.Update(tea.KeyMsg{Type: tea.KeyRight})
+	assert.False(t, sf.accessibility.GetPreferences().HighContrast)
+	assert.Equal(t, before, styles.GetCurrentTheme().Colors)
+}
+
+func TestSettingsColorPreviewOpensAccessibilitySection(t *testing.T) {
+	sf := NewSettingsForm(&storage.Config{}, 100, 60)
+
+	sf, _ = sf.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	assert.Equal(t, SectionAccessibility, sf.currentSection)
+	view := sf.View()
+	assert.Contains(t, view, "Color Vision Preview")
+	assert.Contains(t, view, "Protanopia")
+	assert.Contains(t, view, "Monochromacy")
+
+	sf, _ = sf.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	assert.NotContains(t, sf.View(), "Color Vision Preview")
+}
+
+// roundTripFunc fakes the HTTP transport used by Test Connection
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestSettingsTestConnectionReportsInvalidKey(t *testing.T) {
+	sf := NewSettingsForm(&storage.Config{AnthropicAPIKey: "sk-ant-wrong"}, 100, 40)
+	sf.httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusUnauthorized,
+			Body:       io.NopCloser(strings.NewReader(`{"error":{"type":"authentication_error","message":"invalid x-api-key"}}`)),
+			Request:    req,
+		}, nil
+	})}
+	sf.currentSection = SectionProviders
+	sf.buildForm()
+
+	sf, cmd := sf.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	require.NotNil(t, cmd)
+	assert.Contains(t, sf.View(), "Testing connection")
+
+	batch, ok := cmd().(tea.BatchMsg)
+	require.True(t, ok)
+	for _, c := range batch {
+		if msg, ok := c().(SettingsMsg); ok {
+			sf, _ = sf.Update(msg)
+		}
+	}
+
+	view := sf.View()
+	assert.False(t, sf.testingConnection())
+	assert.Contains(t, view, "Invalid API key")
+	assert.Contains(t, view, "No API key")
+	assert.NotContains(t, view, "Testing connection")
+}
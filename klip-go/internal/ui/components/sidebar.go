@@ -0,0 +1,304 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/john/klip/internal/api"
+	"github.com/john/klip/internal/ui/styles"
+)
+
+// SidebarMsg represents messages for the sidebar component
+type SidebarMsg struct {
+	Type string
+	Data interface{}
+}
+
+// Sidebar shows recent sessions and a model quick-switch beside the chat
+type Sidebar struct {
+	sessions     []SessionItem
+	models       []api.Model
+	currentModel string
+	cursor       int
+	focused      bool
+	hidden       bool
+	layout       styles.ResponsiveConfig
+	width        int
+	height       int
+	keys         SidebarKeyMap
+
+	// Large text widens the panel's padding
+	accessibility *styles.AccessibilityManager
+}
+
+// NewSidebar creates a new sidebar component
+func NewSidebar(width, height int) *Sidebar {
+	return &Sidebar{
+		width:  width,
+		height: height,
+		keys:   DefaultSidebarKeyMap(),
+	}
+}
+
+// SidebarWidths splits the available width between the sidebar and the chat
+func SidebarWidths(width int, layout styles.ResponsiveConfig, hidden bool) (sidebar, chat int) {
+	if hidden || !layout.ShowSidebar || layout.SidebarWidth <= 0 {
+		return 0, width
+	}
+
+	sidebar = layout.SidebarWidth
+	if sidebar > width {
+		sidebar = width
+	}
+	return sidebar, width - sidebar
+}
+
+// Init initializes the sidebar
+func (s *Sidebar) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles sidebar updates
+func (s *Sidebar) Update(msg tea.Msg) (*Sidebar, tea.Cmd) {
+	switch msg := msg.(type) {
+	case SidebarMsg:
+		switch msg.Type {
+		case "toggle":
+			s.Toggle()
+		case "focus":
+			s.focused = true
+		case "blur":
+			s.focused = false
+		}
+
+	case tea.KeyMsg:
+		if !s.focused || !s.Visible() {
+			return s, nil
+		}
+
+		switch {
+		case key.Matches(msg, s.keys.Up):
+			if s.cursor > 0 {
+				s.cursor--
+			}
+		case key.Matches(msg, s.keys.Down):
+			if s.cursor < s.itemCount()-1 {
+				s.cursor++
+			}
+		case key.Matches(msg, s.keys.Select):
+			return s, s.selectCurrent()
+		}
+	}
+
+	return s, nil
+}
+
+// View renders the sidebar, or nothing when it is collapsed
+func (s *Sidebar) View() string {
+	if !s.Visible() {
+		return ""
+	}
+
+	container := s.accessibility.LargeTextStyle(SidebarContainerStyle)
+	inner := s.width - container.GetHorizontalFrameSize()
+	if inner < 1 {
+		return ""
+	}
+
+	var lines []string
+	index := 0
+
+	if len(s.models) > 0 {
+		lines = append(lines, SidebarHeaderStyle.Render("Models"))
+		for _, model := range s.models {
+			marker := " "
+			if model.ID == s.currentModel {
+				marker = "●"
+			}
+			lines = append(lines, s.renderItem(index, marker+" "+model.Name, inner))
+			index++
+		}
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, SidebarHeaderStyle.Render("Sessions"))
+	if len(s.sessions) == 0 {
+		lines = append(lines, SidebarMutedStyle.Render("No sessions yet"))
+	}
+	for _, item := range s.sessions {
+		lines = append(lines, s.renderItem(index, compactSessionLine(item, inner-2), inner))
+		index++
+	}
+
+	// Clip to the available height
+	if maxLines := s.height - container.GetVerticalFrameSize(); maxLines > 0 && len(lines) > maxLines {
+		lines = lines[:maxLines]
+	}
+
+	// Width and Height include padding but not the border
+	return container.
+		Width(s.width - container.GetHorizontalBorderSize()).
+		Height(s.height - container.GetVerticalBorderSize()).
+		Render(strings.Join(lines, "\n"))
+}
+
+// renderItem renders one selectable row, truncated to the sidebar width
+func (s *Sidebar) renderItem(index int, text string, width int) string {
+	prefix := "  "
+	style := SidebarItemStyle
+	if s.focused && index == s.cursor {
+		prefix = "▸ "
+		style = SidebarSelectedStyle
+	}
+	return style.Render(truncateText(prefix+text, width))
+}
+
+// compactSessionLine condenses a history item to its title and message count
+func compactSessionLine(item SessionItem, width int) string {
+	count := ""
+	if item.metadata != nil {
+		count = fmt.Sprintf(" %d", item.metadata.MessageCount)
+	}
+
+	title := truncateText(item.Title(), width-lipgloss.Width(count))
+	return title + count
+}
+
+// truncateText shortens text to width cells, marking the cut with an ellipsis
+func truncateText(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if lipgloss.Width(text) <= width {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 && lipgloss.Width(string(runes))+1 > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// selectCurrent emits the message for the item under the cursor
+func (s *Sidebar) selectCurrent() tea.Cmd {
+	if s.cursor < len(s.models) {
+		model := s.models[s.cursor]
+		return func() tea.Msg {
+			return ModelMsg{Type: "model_selected", Data: model}
+		}
+	}
+
+	index := s.cursor - len(s.models)
+	if index < 0 || index >= len(s.sessions) {
+		return nil
+	}
+	sessionID := s.sessions[index].session.ID
+	return func() tea.Msg {
+		return SidebarMsg{Type: "session_selected", Data: sessionID}
+	}
+}
+
+func (s *Sidebar) itemCount() int {
+	return len(s.models) + len(s.sessions)
+}
+
+// SetSessions replaces the listed sessions with the history browser's items
+func (s *Sidebar) SetSessions(sessions []SessionItem) {
+	s.sessions = sessions
+	s.clampCursor()
+}
+
+// SetModels sets the models offered for quick switching
+func (s *Sidebar) SetModels(models []api.Model, current string) {
+	s.models = models
+	s.currentModel = current
+	s.clampCursor()
+}
+
+func (s *Sidebar) clampCursor() {
+	if s.cursor >= s.itemCount() {
+		s.cursor = s.itemCount() - 1
+	}
+	if s.cursor < 0 {
+		s.cursor = 0
+	}
+}
+
+// SetLayout applies the responsive configuration for the current breakpoint
+func (s *Sidebar) SetLayout(layout styles.ResponsiveConfig) {
+	s.layout = layout
+}
+
+// SetAccessibility sets the manager whose large text setting widens the
+// sidebar's padding
+func (s *Sidebar) SetAccessibility(am *styles.AccessibilityManager) {
+	s.accessibility = am
+}
+
+// Resize updates the sidebar dimensions
+func (s *Sidebar) Resize(width, height int) {
+	s.width = width
+	s.height = height
+}
+
+// Toggle hides or restores the sidebar regardless of the breakpoint
+func (s *Sidebar) Toggle() {
+	s.hidden = !s.hidden
+	if s.hidden {
+		s.focused = false
+	}
+}
+
+// Visible reports whether the sidebar is currently shown
+func (s *Sidebar) Visible() bool {
+	return !s.hidden && s.layout.ShowSidebar && s.layout.SidebarWidth > 0
+}
+
+// Hidden reports whether the user collapsed the sidebar
+func (s *Sidebar) Hidden() bool {
+	return s.hidden
+}
+
+// Focus gives the sidebar keyboard focus
+func (s *Sidebar) Focus() {
+	s.focused = true
+}
+
+// Blur removes keyboard focus from the sidebar
+func (s *Sidebar) Blur() {
+	s.focused = false
+}
+
+// SetKeyMap replaces the sidebar keybindings
+func (s *Sidebar) SetKeyMap(keys SidebarKeyMap) {
+	s.keys = keys
+}
+
+// Sidebar component styles
+var (
+	SidebarContainerStyle = lipgloss.NewStyle().
+				BorderRight(true).
+				BorderStyle(lipgloss.NormalBorder()).
+				BorderForeground(lipgloss.Color("#4B5563")).
+				PaddingRight(1)
+
+	SidebarHeaderStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#7C3AED")).
+				Bold(true)
+
+	SidebarItemStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#D1D5DB"))
+
+	SidebarSelectedStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#F9FAFB")).
+				Background(lipgloss.Color("#7C3AED")).
+				Bold(true)
+
+	SidebarMutedStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#6B7280")).
+				Italic(true)
+)
@@ -0,0 +1,92 @@
+package components
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/john/klip/internal/api"
+	"github.com/john/klip/internal/storage"
+	"github.com/john/klip/internal/ui/styles"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSidebarWidthsAtEachBreakpoint(t *testing.T) {
+	tests := []struct {
+		name         string
+		width        int
+		sidebarWidth int
+		chatWidth    int
+	}{
+		{"tiny", 25, 0, 25},
+		{"small", 59, 0, 59},
+		{"medium", 60, 20, 40},
+		{"large", 120, 25, 95},
+		{"huge", 160, 30, 130},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout := styles.NewAdaptiveStyler(nil, tt.width, 24).GetResponsiveConfig()
+
+			sidebar, chat := SidebarWidths(tt.width, layout, false)
+			assert.Equal(t, tt.sidebarWidth, sidebar)
+			assert.Equal(t, tt.chatWidth, chat)
+
+			sidebar, chat = SidebarWidths(tt.width, layout, true)
+			assert.Equal(t, 0, sidebar)
+			assert.Equal(t, tt.width, chat)
+		})
+	}
+}
+
+func TestSidebarToggleOverridesBreakpoint(t *testing.T) {
+	sidebar := NewSidebar(25, 20)
+	sidebar.SetLayout(styles.NewAdaptiveStyler(nil, 120, 24).GetResponsiveConfig())
+	assert.True(t, sidebar.Visible())
+
+	sidebar, _ = sidebar.Update(SidebarMsg{Type: "toggle"})
+	assert.False(t, sidebar.Visible())
+	assert.Empty(t, sidebar.View())
+
+	sidebar.Toggle()
+	assert.True(t, sidebar.Visible())
+
+	sidebar.SetLayout(styles.NewAdaptiveStyler(nil, 40, 24).GetResponsiveConfig())
+	assert.False(t, sidebar.Visible())
+}
+
+func TestSidebarRendersWithinWidth(t *testing.T) {
+	hb := NewHistoryBrowser(80, 20)
+	hb.SetSessions([]storage.ChatSession{{
+		ID:       "session-with-a-long-identifier",
+		Title:    "A conversation with a rather long title",
+		Messages: []storage.Message{{Role: "user", Content: "hello"}},
+	}})
+
+	sidebar := NewSidebar(25, 20)
+	sidebar.SetLayout(styles.NewAdaptiveStyler(nil, 120, 24).GetResponsiveConfig())
+	sidebar.SetSessions(hb.SessionItems())
+	sidebar.SetModels([]api.Model{{ID: "m1", Name: "Model One"}}, "m1")
+
+	view := sidebar.View()
+	assert.Contains(t, view, "Sessions")
+	assert.Contains(t, view, "Model One")
+	assert.Equal(t, 25, lipgloss.Width(view))
+}
+
+func TestSidebarSelectsModel(t *testing.T) {
+	sidebar := NewSidebar(25, 20)
+	sidebar.SetLayout(styles.NewAdaptiveStyler(nil, 120, 24).GetResponsiveConfig())
+	sidebar.SetModels([]api.Model{{ID: "m1", Name: "One"}, {ID: "m2", Name: "Two"}}, "m1")
+	sidebar.Focus()
+
+	sidebar, _ = sidebar.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, cmd := sidebar.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if assert.NotNil(t, cmd) {
+		msg := cmd().(ModelMsg)
+		assert.Equal(t, "model_selected", msg.Type)
+		assert.Equal(t, "m2", msg.Data.(api.Model).ID)
+	}
+}
@@ -1,17 +1,26 @@
 package components
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/dustin/go-humanize"
 	"github.com/john/klip/internal/api"
 	"github.com/john/klip/internal/app"
+	"github.com/john/klip/internal/storage"
+	"github.com/john/klip/internal/ui/styles"
 )
 
 // StatusMsg represents messages for status components
@@ -39,6 +48,7 @@ type Notification struct {
 	Duration time.Duration
 	ShowTime time.Time
 	Actions  []NotificationAction
+	Count    int
 }
 
 // NotificationAction represents an action button on a notification
@@ -48,6 +58,20 @@ type NotificationAction struct {
 	Style   lipgloss.Style
 }
 
+// StreamThroughput carries the running output estimate of an active stream
+type StreamThroughput struct {
+	Tokens  int
+	Elapsed time.Duration
+}
+
+// TokensPerSecond returns the throughput for the sample
+func (st StreamThroughput) TokensPerSecond() float64 {
+	if st.Elapsed <= 0 || st.Tokens <= 0 {
+		return 0
+	}
+	return float64(st.Tokens) / st.Elapsed.Seconds()
+}
+
 // ConnectionState represents the current connection status
 type ConnectionState int
 
@@ -58,6 +82,135 @@ const (
 	ConnectionError
 )
 
+// ConnectionStateMsg reports a streaming connection change from the API client
+func ConnectionStateMsg(state api.ConnectionState) StatusMsg {
+	return StatusMsg{Type: "connection_state", Data: ConnectionState(state)}
+}
+
+// NetworkTier buckets a network quality score for display
+type NetworkTier int
+
+const (
+	NetworkUnknown NetworkTier = iota
+	NetworkPoor
+	NetworkFair
+	NetworkGood
+	NetworkExcellent
+)
+
+// String names the tier
+func (t NetworkTier) String() string {
+	switch t {
+	case NetworkPoor:
+		return "poor"
+	case NetworkFair:
+		return "fair"
+	case NetworkGood:
+		return "good"
+	case NetworkExcellent:
+		return "excellent"
+	default:
+		return "unknown"
+	}
+}
+
+// NetworkQuality is a network quality score from 0 to 100 and its tier
+type NetworkQuality struct {
+	Score int
+	Tier  NetworkTier
+}
+
+// NetworkQualityFromScore buckets a score from 0 to 100
+func NetworkQualityFromScore(score int) NetworkQuality {
+	score = max(0, min(score, 100))
+	switch {
+	case score >= 80:
+		return NetworkQuality{score, NetworkExcellent}
+	case score >= 60:
+		return NetworkQuality{score, NetworkGood}
+	case score >= 35:
+		return NetworkQuality{score, NetworkFair}
+	default:
+		return NetworkQuality{score, NetworkPoor}
+	}
+}
+
+const (
+	// networkSampleWindow is how many recent requests the quality score
+	// covers
+	networkSampleWindow = 20
+
+	// Average latencies up to fastLatency score full marks and those from
+	// slowLatency score nothing, with a straight line in between
+	fastLatency = time.Second
+	slowLatency = 10 * time.Second
+
+	// networkErrorPenalty is how many points a 100% error rate costs
+	networkErrorPenalty = 150
+)
+
+// networkSample is the outcome of one request
+type networkSample struct {
+	latency time.Duration
+	failed  bool
+}
+
+// NetworkMeter scores network quality from the latencies and failures of
+// recent requests
+type NetworkMeter struct {
+	samples []networkSample
+}
+
+// Record adds a request outcome, dropping the oldest beyond the window,
+// and returns the updated quality
+func (nm *NetworkMeter) Record(latency time.Duration, failed bool) NetworkQuality {
+	nm.samples = append(nm.samples, networkSample{latency, failed})
+	if len(nm.samples) > networkSampleWindow {
+		nm.samples = nm.samples[len(nm.samples)-networkSampleWindow:]
+	}
+	return nm.Quality()
+}
+
+// Quality scores the recorded requests by the average latency of those
+// that succeeded, less a penalty for the share that failed. It is unknown
+// until a request is recorded.
+func (nm *NetworkMeter) Quality() NetworkQuality {
+	if len(nm.samples) == 0 {
+		return NetworkQuality{}
+	}
+
+	var total time.Duration
+	succeeded := 0
+	for _, sample := range nm.samples {
+		if !sample.failed {
+			total += sample.latency
+			succeeded++
+		}
+	}
+
+	latencyScore := 0
+	if succeeded > 0 {
+		average := total / time.Duration(succeeded)
+		switch {
+		case average <= fastLatency:
+			latencyScore = 100
+		case average < slowLatency:
+			latencyScore = int(100 * (slowLatency - average) / (slowLatency - fastLatency))
+		}
+	}
+
+	failed := len(nm.samples) - succeeded
+	penalty := networkErrorPenalty * failed / len(nm.samples)
+	return NetworkQualityFromScore(latencyScore - penalty)
+}
+
+// networkQualityCmd reports the meter's quality to the status bar
+func networkQualityCmd(quality NetworkQuality) tea.Cmd {
+	return func() tea.Msg {
+		return StatusMsg{Type: "network_quality", Data: quality}
+	}
+}
+
 // StatusBar provides a comprehensive status display
 type StatusBar struct {
 	// Connection status
@@ -76,14 +229,37 @@ type StatusBar struct {
 	avgLatency      time.Duration
 	lastRequestTime time.Duration
 	queuedRequests  int
+	tokensPerSecond float64
 
 	// System status
 	memoryUsage    int64
-	networkQuality int // 0-100
+	networkQuality NetworkQuality
+	network        NetworkMeter
 	apiHealth      map[string]bool
 
-	width  int
-	height int
+	// Git context
+	showGit      bool
+	gitContext   GitContext
+	gitRefreshed time.Time
+	gitInterval  time.Duration
+	gitDir       string
+
+	sections []string
+	width    int
+	height   int
+
+	// Glyphs for the active terminal
+	styler *styles.AdaptiveStyler
+	glyphs styles.CharacterSet
+
+	// Screen reader mode describes the sections in words
+	accessibility *styles.AccessibilityManager
+}
+
+// GitContext describes the git state of the working directory
+type GitContext struct {
+	Branch string
+	Dirty  bool
 }
 
 // ProgressTracker manages multiple progress operations
@@ -91,6 +267,8 @@ type ProgressTracker struct {
 	operations map[string]*ProgressOperation
 	width      int
 	height     int
+	styler     *styles.AdaptiveStyler
+	glyphs     styles.CharacterSet
 }
 
 // ProgressOperation represents a single progress operation
@@ -111,21 +289,35 @@ type ProgressOperation struct {
 // LoadingSpinner provides various loading indicators
 type LoadingSpinner struct {
 	spinner    spinner.Model
+	style      string
 	message    string
 	subMessage string
 	showTime   bool
 	startTime  time.Time
 	width      int
 	height     int
+	styler     *styles.AdaptiveStyler
 }
 
 // NotificationCenter manages user notifications
 type NotificationCenter struct {
 	notifications []Notification
 	maxVisible    int
+	maxPerType    int
 	width         int
 	height        int
 	position      NotificationPosition
+
+	bellEnabled  bool
+	bellTypes    map[NotificationType]bool
+	bellInterval time.Duration
+	lastBell     time.Time
+	bellOutput   io.Writer
+
+	eventLog    []Notification
+	maxLogSize  int
+	showLog     bool
+	logViewport viewport.Model
 }
 
 // NotificationPosition represents where notifications appear
@@ -150,6 +342,7 @@ type TokenUsageDisplay struct {
 	currentModel  string
 	rateLimit     int
 	rateLimitUsed int
+	retryAt       time.Time
 	width         int
 	height        int
 	showDetails   bool
@@ -161,18 +354,89 @@ func NewStatusBar(width, height int) *StatusBar {
 		connectionState: ConnectionDisconnected,
 		apiHealth:       make(map[string]bool),
 		sessionStart:    time.Now(),
+		sections:        storage.DefaultStatusBarSections(),
+		gitInterval:     10 * time.Second,
+		gitDir:          ".",
 		width:           width,
 		height:          height,
+		glyphs:          styles.UnicodeCharacterSet,
 	}
 }
 
+// SetStyler adapts the status bar glyphs to the terminal's capabilities
+func (sb *StatusBar) SetStyler(styler *styles.AdaptiveStyler) {
+	sb.styler = styler
+	sb.glyphs = charsetFor(styler)
+}
+
+// SetAccessibility sets the manager whose screen reader mode replaces the
+// status glyphs with words
+func (sb *StatusBar) SetAccessibility(am *styles.AccessibilityManager) {
+	sb.accessibility = am
+}
+
+// NewStatusBarFromConfig creates a status bar showing the configured sections
+func NewStatusBarFromConfig(config *storage.Config, width, height int) *StatusBar {
+	sb := NewStatusBar(width, height)
+	if config != nil {
+		sb.SetSections(config.StatusBarSections)
+		sb.showGit = config.ShowGitContext
+	}
+	return sb
+}
+
+// SetShowGitContext enables or disables the git context section
+func (sb *StatusBar) SetShowGitContext(show bool) {
+	sb.showGit = show
+	if !show {
+		sb.gitContext = GitContext{}
+	}
+}
+
+// refreshGitContext reads the git state in the background
+func (sb *StatusBar) refreshGitContext() tea.Cmd {
+	dir := sb.gitDir
+	return func() tea.Msg {
+		return StatusMsg{Type: "git_context", Data: detectGitContext(dir)}
+	}
+}
+
+// SetSections sets which sections are shown and in what order
+func (sb *StatusBar) SetSections(sections []string) {
+	if len(sections) == 0 {
+		sections = storage.DefaultStatusBarSections()
+	}
+	sb.sections = append([]string(nil), sections...)
+}
+
+// shownSections returns the sections to show. Git context turned on shows
+// after the model even when the sections were saved before it existed.
+func (sb *StatusBar) shownSections() []string {
+	if !sb.showGit || slices.Contains(sb.sections, "git") {
+		return sb.sections
+	}
+
+	at := len(sb.sections)
+	if i := slices.Index(sb.sections, "model"); i >= 0 {
+		at = i + 1
+	}
+	return slices.Insert(slices.Clone(sb.sections), at, "git")
+}
+
 // Update handles status bar updates
 func (sb *StatusBar) Update(msg tea.Msg) (*StatusBar, tea.Cmd) {
+	var cmd tea.Cmd
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		sb.width = msg.Width
 		sb.height = msg.Height
 
+	case app.ProviderHealthMsg:
+		healthy := msg.Healthy()
+		cmd = func() tea.Msg {
+			return StatusMsg{Type: "api_health", Data: healthy}
+		}
+
 	case StatusMsg:
 		switch msg.Type {
 		case "connection_state":
@@ -197,14 +461,31 @@ func (sb *StatusBar) Update(msg tea.Msg) (*StatusBar, tea.Cmd) {
 				} else {
 					sb.avgLatency = (sb.avgLatency + latency) / 2
 				}
+				cmd = networkQualityCmd(sb.network.Record(latency, false))
 			}
+		case "request_failed":
+			latency, _ := msg.Data.(time.Duration)
+			cmd = networkQualityCmd(sb.network.Record(latency, true))
 		case "cost_update":
 			if cost, ok := msg.Data.(float64); ok {
 				sb.estimatedCost += cost
 			}
 		case "network_quality":
-			if quality, ok := msg.Data.(int); ok {
+			switch quality := msg.Data.(type) {
+			case NetworkQuality:
 				sb.networkQuality = quality
+			case int:
+				sb.networkQuality = NetworkQualityFromScore(quality)
+			}
+		case "stream_throughput":
+			if throughput, ok := msg.Data.(StreamThroughput); ok {
+				sb.tokensPerSecond = throughput.TokensPerSecond()
+			}
+		case "stream_start", "stream_end":
+			sb.tokensPerSecond = 0
+		case "git_context":
+			if gitContext, ok := msg.Data.(GitContext); ok {
+				sb.gitContext = gitContext
 			}
 		case "api_health":
 			if healthData, ok := msg.Data.(map[string]bool); ok {
@@ -218,33 +499,146 @@ func (sb *StatusBar) Update(msg tea.Msg) (*StatusBar, tea.Cmd) {
 	// Update session duration
 	sb.sessionDuration = time.Since(sb.sessionStart)
 
-	return sb, nil
+	// Refresh the cached git context on an interval
+	if sb.showGit && time.Since(sb.gitRefreshed) >= sb.gitInterval {
+		sb.gitRefreshed = time.Now()
+		return sb, tea.Batch(cmd, sb.refreshGitContext())
+	}
+
+	return sb, cmd
 }
 
 // View renders the status bar
 func (sb *StatusBar) View() string {
-	var sections []string
-
-	// Connection status
-	sections = append(sections, sb.renderConnectionStatus())
+	if sb.accessibility.IsScreenReaderEnabled() {
+		return sb.describe()
+	}
 
-	// Model info
-	if sb.currentModel != "" {
-		sections = append(sections, sb.renderModelInfo())
+	separator := StatusSeparatorStyle.Render(" " + sb.glyphs.Separator + " ")
+	barStyle := StatusBarStyle
+	if sb.styler != nil {
+		barStyle = barStyle.BorderStyle(sb.styler.GetOptimalBorder())
 	}
+	frame := barStyle.GetHorizontalFrameSize()
 
-	// Usage stats
-	sections = append(sections, sb.renderUsageStats())
+	var rendered []string
+	for _, section := range sb.shownSections() {
+		content := sb.renderSection(section)
+		if content == "" {
+			continue
+		}
 
-	// Performance metrics
-	sections = append(sections, sb.renderPerformanceMetrics())
+		// Drop sections that would overflow the available width
+		if sb.width > 0 {
+			candidate := strings.Join(append(rendered, content), separator)
+			if lipgloss.Width(candidate)+frame > sb.width {
+				continue
+			}
+		}
+		rendered = append(rendered, content)
+	}
 
-	// System status
-	sections = append(sections, sb.renderSystemStatus())
+	return optimizeOutput(sb.styler, barStyle.Render(strings.Join(rendered, separator)))
+}
 
-	content := strings.Join(sections, StatusSeparatorStyle.Render(" │ "))
+// describe renders the status bar as a labeled line of text, one clause
+// per section, for screen readers
+func (sb *StatusBar) describe() string {
+	var parts []string
+	for _, section := range sb.shownSections() {
+		if text := sb.describeSection(section); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return sb.accessibility.CreateLandmark("status", strings.Join(parts, "; "))
+}
 
-	return StatusBarStyle.Render(content)
+// describeSection puts a status bar section into words
+func (sb *StatusBar) describeSection(section string) string {
+	switch section {
+	case "connection":
+		switch sb.connectionState {
+		case ConnectionConnecting:
+			return "Connecting"
+		case ConnectionConnected:
+			return "Connected"
+		case ConnectionError:
+			return "Connection error"
+		default:
+			return "Disconnected"
+		}
+	case "model":
+		if sb.currentModel == "" {
+			return ""
+		}
+		if sb.currentProvider != "" {
+			return fmt.Sprintf("Model %s from %s", sb.currentModel, sb.currentProvider)
+		}
+		return "Model " + sb.currentModel
+	case "git":
+		if !sb.showGit || sb.gitContext.Branch == "" {
+			return ""
+		}
+		if sb.gitContext.Dirty {
+			return "Branch " + sb.gitContext.Branch + " with uncommitted changes"
+		}
+		return "Branch " + sb.gitContext.Branch
+	case "usage":
+		var parts []string
+		if sb.tokenCount > 0 {
+			parts = append(parts, fmt.Sprintf("%s tokens", humanize.Comma(int64(sb.tokenCount))))
+		}
+		if sb.estimatedCost > 0 {
+			parts = append(parts, fmt.Sprintf("cost $%.4f", sb.estimatedCost))
+		}
+		if sb.requestCount > 0 {
+			parts = append(parts, fmt.Sprintf("%d requests", sb.requestCount))
+		}
+		return strings.Join(parts, ", ")
+	case "performance":
+		var parts []string
+		if sb.tokensPerSecond > 0 {
+			parts = append(parts, fmt.Sprintf("%.0f tokens per second", sb.tokensPerSecond))
+		}
+		if sb.avgLatency > 0 {
+			parts = append(parts, fmt.Sprintf("average latency %d milliseconds", sb.avgLatency.Milliseconds()))
+		}
+		if sb.queuedRequests > 0 {
+			parts = append(parts, fmt.Sprintf("%d queued", sb.queuedRequests))
+		}
+		return strings.Join(parts, ", ")
+	case "system":
+		text := "Session time " + sb.sessionDuration.Round(time.Second).String()
+		if sb.networkQuality.Tier != NetworkUnknown {
+			text += ", network " + sb.networkQuality.Tier.String()
+		}
+		return text
+	default:
+		return ""
+	}
+}
+
+// renderSection renders a status bar section by name
+func (sb *StatusBar) renderSection(section string) string {
+	switch section {
+	case "connection":
+		return sb.renderConnectionStatus()
+	case "model":
+		if sb.currentModel == "" {
+			return ""
+		}
+		return sb.renderModelInfo()
+	case "git":
+		return sb.renderGitContext()
+	case "usage":
+		return sb.renderUsageStats()
+	case "performance":
+		return sb.renderPerformanceMetrics()
+	case "system":
+		return sb.renderSystemStatus()
+	default:
+		return ""
+	}
 }
 
 // renderConnectionStatus renders the connection status indicator
@@ -254,16 +648,16 @@ func (sb *StatusBar) renderConnectionStatus() string {
 
 	switch sb.connectionState {
 	case ConnectionDisconnected:
-		status = "●"
+		status = sb.glyphs.StatusDot
 		style = StatusDisconnectedStyle
 	case ConnectionConnecting:
-		status = "◐"
+		status = sb.glyphs.StatusPending
 		style = StatusConnectingStyle
 	case ConnectionConnected:
-		status = "●"
+		status = sb.glyphs.StatusDot
 		style = StatusConnectedStyle
 	case ConnectionError:
-		status = "●"
+		status = sb.glyphs.StatusDot
 		style = StatusErrorStyle
 	}
 
@@ -278,6 +672,97 @@ func (sb *StatusBar) renderModelInfo() string {
 	return ModelInfoStyle.Render(sb.currentModel)
 }
 
+// renderGitContext renders the current branch and dirty marker
+func (sb *StatusBar) renderGitContext() string {
+	if !sb.showGit || sb.gitContext.Branch == "" {
+		return ""
+	}
+
+	branch := sb.gitContext.Branch
+	if sb.gitContext.Dirty {
+		branch += "*"
+	}
+	return GitContextStyle.Render(sb.glyphs.Branch + " " + branch)
+}
+
+// detectGitContext returns the git state for dir, or an empty context
+// outside a repository
+func detectGitContext(dir string) GitContext {
+	gitDir := findGitDir(dir)
+	if gitDir == "" {
+		return GitContext{}
+	}
+
+	head, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return GitContext{}
+	}
+
+	gitContext := GitContext{Branch: parseGitHead(string(head))}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain", "--untracked-files=no")
+	cmd.Dir = dir
+	if output, err := cmd.Output(); err == nil {
+		gitContext.Dirty = len(strings.TrimSpace(string(output))) > 0
+	}
+
+	return gitContext
+}
+
+// findGitDir walks up from dir looking for a .git directory or file
+func findGitDir(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		candidate := filepath.Join(abs, ".git")
+		if info, err := os.Stat(candidate); err == nil {
+			if info.IsDir() {
+				return candidate
+			}
+
+			// Worktrees and submodules use a .git file pointing at the real dir
+			if data, err := os.ReadFile(candidate); err == nil {
+				line := strings.TrimSpace(string(data))
+				if strings.HasPrefix(line, "gitdir:") {
+					target := strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+					if !filepath.IsAbs(target) {
+						target = filepath.Join(abs, target)
+					}
+					return target
+				}
+			}
+			return ""
+		}
+
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return ""
+		}
+		abs = parent
+	}
+}
+
+// parseGitHead extracts the branch name from the contents of .git/HEAD,
+// falling back to a short commit hash for a detached HEAD
+func parseGitHead(head string) string {
+	head = strings.TrimSpace(head)
+	if ref, ok := strings.CutPrefix(head, "ref:"); ok {
+		ref = strings.TrimSpace(ref)
+		return strings.TrimPrefix(ref, "refs/heads/")
+	}
+
+	if len(head) >= 7 {
+		return head[:7]
+	}
+	return head
+}
+
 // renderUsageStats renders usage statistics
 func (sb *StatusBar) renderUsageStats() string {
 	var parts []string
@@ -298,13 +783,17 @@ func (sb *StatusBar) renderUsageStats() string {
 		return ""
 	}
 
-	return UsageStatsStyle.Render(strings.Join(parts, " • "))
+	return UsageStatsStyle.Render(strings.Join(parts, " "+sb.glyphs.Bullet+" "))
 }
 
 // renderPerformanceMetrics renders performance information
 func (sb *StatusBar) renderPerformanceMetrics() string {
 	var parts []string
 
+	if sb.tokensPerSecond > 0 {
+		parts = append(parts, fmt.Sprintf("%.0f tok/s", sb.tokensPerSecond))
+	}
+
 	if sb.avgLatency > 0 {
 		parts = append(parts, fmt.Sprintf("~%dms", sb.avgLatency.Milliseconds()))
 	}
@@ -317,34 +806,40 @@ func (sb *StatusBar) renderPerformanceMetrics() string {
 		return ""
 	}
 
-	return PerformanceStyle.Render(strings.Join(parts, " • "))
+	return PerformanceStyle.Render(strings.Join(parts, " "+sb.glyphs.Bullet+" "))
 }
 
 // renderSystemStatus renders system status information
 func (sb *StatusBar) renderSystemStatus() string {
-	var parts []string
-
 	// Session duration
 	duration := sb.sessionDuration.Round(time.Second)
-	parts = append(parts, fmt.Sprintf("⏱ %s", duration))
+	status := SystemStatusStyle.Render(fmt.Sprintf("%s %s", sb.glyphs.Timer, duration))
 
-	// Network quality
-	if sb.networkQuality > 0 {
-		var qualityIcon string
-		switch {
-		case sb.networkQuality >= 90:
-			qualityIcon = "📶"
-		case sb.networkQuality >= 70:
-			qualityIcon = "📶"
-		case sb.networkQuality >= 50:
-			qualityIcon = "📶"
-		default:
-			qualityIcon = "📶"
-		}
-		parts = append(parts, qualityIcon)
+	// Network quality, colored by tier
+	if signal := sb.renderNetworkQuality(); signal != "" {
+		status += " " + signal
+	}
+	return status
+}
+
+// renderNetworkQuality renders the signal glyph for the network quality
+// tier in the tier's color
+func (sb *StatusBar) renderNetworkQuality() string {
+	tier := sb.networkQuality.Tier
+	if tier == NetworkUnknown || int(tier) > len(sb.glyphs.Signal) {
+		return ""
 	}
 
-	return SystemStatusStyle.Render(strings.Join(parts, " "))
+	style := StatusConnectedStyle
+	switch tier {
+	case NetworkPoor:
+		style = StatusErrorStyle
+	case NetworkFair:
+		style = StatusConnectingStyle
+	case NetworkGood:
+		style = NetworkGoodStyle
+	}
+	return style.Render(sb.glyphs.Signal[tier-1])
 }
 
 // NewProgressTracker creates a new progress tracker
@@ -353,6 +848,26 @@ func NewProgressTracker(width, height int) *ProgressTracker {
 		operations: make(map[string]*ProgressOperation),
 		width:      width,
 		height:     height,
+		glyphs:     styles.UnicodeCharacterSet,
+	}
+}
+
+// SetStyler adapts progress bar glyphs to the terminal's capabilities
+func (pt *ProgressTracker) SetStyler(styler *styles.AdaptiveStyler) {
+	pt.styler = styler
+	pt.glyphs = charsetFor(styler)
+	for _, op := range pt.operations {
+		pt.applyGlyphs(&op.progress)
+	}
+}
+
+// applyGlyphs sets the fill characters of a progress bar
+func (pt *ProgressTracker) applyGlyphs(prog *progress.Model) {
+	if full := []rune(pt.glyphs.ProgressFull); len(full) > 0 {
+		prog.Full = full[0]
+	}
+	if empty := []rune(pt.glyphs.ProgressEmpty); len(empty) > 0 {
+		prog.Empty = empty[0]
 	}
 }
 
@@ -360,6 +875,7 @@ func NewProgressTracker(width, height int) *ProgressTracker {
 func (pt *ProgressTracker) AddOperation(id, title string, total int64, unit string) {
 	prog := progress.New(progress.WithDefaultGradient())
 	prog.Width = pt.width - 20 // Leave space for text
+	pt.applyGlyphs(&prog)
 
 	pt.operations[id] = &ProgressOperation{
 		ID:         id,
@@ -475,7 +991,12 @@ func (pt *ProgressTracker) View() string {
 		content.WriteString("\n")
 	}
 
-	return ProgressContainerStyle.Render(content.String())
+	containerStyle := ProgressContainerStyle
+	if pt.styler != nil {
+		containerStyle = containerStyle.Border(pt.styler.GetOptimalBorder())
+	}
+
+	return optimizeOutput(pt.styler, containerStyle.Render(content.String()))
 }
 
 // renderOperation renders a single progress operation
@@ -516,7 +1037,7 @@ func (pt *ProgressTracker) renderOperation(op *ProgressOperation) string {
 	}
 
 	if len(details) > 0 {
-		content.WriteString(ProgressDetailsStyle.Render(strings.Join(details, " • ")))
+		content.WriteString(ProgressDetailsStyle.Render(strings.Join(details, " "+pt.glyphs.Bullet+" ")))
 	}
 
 	return content.String()
@@ -536,6 +1057,55 @@ func NewLoadingSpinner(width, height int) *LoadingSpinner {
 	}
 }
 
+// SetStyler adapts the spinner frames to the terminal's capabilities
+func (ls *LoadingSpinner) SetStyler(styler *styles.AdaptiveStyler) {
+	ls.styler = styler
+	ls.spinner.Spinner = spinnerFor(ls.style, styler)
+}
+
+// SetSpinnerStyle selects the spinner pattern by its config name
+func (ls *LoadingSpinner) SetSpinnerStyle(style string) {
+	ls.style = style
+	ls.spinner.Spinner = spinnerFor(style, ls.styler)
+}
+
+// spinnerFor resolves a spinner style name. Terminals without unicode get the
+// character set's line spinner whatever was configured.
+func spinnerFor(style string, styler *styles.AdaptiveStyler) spinner.Spinner {
+	if styler != nil && !styler.UsesUnicode() {
+		return spinner.Spinner{
+			Frames: charsetFor(styler).Spinner,
+			FPS:    time.Second / 10,
+		}
+	}
+
+	switch style {
+	case "line":
+		return spinner.Line
+	case "dot":
+		return spinner.Dot
+	case "minidot":
+		return spinner.MiniDot
+	case "jump":
+		return spinner.Jump
+	case "points":
+		return spinner.Points
+	case "moon":
+		return spinner.Moon
+	case "braille":
+		return spinner.Spinner{
+			Frames: styles.UnicodeCharacterSet.Spinner,
+			FPS:    time.Second / 10,
+		}
+	}
+
+	// Unconfigured: braille when the terminal is known, otherwise the bubbles default
+	if styler != nil {
+		return spinnerFor("braille", styler)
+	}
+	return spinner.Dot
+}
+
 // SetMessage sets the main loading message
 func (ls *LoadingSpinner) SetMessage(message string) {
 	ls.message = message
@@ -598,7 +1168,23 @@ func (ls *LoadingSpinner) View() string {
 		content.WriteString(SpinnerTimeStyle.Render(fmt.Sprintf("(%s)", elapsed)))
 	}
 
-	return SpinnerContainerStyle.Render(content.String())
+	return optimizeOutput(ls.styler, SpinnerContainerStyle.Render(content.String()))
+}
+
+// charsetFor returns the glyphs suited to a styler, defaulting to unicode
+func charsetFor(styler *styles.AdaptiveStyler) styles.CharacterSet {
+	if styler == nil {
+		return styles.UnicodeCharacterSet
+	}
+	return styler.GetOptimalCharset()
+}
+
+// optimizeOutput simplifies rendered output when the terminal needs plain characters
+func optimizeOutput(styler *styles.AdaptiveStyler, content string) string {
+	if styler == nil || !styler.GetPerformanceSettings().UseSimpleChars {
+		return content
+	}
+	return styler.OptimizeContent(content)
 }
 
 // NewNotificationCenter creates a new notification center
@@ -606,16 +1192,72 @@ func NewNotificationCenter(width, height int) *NotificationCenter {
 	return &NotificationCenter{
 		notifications: make([]Notification, 0),
 		maxVisible:    5,
+		maxPerType:    3,
 		width:         width,
 		height:        height,
 		position:      NotificationTopRight,
+		bellTypes: map[NotificationType]bool{
+			NotificationError:   true,
+			NotificationSuccess: true,
+		},
+		bellInterval: 2 * time.Second,
+		bellOutput:   os.Stdout,
+		eventLog:     make([]Notification, 0),
+		maxLogSize:   500,
+		logViewport:  viewport.New(width, height-4),
 	}
 }
 
-// AddNotification adds a new notification
+// AddNotification adds a new notification, coalescing it into an identical
+// active one when present
 func (nc *NotificationCenter) AddNotification(notification Notification) {
-	notification.ShowTime = time.Now()
+	now := time.Now()
+	nc.logEvent(notification, now)
+
+	for i, existing := range nc.notifications {
+		if existing.Title != notification.Title || existing.Message != notification.Message {
+			continue
+		}
+		if existing.Duration > 0 && now.Sub(existing.ShowTime) >= existing.Duration {
+			continue
+		}
+
+		if existing.Count < 1 {
+			existing.Count = 1
+		}
+		existing.Count++
+		existing.ShowTime = now
+
+		// Move the refreshed notification to the top of the stack
+		nc.notifications = append(nc.notifications[:i], nc.notifications[i+1:]...)
+		nc.notifications = append(nc.notifications, existing)
+		return
+	}
+
+	notification.ShowTime = now
+	if notification.Count < 1 {
+		notification.Count = 1
+	}
 	nc.notifications = append(nc.notifications, notification)
+	nc.ringBell(notification.Type, now)
+
+	// Drop the oldest notifications of this type beyond the per-type cap
+	if nc.maxPerType > 0 {
+		sameType := 0
+		for _, n := range nc.notifications {
+			if n.Type == notification.Type {
+				sameType++
+			}
+		}
+		for i := 0; sameType > nc.maxPerType && i < len(nc.notifications); {
+			if nc.notifications[i].Type == notification.Type {
+				nc.notifications = append(nc.notifications[:i], nc.notifications[i+1:]...)
+				sameType--
+				continue
+			}
+			i++
+		}
+	}
 
 	// Remove old notifications if we exceed the limit
 	if len(nc.notifications) > 20 {
@@ -623,6 +1265,72 @@ func (nc *NotificationCenter) AddNotification(notification Notification) {
 	}
 }
 
+// SetMaxPerType sets how many notifications of one type may be shown at once
+func (nc *NotificationCenter) SetMaxPerType(max int) {
+	nc.maxPerType = max
+}
+
+// SetBell enables or disables the terminal bell for important notifications
+func (nc *NotificationCenter) SetBell(enabled bool) {
+	nc.bellEnabled = enabled
+}
+
+// ringBell writes the terminal bell for configured types, at most once per
+// bell interval so error storms stay quiet
+func (nc *NotificationCenter) ringBell(notificationType NotificationType, now time.Time) {
+	if !nc.bellEnabled || !nc.bellTypes[notificationType] || nc.bellOutput == nil {
+		return
+	}
+	if !nc.lastBell.IsZero() && now.Sub(nc.lastBell) < nc.bellInterval {
+		return
+	}
+
+	nc.lastBell = now
+	fmt.Fprint(nc.bellOutput, "\a")
+}
+
+// logEvent records a notification in the event log, which outlives toasts
+func (nc *NotificationCenter) logEvent(notification Notification, now time.Time) {
+	notification.ShowTime = now
+	nc.eventLog = append(nc.eventLog, notification)
+	if len(nc.eventLog) > nc.maxLogSize {
+		nc.eventLog = nc.eventLog[len(nc.eventLog)-nc.maxLogSize:]
+	}
+	if nc.showLog {
+		nc.updateLogContent()
+	}
+}
+
+// EventLog returns every recorded notification, oldest first
+func (nc *NotificationCenter) EventLog() []Notification {
+	return nc.eventLog
+}
+
+// ClearEventLog removes all entries from the event log
+func (nc *NotificationCenter) ClearEventLog() {
+	nc.eventLog = nc.eventLog[:0]
+	nc.updateLogContent()
+}
+
+// ToggleEventLog shows or hides the event log panel
+func (nc *NotificationCenter) ToggleEventLog() {
+	nc.showLog = !nc.showLog
+	if nc.showLog {
+		nc.updateLogContent()
+		nc.logViewport.GotoBottom()
+	}
+}
+
+// IsEventLogVisible reports whether the event log panel is open
+func (nc *NotificationCenter) IsEventLogVisible() bool {
+	return nc.showLog
+}
+
+// Notifications returns the active notifications, oldest first
+func (nc *NotificationCenter) Notifications() []Notification {
+	return nc.notifications
+}
+
 // RemoveNotification removes a notification by ID
 func (nc *NotificationCenter) RemoveNotification(id string) {
 	for i, notification := range nc.notifications {
@@ -641,9 +1349,73 @@ func (nc *NotificationCenter) Update(msg tea.Msg) (*NotificationCenter, tea.Cmd)
 	case tea.WindowSizeMsg:
 		nc.width = msg.Width
 		nc.height = msg.Height
+		nc.logViewport.Width = msg.Width
+		nc.logViewport.Height = msg.Height - 4
+
+	case tea.KeyMsg:
+		if nc.showLog {
+			switch msg.String() {
+			case "esc", "q":
+				nc.showLog = false
+			case "c":
+				nc.ClearEventLog()
+			default:
+				var cmd tea.Cmd
+				nc.logViewport, cmd = nc.logViewport.Update(msg)
+				cmds = append(cmds, cmd)
+			}
+		}
+
+	case app.NotificationLogMsg:
+		if msg.Clear {
+			nc.ClearEventLog()
+		} else {
+			nc.ToggleEventLog()
+		}
+
+	case app.ProviderHealthMsg:
+		for _, result := range msg.Problems() {
+			nc.AddNotification(Notification{
+				Type:     NotificationWarning,
+				Title:    fmt.Sprintf("%s unavailable", result.Provider),
+				Message:  result.Message(),
+				Duration: 15 * time.Second,
+				Actions: []NotificationAction{
+					{Label: "/settings providers to update the key", Command: "/settings providers"},
+				},
+			})
+		}
+
+	case app.ProviderAuthErrorMsg:
+		nc.AddNotification(Notification{
+			Type:     NotificationError,
+			Title:    fmt.Sprintf("%s key rejected", msg.Provider.DisplayName()),
+			Message:  msg.Message,
+			Duration: 15 * time.Second,
+			Actions: []NotificationAction{
+				{Label: "/settings providers to update the key", Command: "/settings providers"},
+			},
+		})
+
+	case app.ContextWarningMsg:
+		nc.AddNotification(Notification{
+			Type:     NotificationWarning,
+			Title:    "Context window almost full",
+			Message:  fmt.Sprintf("%s: ~%d of %d tokens used", msg.Model, msg.Used, msg.Window),
+			Duration: 10 * time.Second,
+			Actions: []NotificationAction{
+				{Label: "/summarize older turns", Command: "/summarize"},
+				{Label: "/trim oldest turns", Command: "/trim"},
+				{Label: "/clear for a new session", Command: "/clear"},
+			},
+		})
 
 	case StatusMsg:
 		switch msg.Type {
+		case "notification_log_toggle":
+			nc.ToggleEventLog()
+		case "notification_log_clear":
+			nc.ClearEventLog()
 		case "notification_add":
 			if notification, ok := msg.Data.(Notification); ok {
 				nc.AddNotification(notification)
@@ -652,6 +1424,10 @@ func (nc *NotificationCenter) Update(msg tea.Msg) (*NotificationCenter, tea.Cmd)
 			if id, ok := msg.Data.(string); ok {
 				nc.RemoveNotification(id)
 			}
+		case "notification_bell":
+			if enabled, ok := msg.Data.(bool); ok {
+				nc.SetBell(enabled)
+			}
 		}
 	}
 
@@ -670,28 +1446,92 @@ func (nc *NotificationCenter) Update(msg tea.Msg) (*NotificationCenter, tea.Cmd)
 	return nc, tea.Batch(cmds...)
 }
 
+// SetPosition sets where the notification stack is anchored
+func (nc *NotificationCenter) SetPosition(position NotificationPosition) {
+	nc.position = position
+}
+
 // View renders visible notifications
 func (nc *NotificationCenter) View() string {
+	if nc.showLog {
+		return nc.renderEventLog()
+	}
+
 	if len(nc.notifications) == 0 {
 		return ""
 	}
 
-	// Show only the most recent notifications
+	// Show only the most recent notifications, newest first
 	visible := nc.notifications
 	if len(visible) > nc.maxVisible {
 		visible = visible[len(visible)-nc.maxVisible:]
 	}
+	stack := make([]Notification, 0, len(visible))
+	for i := len(visible) - 1; i >= 0; i-- {
+		stack = append(stack, visible[i])
+	}
 
-	var content strings.Builder
+	// Bottom-anchored stacks grow upwards, so the newest sits nearest the edge
+	if nc.isBottomAnchored() {
+		for i, j := 0, len(stack)-1; i < j; i, j = i+1, j-1 {
+			stack[i], stack[j] = stack[j], stack[i]
+		}
+	}
 
-	for i, notification := range visible {
-		content.WriteString(nc.renderNotification(notification))
-		if i < len(visible)-1 {
-			content.WriteString("\n")
+	hAlign, _ := nc.alignment()
+	rendered := make([]string, 0, len(stack))
+	for _, notification := range stack {
+		block := nc.renderNotification(notification)
+		if nc.width > 0 {
+			block = lipgloss.PlaceHorizontal(nc.width, hAlign, block)
 		}
+		rendered = append(rendered, block)
 	}
 
-	return nc.positionContent(content.String())
+	return nc.positionContent(strings.Join(rendered, "\n"))
+}
+
+// renderEventLog renders the scrollable event log panel
+func (nc *NotificationCenter) renderEventLog() string {
+	title := NotificationLogTitleStyle.Render(fmt.Sprintf("Notifications (%d)", len(nc.eventLog)))
+	footer := NotificationLogFooterStyle.Render("↑/↓ scroll • c clear • esc close")
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, nc.logViewport.View(), footer)
+}
+
+// updateLogContent refreshes the event log viewport content
+func (nc *NotificationCenter) updateLogContent() {
+	if len(nc.eventLog) == 0 {
+		nc.logViewport.SetContent(NotificationLogFooterStyle.Render("No notifications yet"))
+		return
+	}
+
+	lines := make([]string, 0, len(nc.eventLog))
+	for _, notification := range nc.eventLog {
+		line := fmt.Sprintf("%s  %-7s  %s",
+			NotificationLogTimeStyle.Render(notification.ShowTime.Format("15:04:05")),
+			notificationTypeName(notification.Type),
+			notification.Title)
+		if notification.Message != "" {
+			line += " — " + notification.Message
+		}
+		lines = append(lines, line)
+	}
+	nc.logViewport.SetContent(strings.Join(lines, "\n"))
+}
+
+// notificationTypeName returns a short label for a notification type
+func notificationTypeName(notificationType NotificationType) string {
+	switch notificationType {
+	case NotificationSuccess:
+		return "success"
+	case NotificationWarning:
+		return "warning"
+	case NotificationError:
+		return "error"
+	default:
+		return "info"
+	}
 }
 
 // renderNotification renders a single notification
@@ -718,6 +1558,9 @@ func (nc *NotificationCenter) renderNotification(notification Notification) stri
 
 	// Title with icon
 	title := fmt.Sprintf("%s %s", icon, notification.Title)
+	if notification.Count > 1 {
+		title = fmt.Sprintf("%s (x%d)", title, notification.Count)
+	}
 	content.WriteString(NotificationTitleStyle.Render(title))
 
 	// Message
@@ -741,32 +1584,37 @@ func (nc *NotificationCenter) renderNotification(notification Notification) stri
 
 // positionContent positions the notifications based on the position setting
 func (nc *NotificationCenter) positionContent(content string) string {
-	switch nc.position {
-	case NotificationTopRight:
+	hAlign, vAlign := nc.alignment()
+	if nc.width <= 0 || nc.height <= 0 {
 		return lipgloss.NewStyle().
-			Align(lipgloss.Right).
+			Align(hAlign).
 			Render(content)
+	}
+
+	return lipgloss.Place(nc.width, nc.height, hAlign, vAlign, content)
+}
+
+// alignment returns the horizontal and vertical placement for the position
+func (nc *NotificationCenter) alignment() (lipgloss.Position, lipgloss.Position) {
+	switch nc.position {
 	case NotificationTopLeft:
-		return lipgloss.NewStyle().
-			Align(lipgloss.Left).
-			Render(content)
+		return lipgloss.Left, lipgloss.Top
 	case NotificationBottomRight:
-		return lipgloss.NewStyle().
-			Align(lipgloss.Right).
-			Render(content)
+		return lipgloss.Right, lipgloss.Bottom
 	case NotificationBottomLeft:
-		return lipgloss.NewStyle().
-			Align(lipgloss.Left).
-			Render(content)
+		return lipgloss.Left, lipgloss.Bottom
 	case NotificationPositionCenter:
-		return lipgloss.NewStyle().
-			Align(lipgloss.Center).
-			Render(content)
+		return lipgloss.Center, lipgloss.Center
 	default:
-		return content
+		return lipgloss.Right, lipgloss.Top
 	}
 }
 
+// isBottomAnchored reports whether the stack is anchored to the bottom edge
+func (nc *NotificationCenter) isBottomAnchored() bool {
+	return nc.position == NotificationBottomRight || nc.position == NotificationBottomLeft
+}
+
 // NewTokenUsageDisplay creates a new token usage display
 func NewTokenUsageDisplay(width, height int) *TokenUsageDisplay {
 	return &TokenUsageDisplay{
@@ -808,11 +1656,18 @@ func (tud *TokenUsageDisplay) Update(msg tea.Msg) (*TokenUsageDisplay, tea.Cmd)
 			if cost, ok := msg.Data.(float64); ok {
 				tud.totalCost = cost
 			}
+		case "usage_totals":
+			if totals, ok := msg.Data.(*storage.UsageTotals); ok {
+				tud.SetTotals(totals)
+			}
 		case "model_current":
 			if model, ok := msg.Data.(string); ok {
 				tud.currentModel = model
 			}
 		case "rate_limit":
+			if limit, ok := msg.Data.(*api.RateLimit); ok && limit != nil {
+				tud.SetRateLimit(limit)
+			}
 			if data, ok := msg.Data.(map[string]int); ok {
 				if limit, exists := data["limit"]; exists {
 					tud.rateLimit = limit
@@ -829,6 +1684,47 @@ func (tud *TokenUsageDisplay) Update(msg tea.Msg) (*TokenUsageDisplay, tea.Cmd)
 	return tud, nil
 }
 
+// SetRateLimit shows the quota parsed from a provider's response headers,
+// counting down its Retry-After when there is one
+func (tud *TokenUsageDisplay) SetRateLimit(limit *api.RateLimit) {
+	if limit.Limit > 0 {
+		tud.rateLimit = limit.Limit
+		tud.rateLimitUsed = limit.Used()
+	}
+	tud.retryAt = time.Time{}
+	if limit.RetryAfter > 0 {
+		tud.retryAt = time.Now().Add(limit.RetryAfter)
+	}
+}
+
+// RetryIn returns how long until the provider accepts requests again, or
+// zero when not rate limited
+func (tud *TokenUsageDisplay) RetryIn() time.Duration {
+	if wait := time.Until(tud.retryAt); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// SetTotals sets the all-time totals shown by the display
+func (tud *TokenUsageDisplay) SetTotals(totals *storage.UsageTotals) {
+	if totals == nil {
+		return
+	}
+	tud.totalTokens = totals.TotalTokens
+	tud.totalCost = totals.TotalCost
+}
+
+// LoadTotals loads persisted all-time totals from the usage store
+func (tud *TokenUsageDisplay) LoadTotals(store *storage.UsageStore) error {
+	totals, err := store.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load usage totals: %w", err)
+	}
+	tud.SetTotals(totals)
+	return nil
+}
+
 // View renders the token usage display
 func (tud *TokenUsageDisplay) View() string {
 	if !tud.showDetails {
@@ -849,6 +1745,10 @@ func (tud *TokenUsageDisplay) renderCompact() string {
 		parts = append(parts, fmt.Sprintf("~$%.4f", tud.estimatedCost))
 	}
 
+	if wait := tud.RetryIn(); wait > 0 {
+		parts = append(parts, TokenUsageRateLimitStyle.Render(formatRetryIn(wait)))
+	}
+
 	if len(parts) == 0 {
 		return ""
 	}
@@ -856,6 +1756,12 @@ func (tud *TokenUsageDisplay) renderCompact() string {
 	return TokenUsageCompactStyle.Render(strings.Join(parts, " • "))
 }
 
+// formatRetryIn renders a cooldown as "retry in 12s", rounding up so it
+// never reads zero while still waiting
+func formatRetryIn(wait time.Duration) string {
+	return fmt.Sprintf("retry in %ds", int((wait+time.Second-1)/time.Second))
+}
+
 // renderDetailed renders a detailed token usage view
 func (tud *TokenUsageDisplay) renderDetailed() string {
 	var content strings.Builder
@@ -907,6 +1813,10 @@ func (tud *TokenUsageDisplay) renderDetailed() string {
 		content.WriteString("\n")
 		content.WriteString(prog.ViewAs(rateLimitProgress))
 	}
+	if wait := tud.RetryIn(); wait > 0 {
+		content.WriteString("\n")
+		content.WriteString(TokenUsageRateLimitStyle.Render(formatRetryIn(wait)))
+	}
 
 	// Current model
 	if tud.currentModel != "" {
@@ -931,6 +1841,9 @@ var (
 	StatusSeparatorStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color("#D1D5DB"))
 
+	GitContextStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#F97316"))
+
 	StatusConnectedStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color("#10B981"))
 
@@ -943,6 +1856,9 @@ var (
 	StatusErrorStyle = lipgloss.NewStyle().
 				Foreground(lipgloss.Color("#EF4444"))
 
+	NetworkGoodStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#84CC16"))
+
 	ModelInfoStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("#7C3AED")).
 			Bold(true)
@@ -1029,6 +1945,18 @@ var (
 	NotificationMessageStyle = lipgloss.NewStyle().
 					PaddingTop(1)
 
+	NotificationLogTitleStyle = lipgloss.NewStyle().
+					Foreground(lipgloss.Color("#7C3AED")).
+					Bold(true).
+					MarginBottom(1)
+
+	NotificationLogTimeStyle = lipgloss.NewStyle().
+					Foreground(lipgloss.Color("#6B7280"))
+
+	NotificationLogFooterStyle = lipgloss.NewStyle().
+					Foreground(lipgloss.Color("#9CA3AF")).
+					Italic(true)
+
 	// Token usage styles
 	TokenUsageContainerStyle = lipgloss.NewStyle().
 					Border(lipgloss.RoundedBorder()).
@@ -0,0 +1,370 @@
+package components
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"unicode"
+
+	"github.com/john/klip/internal/api"
+	"github.com/john/klip/internal/app"
+	"github.com/john/klip/internal/storage"
+	"github.com/john/klip/internal/ui/styles"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationCenterStackOrder(t *testing.T) {
+	render := func(position NotificationPosition) string {
+		nc := NewNotificationCenter(60, 30)
+		nc.SetPosition(position)
+		nc.AddNotification(Notification{ID: "1", Type: NotificationInfo, Title: "older"})
+		nc.AddNotification(Notification{ID: "2", Type: NotificationInfo, Title: "newer"})
+		return nc.View()
+	}
+
+	top := render(NotificationTopRight)
+	bottom := render(NotificationBottomRight)
+
+	// Top-anchored stacks show the newest first
+	assert.Less(t, strings.Index(top, "newer"), strings.Index(top, "older"))
+
+	// Bottom-anchored stacks keep the newest nearest the bottom edge
+	assert.Greater(t, strings.Index(bottom, "newer"), strings.Index(bottom, "older"))
+}
+
+func TestNotificationCenterVerticalPlacement(t *testing.T) {
+	nc := NewNotificationCenter(60, 30)
+	nc.AddNotification(Notification{ID: "1", Type: NotificationInfo, Title: "hello"})
+
+	nc.SetPosition(NotificationTopLeft)
+	topLines := strings.Split(nc.View(), "\n")
+	assert.Len(t, topLines, 30)
+	assert.Equal(t, "", strings.TrimSpace(topLines[len(topLines)-1]))
+
+	nc.SetPosition(NotificationBottomLeft)
+	bottomLines := strings.Split(nc.View(), "\n")
+	assert.Len(t, bottomLines, 30)
+	assert.Equal(t, "", strings.TrimSpace(bottomLines[0]))
+}
+
+func TestNotificationCenterCoalescesDuplicates(t *testing.T) {
+	nc := NewNotificationCenter(60, 30)
+	for i := 0; i < 3; i++ {
+		nc.AddNotification(Notification{Type: NotificationError, Title: "Network error", Message: "connection reset"})
+	}
+
+	notifications := nc.Notifications()
+	assert.Len(t, notifications, 1)
+	assert.Equal(t, 3, notifications[0].Count)
+	assert.Contains(t, nc.View(), "(x3)")
+}
+
+func TestNotificationCenterMaxPerType(t *testing.T) {
+	nc := NewNotificationCenter(60, 30)
+	nc.SetMaxPerType(2)
+	nc.AddNotification(Notification{Type: NotificationWarning, Title: "first"})
+	nc.AddNotification(Notification{Type: NotificationInfo, Title: "info"})
+	nc.AddNotification(Notification{Type: NotificationWarning, Title: "second"})
+	nc.AddNotification(Notification{Type: NotificationWarning, Title: "third"})
+
+	var titles []string
+	for _, n := range nc.Notifications() {
+		titles = append(titles, n.Title)
+	}
+	assert.Equal(t, []string{"info", "second", "third"}, titles)
+}
+
+func TestNotificationCenterBell(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		typ      NotificationType
+		expected string
+	}{
+		{"disabled", false, NotificationError, ""},
+		{"error", true, NotificationError, "\a"},
+		{"success", true, NotificationSuccess, "\a"},
+		{"info", true, NotificationInfo, ""},
+		{"warning", true, NotificationWarning, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			nc := NewNotificationCenter(60, 30)
+			nc.bellOutput = &out
+			nc.SetBell(tt.enabled)
+
+			nc.AddNotification(Notification{Type: tt.typ, Title: tt.name})
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+}
+
+func TestNotificationCenterBellThrottled(t *testing.T) {
+	var out bytes.Buffer
+	nc := NewNotificationCenter(60, 30)
+	nc.bellOutput = &out
+	nc.SetBell(true)
+
+	nc.AddNotification(Notification{Type: NotificationError, Title: "first"})
+	nc.AddNotification(Notification{Type: NotificationError, Title: "second"})
+	assert.Equal(t, "\a", out.String())
+}
+
+func TestNotificationCenterEventLogKeepsExpired(t *testing.T) {
+	nc := NewNotificationCenter(60, 30)
+	nc.AddNotification(Notification{ID: "1", Type: NotificationError, Title: "API error", Duration: time.Millisecond})
+	nc.notifications[0].ShowTime = time.Now().Add(-time.Second)
+
+	nc, _ = nc.Update(StatusMsg{Type: "tick"})
+	assert.Empty(t, nc.Notifications())
+	assert.Len(t, nc.EventLog(), 1)
+
+	nc, _ = nc.Update(app.NotificationLogMsg{})
+	assert.True(t, nc.IsEventLogVisible())
+	assert.Contains(t, nc.View(), "API error")
+
+	nc, _ = nc.Update(app.NotificationLogMsg{Clear: true})
+	assert.Empty(t, nc.EventLog())
+}
+
+func TestStreamThroughput(t *testing.T) {
+	tests := []struct {
+		name     string
+		sample   StreamThroughput
+		expected float64
+	}{
+		{"steady", StreamThroughput{Tokens: 84, Elapsed: 2 * time.Second}, 42},
+		{"sub-second", StreamThroughput{Tokens: 10, Elapsed: 500 * time.Millisecond}, 20},
+		{"no time", StreamThroughput{Tokens: 10}, 0},
+		{"no tokens", StreamThroughput{Elapsed: time.Second}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, tt.sample.TokensPerSecond(), 0.001)
+		})
+	}
+}
+
+func TestStatusBarThroughput(t *testing.T) {
+	sb := NewStatusBar(120, 1)
+
+	sb, _ = sb.Update(StatusMsg{Type: "stream_throughput", Data: StreamThroughput{Tokens: 84, Elapsed: 2 * time.Second}})
+	assert.Contains(t, sb.View(), "42 tok/s")
+
+	sb, _ = sb.Update(StatusMsg{Type: "stream_end"})
+	assert.NotContains(t, sb.View(), "tok/s")
+}
+
+func TestNetworkMeterQuality(t *testing.T) {
+	tests := []struct {
+		name      string
+		latencies []time.Duration
+		failures  int
+		expected  NetworkTier
+	}{
+		{"no requests", nil, 0, NetworkUnknown},
+		{"fast", []time.Duration{400 * time.Millisecond, 800 * time.Millisecond}, 0, NetworkExcellent},
+		{"a few seconds", []time.Duration{3 * time.Second, 3 * time.Second}, 0, NetworkGood},
+		{"slow", []time.Duration{6 * time.Second, 7 * time.Second}, 0, NetworkFair},
+		{"fast but failing", []time.Duration{500 * time.Millisecond, 500 * time.Millisecond}, 2, NetworkPoor},
+		{"fast with one failure", []time.Duration{time.Second, time.Second, time.Second, time.Second, time.Second}, 1, NetworkGood},
+		{"all failing", nil, 3, NetworkPoor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var meter NetworkMeter
+			for _, latency := range tt.latencies {
+				meter.Record(latency, false)
+			}
+			for i := 0; i < tt.failures; i++ {
+				meter.Record(0, true)
+			}
+			assert.Equal(t, tt.expected, meter.Quality().Tier)
+		})
+	}
+
+	// Old samples roll out of the window
+	var meter NetworkMeter
+	for i := 0; i < networkSampleWindow; i++ {
+		meter.Record(0, true)
+	}
+	for i := 0; i < networkSampleWindow; i++ {
+		meter.Record(200*time.Millisecond, false)
+	}
+	assert.Equal(t, NetworkQuality{100, NetworkExcellent}, meter.Quality())
+}
+
+func TestStatusBarNetworkQuality(t *testing.T) {
+	sb := NewStatusBar(120, 1)
+	assert.NotContains(t, sb.View(), "▂")
+
+	sb, cmd := sb.Update(StatusMsg{Type: "request_completed", Data: 300 * time.Millisecond})
+	require.NotNil(t, cmd)
+	msg := cmd().(StatusMsg)
+	assert.Equal(t, "network_quality", msg.Type)
+
+	sb, _ = sb.Update(msg)
+	assert.Contains(t, sb.View(), "▂▄▆█")
+
+	sb, _ = sb.Update(StatusMsg{Type: "network_quality", Data: 40})
+	assert.Contains(t, sb.View(), "▂▄")
+	assert.NotContains(t, sb.View(), "▂▄▆")
+}
+
+func TestTokenUsageDisplayRateLimitHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("x-ratelimit-limit-requests", "60")
+	header.Set("x-ratelimit-remaining-requests", "15")
+	header.Set("Retry-After", "12")
+
+	tud := NewTokenUsageDisplay(80, 10)
+	tud, _ = tud.Update(StatusMsg{Type: "rate_limit", Data: api.ParseRateLimitHeaders(header)})
+	assert.Equal(t, 60, tud.rateLimit)
+	assert.Equal(t, 45, tud.rateLimitUsed)
+	assert.InDelta(t, float64(12*time.Second), float64(tud.RetryIn()), float64(time.Second))
+	assert.Contains(t, tud.View(), "retry in 12s")
+
+	tud.showDetails = true
+	assert.Contains(t, tud.View(), "45/60 requests")
+
+	// A later response without Retry-After ends the cooldown
+	tud, _ = tud.Update(StatusMsg{Type: "rate_limit", Data: &api.RateLimit{Limit: 60, Remaining: 59}})
+	assert.Zero(t, tud.RetryIn())
+	assert.NotContains(t, tud.View(), "retry in")
+}
+
+func TestStatusBarSections(t *testing.T) {
+	newBar := func(sections []string) *StatusBar {
+		sb := NewStatusBarFromConfig(&storage.Config{StatusBarSections: sections}, 200, 1)
+		sb, _ = sb.Update(StatusMsg{Type: "model_changed", Data: api.Model{Name: "test-model", Provider: api.ProviderAnthropic}})
+		return sb
+	}
+
+	view := newBar(nil).View()
+	assert.Less(t, strings.Index(view, "test-model"), strings.Index(view, "⏱"))
+
+	view = newBar([]string{"system", "model"}).View()
+	assert.Less(t, strings.Index(view, "⏱"), strings.Index(view, "test-model"))
+
+	view = newBar([]string{"system"}).View()
+	assert.NotContains(t, view, "test-model")
+	assert.Contains(t, view, "⏱")
+}
+
+func TestStatusBarDropsSectionsThatDoNotFit(t *testing.T) {
+	sb := NewStatusBar(20, 1)
+	sb.SetSections([]string{"system", "model"})
+	sb, _ = sb.Update(StatusMsg{Type: "model_changed", Data: api.Model{Name: "a-very-long-model-name", Provider: api.ProviderOpenAI}})
+
+	view := sb.View()
+	assert.Contains(t, view, "⏱")
+	assert.NotContains(t, view, "a-very-long-model-name")
+}
+
+func TestParseGitHead(t *testing.T) {
+	tests := []struct {
+		name     string
+		head     string
+		expected string
+	}{
+		{"branch", "ref: refs/heads/main\n", "main"},
+		{"nested branch", "ref: refs/heads/feature/status-bar\n", "feature/status-bar"},
+		{"detached", "3f2a9c1d8e7b6a5f4e3d2c1b0a9f8e7d6c5b4a39\n", "3f2a9c1"},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseGitHead(tt.head))
+		})
+	}
+}
+
+func TestDetectGitContextOutsideRepo(t *testing.T) {
+	assert.Equal(t, GitContext{}, detectGitContext(t.TempDir()))
+}
+
+func TestStatusBarGitSection(t *testing.T) {
+	sb := NewStatusBarFromConfig(&storage.Config{ShowGitContext: true}, 200, 1)
+	sb, _ = sb.Update(StatusMsg{Type: "git_context", Data: GitContext{Branch: "main", Dirty: true}})
+	assert.Contains(t, sb.View(), "⎇ main*")
+
+	sb.SetShowGitContext(false)
+	assert.NotContains(t, sb.View(), "⎇")
+}
+
+func TestConstrainedTerminalOutputIsASCII(t *testing.T) {
+	t.Setenv("TERM", "dumb")
+	t.Setenv("TERM_PROGRAM", "")
+	styler := styles.NewAdaptiveStyler(nil, 80, 24)
+
+	sb := NewStatusBar(200, 1)
+	sb.SetStyler(styler)
+	sb.SetShowGitContext(true)
+	sb, _ = sb.Update(StatusMsg{Type: "model_changed", Data: api.Model{Name: "test-model", Provider: api.ProviderAnthropic}})
+	sb, _ = sb.Update(StatusMsg{Type: "git_context", Data: GitContext{Branch: "main", Dirty: true}})
+	sb, _ = sb.Update(StatusMsg{Type: "stream_throughput", Data: StreamThroughput{Tokens: 84, Elapsed: 2 * time.Second}})
+
+	pt := NewProgressTracker(80, 20)
+	pt.SetStyler(styler)
+	pt.AddOperation("export", "Exporting", 10, "files")
+	pt.UpdateOperation("export", 4, "Writing")
+
+	ls := NewLoadingSpinner(80, 10)
+	ls.SetStyler(styler)
+	ls.SetMessage("Loading models")
+
+	for name, view := range map[string]string{
+		"status bar": sb.View(),
+		"progress":   pt.View(),
+		"spinner":    ls.View(),
+	} {
+		for _, r := range view {
+			if r > unicode.MaxASCII {
+				t.Errorf("%s output contains non-ASCII %q: %s", name, r, view)
+				break
+			}
+		}
+	}
+}
+
+func TestLoadingSpinnerStyleFirstFrame(t *testing.T) {
+	t.Setenv("TERM", "xterm-256color")
+	t.Setenv("TERM_PROGRAM", "")
+	unicodeStyler := styles.NewAdaptiveStyler(nil, 80, 24)
+
+	tests := []struct {
+		style string
+		frame string
+	}{
+		{"line", "|"},
+		{"dot", "⣾ "},
+		{"minidot", "⠋"},
+		{"jump", "⢄"},
+		{"points", "∙∙∙"},
+		{"moon", "🌑"},
+		{"braille", "⠋"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			ls := NewLoadingSpinner(80, 10)
+			ls.SetStyler(unicodeStyler)
+			ls.SetSpinnerStyle(tt.style)
+			assert.Equal(t, tt.frame, ls.spinner.Spinner.Frames[0])
+		})
+	}
+
+	t.Setenv("TERM", "dumb")
+	ls := NewLoadingSpinner(80, 10)
+	ls.SetSpinnerStyle("moon")
+	ls.SetStyler(styles.NewAdaptiveStyler(nil, 80, 24))
+	assert.Equal(t, styles.ASCIICharacterSet.Spinner, ls.spinner.Spinner.Frames)
+}
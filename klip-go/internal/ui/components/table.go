@@ -0,0 +1,173 @@
+package components
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// minTableColumnWidth is the narrowest a column is squeezed to when a
+// table is wider than the chat
+const minTableColumnWidth = 3
+
+// tableDelimiterCell matches one cell of a GFM table's alignment row
+var tableDelimiterCell = regexp.MustCompile(`^:?-+:?$`)
+
+// markdownTable is a parsed GFM table
+type markdownTable struct {
+	header []string
+	align  []lipgloss.Position
+	rows   [][]string
+}
+
+// markdownTableAt reports whether a GFM table starts at lines[start]: a
+// header row followed by an alignment row with as many cells. It returns
+// the table and the index of its last row; body rows run until a line
+// without a pipe.
+func markdownTableAt(lines []string, start int) (markdownTable, int, bool) {
+	if start+1 >= len(lines) || !strings.Contains(lines[start], "|") {
+		return markdownTable{}, 0, false
+	}
+
+	header := splitTableRow(lines[start])
+	delimiters := splitTableRow(lines[start+1])
+	if len(delimiters) != len(header) {
+		return markdownTable{}, 0, false
+	}
+
+	table := markdownTable{header: header, align: make([]lipgloss.Position, len(header))}
+	for i, cell := range delimiters {
+		if !tableDelimiterCell.MatchString(cell) {
+			return markdownTable{}, 0, false
+		}
+		switch {
+		case strings.HasPrefix(cell, ":") && strings.HasSuffix(cell, ":"):
+			table.align[i] = lipgloss.Center
+		case strings.HasSuffix(cell, ":"):
+			table.align[i] = lipgloss.Right
+		default:
+			table.align[i] = lipgloss.Left
+		}
+	}
+
+	end := start + 1
+	for end+1 < len(lines) && strings.Contains(lines[end+1], "|") && strings.TrimSpace(lines[end+1]) != "" {
+		end++
+		// Rows are padded or cut to the header's column count, as GFM does
+		row := splitTableRow(lines[end])
+		cells := make([]string, len(header))
+		copy(cells, row)
+		table.rows = append(table.rows, cells)
+	}
+	return table, end, true
+}
+
+// splitTableRow splits a table row into trimmed cells, dropping the outer
+// pipes and keeping escaped \| in the cell text
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// renderTable draws a table with box borders and aligned columns. Tables
+// wider than the message area have their widest columns narrowed, cutting
+// the cells that no longer fit.
+func (cv *ChatView) renderTable(table markdownTable, baseStyle lipgloss.Style) string {
+	render := func(cells []string) []string {
+		rendered := make([]string, len(cells))
+		for i, cell := range cells {
+			rendered[i] = cv.renderInlineMath(cell)
+		}
+		return rendered
+	}
+	header := render(table.header)
+	rows := make([][]string, len(table.rows))
+	for i, row := range table.rows {
+		rows[i] = render(row)
+	}
+
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			widths[i] = max(widths[i], lipgloss.Width(cell), 1)
+		}
+	}
+	fitTableColumns(widths, cv.width-baseStyle.GetHorizontalPadding())
+
+	border := lipgloss.NormalBorder()
+	rule := func(left, middle, right string) string {
+		segments := make([]string, len(widths))
+		for i, width := range widths {
+			segments[i] = strings.Repeat(border.Top, width+2)
+		}
+		return TableBorderStyle.Render(left + strings.Join(segments, middle) + right)
+	}
+	line := func(cells []string, style lipgloss.Style) string {
+		separator := TableBorderStyle.Render(border.Left)
+		parts := make([]string, len(cells))
+		for i, cell := range cells {
+			cell = ansi.Truncate(cell, widths[i], "…")
+			parts[i] = " " + style.Render(lipgloss.PlaceHorizontal(widths[i], table.align[i], cell)) + " "
+		}
+		return separator + strings.Join(parts, separator) + separator
+	}
+
+	lines := []string{
+		rule(border.TopLeft, border.MiddleTop, border.TopRight),
+		line(header, TableHeaderStyle),
+		rule(border.MiddleLeft, border.Middle, border.MiddleRight),
+	}
+	for _, row := range rows {
+		lines = append(lines, line(row, lipgloss.NewStyle()))
+	}
+	lines = append(lines, rule(border.BottomLeft, border.MiddleBottom, border.BottomRight))
+
+	for i, text := range lines {
+		lines[i] = baseStyle.Render(text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fitTableColumns narrows the widest columns one cell at a time until the
+// table, borders included, fits in width or every column is at its minimum
+func fitTableColumns(widths []int, width int) {
+	total := len(widths)*3 + 1
+	for _, w := range widths {
+		total += w
+	}
+
+	for total > width {
+		widest := 0
+		for i, w := range widths {
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= minTableColumnWidth {
+			return
+		}
+		widths[widest]--
+		total--
+	}
+}
@@ -0,0 +1,287 @@
+package components
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Typo is a flagged word and its byte range in the checked text
+type Typo struct {
+	Word       string
+	Suggestion string
+	Start      int
+	End        int
+}
+
+// commonTypos maps frequent typos to their corrections. This is a short
+// list, not a dictionary: like misspell, the checker only flags words it
+// knows are wrong, so technical vocabulary,
+// names and identifiers never raise false alarms. Words that are also
+// correct spellings elsewhere, such as "dependant" or "calender", don't
+// belong here.
+var commonTypos = map[string]string{
+	"accomodate":    "accommodate",
+	"acheive":       "achieve",
+	"accross":       "across",
+	"adress":        "address",
+	"agressive":     "aggressive",
+	"apparantly":    "apparently",
+	"arguement":     "argument",
+	"asynchonous":   "asynchronous",
+	"basicly":       "basically",
+	"begining":      "beginning",
+	"beleive":       "believe",
+	"cemetary":      "cemetery",
+	"commited":      "committed",
+	"completly":     "completely",
+	"concious":      "conscious",
+	"definately":    "definitely",
+	"dependancy":    "dependency",
+	"enviroment":    "environment",
+	"existance":     "existence",
+	"explaination":  "explanation",
+	"familar":       "familiar",
+	"finaly":        "finally",
+	"foward":        "forward",
+	"freind":        "friend",
+	"goverment":     "government",
+	"grammer":       "grammar",
+	"guage":         "gauge",
+	"happend":       "happened",
+	"immediatly":    "immediately",
+	"implmentation": "implementation",
+	"independant":   "independent",
+	"informaton":    "information",
+	"interupt":      "interrupt",
+	"knowlege":      "knowledge",
+	"lenght":        "length",
+	"libary":        "library",
+	"maintenence":   "maintenance",
+	"neccessary":    "necessary",
+	"necesary":      "necessary",
+	"noticable":     "noticeable",
+	"occured":       "occurred",
+	"occurence":     "occurrence",
+	"paramter":      "parameter",
+	"parralel":      "parallel",
+	"peice":         "piece",
+	"performace":    "performance",
+	"persistant":    "persistent",
+	"posible":       "possible",
+	"prefered":      "preferred",
+	"priviledge":    "privilege",
+	"recieve":       "receive",
+	"recomend":      "recommend",
+	"refered":       "referred",
+	"relevent":      "relevant",
+	"repositry":     "repository",
+	"responce":      "response",
+	"seperate":      "separate",
+	"succesful":     "successful",
+	"sucessful":     "successful",
+	"suprise":       "surprise",
+	"teh":           "the",
+	"tommorow":      "tomorrow",
+	"truely":        "truly",
+	"untill":        "until",
+	"usefull":       "useful",
+	"wierd":         "weird",
+	"whith":         "with",
+	"wich":          "which",
+	"writting":      "writing",
+}
+
+var (
+	typoWordPattern = regexp.MustCompile(`[\p{L}']+`)
+	typoSkipPattern = regexp.MustCompile("(?s)```.*?(```|$)|`[^`\n]*`?|(?i)\\b(?:https?://|www\\.)\\S+")
+)
+
+// TypoChecker flags common typos, skipping code and URLs. It is safe to
+// use from the goroutines that run input commands.
+type TypoChecker struct {
+	mu       sync.RWMutex
+	personal map[string]bool
+}
+
+// NewTypoChecker creates a checker that accepts the given personal words
+func NewTypoChecker(personal []string) *TypoChecker {
+	sc := &TypoChecker{personal: make(map[string]bool)}
+	for _, word := range personal {
+		sc.personal[strings.ToLower(word)] = true
+	}
+	return sc
+}
+
+// AddWord teaches the checker a word so it is no longer flagged
+func (sc *TypoChecker) AddWord(word string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.personal[strings.ToLower(word)] = true
+}
+
+// Check returns the common typos in text in order of appearance
+func (sc *TypoChecker) Check(text string) []Typo {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+
+	skipped := typoSkipPattern.FindAllStringIndex(text, -1)
+	var result []Typo
+
+	for _, loc := range typoWordPattern.FindAllStringIndex(text, -1) {
+		if insideRanges(loc[0], skipped) {
+			continue
+		}
+
+		word := strings.Trim(text[loc[0]:loc[1]], "'")
+		lower := strings.ToLower(word)
+		if sc.personal[lower] {
+			continue
+		}
+
+		suggestion, ok := commonTypos[lower]
+		if !ok {
+			continue
+		}
+		if first := []rune(word)[0]; unicode.IsUpper(first) {
+			suggestion = strings.ToUpper(suggestion[:1]) + suggestion[1:]
+		}
+
+		start := loc[0] + strings.Index(text[loc[0]:loc[1]], word)
+		result = append(result, Typo{
+			Word:       word,
+			Suggestion: suggestion,
+			Start:      start,
+			End:        start + len(word),
+		})
+	}
+
+	return result
+}
+
+// insideRanges reports whether offset falls within any [start, end) range
+func insideRanges(offset int, ranges [][]int) bool {
+	for _, r := range ranges {
+		if offset >= r[0] && offset < r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// underlineSpans underlines each typo where it appears in rendered,
+// the styled view of text. Words are matched by their position among the
+// visible occurrences of the same word, so styling inside the view doesn't
+// get in the way, and on is repeated after every escape sequence within a
+// word so that a reset, such as the cursor's, doesn't end the underline.
+// Typos that are stale or scrolled out of view are skipped.
+func underlineSpans(rendered, text string, typos []Typo, on, off string) string {
+	if len(typos) == 0 {
+		return rendered
+	}
+
+	// The visible text, with the offset in rendered of each of its bytes
+	var visible strings.Builder
+	var offsets []int
+	for i := 0; i < len(rendered); {
+		if n := escapeLength(rendered[i:]); n > 0 {
+			i += n
+			continue
+		}
+		visible.WriteByte(rendered[i])
+		offsets = append(offsets, i)
+		i++
+	}
+
+	type span struct{ start, end int }
+	var spans []span
+	for _, m := range typos {
+		if m.End > len(text) || text[m.Start:m.End] != m.Word {
+			continue
+		}
+		nth := len(wordOccurrences(text[:m.Start], m.Word))
+		if locs := wordOccurrences(visible.String(), m.Word); nth < len(locs) {
+			spans = append(spans, span{offsets[locs[nth][0]], offsets[locs[nth][1]-1] + 1})
+		}
+	}
+	if len(spans) == 0 {
+		return rendered
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var out strings.Builder
+	next, inside := 0, false
+	for i := 0; i < len(rendered); {
+		if inside && i == spans[next].end {
+			out.WriteString(off)
+			inside = false
+			next++
+		}
+		if !inside && next < len(spans) && i == spans[next].start {
+			out.WriteString(on)
+			inside = true
+		}
+		if n := escapeLength(rendered[i:]); n > 0 {
+			out.WriteString(rendered[i : i+n])
+			if inside {
+				out.WriteString(on)
+			}
+			i += n
+			continue
+		}
+		out.WriteByte(rendered[i])
+		i++
+	}
+	if inside {
+		out.WriteString(off)
+	}
+	return out.String()
+}
+
+// wordOccurrences returns the byte ranges of word in s, matched as a whole
+// word the way Check finds them
+func wordOccurrences(s, word string) [][]int {
+	var found [][]int
+	for _, loc := range typoWordPattern.FindAllStringIndex(s, -1) {
+		candidate := s[loc[0]:loc[1]]
+		trimmed := strings.Trim(candidate, "'")
+		if trimmed != word {
+			continue
+		}
+		start := loc[0] + strings.Index(candidate, trimmed)
+		found = append(found, []int{start, start + len(trimmed)})
+	}
+	return found
+}
+
+// escapeLength returns the length of the terminal escape sequence at the
+// start of s, or 0 if s doesn't start with one
+func escapeLength(s string) int {
+	if len(s) < 2 || s[0] != '\x1b' {
+		return 0
+	}
+	switch s[1] {
+	case '[':
+		// CSI: parameter and intermediate bytes up to a final byte
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+		return len(s)
+	case ']':
+		// OSC, ended by BEL or ST
+		for i := 2; i < len(s); i++ {
+			if s[i] == '\a' {
+				return i + 1
+			}
+			if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+		return len(s)
+	}
+	return 2
+}
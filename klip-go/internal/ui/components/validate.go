@@ -0,0 +1,110 @@
+package components
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/john/klip/internal/storage"
+)
+
+// Limits applied by DefaultValidatorRegistry
+const (
+	maxMessageLength = 100000
+	maxSearchLength  = 200
+)
+
+// Validator checks input text; its error is shown in the input footer
+type Validator func(string) error
+
+// NonEmpty rejects input that is only whitespace
+func NonEmpty() Validator {
+	return func(value string) error {
+		if strings.TrimSpace(value) == "" {
+			return errors.New("input is empty")
+		}
+		return nil
+	}
+}
+
+// MaxLength rejects input longer than limit characters
+func MaxLength(limit int) Validator {
+	return func(value string) error {
+		if n := utf8.RuneCountInString(value); n > limit {
+			return fmt.Errorf("%d characters over the %d limit", n-limit, limit)
+		}
+		return nil
+	}
+}
+
+// NoSecrets rejects input containing something that looks like an API key
+// or token, so it isn't sent to a model by accident
+func NoSecrets() Validator {
+	return func(value string) error {
+		if _, count := storage.RedactSecrets(value); count > 0 {
+			return errors.New("looks like it contains an API key or token")
+		}
+		return nil
+	}
+}
+
+// ComposeValidators runs validators in order and returns the first error.
+// Nil validators are skipped; the result is nil when none are left.
+func ComposeValidators(validators ...Validator) Validator {
+	var active []Validator
+	for _, validator := range validators {
+		if validator != nil {
+			active = append(active, validator)
+		}
+	}
+
+	switch len(active) {
+	case 0:
+		return nil
+	case 1:
+		return active[0]
+	}
+	return func(value string) error {
+		for _, validator := range active {
+			if err := validator(value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// ValidatorRegistry holds the validators applied to each input type
+type ValidatorRegistry struct {
+	validators map[InputType][]Validator
+}
+
+// NewValidatorRegistry creates an empty validator registry
+func NewValidatorRegistry() *ValidatorRegistry {
+	return &ValidatorRegistry{validators: make(map[InputType][]Validator)}
+}
+
+// DefaultValidatorRegistry checks messages for blank input, excessive
+// length and pasted secrets, and keeps searches short
+func DefaultValidatorRegistry() *ValidatorRegistry {
+	registry := NewValidatorRegistry()
+	for _, inputType := range []InputType{InputTypeText, InputTypeMultiline} {
+		registry.Register(inputType, NonEmpty(), MaxLength(maxMessageLength), NoSecrets())
+	}
+	registry.Register(InputTypeSearch, NonEmpty(), MaxLength(maxSearchLength))
+	return registry
+}
+
+// Register adds validators for inputType, after any already registered
+func (vr *ValidatorRegistry) Register(inputType InputType, validators ...Validator) {
+	vr.validators[inputType] = append(vr.validators[inputType], validators...)
+}
+
+// For returns the composed validator of inputType, or nil when it has none
+func (vr *ValidatorRegistry) For(inputType InputType) Validator {
+	if vr == nil {
+		return nil
+	}
+	return ComposeValidators(vr.validators[inputType]...)
+}
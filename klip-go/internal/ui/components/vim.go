@@ -0,0 +1,487 @@
+package components
+
+import (
+	"unicode"
+)
+
+// VimMode is the editing mode of the Vim keybindings
+type VimMode int
+
+const (
+	VimInsert VimMode = iota
+	VimNormal
+	VimVisual
+	VimCommand
+)
+
+// String returns the label shown in the input footer
+func (m VimMode) String() string {
+	switch m {
+	case VimNormal:
+		return "NORMAL"
+	case VimVisual:
+		return "VISUAL"
+	case VimCommand:
+		return "COMMAND"
+	default:
+		return "INSERT"
+	}
+}
+
+// vimAction tells the input what to do after the editor has seen a key
+type vimAction int
+
+const (
+	// vimPassThrough lets the input handle the key as usual
+	vimPassThrough vimAction = iota
+	// vimHandled means the editor consumed the key
+	vimHandled
+	// vimSubmit sends the buffer, as ":w" does
+	vimSubmit
+)
+
+// vimEditor implements modal editing over a plain text buffer. Cursor
+// positions are rune offsets into the whole buffer, with lines separated by
+// newlines, so the same editor drives both the single-line and multiline
+// backends.
+type vimEditor struct {
+	mode     VimMode
+	pending  string // operator or prefix waiting for its motion, e.g. "d" or "g"
+	command  string // text typed after ":"
+	anchor   int    // fixed end of the visual selection
+	register string // last yanked or deleted text
+}
+
+// newVimEditor starts in insert mode so the composer accepts typing right away
+func newVimEditor() *vimEditor {
+	return &vimEditor{mode: VimInsert}
+}
+
+// handleKey applies a key to the buffer, returning the edited buffer, the new
+// cursor and what the input should do next
+func (v *vimEditor) handleKey(buf []rune, cursor int, k string) ([]rune, int, vimAction) {
+	cursor = clampInt(cursor, 0, len(buf))
+
+	switch v.mode {
+	case VimInsert:
+		if k != "esc" {
+			return buf, cursor, vimPassThrough
+		}
+		v.mode = VimNormal
+		if cursor > lineStart(buf, cursor) {
+			cursor--
+		}
+		return buf, normalCursor(buf, cursor), vimHandled
+
+	case VimCommand:
+		return v.handleCommandKey(buf, cursor, k)
+
+	case VimVisual:
+		return v.handleVisualKey(buf, cursor, k)
+	}
+
+	return v.handleNormalKey(buf, cursor, k)
+}
+
+// handleCommandKey edits the ":" command line and runs it on enter
+func (v *vimEditor) handleCommandKey(buf []rune, cursor int, k string) ([]rune, int, vimAction) {
+	switch k {
+	case "esc":
+		v.mode, v.command = VimNormal, ""
+	case "enter":
+		command := v.command
+		v.mode, v.command = VimNormal, ""
+		switch command {
+		case "w", "wq", "x":
+			v.mode = VimInsert
+			return buf, cursor, vimSubmit
+		}
+	case "backspace":
+		if v.command == "" {
+			v.mode = VimNormal
+		} else {
+			v.command = v.command[:len(v.command)-1]
+		}
+	default:
+		if len([]rune(k)) == 1 {
+			v.command += k
+		}
+	}
+	return buf, cursor, vimHandled
+}
+
+// handleVisualKey moves the selection and applies operators to it
+func (v *vimEditor) handleVisualKey(buf []rune, cursor int, k string) ([]rune, int, vimAction) {
+	switch k {
+	case "esc", "v":
+		v.mode = VimNormal
+		return buf, normalCursor(buf, cursor), vimHandled
+	case "d", "x", "y", "c":
+		start, end := v.anchor, cursor
+		if start > end {
+			start, end = end, start
+		}
+		end = min(end+1, len(buf))
+		v.mode = VimNormal
+		return v.applyOperator(buf, k, start, end)
+	}
+
+	if target, ok := v.motion(buf, cursor, k, false); ok {
+		return buf, target, vimHandled
+	}
+	if len([]rune(k)) > 1 {
+		return buf, cursor, vimPassThrough
+	}
+	return buf, cursor, vimHandled
+}
+
+// handleNormalKey runs motions, operators and mode switches
+func (v *vimEditor) handleNormalKey(buf []rune, cursor int, k string) ([]rune, int, vimAction) {
+	if k == "esc" {
+		v.pending = ""
+		return buf, cursor, vimHandled
+	}
+
+	if v.pending != "" {
+		op := v.pending
+		v.pending = ""
+		return v.handlePending(buf, cursor, op, k)
+	}
+
+	switch k {
+	case "i":
+		v.mode = VimInsert
+	case "a":
+		v.mode = VimInsert
+		if cursor < len(buf) && buf[cursor] != '\n' {
+			cursor++
+		}
+	case "I":
+		v.mode = VimInsert
+		cursor = lineStart(buf, cursor)
+	case "A":
+		v.mode = VimInsert
+		cursor = lineEnd(buf, cursor)
+	case "o":
+		end := lineEnd(buf, cursor)
+		buf = insertRunes(buf, end, []rune("\n"))
+		v.mode = VimInsert
+		cursor = end + 1
+	case "O":
+		start := lineStart(buf, cursor)
+		buf = insertRunes(buf, start, []rune("\n"))
+		v.mode = VimInsert
+		cursor = start
+	case "v":
+		v.mode = VimVisual
+		v.anchor = cursor
+	case ":":
+		v.mode = VimCommand
+		v.command = ""
+	case "x":
+		if cursor < len(buf) && buf[cursor] != '\n' {
+			return v.applyOperator(buf, "d", cursor, cursor+1)
+		}
+	case "D", "C":
+		op := "d"
+		if k == "C" {
+			op = "c"
+		}
+		return v.applyOperator(buf, op, cursor, lineEnd(buf, cursor))
+	case "p", "P":
+		return v.put(buf, cursor, k == "P")
+	case "d", "c", "y", "g":
+		v.pending = k
+	default:
+		if target, ok := v.motion(buf, cursor, k, false); ok {
+			return buf, normalCursor(buf, target), vimHandled
+		}
+		if len([]rune(k)) > 1 {
+			return buf, cursor, vimPassThrough
+		}
+	}
+
+	if v.mode == VimNormal {
+		cursor = normalCursor(buf, cursor)
+	}
+	return buf, cursor, vimHandled
+}
+
+// handlePending completes a two-key command such as "dd", "cw" or "gg"
+func (v *vimEditor) handlePending(buf []rune, cursor int, op, k string) ([]rune, int, vimAction) {
+	if op == "g" {
+		if k == "g" {
+			cursor = 0
+		}
+		return buf, normalCursor(buf, cursor), vimHandled
+	}
+
+	// Doubled operators work on whole lines
+	if k == op {
+		start, end := lineStart(buf, cursor), lineEnd(buf, cursor)
+		if op == "c" {
+			return v.applyOperator(buf, op, start, end)
+		}
+		if end < len(buf) {
+			end++
+		} else if start > 0 {
+			start--
+		}
+		buf, cursor, action := v.applyOperator(buf, op, start, end)
+		v.register = string(lineText(v.register)) + "\n"
+		return buf, normalCursor(buf, lineStart(buf, cursor)), action
+	}
+
+	// "cw" changes to the end of the word rather than up to the next one
+	if op == "c" && k == "w" && cursor < len(buf) && !unicode.IsSpace(buf[cursor]) {
+		k = "e"
+	}
+
+	target, ok := v.motion(buf, cursor, k, true)
+	if !ok {
+		return buf, cursor, vimHandled
+	}
+
+	start, end := cursor, target
+	if start > end {
+		start, end = end, start
+	}
+	if k == "e" {
+		end = min(end+1, len(buf))
+	}
+	return v.applyOperator(buf, op, start, end)
+}
+
+// applyOperator deletes, changes or yanks buf[start:end]
+func (v *vimEditor) applyOperator(buf []rune, op string, start, end int) ([]rune, int, vimAction) {
+	if start >= end {
+		return buf, normalCursor(buf, start), vimHandled
+	}
+
+	v.register = string(buf[start:end])
+	if op == "y" {
+		return buf, normalCursor(buf, start), vimHandled
+	}
+
+	buf = append(buf[:start:start], buf[end:]...)
+	if op == "c" {
+		v.mode = VimInsert
+		return buf, start, vimHandled
+	}
+	return buf, normalCursor(buf, start), vimHandled
+}
+
+// put pastes the register after the cursor, or before it for "P". Linewise
+// registers paste as whole lines.
+func (v *vimEditor) put(buf []rune, cursor int, before bool) ([]rune, int, vimAction) {
+	if v.register == "" {
+		return buf, cursor, vimHandled
+	}
+	text := []rune(v.register)
+
+	if text[len(text)-1] == '\n' {
+		at := lineStart(buf, cursor)
+		if !before {
+			at = lineEnd(buf, cursor)
+			text = append([]rune("\n"), text[:len(text)-1]...)
+		}
+		buf = insertRunes(buf, at, text)
+		if !before {
+			at++
+		}
+		return buf, normalCursor(buf, at), vimHandled
+	}
+
+	at := cursor
+	if !before && cursor < len(buf) && buf[cursor] != '\n' {
+		at++
+	}
+	buf = insertRunes(buf, at, text)
+	return buf, normalCursor(buf, at+len(text)-1), vimHandled
+}
+
+// motion returns where a movement key takes the cursor. Operator motions stop
+// at the end of the line instead of wrapping onto the next one.
+func (v *vimEditor) motion(buf []rune, cursor int, k string, operator bool) (int, bool) {
+	switch k {
+	case "h", "left":
+		if cursor > lineStart(buf, cursor) {
+			return cursor - 1, true
+		}
+		return cursor, true
+	case "l", "right":
+		end := lineEnd(buf, cursor)
+		if operator {
+			return min(cursor+1, end), true
+		}
+		if cursor+1 < end {
+			return cursor + 1, true
+		}
+		return cursor, true
+	case "j", "down":
+		return verticalMove(buf, cursor, 1), true
+	case "k", "up":
+		return verticalMove(buf, cursor, -1), true
+	case "0", "home":
+		return lineStart(buf, cursor), true
+	case "$", "end":
+		return lineEnd(buf, cursor), true
+	case "G":
+		return lineStart(buf, len(buf)), true
+	case "w":
+		target := wordForward(buf, cursor)
+		if operator && target > lineEnd(buf, cursor) {
+			target = lineEnd(buf, cursor)
+		}
+		return target, true
+	case "e":
+		return wordEnd(buf, cursor), true
+	case "b":
+		return wordBackward(buf, cursor), true
+	}
+	return cursor, false
+}
+
+// runeClass groups runes the way Vim splits words: whitespace, keyword
+// characters and punctuation
+func runeClass(r rune) int {
+	switch {
+	case unicode.IsSpace(r):
+		return 0
+	case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+		return 1
+	default:
+		return 2
+	}
+}
+
+// wordForward returns the start of the next word
+func wordForward(buf []rune, i int) int {
+	if i >= len(buf) {
+		return len(buf)
+	}
+	if class := runeClass(buf[i]); class != 0 {
+		for i < len(buf) && runeClass(buf[i]) == class {
+			i++
+		}
+	}
+	for i < len(buf) && runeClass(buf[i]) == 0 {
+		i++
+	}
+	return i
+}
+
+// wordEnd returns the last rune of the current or next word
+func wordEnd(buf []rune, i int) int {
+	if len(buf) == 0 {
+		return 0
+	}
+	i++
+	for i < len(buf) && runeClass(buf[i]) == 0 {
+		i++
+	}
+	if i >= len(buf) {
+		return len(buf) - 1
+	}
+	class := runeClass(buf[i])
+	for i+1 < len(buf) && runeClass(buf[i+1]) == class {
+		i++
+	}
+	return i
+}
+
+// wordBackward returns the start of the current or previous word
+func wordBackward(buf []rune, i int) int {
+	if i <= 0 {
+		return 0
+	}
+	i--
+	for i > 0 && runeClass(buf[i]) == 0 {
+		i--
+	}
+	class := runeClass(buf[i])
+	for i > 0 && runeClass(buf[i-1]) == class {
+		i--
+	}
+	return i
+}
+
+// verticalMove moves to the same column on an adjacent line
+func verticalMove(buf []rune, cursor, direction int) int {
+	start := lineStart(buf, cursor)
+	column := cursor - start
+
+	var target int
+	if direction > 0 {
+		end := lineEnd(buf, cursor)
+		if end >= len(buf) {
+			return cursor
+		}
+		target = end + 1
+	} else {
+		if start == 0 {
+			return cursor
+		}
+		target = lineStart(buf, start-1)
+	}
+
+	return normalCursor(buf, min(target+column, lineEnd(buf, target)))
+}
+
+// normalCursor keeps the cursor on a character, as normal mode requires,
+// except on empty lines
+func normalCursor(buf []rune, cursor int) int {
+	cursor = clampInt(cursor, 0, len(buf))
+	if cursor > lineStart(buf, cursor) && (cursor == len(buf) || buf[cursor] == '\n') {
+		cursor--
+	}
+	return cursor
+}
+
+// lineStart returns the offset of the first rune on the cursor's line
+func lineStart(buf []rune, i int) int {
+	i = clampInt(i, 0, len(buf))
+	for i > 0 && buf[i-1] != '\n' {
+		i--
+	}
+	return i
+}
+
+// lineEnd returns the offset of the newline ending the cursor's line, or the
+// buffer length on the last line
+func lineEnd(buf []rune, i int) int {
+	i = clampInt(i, 0, len(buf))
+	for i < len(buf) && buf[i] != '\n' {
+		i++
+	}
+	return i
+}
+
+// lineText strips a trailing or leading newline left by a linewise delete
+func lineText(s string) []rune {
+	r := []rune(s)
+	if len(r) > 0 && r[len(r)-1] == '\n' {
+		return r[:len(r)-1]
+	}
+	if len(r) > 0 && r[0] == '\n' {
+		return r[1:]
+	}
+	return r
+}
+
+func insertRunes(buf []rune, at int, text []rune) []rune {
+	result := make([]rune, 0, len(buf)+len(text))
+	result = append(result, buf[:at]...)
+	result = append(result, text...)
+	return append(result, buf[at:]...)
+}
+
+func clampInt(v, low, high int) int {
+	if v < low {
+		return low
+	}
+	if v > high {
+		return high
+	}
+	return v
+}
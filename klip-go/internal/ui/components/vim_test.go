@@ -0,0 +1,95 @@
+package components
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+// vimKeys feeds keys to an editor and returns the resulting text and cursor
+func vimKeys(v *vimEditor, text string, cursor int, keys ...string) (string, int, vimAction) {
+	buf := []rune(text)
+	action := vimHandled
+	for _, k := range keys {
+		buf, cursor, action = v.handleKey(buf, cursor, k)
+	}
+	return string(buf), cursor, action
+}
+
+func TestVimModeTransitions(t *testing.T) {
+	v := newVimEditor()
+	assert.Equal(t, VimInsert, v.mode)
+
+	_, cursor, _ := vimKeys(v, "hello", 5, "esc")
+	assert.Equal(t, VimNormal, v.mode)
+	assert.Equal(t, 4, cursor, "leaving insert mode steps back onto the last character")
+
+	vimKeys(v, "hello", 4, "v")
+	assert.Equal(t, VimVisual, v.mode)
+	vimKeys(v, "hello", 4, "esc", ":")
+	assert.Equal(t, VimCommand, v.mode)
+	vimKeys(v, "hello", 4, "esc", "a")
+	assert.Equal(t, VimInsert, v.mode)
+
+	_, _, action := vimKeys(v, "hello", 4, "x")
+	assert.Equal(t, vimPassThrough, action, "insert mode leaves typing to the input")
+}
+
+func TestVimOperators(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		cursor     int
+		keys       []string
+		want       string
+		wantCursor int
+		mode       VimMode
+	}{
+		{"dw", "hello big world", 6, []string{"d", "w"}, "hello world", 6, VimNormal},
+		{"dw at line end", "one two\nthree", 4, []string{"d", "w"}, "one \nthree", 3, VimNormal},
+		{"cw", "hello world", 0, []string{"c", "w"}, " world", 0, VimInsert},
+		{"dd", "first\nsecond\nthird", 8, []string{"d", "d"}, "first\nthird", 6, VimNormal},
+		{"dd last line", "first\nsecond", 7, []string{"d", "d"}, "first", 0, VimNormal},
+		{"x", "abc", 1, []string{"x"}, "ac", 1, VimNormal},
+		{"visual delete", "abcdef", 1, []string{"v", "l", "l", "d"}, "aef", 1, VimNormal},
+		{"dd then p", "a\nb", 0, []string{"d", "d", "p"}, "b\na", 2, VimNormal},
+		{"j keeps column", "abc\nxy", 2, []string{"j", "x"}, "abc\nx", 4, VimNormal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newVimEditor()
+			v.mode = VimNormal
+
+			text, cursor, _ := vimKeys(v, tt.text, tt.cursor, tt.keys...)
+			assert.Equal(t, tt.want, text)
+			assert.Equal(t, tt.wantCursor, cursor)
+			assert.Equal(t, tt.mode, v.mode)
+		})
+	}
+}
+
+func TestVimWriteSubmits(t *testing.T) {
+	ei := NewEnhancedInput(InputTypeMultiline, 80, 6)
+	ei.SetVimMode(true)
+	ei.SetValue("hello big world")
+
+	ei, _ = ei.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	for _, r := range "0wdw" {
+		ei, _ = ei.Update(runeKey(r))
+	}
+	assert.Equal(t, "hello world", ei.Value())
+	assert.Contains(t, ei.renderFooter(), "NORMAL")
+
+	ei, _ = ei.Update(runeKey(':'))
+	ei, _ = ei.Update(runeKey('w'))
+	assert.Contains(t, ei.renderFooter(), ":w")
+
+	ei, _ = ei.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, []string{"hello world"}, ei.history)
+
+	mode, enabled := ei.VimMode()
+	assert.True(t, enabled)
+	assert.Equal(t, VimInsert, mode)
+}
@@ -110,6 +110,86 @@ const (
 	ColorBlindMonochromacy                 // Complete color blindness
 )
 
+// ColorBlindTypes lists the color vision deficiencies, excluding
+// ColorBlindNone
+var ColorBlindTypes = []ColorBlindType{
+	ColorBlindProtanopia,
+	ColorBlindDeuteranopia,
+	ColorBlindTritanopia,
+	ColorBlindProtanomaly,
+	ColorBlindDeuteranomaly,
+	ColorBlindTritanomaly,
+	ColorBlindMonochromacy,
+}
+
+// colorBlindSimulations are the Machado et al. (2009) matrices, applied to
+// linear RGB; the anomalies use severity 0.6
+var colorBlindSimulations = map[ColorBlindType][3][3]float64{
+	ColorBlindProtanopia: {
+		{0.152286, 1.052583, -0.204868},
+		{0.114503, 0.786281, 0.099216},
+		{-0.003882, -0.048116, 1.051998},
+	},
+	ColorBlindDeuteranopia: {
+		{0.367322, 0.860646, -0.227968},
+		{0.280085, 0.672501, 0.047413},
+		{-0.011820, 0.042940, 0.968881},
+	},
+	ColorBlindTritanopia: {
+		{1.255528, -0.076749, -0.178779},
+		{-0.078411, 0.930809, 0.147602},
+		{0.004733, 0.691367, 0.303900},
+	},
+	ColorBlindProtanomaly: {
+		{0.385450, 0.769005, -0.154455},
+		{0.100526, 0.829802, 0.069673},
+		{-0.007442, -0.022190, 1.029632},
+	},
+	ColorBlindDeuteranomaly: {
+		{0.547494, 0.607765, -0.155259},
+		{0.181692, 0.781742, 0.036566},
+		{-0.010410, 0.027275, 0.983136},
+	},
+	ColorBlindTritanomaly: {
+		{1.104996, -0.046633, -0.058363},
+		{-0.032137, 0.971635, 0.060503},
+		{0.001390, 0.344078, 0.654532},
+	},
+}
+
+// SimulateColorBlindness returns color as it appears with the given color
+// vision deficiency. Colors that aren't hex, such as ANSI indices, are
+// returned unchanged.
+func SimulateColorBlindness(color lipgloss.Color, colorBlindType ColorBlindType) lipgloss.Color {
+	c, err := colorful.Hex(string(color))
+	if err != nil || colorBlindType == ColorBlindNone {
+		return color
+	}
+
+	r, g, b := c.LinearRgb()
+	if colorBlindType == ColorBlindMonochromacy {
+		gray := 0.2126*r + 0.7152*g + 0.0722*b
+		return lipgloss.Color(colorful.LinearRgb(gray, gray, gray).Clamped().Hex())
+	}
+
+	m, ok := colorBlindSimulations[colorBlindType]
+	if !ok {
+		return color
+	}
+	simulated := colorful.LinearRgb(
+		m[0][0]*r+m[0][1]*g+m[0][2]*b,
+		m[1][0]*r+m[1][1]*g+m[1][2]*b,
+		m[2][0]*r+m[2][1]*g+m[2][2]*b,
+	)
+	return lipgloss.Color(simulated.Clamped().Hex())
+}
+
+// ColorBlindPalette returns the replacement palette used for colorBlindType
+// when the color blind palette is turned on
+func (am *AccessibilityManager) ColorBlindPalette(colors ColorPalette, colorBlindType ColorBlindType) ColorPalette {
+	return am.adaptColorsForColorBlindness(colors, colorBlindType)
+}
+
 // ContrastChecker validates color contrast ratios for accessibility
 type ContrastChecker struct {
 	minNormalContrast   float64 // WCAG AA: 4.5:1
@@ -131,7 +211,7 @@ func NewAccessibilityManager(theme *Theme, capabilities *TerminalCapabilities) *
 	am := &AccessibilityManager{
 		theme:        theme,
 		capabilities: capabilities,
-		preferences:  detectAccessibilityPreferences(),
+		preferences:  DetectAccessibilityPreferences(),
 		contrastChecker: &ContrastChecker{
 			minNormalContrast:   4.5,
 			minLargeContrast:    3.0,
@@ -145,8 +225,8 @@ func NewAccessibilityManager(theme *Theme, capabilities *TerminalCapabilities) *
 	return am
 }
 
-// detectAccessibilityPreferences detects accessibility preferences from environment
-func detectAccessibilityPreferences() *AccessibilityPreferences {
+// DetectAccessibilityPreferences detects accessibility preferences from environment
+func DetectAccessibilityPreferences() *AccessibilityPreferences {
 	prefs := &AccessibilityPreferences{}
 
 	// Check environment variables
@@ -170,30 +250,8 @@ func detectAccessibilityPreferences() *AccessibilityPreferences {
 		isEnvTrue("ACCESSIBILITY_VERBOSE")
 
 	// Detect color blind preferences
-	colorBlindEnv := strings.ToLower(os.Getenv("COLOR_BLIND_TYPE"))
-	switch colorBlindEnv {
-	case "protanopia":
-		prefs.ColorBlindType = ColorBlindProtanopia
-		prefs.UseColorBlindPalette = true
-	case "deuteranopia":
-		prefs.ColorBlindType = ColorBlindDeuteranopia
-		prefs.UseColorBlindPalette = true
-	case "tritanopia":
-		prefs.ColorBlindType = ColorBlindTritanopia
-		prefs.UseColorBlindPalette = true
-	case "protanomaly":
-		prefs.ColorBlindType = ColorBlindProtanomaly
-		prefs.UseColorBlindPalette = true
-	case "deuteranomaly":
-		prefs.ColorBlindType = ColorBlindDeuteranomaly
-		prefs.UseColorBlindPalette = true
-	case "tritanomaly":
-		prefs.ColorBlindType = ColorBlindTritanomaly
-		prefs.UseColorBlindPalette = true
-	case "monochromacy":
-		prefs.ColorBlindType = ColorBlindMonochromacy
-		prefs.UseColorBlindPalette = true
-	}
+	prefs.ColorBlindType = ParseColorBlindType(os.Getenv("COLOR_BLIND_TYPE"))
+	prefs.UseColorBlindPalette = prefs.ColorBlindType != ColorBlindNone
 
 	// Cognitive preferences
 	prefs.ReduceComplexity = isEnvTrue("ACCESSIBILITY_SIMPLE_MODE") ||
@@ -205,6 +263,29 @@ func detectAccessibilityPreferences() *AccessibilityPreferences {
 	return prefs
 }
 
+// ParseColorBlindType returns the color blind type with the given name, such
+// as "deuteranopia", or ColorBlindNone for an unknown name
+func ParseColorBlindType(name string) ColorBlindType {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "protanopia":
+		return ColorBlindProtanopia
+	case "deuteranopia":
+		return ColorBlindDeuteranopia
+	case "tritanopia":
+		return ColorBlindTritanopia
+	case "protanomaly":
+		return ColorBlindProtanomaly
+	case "deuteranomaly":
+		return ColorBlindDeuteranomaly
+	case "tritanomaly":
+		return ColorBlindTritanomaly
+	case "monochromacy":
+		return ColorBlindMonochromacy
+	default:
+		return ColorBlindNone
+	}
+}
+
 // applyPreferences applies accessibility preferences to the manager
 func (am *AccessibilityManager) applyPreferences() {
 	am.highContrastMode = am.preferences.HighContrast
@@ -216,6 +297,7 @@ func (am *AccessibilityManager) applyPreferences() {
 // CreateAccessibleTheme creates a theme optimized for accessibility
 func (am *AccessibilityManager) CreateAccessibleTheme(baseTheme *Theme, level AccessibilityLevel) *Theme {
 	accessibleTheme := *baseTheme // Copy theme
+	am.theme = baseTheme          // Contrast fixes follow the base theme's brightness
 
 	// Apply high contrast if needed
 	if am.highContrastMode {
@@ -474,6 +556,13 @@ func (am *AccessibilityManager) adaptComponentsForAccessibility(components Compo
 		adapted.TransitionDuration = "0ms"
 	}
 
+	// Taller rows leave room around large text
+	if am.preferences.LargeText {
+		adapted.InputHeight = scaleSpacing(adapted.InputHeight)
+		adapted.MenuItemHeight = scaleSpacing(adapted.MenuItemHeight)
+		adapted.ListItemHeight = scaleSpacing(adapted.ListItemHeight)
+	}
+
 	// Increase touch targets for motor accessibility
 	if am.preferences.ReduceInteraction {
 		adapted.ButtonHeight = max(adapted.ButtonHeight, 4)
@@ -504,9 +593,51 @@ func (am *AccessibilityManager) increasedSpacing(spacing Spacing) Spacing {
 	increased.SectionSpacing = int(float64(spacing.SectionSpacing) * 1.3)
 	increased.ElementSpacing = int(float64(spacing.ElementSpacing) * 1.2)
 
+	// Pad components the way running components are padded
+	for _, padding := range []*[2]int{
+		&increased.ButtonPadding, &increased.InputPadding, &increased.PanelPadding,
+		&increased.CardPadding, &increased.ModalPadding,
+	} {
+		padding[0], padding[1] = scaleSpacing(padding[0]), scaleSpacing(padding[1])
+	}
+
 	return increased
 }
 
+// scaleSpacing grows a terminal spacing by half, rounding up so a single
+// cell becomes two
+func scaleSpacing(n int) int {
+	return int(math.Ceil(float64(n) * 1.5))
+}
+
+// IsLargeTextEnabled reports whether components should add room around
+// text; a nil manager means no
+func (am *AccessibilityManager) IsLargeTextEnabled() bool {
+	return am != nil && am.preferences != nil && am.preferences.LargeText
+}
+
+// ScaleForLargeText grows a padding, spacing or height when large text is
+// on and returns it unchanged otherwise
+func (am *AccessibilityManager) ScaleForLargeText(n int) int {
+	if !am.IsLargeTextEnabled() {
+		return n
+	}
+	return scaleSpacing(n)
+}
+
+// LargeTextStyle returns style with its padding grown for large text
+func (am *AccessibilityManager) LargeTextStyle(style lipgloss.Style) lipgloss.Style {
+	if !am.IsLargeTextEnabled() {
+		return style
+	}
+	return style.Padding(
+		scaleSpacing(style.GetPaddingTop()),
+		scaleSpacing(style.GetPaddingRight()),
+		scaleSpacing(style.GetPaddingBottom()),
+		scaleSpacing(style.GetPaddingLeft()),
+	)
+}
+
 // Contrast checking methods
 
 // hasAdequateContrast checks if two colors have adequate contrast
@@ -713,6 +844,12 @@ func (am *AccessibilityManager) IsAccessibilityEnabled() bool {
 		am.preferences.LargeText
 }
 
+// IsScreenReaderEnabled reports whether output should be linearized for a
+// screen reader; a nil manager means no
+func (am *AccessibilityManager) IsScreenReaderEnabled() bool {
+	return am != nil && am.screenReaderMode
+}
+
 // GetAccessibilityStatus returns a summary of enabled accessibility features
 func (am *AccessibilityManager) GetAccessibilityStatus() string {
 	var features []string
@@ -742,7 +879,12 @@ func (am *AccessibilityManager) GetAccessibilityStatus() string {
 
 // getColorBlindTypeName returns a human-readable name for the color blind type
 func (am *AccessibilityManager) getColorBlindTypeName() string {
-	switch am.preferences.ColorBlindType {
+	return am.preferences.ColorBlindType.String()
+}
+
+// String returns a human-readable name for the color blind type
+func (t ColorBlindType) String() string {
+	switch t {
 	case ColorBlindProtanopia:
 		return "Protanopia"
 	case ColorBlindDeuteranopia:
@@ -773,6 +915,12 @@ func (am *AccessibilityManager) GetPreferences() *AccessibilityPreferences {
 	return am.preferences
 }
 
+// PrefersReducedMotion reports whether the environment asks for animations
+// to be kept to a minimum
+func PrefersReducedMotion() bool {
+	return DetectAccessibilityPreferences().ReducedMotion
+}
+
 // Utility function for environment variable checking
 func isEnvTrue(envVar string) bool {
 	value := strings.ToLower(os.Getenv(envVar))
@@ -0,0 +1,26 @@
+package styles
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulateProtanopiaChangesErrorColor(t *testing.T) {
+	original := CharmDark.Colors.Error
+	simulated := SimulateColorBlindness(original, ColorBlindProtanopia)
+
+	assert.NotEqual(t, strings.ToLower(string(original)), strings.ToLower(string(simulated)))
+	assert.Equal(t, original, SimulateColorBlindness(original, ColorBlindNone))
+
+	// Grays look the same to everyone
+	gray := lipgloss.Color("#808080")
+	for _, colorBlindType := range ColorBlindTypes {
+		assert.Equal(t, "#808080", strings.ToLower(string(SimulateColorBlindness(gray, colorBlindType))), colorBlindType.String())
+	}
+
+	// ANSI colors can't be simulated
+	assert.Equal(t, lipgloss.Color("9"), SimulateColorBlindness("9", ColorBlindProtanopia))
+}
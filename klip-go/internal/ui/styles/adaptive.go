@@ -5,6 +5,7 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/muesli/termenv"
@@ -122,8 +123,26 @@ func (as *AdaptiveStyler) detectTerminalCapabilities() *TerminalCapabilities {
 	caps.SupportsStrike = as.detectStyleSupport(term, "strike")
 	caps.SupportsBoxDrawing = as.detectBoxDrawingSupport(term)
 
+	// Real capability flags from terminfo take precedence over name heuristics
+	if ti, err := LoadTerminfo(term); err == nil {
+		applyTerminfo(caps, ti)
+	}
+
+	// Emulators that are known to be capable regardless of the TERM they advertise
+	if name := detectModernTerminal(term, termProgram); name != "" {
+		caps.TerminalType = name
+		caps.HasTrueColor = true
+		caps.Has256Color = true
+		caps.HasBasicColor = true
+		caps.IsMonochrome = false
+		caps.SupportsUnicode = true
+		caps.SupportsEmoji = true
+		caps.SupportsBoxDrawing = true
+	}
+	caps.TerminalVersion = os.Getenv("TERM_PROGRAM_VERSION")
+
 	// Performance characteristics
-	caps.IsSlowTerminal = as.detectSlowTerminal(term)
+	caps.IsSlowTerminal = as.detectSlowTerminal(term, caps)
 	caps.HasLowBandwidth = caps.IsRemote || caps.IsSSH
 	caps.LimitedBuffer = as.detectLimitedBuffer(term)
 
@@ -482,13 +501,18 @@ func (as *AdaptiveStyler) GetOptimalBorder() lipgloss.Border {
 
 // GetOptimalCharset returns appropriate character set for current capabilities
 func (as *AdaptiveStyler) GetOptimalCharset() CharacterSet {
-	if as.capabilities.SupportsUnicode && as.performance.EnableUnicodeChars {
+	if as.UsesUnicode() {
 		return UnicodeCharacterSet
 	} else {
 		return ASCIICharacterSet
 	}
 }
 
+// UsesUnicode reports whether unicode glyphs should be drawn
+func (as *AdaptiveStyler) UsesUnicode() bool {
+	return as.capabilities.SupportsUnicode && as.performance.EnableUnicodeChars
+}
+
 // CharacterSet defines different character sets for UI elements
 type CharacterSet struct {
 	CheckMark     string
@@ -499,6 +523,15 @@ type CharacterSet struct {
 	ProgressFull  string
 	ProgressEmpty string
 	Ellipsis      string
+	Separator     string
+	StatusDot     string
+	StatusPending string
+	Timer         string
+	Branch        string
+	Sparkline     []string
+
+	// Signal holds one glyph per network quality tier, worst first
+	Signal []string
 }
 
 var (
@@ -511,6 +544,13 @@ var (
 		ProgressFull:  "█",
 		ProgressEmpty: "░",
 		Ellipsis:      "…",
+		Separator:     "│",
+		StatusDot:     "●",
+		StatusPending: "◐",
+		Timer:         "⏱",
+		Branch:        "⎇",
+		Sparkline:     []string{"▁", "▂", "▃", "▄", "▅", "▆", "▇", "█"},
+		Signal:        []string{"▂", "▂▄", "▂▄▆", "▂▄▆█"},
 	}
 
 	ASCIICharacterSet = CharacterSet{
@@ -522,6 +562,13 @@ var (
 		ProgressFull:  "#",
 		ProgressEmpty: "-",
 		Ellipsis:      "...",
+		Separator:     "|",
+		StatusDot:     "o",
+		StatusPending: "~",
+		Timer:         "time",
+		Branch:        "git:",
+		Sparkline:     []string{"_", ".", "-", "~", "=", "+", "*", "#"},
+		Signal:        []string{"net:poor", "net:fair", "net:good", "net:great"},
 	}
 )
 
@@ -571,6 +618,15 @@ func (as *AdaptiveStyler) GetFrameRate() int {
 	}
 }
 
+// FrameInterval returns the delay between animation ticks for the current frame rate
+func (as *AdaptiveStyler) FrameInterval() time.Duration {
+	fps := as.GetFrameRate()
+	if fps <= 0 {
+		fps = 10
+	}
+	return time.Second / time.Duration(fps)
+}
+
 // Detection helper methods
 
 func (as *AdaptiveStyler) detectUnicodeSupport(term string) bool {
@@ -587,6 +643,10 @@ func (as *AdaptiveStyler) detectUnicodeSupport(term string) bool {
 		return true
 	case strings.Contains(term, "wezterm"):
 		return true
+	case strings.Contains(term, "ghostty"):
+		return true
+	case strings.HasPrefix(term, "foot"):
+		return true
 	case term == "dumb":
 		return false
 	default:
@@ -610,6 +670,10 @@ func (as *AdaptiveStyler) detectEmojiSupport(term string) bool {
 		return true
 	case strings.Contains(term, "vscode"):
 		return true
+	case strings.Contains(term, "ghostty"):
+		return true
+	case strings.HasPrefix(term, "foot"):
+		return true
 	case term == "dumb":
 		return false
 	default:
@@ -617,6 +681,42 @@ func (as *AdaptiveStyler) detectEmojiSupport(term string) bool {
 	}
 }
 
+// detectModernTerminal identifies Ghostty, WezTerm and foot, which set TERM
+// and TERM_PROGRAM in their own ways
+func detectModernTerminal(term, termProgram string) string {
+	switch {
+	case termProgram == "ghostty" || strings.Contains(term, "ghostty"):
+		return "ghostty"
+	case termProgram == "wezterm" || strings.Contains(term, "wezterm"):
+		return "wezterm"
+	case term == "foot" || strings.HasPrefix(term, "foot-"):
+		return "foot"
+	default:
+		return ""
+	}
+}
+
+// applyTerminfo overrides heuristic capabilities with those from the terminfo
+// entry. Color support is only ever raised, since termenv also honours COLORTERM.
+func applyTerminfo(caps *TerminalCapabilities, ti *Terminfo) {
+	if ti.TrueColor {
+		caps.HasTrueColor = true
+	}
+	if ti.Colors >= 256 {
+		caps.Has256Color = true
+	}
+	if ti.Colors >= 8 {
+		caps.HasBasicColor = true
+		caps.IsMonochrome = false
+	}
+
+	caps.SupportsBold = ti.Bold
+	caps.SupportsItalic = ti.Italic
+	caps.SupportsUnderline = ti.Underline
+	caps.SupportsBlink = ti.Blink
+	caps.SupportsBoxDrawing = ti.BoxDrawing
+}
+
 func (as *AdaptiveStyler) detectStyleSupport(term, style string) bool {
 	switch term {
 	case "dumb":
@@ -643,7 +743,7 @@ func (as *AdaptiveStyler) detectBoxDrawingSupport(term string) bool {
 	}
 }
 
-func (as *AdaptiveStyler) detectSlowTerminal(term string) bool {
+func (as *AdaptiveStyler) detectSlowTerminal(term string, caps *TerminalCapabilities) bool {
 	switch {
 	case term == "dumb":
 		return true
@@ -655,7 +755,7 @@ func (as *AdaptiveStyler) detectSlowTerminal(term string) bool {
 		return true // Apple Terminal can be slow with heavy content
 	default:
 		// Check if we're on a slow connection
-		return as.capabilities.IsRemote && as.detectLowBandwidth()
+		return caps.IsRemote && as.detectLowBandwidth(caps)
 	}
 }
 
@@ -670,9 +770,9 @@ func (as *AdaptiveStyler) detectLimitedBuffer(term string) bool {
 	}
 }
 
-func (as *AdaptiveStyler) detectLowBandwidth() bool {
+func (as *AdaptiveStyler) detectLowBandwidth(caps *TerminalCapabilities) bool {
 	// Simple heuristic: assume SSH connections might have lower bandwidth
-	return as.capabilities.IsSSH
+	return caps.IsSSH
 }
 
 // Utility methods
@@ -705,6 +805,9 @@ func (as *AdaptiveStyler) PrintCapabilities() string {
 
 	fmt.Fprintf(&b, "Terminal Capabilities:\n")
 	fmt.Fprintf(&b, "  Type: %s\n", as.capabilities.TerminalType)
+	if as.capabilities.TerminalVersion != "" {
+		fmt.Fprintf(&b, "  Version: %s\n", as.capabilities.TerminalVersion)
+	}
 	fmt.Fprintf(&b, "  Platform: %s/%s\n", as.capabilities.Platform, as.capabilities.Architecture)
 	fmt.Fprintf(&b, "  Remote: %v (SSH: %v)\n", as.capabilities.IsRemote, as.capabilities.IsSSH)
 	fmt.Fprintf(&b, "  Color Support:\n")
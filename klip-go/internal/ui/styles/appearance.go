@@ -0,0 +1,109 @@
+package styles
+
+import (
+	"context"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// appearance is the light/dark preference reported by the operating system
+type appearance int
+
+const (
+	appearanceUnknown appearance = iota
+	appearanceLight
+	appearanceDark
+)
+
+// appearanceTimeout bounds how long we wait on the OS when probing appearance
+const appearanceTimeout = 300 * time.Millisecond
+
+var (
+	// detectAppearance probes the OS appearance; tests replace it
+	detectAppearance = detectSystemAppearance
+
+	appearanceOnce   sync.Once
+	appearanceCached appearance
+)
+
+// systemAppearance returns the OS appearance, probing it at most once per process
+func systemAppearance() appearance {
+	appearanceOnce.Do(func() {
+		appearanceCached = detectAppearance()
+	})
+	return appearanceCached
+}
+
+// detectSystemAppearance reads the appearance setting for the current platform
+func detectSystemAppearance() appearance {
+	switch runtime.GOOS {
+	case "darwin":
+		return detectMacOSAppearance()
+	case "windows":
+		return detectWindowsAppearance()
+	default:
+		// Linux desktops have no single source of truth; rely on env heuristics
+		return appearanceUnknown
+	}
+}
+
+// detectMacOSAppearance checks AppleInterfaceStyle, which is only set in dark mode
+func detectMacOSAppearance() appearance {
+	ctx, cancel := context.WithTimeout(context.Background(), appearanceTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "defaults", "read", "-g", "AppleInterfaceStyle").Output()
+	if ctx.Err() != nil {
+		return appearanceUnknown
+	}
+	if err != nil {
+		// The key does not exist in light mode
+		return appearanceLight
+	}
+
+	return parseMacOSAppearance(string(out))
+}
+
+// parseMacOSAppearance interprets the output of `defaults read -g AppleInterfaceStyle`
+func parseMacOSAppearance(output string) appearance {
+	if strings.EqualFold(strings.TrimSpace(output), "dark") {
+		return appearanceDark
+	}
+	return appearanceLight
+}
+
+// detectWindowsAppearance reads AppsUseLightTheme from the registry
+func detectWindowsAppearance() appearance {
+	ctx, cancel := context.WithTimeout(context.Background(), appearanceTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "reg", "query",
+		`HKCU\Software\Microsoft\Windows\CurrentVersion\Themes\Personalize`,
+		"/v", "AppsUseLightTheme").Output()
+	if err != nil {
+		return appearanceUnknown
+	}
+
+	return parseWindowsAppearance(string(out))
+}
+
+// parseWindowsAppearance interprets `reg query ... /v AppsUseLightTheme` output
+func parseWindowsAppearance(output string) appearance {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[0] != "AppsUseLightTheme" {
+			continue
+		}
+
+		switch fields[len(fields)-1] {
+		case "0x0":
+			return appearanceDark
+		case "0x1":
+			return appearanceLight
+		}
+	}
+	return appearanceUnknown
+}
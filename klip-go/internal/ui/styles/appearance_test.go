@@ -0,0 +1,86 @@
+package styles
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockAppearance replaces OS detection for the duration of a test
+func mockAppearance(t *testing.T, result appearance) *int {
+	calls := 0
+	original := detectAppearance
+	detectAppearance = func() appearance {
+		calls++
+		return result
+	}
+	appearanceOnce = sync.Once{}
+
+	t.Cleanup(func() {
+		detectAppearance = original
+		appearanceOnce = sync.Once{}
+	})
+	return &calls
+}
+
+func clearThemeEnv(t *testing.T) {
+	for _, name := range []string{"KLIP_THEME", "COLORFGBG", "KLIP_DARK_MODE", "DARK_MODE", "FORCE_HIGH_CONTRAST", "ACCESSIBILITY_HIGH_CONTRAST"} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestSetDefaultThemeUsesSystemAppearance(t *testing.T) {
+	tests := []struct {
+		name       string
+		appearance appearance
+		expected   string
+	}{
+		{"dark", appearanceDark, "charm-dark"},
+		{"light", appearanceLight, "charm-light"},
+		{"unknown", appearanceUnknown, "charm-light"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearThemeEnv(t)
+			mockAppearance(t, tt.appearance)
+
+			tm := NewThemeManager()
+			assert.Equal(t, tt.expected, tm.GetCurrentTheme().Name)
+		})
+	}
+}
+
+func TestSetDefaultThemePrefersEnvironment(t *testing.T) {
+	clearThemeEnv(t)
+	t.Setenv("KLIP_DARK_MODE", "true")
+	calls := mockAppearance(t, appearanceLight)
+
+	tm := NewThemeManager()
+	assert.Equal(t, "charm-dark", tm.GetCurrentTheme().Name)
+	assert.Zero(t, *calls)
+}
+
+func TestSystemAppearanceIsCached(t *testing.T) {
+	calls := mockAppearance(t, appearanceDark)
+
+	systemAppearance()
+	systemAppearance()
+	assert.Equal(t, 1, *calls)
+}
+
+func TestParseMacOSAppearance(t *testing.T) {
+	assert.Equal(t, appearanceDark, parseMacOSAppearance("Dark\n"))
+	assert.Equal(t, appearanceLight, parseMacOSAppearance(""))
+}
+
+func TestParseWindowsAppearance(t *testing.T) {
+	output := "\nHKEY_CURRENT_USER\\Software\\Microsoft\\Windows\\CurrentVersion\\Themes\\Personalize\n    AppsUseLightTheme    REG_DWORD    0x0\n"
+	assert.Equal(t, appearanceDark, parseWindowsAppearance(output))
+
+	output = "    AppsUseLightTheme    REG_DWORD    0x1\n"
+	assert.Equal(t, appearanceLight, parseWindowsAppearance(output))
+
+	assert.Equal(t, appearanceUnknown, parseWindowsAppearance("ERROR: The system was unable to find the specified registry key"))
+}
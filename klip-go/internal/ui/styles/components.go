@@ -3,6 +3,7 @@ package styles
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -63,6 +64,25 @@ const (
 
 // Button creates a styled button
 func (cs *ComponentStyler) Button(text string, variant ButtonStyle, size ButtonSize, state ButtonState) string {
+	style := cs.buttonStyle(variant, size, state)
+
+	// Loading buttons keep their variant colors with a spinner and dimmed label.
+	// The label is styled on its own so the spinner's reset doesn't strip it.
+	if state == ButtonStateLoading {
+		spinner := NewInteractiveStyler(cs.theme, cs.width, cs.height).AnimatedSpinner(spinnerPhase(time.Now()))
+		label := lipgloss.NewStyle().
+			Foreground(style.GetForeground()).
+			Background(style.GetBackground()).
+			Faint(true).
+			Render(" " + text)
+		text = spinner + label
+	}
+
+	return style.Render(text)
+}
+
+// buttonStyle resolves the style for a button variant in the given state
+func (cs *ComponentStyler) buttonStyle(variant ButtonStyle, size ButtonSize, state ButtonState) lipgloss.Style {
 	baseStyle := lipgloss.NewStyle().
 		Padding(cs.getButtonPadding(size)...).
 		Align(lipgloss.Center).
@@ -154,19 +174,38 @@ func (cs *ComponentStyler) Button(text string, variant ButtonStyle, size ButtonS
 		}
 	}
 
-	// Handle disabled state
+	// Disabled buttons replace the variant colors entirely
 	if state == ButtonStateDisabled {
-		baseStyle = baseStyle.
-			Foreground(cs.theme.Colors.TextMuted).
-			Background(cs.theme.Colors.BackgroundSubtle)
+		return cs.disabledButtonStyle(baseStyle, variant)
 	}
 
-	// Handle loading state
-	if state == ButtonStateLoading {
-		text = cs.Spinner("small") + " " + text
+	return baseStyle
+}
+
+// disabledButtonStyle mutes a button while keeping its variant's shape
+func (cs *ComponentStyler) disabledButtonStyle(style lipgloss.Style, variant ButtonStyle) lipgloss.Style {
+	style = style.
+		Foreground(cs.theme.Colors.TextMuted).
+		Bold(false)
+
+	switch variant {
+	case ButtonGhost:
+		return style.Background(lipgloss.Color(""))
+	case ButtonLink:
+		return style.Background(lipgloss.Color("")).Underline(false)
+	case ButtonOutline:
+		return style.
+			Background(lipgloss.Color("")).
+			BorderForeground(cs.theme.Colors.BorderSubtle)
+	default:
+		return style.Background(cs.theme.Colors.BackgroundSubtle)
 	}
+}
 
-	return baseStyle.Render(text)
+// spinnerPhase maps the wall clock onto a one-second spinner cycle so static
+// renders still advance between frames
+func spinnerPhase(now time.Time) float64 {
+	return float64(now.UnixNano()%int64(time.Second)) / float64(time.Second)
 }
 
 func (cs *ComponentStyler) getButtonPadding(size ButtonSize) []int {
@@ -182,6 +221,78 @@ func (cs *ComponentStyler) getButtonPadding(size ButtonSize) []int {
 	}
 }
 
+// Toggle Styles
+
+// toggleTrackWidth is the number of cells the knob travels across
+const toggleTrackWidth = 3
+
+// Toggle creates an on/off switch with a label, e.g. "[  ●] Dark mode"
+func (cs *ComponentStyler) Toggle(label string, on bool, state ButtonState) string {
+	position := 0
+	if on {
+		position = toggleTrackWidth - 1
+	}
+
+	trackStyle := lipgloss.NewStyle().Foreground(cs.theme.Colors.Border)
+	knobStyle := lipgloss.NewStyle().Foreground(cs.theme.Colors.TextMuted)
+	if on {
+		trackStyle = trackStyle.Foreground(cs.theme.Colors.Primary)
+		knobStyle = knobStyle.Foreground(cs.theme.Colors.Success)
+	}
+
+	labelStyle := lipgloss.NewStyle().Foreground(cs.theme.Colors.Text)
+	switch state {
+	case ButtonStateHover:
+		trackStyle = trackStyle.Foreground(cs.theme.Colors.PrimaryLight)
+	case ButtonStateFocus, ButtonStateActive:
+		trackStyle = trackStyle.Foreground(cs.theme.Colors.BorderFocus)
+		labelStyle = labelStyle.Bold(true)
+	case ButtonStateDisabled:
+		trackStyle = trackStyle.Foreground(cs.theme.Colors.BorderSubtle)
+		knobStyle = knobStyle.Foreground(cs.theme.Colors.TextMuted)
+		labelStyle = labelStyle.Foreground(cs.theme.Colors.TextMuted)
+	case ButtonStateLoading:
+		labelStyle = labelStyle.Faint(true)
+	}
+
+	return renderToggle(label, position, trackStyle, knobStyle, labelStyle)
+}
+
+// renderToggle draws the track with the knob at position, followed by the label
+func renderToggle(label string, position int, trackStyle, knobStyle, labelStyle lipgloss.Style) string {
+	if position < 0 {
+		position = 0
+	}
+	if position > toggleTrackWidth-1 {
+		position = toggleTrackWidth - 1
+	}
+
+	toggle := trackStyle.Render("["+strings.Repeat(" ", position)) +
+		knobStyle.Render("●") +
+		trackStyle.Render(strings.Repeat(" ", toggleTrackWidth-1-position)+"]")
+
+	if label != "" {
+		toggle += " " + labelStyle.Render(label)
+	}
+	return toggle
+}
+
+// ToggleKey applies a key press to a toggle's value. Space, enter and x flip
+// it; left/h and right/l set it off and on. handled reports whether the key
+// belongs to the toggle so the owning component can stop propagating it.
+func ToggleKey(on bool, key string) (value bool, handled bool) {
+	switch key {
+	case " ", "space", "enter", "x":
+		return !on, true
+	case "left", "h":
+		return false, true
+	case "right", "l":
+		return true, true
+	default:
+		return on, false
+	}
+}
+
 // Input Styles
 
 // InputType represents different input variants
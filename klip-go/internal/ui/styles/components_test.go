@@ -0,0 +1,87 @@
+package styles
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+)
+
+var allButtonVariants = []ButtonStyle{
+	ButtonPrimary, ButtonSecondary, ButtonSuccess, ButtonError, ButtonWarning,
+	ButtonInfo, ButtonGhost, ButtonOutline, ButtonLink,
+}
+
+func TestDisabledButtonIsMuted(t *testing.T) {
+	cs := NewComponentStyler(&CharmDark, 80, 24)
+
+	for _, variant := range allButtonVariants {
+		disabled := cs.buttonStyle(variant, ButtonSizeSmall, ButtonStateDisabled)
+		assert.Equal(t, CharmDark.Colors.TextMuted, disabled.GetForeground(), "variant %d", variant)
+		assert.False(t, disabled.GetBold(), "variant %d", variant)
+
+		// Hover colors never leak into the disabled style
+		hover := cs.buttonStyle(variant, ButtonSizeSmall, ButtonStateHover)
+		normal := cs.buttonStyle(variant, ButtonSizeSmall, ButtonStateNormal)
+		if hover.GetBackground() != normal.GetBackground() {
+			assert.NotEqual(t, hover.GetBackground(), disabled.GetBackground(), "variant %d", variant)
+		}
+	}
+
+	primary := cs.buttonStyle(ButtonPrimary, ButtonSizeSmall, ButtonStateDisabled)
+	assert.Equal(t, CharmDark.Colors.BackgroundSubtle, primary.GetBackground())
+}
+
+func TestLoadingButtonShowsSpinner(t *testing.T) {
+	cs := NewComponentStyler(&CharmDark, 80, 24)
+
+	for _, variant := range allButtonVariants {
+		rendered := cs.Button("Save", variant, ButtonSizeSmall, ButtonStateLoading)
+		assert.Contains(t, rendered, "Save")
+
+		hasFrame := false
+		for _, frame := range []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"} {
+			if strings.Contains(rendered, frame) {
+				hasFrame = true
+				break
+			}
+		}
+		assert.True(t, hasFrame, "variant %d should render a spinner", variant)
+	}
+}
+
+func TestToggleRendersKnobPosition(t *testing.T) {
+	cs := NewComponentStyler(&CharmDark, 80, 24)
+
+	off := ansi.Strip(cs.Toggle("Dark mode", false, ButtonStateNormal))
+	assert.Equal(t, "[●  ] Dark mode", off)
+
+	on := ansi.Strip(cs.Toggle("Dark mode", true, ButtonStateFocus))
+	assert.Equal(t, "[  ●] Dark mode", on)
+
+	assert.Equal(t, "[●  ]", ansi.Strip(cs.Toggle("", false, ButtonStateDisabled)))
+}
+
+func TestAnimatedToggleSlidesKnob(t *testing.T) {
+	is := NewInteractiveStyler(&CharmDark, 80, 24)
+
+	assert.Equal(t, "[●  ] Sync", ansi.Strip(is.AnimatedToggle("Sync", true, StateIdle, 0)))
+	assert.Equal(t, "[ ● ] Sync", ansi.Strip(is.AnimatedToggle("Sync", true, StateIdle, 0.5)))
+	assert.Equal(t, "[ ● ] Sync", ansi.Strip(is.AnimatedToggle("Sync", false, StateIdle, 0.5)))
+	assert.Equal(t, "[  ●] Sync", ansi.Strip(is.AnimatedToggle("Sync", true, StateIdle, 1)))
+}
+
+func TestToggleKey(t *testing.T) {
+	value, handled := ToggleKey(false, " ")
+	assert.True(t, value)
+	assert.True(t, handled)
+
+	value, handled = ToggleKey(true, "left")
+	assert.False(t, value)
+	assert.True(t, handled)
+
+	value, handled = ToggleKey(true, "q")
+	assert.True(t, value)
+	assert.False(t, handled)
+}
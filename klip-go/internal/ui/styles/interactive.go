@@ -145,6 +145,36 @@ func (is *InteractiveStyler) InteractiveButton(text string, variant ButtonStyle,
 	return baseStyle.Render(text)
 }
 
+// AnimatedToggle slides the knob toward its new position as progress goes
+// from 0 to 1, so on=true animates a switch being turned on
+func (is *InteractiveStyler) AnimatedToggle(label string, on bool, state InteractionState, progress float64) string {
+	progress = math.Max(0, math.Min(1, progress))
+
+	travel := progress
+	if !on {
+		travel = 1 - progress
+	}
+	position := int(math.Round(travel * float64(toggleTrackWidth-1)))
+
+	trackStyle := lipgloss.NewStyle().
+		Foreground(is.interpolateColor(is.theme.Colors.Border, is.theme.Colors.Primary, travel))
+	knobStyle := lipgloss.NewStyle().
+		Foreground(is.interpolateColor(is.theme.Colors.TextMuted, is.theme.Colors.Success, travel))
+	labelStyle := lipgloss.NewStyle().Foreground(is.theme.Colors.Text)
+
+	switch state {
+	case StateHover:
+		trackStyle = trackStyle.Foreground(is.theme.Colors.PrimaryLight)
+	case StateFocus, StateActive, StatePressed:
+		trackStyle = trackStyle.Foreground(is.theme.Colors.BorderFocus)
+		labelStyle = labelStyle.Bold(true)
+	case StateLoading:
+		labelStyle = labelStyle.Faint(true)
+	}
+
+	return renderToggle(label, position, trackStyle, knobStyle, labelStyle)
+}
+
 // Interactive Input with focus animations
 func (is *InteractiveStyler) InteractiveInput(value, placeholder string, inputType InputType, state InteractionState, width int, progress float64) string {
 	if width <= 0 {
@@ -0,0 +1,270 @@
+package styles
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Magic numbers of the compiled terminfo formats
+const (
+	terminfoMagicLegacy = 0432  // 16-bit numbers
+	terminfoMagic32Bit  = 01036 // 32-bit numbers (ncurses 6.1+)
+)
+
+// Indices into the standard terminfo capability arrays (see term(5))
+const (
+	terminfoNumColors = 13 // max_colors
+
+	terminfoStrSmacs = 25  // enter_alt_charset_mode
+	terminfoStrBlink = 26  // enter_blink_mode
+	terminfoStrBold  = 27  // enter_bold_mode
+	terminfoStrSmul  = 36  // enter_underline_mode
+	terminfoStrAcsc  = 146 // acs_chars
+	terminfoStrSitm  = 311 // enter_italics_mode
+)
+
+// errTerminfoNotFound is returned when no entry exists for a terminal name
+var errTerminfoNotFound = errors.New("terminfo entry not found")
+
+// Terminfo holds the capabilities we care about from a compiled terminfo entry
+type Terminfo struct {
+	Names     []string
+	Colors    int
+	TrueColor bool
+
+	Bold       bool
+	Italic     bool
+	Underline  bool
+	Blink      bool
+	BoxDrawing bool
+}
+
+// LoadTerminfo finds and parses the terminfo entry for a terminal name
+func LoadTerminfo(term string) (*Terminfo, error) {
+	if term == "" {
+		return nil, errTerminfoNotFound
+	}
+
+	for _, dir := range terminfoDirs() {
+		// Entries live under their first letter, or its hex code on macOS
+		for _, sub := range []string{term[:1], fmt.Sprintf("%x", term[0])} {
+			data, err := os.ReadFile(filepath.Join(dir, sub, term))
+			if err == nil {
+				return ParseTerminfo(data)
+			}
+		}
+	}
+
+	return nil, errTerminfoNotFound
+}
+
+// terminfoDirs returns the terminfo search path in ncurses order
+func terminfoDirs() []string {
+	var dirs []string
+
+	if dir := os.Getenv("TERMINFO"); dir != "" {
+		dirs = append(dirs, dir)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".terminfo"))
+	}
+	if list := os.Getenv("TERMINFO_DIRS"); list != "" {
+		for _, dir := range strings.Split(list, ":") {
+			if dir == "" {
+				dir = "/usr/share/terminfo"
+			}
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return append(dirs, "/etc/terminfo", "/lib/terminfo", "/usr/share/terminfo", "/usr/lib/terminfo")
+}
+
+// ParseTerminfo decodes a compiled terminfo entry
+func ParseTerminfo(data []byte) (*Terminfo, error) {
+	r := &terminfoReader{data: data}
+
+	magic := r.short()
+	numSize := 2
+	switch magic {
+	case terminfoMagicLegacy:
+	case terminfoMagic32Bit:
+		numSize = 4
+	default:
+		return nil, fmt.Errorf("invalid terminfo magic number %#o", magic)
+	}
+
+	namesSize := r.short()
+	boolCount := r.short()
+	numCount := r.short()
+	strCount := r.short()
+	strTableSize := r.short()
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	names := strings.TrimRight(string(r.bytes(namesSize)), "\x00")
+	r.bytes(boolCount)
+	r.align()
+
+	numbers := make([]int, numCount)
+	for i := range numbers {
+		numbers[i] = r.number(numSize)
+	}
+
+	offsets := make([]int, strCount)
+	for i := range offsets {
+		offsets[i] = r.short()
+	}
+	table := r.bytes(strTableSize)
+	if r.err != nil {
+		return nil, fmt.Errorf("truncated terminfo entry: %w", r.err)
+	}
+
+	hasString := func(index int) bool {
+		return index < len(offsets) && offsets[index] >= 0 && offsets[index] < len(table)
+	}
+
+	ti := &Terminfo{
+		Names:      strings.Split(names, "|"),
+		Bold:       hasString(terminfoStrBold),
+		Italic:     hasString(terminfoStrSitm),
+		Underline:  hasString(terminfoStrSmul),
+		Blink:      hasString(terminfoStrBlink),
+		BoxDrawing: hasString(terminfoStrSmacs) && hasString(terminfoStrAcsc),
+	}
+	if terminfoNumColors < len(numbers) && numbers[terminfoNumColors] > 0 {
+		ti.Colors = numbers[terminfoNumColors]
+	}
+
+	// Extended capabilities are optional; a malformed section is ignored
+	r.align()
+	if extended, err := r.extendedNames(numSize); err == nil {
+		ti.TrueColor = extended["Tc"] || extended["RGB"]
+	}
+
+	return ti, nil
+}
+
+// terminfoReader reads little-endian values from a compiled entry
+type terminfoReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *terminfoReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.pos+n > len(r.data) {
+		r.err = errors.New("unexpected end of data")
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *terminfoReader) short() int {
+	b := r.bytes(2)
+	if b == nil {
+		return 0
+	}
+	return int(int16(binary.LittleEndian.Uint16(b)))
+}
+
+func (r *terminfoReader) number(size int) int {
+	if size == 4 {
+		b := r.bytes(4)
+		if b == nil {
+			return 0
+		}
+		return int(int32(binary.LittleEndian.Uint32(b)))
+	}
+	return r.short()
+}
+
+// align skips the padding byte that keeps sections on even offsets
+func (r *terminfoReader) align() {
+	if r.pos%2 == 1 && r.pos < len(r.data) {
+		r.pos++
+	}
+}
+
+// extendedNames reads the user-defined section, returning which boolean
+// and numeric capabilities are set by name
+func (r *terminfoReader) extendedNames(numSize int) (map[string]bool, error) {
+	if r.pos >= len(r.data) {
+		return nil, errTerminfoNotFound
+	}
+
+	boolCount := r.short()
+	numCount := r.short()
+	strCount := r.short()
+	r.short() // total number of string table items
+	tableSize := r.short()
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	bools := r.bytes(boolCount)
+	r.align()
+	numbers := make([]int, numCount)
+	for i := range numbers {
+		numbers[i] = r.number(numSize)
+	}
+	valueOffsets := make([]int, strCount)
+	for i := range valueOffsets {
+		valueOffsets[i] = r.short()
+	}
+	nameOffsets := make([]int, boolCount+numCount+strCount)
+	for i := range nameOffsets {
+		nameOffsets[i] = r.short()
+	}
+	table := r.bytes(tableSize)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	// Names follow the last string value in the table
+	namesStart := 0
+	for _, offset := range valueOffsets {
+		if offset < 0 || offset >= len(table) {
+			continue
+		}
+		if end := strings.IndexByte(string(table[offset:]), 0); end >= 0 && offset+end+1 > namesStart {
+			namesStart = offset + end + 1
+		}
+	}
+
+	nameAt := func(offset int) string {
+		start := namesStart + offset
+		if offset < 0 || start >= len(table) {
+			return ""
+		}
+		end := strings.IndexByte(string(table[start:]), 0)
+		if end < 0 {
+			return string(table[start:])
+		}
+		return string(table[start : start+end])
+	}
+
+	set := make(map[string]bool)
+	for i, b := range bools {
+		if b == 1 {
+			set[nameAt(nameOffsets[i])] = true
+		}
+	}
+	for i, n := range numbers {
+		if n >= 0 {
+			set[nameAt(nameOffsets[boolCount+i])] = true
+		}
+	}
+
+	return set, nil
+}
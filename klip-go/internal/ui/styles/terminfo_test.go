@@ -0,0 +1,109 @@
+package styles
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readTerminfoFixture(t *testing.T, name string) *Terminfo {
+	data, err := os.ReadFile(filepath.Join("testdata", "terminfo", name[:1], name))
+	require.NoError(t, err)
+
+	ti, err := ParseTerminfo(data)
+	require.NoError(t, err)
+	return ti
+}
+
+func TestParseTerminfoXterm256(t *testing.T) {
+	ti := readTerminfoFixture(t, "xterm-256color")
+
+	assert.Equal(t, "xterm-256color", ti.Names[0])
+	assert.Equal(t, 256, ti.Colors)
+	assert.False(t, ti.TrueColor)
+	assert.True(t, ti.Bold)
+	assert.True(t, ti.Italic)
+	assert.True(t, ti.Underline)
+	assert.True(t, ti.Blink)
+	assert.True(t, ti.BoxDrawing)
+}
+
+func TestParseTerminfoLinuxConsole(t *testing.T) {
+	ti := readTerminfoFixture(t, "linux")
+
+	assert.Equal(t, 8, ti.Colors)
+	assert.True(t, ti.Bold)
+	assert.False(t, ti.Italic)
+	assert.True(t, ti.BoxDrawing)
+}
+
+func TestParseTerminfoDirectColor(t *testing.T) {
+	ti := readTerminfoFixture(t, "xterm-direct")
+
+	assert.True(t, ti.TrueColor)
+}
+
+func TestParseTerminfoRejectsInvalidData(t *testing.T) {
+	_, err := ParseTerminfo([]byte{0x00, 0x01, 0x02})
+	assert.Error(t, err)
+
+	data, err := os.ReadFile(filepath.Join("testdata", "terminfo", "x", "xterm-256color"))
+	require.NoError(t, err)
+	_, err = ParseTerminfo(data[:20])
+	assert.Error(t, err)
+}
+
+func TestLoadTerminfoSearchesTerminfoDir(t *testing.T) {
+	t.Setenv("TERMINFO", filepath.Join("testdata", "terminfo"))
+
+	ti, err := LoadTerminfo("linux")
+	require.NoError(t, err)
+	assert.Equal(t, 8, ti.Colors)
+
+	_, err = LoadTerminfo("")
+	assert.Error(t, err)
+}
+
+func TestDetectCapabilitiesPrefersTerminfo(t *testing.T) {
+	t.Setenv("TERMINFO", filepath.Join("testdata", "terminfo"))
+	t.Setenv("TERM", "linux")
+	t.Setenv("TERM_PROGRAM", "")
+
+	caps := (&AdaptiveStyler{}).detectTerminalCapabilities()
+
+	assert.True(t, caps.HasBasicColor)
+	assert.True(t, caps.SupportsBold)
+	assert.False(t, caps.SupportsItalic)
+}
+
+func TestDetectModernTerminals(t *testing.T) {
+	tests := []struct {
+		term, program, expected string
+	}{
+		{"xterm-ghostty", "ghostty", "ghostty"},
+		{"xterm-256color", "wezterm", "wezterm"},
+		{"foot", "", "foot"},
+		{"foot-extra", "", "foot"},
+		{"xterm-256color", "", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, detectModernTerminal(tt.term, tt.program), tt.term+"/"+tt.program)
+	}
+}
+
+func TestDetectCapabilitiesRecordsWezTermVersion(t *testing.T) {
+	t.Setenv("TERM", "xterm-256color")
+	t.Setenv("TERM_PROGRAM", "WezTerm")
+	t.Setenv("TERM_PROGRAM_VERSION", "20240203-110809-5046fc22")
+
+	caps := (&AdaptiveStyler{}).detectTerminalCapabilities()
+
+	assert.Equal(t, "wezterm", caps.TerminalType)
+	assert.Equal(t, "20240203-110809-5046fc22", caps.TerminalVersion)
+	assert.True(t, caps.HasTrueColor)
+	assert.True(t, caps.SupportsEmoji)
+}
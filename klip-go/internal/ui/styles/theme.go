@@ -473,6 +473,7 @@ type ThemeManager struct {
 	availableThemes map[string]*Theme
 	terminalProfile termenv.Profile
 	colorSupport    ColorSupport
+	accessibility   *AccessibilityManager
 }
 
 // ColorSupport represents terminal color capabilities
@@ -518,11 +519,35 @@ func (tm *ThemeManager) SetTheme(name string) error {
 
 	// Adapt theme to terminal capabilities
 	adaptedTheme := tm.adaptThemeToTerminal(theme)
+	if tm.accessibility != nil && tm.accessibility.IsAccessibilityEnabled() {
+		adaptedTheme = tm.accessibility.CreateAccessibleTheme(adaptedTheme, AccessibilityAA)
+	}
 	tm.currentTheme = adaptedTheme
 
 	return nil
 }
 
+// SetAccessibility applies am's preferences to the current theme and every
+// theme set after it; nil turns the adaptations off
+func (tm *ThemeManager) SetAccessibility(am *AccessibilityManager) error {
+	tm.accessibility = am
+	if tm.currentTheme == nil {
+		return nil
+	}
+	return tm.SetTheme(tm.currentTheme.Name)
+}
+
+// SetColorProfile adapts themes to a terminal with the given color
+// profile instead of the detected one, re-applying the current theme
+func (tm *ThemeManager) SetColorProfile(profile termenv.Profile) error {
+	tm.terminalProfile = profile
+	tm.colorSupport = tm.detectColorSupport()
+	if tm.currentTheme == nil {
+		return nil
+	}
+	return tm.SetTheme(tm.currentTheme.Name)
+}
+
 // GetCurrentTheme returns the currently active theme
 func (tm *ThemeManager) GetCurrentTheme() *Theme {
 	return tm.currentTheme
@@ -605,8 +630,13 @@ func (tm *ThemeManager) shouldUseDarkTheme() bool {
 	}
 
 	// Check for explicit dark theme preference
-	return strings.ToLower(os.Getenv("KLIP_DARK_MODE")) == "true" ||
-		strings.ToLower(os.Getenv("DARK_MODE")) == "true"
+	if strings.ToLower(os.Getenv("KLIP_DARK_MODE")) == "true" ||
+		strings.ToLower(os.Getenv("DARK_MODE")) == "true" {
+		return true
+	}
+
+	// Fall back to the OS appearance on macOS and Windows
+	return systemAppearance() == appearanceDark
 }
 
 // Color conversion methods for terminal adaptation
@@ -781,3 +811,7 @@ func SetTheme(name string) error {
 func RegisterTheme(theme *Theme) {
 	DefaultThemeManager.RegisterTheme(theme)
 }
+
+func SetAccessibility(am *AccessibilityManager) error {
+	return DefaultThemeManager.SetAccessibility(am)
+}